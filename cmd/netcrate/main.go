@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+
+	"github.com/netcrate/netcrate/internal/abort"
+	"github.com/netcrate/netcrate/internal/engine"
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	root := &cobra.Command{
+		Use:   "netcrate",
+		Short: "Network discovery and scanning toolkit",
+		Long: `NetCrate discovers hosts, scans ports and fingerprints services on networks
+you are authorized to test. Start with 'netcrate quick' or 'netcrate scan',
+and 'netcrate doctor' when something does not work.`,
+		Version:           version.GetVersion().Short(),
+		SilenceUsage:      true,
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+	}
+	root.SetVersionTemplate("{{.Version}}\n")
+	engine.AddStyleFlags(root)
+	engine.AddGlobalFlags(root)
+
+	root.AddCommand(
+		engine.NewQuickCommand(),
+		engine.NewScanCommand(),
+		engine.NewOpsCommand(),
+		engine.NewOutputCommand(),
+		engine.NewTemplateCommand(),
+		engine.NewConfigCommand(),
+		engine.NewComplianceCommand(),
+		engine.NewAuditCommand(),
+		engine.NewAbortCommand(),
+		engine.NewPrivilegesCommand(),
+		engine.NewDoctorCommand(),
+		engine.NewTUICommand(),
+		engine.NewCompletionCommand(),
+		engine.NewDebugCommand(),
+		engine.NewSelfUpdateCommand(),
+	)
+
+	root.SetArgs(engine.ExpandAlias(root, os.Args[1:]))
+
+	// Commands exit with their own codes; an error returned here comes from
+	// cobra itself, an unknown command or an invalid flag
+	if err := root.Execute(); err != nil {
+		os.Exit(exitcode.Usage)
+	}
+	// A command stopped by the kill switch has saved what it had and run
+	// its cleanup by now
+	if abort.Stopped() != nil {
+		os.Exit(abort.ExitCode)
+	}
+}
@@ -0,0 +1,289 @@
+// Package abort is the kill switch of shared environments. Engaging it
+// writes a sentinel file under ~/.netcrate/run that every running netcrate
+// command watches: they stop sending traffic, keep what they have and exit,
+// and new runs are refused until the switch is cleared.
+package abort
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/style"
+)
+
+const (
+	// ExitCode is the exit status of a command stopped by the kill switch,
+	// the same as an interrupted one
+	ExitCode = exitcode.Interrupted
+
+	sentinelName = "abort"
+	pidSuffix    = ".pid"
+
+	// pollInterval is how often running commands look for the sentinel
+	pollInterval = 100 * time.Millisecond
+
+	// exitGrace is how long a stopped command has to wind down before it
+	// is made to exit
+	exitGrace = 5 * time.Second
+)
+
+var (
+	stopCtx, stop = context.WithCancel(context.Background())
+	stopped       atomic.Pointer[Switch]
+)
+
+// Context is canceled when the kill switch is engaged while a guarded
+// command runs. Operations that send traffic derive their contexts from it.
+func Context() context.Context {
+	return stopCtx
+}
+
+// Stopped returns the kill switch that stopped the command, or nil
+func Stopped() *Switch {
+	return stopped.Load()
+}
+
+// Switch records who engaged the kill switch, and why
+type Switch struct {
+	Time   time.Time `json:"time"`
+	By     string    `json:"by,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+func (s *Switch) String() string {
+	text := fmt.Sprintf("engaged %s", s.Time.Local().Format("2006-01-02 15:04:05"))
+	if s.By != "" {
+		text += " by " + s.By
+	}
+	if s.Reason != "" {
+		text += ": " + s.Reason
+	}
+	return text
+}
+
+// Process is a running netcrate command that watches the kill switch
+type Process struct {
+	PID     int       `json:"pid"`
+	Command string    `json:"command"`
+	User    string    `json:"user,omitempty"`
+	Started time.Time `json:"started"`
+}
+
+// Dir returns ~/.netcrate/run
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".netcrate", "run"), nil
+}
+
+func sentinelPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sentinelName), nil
+}
+
+// Engaged returns the kill switch when it is engaged, nil otherwise
+func Engaged() (*Switch, error) {
+	path, err := sentinelPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kill switch: %w", err)
+	}
+	var s Switch
+	if json.Unmarshal(data, &s) != nil {
+		// An empty or hand-made sentinel still counts
+		s = Switch{Reason: strings.TrimSpace(string(data))}
+		if info, err := os.Stat(path); err == nil {
+			s.Time = info.ModTime()
+		}
+	}
+	return &s, nil
+}
+
+// Engage engages the kill switch. Running commands notice within
+// pollInterval.
+func Engage(reason string) (*Switch, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	s := &Switch{Time: time.Now(), By: currentUser(), Reason: reason}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, sentinelName), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to engage kill switch: %w", err)
+	}
+	return s, nil
+}
+
+// Clear releases the kill switch so that new runs may start
+func Clear() error {
+	path, err := sentinelPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to clear kill switch: %w", err)
+	}
+	return nil
+}
+
+// Running lists the commands watching the kill switch. Pid files left by
+// commands that died without cleaning up are removed.
+func Running() ([]Process, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []Process
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), pidSuffix) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var process Process
+		if json.Unmarshal(data, &process) != nil || !alive(process.PID) {
+			os.Remove(path)
+			continue
+		}
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+// Guard registers a command that sends traffic. It fails when the kill
+// switch is engaged; otherwise engaging the switch cancels Context, after
+// which the command is expected to save what it has, return and exit with
+// ExitCode. One still running after exitGrace exits at once. The returned
+// function unregisters the command.
+func Guard(command string) (func(), error) {
+	if s, err := Engaged(); err != nil {
+		return nil, err
+	} else if s != nil {
+		return nil, fmt.Errorf("the kill switch is %s; run 'netcrate abort --clear' to allow new runs", s)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	pidPath := filepath.Join(dir, strconv.Itoa(os.Getpid())+pidSuffix)
+	data, err := json.Marshal(Process{
+		PID:     os.Getpid(),
+		Command: command,
+		User:    currentUser(),
+		Started: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(pidPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to register with the kill switch: %w", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if s, _ := Engaged(); s != nil {
+					os.Remove(pidPath)
+					style.Fprintf(os.Stderr, "\n🛑 Aborted: the kill switch was %s\n", s)
+					stopped.Store(s)
+					stop()
+					select {
+					case <-done:
+					case <-time.After(exitGrace):
+						style.Fprintf(os.Stderr, "🛑 Still running %s after the abort, exiting\n", exitGrace)
+						os.Exit(ExitCode)
+					}
+					return
+				}
+			}
+		}
+	}()
+
+	released := false
+	return func() {
+		if !released {
+			released = true
+			close(done)
+			os.Remove(pidPath)
+		}
+	}, nil
+}
+
+// alive reports whether a process exists. Signal 0 checks without
+// delivering anything; where it is unsupported, as on Windows, finding the
+// process is enough.
+func alive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	switch {
+	case err == nil, errors.Is(err, syscall.EPERM):
+		return true
+	case errors.Is(err, os.ErrProcessDone):
+		return false
+	}
+	return runtime.GOOS == "windows"
+}
+
+// currentUser names the operator, the invoking user under sudo
+func currentUser() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
@@ -0,0 +1,273 @@
+// Package audit keeps a tamper-evident log of the operations NetCrate runs.
+// Each entry is a JSON line carrying the hash of the previous one, so an
+// edited, reordered or removed entry breaks the chain.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Budget is the traffic an operation was allowed to send
+type Budget struct {
+	Profile     string `json:"rate_profile,omitempty"`
+	Rate        int    `json:"rate_pps,omitempty"`
+	Concurrency int    `json:"concurrency,omitempty"`
+	Ports       int    `json:"ports_per_host,omitempty"`
+	Packets     int    `json:"packets,omitempty"` // total, when known in advance
+}
+
+// Entry is one audited operation
+type Entry struct {
+	Seq           int       `json:"seq"`
+	Timestamp     time.Time `json:"timestamp"`
+	User          string    `json:"user"`
+	SudoUser      string    `json:"sudo_user,omitempty"`
+	Hostname      string    `json:"hostname,omitempty"`
+	Command       string    `json:"command"`
+	Args          []string  `json:"args,omitempty"`
+	SessionID     string    `json:"session_id,omitempty"`
+	Targets       []string  `json:"targets,omitempty"`
+	Budget        *Budget   `json:"budget,omitempty"`
+	Decision      string    `json:"decision,omitempty"` // compliance decision: allowed or blocked
+	Reason        string    `json:"reason,omitempty"`
+	Policy        string    `json:"policy,omitempty"`         // compliance policy level
+	Authorization string    `json:"authorization,omitempty"`  // of the engagement scope in force
+	AuthorizedBy  string    `json:"authorized_by,omitempty"`  // who authorized the operation
+	Ticket        string    `json:"ticket,omitempty"`         // ticket or change number of the authorization
+	OutsideWindow bool      `json:"outside_window,omitempty"` // run outside the permitted scan windows
+	PrevHash      string    `json:"prev_hash"`
+	Hash          string    `json:"hash"`
+}
+
+// Path returns the audit log path
+func Path() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".netcrate", "audit.log"), nil
+}
+
+// Record appends an entry to the audit log, filling in the sequence
+// number, time, operator, command line and chain hashes
+func Record(entry Entry) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	unlock, err := lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	last, err := lastEntry(path)
+	if err != nil {
+		return err
+	}
+
+	entry.Seq = 1
+	if last != nil {
+		entry.Seq = last.Seq + 1
+		entry.PrevHash = last.Hash
+	}
+	entry.Timestamp = time.Now().UTC()
+	entry.User, entry.SudoUser = operator()
+	entry.Hostname, _ = os.Hostname()
+	if entry.Args == nil && len(os.Args) > 1 {
+		entry.Args = redactArgs(os.Args[1:])
+	}
+	entry.Hash = entry.computeHash()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return file.Sync()
+}
+
+// Read returns the entries of the audit log, oldest first
+func Read() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	err = scan(path, func(line int, data []byte) error {
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// VerifyError locates the first entry that breaks the chain
+type VerifyError struct {
+	Line   int
+	Reason string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// Verify walks the chain and returns the number of entries and the hash of
+// the last one. An altered, inserted or removed entry yields a *VerifyError;
+// entries cut from the end of the log cannot be detected from the log alone,
+// so compare the returned head against one recorded elsewhere.
+func Verify() (int, string, error) {
+	path, err := Path()
+	if err != nil {
+		return 0, "", err
+	}
+	count, head := 0, ""
+	err = scan(path, func(line int, data []byte) error {
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return &VerifyError{Line: line, Reason: "not a valid entry: " + err.Error()}
+		}
+		switch {
+		case entry.Seq != count+1:
+			return &VerifyError{Line: line, Reason: fmt.Sprintf("sequence %d, expected %d (entry removed or inserted)", entry.Seq, count+1)}
+		case entry.PrevHash != head:
+			return &VerifyError{Line: line, Reason: "previous hash does not match the entry before it"}
+		case entry.Hash != entry.computeHash():
+			return &VerifyError{Line: line, Reason: "hash does not match the entry (entry modified)"}
+		}
+		count, head = count+1, entry.Hash
+		return nil
+	})
+	return count, head, err
+}
+
+// computeHash hashes the entry without its own hash
+func (e Entry) computeHash() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// scan calls fn for each non-empty line of the log. A missing log is empty.
+func scan(path string, fn func(line int, data []byte) error) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		if err := fn(line, scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// lastEntry returns the newest entry, or nil for an empty log
+func lastEntry(path string) (*Entry, error) {
+	var last []byte
+	err := scan(path, func(_ int, data []byte) error {
+		last = append(last[:0], data...)
+		return nil
+	})
+	if err != nil || last == nil {
+		return nil, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(last, &entry); err != nil {
+		return nil, fmt.Errorf("audit log is corrupt (see netcrate audit verify): %v", err)
+	}
+	return &entry, nil
+}
+
+// lock serializes writers with a lock file next to the log. A lock older
+// than staleLock is left over from a crashed process and is taken over.
+func lock(path string) (func(), error) {
+	const staleLock = 10 * time.Second
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("audit log is locked by another process (%s)", lockPath)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// operator returns the user running NetCrate and, under sudo, the user who
+// invoked sudo
+func operator() (string, string) {
+	name := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	return name, os.Getenv("SUDO_USER")
+}
+
+// redactArgs hides passwords in URLs on the command line, such as those of
+// --proxy
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = arg
+		start := strings.Index(arg, "://")
+		if start < 0 {
+			continue
+		}
+		// Keep a leading flag or key=, e.g. --proxy=http://u:p@host
+		prefixEnd := strings.LastIndexAny(arg[:start], "= ") + 1
+		u, err := url.Parse(arg[prefixEnd:])
+		if err != nil || u.User == nil {
+			continue
+		}
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "xxxxx")
+			redacted[i] = arg[:prefixEnd] + u.String()
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,80 @@
+package compliance
+
+import "fmt"
+
+// Blocklist rule sources
+const (
+	SourceBuiltin = "built-in"
+	SourceConfig  = "compliance.blocked_ranges"
+)
+
+// BlocklistRule is a network that is never scanned, whatever the policy
+// level, --dangerous or the engagement scope
+type BlocklistRule struct {
+	Network string `json:"network"` // IP or CIDR
+	Name    string `json:"name,omitempty"`
+	Source  string `json:"source"` // built-in or compliance.blocked_ranges
+}
+
+func (r BlocklistRule) String() string {
+	if r.Name == "" {
+		return fmt.Sprintf("%s (%s)", r.Network, r.Source)
+	}
+	return fmt.Sprintf("%s (%s, %s)", r.Network, r.Name, r.Source)
+}
+
+// builtinBlocklist lists networks that no engagement should probe: cloud
+// metadata services, which hand out instance credentials, multicast and
+// reserved space, and networks of the US Department of Defense
+var builtinBlocklist = []BlocklistRule{
+	{Network: "169.254.169.254/32", Name: "cloud instance metadata service"},
+	{Network: "fd00:ec2::254/128", Name: "AWS instance metadata service"},
+	{Network: "100.100.100.200/32", Name: "Alibaba Cloud metadata service"},
+	{Network: "0.0.0.0/8", Name: "\"this network\""},
+	{Network: "224.0.0.0/4", Name: "IPv4 multicast"},
+	{Network: "ff00::/8", Name: "IPv6 multicast"},
+	{Network: "240.0.0.0/4", Name: "reserved and limited broadcast"},
+	{Network: "6.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "7.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "11.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "21.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "22.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "26.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "28.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "29.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "30.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "33.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "55.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "214.0.0.0/8", Name: "US Department of Defense"},
+	{Network: "215.0.0.0/8", Name: "US Department of Defense"},
+}
+
+// Blocklist returns the built-in rules followed by the blocked ranges of the
+// config
+func Blocklist(blockedRanges []string) []BlocklistRule {
+	rules := make([]BlocklistRule, 0, len(builtinBlocklist)+len(blockedRanges))
+	for _, rule := range builtinBlocklist {
+		rule.Source = SourceBuiltin
+		rules = append(rules, rule)
+	}
+	for _, network := range blockedRanges {
+		rules = append(rules, BlocklistRule{Network: network, Source: SourceConfig})
+	}
+	return rules
+}
+
+// matchBlocklist returns the first rule whose network overlaps host
+func matchBlocklist(host string, rules []BlocklistRule) (BlocklistRule, bool) {
+	networks := make([]string, len(rules))
+	for i, rule := range rules {
+		networks[i] = rule.Network
+	}
+	if network, ok := blockingRange(host, networks); ok {
+		for _, rule := range rules {
+			if rule.Network == network {
+				return rule, true
+			}
+		}
+	}
+	return BlocklistRule{}, false
+}
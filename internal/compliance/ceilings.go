@@ -0,0 +1,70 @@
+package compliance
+
+import (
+	"fmt"
+
+	"github.com/netcrate/netcrate/internal/config"
+)
+
+// Ceilings are the hard limits the compliance policy puts on one run.
+// Options above a ceiling are lowered to it, whatever the rate profile,
+// network override or flag asked for. Zero leaves a limit unset.
+type Ceilings struct {
+	Rate         int // packets per second
+	Concurrency  int // concurrent workers
+	PortsPerHost int // ports probed on each host
+}
+
+// Clamp records an option lowered to a ceiling
+type Clamp struct {
+	Option    string `json:"option"`    // rate, concurrency or ports_per_host
+	Requested int    `json:"requested"` // 0 when the option had no limit
+	Ceiling   int    `json:"ceiling"`
+}
+
+func (c Clamp) String() string {
+	if c.Requested == 0 {
+		return fmt.Sprintf("%s limited to %d", c.Option, c.Ceiling)
+	}
+	return fmt.Sprintf("%s lowered from %d to %d", c.Option, c.Requested, c.Ceiling)
+}
+
+// CeilingsOf returns the ceilings of a compliance policy
+func CeilingsOf(policy config.ComplianceConfig) Ceilings {
+	return Ceilings{
+		Rate:         policy.MaxRate,
+		Concurrency:  policy.MaxConcurrency,
+		PortsPerHost: policy.MaxPortsPerHost,
+	}
+}
+
+// LoadCeilings returns the ceilings of the effective config, none when it
+// cannot be loaded
+func LoadCeilings() Ceilings {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return Ceilings{}
+	}
+	return CeilingsOf(cm.GetConfig().Compliance)
+}
+
+// Apply lowers the options above a ceiling and returns what it lowered.
+// Ports beyond the ceiling are dropped from the end of the list, so port
+// sets ordered by popularity keep their most common ports. Nil options are
+// left alone.
+func (c Ceilings) Apply(rate, concurrency *int, ports *[]int) []Clamp {
+	var clamps []Clamp
+	if c.Rate > 0 && rate != nil && *rate > c.Rate {
+		clamps = append(clamps, Clamp{Option: "rate", Requested: *rate, Ceiling: c.Rate})
+		*rate = c.Rate
+	}
+	if c.Concurrency > 0 && concurrency != nil && *concurrency > c.Concurrency {
+		clamps = append(clamps, Clamp{Option: "concurrency", Requested: *concurrency, Ceiling: c.Concurrency})
+		*concurrency = c.Concurrency
+	}
+	if c.PortsPerHost > 0 && ports != nil && len(*ports) > c.PortsPerHost {
+		clamps = append(clamps, Clamp{Option: "ports_per_host", Requested: len(*ports), Ceiling: c.PortsPerHost})
+		*ports = (*ports)[:c.PortsPerHost]
+	}
+	return clamps
+}
@@ -37,8 +37,7 @@ func (c *Checker) CheckRate(rate int) error {
 
 // IsPrivateIP checks if an IP is in private ranges
 func IsPrivateIP(ip net.IP) bool {
-	// TODO: Implement private IP checking
-	return false
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast()
 }
 
 // GetDefaultPolicy returns the default compliance policy
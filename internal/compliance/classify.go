@@ -0,0 +1,66 @@
+package compliance
+
+import (
+	"fmt"
+	"net"
+)
+
+// Classes of a result host
+const (
+	ClassPrivate = "private"
+	ClassPublic  = "public"
+	ClassBlocked = "blocked" // on the blocklist or outside the allowed ranges or scope
+)
+
+// privateRanges name the networks IsPrivateIP accepts
+var privateRanges = []struct {
+	network string
+	name    string
+}{
+	{"10.0.0.0/8", "RFC 1918 10.0.0.0/8"},
+	{"172.16.0.0/12", "RFC 1918 172.16.0.0/12"},
+	{"192.168.0.0/16", "RFC 1918 192.168.0.0/16"},
+	{"127.0.0.0/8", "loopback 127.0.0.0/8"},
+	{"169.254.0.0/16", "link-local 169.254.0.0/16"},
+	{"fc00::/7", "unique local fc00::/7"},
+	{"::1/128", "loopback ::1"},
+	{"fe80::/10", "link-local fe80::/10"},
+}
+
+// Classify returns how the policy sees one host of a run's results, and
+// the rule that decided it. Hosts are normally addresses; hostnames are
+// classified by the addresses they resolve to.
+func (cc *ComplianceChecker) Classify(host string) (class, rule string) {
+	if rule, ok := matchBlocklist(host, Blocklist(cc.policy.BlockedRanges)); ok {
+		return ClassBlocked, rule.String()
+	}
+	if len(cc.policy.AllowedRanges) > 0 && !withinRanges(host, cc.policy.AllowedRanges) {
+		return ClassBlocked, "outside compliance.allowed_ranges"
+	}
+	if cc.scope != nil && !cc.scope.Contains(host) {
+		return ClassBlocked, fmt.Sprintf("outside scope %s", cc.scope.path)
+	}
+
+	private, _ := isPrivateTarget(host)
+	if private {
+		return ClassPrivate, privateRange(host)
+	}
+	if entry, ok := blockingRange(host, cc.policy.AllowedRanges); ok {
+		return ClassPublic, "compliance.allowed_ranges " + entry
+	}
+	return ClassPublic, ""
+}
+
+// privateRange names the private network of host, "" when there is none
+func privateRange(host string) string {
+	addresses := targetAddresses(host)
+	if len(addresses) == 0 {
+		return ""
+	}
+	for _, r := range privateRanges {
+		if _, network, err := net.ParseCIDR(r.network); err == nil && network.Contains(addresses[0]) {
+			return r.name
+		}
+	}
+	return ""
+}
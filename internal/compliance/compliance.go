@@ -0,0 +1,801 @@
+package compliance
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/audit"
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/style"
+)
+
+// Check statuses
+const (
+	StatusAllowed = "allowed"
+	StatusBlocked = "blocked"
+)
+
+// AutoDetect is the target of a run that picks its network itself, such as
+// ops discover with --targets auto, when the network is not known in
+// advance. It is refused while an engagement scope is in force.
+const AutoDetect = "auto-detect"
+
+// MaxTargetAddresses is the most addresses the targets of one run may expand
+// to; the scanners stop expanding a CIDR at this size. compliance.max_targets
+// can only lower it.
+const MaxTargetAddresses = 65536
+
+// ownerLookupTimeout bounds each RDAP query of the consent prompt
+const ownerLookupTimeout = 5 * time.Second
+
+// ComplianceResult records one compliance check
+type ComplianceResult struct {
+	Timestamp          time.Time       `json:"timestamp"`
+	SessionID          string          `json:"session_id"`
+	TemplateName       string          `json:"template_name"`
+	Command            string          `json:"command"`
+	Targets            []string        `json:"targets"`
+	Addresses          string          `json:"addresses,omitempty"` // the targets expand to, hostnames counted by resolved address
+	PublicTargets      []string        `json:"public_targets"`
+	PrivateTargets     []string        `json:"private_targets"`
+	OutOfScope         []string        `json:"out_of_scope,omitempty"`
+	PolicyLevel        string          `json:"policy_level"`
+	PolicyOverride     bool            `json:"policy_override,omitempty"` // level set for this run with --policy
+	OutsideWindow      bool            `json:"outside_window,omitempty"`  // run outside the scan windows with --outside-window
+	DangerousFlag      bool            `json:"dangerous_flag"`
+	UserConfirmation   bool            `json:"user_confirmation"`
+	ConfirmationPhrase string          `json:"confirmation_phrase,omitempty"` // typed to confirm public targets
+	Status             string          `json:"status"`
+	BlockReason        string          `json:"block_reason,omitempty"`
+	RiskLevel          string          `json:"risk_level"` // low, medium or high
+	Warnings           []string        `json:"warnings,omitempty"`
+	Scope              *ScopeReference `json:"scope,omitempty"`
+	AuthorizedBy       string          `json:"authorized_by,omitempty"`
+	Ticket             string          `json:"ticket,omitempty"`
+	Clamped            []Clamp         `json:"clamped,omitempty"` // options lowered to the policy ceilings
+}
+
+// RunAuthorization records who authorized a run and under which ticket or
+// change number
+type RunAuthorization struct {
+	AuthorizedBy string `json:"authorized_by,omitempty"`
+	Ticket       string `json:"ticket,omitempty"`
+}
+
+// IsZero reports whether nothing was recorded
+func (a RunAuthorization) IsZero() bool {
+	return a.AuthorizedBy == "" && a.Ticket == ""
+}
+
+func (a RunAuthorization) String() string {
+	switch {
+	case a.Ticket == "":
+		return a.AuthorizedBy
+	case a.AuthorizedBy == "":
+		return "ticket " + a.Ticket
+	}
+	return fmt.Sprintf("%s (ticket %s)", a.AuthorizedBy, a.Ticket)
+}
+
+// ComplianceSummary totals the recorded checks
+type ComplianceSummary struct {
+	TotalChecks    int    `json:"total_checks"`
+	AllowedScans   int    `json:"allowed_scans"`
+	BlockedScans   int    `json:"blocked_scans"`
+	PublicTargets  int    `json:"public_targets"`
+	PrivateTargets int    `json:"private_targets"`
+	LastCheck      string `json:"last_check,omitempty"`
+}
+
+// ComplianceChecker checks the targets of a run against the compliance
+// policy of the config and the engagement scope, and records every check in
+// ~/.netcrate/compliance/compliance.json and the audit log
+type ComplianceChecker struct {
+	Budget        *audit.Budget    // traffic budget of the checked run, for the audit log
+	Authorization RunAuthorization // who authorized the checked run, by default that of the config
+	Clamped       []Clamp          // options of the checked run lowered to the ceilings
+
+	policy        config.ComplianceConfig
+	level         string
+	profileLevel  string // level the config profile asks for, which needs the policy token
+	overridden    bool
+	outsideWindow bool
+	scope         *Scope
+	scopeErr      error
+	logPath       string
+	confirmIn     *bufio.Reader
+	preview       bool // evaluating for a dry run: never prompt
+}
+
+// NewComplianceChecker creates a checker for the effective config. A scope
+// file that cannot be loaded blocks every check rather than failing here,
+// so that read-only commands keep working.
+func NewComplianceChecker() (*ComplianceChecker, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load compliance policy: %w", err)
+	}
+
+	checker := &ComplianceChecker{
+		Authorization: RunAuthorization{
+			AuthorizedBy: cm.GetConfig().Compliance.AuthorizedBy,
+			Ticket:       cm.GetConfig().Compliance.Ticket,
+		},
+		policy:       cm.GetConfig().Compliance,
+		level:        cm.GetConfig().Compliance.EffectiveLevel(),
+		profileLevel: cm.HeldComplianceLevel(),
+		logPath:      filepath.Join(homeDir, ".netcrate", "compliance", "compliance.json"),
+		confirmIn:    bufio.NewReader(os.Stdin),
+	}
+	if path := checker.policy.EffectiveScopeFile(); path != "" {
+		checker.scope, checker.scopeErr = LoadScope(path)
+	}
+	return checker, nil
+}
+
+// Level returns the policy level checks are made at
+func (cc *ComplianceChecker) Level() string {
+	return cc.level
+}
+
+// ProfileLevel returns the level the config profile asks for when it would
+// leave the strict level, which takes OverrideLevel with the policy token;
+// "" otherwise
+func (cc *ComplianceChecker) ProfileLevel() string {
+	return cc.profileLevel
+}
+
+// OverrideLevel sets the policy level for the checks of one run. Leaving the
+// strict level takes the organization's policy token; any other change,
+// including to strict, does not.
+func (cc *ComplianceChecker) OverrideLevel(level, token string) error {
+	known := false
+	for _, l := range config.PolicyLevels {
+		known = known || l == level
+	}
+	if !known {
+		return fmt.Errorf("unknown policy level %q (expected %s)", level, strings.Join(config.PolicyLevels, ", "))
+	}
+	if cc.level == config.PolicyStrict && level != config.PolicyStrict {
+		switch {
+		case cc.policy.PolicyTokenHash == "":
+			return fmt.Errorf("the strict policy cannot be overridden: no policy token is configured")
+		case token == "":
+			return fmt.Errorf("overriding the strict policy requires --policy-token")
+		case subtle.ConstantTimeCompare([]byte(config.HashPolicyToken(token)), []byte(cc.policy.PolicyTokenHash)) != 1:
+			return fmt.Errorf("invalid policy token")
+		}
+	}
+	cc.overridden = level != cc.level
+	cc.level = level
+	return nil
+}
+
+// AllowOutsideWindow lets the checked run go ahead outside the scan windows
+// of the policy. Such runs are flagged in the compliance and audit logs.
+func (cc *ComplianceChecker) AllowOutsideWindow() {
+	cc.outsideWindow = true
+}
+
+// Scope returns the engagement scope in force, or nil
+func (cc *ComplianceChecker) Scope() *Scope {
+	return cc.scope
+}
+
+// CheckCompliance checks the targets of a run. Targets outside the
+// engagement scope or in a blocked range are always refused. Public targets
+// are refused at the strict level, need --dangerous and a typed confirmation
+// at the standard level and only --dangerous at the permissive one. A
+// blocked check returns the result together with an error matching
+// ErrBlocked.
+func (cc *ComplianceChecker) CheckCompliance(sessionID, templateName, command string, targets []string, dangerous bool) (*ComplianceResult, error) {
+	result := &ComplianceResult{
+		Timestamp:      time.Now(),
+		SessionID:      sessionID,
+		TemplateName:   templateName,
+		Command:        command,
+		Targets:        targets,
+		PublicTargets:  make([]string, 0),
+		PrivateTargets: make([]string, 0),
+		DangerousFlag:  dangerous,
+		Status:         StatusAllowed,
+		RiskLevel:      "low",
+		PolicyLevel:    cc.level,
+		PolicyOverride: cc.overridden,
+		AuthorizedBy:   cc.Authorization.AuthorizedBy,
+		Ticket:         cc.Authorization.Ticket,
+		Clamped:        cc.Clamped,
+	}
+	if cc.scope != nil {
+		result.Scope = cc.scope.Reference()
+	}
+
+	if err := cc.evaluate(result); err != nil {
+		result.Status = StatusBlocked
+		result.BlockReason = err.Error()
+	}
+	if logErr := cc.record(result); logErr != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("compliance log not written: %v", logErr))
+	}
+	if auditErr := cc.audit(result); auditErr != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("audit log not written: %v", auditErr))
+	}
+	if result.Status == StatusBlocked {
+		return result, blockedError(result.BlockReason)
+	}
+	return result, nil
+}
+
+// Preview evaluates a run like CheckCompliance without recording it or asking
+// for confirmation, for dry runs. Confirmations the run would ask for are
+// listed in the warnings.
+func (cc *ComplianceChecker) Preview(command string, targets []string, dangerous bool) *ComplianceResult {
+	result := &ComplianceResult{
+		Timestamp:      time.Now(),
+		Command:        command,
+		Targets:        targets,
+		PublicTargets:  make([]string, 0),
+		PrivateTargets: make([]string, 0),
+		DangerousFlag:  dangerous,
+		Status:         StatusAllowed,
+		RiskLevel:      "low",
+		PolicyLevel:    cc.level,
+		PolicyOverride: cc.overridden,
+		AuthorizedBy:   cc.Authorization.AuthorizedBy,
+		Ticket:         cc.Authorization.Ticket,
+		Clamped:        cc.Clamped,
+	}
+	if cc.scope != nil {
+		result.Scope = cc.scope.Reference()
+	}
+
+	cc.preview = true
+	defer func() { cc.preview = false }()
+	if err := cc.evaluate(result); err != nil {
+		result.Status = StatusBlocked
+		result.BlockReason = err.Error()
+	}
+	return result
+}
+
+// ErrBlocked matches the error of a refused check with errors.Is, also when
+// a caller has wrapped it
+var ErrBlocked = errors.New("blocked by compliance rules")
+
+// Blocked returns an error matching ErrBlocked for a run refused outside
+// the checker, such as quick mode's own refusal of public networks
+func Blocked(reason string) error {
+	return blockedError(reason)
+}
+
+// blockedError is the error of a refused check, reading as the block reason
+type blockedError string
+
+func (e blockedError) Error() string { return string(e) }
+
+func (e blockedError) Is(target error) bool { return target == ErrBlocked }
+
+// evaluate applies the policy to result, returning why the run is blocked
+func (cc *ComplianceChecker) evaluate(result *ComplianceResult) error {
+	if cc.scopeErr != nil {
+		return fmt.Errorf("engagement scope: %v", cc.scopeErr)
+	}
+	if cc.scope != nil {
+		if err := cc.scope.CheckWindow(result.Timestamp); err != nil {
+			return err
+		}
+	}
+	if !inWindows(result.Timestamp, cc.policy.Windows) {
+		if !cc.outsideWindow {
+			return fmt.Errorf("outside the permitted scan windows (%s); use --outside-window to run anyway", describeWindows(cc.policy.Windows))
+		}
+		result.OutsideWindow = true
+		result.Warnings = append(result.Warnings, "running outside the permitted scan windows")
+	}
+
+	total := new(big.Int)
+	for _, target := range result.Targets {
+		if target == AutoDetect {
+			if cc.scope != nil {
+				return fmt.Errorf("the auto-detected network cannot be checked against engagement scope %s; give the targets explicitly", cc.scope.path)
+			}
+			result.PrivateTargets = append(result.PrivateTargets, target)
+			continue
+		}
+		host := targetHost(target)
+		if host == "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("could not interpret target %q", target))
+			continue
+		}
+
+		if rule, ok := matchBlocklist(host, Blocklist(cc.policy.BlockedRanges)); ok {
+			return fmt.Errorf("target %s is on the never-scan blocklist: %s", target, rule)
+		}
+		total.Add(total, addressCount(host))
+
+		if cc.scope != nil && !cc.scope.Contains(host) {
+			result.OutOfScope = append(result.OutOfScope, target)
+		}
+		if len(cc.policy.AllowedRanges) > 0 && !withinRanges(host, cc.policy.AllowedRanges) {
+			return fmt.Errorf("target %s is outside the allowed ranges", target)
+		}
+
+		private, resolved := isPrivateTarget(host)
+		if !resolved {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("could not resolve %s; treating it as public", host))
+		}
+		if private {
+			result.PrivateTargets = append(result.PrivateTargets, target)
+		} else {
+			result.PublicTargets = append(result.PublicTargets, target)
+		}
+	}
+
+	if total.Sign() > 0 {
+		result.Addresses = total.String()
+	}
+	if limit, setting := cc.targetLimit(); total.Cmp(big.NewInt(int64(limit))) > 0 {
+		return fmt.Errorf("targets expand to %s addresses, more than the limit of %d%s", total, limit, setting)
+	}
+	if len(result.OutOfScope) > 0 {
+		return fmt.Errorf("targets outside engagement scope %s: %s", cc.scope.path, strings.Join(result.OutOfScope, ", "))
+	}
+
+	if len(result.PublicTargets) > 0 {
+		result.RiskLevel = "high"
+		switch {
+		case cc.level == config.PolicyStrict:
+			return fmt.Errorf("the strict compliance policy allows private targets only")
+		case !result.DangerousFlag:
+			return fmt.Errorf("public network targets require --dangerous flag")
+		case cc.level == config.PolicyStandard && !cc.policy.AllowPublic && cc.preview:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("the run asks to type '%s' before scanning public targets", confirmationPhrase(result.PublicTargets)))
+		case cc.level == config.PolicyStandard && !cc.policy.AllowPublic:
+			if !cc.confirmPublic(result) {
+				return fmt.Errorf("user denied confirmation for public network scan")
+			}
+			result.UserConfirmation = true
+		}
+	} else if result.DangerousFlag {
+		result.RiskLevel = "medium"
+	}
+
+	if (cc.policy.RequireConfirmation || cc.level == config.PolicyStrict) && !result.UserConfirmation {
+		if cc.preview {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("the %s policy asks for confirmation before every run", cc.level))
+			return nil
+		}
+		fmt.Printf("\nCompliance policy (%s) requires confirmation for every run.\n", cc.level)
+		fmt.Printf("Command: %s\nTargets: %s\n", result.Command, strings.Join(result.Targets, ", "))
+		if !cc.confirm("Proceed? [y/N]: ", "y", "yes") {
+			return fmt.Errorf("run not confirmed")
+		}
+		result.UserConfirmation = true
+	}
+	return nil
+}
+
+// targetLimit returns the most addresses the targets of a run may expand
+// to, naming the setting when the policy lowers the built-in limit
+func (cc *ComplianceChecker) targetLimit() (int, string) {
+	if cc.policy.MaxTargets > 0 && cc.policy.MaxTargets < MaxTargetAddresses {
+		return cc.policy.MaxTargets, " (compliance.max_targets)"
+	}
+	return MaxTargetAddresses, ""
+}
+
+// confirmPublic previews the public targets and asks for a typed
+// confirmation phrase naming them
+func (cc *ComplianceChecker) confirmPublic(result *ComplianceResult) bool {
+	style.Printf("\n⚠️  COMPLIANCE WARNING ⚠️\n")
+	fmt.Printf("==========================================\n")
+	fmt.Printf("You are about to scan PUBLIC NETWORK targets:\n")
+	for _, target := range result.PublicTargets {
+		fmt.Printf("  • %s\n", cc.previewTarget(target))
+	}
+	style.Printf("\n🚨 IMPORTANT SECURITY NOTICE:\n")
+	fmt.Printf("• Only scan networks you own or have explicit permission to test\n")
+	fmt.Printf("• Unauthorized scanning may violate laws and policies\n")
+	fmt.Printf("\nCommand: %s\n", result.Command)
+	fmt.Printf("Template: %s\n", result.TemplateName)
+	fmt.Printf("Risk Level: %s\n", result.RiskLevel)
+	if result.Scope != nil {
+		fmt.Printf("Authorization: %s\n", result.Scope.Authorization)
+	}
+	phrase := confirmationPhrase(result.PublicTargets)
+	if !cc.confirm(style.Sprintf("\n⚠️  Type '%s' to proceed, or anything else to abort: ", phrase), phrase) {
+		return false
+	}
+	result.ConfirmationPhrase = phrase
+	return true
+}
+
+// confirmationPhrase is what must be typed to scan public targets. It names
+// the target, or how many there are, so that it cannot be typed by reflex.
+func confirmationPhrase(targets []string) string {
+	if len(targets) == 1 {
+		return "scan " + targets[0]
+	}
+	return fmt.Sprintf("scan %d public targets", len(targets))
+}
+
+// previewTarget describes what a public target resolves to and, when
+// compliance.lookup_owner is set, who holds it
+func (cc *ComplianceChecker) previewTarget(target string) string {
+	host := targetHost(target)
+	var addresses []net.IP
+	preview := target
+	if first, last, ok := span(host); ok {
+		addresses = []net.IP{first}
+		if !first.Equal(last) {
+			preview = fmt.Sprintf("%s → %s - %s (%s addresses)", target, first, last, addressCount(host))
+		}
+	} else {
+		addresses, _ = net.LookupIP(host)
+		resolved := make([]string, len(addresses))
+		for i, ip := range addresses {
+			resolved[i] = ip.String()
+		}
+		if len(resolved) == 0 {
+			resolved = []string{"unresolved"}
+		}
+		preview = fmt.Sprintf("%s → %s", target, strings.Join(resolved, ", "))
+	}
+
+	if cc.policy.LookupOwner && len(addresses) > 0 {
+		owner, err := LookupOwner(addresses[0].String(), ownerLookupTimeout)
+		if err != nil {
+			owner = "owner unknown: " + err.Error()
+		}
+		preview += " [" + owner + "]"
+	}
+	return preview
+}
+
+// confirm prompts and reports whether the answer is one of accepted. No
+// input, as in scripts, is a refusal.
+func (cc *ComplianceChecker) confirm(prompt string, accepted ...string) bool {
+	fmt.Print(prompt)
+	answer, _ := cc.confirmIn.ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	for _, a := range accepted {
+		if answer == a {
+			return true
+		}
+	}
+	return false
+}
+
+// record appends a check to the compliance log
+func (cc *ComplianceChecker) record(result *ComplianceResult) error {
+	if err := os.MkdirAll(filepath.Dir(cc.logPath), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(cc.logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// audit records a check and its decision in the audit log
+func (cc *ComplianceChecker) audit(result *ComplianceResult) error {
+	entry := audit.Entry{
+		Command:       result.Command,
+		SessionID:     result.SessionID,
+		Targets:       result.Targets,
+		Budget:        cc.Budget,
+		Decision:      result.Status,
+		Reason:        result.BlockReason,
+		Policy:        result.PolicyLevel,
+		AuthorizedBy:  result.AuthorizedBy,
+		Ticket:        result.Ticket,
+		OutsideWindow: result.OutsideWindow,
+	}
+	if result.PolicyOverride {
+		entry.Policy += " (override)"
+	}
+	if result.Scope != nil {
+		entry.Authorization = result.Scope.Authorization
+	}
+	return audit.Record(entry)
+}
+
+// History returns the recorded checks, oldest first
+func (cc *ComplianceChecker) History() ([]ComplianceResult, error) {
+	file, err := os.Open(cc.logPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var results []ComplianceResult
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var result ComplianceResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			continue
+		}
+		results = append(results, result)
+	}
+	return results, scanner.Err()
+}
+
+// GetComplianceSummary totals the recorded checks
+func (cc *ComplianceChecker) GetComplianceSummary() (*ComplianceSummary, error) {
+	results, err := cc.History()
+	if err != nil {
+		return nil, err
+	}
+	summary := &ComplianceSummary{TotalChecks: len(results)}
+	for _, result := range results {
+		if result.Status == StatusBlocked {
+			summary.BlockedScans++
+		} else {
+			summary.AllowedScans++
+		}
+		summary.PublicTargets += len(result.PublicTargets)
+		summary.PrivateTargets += len(result.PrivateTargets)
+	}
+	if len(results) > 0 {
+		summary.LastCheck = results[len(results)-1].Timestamp.Format("2006-01-02 15:04:05")
+	}
+	return summary, nil
+}
+
+// targetParams are the template parameter names that hold targets
+var targetParams = []string{"target", "host", "cidr", "network", "endpoint", "url", "domain"}
+
+// ParseTargetsFromTemplate collects the targets in template parameters:
+// the values of parameters whose names mention a target, host, network or
+// URL, with comma-separated lists split
+func (cc *ComplianceChecker) ParseTargetsFromTemplate(params map[string]interface{}) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var targets []string
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		isTarget := false
+		for _, hint := range targetParams {
+			if strings.Contains(lower, hint) {
+				isTarget = true
+				break
+			}
+		}
+		if !isTarget {
+			continue
+		}
+
+		var values []string
+		switch value := params[name].(type) {
+		case string:
+			values = strings.Split(value, ",")
+		case []string:
+			values = value
+		case []interface{}:
+			for _, v := range value {
+				if s, ok := v.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+		for _, v := range values {
+			if v = strings.TrimSpace(v); v != "" {
+				targets = append(targets, v)
+			}
+		}
+	}
+	return targets
+}
+
+// targetHost reduces a target (IP, CIDR, IP range, host, host:port or URL)
+// to the IP, CIDR, range or hostname it reaches
+func targetHost(target string) string {
+	target = strings.TrimSpace(target)
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil {
+			return u.Hostname()
+		}
+		return ""
+	}
+	if _, _, ok := span(target); ok {
+		return target
+	}
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		return host
+	}
+	return strings.Trim(target, "[]")
+}
+
+// span returns the first and last address of an IP, a CIDR or an IP range
+// such as 192.168.1.10-50 or 10.0.0.1-10.0.0.9
+func span(host string) (net.IP, net.IP, bool) {
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		return ip, ip, true
+	}
+	if _, network, err := net.ParseCIDR(host); err == nil {
+		last := make(net.IP, len(network.IP))
+		for i := range network.IP {
+			last[i] = network.IP[i] | ^network.Mask[i]
+		}
+		return network.IP, last, true
+	}
+	parts := strings.Split(host, "-")
+	if len(parts) != 2 {
+		return nil, nil, false
+	}
+	first := net.ParseIP(parts[0]).To4()
+	if first == nil {
+		return nil, nil, false
+	}
+	last := net.ParseIP(parts[1]).To4()
+	if last == nil {
+		octets := strings.Split(parts[0], ".")
+		last = net.ParseIP(strings.Join(octets[:3], ".") + "." + parts[1]).To4()
+	}
+	if last == nil || bytes.Compare(first, last) > 0 {
+		return nil, nil, false
+	}
+	return first, last, true
+}
+
+// addressCount returns how many addresses a target expands to. A hostname
+// counts once per address it resolves to.
+func addressCount(host string) *big.Int {
+	first, last, ok := span(host)
+	if !ok {
+		addresses, _ := net.LookupIP(host)
+		if len(addresses) == 0 {
+			return big.NewInt(1)
+		}
+		return big.NewInt(int64(len(addresses)))
+	}
+	count := new(big.Int).Sub(new(big.Int).SetBytes(last), new(big.Int).SetBytes(first))
+	return count.Add(count, big.NewInt(1))
+}
+
+// blockingRange returns the first IP or CIDR of ranges that overlaps host
+func blockingRange(host string, ranges []string) (string, bool) {
+	first, last, isSpan := span(host)
+	addresses := targetAddresses(host)
+	for _, entry := range ranges {
+		network, ok := parseNetwork(entry)
+		if !ok {
+			continue
+		}
+		if isSpan && len(first) == len(network.IP) &&
+			(network.Contains(first) || network.Contains(last) ||
+				(bytes.Compare(first, network.IP) <= 0 && bytes.Compare(network.IP, last) <= 0)) {
+			return entry, true
+		}
+		if isSpan {
+			continue
+		}
+		for _, ip := range addresses {
+			if network.Contains(ip) {
+				return entry, true
+			}
+		}
+	}
+	return "", false
+}
+
+// withinRanges reports whether every address of host lies in one of ranges.
+// A CIDR or IP range may be split across several ranges but must not reach
+// an address outside them.
+func withinRanges(host string, ranges []string) bool {
+	if first, last, ok := span(host); ok {
+		return spanCovered(first, last, ranges)
+	}
+	addresses := targetAddresses(host)
+	if len(addresses) == 0 {
+		return false
+	}
+	for _, ip := range addresses {
+		if !spanCovered(ip, ip, ranges) {
+			return false
+		}
+	}
+	return true
+}
+
+// spanCovered reports whether the ranges together cover every address from
+// first to last. It walks the span from first, each time skipping to the end
+// of a range containing the current address.
+func spanCovered(first, last net.IP, ranges []string) bool {
+	if ip4 := first.To4(); ip4 != nil {
+		first = ip4
+	}
+	if ip4 := last.To4(); ip4 != nil {
+		last = ip4
+	}
+	current := new(big.Int).SetBytes(first)
+	end := new(big.Int).SetBytes(last)
+	for {
+		next := -1
+		for i, entry := range ranges {
+			network, ok := parseNetwork(entry)
+			if ok && len(network.IP) == len(first) && network.Contains(ipFromInt(current, len(first))) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return false
+		}
+		network, _ := parseNetwork(ranges[next])
+		_, networkLast, _ := span(network.String())
+		current.SetBytes(networkLast)
+		if current.Cmp(end) >= 0 {
+			return true
+		}
+		current.Add(current, big.NewInt(1))
+	}
+}
+
+// ipFromInt returns n as an IP of size bytes
+func ipFromInt(n *big.Int, size int) net.IP {
+	return n.FillBytes(make([]byte, size))
+}
+
+// targetAddresses returns the addresses a host reaches: the IP itself, the
+// first and last address of a CIDR or range, or the addresses a hostname
+// resolves to
+func targetAddresses(host string) []net.IP {
+	if first, last, ok := span(host); ok {
+		return []net.IP{first, last}
+	}
+	addresses, _ := net.LookupIP(host)
+	return addresses
+}
+
+// isPrivateTarget reports whether every address of host is private, and
+// whether host could be resolved at all
+func isPrivateTarget(host string) (private, resolved bool) {
+	if strings.EqualFold(host, "localhost") {
+		return true, true
+	}
+	addresses := targetAddresses(host)
+	if len(addresses) == 0 {
+		return false, false
+	}
+	for _, ip := range addresses {
+		if !IsPrivateIP(ip) {
+			return false, true
+		}
+	}
+	return true, true
+}
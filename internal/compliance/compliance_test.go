@@ -0,0 +1,63 @@
+package compliance
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithinRanges(t *testing.T) {
+	ranges := []string{"10.0.0.0/25", "10.0.0.128/26", "10.0.1.0/24", "192.168.1.10", "fd00::/64"}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"10.0.0.5", true},
+		{"10.0.0.200", false},
+		{"10.0.0.0/25", true},
+		{"10.0.0.0/24", false},          // .192-.255 are not covered
+		{"10.0.0.0-10.0.0.191", true},   // split across two ranges
+		{"10.0.0.100-10.0.1.20", false}, // straddles the hole at .192-.255
+		{"10.0.1.0-10.0.1.255", true},
+		{"192.168.1.10", true},
+		{"192.168.1.9-11", false},
+		{"fd00::1", true},
+		{"fd00::/63", false},
+		{"fd00::/64", true},
+	}
+	for _, tt := range tests {
+		if got := withinRanges(tt.host, ranges); got != tt.want {
+			t.Errorf("withinRanges(%s) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestAutoDetectWithScope(t *testing.T) {
+	scope := &Scope{Authorization: "SOW-1", Allowed: []string{"10.0.0.0/24"}, path: "scope.yaml"}
+	if err := scope.parse(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		scope   *Scope
+		targets []string
+		blocked string
+	}{
+		{"auto without scope", nil, []string{AutoDetect}, ""},
+		{"auto with scope", scope, []string{AutoDetect}, "cannot be checked against engagement scope"},
+		{"detected network in scope", scope, []string{"10.0.0.0/24"}, ""},
+		{"detected network outside scope", scope, []string{"10.0.5.0/24"}, "outside engagement scope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := &ComplianceChecker{scope: tt.scope, preview: true}
+			err := cc.evaluate(&ComplianceResult{Timestamp: time.Now(), Targets: tt.targets})
+			switch {
+			case tt.blocked == "" && err != nil:
+				t.Errorf("evaluate = %v, want allowed", err)
+			case tt.blocked != "" && (err == nil || !strings.Contains(err.Error(), tt.blocked)):
+				t.Errorf("evaluate = %v, want blocked with %q", err, tt.blocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/transport"
+)
+
+// rdapService answers RDAP queries for any address by redirecting to the
+// registry that holds it
+const rdapService = "https://rdap.org/ip/"
+
+// rdapNetwork is the part of an RDAP IP network response the consent
+// prompt shows
+type rdapNetwork struct {
+	Name     string       `json:"name"`
+	Handle   string       `json:"handle"`
+	Country  string       `json:"country"`
+	Entities []rdapEntity `json:"entities"`
+}
+
+type rdapEntity struct {
+	Roles      []string        `json:"roles"`
+	VCardArray json.RawMessage `json:"vcardArray"`
+}
+
+// LookupOwner asks RDAP which organization holds an address, for example
+// "EXAMPLE-NET (Example Inc, US)"
+func LookupOwner(ip string, timeout time.Duration) (string, error) {
+	client := &http.Client{Timeout: timeout, Transport: transport.HTTPTransport(nil)}
+	req, err := http.NewRequest(http.MethodGet, rdapService+ip, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("RDAP lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("RDAP lookup failed: %s", resp.Status)
+	}
+
+	var network rdapNetwork
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&network); err != nil {
+		return "", fmt.Errorf("RDAP lookup failed: %w", err)
+	}
+
+	owner := network.Name
+	if owner == "" {
+		owner = network.Handle
+	}
+	var details []string
+	for _, entity := range network.Entities {
+		if hasRole(entity.Roles, "registrant") {
+			if name := vcardName(entity.VCardArray); name != "" {
+				details = append(details, name)
+				break
+			}
+		}
+	}
+	if network.Country != "" {
+		details = append(details, network.Country)
+	}
+	if len(details) > 0 {
+		owner = fmt.Sprintf("%s (%s)", owner, strings.Join(details, ", "))
+	}
+	if owner == "" {
+		return "", fmt.Errorf("RDAP lookup returned no owner")
+	}
+	return owner, nil
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// vcardName returns the fn property of a jCard, "" when it has none
+func vcardName(raw json.RawMessage) string {
+	var card []interface{}
+	if json.Unmarshal(raw, &card) != nil || len(card) < 2 {
+		return ""
+	}
+	properties, _ := card[1].([]interface{})
+	for _, property := range properties {
+		fields, _ := property.([]interface{})
+		if len(fields) == 4 && fields[0] == "fn" {
+			name, _ := fields[3].(string)
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,242 @@
+package compliance
+
+import (
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/audit"
+)
+
+// Report summarizes the compliance checks and audited operations of a
+// period for governance reviews
+type Report struct {
+	Generated        time.Time          `json:"generated"`
+	Since            *time.Time         `json:"since,omitempty"` // nil covers every record
+	Operations       int                `json:"operations"`      // audited operations, including those without a check
+	Checks           int                `json:"checks"`
+	Allowed          int                `json:"allowed"`
+	Blocked          int                `json:"blocked"`
+	PublicRuns       int                `json:"public_target_runs"` // allowed checks with public targets
+	PublicTargets    []string           `json:"public_targets,omitempty"`
+	BudgetViolations int                `json:"budget_violations"` // checks whose options were lowered to the ceilings
+	Overrides        int                `json:"policy_overrides"`
+	OutsideWindow    int                `json:"outside_window_runs"` // allowed checks outside the scan windows
+	Unauthorized     int                `json:"unauthorized"`        // allowed checks with neither authorized_by nor ticket
+	ByCommand        []Count            `json:"by_command,omitempty"`
+	ByLevel          []Count            `json:"by_policy_level,omitempty"`
+	ByOperator       []Count            `json:"by_operator,omitempty"`
+	BlockReasons     []Count            `json:"block_reasons,omitempty"`
+	BlockedAttempts  []ComplianceResult `json:"blocked_attempts,omitempty"`
+	Violations       []ComplianceResult `json:"budget_violation_runs,omitempty"`
+	AuditLog         AuditStatus        `json:"audit_log"`
+}
+
+// Count is a value and how often it occurred
+type Count struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// AuditStatus is the state of the audit log when a report was made
+type AuditStatus struct {
+	Entries int    `json:"entries"` // in the whole log
+	Intact  bool   `json:"intact"`
+	Head    string `json:"head,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report builds the report of the checks and audited operations since a
+// time; a zero time covers every record
+func (cc *ComplianceChecker) Report(since time.Time) (*Report, error) {
+	results, err := cc.History()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := audit.Read()
+	if err != nil {
+		return nil, err
+	}
+	report := BuildReport(results, entries, since)
+
+	count, head, err := audit.Verify()
+	report.AuditLog = AuditStatus{Entries: count, Intact: err == nil, Head: head}
+	if err != nil {
+		report.AuditLog.Error = err.Error()
+	}
+	return report, nil
+}
+
+// BuildReport aggregates checks and audit entries since a time
+func BuildReport(results []ComplianceResult, entries []audit.Entry, since time.Time) *Report {
+	report := &Report{Generated: time.Now()}
+	if !since.IsZero() {
+		report.Since = &since
+	}
+
+	byCommand := map[string]int{}
+	byLevel := map[string]int{}
+	byOperator := map[string]int{}
+	reasons := map[string]int{}
+	public := map[string]bool{}
+
+	for _, result := range results {
+		if result.Timestamp.Before(since) {
+			continue
+		}
+		report.Checks++
+		byCommand[result.Command]++
+		if result.PolicyLevel != "" {
+			byLevel[result.PolicyLevel]++
+		} else {
+			byLevel["(not recorded)"]++
+		}
+		if result.PolicyOverride {
+			report.Overrides++
+		}
+		if len(result.Clamped) > 0 {
+			report.BudgetViolations++
+			report.Violations = append(report.Violations, result)
+		}
+		if result.Status == StatusBlocked {
+			report.Blocked++
+			reasons[result.BlockReason]++
+			report.BlockedAttempts = append(report.BlockedAttempts, result)
+			continue
+		}
+		report.Allowed++
+		if result.OutsideWindow {
+			report.OutsideWindow++
+		}
+		if len(result.PublicTargets) > 0 {
+			report.PublicRuns++
+			for _, target := range result.PublicTargets {
+				public[target] = true
+			}
+		}
+		if result.AuthorizedBy == "" && result.Ticket == "" {
+			report.Unauthorized++
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.Timestamp.Before(since) {
+			continue
+		}
+		report.Operations++
+		operator := entry.User
+		if entry.SudoUser != "" {
+			operator = entry.SudoUser + " (sudo)"
+		}
+		byOperator[operator]++
+	}
+
+	for target := range public {
+		report.PublicTargets = append(report.PublicTargets, target)
+	}
+	sort.Strings(report.PublicTargets)
+	report.ByCommand = sortCounts(byCommand)
+	report.ByLevel = sortCounts(byLevel)
+	report.ByOperator = sortCounts(byOperator)
+	report.BlockReasons = sortCounts(reasons)
+	return report
+}
+
+// sortCounts orders counts from most to least frequent
+func sortCounts(counts map[string]int) []Count {
+	sorted := make([]Count, 0, len(counts))
+	for value, count := range counts {
+		sorted = append(sorted, Count{Value: value, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+	return sorted
+}
+
+// WriteHTML renders the report as a standalone HTML page
+func (r *Report) WriteHTML(w io.Writer) error {
+	return reportTemplate.Execute(w, r)
+}
+
+var reportTemplate = template.Must(template.New("compliance").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.Local().Format("2006-01-02 15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>NetCrate Compliance Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1000px; color: #222; }
+h1 { border-bottom: 2px solid #2c3e50; padding-bottom: .3em; }
+h2 { margin-top: 1.6em; color: #2c3e50; }
+.cards { display: flex; flex-wrap: wrap; gap: 1em; }
+.card { border: 1px solid #ddd; border-radius: 6px; padding: .8em 1.2em; min-width: 120px; }
+.card b { display: block; font-size: 1.8em; }
+.bad { color: #c0392b; }
+.ok { color: #27ae60; }
+table { border-collapse: collapse; width: 100%; margin-top: .5em; }
+th, td { border-bottom: 1px solid #eee; padding: .4em .6em; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+code { font-size: .9em; }
+</style>
+</head>
+<body>
+<h1>NetCrate Compliance Report</h1>
+<p>Generated {{time .Generated}}{{if .Since}} &middot; records since {{time .Since}}{{else}} &middot; all records{{end}}</p>
+
+<div class="cards">
+<div class="card"><b>{{.Operations}}</b>operations</div>
+<div class="card"><b>{{.Checks}}</b>compliance checks</div>
+<div class="card"><b class="ok">{{.Allowed}}</b>allowed</div>
+<div class="card"><b{{if .Blocked}} class="bad"{{end}}>{{.Blocked}}</b>blocked</div>
+<div class="card"><b>{{.PublicRuns}}</b>runs with public targets</div>
+<div class="card"><b{{if .BudgetViolations}} class="bad"{{end}}>{{.BudgetViolations}}</b>budget violations</div>
+<div class="card"><b>{{.Overrides}}</b>policy overrides</div>
+<div class="card"><b{{if .OutsideWindow}} class="bad"{{end}}>{{.OutsideWindow}}</b>runs outside scan windows</div>
+<div class="card"><b{{if .Unauthorized}} class="bad"{{end}}>{{.Unauthorized}}</b>runs without authorization</div>
+</div>
+
+<h2>Audit log</h2>
+{{if .AuditLog.Intact}}<p class="ok">Intact: {{.AuditLog.Entries}} entries, head <code>{{.AuditLog.Head}}</code></p>
+{{else}}<p class="bad">Tampered or unreadable: {{.AuditLog.Error}}</p>{{end}}
+
+{{if .ByCommand}}<h2>Checks by command</h2>
+<table><tr><th>Command</th><th>Checks</th></tr>
+{{range .ByCommand}}<tr><td>{{.Value}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>{{end}}
+
+{{if .ByLevel}}<h2>Checks by policy level</h2>
+<table><tr><th>Level</th><th>Checks</th></tr>
+{{range .ByLevel}}<tr><td>{{.Value}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>{{end}}
+
+{{if .ByOperator}}<h2>Operations by operator</h2>
+<table><tr><th>Operator</th><th>Operations</th></tr>
+{{range .ByOperator}}<tr><td>{{.Value}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>{{end}}
+
+{{if .PublicTargets}}<h2>Public targets</h2>
+<p>{{range $i, $t := .PublicTargets}}{{if $i}}, {{end}}<code>{{$t}}</code>{{end}}</p>{{end}}
+
+{{if .BlockReasons}}<h2>Block reasons</h2>
+<table><tr><th>Reason</th><th>Attempts</th></tr>
+{{range .BlockReasons}}<tr><td>{{.Value}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>{{end}}
+
+{{if .BlockedAttempts}}<h2>Blocked attempts</h2>
+<table><tr><th>Time</th><th>Command</th><th>Targets</th><th>Reason</th></tr>
+{{range .BlockedAttempts}}<tr><td>{{time .Timestamp}}</td><td>{{.Command}}</td><td>{{range $i, $t := .Targets}}{{if $i}}, {{end}}{{$t}}{{end}}</td><td>{{.BlockReason}}</td></tr>
+{{end}}</table>{{end}}
+
+{{if .Violations}}<h2>Budget violations</h2>
+<table><tr><th>Time</th><th>Command</th><th>Lowered to the ceilings</th></tr>
+{{range .Violations}}<tr><td>{{time .Timestamp}}</td><td>{{.Command}}</td><td>{{range $i, $c := .Clamped}}{{if $i}}; {{end}}{{$c}}{{end}}</td></tr>
+{{end}}</table>{{end}}
+</body>
+</html>
+`))
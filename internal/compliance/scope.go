@@ -0,0 +1,194 @@
+package compliance
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Scope is an engagement scope file: the targets an engagement is
+// authorized to test, for how long, and under which authorization. While a
+// scope file is configured, targets outside it are blocked even on private
+// networks.
+type Scope struct {
+	Engagement    string   `yaml:"engagement" json:"engagement,omitempty"`
+	Authorization string   `yaml:"authorization" json:"authorization"`       // e.g. statement of work or ticket
+	ValidFrom     string   `yaml:"valid_from" json:"valid_from,omitempty"`   // date or RFC 3339 time
+	ValidUntil    string   `yaml:"valid_until" json:"valid_until,omitempty"` // date (inclusive) or RFC 3339 time
+	Allowed       []string `yaml:"allowed" json:"allowed"`                   // IPs, CIDRs, hostnames or *.domain
+
+	path     string
+	from     time.Time
+	until    time.Time
+	networks []*net.IPNet
+	hosts    []string
+}
+
+// ScopeReference identifies the scope a check was made against
+type ScopeReference struct {
+	File          string `json:"file"`
+	Engagement    string `json:"engagement,omitempty"`
+	Authorization string `json:"authorization"`
+}
+
+// LoadScope reads and validates a scope file
+func LoadScope(path string) (*Scope, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scope file: %w", err)
+	}
+	var scope Scope
+	if err := yaml.UnmarshalStrict(data, &scope); err != nil {
+		return nil, fmt.Errorf("%s: invalid YAML: %s", path, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	scope.path = path
+	if err := scope.parse(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &scope, nil
+}
+
+// parse validates the scope and prepares it for matching
+func (s *Scope) parse() error {
+	if strings.TrimSpace(s.Authorization) == "" {
+		return fmt.Errorf("authorization is required (e.g. the statement of work or ticket)")
+	}
+	if len(s.Allowed) == 0 {
+		return fmt.Errorf("allowed lists no targets")
+	}
+
+	var err error
+	if s.ValidFrom != "" {
+		if s.from, err = parseScopeTime(s.ValidFrom, false); err != nil {
+			return fmt.Errorf("valid_from: %w", err)
+		}
+	}
+	if s.ValidUntil != "" {
+		if s.until, err = parseScopeTime(s.ValidUntil, true); err != nil {
+			return fmt.Errorf("valid_until: %w", err)
+		}
+		if !s.from.IsZero() && s.until.Before(s.from) {
+			return fmt.Errorf("valid_until is before valid_from")
+		}
+	}
+
+	for _, entry := range s.Allowed {
+		entry = strings.TrimSpace(entry)
+		if network, ok := parseNetwork(entry); ok {
+			s.networks = append(s.networks, network)
+			continue
+		}
+		if entry == "" || strings.ContainsAny(entry, "/ ") {
+			return fmt.Errorf("invalid allowed entry %q", entry)
+		}
+		s.hosts = append(s.hosts, strings.ToLower(entry))
+	}
+	return nil
+}
+
+// parseScopeTime parses a date or an RFC 3339 time. A date given as the end
+// of a window covers the whole day.
+func parseScopeTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a date (2006-01-02) or an RFC 3339 time, got %q", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// Reference returns what a check records about the scope
+func (s *Scope) Reference() *ScopeReference {
+	return &ScopeReference{File: s.path, Engagement: s.Engagement, Authorization: s.Authorization}
+}
+
+// CheckWindow returns an error when now is outside the validity window
+func (s *Scope) CheckWindow(now time.Time) error {
+	if !s.from.IsZero() && now.Before(s.from) {
+		return fmt.Errorf("engagement scope is not valid before %s", s.ValidFrom)
+	}
+	if !s.until.IsZero() && now.After(s.until) {
+		return fmt.Errorf("engagement scope expired after %s", s.ValidUntil)
+	}
+	return nil
+}
+
+// Contains reports whether a target lies inside the scope. A CIDR or IP
+// range must lie entirely inside an allowed network; a hostname must be
+// listed or resolve only to allowed addresses.
+func (s *Scope) Contains(target string) bool {
+	if strings.Contains(target, "-") {
+		if first, last, ok := span(target); ok {
+			for _, allowed := range s.networks {
+				if allowed.Contains(first) && allowed.Contains(last) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	if _, network, err := net.ParseCIDR(target); err == nil {
+		for _, allowed := range s.networks {
+			allowedOnes, allowedBits := allowed.Mask.Size()
+			ones, bits := network.Mask.Size()
+			if allowedBits == bits && allowedOnes <= ones && allowed.Contains(network.IP) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ip := net.ParseIP(target); ip != nil {
+		return s.containsIP(ip)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(target, "."))
+	for _, allowed := range s.hosts {
+		if host == allowed || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return true
+		}
+	}
+	addresses, err := net.LookupIP(host)
+	if err != nil || len(addresses) == 0 {
+		return false
+	}
+	for _, ip := range addresses {
+		if !s.containsIP(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Scope) containsIP(ip net.IP) bool {
+	for _, allowed := range s.networks {
+		if allowed.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNetwork parses an IP or CIDR, treating an IP as a single host
+func parseNetwork(entry string) (*net.IPNet, bool) {
+	if ip := net.ParseIP(entry); ip != nil {
+		bits := 128
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, true
+	}
+	if _, network, err := net.ParseCIDR(entry); err == nil {
+		return network, true
+	}
+	return nil, false
+}
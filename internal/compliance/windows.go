@@ -0,0 +1,78 @@
+package compliance
+
+import (
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/config"
+)
+
+// inWindows reports whether now falls in one of the scan windows. A window
+// that runs past midnight belongs to the day it starts on, so weekdays
+// 20:00-06:00 covers Friday night into Saturday morning but not Monday
+// morning. No windows permits any time.
+func inWindows(now time.Time, windows []config.ScanWindow) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, window := range windows {
+		if inWindow(now, window) {
+			return true
+		}
+	}
+	return false
+}
+
+func inWindow(now time.Time, window config.ScanWindow) bool {
+	start, err := config.ParseClock(window.Start)
+	if err != nil {
+		return false
+	}
+	end, err := config.ParseClock(window.End)
+	if err != nil {
+		return false
+	}
+	if window.Timezone != "" {
+		location, err := time.LoadLocation(window.Timezone)
+		if err != nil {
+			return false
+		}
+		now = now.In(location)
+	}
+
+	minute := now.Hour()*60 + now.Minute()
+	day := now.Weekday()
+	if end <= start {
+		// Past midnight: the early hours belong to the previous day
+		if minute < end {
+			return onDay(day-1, window.Days)
+		}
+		return minute >= start && onDay(day, window.Days)
+	}
+	return minute >= start && minute < end && onDay(day, window.Days)
+}
+
+// onDay reports whether a window with these days is open on a weekday
+func onDay(day time.Weekday, days []string) bool {
+	if len(days) == 0 {
+		return true
+	}
+	day = (day + 7) % 7
+	for _, name := range days {
+		for _, d := range config.ScanWindowDays[strings.ToLower(name)] {
+			if d == day {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// describeWindows lists scan windows for messages
+func describeWindows(windows []config.ScanWindow) string {
+	described := make([]string, len(windows))
+	for i, window := range windows {
+		described[i] = window.String()
+	}
+	return strings.Join(described, "; ")
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SplitCommandLine splits an alias expansion into arguments like a shell
+// would: on whitespace, with single and double quotes and backslash escapes
+func SplitCommandLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// AliasNames returns the names of the command aliases, sorted
+func (cm *ConfigManager) AliasNames() []string {
+	names := make([]string, 0, len(cm.config.Aliases))
+	for name := range cm.config.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetAlias returns the command line an alias stands for
+func (cm *ConfigManager) GetAlias(name string) (string, bool) {
+	line, ok := cm.config.Aliases[name]
+	return line, ok
+}
+
+// SetAlias creates or replaces a command alias
+func (cm *ConfigManager) SetAlias(name, line string) error {
+	if cm.config.Aliases == nil {
+		cm.config.Aliases = make(map[string]string)
+	}
+	previous, existed := cm.config.Aliases[name]
+	cm.config.Aliases[name] = line
+	if err := validateAliases(cm.config); err != nil {
+		if existed {
+			cm.config.Aliases[name] = previous
+		} else {
+			delete(cm.config.Aliases, name)
+		}
+		return err
+	}
+	return cm.Save()
+}
+
+// RemoveAlias deletes a command alias
+func (cm *ConfigManager) RemoveAlias(name string) error {
+	if _, ok := cm.config.Aliases[name]; !ok {
+		return fmt.Errorf("unknown alias %q", name)
+	}
+	delete(cm.config.Aliases, name)
+	return cm.Save()
+}
+
+// validateAliases checks the aliases section: names are single words and
+// expansions are command lines without the program name
+func validateAliases(config *Config) error {
+	for name, line := range config.Aliases {
+		field := "aliases." + name
+		if !profileNamePattern.MatchString(name) || strings.HasPrefix(name, "-") {
+			return fieldErrorf(field, "invalid alias name (use letters, digits, '_' and '-', not starting with '-')")
+		}
+		args, err := SplitCommandLine(line)
+		if err != nil {
+			return fieldErrorf(field, "invalid command line: %v", err)
+		}
+		if len(args) == 0 {
+			return fieldErrorf(field, "empty command line")
+		}
+		if args[0] == "netcrate" {
+			return fieldErrorf(field, "write the command without \"netcrate\", e.g. \"ops scan ports --ports web\"")
+		}
+		if args[0] == name {
+			return fieldErrorf(field, "an alias cannot expand to itself")
+		}
+	}
+	return nil
+}
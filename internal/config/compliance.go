@@ -0,0 +1,229 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Compliance policy levels
+const (
+	PolicyStrict     = "strict"     // private targets only, every run confirmed
+	PolicyStandard   = "standard"   // public targets need --dangerous and a typed confirmation
+	PolicyPermissive = "permissive" // public targets need only --dangerous
+)
+
+// PolicyLevels lists the policy levels from strictest to most permissive
+var PolicyLevels = []string{PolicyStrict, PolicyStandard, PolicyPermissive}
+
+// ComplianceConfig is the organization's scanning policy: its level, which
+// networks may be targeted and the ceilings on the rate, concurrency,
+// targets and ports of a run. Zero limits leave the built-in checks in
+// charge. A scope file narrows the targets further to those of one
+// engagement.
+type ComplianceConfig struct {
+	Level               string       `yaml:"level" json:"level,omitempty"`                               // strict, standard (default) or permissive
+	PolicyTokenHash     string       `yaml:"policy_token_sha256" json:"-"`                               // SHA-256 of the token that lifts a strict level for one run
+	AllowPublic         bool         `yaml:"allow_public" json:"allow_public,omitempty"`                 // public targets need --dangerous but no typed confirmation
+	AllowedRanges       []string     `yaml:"allowed_ranges" json:"allowed_ranges,omitempty"`             // CIDRs that may be scanned
+	BlockedRanges       []string     `yaml:"blocked_ranges" json:"blocked_ranges,omitempty"`             // CIDRs never scanned, added to the built-in blocklist
+	MaxRate             int          `yaml:"max_rate" json:"max_rate,omitempty"`                         // packets per second
+	MaxConcurrency      int          `yaml:"max_concurrency" json:"max_concurrency,omitempty"`           // concurrent workers
+	MaxTargets          int          `yaml:"max_targets" json:"max_targets,omitempty"`                   // addresses the targets of a run expand to
+	MaxPortsPerHost     int          `yaml:"max_ports_per_host" json:"max_ports_per_host,omitempty"`     // ports probed on each host
+	RequireConfirmation bool         `yaml:"require_confirmation" json:"require_confirmation,omitempty"` // ask before every run
+	ScopeFile           string       `yaml:"scope_file" json:"scope_file,omitempty"`                     // engagement scope; targets outside it are blocked
+	LookupOwner         bool         `yaml:"lookup_owner" json:"lookup_owner,omitempty"`                 // show the RDAP owner of public targets before confirming
+	Windows             []ScanWindow `yaml:"windows" json:"windows,omitempty"`                           // when runs are permitted (empty = any time)
+	AuthorizedBy        string       `yaml:"authorized_by" json:"authorized_by,omitempty"`               // default of --authorized-by
+	Ticket              string       `yaml:"ticket" json:"ticket,omitempty"`                             // default of --ticket
+}
+
+// ScanWindow is a period in which runs are permitted, such as weekdays
+// 20:00–06:00. A window that ends at or before its start runs past midnight
+// and belongs to the day it starts on.
+type ScanWindow struct {
+	Days     []string `yaml:"days" json:"days,omitempty"`         // mon..sun, weekdays or weekend (empty = every day)
+	Start    string   `yaml:"start" json:"start"`                 // HH:MM
+	End      string   `yaml:"end" json:"end"`                     // HH:MM
+	Timezone string   `yaml:"timezone" json:"timezone,omitempty"` // IANA name (empty = local time)
+}
+
+func (w ScanWindow) String() string {
+	days := "every day"
+	if len(w.Days) > 0 {
+		days = strings.Join(w.Days, ",")
+	}
+	text := fmt.Sprintf("%s %s-%s", days, w.Start, w.End)
+	if w.Timezone != "" {
+		text += " " + w.Timezone
+	}
+	return text
+}
+
+// ScanWindowDays maps the day names of a scan window to weekdays
+var ScanWindowDays = map[string][]time.Weekday{
+	"sun":      {time.Sunday},
+	"mon":      {time.Monday},
+	"tue":      {time.Tuesday},
+	"wed":      {time.Wednesday},
+	"thu":      {time.Thursday},
+	"fri":      {time.Friday},
+	"sat":      {time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekend":  {time.Saturday, time.Sunday},
+}
+
+// ParseClock parses an HH:MM time of day into minutes after midnight
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// validateWindow checks a scan window
+func validateWindow(field string, window ScanWindow) error {
+	for _, day := range window.Days {
+		if _, ok := ScanWindowDays[strings.ToLower(day)]; !ok {
+			return fieldErrorf(field, "unknown day %q (use mon..sun, weekdays or weekend)", day)
+		}
+	}
+	if _, err := ParseClock(window.Start); err != nil {
+		return fieldErrorf(field, "start: %v", err)
+	}
+	if _, err := ParseClock(window.End); err != nil {
+		return fieldErrorf(field, "end: %v", err)
+	}
+	if window.Timezone != "" {
+		if _, err := time.LoadLocation(window.Timezone); err != nil {
+			return fieldErrorf(field, "unknown timezone %q", window.Timezone)
+		}
+	}
+	return nil
+}
+
+// EffectiveScopeFile returns the scope file with ~ expanded, or "" when
+// no engagement scope is in force
+func (c ComplianceConfig) EffectiveScopeFile() string {
+	return expandHome(c.ScopeFile)
+}
+
+// EffectiveLevel returns the policy level, PolicyStandard when unset
+func (c ComplianceConfig) EffectiveLevel() string {
+	if c.Level == "" {
+		return PolicyStandard
+	}
+	return c.Level
+}
+
+// HashPolicyToken returns the hash a policy token is stored as
+func HashPolicyToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetCompliance sets the policy level, the policy token or the default
+// authorization of runs. The token is stored only as its hash; an empty
+// token removes it.
+func (cm *ConfigManager) SetCompliance(key, value string) error {
+	switch key {
+	case "compliance_level":
+		if err := oneOf("compliance.level", value, append([]string{""}, PolicyLevels...)...); err != nil {
+			return err
+		}
+		cm.config.Compliance.Level = value
+	case "policy_token":
+		if value == "" {
+			cm.config.Compliance.PolicyTokenHash = ""
+		} else {
+			cm.config.Compliance.PolicyTokenHash = HashPolicyToken(value)
+		}
+	case "authorized_by":
+		cm.config.Compliance.AuthorizedBy = value
+	case "ticket":
+		cm.config.Compliance.Ticket = value
+	default:
+		return fmt.Errorf("unknown compliance setting: %s", key)
+	}
+	return cm.Save()
+}
+
+// AddBlockedRange adds an IP or CIDR to the never-scan blocklist
+func (cm *ConfigManager) AddBlockedRange(network string) error {
+	if validateRanges("compliance.blocked_ranges", []string{network}) != nil {
+		return fmt.Errorf("invalid IP or CIDR %q", network)
+	}
+	for _, existing := range cm.config.Compliance.BlockedRanges {
+		if existing == network {
+			return fmt.Errorf("%s is already blocked", network)
+		}
+	}
+	cm.config.Compliance.BlockedRanges = append(cm.config.Compliance.BlockedRanges, network)
+	return cm.Save()
+}
+
+// RemoveBlockedRange removes an IP or CIDR from the never-scan blocklist
+func (cm *ConfigManager) RemoveBlockedRange(network string) error {
+	ranges := cm.config.Compliance.BlockedRanges
+	for i, existing := range ranges {
+		if existing == network {
+			cm.config.Compliance.BlockedRanges = append(ranges[:i:i], ranges[i+1:]...)
+			return cm.Save()
+		}
+	}
+	return fmt.Errorf("%s is not in compliance.blocked_ranges", network)
+}
+
+// SetScopeFile sets the engagement scope file, or clears it when path is
+// empty. The caller validates the file.
+func (cm *ConfigManager) SetScopeFile(path string) error {
+	if path != "" && !filepath.IsAbs(path) && !strings.HasPrefix(path, "~/") {
+		return fmt.Errorf("invalid scope_file: %s (use an absolute path or one starting with ~/)", path)
+	}
+	cm.config.Compliance.ScopeFile = path
+	return cm.Save()
+}
+
+// validateCompliance checks a compliance policy
+func validateCompliance(field string, compliance ComplianceConfig) error {
+	if err := oneOf(field+".level", compliance.Level, append([]string{""}, PolicyLevels...)...); err != nil {
+		return err
+	}
+	if err := validateRanges(field+".allowed_ranges", compliance.AllowedRanges); err != nil {
+		return err
+	}
+	if err := validateRanges(field+".blocked_ranges", compliance.BlockedRanges); err != nil {
+		return err
+	}
+	for i, window := range compliance.Windows {
+		if err := validateWindow(fmt.Sprintf("%s.windows[%d]", field, i), window); err != nil {
+			return err
+		}
+	}
+	if compliance.MaxRate < 0 || compliance.MaxConcurrency < 0 || compliance.MaxTargets < 0 || compliance.MaxPortsPerHost < 0 {
+		return fieldErrorf(field, "max_rate, max_concurrency, max_targets and max_ports_per_host cannot be negative")
+	}
+	return nil
+}
+
+// validateRanges checks a list of IPs or CIDRs
+func validateRanges(setting string, ranges []string) error {
+	for i, entry := range ranges {
+		probe := entry
+		if !strings.Contains(probe, "/") {
+			if net.ParseIP(probe) != nil {
+				continue
+			}
+		} else if _, _, err := net.ParseCIDR(probe); err == nil {
+			continue
+		}
+		return fieldErrorf(fmt.Sprintf("%s[%d]", setting, i), "invalid IP or CIDR %q", entry)
+	}
+	return nil
+}
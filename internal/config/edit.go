@@ -0,0 +1,247 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/transport"
+)
+
+// ConfigSource is one of the layers the effective configuration is built from
+type ConfigSource struct {
+	Name   string // "defaults", "config file", "config profile", "project file" or an environment variable
+	Detail string
+}
+
+// Path returns the location of the config file
+func (cm *ConfigManager) Path() string {
+	return cm.configPath
+}
+
+// Sources lists the layers in effect, lowest precedence first
+func (cm *ConfigManager) Sources() []ConfigSource {
+	sources := []ConfigSource{{Name: "defaults", Detail: "built in"}}
+
+	detail := cm.configPath
+	if info, err := os.Stat(cm.configPath); err == nil {
+		detail += fmt.Sprintf(" (modified %s)", info.ModTime().Format("2006-01-02 15:04:05"))
+	}
+	sources = append(sources, ConfigSource{Name: "config file", Detail: detail})
+
+	if cm.profile != "" {
+		sources = append(sources, ConfigSource{Name: "config profile", Detail: cm.profile})
+	}
+	if cm.project != nil {
+		sources = append(sources, ConfigSource{Name: "project file", Detail: cm.project.path})
+	}
+	for _, override := range cm.overrides {
+		if override.origin != OriginEnv {
+			continue
+		}
+		shown := override.value
+		if override.setting.Secret {
+			shown = "(set)"
+		}
+		sources = append(sources, ConfigSource{Name: "$" + override.setting.Env, Detail: fmt.Sprintf("%s = %s", override.setting.Key, shown)})
+	}
+	// Not a config setting, but read the same way
+	if paths := os.Getenv("NETCRATE_TEMPLATES"); paths != "" {
+		sources = append(sources, ConfigSource{Name: "$NETCRATE_TEMPLATES", Detail: "template search paths = " + paths})
+	}
+	return sources
+}
+
+// ValidateConfig checks the values of a configuration, reporting the first
+// invalid setting it finds
+func ValidateConfig(config *Config) error {
+	for name, profile := range config.RateProfiles {
+		if profile.Rate <= 0 || profile.Concurrency <= 0 {
+			return fieldErrorf("rate_profiles."+name, "rate and concurrency must be positive")
+		}
+		if profile.Timeout < 0 || profile.Retries < 0 || profile.MaxPerHost < 0 {
+			return fieldErrorf("rate_profiles."+name, "timeout, retries and max_per_host cannot be negative")
+		}
+	}
+	if config.CurrentRateProfile != "" {
+		if _, ok := config.RateProfiles[config.CurrentRateProfile]; !ok {
+			return fieldErrorf("current_rate_profile", "unknown profile %q", config.CurrentRateProfile)
+		}
+	}
+
+	prefs := config.Preferences
+	if err := oneOf("preferences.default_output_format", prefs.DefaultOutputFormat, "", "table", "json", "yaml"); err != nil {
+		return err
+	}
+	if err := oneOf("preferences.language", prefs.Language, "", "en", "zh-CN"); err != nil {
+		return err
+	}
+	if err := oneOf("preferences.report_language", prefs.ReportLanguage, "", "en", "zh-CN"); err != nil {
+		return err
+	}
+	if err := validateRanges("preferences.do_not_scan", prefs.DoNotScan); err != nil {
+		return err
+	}
+	if prefs.ResultsDir != "" && !filepath.IsAbs(prefs.ResultsDir) && !strings.HasPrefix(prefs.ResultsDir, "~/") {
+		return fieldErrorf("preferences.results_dir", "expected an absolute path or one starting with ~/, got %q", prefs.ResultsDir)
+	}
+
+	if err := oneOf("notifications.trigger", config.Notifications.Trigger, "", "new-critical", "always"); err != nil {
+		return err
+	}
+
+	if config.Retention.MaxRuns < 0 {
+		return fieldErrorf("retention.max_runs", "cannot be negative")
+	}
+	if config.Retention.MaxAge != "" {
+		if _, err := ParseAge(config.Retention.MaxAge); err != nil {
+			return fieldErrorf("retention.max_age", "%v", err)
+		}
+	}
+	if config.Retention.MaxDisk != "" {
+		if _, err := ParseByteSize(config.Retention.MaxDisk); err != nil {
+			return fieldErrorf("retention.max_disk", "%v", err)
+		}
+	}
+
+	if config.Sink.URL != "" {
+		if u, err := url.Parse(config.Sink.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fieldErrorf("sink.url", "expected an http(s) URL, got %q", config.Sink.URL)
+		}
+	}
+	if config.Sink.FlushInterval != "" {
+		if _, err := time.ParseDuration(config.Sink.FlushInterval); err != nil {
+			return fieldErrorf("sink.flush_interval", "%v", err)
+		}
+	}
+
+	if config.Syslog.Address != "" {
+		if _, _, err := net.SplitHostPort(config.Syslog.Address); err != nil {
+			return fieldErrorf("syslog.address", "invalid collector address %q (use host:port)", config.Syslog.Address)
+		}
+	}
+	if err := oneOf("syslog.protocol", config.Syslog.Protocol, "", "udp", "tcp"); err != nil {
+		return err
+	}
+	if err := oneOf("syslog.format", config.Syslog.Format, "", "rfc5424", "cef"); err != nil {
+		return err
+	}
+	if err := oneOf("logging.level", config.Logging.Level, "", "debug", "info", "warn", "error"); err != nil {
+		return err
+	}
+	if err := oneOf("logging.format", config.Logging.Format, "", "text", "json"); err != nil {
+		return err
+	}
+
+	if _, err := transport.ParseProxy(config.Proxy.URL); err != nil {
+		return fieldErrorf("proxy.url", "%v", err)
+	}
+	if _, err := transport.ParseResolvers(config.DNS.Resolvers); err != nil {
+		return fieldErrorf("dns.resolvers", "%v", err)
+	}
+	if err := validateEgress(config.Egress); err != nil {
+		return err
+	}
+	if err := validateUpdates(config.Updates); err != nil {
+		return err
+	}
+
+	if config.Redaction.IPOctets < 0 || config.Redaction.IPOctets > 4 {
+		return fieldErrorf("redaction.ip_octets", "must be between 0 and 4")
+	}
+
+	if err := validateCompliance("compliance", config.Compliance); err != nil {
+		return err
+	}
+
+	if err := validateReportConfig(config.Reports); err != nil {
+		return err
+	}
+
+	if err := validateNetworks(config); err != nil {
+		return err
+	}
+
+	if err := validateConfigProfiles(config); err != nil {
+		return err
+	}
+
+	if err := validateAliases(config); err != nil {
+		return err
+	}
+
+	for command, flags := range config.Defaults {
+		for name := range flags {
+			if strings.HasPrefix(name, "-") {
+				return fieldErrorf("defaults."+command+"."+name, "flag names are written without dashes")
+			}
+		}
+	}
+	return nil
+}
+
+// oneOf checks that a setting has one of the allowed values
+func oneOf(setting, value string, allowed ...string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	var shown []string
+	for _, a := range allowed {
+		if a != "" {
+			shown = append(shown, a)
+		}
+	}
+	return fieldErrorf(setting, "invalid value %q (expected %s)", value, strings.Join(shown, ", "))
+}
+
+// Backup copies the config file next to itself as config.yaml.bak-<time>
+// and returns the copy's path
+func (cm *ConfigManager) Backup() (string, error) {
+	data, err := os.ReadFile(cm.configPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	path := cm.configPath + ".bak-" + time.Now().Format("20060102-150405")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write config backup: %w", err)
+	}
+	return path, nil
+}
+
+// Reset backs up the config file and replaces it with the defaults,
+// returning the backup's path
+func (cm *ConfigManager) Reset() (string, error) {
+	backup := ""
+	if _, err := os.Stat(cm.configPath); err == nil {
+		if backup, err = cm.Backup(); err != nil {
+			return "", err
+		}
+	}
+	cm.config = cm.createDefaultConfig()
+	cm.profile = ""
+	cm.overrides = nil
+	if err := cm.Save(); err != nil {
+		return backup, err
+	}
+	return backup, nil
+}
+
+// ResetConfig restores the default configuration without loading the current
+// one, so a config file that no longer validates can still be reset
+func ResetConfig() (string, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	cm := &ConfigManager{configPath: path}
+	return cm.Reset()
+}
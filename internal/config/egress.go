@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// EgressConfig sets the external endpoints "ops netenv --egress" asks for
+// the public address. Empty lists use the built-in public servers.
+type EgressConfig struct {
+	STUNServers []string `yaml:"stun_servers" json:"stun_servers,omitempty"` // host:port of STUN servers
+	IPServices  []string `yaml:"ip_services" json:"ip_services,omitempty"`   // https URLs that answer with the caller's address
+}
+
+// validateEgress checks the STUN servers and public IP services
+func validateEgress(egress EgressConfig) error {
+	for i, server := range egress.STUNServers {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			return fieldErrorf(fmt.Sprintf("egress.stun_servers[%d]", i), "invalid STUN server %q (use host:port)", server)
+		}
+	}
+	for i, service := range egress.IPServices {
+		if u, err := url.Parse(service); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fieldErrorf(fmt.Sprintf("egress.ip_services[%d]", i), "expected an http(s) URL, got %q", service)
+		}
+	}
+	return nil
+}
+
+// SetEgress sets the STUN servers or public IP services
+func (cm *ConfigManager) SetEgress(key, value string) error {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+
+	egress := cm.config.Egress
+	switch key {
+	case "egress_stun_servers":
+		egress.STUNServers = list
+	case "egress_ip_services":
+		egress.IPServices = list
+	default:
+		return fmt.Errorf("unknown egress setting: %s", key)
+	}
+	if err := validateEgress(egress); err != nil {
+		return err
+	}
+
+	cm.config.Egress = egress
+	return cm.Save()
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// PassphraseEnv overrides the configured passphrase, so it need not be stored on disk
+const PassphraseEnv = "NETCRATE_PASSPHRASE"
+
+// EncryptionConfig controls encryption of saved runs at rest
+type EncryptionConfig struct {
+	Enabled    bool   `yaml:"enabled" json:"enabled,omitempty"`
+	KeyFile    string `yaml:"key_file" json:"key_file,omitempty"`     // file whose contents are the key
+	Passphrase string `yaml:"passphrase" json:"passphrase,omitempty"` // used when no key file is set
+}
+
+// Secret returns the key material for run encryption: the key file contents,
+// else $NETCRATE_PASSPHRASE, else the configured passphrase. It returns nil
+// when none is available.
+func (e EncryptionConfig) Secret() ([]byte, error) {
+	if e.KeyFile != "" {
+		data, err := os.ReadFile(e.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+		}
+		if len(data) == 0 {
+			return nil, fmt.Errorf("encryption key file %s is empty", e.KeyFile)
+		}
+		return data, nil
+	}
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	if e.Passphrase != "" {
+		return []byte(e.Passphrase), nil
+	}
+	return nil, nil
+}
+
+// SetEncryption sets an encryption setting
+func (cm *ConfigManager) SetEncryption(key, value string) error {
+	switch key {
+	case "encryption_enabled":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean value: %s", value)
+		}
+		cm.config.Encryption.Enabled = b
+	case "encryption_key_file":
+		if value != "" {
+			if _, err := os.Stat(value); err != nil {
+				return fmt.Errorf("key file not found: %s", value)
+			}
+		}
+		cm.config.Encryption.KeyFile = value
+	case "encryption_passphrase":
+		cm.config.Encryption.Passphrase = value
+	default:
+		return fmt.Errorf("unknown encryption setting: %s", key)
+	}
+
+	return cm.Save()
+}
@@ -0,0 +1,295 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Setting is a config value that an environment variable can override.
+// Precedence is flags > environment > project file > config profile > config
+// file > built-in defaults.
+type Setting struct {
+	Key    string // path in the config file
+	Env    string
+	Secret bool // value is never printed
+
+	get func(*Config) string
+	set func(*Config, string) error
+}
+
+func stringSetting(key, env string, field func(*Config) *string) Setting {
+	return Setting{
+		Key: key,
+		Env: env,
+		get: func(c *Config) string { return *field(c) },
+		set: func(c *Config, value string) error {
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+func boolSetting(key, env string, field func(*Config) *bool) Setting {
+	return Setting{
+		Key: key,
+		Env: env,
+		get: func(c *Config) string { return strconv.FormatBool(*field(c)) },
+		set: func(c *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("expected true or false, got %q", value)
+			}
+			*field(c) = b
+			return nil
+		},
+	}
+}
+
+func intSetting(key, env string, field func(*Config) *int) Setting {
+	return Setting{
+		Key: key,
+		Env: env,
+		get: func(c *Config) string { return strconv.Itoa(*field(c)) },
+		set: func(c *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("expected a number, got %q", value)
+			}
+			*field(c) = n
+			return nil
+		},
+	}
+}
+
+// optionalBoolSetting is a bool setting whose unset value reads as def
+func optionalBoolSetting(key, env string, def bool, field func(*Config) **bool) Setting {
+	return Setting{
+		Key: key,
+		Env: env,
+		get: func(c *Config) string {
+			if *field(c) == nil {
+				return strconv.FormatBool(def)
+			}
+			return strconv.FormatBool(**field(c))
+		},
+		set: func(c *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("expected true or false, got %q", value)
+			}
+			*field(c) = &b
+			return nil
+		},
+	}
+}
+
+func listSetting(key, env string, field func(*Config) *[]string) Setting {
+	return Setting{
+		Key: key,
+		Env: env,
+		get: func(c *Config) string { return strings.Join(*field(c), ",") },
+		set: func(c *Config, value string) error {
+			var list []string
+			for _, entry := range strings.Split(value, ",") {
+				if entry = strings.TrimSpace(entry); entry != "" {
+					list = append(list, entry)
+				}
+			}
+			*field(c) = list
+			return nil
+		},
+	}
+}
+
+// Settings lists the config values that can be set through NETCRATE_*
+// environment variables
+var Settings = []Setting{
+	stringSetting("current_rate_profile", "NETCRATE_RATE_PROFILE", func(c *Config) *string { return &c.CurrentRateProfile }),
+	stringSetting("preferences.default_output_format", "NETCRATE_OUTPUT_FORMAT", func(c *Config) *string { return &c.Preferences.DefaultOutputFormat }),
+	boolSetting("preferences.show_banners", "NETCRATE_SHOW_BANNERS", func(c *Config) *bool { return &c.Preferences.ShowBanners }),
+	boolSetting("preferences.color_output", "NETCRATE_COLOR", func(c *Config) *bool { return &c.Preferences.ColorOutput }),
+	optionalBoolSetting("preferences.emoji_output", "NETCRATE_EMOJI", true, func(c *Config) **bool { return &c.Preferences.EmojiOutput }),
+	boolSetting("preferences.verbose_mode", "NETCRATE_VERBOSE", func(c *Config) *bool { return &c.Preferences.VerboseMode }),
+	stringSetting("preferences.language", "NETCRATE_LANG", func(c *Config) *string { return &c.Preferences.Language }),
+	stringSetting("preferences.report_language", "NETCRATE_REPORT_LANG", func(c *Config) *string { return &c.Preferences.ReportLanguage }),
+	stringSetting("preferences.risk_rules_file", "NETCRATE_RISK_RULES", func(c *Config) *string { return &c.Preferences.RiskRulesFile }),
+	boolSetting("preferences.quick_exclude_self", "NETCRATE_EXCLUDE_SELF", func(c *Config) *bool { return &c.Preferences.QuickExcludeSelf }),
+	boolSetting("preferences.quick_exclude_gateway", "NETCRATE_EXCLUDE_GATEWAY", func(c *Config) *bool { return &c.Preferences.QuickExcludeGateway }),
+	listSetting("preferences.do_not_scan", "NETCRATE_DO_NOT_SCAN", func(c *Config) *[]string { return &c.Preferences.DoNotScan }),
+	boolSetting("preferences.results_db", "NETCRATE_RESULTS_DB", func(c *Config) *bool { return &c.Preferences.ResultsDB }),
+	boolSetting("preferences.record_public_ip", "NETCRATE_RECORD_PUBLIC_IP", func(c *Config) *bool { return &c.Preferences.RecordPublicIP }),
+	stringSetting("preferences.results_dir", "NETCRATE_RESULTS_DIR", func(c *Config) *string { return &c.Preferences.ResultsDir }),
+	optionalBoolSetting("preferences.auto_save", "NETCRATE_AUTO_SAVE", true, func(c *Config) **bool { return &c.Preferences.AutoSave }),
+	secret(stringSetting("notifications.webhook_url", "NETCRATE_NOTIFY_WEBHOOK", func(c *Config) *string { return &c.Notifications.WebhookURL })),
+	secret(stringSetting("notifications.slack_url", "NETCRATE_NOTIFY_SLACK", func(c *Config) *string { return &c.Notifications.SlackURL })),
+	secret(stringSetting("notifications.discord_url", "NETCRATE_NOTIFY_DISCORD", func(c *Config) *string { return &c.Notifications.DiscordURL })),
+	stringSetting("notifications.trigger", "NETCRATE_NOTIFY_TRIGGER", func(c *Config) *string { return &c.Notifications.Trigger }),
+	intSetting("retention.max_runs", "NETCRATE_RETENTION_MAX_RUNS", func(c *Config) *int { return &c.Retention.MaxRuns }),
+	stringSetting("retention.max_age", "NETCRATE_RETENTION_MAX_AGE", func(c *Config) *string { return &c.Retention.MaxAge }),
+	stringSetting("retention.max_disk", "NETCRATE_RETENTION_MAX_DISK", func(c *Config) *string { return &c.Retention.MaxDisk }),
+	boolSetting("encryption.enabled", "NETCRATE_ENCRYPT", func(c *Config) *bool { return &c.Encryption.Enabled }),
+	stringSetting("encryption.key_file", "NETCRATE_KEY_FILE", func(c *Config) *string { return &c.Encryption.KeyFile }),
+	secret(stringSetting("encryption.passphrase", PassphraseEnv, func(c *Config) *string { return &c.Encryption.Passphrase })),
+	stringSetting("sink.url", "NETCRATE_SINK_URL", func(c *Config) *string { return &c.Sink.URL }),
+	secret(stringSetting("proxy.url", "NETCRATE_PROXY", func(c *Config) *string { return &c.Proxy.URL })),
+	listSetting("proxy.no_proxy", "NETCRATE_NO_PROXY", func(c *Config) *[]string { return &c.Proxy.NoProxy }),
+	listSetting("dns.resolvers", "NETCRATE_DNS", func(c *Config) *[]string { return &c.DNS.Resolvers }),
+	listSetting("egress.stun_servers", "NETCRATE_STUN_SERVERS", func(c *Config) *[]string { return &c.Egress.STUNServers }),
+	listSetting("egress.ip_services", "NETCRATE_IP_SERVICES", func(c *Config) *[]string { return &c.Egress.IPServices }),
+	boolSetting("updates.disabled", "NETCRATE_UPDATES_DISABLED", func(c *Config) *bool { return &c.Updates.Disabled }),
+	stringSetting("updates.repository", "NETCRATE_UPDATE_REPOSITORY", func(c *Config) *string { return &c.Updates.Repository }),
+	stringSetting("syslog.address", "NETCRATE_SYSLOG_ADDRESS", func(c *Config) *string { return &c.Syslog.Address }),
+	stringSetting("syslog.protocol", "NETCRATE_SYSLOG_PROTOCOL", func(c *Config) *string { return &c.Syslog.Protocol }),
+	stringSetting("syslog.format", "NETCRATE_SYSLOG_FORMAT", func(c *Config) *string { return &c.Syslog.Format }),
+	stringSetting("logging.level", "NETCRATE_LOG_LEVEL", func(c *Config) *string { return &c.Logging.Level }),
+	stringSetting("logging.format", "NETCRATE_LOG_FORMAT", func(c *Config) *string { return &c.Logging.Format }),
+	boolSetting("logging.persist", "NETCRATE_LOG_PERSIST", func(c *Config) *bool { return &c.Logging.Persist }),
+	stringSetting("reports.theme", "NETCRATE_REPORT_THEME", func(c *Config) *string { return &c.Reports.Theme }),
+	stringSetting("reports.format", "NETCRATE_REPORT_FORMAT", func(c *Config) *string { return &c.Reports.Format }),
+	stringSetting("reports.directory", "NETCRATE_REPORT_DIR", func(c *Config) *string { return &c.Reports.Directory }),
+	intSetting("reports.history", "NETCRATE_REPORT_HISTORY", func(c *Config) *int { return &c.Reports.History }),
+	stringSetting("compliance.scope_file", "NETCRATE_SCOPE_FILE", func(c *Config) *string { return &c.Compliance.ScopeFile }),
+	stringSetting("compliance.authorized_by", "NETCRATE_AUTHORIZED_BY", func(c *Config) *string { return &c.Compliance.AuthorizedBy }),
+	stringSetting("compliance.ticket", "NETCRATE_TICKET", func(c *Config) *string { return &c.Compliance.Ticket }),
+}
+
+// RunSetting is a run flag that an environment variable gives when the flag
+// is not on the command line. It has no config key of its own: without the
+// flag or the variable, the value comes from the command's defaults or the
+// rate profile.
+type RunSetting struct {
+	Flag string
+	Env  string
+	get  func(RateProfile) string
+}
+
+// RunSettings lists the run flags that can be set through NETCRATE_*
+// environment variables
+var RunSettings = []RunSetting{
+	{Flag: "rate", Env: "NETCRATE_RATE", get: func(p RateProfile) string { return strconv.Itoa(p.Rate) }},
+	{Flag: "concurrency", Env: "NETCRATE_CONCURRENCY", get: func(p RateProfile) string { return strconv.Itoa(p.Concurrency) }},
+	{Flag: "timeout", Env: "NETCRATE_TIMEOUT", get: func(p RateProfile) string { return p.Timeout.String() }},
+}
+
+func secret(s Setting) Setting {
+	s.Secret = true
+	return s
+}
+
+// Origin names where an effective config value came from
+const (
+	OriginDefault = "default"
+	OriginFile    = "config file"
+	OriginProfile = "config profile"
+	OriginProject = "project file"
+	OriginEnv     = "environment"
+
+	// OriginRateProfile is where run flags come from without a variable
+	OriginRateProfile = "rate profile"
+)
+
+// ValueOrigin is an effective config value and where it came from
+type ValueOrigin struct {
+	Key    string
+	Value  string
+	Origin string // OriginDefault, OriginFile, OriginProfile, OriginProject, OriginEnv or OriginRateProfile
+	Env    string // the variable that set it, or could set it
+}
+
+// override is a setting taken from the environment, the project file or a
+// config profile, with the value it hides
+type override struct {
+	setting   Setting
+	value     string
+	fileValue string
+	origin    string // OriginEnv, OriginProject or OriginProfile
+}
+
+// applyEnv overrides settings with the NETCRATE_* variables that are set.
+// Empty variables are ignored.
+func (cm *ConfigManager) applyEnv() error {
+	applied := len(cm.overrides)
+	for _, setting := range Settings {
+		value, ok := os.LookupEnv(setting.Env)
+		if !ok || value == "" {
+			continue
+		}
+		fileValue := setting.get(cm.config)
+		if err := setting.set(cm.config, value); err != nil {
+			return fmt.Errorf("$%s: %w", setting.Env, err)
+		}
+		cm.overrides = append(cm.overrides, override{setting: setting, value: setting.get(cm.config), fileValue: fileValue, origin: OriginEnv})
+	}
+	if len(cm.overrides) == applied {
+		return nil
+	}
+	if err := ValidateConfig(cm.config); err != nil {
+		if fieldErr, ok := err.(*FieldError); ok {
+			for _, override := range cm.overrides[applied:] {
+				if override.setting.Key == fieldErr.Field {
+					return fmt.Errorf("$%s: %s", override.setting.Env, fieldErr.Message)
+				}
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// fileConfig returns the config as it should be saved: values taken from the
+// environment, the project file or a profile are replaced by those of the config file
+// unless they were changed since, e.g. with config set
+func (cm *ConfigManager) fileConfig() *Config {
+	if len(cm.overrides) == 0 {
+		return cm.config
+	}
+	saved := *cm.config
+	// Latest first, since a later override hides the value of an earlier one
+	for i := len(cm.overrides) - 1; i >= 0; i-- {
+		override := cm.overrides[i]
+		if override.setting.get(&saved) == override.value {
+			override.setting.set(&saved, override.fileValue)
+		}
+	}
+	return &saved
+}
+
+// Origins reports where each setting of the effective configuration comes
+// from: the environment, the project file, a config profile, the config file
+// or the built-in defaults. The run flags of RunSettings follow, taken from
+// the environment or the current rate profile.
+func (cm *ConfigManager) Origins() []ValueOrigin {
+	overridden := make(map[string]string, len(cm.overrides))
+	for _, override := range cm.overrides {
+		overridden[override.setting.Key] = override.origin
+	}
+	defaults := cm.createDefaultConfig()
+	file := cm.fileConfig()
+
+	origins := make([]ValueOrigin, 0, len(Settings))
+	for _, setting := range Settings {
+		origin := ValueOrigin{Key: setting.Key, Value: setting.get(cm.config), Origin: OriginFile, Env: setting.Env}
+		switch {
+		case overridden[setting.Key] != "":
+			origin.Origin = overridden[setting.Key]
+		case setting.get(file) == setting.get(defaults):
+			origin.Origin = OriginDefault
+		}
+		if setting.Secret && origin.Value != "" {
+			origin.Value = "(set)"
+		}
+		origins = append(origins, origin)
+	}
+
+	profile := cm.GetCurrentRateProfile()
+	for _, setting := range RunSettings {
+		origin := ValueOrigin{Key: "--" + setting.Flag, Value: setting.get(profile), Origin: OriginRateProfile, Env: setting.Env}
+		if value := os.Getenv(setting.Env); value != "" {
+			origin.Value, origin.Origin = value, OriginEnv
+		}
+		origins = append(origins, origin)
+	}
+	return origins
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// LoggingConfig configures the diagnostic log written to stderr, which
+// --quiet, --verbose and --log-format override for one command
+type LoggingConfig struct {
+	Level   string `yaml:"level" json:"level,omitempty"`     // debug, info (default), warn, error
+	Format  string `yaml:"format" json:"format,omitempty"`   // text (default) or json
+	Persist bool   `yaml:"persist" json:"persist,omitempty"` // keep a debug log of every saved run in ~/.netcrate/logs
+}
+
+// EffectiveLevel returns the log level, applying the default
+func (l LoggingConfig) EffectiveLevel() string {
+	if l.Level != "" {
+		return l.Level
+	}
+	return "info"
+}
+
+// EffectiveFormat returns the log format, applying the default
+func (l LoggingConfig) EffectiveFormat() string {
+	if l.Format != "" {
+		return l.Format
+	}
+	return "text"
+}
+
+// SetLogging sets a logging setting
+func (cm *ConfigManager) SetLogging(key, value string) error {
+	logging := &cm.config.Logging
+
+	switch key {
+	case "log_level":
+		if err := oneOf("logging.level", value, "", "debug", "info", "warn", "error"); err != nil {
+			return err
+		}
+		logging.Level = value
+	case "log_format":
+		if err := oneOf("logging.format", value, "", "text", "json"); err != nil {
+			return err
+		}
+		logging.Format = value
+	case "log_persist":
+		persist, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for log_persist: %s (expected true or false)", value)
+		}
+		logging.Persist = persist
+	default:
+		return fmt.Errorf("unknown logging setting: %s", key)
+	}
+
+	return cm.Save()
+}
@@ -1,11 +1,15 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v2"
 )
 
 // RateProfile defines different speed presets for scanning
@@ -16,6 +20,7 @@ type RateProfile struct {
 	Concurrency int           `yaml:"concurrency" json:"concurrency"` // concurrent workers
 	Timeout     time.Duration `yaml:"timeout" json:"timeout"`         // per-operation timeout
 	Retries     int           `yaml:"retries" json:"retries"`         // retry attempts
+	MaxPerHost  int           `yaml:"max_per_host" json:"max_per_host,omitempty"` // concurrent probes per host, 0 for no cap
 }
 
 // Config represents the persistent NetCrate configuration
@@ -32,6 +37,58 @@ type Config struct {
 	
 	// Session settings
 	Session            SessionConfig      `yaml:"session" json:"session"`
+	
+	// Run completion notifications
+	Notifications      NotificationConfig `yaml:"notifications" json:"notifications"`
+	
+	// Saved run retention
+	Retention          RetentionConfig    `yaml:"retention" json:"retention"`
+	
+	// Saved run encryption at rest
+	Encryption         EncryptionConfig   `yaml:"encryption" json:"encryption"`
+	
+	// HTTP result sink
+	Sink               SinkConfig         `yaml:"sink" json:"sink"`
+	
+	// Syslog/CEF sink
+	Syslog             SyslogConfig       `yaml:"syslog" json:"syslog"`
+	
+	// Diagnostic log level, format and per-run persistence
+	Logging            LoggingConfig      `yaml:"logging" json:"logging"`
+	
+	// Outbound proxy and DNS resolvers for application probes
+	Proxy              ProxyConfig        `yaml:"proxy" json:"proxy"`
+	DNS                DNSConfig          `yaml:"dns" json:"dns"`
+	
+	// Public address endpoints for netenv egress detection
+	Egress             EgressConfig       `yaml:"egress" json:"egress"`
+	
+	// Release checks of self-update
+	Updates            UpdateConfig       `yaml:"updates" json:"updates"`
+	
+	// Custom redaction profile for shared exports
+	Redaction          RedactionProfile   `yaml:"redaction" json:"redaction"`
+	
+	// Organization scanning policy
+	Compliance         ComplianceConfig   `yaml:"compliance" json:"compliance"`
+	
+	// Report defaults
+	Reports            ReportConfig       `yaml:"reports" json:"reports"`
+	
+	// Stricter settings for recognized networks
+	Networks           []NetworkOverride  `yaml:"networks" json:"networks,omitempty"`
+	
+	// Named config profiles (work, home, lab) and the one in use
+	CurrentProfile     string                   `yaml:"current_profile" json:"current_profile,omitempty"`
+	Profiles           map[string]ConfigProfile `yaml:"profiles" json:"profiles,omitempty"`
+	
+	// Flag defaults per command, keyed by command path ("quick",
+	// "ops scan ports", "output report") and then flag name
+	Defaults           map[string]map[string]string `yaml:"defaults" json:"defaults,omitempty"`
+	
+	// Shortcuts for frequent command lines, e.g. web-sweep for
+	// "ops scan ports --ports web --fingerprint"
+	Aliases            map[string]string  `yaml:"aliases" json:"aliases,omitempty"`
 }
 
 // UserPreferences stores user configuration choices
@@ -41,6 +98,61 @@ type UserPreferences struct {
 	ColorOutput          bool   `yaml:"color_output" json:"color_output"`
 	VerboseMode          bool   `yaml:"verbose_mode" json:"verbose_mode"`
 	AutoConfirmDangerous bool   `yaml:"auto_confirm_dangerous" json:"auto_confirm_dangerous"`
+	RiskRulesFile        string `yaml:"risk_rules_file" json:"risk_rules_file,omitempty"`
+	Language             string `yaml:"language" json:"language,omitempty"` // "en" (default) or "zh-CN"
+	ReportLanguage       string `yaml:"report_language" json:"report_language,omitempty"` // language of HTML reports; empty follows Language
+	QuickExcludeSelf     bool     `yaml:"quick_exclude_self" json:"quick_exclude_self,omitempty"`
+	QuickExcludeGateway  bool     `yaml:"quick_exclude_gateway" json:"quick_exclude_gateway,omitempty"`
+	DoNotScan            []string `yaml:"do_not_scan" json:"do_not_scan,omitempty"` // IPs or CIDRs quick mode never probes
+	ResultsDB            bool     `yaml:"results_db" json:"results_db,omitempty"`   // index runs in ~/.netcrate/netcrate.db as they are saved
+	RecordPublicIP       bool     `yaml:"record_public_ip" json:"record_public_ip,omitempty"` // look up the egress IP for each run's environment record
+	ResultsDir           string   `yaml:"results_dir" json:"results_dir,omitempty"`           // where runs are saved; ~/.netcrate/runs when empty
+	AutoSave             *bool    `yaml:"auto_save,omitempty" json:"auto_save,omitempty"`     // save every ops run unless --save=false; true when unset
+	EmojiOutput          *bool    `yaml:"emoji_output,omitempty" json:"emoji_output,omitempty"` // emoji in messages unless --no-emoji; true when unset
+}
+
+// SaveRuns reports whether ops runs are saved when --save is not given
+func (p UserPreferences) SaveRuns() bool {
+	return p.AutoSave == nil || *p.AutoSave
+}
+
+// Emoji reports whether messages carry emoji when --no-emoji is not given
+func (p UserPreferences) Emoji() bool {
+	return p.EmojiOutput == nil || *p.EmojiOutput
+}
+
+// EffectiveResultsDir returns the results directory with ~ expanded, or ""
+// for the default
+func (p UserPreferences) EffectiveResultsDir() string {
+	return expandHome(p.ResultsDir)
+}
+
+// NotificationConfig configures alerts sent when a run completes
+type NotificationConfig struct {
+	WebhookURL string `yaml:"webhook_url" json:"webhook_url,omitempty"` // receives the full summary as JSON
+	SlackURL   string `yaml:"slack_url" json:"slack_url,omitempty"`     // Slack incoming webhook
+	DiscordURL string `yaml:"discord_url" json:"discord_url,omitempty"` // Discord channel webhook
+	Trigger    string `yaml:"trigger" json:"trigger,omitempty"`         // "new-critical" (default) or "always"
+}
+
+// Redacted returns the settings with the webhook URLs cut to their scheme
+// and host, since anyone holding a full webhook URL can post to it
+func (n NotificationConfig) Redacted() NotificationConfig {
+	n.WebhookURL = redactWebhookURL(n.WebhookURL)
+	n.SlackURL = redactWebhookURL(n.SlackURL)
+	n.DiscordURL = redactWebhookURL(n.DiscordURL)
+	return n
+}
+
+func redactWebhookURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "(hidden)"
+	}
+	return u.Scheme + "://" + u.Host + "/(hidden)"
 }
 
 // SessionConfig stores session-specific settings
@@ -54,7 +166,11 @@ type SessionConfig struct {
 // ConfigManager handles configuration persistence
 type ConfigManager struct {
 	configPath string
-	config     *Config
+	config     *Config        // effective configuration
+	project    *ProjectConfig // ./netcrate.yaml, if there is one
+	profile    string         // config profile in effect, if any
+	heldLevel  string         // compliance level of the profile awaiting the policy token
+	overrides  []override     // settings taken from the profile, the project file and the environment
 }
 
 // Default rate profiles
@@ -101,7 +217,7 @@ func NewConfigManager() (*ConfigManager, error) {
 	}
 	
 	configDir := filepath.Join(homeDir, ".netcrate")
-	configPath := filepath.Join(configDir, "config.json")
+	configPath := filepath.Join(configDir, "config.yaml")
 	
 	// Ensure config directory exists
 	if err := os.MkdirAll(configDir, 0755); err != nil {
@@ -112,58 +228,97 @@ func NewConfigManager() (*ConfigManager, error) {
 		configPath: configPath,
 	}
 	
-	// Load existing config or create default
+	// Load existing config or create default. An invalid config is reported
+	// rather than replaced, so no settings are lost to a typo.
 	if err := cm.load(); err != nil {
-		// Create default config if load fails
+		if err != errNoConfig {
+			return nil, err
+		}
 		cm.config = cm.createDefaultConfig()
 		if err := cm.Save(); err != nil {
 			return nil, fmt.Errorf("failed to save default config: %w", err)
 		}
 	}
 	
+	if err := cm.applyConfigProfile(); err != nil {
+		return nil, err
+	}
+	if err := cm.applyProject(); err != nil {
+		return nil, err
+	}
+	if err := cm.applyEnv(); err != nil {
+		return nil, err
+	}
+	
 	return cm, nil
 }
 
+// ConfigPath returns the location of the config file
+func ConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".netcrate", "config.yaml"), nil
+}
+
 // load reads configuration from disk
 func (cm *ConfigManager) load() error {
 	data, err := os.ReadFile(cm.configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("config file does not exist")
+			return cm.loadLegacy()
 		}
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 	
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return fmt.Errorf("failed to parse config file: %w", err)
+	config, migrated, err := parseConfig(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", cm.configPath, err)
 	}
 	
-	cm.config = &config
+	cm.config = config
+	if migrated {
+		// Keep the file as it was in case the upgrade loses something
+		if _, err := cm.Backup(); err != nil {
+			return err
+		}
+		return cm.Save()
+	}
 	return nil
 }
 
 // Save writes configuration to disk
 func (cm *ConfigManager) Save() error {
 	cm.config.LastUpdated = time.Now()
-	cm.config.Version = "1.0"
+	cm.config.Version = SchemaVersion
 	
-	data, err := json.MarshalIndent(cm.config, "", "  ")
+	// Values from the environment are not written to the file
+	data, err := yaml.Marshal(cm.fileConfig())
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
+	data = append([]byte(configHeader), data...)
 	
 	if err := os.WriteFile(cm.configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 	
+	// Stored secrets must not be readable by other users
+	if cm.config.Encryption.Passphrase != "" || len(cm.config.Sink.Headers) > 0 {
+		if err := os.Chmod(cm.configPath, 0600); err != nil {
+			return fmt.Errorf("failed to restrict config file permissions: %w", err)
+		}
+	}
+	
 	return nil
 }
 
 // createDefaultConfig creates a default configuration
 func (cm *ConfigManager) createDefaultConfig() *Config {
+	autoSave := true
 	return &Config{
-		Version:            "1.0",
+		Version:            SchemaVersion,
 		LastUpdated:        time.Now(),
 		CurrentRateProfile: "medium", // Default to medium speed
 		RateProfiles:       DefaultRateProfiles,
@@ -173,6 +328,8 @@ func (cm *ConfigManager) createDefaultConfig() *Config {
 			ColorOutput:          true,
 			VerboseMode:          false,
 			AutoConfirmDangerous: false,
+			Language:             "en",
+			AutoSave:             &autoSave,
 		},
 		Session: SessionConfig{
 			RecentTargets:  make([]string, 0),
@@ -191,6 +348,21 @@ func (cm *ConfigManager) GetCurrentRateProfile() RateProfile {
 	return profile
 }
 
+// GetRateProfile returns a rate profile by name
+func (cm *ConfigManager) GetRateProfile(name string) (RateProfile, error) {
+	profile, exists := cm.config.RateProfiles[name]
+	if !exists {
+		return RateProfile{}, fmt.Errorf("rate profile '%s' does not exist", name)
+	}
+	return profile, nil
+}
+
+// IsBuiltinProfile reports whether a rate profile ships with NetCrate
+func IsBuiltinProfile(name string) bool {
+	_, builtin := DefaultRateProfiles[name]
+	return builtin
+}
+
 // SetCurrentRateProfile sets the active rate profile and persists it
 func (cm *ConfigManager) SetCurrentRateProfile(profileName string) error {
 	// Check if profile exists
@@ -202,13 +374,39 @@ func (cm *ConfigManager) SetCurrentRateProfile(profileName string) error {
 	return cm.Save()
 }
 
+// ProfileNames returns the names of the rate profiles from slowest to fastest
+func (cm *ConfigManager) ProfileNames() []string {
+	names := make([]string, 0, len(cm.config.RateProfiles))
+	for name := range cm.config.RateProfiles {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := cm.config.RateProfiles[names[i]], cm.config.RateProfiles[names[j]]
+		if a.Rate != b.Rate {
+			return a.Rate < b.Rate
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
 // GetAvailableProfiles returns all available rate profiles
 func (cm *ConfigManager) GetAvailableProfiles() map[string]RateProfile {
 	return cm.config.RateProfiles
 }
 
-// AddCustomProfile adds a custom rate profile
+// AddCustomProfile adds a custom rate profile, replacing a custom profile
+// of the same name
 func (cm *ConfigManager) AddCustomProfile(name string, profile RateProfile) error {
+	if IsBuiltinProfile(name) {
+		return fmt.Errorf("cannot redefine built-in profile '%s'", name)
+	}
+	if profile.Rate <= 0 || profile.Concurrency <= 0 {
+		return fmt.Errorf("rate and concurrency must be positive")
+	}
+	if profile.Timeout < 0 || profile.Retries < 0 || profile.MaxPerHost < 0 {
+		return fmt.Errorf("timeout, retries and max per host cannot be negative")
+	}
 	if cm.config.Session.CustomProfiles == nil {
 		cm.config.Session.CustomProfiles = make(map[string]RateProfile)
 	}
@@ -227,8 +425,8 @@ func (cm *ConfigManager) RemoveCustomProfile(name string) error {
 		return fmt.Errorf("cannot remove default profile '%s'", name)
 	}
 	
-	// Check if profile exists in custom profiles
-	if _, exists := cm.config.Session.CustomProfiles[name]; !exists {
+	// Custom profiles may also have been added to the config file directly
+	if _, exists := cm.config.RateProfiles[name]; !exists {
 		return fmt.Errorf("custom profile '%s' does not exist", name)
 	}
 	
@@ -271,6 +469,50 @@ func (cm *ConfigManager) SetPreference(key string, value interface{}) error {
 		if b, ok := value.(bool); ok {
 			cm.config.Preferences.AutoConfirmDangerous = b
 		}
+	case "language":
+		if str, ok := value.(string); ok {
+			cm.config.Preferences.Language = str
+		}
+	case "report_language":
+		if str, ok := value.(string); ok {
+			cm.config.Preferences.ReportLanguage = str
+		}
+	case "risk_rules_file":
+		if str, ok := value.(string); ok {
+			cm.config.Preferences.RiskRulesFile = str
+		}
+	case "quick_exclude_self":
+		if b, ok := value.(bool); ok {
+			cm.config.Preferences.QuickExcludeSelf = b
+		}
+	case "quick_exclude_gateway":
+		if b, ok := value.(bool); ok {
+			cm.config.Preferences.QuickExcludeGateway = b
+		}
+	case "do_not_scan":
+		if list, ok := value.([]string); ok {
+			cm.config.Preferences.DoNotScan = list
+		}
+	case "results_db":
+		if b, ok := value.(bool); ok {
+			cm.config.Preferences.ResultsDB = b
+		}
+	case "record_public_ip":
+		if b, ok := value.(bool); ok {
+			cm.config.Preferences.RecordPublicIP = b
+		}
+	case "results_dir":
+		if str, ok := value.(string); ok {
+			cm.config.Preferences.ResultsDir = str
+		}
+	case "auto_save":
+		if b, ok := value.(bool); ok {
+			cm.config.Preferences.AutoSave = &b
+		}
+	case "emoji_output":
+		if b, ok := value.(bool); ok {
+			cm.config.Preferences.EmojiOutput = &b
+		}
 	default:
 		return fmt.Errorf("unknown preference: %s", key)
 	}
@@ -278,6 +520,27 @@ func (cm *ConfigManager) SetPreference(key string, value interface{}) error {
 	return cm.Save()
 }
 
+// SetNotification sets a notification setting
+func (cm *ConfigManager) SetNotification(key, value string) error {
+	switch key {
+	case "notify_webhook":
+		cm.config.Notifications.WebhookURL = value
+	case "notify_slack":
+		cm.config.Notifications.SlackURL = value
+	case "notify_discord":
+		cm.config.Notifications.DiscordURL = value
+	case "notify_trigger":
+		if value != "always" && value != "new-critical" {
+			return fmt.Errorf("invalid notification trigger: %s (expected always or new-critical)", value)
+		}
+		cm.config.Notifications.Trigger = value
+	default:
+		return fmt.Errorf("unknown notification setting: %s", key)
+	}
+	
+	return cm.Save()
+}
+
 // AddRecentTarget adds a target to the recent targets list
 func (cm *ConfigManager) AddRecentTarget(target string) error {
 	// Remove target if it already exists
@@ -323,7 +586,11 @@ func (cm *ConfigManager) PrintConfig() {
 	fmt.Printf("======================\n")
 	fmt.Printf("Config file: %s\n", cm.configPath)
 	fmt.Printf("Version: %s\n", cm.config.Version)
-	fmt.Printf("Last updated: %s\n\n", cm.config.LastUpdated.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Last updated: %s\n", cm.config.LastUpdated.Format("2006-01-02 15:04:05"))
+	if cm.profile != "" {
+		fmt.Printf("Config profile: %s\n", cm.profile)
+	}
+	fmt.Println()
 	
 	fmt.Printf("Rate Profiles:\n")
 	fmt.Printf("--------------\n")
@@ -337,7 +604,8 @@ func (cm *ConfigManager) PrintConfig() {
 	fmt.Printf("  • Retries: %d attempts\n\n", current.Retries)
 	
 	fmt.Printf("Available Profiles:\n")
-	for name, profile := range cm.config.RateProfiles {
+	for _, name := range cm.ProfileNames() {
+		profile := cm.config.RateProfiles[name]
 		status := ""
 		if name == cm.config.CurrentRateProfile {
 			status = " (current)"
@@ -354,6 +622,265 @@ func (cm *ConfigManager) PrintConfig() {
 	fmt.Printf("  • Color output: %v\n", cm.config.Preferences.ColorOutput)
 	fmt.Printf("  • Verbose mode: %v\n", cm.config.Preferences.VerboseMode)
 	fmt.Printf("  • Auto-confirm dangerous: %v\n", cm.config.Preferences.AutoConfirmDangerous)
+	if cm.config.Preferences.Language != "" {
+		fmt.Printf("  • Language: %s\n", cm.config.Preferences.Language)
+	}
+	if cm.config.Preferences.ReportLanguage != "" {
+		fmt.Printf("  • Report language: %s\n", cm.config.Preferences.ReportLanguage)
+	}
+	if cm.config.Preferences.RiskRulesFile != "" {
+		fmt.Printf("  • Risk rules file: %s\n", cm.config.Preferences.RiskRulesFile)
+	}
+	fmt.Printf("  • Quick: exclude self: %v, exclude gateway: %v\n",
+		cm.config.Preferences.QuickExcludeSelf, cm.config.Preferences.QuickExcludeGateway)
+	if len(cm.config.Preferences.DoNotScan) > 0 {
+		fmt.Printf("  • Do not scan: %s\n", strings.Join(cm.config.Preferences.DoNotScan, ", "))
+	}
+	fmt.Printf("  • Results database: %v\n", cm.config.Preferences.ResultsDB)
+	fmt.Printf("  • Record public IP: %v\n", cm.config.Preferences.RecordPublicIP)
+	fmt.Printf("  • Results directory: %s\n", valueOr(cm.config.Preferences.ResultsDir, "~/.netcrate/runs"))
+	fmt.Printf("  • Auto-save ops runs: %v\n", cm.config.Preferences.SaveRuns())
+	
+	logging := cm.config.Logging
+	fmt.Printf("\nLogging:\n")
+	fmt.Printf("--------\n")
+	fmt.Printf("  • Level: %s, format: %s\n", logging.EffectiveLevel(), logging.EffectiveFormat())
+	fmt.Printf("  • Keep run logs: %v\n", logging.Persist)
+	
+	notifications := cm.config.Notifications.Redacted()
+	if notifications.WebhookURL != "" || notifications.SlackURL != "" || notifications.DiscordURL != "" {
+		trigger := notifications.Trigger
+		if trigger == "" {
+			trigger = "new-critical"
+		}
+		fmt.Printf("\nNotifications (%s):\n", trigger)
+		fmt.Printf("--------------\n")
+		if notifications.WebhookURL != "" {
+			fmt.Printf("  • Webhook: %s\n", notifications.WebhookURL)
+		}
+		if notifications.SlackURL != "" {
+			fmt.Printf("  • Slack: %s\n", notifications.SlackURL)
+		}
+		if notifications.DiscordURL != "" {
+			fmt.Printf("  • Discord: %s\n", notifications.DiscordURL)
+		}
+	}
+	
+	retention := cm.config.Retention
+	if retention.MaxRuns > 0 || retention.MaxAge != "" || retention.MaxDisk != "" {
+		fmt.Printf("\nRun Retention:\n")
+		fmt.Printf("--------------\n")
+		if retention.MaxRuns > 0 {
+			fmt.Printf("  • Max runs: %d\n", retention.MaxRuns)
+		}
+		if retention.MaxAge != "" {
+			fmt.Printf("  • Max age: %s\n", retention.MaxAge)
+		}
+		if retention.MaxDisk != "" {
+			fmt.Printf("  • Max disk: %s\n", retention.MaxDisk)
+		}
+	}
+	
+	sink := cm.config.Sink
+	if sink.URL != "" {
+		fmt.Printf("\nResult Sink:\n")
+		fmt.Printf("------------\n")
+		fmt.Printf("  • URL: %s\n", sink.URL)
+		fmt.Printf("  • Batch size: %d, flush interval: %s, retries: %d\n",
+			sink.EffectiveBatchSize(), sink.EffectiveFlushInterval(), sink.EffectiveRetries())
+		for name := range sink.Headers {
+			fmt.Printf("  • Header: %s: (set)\n", name)
+		}
+	}
+	
+	if syslog := cm.config.Syslog; syslog.Address != "" {
+		fmt.Printf("\nSyslog Sink:\n")
+		fmt.Printf("------------\n")
+		fmt.Printf("  • Collector: %s (%s, %s)\n", syslog.Address,
+			syslog.EffectiveProtocol(), syslog.EffectiveFormat())
+	}
+	
+	if proxy, dns := cm.config.Proxy, cm.config.DNS; proxy.URL != "" || len(dns.Resolvers) > 0 {
+		fmt.Printf("\nProxy and DNS:\n")
+		fmt.Printf("--------------\n")
+		if proxy.URL != "" {
+			fmt.Printf("  • Proxy: %s\n", RedactProxyURL(proxy.URL))
+			if len(proxy.NoProxy) > 0 {
+				fmt.Printf("  • No proxy for: %s\n", strings.Join(proxy.NoProxy, ", "))
+			}
+		}
+		if len(dns.Resolvers) > 0 {
+			fmt.Printf("  • DNS resolvers: %s\n", strings.Join(dns.Resolvers, ", "))
+		}
+	}
+	
+	if egress := cm.config.Egress; len(egress.STUNServers) > 0 || len(egress.IPServices) > 0 {
+		fmt.Printf("\nEgress Detection:\n")
+		fmt.Printf("-----------------\n")
+		if len(egress.STUNServers) > 0 {
+			fmt.Printf("  • STUN servers: %s\n", strings.Join(egress.STUNServers, ", "))
+		}
+		if len(egress.IPServices) > 0 {
+			fmt.Printf("  • Public IP services: %s\n", strings.Join(egress.IPServices, ", "))
+		}
+	}
+	
+	if updates := cm.config.Updates; updates != (UpdateConfig{}) {
+		fmt.Printf("\nUpdates:\n")
+		fmt.Printf("--------\n")
+		if updates.Disabled {
+			fmt.Printf("  • Update checks: disabled\n")
+		}
+		if updates.Repository != "" {
+			fmt.Printf("  • Release repository: %s\n", updates.Repository)
+		}
+	}
+	
+	if redaction := cm.config.Redaction; redaction != (RedactionProfile{}) {
+		fmt.Printf("\nCustom Redaction Profile:\n")
+		fmt.Printf("-------------------------\n")
+		fmt.Printf("  • IP octets masked: %d\n", redaction.IPOctets)
+		fmt.Printf("  • Hostnames: %v, banners: %v, MACs: %v\n", redaction.Hostnames, redaction.Banners, redaction.MACs)
+	}
+	
+	if compliance := cm.config.Compliance; compliance.Level != "" || compliance.AllowPublic || len(compliance.AllowedRanges) > 0 ||
+		len(compliance.BlockedRanges) > 0 || compliance.MaxRate > 0 || compliance.MaxConcurrency > 0 ||
+		compliance.MaxTargets > 0 || compliance.MaxPortsPerHost > 0 || compliance.RequireConfirmation || compliance.LookupOwner || compliance.ScopeFile != "" ||
+		len(compliance.Windows) > 0 || compliance.AuthorizedBy != "" || compliance.Ticket != "" {
+		fmt.Printf("\nCompliance Policy:\n")
+		fmt.Printf("------------------\n")
+		fmt.Printf("  • Level: %s", compliance.EffectiveLevel())
+		if compliance.PolicyTokenHash != "" {
+			fmt.Printf(" (policy token set)")
+		}
+		fmt.Printf("\n")
+		fmt.Printf("  • Allow public targets: %v, require confirmation: %v\n", compliance.AllowPublic, compliance.RequireConfirmation)
+		if compliance.LookupOwner {
+			fmt.Printf("  • Owners of public targets looked up over RDAP\n")
+		}
+		if len(compliance.AllowedRanges) > 0 {
+			fmt.Printf("  • Allowed ranges: %s\n", strings.Join(compliance.AllowedRanges, ", "))
+		}
+		if len(compliance.BlockedRanges) > 0 {
+			fmt.Printf("  • Blocked ranges: %s\n", strings.Join(compliance.BlockedRanges, ", "))
+		}
+		if compliance.MaxRate > 0 || compliance.MaxConcurrency > 0 {
+			fmt.Printf("  • Max rate: %d pps, max concurrency: %d\n", compliance.MaxRate, compliance.MaxConcurrency)
+		}
+		if compliance.MaxTargets > 0 || compliance.MaxPortsPerHost > 0 {
+			fmt.Printf("  • Max targets per run: %d, max ports per host: %d\n", compliance.MaxTargets, compliance.MaxPortsPerHost)
+		}
+		if compliance.ScopeFile != "" {
+			fmt.Printf("  • Scope file: %s\n", compliance.ScopeFile)
+		}
+		for _, window := range compliance.Windows {
+			fmt.Printf("  • Scan window: %s\n", window)
+		}
+		if compliance.AuthorizedBy != "" || compliance.Ticket != "" {
+			fmt.Printf("  • Authorized by: %s, ticket: %s\n", compliance.AuthorizedBy, compliance.Ticket)
+		}
+	}
+	
+	if report := cm.config.Reports; report != (ReportConfig{}) {
+		fmt.Printf("\nReports:\n")
+		fmt.Printf("--------\n")
+		if report.Theme != "" || report.Format != "" {
+			fmt.Printf("  • Theme: %s, format: %s\n", valueOr(report.Theme, "default"), valueOr(report.Format, "html"))
+		}
+		if report.Directory != "" {
+			fmt.Printf("  • Directory: %s\n", report.Directory)
+		}
+		if report.History > 0 {
+			fmt.Printf("  • Host history: %d runs\n", report.History)
+		}
+	}
+	
+	if len(cm.config.Networks) > 0 {
+		fmt.Printf("\nNetwork Overrides:\n")
+		fmt.Printf("------------------\n")
+		for _, override := range cm.config.Networks {
+			var match []string
+			if override.SSID != "" {
+				match = append(match, "SSID "+override.SSID)
+			}
+			if override.GatewayMAC != "" {
+				match = append(match, "gateway "+override.GatewayMAC)
+			}
+			if override.CIDR != "" {
+				match = append(match, "in "+override.CIDR)
+			}
+			fmt.Printf("  • %s (%s)\n", override.Name, strings.Join(match, ", "))
+			if override.RateProfile != "" {
+				fmt.Printf("    Rate profile: %s\n", override.RateProfile)
+			}
+			if override.MaxRate > 0 || override.MaxConcurrency > 0 {
+				fmt.Printf("    Max rate: %d pps, max concurrency: %d\n", override.MaxRate, override.MaxConcurrency)
+			}
+			if len(override.DoNotScan) > 0 {
+				fmt.Printf("    Do not scan: %s\n", strings.Join(override.DoNotScan, ", "))
+			}
+		}
+	}
+	
+	if project := cm.project; project != nil {
+		fmt.Printf("\nProject (%s):\n", project.path)
+		fmt.Printf("--------%s\n", strings.Repeat("-", len(project.path)+3))
+		if project.Name != "" {
+			fmt.Printf("  • Name: %s\n", project.Name)
+		}
+		if len(project.Scope.Allowed) > 0 {
+			fmt.Printf("  • Scope: %s\n", strings.Join(project.Scope.Allowed, ", "))
+		}
+		if len(project.Scope.Exclude) > 0 {
+			fmt.Printf("  • Excluded: %s\n", strings.Join(project.Scope.Exclude, ", "))
+		}
+		if len(project.Tags) > 0 {
+			fmt.Printf("  • Tags: %s\n", strings.Join(project.Tags, ", "))
+		}
+		if project.OutputDir != "" {
+			fmt.Printf("  • Output directory: %s\n", project.OutputDir)
+		}
+	}
+	
+	if len(cm.config.Defaults) > 0 {
+		fmt.Printf("\nCommand Defaults:\n")
+		fmt.Printf("-----------------\n")
+		commands := make([]string, 0, len(cm.config.Defaults))
+		for command := range cm.config.Defaults {
+			commands = append(commands, command)
+		}
+		sort.Strings(commands)
+		for _, command := range commands {
+			flags := make([]string, 0, len(cm.config.Defaults[command]))
+			for name, value := range cm.config.Defaults[command] {
+				flags = append(flags, fmt.Sprintf("--%s=%s", name, value))
+			}
+			sort.Strings(flags)
+			fmt.Printf("  • %s: %s\n", command, strings.Join(flags, " "))
+		}
+	}
+	
+	if len(cm.config.Aliases) > 0 {
+		fmt.Printf("\nAliases:\n")
+		fmt.Printf("--------\n")
+		for _, name := range cm.AliasNames() {
+			fmt.Printf("  • %s = %s\n", name, cm.config.Aliases[name])
+		}
+	}
+	
+	encryption := cm.config.Encryption
+	if encryption.Enabled {
+		fmt.Printf("\nRun Encryption:\n")
+		fmt.Printf("---------------\n")
+		switch {
+		case encryption.KeyFile != "":
+			fmt.Printf("  • Key file: %s\n", encryption.KeyFile)
+		case encryption.Passphrase != "":
+			fmt.Printf("  • Passphrase: (set)\n")
+		default:
+			fmt.Printf("  • Passphrase: $%s\n", PassphraseEnv)
+		}
+	}
 	
 	if len(cm.config.Session.RecentTargets) > 0 {
 		fmt.Printf("\nRecent Targets:\n")
@@ -366,4 +893,12 @@ func (cm *ConfigManager) PrintConfig() {
 	if cm.config.Session.LastTemplate != "" {
 		fmt.Printf("\nLast Template: %s\n", cm.config.Session.LastTemplate)
 	}
-}
\ No newline at end of file
+}
+
+// valueOr returns value, or fallback when it is empty
+func valueOr(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
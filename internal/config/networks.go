@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// NetworkOverride tightens settings while NetCrate runs on a matching
+// network, e.g. lower rates and extra exclusions on the office LAN. Every
+// criterion that is set must match: the Wi-Fi SSID, the MAC address of the
+// default gateway, and a range holding the local address.
+type NetworkOverride struct {
+	Name       string `yaml:"name" json:"name"`
+	SSID       string `yaml:"ssid" json:"ssid,omitempty"`
+	GatewayMAC string `yaml:"gateway_mac" json:"gateway_mac,omitempty"`
+	CIDR       string `yaml:"cidr" json:"cidr,omitempty"`
+
+	RateProfile    string   `yaml:"rate_profile" json:"rate_profile,omitempty"`       // used unless --profile is given
+	MaxRate        int      `yaml:"max_rate" json:"max_rate,omitempty"`               // packets per second, even with explicit flags
+	MaxConcurrency int      `yaml:"max_concurrency" json:"max_concurrency,omitempty"` // concurrent workers, even with explicit flags
+	DoNotScan      []string `yaml:"do_not_scan" json:"do_not_scan,omitempty"`         // added to preferences.do_not_scan
+}
+
+// NetworkIdentity describes the network this machine is on
+type NetworkIdentity struct {
+	SSID       string
+	GatewayMAC string
+	Address    string // local IP, with or without prefix length
+}
+
+// NetworkSettings is the combined effect of the overrides matching a network
+type NetworkSettings struct {
+	Names          []string
+	RateProfile    string
+	MaxRate        int
+	MaxConcurrency int
+	DoNotScan      []string
+}
+
+// Matches reports whether a network meets every criterion of the override.
+// An override without criteria never matches.
+func (o NetworkOverride) Matches(id NetworkIdentity) bool {
+	if o.SSID == "" && o.GatewayMAC == "" && o.CIDR == "" {
+		return false
+	}
+	if o.SSID != "" && o.SSID != id.SSID {
+		return false
+	}
+	if o.GatewayMAC != "" && normalizeMAC(o.GatewayMAC) != normalizeMAC(id.GatewayMAC) {
+		return false
+	}
+	if o.CIDR != "" {
+		_, network, err := net.ParseCIDR(o.CIDR)
+		address, _, _ := strings.Cut(id.Address, "/")
+		ip := net.ParseIP(address)
+		if err != nil || ip == nil || !network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// NetworkSettings combines the overrides matching a network. The lowest
+// caps win, the first matching rate profile is used and exclusions add up.
+func (c *Config) NetworkSettings(id NetworkIdentity) NetworkSettings {
+	var settings NetworkSettings
+	for _, override := range c.Networks {
+		if !override.Matches(id) {
+			continue
+		}
+		settings.Names = append(settings.Names, override.Name)
+		if settings.RateProfile == "" {
+			settings.RateProfile = override.RateProfile
+		}
+		settings.MaxRate = lowestLimit(settings.MaxRate, override.MaxRate)
+		settings.MaxConcurrency = lowestLimit(settings.MaxConcurrency, override.MaxConcurrency)
+		settings.DoNotScan = append(settings.DoNotScan, override.DoNotScan...)
+	}
+	return settings
+}
+
+// lowestLimit returns the stricter of two limits, where 0 means no limit
+func lowestLimit(a, b int) int {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// normalizeMAC writes a MAC address as upper-case, zero-padded,
+// colon-separated pairs so differently printed addresses compare equal
+func normalizeMAC(mac string) string {
+	parts := strings.FieldsFunc(mac, func(r rune) bool { return r == ':' || r == '-' })
+	for i, part := range parts {
+		if len(part) == 1 {
+			parts[i] = "0" + part
+		}
+	}
+	return strings.ToUpper(strings.Join(parts, ":"))
+}
+
+// validateNetworks checks the networks section
+func validateNetworks(config *Config) error {
+	for i, override := range config.Networks {
+		field := fmt.Sprintf("networks[%d]", i)
+		if override.Name == "" {
+			return fieldErrorf(field, "name is required")
+		}
+		if override.SSID == "" && override.GatewayMAC == "" && override.CIDR == "" {
+			return fieldErrorf(field, "set at least one of ssid, gateway_mac and cidr")
+		}
+		if override.GatewayMAC != "" {
+			if _, err := net.ParseMAC(normalizeMAC(override.GatewayMAC)); err != nil {
+				return fieldErrorf(field, "invalid gateway_mac %q", override.GatewayMAC)
+			}
+		}
+		if override.CIDR != "" {
+			if _, _, err := net.ParseCIDR(override.CIDR); err != nil {
+				return fieldErrorf(field, "invalid cidr %q", override.CIDR)
+			}
+		}
+		if override.RateProfile != "" {
+			if _, ok := config.RateProfiles[override.RateProfile]; !ok {
+				return fieldErrorf(field, "unknown rate_profile %q", override.RateProfile)
+			}
+		}
+		if override.MaxRate < 0 || override.MaxConcurrency < 0 {
+			return fieldErrorf(field, "max_rate and max_concurrency cannot be negative")
+		}
+		if err := validateRanges(field+".do_not_scan", override.DoNotScan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
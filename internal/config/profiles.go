@@ -0,0 +1,212 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigProfileEnv selects a config profile for one shell or CI job
+const ConfigProfileEnv = "NETCRATE_CONFIG_PROFILE"
+
+// NoConfigProfile is the profile name that turns the current profile off
+const NoConfigProfile = "none"
+
+// ConfigProfile is a named set of settings, such as work, home or lab, laid
+// over the config file while it is in use. Unset fields keep the values of
+// the config file.
+type ConfigProfile struct {
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	RateProfile string            `yaml:"rate_profile,omitempty" json:"rate_profile,omitempty"` // replaces current_rate_profile
+	Compliance  *ComplianceConfig `yaml:"compliance,omitempty" json:"compliance,omitempty"`     // replaces the compliance policy
+	ResultsDir  string            `yaml:"results_dir,omitempty" json:"results_dir,omitempty"`   // replaces preferences.results_dir
+	ReportDir   string            `yaml:"report_dir,omitempty" json:"report_dir,omitempty"`     // replaces reports.directory
+}
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// selectedConfigProfile is the profile chosen with --config-profile
+var selectedConfigProfile string
+
+// SelectConfigProfile makes every config loaded by the process use the named
+// profile instead of current_profile, as --config-profile does
+func SelectConfigProfile(name string) {
+	selectedConfigProfile = name
+}
+
+// complianceSetting lets a profile's compliance policy be laid over that of
+// the config file like any other setting
+var complianceSetting = Setting{
+	Key: "compliance",
+	get: func(c *Config) string {
+		data, _ := yaml.Marshal(c.Compliance)
+		return string(data)
+	},
+	set: func(c *Config, value string) error {
+		var compliance ComplianceConfig
+		if err := yaml.Unmarshal([]byte(value), &compliance); err != nil {
+			return err
+		}
+		c.Compliance = compliance
+		return nil
+	},
+}
+
+// ActiveConfigProfile returns the name of the config profile in effect, or ""
+func (cm *ConfigManager) ActiveConfigProfile() string {
+	return cm.profile
+}
+
+// applyConfigProfile lays the active profile over the config file. --config-profile
+// takes precedence over $NETCRATE_CONFIG_PROFILE, which takes precedence over
+// current_profile.
+func (cm *ConfigManager) applyConfigProfile() error {
+	name := selectedConfigProfile
+	if name == "" {
+		name = os.Getenv(ConfigProfileEnv)
+	}
+	if name == "" {
+		name = cm.config.CurrentProfile
+	}
+	if name == "" || name == NoConfigProfile {
+		return nil
+	}
+	profile, ok := cm.config.Profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown config profile %q (see netcrate config profile list)", name)
+	}
+	cm.profile = name
+
+	if profile.RateProfile != "" {
+		cm.overlay("current_rate_profile", profile.RateProfile, OriginProfile)
+	}
+	if profile.ResultsDir != "" {
+		cm.overlay("preferences.results_dir", profile.ResultsDir, OriginProfile)
+	}
+	if profile.ReportDir != "" {
+		cm.overlay("reports.directory", profile.ReportDir, OriginProfile)
+	}
+	if profile.Compliance != nil {
+		policy := *cm.config
+		policy.Compliance = *profile.Compliance
+		// Leaving a strict level takes the policy token of the config file,
+		// which the compliance checker verifies; until then the level stays
+		policy.Compliance.PolicyTokenHash = cm.config.Compliance.PolicyTokenHash
+		if cm.config.Compliance.EffectiveLevel() == PolicyStrict && policy.Compliance.EffectiveLevel() != PolicyStrict {
+			cm.heldLevel = policy.Compliance.EffectiveLevel()
+			policy.Compliance.Level = PolicyStrict
+		}
+		cm.overlaySetting(complianceSetting, complianceSetting.get(&policy), OriginProfile)
+	}
+	return nil
+}
+
+// HeldComplianceLevel returns the policy level the active config profile
+// asks for but does not get by itself, because leaving the strict level of
+// the config file takes the policy token; "" when there is none
+func (cm *ConfigManager) HeldComplianceLevel() string {
+	return cm.heldLevel
+}
+
+// ConfigProfileNames returns the names of the config profiles, sorted
+func (cm *ConfigManager) ConfigProfileNames() []string {
+	names := make([]string, 0, len(cm.config.Profiles))
+	for name := range cm.config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetConfigProfile returns a config profile
+func (cm *ConfigManager) GetConfigProfile(name string) (ConfigProfile, bool) {
+	profile, ok := cm.config.Profiles[name]
+	return profile, ok
+}
+
+// UseConfigProfile makes a profile current, or turns profiles off for NoConfigProfile
+func (cm *ConfigManager) UseConfigProfile(name string) error {
+	if name == NoConfigProfile {
+		cm.config.CurrentProfile = ""
+		return cm.Save()
+	}
+	if _, ok := cm.config.Profiles[name]; !ok {
+		return fmt.Errorf("unknown config profile %q", name)
+	}
+	cm.config.CurrentProfile = name
+	return cm.Save()
+}
+
+// AddConfigProfile creates or replaces a config profile. Replacing a profile keeps
+// its compliance policy, which is edited in the config file.
+func (cm *ConfigManager) AddConfigProfile(name string, profile ConfigProfile) error {
+	if !profileNamePattern.MatchString(name) || name == NoConfigProfile {
+		return fmt.Errorf("invalid profile name %q (use letters, digits, '_' and '-'; %q is reserved)", name, NoConfigProfile)
+	}
+	if existing, ok := cm.config.Profiles[name]; ok && profile.Compliance == nil {
+		profile.Compliance = existing.Compliance
+	}
+	if cm.config.Profiles == nil {
+		cm.config.Profiles = make(map[string]ConfigProfile)
+	}
+	previous, existed := cm.config.Profiles[name]
+	cm.config.Profiles[name] = profile
+	if err := validateConfigProfiles(cm.config); err != nil {
+		if existed {
+			cm.config.Profiles[name] = previous
+		} else {
+			delete(cm.config.Profiles, name)
+		}
+		return err
+	}
+	return cm.Save()
+}
+
+// RemoveConfigProfile deletes a config profile. The current profile cannot be
+// removed.
+func (cm *ConfigManager) RemoveConfigProfile(name string) error {
+	if _, ok := cm.config.Profiles[name]; !ok {
+		return fmt.Errorf("unknown config profile %q", name)
+	}
+	if cm.config.CurrentProfile == name {
+		return fmt.Errorf("profile %q is current; switch with 'netcrate config profile use' first", name)
+	}
+	delete(cm.config.Profiles, name)
+	return cm.Save()
+}
+
+// validateConfigProfiles checks the profiles section and current_profile
+func validateConfigProfiles(config *Config) error {
+	for name, profile := range config.Profiles {
+		field := "profiles." + name
+		if !profileNamePattern.MatchString(name) || name == NoConfigProfile {
+			return fieldErrorf(field, "invalid profile name (use letters, digits, '_' and '-'; %q is reserved)", NoConfigProfile)
+		}
+		if profile.RateProfile != "" {
+			if _, ok := config.RateProfiles[profile.RateProfile]; !ok {
+				return fieldErrorf(field+".rate_profile", "unknown rate profile %q", profile.RateProfile)
+			}
+		}
+		for setting, dir := range map[string]string{"results_dir": profile.ResultsDir, "report_dir": profile.ReportDir} {
+			if dir != "" && !filepath.IsAbs(dir) && !strings.HasPrefix(dir, "~/") {
+				return fieldErrorf(field+"."+setting, "expected an absolute path or one starting with ~/, got %q", dir)
+			}
+		}
+		if profile.Compliance != nil {
+			if err := validateCompliance(field+".compliance", *profile.Compliance); err != nil {
+				return err
+			}
+		}
+	}
+	if config.CurrentProfile != "" {
+		if _, ok := config.Profiles[config.CurrentProfile]; !ok {
+			return fieldErrorf("current_profile", "unknown profile %q", config.CurrentProfile)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ProjectFileName is the engagement file NetCrate reads from the working
+// directory. Unlike ~/.netcrate/config.yaml it is meant to be committed next
+// to the engagement's notes, so everyone runs with the same scope.
+const ProjectFileName = "netcrate.yaml"
+
+// ProjectConfig is an engagement's netcrate.yaml. Its settings take
+// precedence over the user's config file.
+type ProjectConfig struct {
+	Name      string       `yaml:"name" json:"name,omitempty"`
+	Scope     ProjectScope `yaml:"scope" json:"scope"`
+	Tags      []string     `yaml:"tags" json:"tags,omitempty"`             // saved with every run
+	OutputDir string       `yaml:"output_dir" json:"output_dir,omitempty"` // reports go here; relative to the file
+
+	path string
+}
+
+// ProjectScope limits which targets the engagement may probe
+type ProjectScope struct {
+	Allowed []string `yaml:"allowed" json:"allowed,omitempty"` // IPs or CIDRs; targets outside them are refused
+	Exclude []string `yaml:"exclude" json:"exclude,omitempty"` // IPs or CIDRs never probed, even inside the scope
+}
+
+// Path returns the location of the project file
+func (p *ProjectConfig) Path() string {
+	return p.path
+}
+
+// LoadProject reads netcrate.yaml from the working directory. It returns
+// nil without error when there is none.
+func LoadProject() (*ProjectConfig, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	path := filepath.Join(dir, ProjectFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read project file: %w", err)
+	}
+
+	project, err := ParseProject(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	project.path = path
+	if project.OutputDir != "" && !strings.HasPrefix(project.OutputDir, "~/") && !filepath.IsAbs(project.OutputDir) {
+		project.OutputDir = filepath.Join(dir, project.OutputDir)
+	}
+	return project, nil
+}
+
+// ParseProject decodes and validates the contents of a project file
+func ParseProject(data []byte) (*ProjectConfig, error) {
+	var project ProjectConfig
+	if err := yaml.UnmarshalStrict(data, &project); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %s", strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	if err := validateProject(&project); err != nil {
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			fieldErr.Line = lineOf(data, fieldErr.Field)
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+// validateProject checks a project file
+func validateProject(project *ProjectConfig) error {
+	if err := validateRanges("scope.allowed", project.Scope.Allowed); err != nil {
+		return err
+	}
+	if err := validateRanges("scope.exclude", project.Scope.Exclude); err != nil {
+		return err
+	}
+	for i, tag := range project.Tags {
+		if strings.TrimSpace(tag) == "" || strings.Contains(tag, ",") {
+			return fieldErrorf(fmt.Sprintf("tags[%d]", i), "invalid tag %q", tag)
+		}
+	}
+	return nil
+}
+
+// Project returns the project file in effect, or nil
+func (cm *ConfigManager) Project() *ProjectConfig {
+	return cm.project
+}
+
+// applyProject loads the project file and lays its settings over those of
+// the config file
+func (cm *ConfigManager) applyProject() error {
+	project, err := LoadProject()
+	if err != nil || project == nil {
+		return err
+	}
+	cm.project = project
+
+	if project.OutputDir != "" {
+		cm.overlay("reports.directory", project.OutputDir, OriginProject)
+	}
+	return nil
+}
+
+// overlay sets a setting without it being saved to the config file
+func (cm *ConfigManager) overlay(key, value, origin string) {
+	for _, setting := range Settings {
+		if setting.Key == key {
+			cm.overlaySetting(setting, value, origin)
+			return
+		}
+	}
+}
+
+// overlaySetting sets a setting that need not be in Settings without it
+// being saved to the config file
+func (cm *ConfigManager) overlaySetting(setting Setting, value, origin string) {
+	fileValue := setting.get(cm.config)
+	if err := setting.set(cm.config, value); err == nil {
+		cm.overrides = append(cm.overrides, override{setting: setting, value: setting.get(cm.config), fileValue: fileValue, origin: origin})
+	}
+}
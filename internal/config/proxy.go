@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/netcrate/netcrate/internal/transport"
+)
+
+// ProxyConfig routes application probes (packet templates, service
+// fingerprinting) through a proxy. Discovery and port scans go direct.
+type ProxyConfig struct {
+	URL     string   `yaml:"url" json:"url,omitempty"`           // http://, https:// or socks5://host:port; empty honours $HTTP(S)_PROXY for HTTP probes
+	NoProxy []string `yaml:"no_proxy" json:"no_proxy,omitempty"` // hosts, domain suffixes, IPs or CIDRs reached directly
+}
+
+// DNSConfig sets the DNS servers hostnames are resolved with
+type DNSConfig struct {
+	Resolvers []string `yaml:"resolvers" json:"resolvers,omitempty"` // 1.1.1.1, tls://host (DoT) or https://url (DoH); empty for the system resolver
+}
+
+// TransportSettings returns the proxy and DNS settings for transport.Configure
+func (c *Config) TransportSettings() transport.Settings {
+	return transport.Settings{
+		Proxy:     c.Proxy.URL,
+		NoProxy:   c.Proxy.NoProxy,
+		Resolvers: c.DNS.Resolvers,
+	}
+}
+
+// RedactProxyURL hides the password of a proxy URL, for display
+func RedactProxyURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
+
+// SetTransport sets a proxy or DNS setting
+func (cm *ConfigManager) SetTransport(key, value string) error {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+
+	switch key {
+	case "proxy_url":
+		if _, err := transport.ParseProxy(value); err != nil {
+			return err
+		}
+		cm.config.Proxy.URL = value
+	case "proxy_no_proxy":
+		cm.config.Proxy.NoProxy = list
+	case "dns_resolvers":
+		if _, err := transport.ParseResolvers(list); err != nil {
+			return err
+		}
+		cm.config.DNS.Resolvers = list
+	default:
+		return fmt.Errorf("unknown proxy or DNS setting: %s", key)
+	}
+
+	return cm.Save()
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RedactionProfile says what to mask when results are shared outside the
+// team. The built-in profiles are light, standard and strict; "custom" is
+// the profile stored in the config file.
+type RedactionProfile struct {
+	IPOctets  int  `yaml:"ip_octets" json:"ip_octets"` // trailing IPv4 octets to mask, 0-4; IPv6 interface IDs are masked when > 0
+	Hostnames bool `yaml:"hostnames" json:"hostnames"` // hostnames, mDNS and certificate names, Wi-Fi SSID
+	Banners   bool `yaml:"banners" json:"banners"`     // banners, HTTP titles/headers, SSH host keys
+	MACs      bool `yaml:"macs" json:"macs"`           // MAC addresses, keeping the vendor prefix
+}
+
+// builtinRedactionProfiles are available without any configuration
+var builtinRedactionProfiles = map[string]RedactionProfile{
+	"light":    {IPOctets: 1},
+	"standard": {IPOctets: 1, Hostnames: true, Banners: true, MACs: true},
+	"strict":   {IPOctets: 2, Hostnames: true, Banners: true, MACs: true},
+}
+
+// RedactionProfileNames lists the profiles --redact accepts
+func RedactionProfileNames() []string {
+	names := []string{"custom"}
+	for name := range builtinRedactionProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetRedactionProfile returns a built-in profile or the configured "custom" one
+func (cm *ConfigManager) GetRedactionProfile(name string) (RedactionProfile, error) {
+	if name == "custom" {
+		return cm.config.Redaction, nil
+	}
+	if profile, ok := builtinRedactionProfiles[name]; ok {
+		return profile, nil
+	}
+	return RedactionProfile{}, fmt.Errorf("unknown redaction profile: %s (available: %s)",
+		name, strings.Join(RedactionProfileNames(), ", "))
+}
+
+// SetRedaction sets a field of the custom redaction profile
+func (cm *ConfigManager) SetRedaction(key, value string) error {
+	profile := &cm.config.Redaction
+
+	if key == "redaction_ip_octets" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > 4 {
+			return fmt.Errorf("invalid octet count: %s (expected 0-4)", value)
+		}
+		profile.IPOctets = n
+		return cm.Save()
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %s (expected true or false)", key, value)
+	}
+	switch key {
+	case "redaction_hostnames":
+		profile.Hostnames = enabled
+	case "redaction_banners":
+		profile.Banners = enabled
+	case "redaction_macs":
+		profile.MACs = enabled
+	default:
+		return fmt.Errorf("unknown redaction setting: %s", key)
+	}
+
+	return cm.Save()
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/netcrate/netcrate/internal/reports"
+)
+
+// ReportConfig holds the defaults of generated reports; report flags and
+// template report steps override them
+type ReportConfig struct {
+	Theme     string `yaml:"theme" json:"theme,omitempty"`         // HTML theme, "default" when empty
+	Format    string `yaml:"format" json:"format,omitempty"`       // "html" (default) or "md"
+	Directory string `yaml:"directory" json:"directory,omitempty"` // where reports are written when no path is given
+	History   int    `yaml:"history" json:"history,omitempty"`     // earlier runs compared in host details; 0 uses the default
+}
+
+// EffectiveDirectory returns the report directory with ~ expanded, or ""
+// for the working directory
+func (r ReportConfig) EffectiveDirectory() string {
+	return expandHome(r.Directory)
+}
+
+// expandHome expands a leading ~/ to the home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, path[2:])
+		}
+	}
+	return path
+}
+
+// SetReport sets a report setting
+func (cm *ConfigManager) SetReport(key, value string) error {
+	report := &cm.config.Reports
+
+	switch key {
+	case "report_theme":
+		report.Theme = value
+	case "report_format":
+		report.Format = value
+	case "report_dir":
+		report.Directory = value
+	case "report_history":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid run count: %s", value)
+		}
+		report.History = n
+	default:
+		return fmt.Errorf("unknown report setting: %s", key)
+	}
+	if err := validateReportConfig(*report); err != nil {
+		return err
+	}
+
+	return cm.Save()
+}
+
+// validateReportConfig checks the report defaults against the themes and
+// formats the report package supports
+func validateReportConfig(report ReportConfig) error {
+	if report.Theme != "" {
+		if err := (reports.ReportOutput{Theme: report.Theme}).Validate(); err != nil {
+			return fieldErrorf("reports.theme", "%v", err)
+		}
+	}
+	if report.Format != "" {
+		if err := (reports.ReportOutput{Format: report.Format}).Validate(); err != nil {
+			return fieldErrorf("reports.format", "%v", err)
+		}
+	}
+	if report.History < 0 {
+		return fieldErrorf("reports.history", "cannot be negative")
+	}
+	return nil
+}
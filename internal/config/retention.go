@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetentionConfig limits how many saved runs are kept in ~/.netcrate/runs.
+// Zero values disable the corresponding limit.
+type RetentionConfig struct {
+	MaxRuns int    `yaml:"max_runs" json:"max_runs,omitempty"` // keep at most this many runs
+	MaxAge  string `yaml:"max_age" json:"max_age,omitempty"`   // e.g. "30d", "12h"
+	MaxDisk string `yaml:"max_disk" json:"max_disk,omitempty"` // e.g. "500MB", "2GB"
+}
+
+// SetRetention sets a retention setting
+func (cm *ConfigManager) SetRetention(key, value string) error {
+	switch key {
+	case "retention_max_runs":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid run count: %s", value)
+		}
+		cm.config.Retention.MaxRuns = n
+	case "retention_max_age":
+		if _, err := ParseAge(value); err != nil {
+			return err
+		}
+		cm.config.Retention.MaxAge = value
+	case "retention_max_disk":
+		if _, err := ParseByteSize(value); err != nil {
+			return err
+		}
+		cm.config.Retention.MaxDisk = value
+	default:
+		return fmt.Errorf("unknown retention setting: %s", key)
+	}
+
+	return cm.Save()
+}
+
+// ParseAge parses a duration that may also use d (days) and w (weeks), e.g. "30d".
+// An empty string means no limit.
+func ParseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if strings.HasSuffix(value, suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(value, suffix), 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age: %s", value)
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age: %s (use e.g. 30d, 2w or 12h)", value)
+	}
+	return d, nil
+}
+
+// ParseByteSize parses a size such as "500MB" or "2GB" (binary multiples).
+// An empty string means no limit.
+func ParseByteSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+
+	multipliers := []struct {
+		suffix string
+		factor int64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1},
+	}
+	for _, m := range multipliers {
+		if strings.HasSuffix(value, m.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, m.suffix)), 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid size: %s", value)
+			}
+			return int64(n * float64(m.factor)), nil
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size: %s (use e.g. 500MB or 2GB)", value)
+	}
+	return n, nil
+}
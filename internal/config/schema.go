@@ -0,0 +1,225 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SchemaVersion is the version of the config file layout this build writes.
+// Version 1.0 was the JSON file ~/.netcrate/config.json; version 2 is YAML.
+const SchemaVersion = "2"
+
+// configHeader starts every config file NetCrate writes
+const configHeader = "# NetCrate configuration. Edit with `netcrate config edit`, which validates\n" +
+	"# the file before saving it. Settings are described in docs/USER_GUIDE.md.\n"
+
+// errNoConfig reports that neither a config file nor a legacy one exists
+var errNoConfig = errors.New("config file does not exist")
+
+// migrations upgrade a config from the schema version they are keyed by to
+// the next one
+var migrations = map[string]func(*Config){
+	"1.0": migrateV1,
+}
+
+// migrateV1 upgrades a version 1.0 config: custom profiles lived in the
+// session section and profiles could lack their name
+func migrateV1(config *Config) {
+	if config.RateProfiles == nil {
+		config.RateProfiles = make(map[string]RateProfile)
+	}
+	for name, profile := range DefaultRateProfiles {
+		if _, ok := config.RateProfiles[name]; !ok {
+			config.RateProfiles[name] = profile
+		}
+	}
+	for name, profile := range config.Session.CustomProfiles {
+		if _, ok := config.RateProfiles[name]; !ok {
+			config.RateProfiles[name] = profile
+		}
+	}
+	for name, profile := range config.RateProfiles {
+		if profile.Name == "" {
+			profile.Name = name
+			config.RateProfiles[name] = profile
+		}
+	}
+	if config.CurrentRateProfile == "" {
+		config.CurrentRateProfile = "medium"
+	}
+	config.Version = "2"
+}
+
+// migrate upgrades a config to SchemaVersion, reporting whether anything
+// changed
+func migrate(config *Config) (bool, error) {
+	if config.Version == "" {
+		config.Version = "1.0"
+	}
+	migrated := false
+	for config.Version != SchemaVersion {
+		step, ok := migrations[config.Version]
+		if !ok {
+			return migrated, fieldErrorf("version", "unsupported schema version %q (this NetCrate reads up to %s)", config.Version, SchemaVersion)
+		}
+		step(config)
+		migrated = true
+	}
+	return migrated, nil
+}
+
+// FieldError is an invalid config setting, with the line of the config file
+// it is on when known
+type FieldError struct {
+	Field   string // dotted path, e.g. "preferences.do_not_scan[1]"
+	Line    int
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Field, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+func fieldErrorf(field, format string, args ...interface{}) error {
+	return &FieldError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// ParseConfig decodes the contents of a config file, migrates older schema
+// versions and validates the result. Errors carry the line they refer to.
+func ParseConfig(data []byte) (*Config, error) {
+	config, _, err := parseConfig(data)
+	return config, err
+}
+
+func parseConfig(data []byte) (*Config, bool, error) {
+	var config Config
+	// Strict decoding turns misspelled keys into errors instead of settings
+	// that are silently ignored
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, false, fmt.Errorf("invalid YAML: %s", strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	migrated, err := migrate(&config)
+	if err == nil {
+		err = ValidateConfig(&config)
+	}
+	if err != nil {
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			fieldErr.Line = lineOf(data, fieldErr.Field)
+		}
+		return nil, false, err
+	}
+	return &config, migrated, nil
+}
+
+// parseLegacyConfig decodes a version 1.0 config.json
+func parseLegacyConfig(data []byte) (*Config, error) {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if _, err := migrate(&config); err != nil {
+		return nil, err
+	}
+	if err := ValidateConfig(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// lineOf finds the line of a dotted setting path in a block-style YAML
+// document, falling back to the closest parent it finds. It returns 0 when
+// not even the first key is found.
+func lineOf(data []byte, field string) int {
+	lines := strings.Split(string(data), "\n")
+	found, indent := -1, -1
+
+	for _, segment := range strings.Split(field, ".") {
+		key, index := segment, -1
+		if open := strings.Index(segment, "["); open > 0 && strings.HasSuffix(segment, "]") {
+			key = segment[:open]
+			index, _ = strconv.Atoi(segment[open+1 : len(segment)-1])
+		}
+
+		match := -1
+		for n := found + 1; n < len(lines); n++ {
+			text := strings.TrimRight(lines[n], " \r")
+			trimmed := strings.TrimLeft(text, " ")
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			depth := len(text) - len(trimmed)
+			if depth <= indent {
+				break // left the parent's block
+			}
+			if colon := strings.Index(trimmed, ":"); colon > 0 && strings.Trim(trimmed[:colon], `"'`) == key {
+				match, indent = n, depth
+				break
+			}
+		}
+		if match < 0 {
+			break
+		}
+		found = match
+
+		if index >= 0 {
+			// yaml list items may sit at the same indentation as their key
+			item := 0
+			for n := found + 1; n < len(lines); n++ {
+				text := strings.TrimRight(lines[n], " \r")
+				trimmed := strings.TrimLeft(text, " ")
+				if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+					continue
+				}
+				depth := len(text) - len(trimmed)
+				if depth < indent || (depth == indent && !strings.HasPrefix(trimmed, "- ")) {
+					break
+				}
+				if strings.HasPrefix(trimmed, "- ") {
+					if item == index {
+						found = n
+						break
+					}
+					item++
+				}
+			}
+		}
+	}
+	return found + 1
+}
+
+// loadLegacy migrates a version 1.0 config.json next to the config file to
+// the current schema, keeping the old file as config.json.migrated
+func (cm *ConfigManager) loadLegacy() error {
+	legacyPath := strings.TrimSuffix(cm.configPath, ".yaml") + ".json"
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errNoConfig
+		}
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	config, err := parseLegacyConfig(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", legacyPath, err)
+	}
+	cm.config = config
+	if err := cm.Save(); err != nil {
+		return err
+	}
+	if err := os.Rename(legacyPath, legacyPath+".migrated"); err != nil {
+		return fmt.Errorf("failed to retire %s: %w", legacyPath, err)
+	}
+	fmt.Fprintf(os.Stderr, "Migrated %s to %s (schema version %s)\n", legacyPath, cm.configPath, SchemaVersion)
+	return nil
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SecretScheme prefixes parameter values that name a secret of the vault,
+// e.g. secret://snmp_community
+const SecretScheme = "secret://"
+
+// The vault is ~/.netcrate/secrets.enc, laid out as magic | nonce |
+// AES-256-GCM ciphertext of a JSON object of names to values. Its key is the
+// random secrets.key next to it, created with the vault.
+var secretsMagic = []byte("NCSEC1")
+
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// SecretStore is the encrypted vault of probe credentials
+type SecretStore struct {
+	path    string
+	keyPath string
+	values  map[string]string
+}
+
+// OpenSecrets opens the vault, which is empty until the first secret is set
+func OpenSecrets() (*SecretStore, error) {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(configPath)
+	store := &SecretStore{
+		path:    filepath.Join(dir, "secrets.enc"),
+		keyPath: filepath.Join(dir, "secrets.key"),
+		values:  make(map[string]string),
+	}
+
+	data, err := os.ReadFile(store.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+	key, err := os.ReadFile(store.keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets key: %w", err)
+	}
+	gcm, err := secretsGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, secretsMagic) || len(data) < len(secretsMagic)+gcm.NonceSize() {
+		return nil, fmt.Errorf("%s is not a NetCrate secrets file", store.path)
+	}
+	data = data[len(secretsMagic):]
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], secretsMagic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets: wrong key or corrupted data")
+	}
+	if err := json.Unmarshal(plain, &store.values); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets: %w", err)
+	}
+	return store, nil
+}
+
+// Names returns the names of the stored secrets, sorted
+func (s *SecretStore) Names() []string {
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns a secret's value
+func (s *SecretStore) Get(name string) (string, bool) {
+	value, ok := s.values[name]
+	return value, ok
+}
+
+// Set stores a secret, replacing any secret of the same name
+func (s *SecretStore) Set(name, value string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q (use letters, digits, '_', '.' and '-')", name)
+	}
+	if value == "" {
+		return fmt.Errorf("secret %s has an empty value", name)
+	}
+	s.values[name] = value
+	return s.save()
+}
+
+// Remove deletes a secret
+func (s *SecretStore) Remove(name string) error {
+	if _, ok := s.values[name]; !ok {
+		return fmt.Errorf("secret not found: %s", name)
+	}
+	delete(s.values, name)
+	return s.save()
+}
+
+// save encrypts the vault to disk, creating its key on first use
+func (s *SecretStore) save() error {
+	key, err := os.ReadFile(s.keyPath)
+	if os.IsNotExist(err) {
+		key = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return err
+		}
+		if err := os.WriteFile(s.keyPath, key, 0600); err != nil {
+			return fmt.Errorf("failed to write secrets key: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to read secrets key: %w", err)
+	}
+
+	plain, err := json.Marshal(s.values)
+	if err != nil {
+		return err
+	}
+	gcm, err := secretsGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	out := append(append([]byte{}, secretsMagic...), nonce...)
+	out = gcm.Seal(out, nonce, plain, secretsMagic)
+	if err := os.WriteFile(s.path, out, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets: %w", err)
+	}
+	return nil
+}
+
+func secretsGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secrets key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretRefPattern matches secret references inside parameter values
+var secretRefPattern = regexp.MustCompile(regexp.QuoteMeta(SecretScheme) + `([A-Za-z0-9_.-]+)`)
+
+// ResolveSecrets returns a copy of params with every secret://name in a
+// value replaced by the secret it names, and the secrets used keyed by their
+// reference. The vault is only opened when a parameter refers to it.
+func ResolveSecrets(params map[string]interface{}) (map[string]interface{}, map[string]string, error) {
+	var store *SecretStore
+	resolved := make(map[string]interface{}, len(params))
+	used := make(map[string]string)
+
+	for key, value := range params {
+		resolved[key] = value
+		text, ok := value.(string)
+		if !ok || !strings.Contains(text, SecretScheme) {
+			continue
+		}
+		if store == nil {
+			var err error
+			if store, err = OpenSecrets(); err != nil {
+				return nil, nil, err
+			}
+		}
+		var missing string
+		resolved[key] = secretRefPattern.ReplaceAllStringFunc(text, func(ref string) string {
+			name := strings.TrimPrefix(ref, SecretScheme)
+			secret, ok := store.Get(name)
+			if !ok {
+				if missing == "" {
+					missing = name
+				}
+				return ref
+			}
+			used[ref] = secret
+			return secret
+		})
+		if missing != "" {
+			return nil, nil, fmt.Errorf("parameter %s: secret %q is not set (see netcrate config secret set)", key, missing)
+		}
+	}
+	return resolved, used, nil
+}
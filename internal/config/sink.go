@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result sink defaults
+const (
+	DefaultSinkBatchSize     = 50
+	DefaultSinkRetries       = 3
+	DefaultSinkFlushInterval = 5 * time.Second
+)
+
+// SinkConfig configures the HTTP result sink that receives results as they
+// happen. The sink is disabled while URL is empty.
+type SinkConfig struct {
+	URL           string            `yaml:"url" json:"url,omitempty"`
+	Headers       map[string]string `yaml:"headers" json:"headers,omitempty"`               // e.g. Authorization
+	BatchSize     int               `yaml:"batch_size" json:"batch_size,omitempty"`         // results per request
+	FlushInterval string            `yaml:"flush_interval" json:"flush_interval,omitempty"` // send partial batches this often, e.g. "5s"
+	Retries       int               `yaml:"retries" json:"retries,omitempty"`               // retries per request, with backoff
+}
+
+// EffectiveBatchSize returns the batch size, applying the default
+func (s SinkConfig) EffectiveBatchSize() int {
+	if s.BatchSize > 0 {
+		return s.BatchSize
+	}
+	return DefaultSinkBatchSize
+}
+
+// EffectiveRetries returns the retry count, applying the default
+func (s SinkConfig) EffectiveRetries() int {
+	if s.Retries > 0 {
+		return s.Retries
+	}
+	return DefaultSinkRetries
+}
+
+// EffectiveFlushInterval returns the flush interval, applying the default
+func (s SinkConfig) EffectiveFlushInterval() time.Duration {
+	if d, err := time.ParseDuration(s.FlushInterval); err == nil && d > 0 {
+		return d
+	}
+	return DefaultSinkFlushInterval
+}
+
+// SetSink sets a result sink setting. sink_header takes "Name: value";
+// a header with an empty value is removed.
+func (cm *ConfigManager) SetSink(key, value string) error {
+	sink := &cm.config.Sink
+
+	switch key {
+	case "sink_url":
+		if value != "" {
+			parsed, err := url.Parse(value)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("invalid sink URL: %s", value)
+			}
+		}
+		sink.URL = value
+	case "sink_header":
+		name, headerValue, found := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return fmt.Errorf("invalid header %q (use \"Name: value\")", value)
+		}
+		headerValue = strings.TrimSpace(headerValue)
+		if headerValue == "" {
+			delete(sink.Headers, name)
+		} else {
+			if sink.Headers == nil {
+				sink.Headers = make(map[string]string)
+			}
+			sink.Headers[name] = headerValue
+		}
+	case "sink_batch_size":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid batch size: %s", value)
+		}
+		sink.BatchSize = n
+	case "sink_flush_interval":
+		if value != "" {
+			if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+				return fmt.Errorf("invalid flush interval: %s", value)
+			}
+		}
+		sink.FlushInterval = value
+	case "sink_retries":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid retry count: %s", value)
+		}
+		sink.Retries = n
+	default:
+		return fmt.Errorf("unknown sink setting: %s", key)
+	}
+
+	return cm.Save()
+}
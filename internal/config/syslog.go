@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"net"
+)
+
+// SyslogConfig configures the syslog sink that forwards findings to a
+// collector. The sink is disabled while Address is empty.
+type SyslogConfig struct {
+	Address  string `yaml:"address" json:"address,omitempty"`   // collector host:port
+	Protocol string `yaml:"protocol" json:"protocol,omitempty"` // "udp" (default) or "tcp"
+	Format   string `yaml:"format" json:"format,omitempty"`     // "rfc5424" (default) or "cef"
+}
+
+// EffectiveProtocol returns the transport, applying the default
+func (s SyslogConfig) EffectiveProtocol() string {
+	if s.Protocol != "" {
+		return s.Protocol
+	}
+	return "udp"
+}
+
+// EffectiveFormat returns the message format, applying the default
+func (s SyslogConfig) EffectiveFormat() string {
+	if s.Format != "" {
+		return s.Format
+	}
+	return "rfc5424"
+}
+
+// SetSyslog sets a syslog sink setting
+func (cm *ConfigManager) SetSyslog(key, value string) error {
+	syslog := &cm.config.Syslog
+
+	switch key {
+	case "syslog_address":
+		if value != "" {
+			if _, _, err := net.SplitHostPort(value); err != nil {
+				return fmt.Errorf("invalid collector address %q (use host:port)", value)
+			}
+		}
+		syslog.Address = value
+	case "syslog_protocol":
+		if value != "udp" && value != "tcp" {
+			return fmt.Errorf("invalid protocol: %s (expected udp or tcp)", value)
+		}
+		syslog.Protocol = value
+	case "syslog_format":
+		if value != "rfc5424" && value != "cef" {
+			return fmt.Errorf("invalid format: %s (expected rfc5424 or cef)", value)
+		}
+		syslog.Format = value
+	default:
+		return fmt.Errorf("unknown syslog setting: %s", key)
+	}
+
+	return cm.Save()
+}
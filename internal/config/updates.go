@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// UpdateConfig controls "netcrate self-update"
+type UpdateConfig struct {
+	Disabled   bool   `yaml:"disabled" json:"disabled,omitempty"`     // never contact the release server, for air-gapped hosts
+	Repository string `yaml:"repository" json:"repository,omitempty"` // GitHub owner/name releases come from; empty for the official one
+}
+
+// repositoryPattern matches a GitHub owner/name
+var repositoryPattern = regexp.MustCompile(`^[A-Za-z0-9-]+/[A-Za-z0-9._-]+$`)
+
+// validateUpdates checks the release repository
+func validateUpdates(updates UpdateConfig) error {
+	if updates.Repository != "" && !repositoryPattern.MatchString(updates.Repository) {
+		return fieldErrorf("updates.repository", "expected a GitHub owner/name, got %q", updates.Repository)
+	}
+	return nil
+}
+
+// SetUpdates sets a self-update setting
+func (cm *ConfigManager) SetUpdates(key, value string) error {
+	updates := cm.config.Updates
+
+	switch key {
+	case "updates_disabled":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean value for %s: %s", key, value)
+		}
+		updates.Disabled = b
+	case "updates_repository":
+		updates.Repository = value
+	default:
+		return fmt.Errorf("unknown update setting: %s", key)
+	}
+	if err := validateUpdates(updates); err != nil {
+		return err
+	}
+
+	cm.config.Updates = updates
+	return cm.Save()
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/abort"
+	"github.com/netcrate/netcrate/internal/audit"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// NewAbortCommand creates the kill switch command
+func NewAbortCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "abort",
+		Short: "Stop every running scan and refuse new ones (kill switch)",
+		Long: `Abort engages the kill switch: every running netcrate command that sends
+traffic (quick, ops discover, ops scan ports, ops packet send, templates run)
+stops sending, saves what it has and exits with status 130, and new runs are
+refused until the switch is cleared with --clear.
+
+The switch is the file ~/.netcrate/run/abort, which running commands check
+ten times a second; creating it by hand, for example from a monitoring
+system, has the same effect. Running commands register themselves in the
+same directory. Engaging and clearing the switch are recorded in the audit log.
+
+Examples:
+  netcrate abort --reason "scan alarms on the production firewall"
+  netcrate abort --status
+  netcrate abort --clear`,
+		Args: cobra.NoArgs,
+		RunE: runAbort,
+	}
+
+	cmd.Flags().String("reason", "", "Why the runs are stopped, shown to their operators and in the audit log")
+	cmd.Flags().Bool("clear", false, "Release the kill switch so that new runs may start")
+	cmd.Flags().Bool("status", false, "Show whether the switch is engaged and which commands are running")
+	cmd.Flags().Duration("wait", 3*time.Second, "How long to wait for running commands to stop")
+	cmd.Flags().Bool("json", false, "Output the status in JSON format")
+
+	return cmd
+}
+
+func runAbort(cmd *cobra.Command, args []string) error {
+	reason, _ := cmd.Flags().GetString("reason")
+	clearSwitch, _ := cmd.Flags().GetBool("clear")
+	status, _ := cmd.Flags().GetBool("status")
+	wait, _ := cmd.Flags().GetDuration("wait")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	switch {
+	case status:
+		return printAbortStatus(asJSON)
+	case clearSwitch:
+		if err := abort.Clear(); err != nil {
+			return err
+		}
+		recordAbort("netcrate abort --clear", "")
+		style.Printf("✅ Kill switch cleared; new runs may start\n")
+		return nil
+	}
+
+	running, err := abort.Running()
+	if err != nil {
+		return fmt.Errorf("failed to list running commands: %w", err)
+	}
+	s, err := abort.Engage(reason)
+	if err != nil {
+		return err
+	}
+	recordAbort("netcrate abort", reason)
+	style.Printf("🛑 Kill switch %s\n", s)
+
+	deadline := time.Now().Add(wait)
+	remaining := running
+	for len(remaining) > 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		if remaining, err = abort.Running(); err != nil {
+			return fmt.Errorf("failed to list running commands: %w", err)
+		}
+	}
+	fmt.Printf("Stopped %d running command(s)\n", len(running)-len(remaining))
+	for _, process := range remaining {
+		style.Printf("⚠️  Still running: pid %d, %s (%s)\n", process.PID, process.Command, process.User)
+	}
+	fmt.Printf("New runs are refused until 'netcrate abort --clear'\n")
+	if len(remaining) > 0 {
+		return fmt.Errorf("%d command(s) did not stop within %s", len(remaining), wait)
+	}
+	return nil
+}
+
+// printAbortStatus shows the kill switch and the commands watching it
+func printAbortStatus(asJSON bool) error {
+	s, err := abort.Engaged()
+	if err != nil {
+		return err
+	}
+	running, err := abort.Running()
+	if err != nil {
+		return fmt.Errorf("failed to list running commands: %w", err)
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Switch  *abort.Switch   `json:"switch,omitempty"`
+			Running []abort.Process `json:"running"`
+		}{s, running})
+	}
+
+	if s != nil {
+		style.Printf("🛑 Kill switch %s\n", s)
+	} else {
+		fmt.Printf("Kill switch: not engaged\n")
+	}
+	if len(running) == 0 {
+		fmt.Printf("No running commands\n")
+		return nil
+	}
+	fmt.Printf("\n%-8s %-19s %-12s %s\n", "PID", "STARTED", "USER", "COMMAND")
+	for _, process := range running {
+		fmt.Printf("%-8d %-19s %-12s %s\n", process.PID, process.Started.Local().Format("2006-01-02 15:04:05"), process.User, process.Command)
+	}
+	return nil
+}
+
+// recordAbort records a use of the kill switch in the audit log
+func recordAbort(command, reason string) {
+	if err := audit.Record(audit.Entry{Command: command, Reason: reason}); err != nil {
+		style.Fprintf(os.Stderr, "⚠️  audit log not written: %v\n", err)
+	}
+}
@@ -0,0 +1,286 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// finishedScan is a scan the action menu offers follow-ups for
+type finishedScan struct {
+	RunID         string
+	Scan          *ops.ScanSummary // nil when no ports were scanned
+	Fingerprinted bool             // the open ports were fingerprinted during the run
+}
+
+// menuAction is one follow-up of the action menu
+type menuAction struct {
+	Label string
+	Run   func(in *bufio.Reader)
+}
+
+// trafficFlags are passed on to follow-ups that send traffic when they were
+// given to the scan, so they run under the same policy and authorization
+var trafficFlags = []string{"dangerous", "policy", "policy-token", "outside-window", "authorized-by", "ticket", "profile", "proxy", "dns"}
+
+// addActionMenuFlag adds --no-menu to a command that offers the action menu
+func addActionMenuFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-menu", false, "Do not offer follow-up actions after the scan (only offered in a terminal)")
+}
+
+// offerActionMenu lists follow-ups of a finished scan (export, report,
+// fingerprinting, re-scanning filtered ports, a diff against the previous
+// run) and runs the chosen ones until Enter is pressed. It is only offered
+// when both stdin and stdout are terminals.
+func offerActionMenu(cmd *cobra.Command, scan finishedScan) {
+	if noMenu, _ := cmd.Flags().GetBool("no-menu"); noMenu {
+		return
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return
+	}
+	actions := followUpActions(cmd, scan)
+	if len(actions) == 0 {
+		return
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	for {
+		style.Printf("\n👉 Next steps for %s:\n", scan.RunID)
+		for i, action := range actions {
+			fmt.Printf("  %d) %s\n", i+1, action.Label)
+		}
+		fmt.Printf("Choose 1-%d, or Enter to finish: ", len(actions))
+
+		line, err := in.ReadString('\n')
+		choice := strings.TrimSpace(line)
+		if err != nil || choice == "" || choice == "q" {
+			fmt.Println()
+			return
+		}
+		n, err := strconv.Atoi(choice)
+		if err != nil || n < 1 || n > len(actions) {
+			style.Printf("❌ Unknown choice %q\n", choice)
+			continue
+		}
+		fmt.Println()
+		actions[n-1].Run(in)
+	}
+}
+
+// followUpActions returns the follow-ups that apply to a scan: saved runs
+// can be exported, reported and diffed, open and filtered ports probed again
+func followUpActions(cmd *cobra.Command, scan finishedScan) []menuAction {
+	var actions []menuAction
+	run, err := output.GetRunByID(scan.RunID)
+	saved := err == nil
+
+	if saved {
+		actions = append(actions,
+			menuAction{Label: "Export results (csv, json, nmap-xml)", Run: func(in *bufio.Reader) {
+				format := ask(in, "Format (csv, json, nmap-xml)", "csv")
+				extension := map[string]string{"nmap-xml": "xml"}[format]
+				file := ask(in, "File", scan.RunID+"."+valueOrDefault(extension, format))
+				runFollowUp(cmd, nil, "output", "export", "--run", scan.RunID, "--format", format, "-o", file)
+			}},
+			menuAction{Label: "Generate an HTML report", Run: func(in *bufio.Reader) {
+				runFollowUp(cmd, nil, "output", "report", scan.RunID)
+			}},
+		)
+	}
+
+	if scan.Scan != nil {
+		if open := portsWithStatus(scan.Scan, "open"); len(open.hosts) > 0 && !scan.Fingerprinted {
+			actions = append(actions, menuAction{
+				Label: fmt.Sprintf("Fingerprint the open ports (%d on %d hosts)", open.count, len(open.hosts)),
+				Run: func(in *bufio.Reader) {
+					args := append([]string{"scan"}, open.hosts...)
+					args = append(args, "--ports", formatPortList(open.ports, len(open.ports)), "--no-menu")
+					runFollowUp(cmd, trafficFlags, args...)
+				},
+			})
+		}
+		if filtered := portsWithStatus(scan.Scan, "filtered"); len(filtered.hosts) > 0 {
+			actions = append(actions, menuAction{
+				Label: fmt.Sprintf("Re-scan the filtered ports (%d on %d hosts) with longer timeouts", filtered.count, len(filtered.hosts)),
+				Run: func(in *bufio.Reader) {
+					runFollowUp(cmd, trafficFlags, "ops", "scan", "ports",
+						"--targets", strings.Join(filtered.hosts, ","),
+						"--ports", formatPortList(filtered.ports, len(filtered.ports)),
+						"--timeout", "3s", "--retries", "3", "--no-menu")
+				},
+			})
+		}
+	}
+
+	if saved {
+		if previous := previousRun(run); previous != nil {
+			actions = append(actions, menuAction{
+				Label: fmt.Sprintf("Diff against the previous run of these targets (%s)", previous.RunID),
+				Run:   func(in *bufio.Reader) { printRunDiff(previous, run) },
+			})
+		}
+	}
+	return actions
+}
+
+// portSelection is the hosts and the union of ports with one status
+type portSelection struct {
+	hosts []string
+	ports []int
+	count int // host and port pairs
+}
+
+func portsWithStatus(scan *ops.ScanSummary, status string) portSelection {
+	var selection portSelection
+	hosts := make(map[string]bool)
+	ports := make(map[int]bool)
+	for _, r := range scan.Results {
+		if r.Status != status {
+			continue
+		}
+		selection.count++
+		if !hosts[r.Host] {
+			hosts[r.Host] = true
+			selection.hosts = append(selection.hosts, r.Host)
+		}
+		if !ports[r.Port] {
+			ports[r.Port] = true
+			selection.ports = append(selection.ports, r.Port)
+		}
+	}
+	return selection
+}
+
+// ask prompts for a value, returning fallback when the answer is empty
+func ask(in *bufio.Reader, prompt, fallback string) string {
+	fmt.Printf("%s [%s]: ", prompt, fallback)
+	line, _ := in.ReadString('\n')
+	return valueOrDefault(strings.TrimSpace(line), fallback)
+}
+
+// runFollowUp runs netcrate with args, adding the flags of passOn that were
+// given to cmd, and shows the command line so it can be repeated later.
+// --config-profile is always passed on.
+func runFollowUp(cmd *cobra.Command, passOn []string, args ...string) {
+	passOn = append([]string{"config-profile"}, passOn...)
+	seen := make(map[string]bool)
+	for _, name := range passOn {
+		flag := cmd.Flags().Lookup(name)
+		if seen[name] || flag == nil || !flag.Changed {
+			continue
+		}
+		seen[name] = true
+		value := flag.Value.String()
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			value = strings.Join(slice.GetSlice(), ",")
+		}
+		args = append(args, "--"+name+"="+value)
+	}
+
+	fmt.Printf("$ netcrate %s\n", strings.Join(args, " "))
+	executable, err := os.Executable()
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ Cannot find the netcrate binary: %v\n", err)
+		return
+	}
+	followUp := exec.Command(executable, args...)
+	followUp.Stdin, followUp.Stdout, followUp.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := followUp.Run(); err != nil {
+		style.Fprintf(os.Stderr, "⚠️  Follow-up ended with %v\n", err)
+	}
+}
+
+// previousRun returns the newest run before run of the same type and targets
+func previousRun(run *output.RunInfo) *output.RunInfo {
+	runs, err := output.ListRuns()
+	if err != nil {
+		return nil
+	}
+	output.SortRuns(runs, "time")
+	for i := range runs {
+		candidate := &runs[i]
+		if candidate.RunID != run.RunID && candidate.Type == run.Type &&
+			candidate.StartTime.Before(run.StartTime) && sameTargets(candidate.Targets, run.Targets) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func sameTargets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string(nil), a...), append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// printRunDiff prints the ports that opened and closed between two runs
+func printRunDiff(base, current *output.RunInfo) {
+	openPorts := func(run *output.RunInfo) (map[string]string, error) {
+		record, err := output.LoadRecord(run)
+		if err != nil {
+			return nil, err
+		}
+		table, err := output.PortTable(record)
+		if err != nil {
+			return nil, err
+		}
+		open := make(map[string]string)
+		for _, row := range table.Filter(output.RowFilter{Status: "open"}).Rows {
+			open[row["host"]+":"+row["port"]] = valueOrDefault(row["service"], "unknown")
+		}
+		return open, nil
+	}
+	before, err := openPorts(base)
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ Cannot read %s: %v\n", base.RunID, err)
+		return
+	}
+	after, err := openPorts(current)
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ Cannot read %s: %v\n", current.RunID, err)
+		return
+	}
+
+	style.Printf("🔀 Changes since %s (%s):\n", base.RunID, base.StartTime.Format("2006-01-02 15:04"))
+	printPortChanges("+", "opened", after, before)
+	printPortChanges("-", "closed", before, after)
+}
+
+// printPortChanges lists the ports of from that are not in to
+func printPortChanges(mark, verb string, from, to map[string]string) {
+	var changed []string
+	for port := range from {
+		if _, ok := to[port]; !ok {
+			changed = append(changed, port)
+		}
+	}
+	sort.Strings(changed)
+	if len(changed) == 0 {
+		fmt.Printf("  No ports %s\n", verb)
+		return
+	}
+	for _, port := range changed {
+		fmt.Printf("  %s %s (%s) %s\n", mark, port, from[port], verb)
+	}
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"os"
+
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// ExpandAlias replaces a command alias given as the first argument with the
+// command line it stands for, keeping the arguments after it:
+//
+//	netcrate web-sweep --targets 10.0.0.5
+//	netcrate ops scan ports --ports web --fingerprint --targets 10.0.0.5
+//
+// Built-in commands always win over an alias of the same name, and aliases
+// do not expand inside other aliases. The arguments are returned unchanged
+// when the config cannot be loaded; the command run reports the error.
+func ExpandAlias(root *cobra.Command, args []string) []string {
+	if len(args) == 0 || isBuiltinCommand(root, args[0]) {
+		return args
+	}
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return args
+	}
+	line, ok := cm.GetAlias(args[0])
+	if !ok {
+		return args
+	}
+	expansion, err := config.SplitCommandLine(line)
+	if err != nil {
+		// The config is validated on load, so this only guards against
+		// a file edited while it was read
+		style.Fprintf(os.Stderr, "⚠️  alias %s: %v\n", args[0], err)
+		return args
+	}
+	return append(expansion, args[1:]...)
+}
+
+// isBuiltinCommand reports whether name is a command, or an alias of one, of
+// the root command
+func isBuiltinCommand(root *cobra.Command, name string) bool {
+	if name == "help" {
+		return true
+	}
+	for _, cmd := range root.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,41 +1,361 @@
 package engine
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/netcrate/netcrate/internal/abort"
+	"github.com/netcrate/netcrate/internal/audit"
 	"github.com/netcrate/netcrate/internal/compliance"
 	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/i18n"
+	"github.com/netcrate/netcrate/internal/logging"
 	"github.com/netcrate/netcrate/internal/netenv"
 	"github.com/netcrate/netcrate/internal/ops"
 	"github.com/netcrate/netcrate/internal/output"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/progress"
 	"github.com/netcrate/netcrate/internal/quick"
+	"github.com/netcrate/netcrate/internal/reports"
+	"github.com/netcrate/netcrate/internal/risk"
+	"github.com/netcrate/netcrate/internal/style"
 	"github.com/netcrate/netcrate/internal/templates"
+	"github.com/netcrate/netcrate/internal/transport"
 	"github.com/spf13/cobra"
 )
 
-// applyRateProfile applies the current rate profile to operation options if not explicitly set
-func applyRateProfile(rate *int, concurrency *int, timeout *time.Duration) {
+// rateOptions are the pacing options of a command that a rate profile fills
+// in. Options a command does not have are left nil.
+type rateOptions struct {
+	Rate        *int           // --rate, packets per second
+	Concurrency *int           // --concurrency
+	Timeout     *time.Duration // --timeout
+	Retries     *int           // --retries
+	Interval    *time.Duration // --interval, derived from the profile's rate
+}
+
+// applyRateProfile fills the options whose flags were not given on the command line
+// from a rate profile: the one named by --profile, else the one of a matching network
+// override, else the current one. Network caps apply even to explicit values. It
+// returns the profile applied, which is empty when the config cannot be loaded.
+func applyRateProfile(cmd *cobra.Command, network config.NetworkSettings, opts rateOptions) (config.RateProfile, error) {
+	name, _ := cmd.Flags().GetString("profile")
+	defer func() {
+		if network.MaxRate > 0 {
+			if opts.Rate != nil && *opts.Rate > network.MaxRate {
+				*opts.Rate = network.MaxRate
+			}
+			if minInterval := time.Second / time.Duration(network.MaxRate); opts.Interval != nil && *opts.Interval < minInterval {
+				*opts.Interval = minInterval
+			}
+		}
+		if network.MaxConcurrency > 0 && opts.Concurrency != nil && *opts.Concurrency > network.MaxConcurrency {
+			*opts.Concurrency = network.MaxConcurrency
+		}
+	}()
+
 	cm, err := config.NewConfigManager()
 	if err != nil {
-		// If config fails, use defaults - don't block execution
-		return
+		if name != "" {
+			return config.RateProfile{}, err
+		}
+		// If config fails, keep the flag defaults - don't block execution
+		return config.RateProfile{}, nil
+	}
+
+	if name == "" {
+		name = network.RateProfile
 	}
-	
 	profile := cm.GetCurrentRateProfile()
-	
-	// Only apply if values are at defaults (0 or very low values)
-	if *rate == 0 || *rate == 100 { // 100 is common default
-		*rate = profile.Rate
+	if name != "" {
+		if profile, err = cm.GetRateProfile(name); err != nil {
+			return config.RateProfile{}, err
+		}
+	}
+
+	unset := func(flag string) bool { return !cmd.Flags().Changed(flag) }
+	if opts.Rate != nil && unset("rate") {
+		*opts.Rate = profile.Rate
+	}
+	if opts.Concurrency != nil && unset("concurrency") {
+		*opts.Concurrency = profile.Concurrency
+	}
+	if opts.Timeout != nil && unset("timeout") && profile.Timeout > 0 {
+		*opts.Timeout = profile.Timeout
+	}
+	if opts.Retries != nil && unset("retries") {
+		*opts.Retries = profile.Retries
+	}
+	if opts.Interval != nil && unset("interval") && profile.Rate > 0 {
+		*opts.Interval = time.Second / time.Duration(profile.Rate)
+	}
+	return profile, nil
+}
+
+// applyComplianceCeilings lowers the options above the ceilings of the
+// compliance policy, explicit values included, and reports what it lowered
+func applyComplianceCeilings(opts rateOptions, ports *[]int) []compliance.Clamp {
+	ceilings := compliance.LoadCeilings()
+	clamps := ceilings.Apply(opts.Rate, opts.Concurrency, ports)
+	if ceilings.Rate > 0 && opts.Interval != nil {
+		if minInterval := time.Second / time.Duration(ceilings.Rate); *opts.Interval < minInterval {
+			requested := 0
+			if *opts.Interval > 0 {
+				requested = int(time.Second / *opts.Interval)
+			}
+			clamps = append(clamps, compliance.Clamp{Option: "rate", Requested: requested, Ceiling: ceilings.Rate})
+			*opts.Interval = minInterval
+		}
+	}
+	for _, clamp := range clamps {
+		style.Fprintf(os.Stderr, "⚖️  Compliance ceiling: %s\n", clamp)
+	}
+	return clamps
+}
+
+// rateProfileName returns the rate profile a command without --profile runs
+// with: that of a matching network override, else the current one
+func rateProfileName(network config.NetworkSettings) string {
+	if network.RateProfile != "" {
+		return network.RateProfile
+	}
+	if cm, err := config.NewConfigManager(); err == nil {
+		return cm.GetConfig().CurrentRateProfile
+	}
+	return ""
+}
+
+// currentNetworkSettings returns the combined network overrides of the config that
+// match the network this machine is on through iface (the default route when empty)
+func currentNetworkSettings(iface string) config.NetworkSettings {
+	cm, err := config.NewConfigManager()
+	if err != nil || len(cm.GetConfig().Networks) == 0 {
+		return config.NetworkSettings{}
+	}
+
+	snapshot := netenv.TakeSnapshot(iface, false)
+	id := config.NetworkIdentity{SSID: snapshot.SSID, Address: snapshot.Address}
+	if snapshot.Gateway != "" {
+		id.GatewayMAC = ops.LookupMACAddresses()[snapshot.Gateway]
+	}
+	settings := cm.GetConfig().NetworkSettings(id)
+	if len(settings.Names) > 0 {
+		fmt.Fprint(os.Stderr, i18n.T("engine.config.network_overrides", strings.Join(settings.Names, ", ")))
+	}
+	return settings
+}
+
+// currentProject returns the netcrate.yaml of the working directory, or an
+// empty project when there is none. An invalid project file stops the
+// command, since running without its scope could probe the wrong hosts.
+func currentProject() config.ProjectConfig {
+	project, err := config.LoadProject()
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.config.project_invalid", err))
+		os.Exit(exitcode.Usage)
+	}
+	if project == nil {
+		return config.ProjectConfig{}
+	}
+	fmt.Fprint(os.Stderr, i18n.T("engine.config.project", project.Path()))
+	return *project
+}
+
+// applyCommandDefaults sets the flags a command was not given from the
+// defaults section of the config, keyed by the command path without the
+// program name ("quick", "ops scan ports", "output report")
+func applyCommandDefaults(cmd *cobra.Command, args []string) {
+	configureProgress(cmd)
+	guardTraffic(cmd)
+	selectConfigProfile(cmd)
+	applyRunSettingsEnv(cmd)
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		// A broken config file must not block operations
+		fmt.Fprint(os.Stderr, i18n.T("engine.config.load_warning", err))
+		configureLogging(cmd, config.Config{})
+		configureTransport(cmd, transport.Settings{})
+		return
+	}
+
+	path := commandPath(cmd)
+	for name, value := range cm.GetConfig().Defaults[path] {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.config.bad_default", path, name, fmt.Errorf("unknown flag --%s", name)))
+			os.Exit(exitcode.Usage)
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.config.bad_default", path, name, err))
+			os.Exit(exitcode.Usage)
+		}
+	}
+
+	// Output preferences apply to every command with the matching flag
+	prefs := cm.GetConfig().Preferences
+	if flag := cmd.Flags().Lookup("json"); flag != nil && !flag.Changed && prefs.DefaultOutputFormat == "json" {
+		cmd.Flags().Set("json", "true")
+	}
+	if flag := cmd.Flags().Lookup("save"); flag != nil && !flag.Changed && !prefs.SaveRuns() {
+		cmd.Flags().Set("save", "false")
+	}
+
+	configureLogging(cmd, *cm.GetConfig())
+	configureTransport(cmd, cm.GetConfig().TransportSettings())
+}
+
+// applyRunSettingsEnv sets the run flags a command was not given from their
+// NETCRATE_* variables. The flags then count as given, so neither the config
+// defaults nor the rate profile replace them.
+func applyRunSettingsEnv(cmd *cobra.Command) {
+	for _, setting := range config.RunSettings {
+		flag := cmd.Flags().Lookup(setting.Flag)
+		value := os.Getenv(setting.Env)
+		if flag == nil || flag.Changed || value == "" {
+			continue
+		}
+		if err := cmd.Flags().Set(setting.Flag, value); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: $%s: %v\n", setting.Env, err)
+			os.Exit(exitcode.Usage)
+		}
+	}
+}
+
+// commandPath returns the path of a command without the program name, such
+// as "ops scan ports"
+func commandPath(cmd *cobra.Command) string {
+	path := cmd.CommandPath()
+	if i := strings.Index(path, " "); i >= 0 {
+		path = path[i+1:]
+	}
+	return path
+}
+
+// AddGlobalFlags adds the flags every command takes to root: the config
+// profile, the diagnostic log, progress events and the compliance policy.
+// Commands apply them, along with the defaults section of the config, before
+// they run; each flag is read by the commands it concerns.
+func AddGlobalFlags(root *cobra.Command) {
+	flags := root.PersistentFlags()
+	flags.String("config-profile", "", "Config profile to use instead of the current one (see config profile list)")
+	flags.BoolP("quiet", "q", false, "Only log errors")
+	flags.BoolP("verbose", "v", false, "Log debug messages (default from config preferences.verbose_mode)")
+	flags.String("log-format", "", "Log format: text or json (default from config logging.format)")
+	flags.String("progress", "", "Report progress on stderr: json for NDJSON events (phase, done, total, rate, eta) in place of the status lines")
+	flags.String("policy", "", "Compliance policy level for this run: strict, standard, permissive (default from compliance.level)")
+	flags.String("policy-token", "", "Policy token that lifts a strict policy level (default $"+policyTokenEnv+")")
+	flags.Bool("outside-window", false, "Run outside the scan windows of compliance.windows; flagged in the audit log")
+	root.PersistentPreRun = applyCommandDefaults
+}
+
+// selectConfigProfile applies --config-profile before any config is loaded
+func selectConfigProfile(cmd *cobra.Command) {
+	if name, _ := cmd.Flags().GetString("config-profile"); name != "" {
+		config.SelectConfigProfile(name)
+	}
+}
+
+// progressStderr is the real stderr while --progress json is on
+var progressStderr *os.File
+
+// configureProgress turns on the progress events asked for with --progress
+func configureProgress(cmd *cobra.Command) {
+	flag := cmd.Flags().Lookup("progress")
+	if flag == nil {
+		return
+	}
+	switch flag.Value.String() {
+	case "", "none":
+	case "json":
+		// Every stderr line has to parse as JSON: the events and the
+		// diagnostic log keep stderr, the human status lines are dropped
+		progressStderr = os.Stderr
+		progress.Enable(progressStderr, commandPath(cmd))
+		if devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+			os.Stderr = devNull
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid --progress %q (expected json or none)\n", flag.Value.String())
+		os.Exit(exitcode.Usage)
+	}
+}
+
+// configureLogging sets up the diagnostic log from the logging section of
+// the config, overridden by --quiet, --verbose and --log-format
+func configureLogging(cmd *cobra.Command, cfg config.Config) {
+	name := cfg.Logging.EffectiveLevel()
+	if cfg.Preferences.VerboseMode {
+		name = "debug"
+	}
+	// templates run has its own --log-level
+	if flag := cmd.Flags().Lookup("log-level"); flag != nil && flag.Changed {
+		name = flag.Value.String()
+	}
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	switch {
+	case quiet && verbose:
+		fmt.Fprintln(os.Stderr, "Error: --quiet and --verbose cannot be used together")
+		os.Exit(exitcode.Usage)
+	case quiet:
+		name = "error"
+	case verbose:
+		name = "debug"
+	}
+
+	level, err := logging.ParseLevel(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
+	format := cfg.Logging.EffectiveFormat()
+	if flag := cmd.Flags().Lookup("log-format"); flag != nil && flag.Changed {
+		format = flag.Value.String()
+	}
+	var output io.Writer
+	if progressStderr != nil {
+		format, output = "json", progressStderr
+	}
+	if err := logging.Configure(logging.Options{Level: level, Format: format, Output: output}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
+
+	if cfg.Logging.Persist {
+		if home, err := os.UserHomeDir(); err == nil {
+			logging.PersistRuns(filepath.Join(home, ".netcrate", "logs"))
+		}
+	}
+}
+
+// configureTransport applies the proxy and DNS settings, overridden by the
+// command's --proxy and --dns flags
+func configureTransport(cmd *cobra.Command, settings transport.Settings) {
+	if flag := cmd.Flags().Lookup("proxy"); flag != nil && flag.Changed {
+		settings.Proxy = flag.Value.String()
 	}
-	if *concurrency == 0 || *concurrency == 200 { // 200 is common default
-		*concurrency = profile.Concurrency
+	if flag := cmd.Flags().Lookup("dns"); flag != nil && flag.Changed {
+		settings.Resolvers, _ = cmd.Flags().GetStringSlice("dns")
 	}
-	if *timeout == 0 || *timeout == time.Second { // 1s is common default
-		*timeout = profile.Timeout
+	if err := transport.Configure(settings); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
 	}
 }
 
@@ -50,7 +370,16 @@ a comprehensive network scan with minimal configuration.
 Examples:
   netcrate quick              # Auto-detect and scan local network
   netcrate quick --dry-run    # Show what would be done
-  netcrate quick --yes        # Skip confirmation prompts`,
+  netcrate quick --yes        # Skip confirmation prompts
+  netcrate quick --targets 192.168.1.0/24,10.0.0.0/24  # Scan specific ranges
+  netcrate quick --cidr-limit /22                       # Resize the auto-detected network
+  netcrate quick --fingerprint                          # Identify applications on open ports
+  netcrate quick --watch 1h                             # Re-scan hourly and report changes
+  netcrate quick --resume quick_01JAB3                  # Continue an interrupted run
+  netcrate quick --exclude-gateway --exclude 192.168.1.50  # Skip the router and a fragile device
+  netcrate quick --yes --fail-on high                   # Exit with status 5 if high-risk ports are open
+  netcrate quick deep 192.168.1.10                      # Full workup of one host from a previous run
+  netcrate quick trends --last 10                       # Host, port and service trends across runs`,
 		Run: runQuick,
 	}
 
@@ -59,7 +388,48 @@ Examples:
 	cmd.Flags().Bool("yes", false, "Skip all confirmations")
 	cmd.Flags().Bool("interactive", false, "Enable interactive configuration selection")
 	cmd.Flags().String("iface", "", "Force specific network interface")
+	cmd.RegisterFlagCompletionFunc("iface", completeInterfaces)
 	cmd.Flags().Bool("dangerous", false, "Allow scanning of non-private networks")
+	cmd.Flags().StringSlice("targets", []string{}, "Target ranges to scan instead of the auto-detected network (CIDR or IP)")
+	cmd.Flags().String("cidr-limit", "", "Prefix length for the auto-detected network, e.g. /22")
+	cmd.Flags().Bool("fingerprint", false, "Fingerprint services on open ports (application, version, TLS)")
+	cmd.Flags().Duration("watch", 0, "Repeat the scan on an interval and report only changes (e.g. 1h)")
+	cmd.Flags().String("resume", "", "Resume an interrupted run from the port scanning stage")
+	cmd.Flags().Bool("exclude-self", false, "Do not scan this machine's own addresses (default from config quick_exclude_self)")
+	cmd.Flags().Bool("exclude-gateway", false, "Do not scan the default gateway (default from config quick_exclude_gateway)")
+	cmd.Flags().StringSlice("exclude", []string{}, "IPs or CIDRs to skip, added to the config do_not_scan list")
+	cmd.Flags().Bool("compat-a1", false, "Use A1 discovery (disable target pruning and adaptive rate)")
+	addFailOnFlag(cmd)
+	addRunLabelFlags(cmd)
+	addTransportFlags(cmd)
+	addAuthorizationFlags(cmd)
+
+	// Flag defaults from the config apply to the subcommands as well
+
+	cmd.AddCommand(newQuickDeepCommand())
+	cmd.AddCommand(newQuickTrendsCommand())
+
+	return cmd
+}
+
+func newQuickDeepCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deep <host>",
+		Short: "Intensive single-host workup after a quick run",
+		Long: `Deep runs a full port range scan, service fingerprinting, a TLS audit and a
+traceroute against one host, and stores the result in the quick run directory
+that discovered it.`,
+		Args: cobra.ExactArgs(1),
+		Run:  runQuickDeep,
+	}
+
+	cmd.Flags().String("run", "", "Quick run to attach to (default: latest run that saw the host)")
+	cmd.RegisterFlagCompletionFunc("run", completeRunIDs)
+	cmd.Flags().Bool("yes", false, "Skip confirmation")
+	cmd.Flags().Bool("dry-run", false, "Show what would be done without executing")
+	cmd.Flags().Int("max-hops", 30, "Maximum traceroute hops")
+	addTransportFlags(cmd)
+	addAuthorizationFlags(cmd)
 
 	return cmd
 }
@@ -70,38 +440,202 @@ func runQuick(cmd *cobra.Command, args []string) {
 	skipConfirm, _ := cmd.Flags().GetBool("yes")
 	interactive, _ := cmd.Flags().GetBool("interactive")
 	dangerousFlag, _ := cmd.Flags().GetBool("dangerous")
+	targetsFlag, _ := cmd.Flags().GetStringSlice("targets")
+	cidrLimitFlag, _ := cmd.Flags().GetString("cidr-limit")
+	fingerprint, _ := cmd.Flags().GetBool("fingerprint")
+	watchInterval, _ := cmd.Flags().GetDuration("watch")
+	ifaceFlag, _ := cmd.Flags().GetString("iface")
+	resumeRunID, _ := cmd.Flags().GetString("resume")
+	excludeSelf, _ := cmd.Flags().GetBool("exclude-self")
+	excludeGateway, _ := cmd.Flags().GetBool("exclude-gateway")
+	excludeFlag, _ := cmd.Flags().GetStringSlice("exclude")
+	compatA1, _ := cmd.Flags().GetBool("compat-a1")
 	
-	// Run compliance check before execution
-	checker, err := compliance.NewComplianceChecker()
+	// Exclusion defaults come from config unless overridden on the command line
+	if cm, err := config.NewConfigManager(); err == nil {
+		prefs := cm.GetConfig().Preferences
+		if !cmd.Flags().Changed("exclude-self") {
+			excludeSelf = prefs.QuickExcludeSelf
+		}
+		if !cmd.Flags().Changed("exclude-gateway") {
+			excludeGateway = prefs.QuickExcludeGateway
+		}
+		excludeFlag = append(excludeFlag, prefs.DoNotScan...)
+	}
+	network := currentNetworkSettings(ifaceFlag)
+	excludeFlag = append(excludeFlag, network.DoNotScan...)
+	project := currentProject()
+	excludeFlag = append(excludeFlag, project.Scope.Exclude...)
+	
+	cidrLimit, err := quick.ParseCIDRLimit(cidrLimitFlag)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Compliance checker initialization failed: %v\n", err)
-		os.Exit(1)
+		style.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(exitcode.Usage)
 	}
+	failOn := failOnThreshold(cmd)
 	
-	// For quick mode, we need to analyze targets from the detected network
-	// This is a simplified approach - in real implementation we'd get targets from quick mode analysis
-	targets := []string{"auto-detect"}
-	sessionID := fmt.Sprintf("quick-%d", time.Now().Unix())
+	// Compliance is checked once the targets are known: the detected or
+	// given networks after CIDR limits, not the flags as typed
+	checker := newComplianceChecker(cmd)
+	checker.Budget = &audit.Budget{
+		Profile:     rateProfileName(network),
+		Rate:        network.MaxRate,
+		Concurrency: network.MaxConcurrency,
+	}
+	preflight := func(targets []string, clamped []compliance.Clamp) error {
+		checker.Clamped = clamped
+		sessionID := fmt.Sprintf("quick-%d", time.Now().Unix())
+		complianceResult, err := checker.CheckCompliance(sessionID, "quick", "netcrate quick", targets, dangerousFlag)
+		if err != nil {
+			return err
+		}
+		for _, warning := range complianceResult.Warnings {
+			style.Fprintf(os.Stderr, "⚠️  %s\n", warning)
+		}
+		if scope := complianceResult.Scope; scope != nil {
+			style.Fprintf(os.Stderr, "📜 Engagement scope: %s (authorization: %s)\n", scope.File, scope.Authorization)
+		}
+		return nil
+	}
 	
-	complianceResult, err := checker.CheckCompliance(sessionID, "quick", "netcrate quick", targets, dangerousFlag)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Compliance violation: %v\n", err)
-		os.Exit(1)
+	opts := quick.QuickOptions{
+		DryRun:      dryRun,
+		SkipConfirm: skipConfirm,
+		Interactive: interactive,
+		Targets:     targetsFlag,
+		CIDRLimit:   cidrLimit,
+		Fingerprint: fingerprint,
+		Interface:   ifaceFlag,
+		ExcludeSelf:    excludeSelf,
+		ExcludeGateway: excludeGateway,
+		Exclude:        excludeFlag,
+		CompatA1:       compatA1,
+		Labels:         runLabelsFromFlags(cmd),
+		MaxRate:        network.MaxRate,
+		MaxConcurrency: network.MaxConcurrency,
+		Scope:          project.Scope.Allowed,
+		Preflight:      preflight,
+		Ceilings:       compliance.LoadCeilings(),
+		Authorization:  runAuthorization(cmd),
+		Classify:       complianceClassifier(checker),
 	}
 	
-	if complianceResult.Status == "blocked" {
-		fmt.Fprintf(os.Stderr, "❌ Scan blocked by compliance rules: %s\n", complianceResult.BlockReason)
-		os.Exit(1)
+	if resumeRunID != "" {
+		result, err := quick.ResumeQuickMode(resumeRunID, opts)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.quick.resume_failed", err))
+			os.Exit(failureCode(err))
+		}
+		quick.PrintQuickSummary(result)
+		if !dryRun {
+			exitForRun(failOn, result.DiscoverResult, result.ScanResult, quickClamps(result))
+		}
+		return
+	}
+	
+	if watchInterval > 0 {
+		if err := quick.WatchQuickMode(opts, watchInterval); err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.quick.watch_failed", err))
+			os.Exit(failureCode(err))
+		}
+		return
 	}
 	
-	result, err := quick.RunQuickMode(dryRun, skipConfirm, interactive)
+	result, err := quick.RunQuickMode(opts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Quick模式执行失败: %v\n", err)
-		os.Exit(1)
+		fmt.Fprint(os.Stderr, i18n.T("engine.quick.failed", err))
+		os.Exit(failureCode(err))
 	}
 	
 	if result != nil {
 		quick.PrintQuickSummary(result)
+		if !dryRun {
+			exitForRun(failOn, result.DiscoverResult, result.ScanResult, quickClamps(result))
+		}
+	}
+}
+
+// quickClamps returns the budget clamps of a quick run; dry runs and runs
+// saved without a run context have none
+func quickClamps(result *quick.QuickResult) []compliance.Clamp {
+	if result.Context == nil {
+		return nil
+	}
+	return result.Context.Clamped
+}
+
+func newQuickTrendsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trends",
+		Short: "Show host, port and service trends across recent quick runs",
+		Run:   runQuickTrends,
+	}
+
+	cmd.Flags().Int("last", 10, "Number of recent quick runs to aggregate (0 = all)")
+	cmd.Flags().Bool("json", false, "Output in JSON format")
+
+	return cmd
+}
+
+// runQuickTrends aggregates recent quick runs
+func runQuickTrends(cmd *cobra.Command, args []string) {
+	last, _ := cmd.Flags().GetInt("last")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	trends, err := output.ComputeQuickTrends(last)
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.quick.trends_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(trends); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(exitcode.Failed)
+		}
+		return
+	}
+
+	output.PrintQuickTrends(trends)
+}
+
+// runQuickDeep executes a single-host deep dive
+func runQuickDeep(cmd *cobra.Command, args []string) {
+	host := args[0]
+	runID, _ := cmd.Flags().GetString("run")
+	skipConfirm, _ := cmd.Flags().GetBool("yes")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	maxHops, _ := cmd.Flags().GetInt("max-hops")
+
+	checker := newComplianceChecker(cmd)
+
+	sessionID := fmt.Sprintf("quick-deep-%d", time.Now().Unix())
+	checker.Budget = &audit.Budget{Profile: rateProfileName(config.NetworkSettings{})}
+	complianceResult, err := checker.CheckCompliance(sessionID, "quick", "netcrate quick deep", []string{host}, false)
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ Compliance violation: %v\n", err)
+		os.Exit(exitcode.Blocked)
+	}
+	if complianceResult.Status == "blocked" {
+		style.Fprintf(os.Stderr, "❌ Scan blocked by compliance rules: %s\n", complianceResult.BlockReason)
+		os.Exit(exitcode.Blocked)
+	}
+
+	result, err := quick.RunDeepDive(host, quick.DeepOptions{
+		RunID:       runID,
+		SkipConfirm: skipConfirm,
+		DryRun:      dryRun,
+		MaxHops:     maxHops,
+	})
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.quick.deep_failed", err))
+		os.Exit(failureCode(err))
+	}
+
+	if !dryRun {
+		quick.PrintDeepSummary(result)
 	}
 }
 
@@ -139,22 +673,6 @@ func NewTemplateCommand() *cobra.Command {
 	return cmd
 }
 
-// NewConfigCommand creates the configuration management command
-func NewConfigCommand() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "config",
-		Short: "Manage configuration settings",
-		Long:  `Configuration management for NetCrate settings, rate limits, and compliance options.`,
-	}
-
-	// Add subcommands
-	cmd.AddCommand(newConfigShowCommand())
-	cmd.AddCommand(newConfigEditCommand())
-	cmd.AddCommand(newConfigResetCommand())
-
-	return cmd
-}
-
 // NewOutputCommand creates the output management command
 func NewOutputCommand() *cobra.Command {
 	cmd := &cobra.Command{
@@ -167,6 +685,13 @@ func NewOutputCommand() *cobra.Command {
 	cmd.AddCommand(newOutputShowCommand())
 	cmd.AddCommand(newOutputListCommand())
 	cmd.AddCommand(newOutputExportCommand())
+	cmd.AddCommand(newOutputQueryCommand())
+	cmd.AddCommand(newOutputHostsCommand())
+	cmd.AddCommand(newOutputPruneCommand())
+	cmd.AddCommand(newOutputTagCommand())
+	cmd.AddCommand(newOutputEncryptCommand())
+	cmd.AddCommand(newOutputMergeCommand())
+	cmd.AddCommand(newOutputReportCommand())
 
 	return cmd
 }
@@ -184,8 +709,18 @@ func newNetenvCommand() *cobra.Command {
 
 	// Add flags
 	cmd.Flags().Bool("json", false, "Output in JSON format")
-	cmd.Flags().Bool("ping-test", false, "Test gateway connectivity")
+	cmd.Flags().Bool("ping-test", false, "Test gateway connectivity and the health and latency of each DNS server")
 	cmd.Flags().String("interface", "auto", "Filter by interface name")
+	cmd.RegisterFlagCompletionFunc("interface", completeInterfacesOrAuto)
+	cmd.Flags().Bool("connectivity", false, "Check internet reachability, captive portals and DNS hijacking per interface")
+	cmd.Flags().Bool("watch", false, "Monitor interface, address, gateway, Wi-Fi and DNS changes until interrupted")
+	cmd.Flags().Duration("interval", 2*time.Second, "Polling interval of --watch")
+	cmd.Flags().Bool("egress", false, "Look up the public IP, NAT type and UPnP/NAT-PMP support (contacts external STUN or HTTPS servers)")
+	cmd.Flags().Bool("bandwidth", false, "Measure throughput to the gateway (UDP burst) or --bandwidth-peer and suggest a rate profile")
+	cmd.Flags().Bool("neighbors", false, "Listen for LLDP/CDP announcements naming the upstream switch, port and VLAN (needs packet capture)")
+	cmd.Flags().Duration("neighbor-wait", netenv.DefaultNeighborWait, "How long --neighbors listens on each interface")
+	cmd.Flags().String("bandwidth-peer", "", "host:port of a server that accepts and discards TCP data, measured over parallel streams")
+	cmd.Flags().Bool("dangerous", false, "Allow a --bandwidth-peer on a public network")
 	
 	return cmd
 }
@@ -204,9 +739,11 @@ func newDiscoverCommand() *cobra.Command {
 	cmd.Flags().Bool("json", false, "Output in JSON format")
 	cmd.Flags().StringSlice("methods", []string{"icmp", "tcp"}, "Discovery methods (icmp,tcp,arp)")
 	cmd.Flags().String("interface", "auto", "Network interface to use")
+	cmd.RegisterFlagCompletionFunc("interface", completeInterfacesOrAuto)
 	cmd.Flags().Int("rate", 100, "Packets per second")
 	cmd.Flags().Duration("timeout", 1000*time.Millisecond, "Timeout per target")
 	cmd.Flags().Int("concurrency", 200, "Maximum concurrent operations")
+	cmd.Flags().String("profile", "", "Rate profile for this run, sets the pacing flags that are not given (default: the current profile, see config rate list)")
 	cmd.Flags().IntSlice("tcp-ports", []int{80, 443, 22}, "TCP ports for discovery")
 	cmd.Flags().Bool("resolve", false, "Resolve hostnames")
 	
@@ -217,6 +754,17 @@ func newDiscoverCommand() *cobra.Command {
 	cmd.Flags().Bool("no-sampling", false, "Disable sampling for large ranges")
 	cmd.Flags().Bool("compat-a1", false, "Use A1 compatibility mode (disable all enhancements)")
 	cmd.Flags().Bool("dangerous", false, "Allow scanning of public networks")
+	cmd.Flags().String("nmap-xml", "", "Also write results as nmap XML to this file")
+	addTableFlags(cmd, true)
+	addRepeatFlags(cmd)
+	addDryRunFlag(cmd)
+	addResultSinkFlags(cmd)
+	addRunLabelFlags(cmd)
+	addSaveFlag(cmd)
+	addTransportFlags(cmd)
+	addAuthorizationFlags(cmd)
+	addPrivilegedHelperFlag(cmd)
+	addKeepRootFlag(cmd)
 
 	return cmd
 }
@@ -246,13 +794,27 @@ func newScanPortsCommand() *cobra.Command {
 	cmd.Flags().Bool("json", false, "Output in JSON format")
 	cmd.Flags().StringSlice("targets", []string{}, "Target hosts")
 	cmd.Flags().String("ports", "top100", "Ports to scan (top100,top1000,web,database,custom)")
+	cmd.RegisterFlagCompletionFunc("ports", completePortSets)
 	cmd.Flags().String("scan-type", "auto", "Scan type (connect,syn,udp,auto)")
 	cmd.Flags().Bool("service-detection", true, "Enable service detection")
 	cmd.Flags().Int("rate", 100, "Packets per second")
 	cmd.Flags().Duration("timeout", 800*time.Millisecond, "Timeout per port")
 	cmd.Flags().Int("concurrency", 200, "Maximum concurrent connections")
+	cmd.Flags().String("profile", "", "Rate profile for this run, sets the pacing flags that are not given (default: the current profile, see config rate list)")
 	cmd.Flags().Int("retries", 1, "Retry count for failed connections")
 	cmd.Flags().Bool("dangerous", false, "Allow scanning of public networks")
+	cmd.Flags().String("nmap-xml", "", "Also write results as nmap XML to this file")
+	addTableFlags(cmd, true)
+	addActionMenuFlag(cmd)
+	addRepeatFlags(cmd)
+	addFailOnFlag(cmd)
+	addDryRunFlag(cmd)
+	addResultSinkFlags(cmd)
+	addRunLabelFlags(cmd)
+	addSaveFlag(cmd)
+	addTransportFlags(cmd)
+	addAuthorizationFlags(cmd)
+	addKeepRootFlag(cmd)
 
 	return cmd
 }
@@ -285,12 +847,21 @@ func newPacketSendCommand() *cobra.Command {
 	cmd.Flags().Bool("json", false, "Output in JSON format")
 	cmd.Flags().StringSlice("targets", []string{}, "Target endpoints (IP:Port)")
 	cmd.Flags().String("template", "connect", "Packet template to use")
-	cmd.Flags().StringToString("param", map[string]string{}, "Template parameters (key=value)")
+	cmd.Flags().StringToString("param", map[string]string{}, "Template parameters (key=value; secret://name uses a secret from config secret set)")
 	cmd.Flags().Int("count", 1, "Number of packets per target")
 	cmd.Flags().Duration("interval", 100*time.Millisecond, "Interval between packets")
 	cmd.Flags().Duration("timeout", 5*time.Second, "Timeout per packet")
 	cmd.Flags().Bool("follow-redirects", false, "Follow HTTP redirects")
 	cmd.Flags().Int("max-response-size", 1024*1024, "Maximum response size")
+	cmd.Flags().String("profile", "", "Rate profile for this run, sets --interval and --timeout unless given (default: the current profile)")
+	cmd.Flags().Bool("dangerous", false, "Allow sending to public networks")
+	addRepeatFlags(cmd)
+	addDryRunFlag(cmd)
+	addResultSinkFlags(cmd)
+	addRunLabelFlags(cmd)
+	addSaveFlag(cmd)
+	addTransportFlags(cmd)
+	addAuthorizationFlags(cmd)
 
 	return cmd
 }
@@ -324,28 +895,33 @@ func newTemplateListCommand() *cobra.Command {
 
 func newTemplateRunCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "run <name>",
-		Short: "Run a template",
-		Args:  cobra.ExactArgs(1),
+		Use:               "run <name>",
+		Short:             "Run a template",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTemplateNames,
 		Run: func(cmd *cobra.Command, args []string) {
 			runTemplateRun(cmd, args)
 		},
 	}
 	
-	cmd.Flags().StringSlice("param", []string{}, "Template parameters (key=value)")
+	cmd.Flags().StringSlice("param", []string{}, "Template parameters (key=value; secret://name uses a secret from config secret set)")
 	cmd.Flags().Bool("yes", false, "Skip parameter confirmation")
 	cmd.Flags().Bool("continue-on-error", false, "Continue execution on step failures")
 	cmd.Flags().String("log-level", "info", "Log level (info, debug)")
 	cmd.Flags().Bool("dangerous", false, "Allow scanning of public networks")
+	cmd.Flags().String("profile", "", "Rate profile for the template's rate_profile parameter unless --param sets it")
+	addTransportFlags(cmd)
+	addAuthorizationFlags(cmd)
 	
 	return cmd
 }
 
 func newTemplateViewCommand() *cobra.Command {
 	return &cobra.Command{
-		Use:   "view <name>",
-		Short: "View template details",
-		Args:  cobra.ExactArgs(1),
+		Use:               "view <name>",
+		Short:             "View template details",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTemplateNames,
 		Run: func(cmd *cobra.Command, args []string) {
 			runTemplateView(cmd, args)
 		},
@@ -362,151 +938,478 @@ func newTemplateIndexCommand() *cobra.Command {
 	}
 }
 
-func newConfigShowCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "show",
-		Short: "Show current configuration",
-		Run: func(cmd *cobra.Command, args []string) {
-			cmd.Println("Config show command - Coming soon!")
-		},
-	}
-}
-
-func newConfigEditCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "edit",
-		Short: "Edit configuration interactively",
-		Run: func(cmd *cobra.Command, args []string) {
-			cmd.Println("Config edit command - Coming soon!")
-		},
-	}
-}
-
-func newConfigResetCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "reset",
-		Short: "Reset configuration to defaults",
-		Run: func(cmd *cobra.Command, args []string) {
-			cmd.Println("Config reset command - Coming soon!")
-		},
-	}
-}
-
 func newOutputShowCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "show",
 		Short: "Show scan results",
 		Long: `Show detailed results from previous scans.
 		
+Filters print a flat table instead of the full report: one row per port for
+quick and scan runs, one row per host for discover runs (or with --only up/down).
+
 Examples:
   netcrate output show --last        # Show latest run
-  netcrate output show --run quick_123456  # Show specific run`,
+  netcrate output show --run quick_01JAB3  # Show specific run (ID, unique prefix or alias)
+  netcrate output show --port 3389 --only open --fields host
+  netcrate output show --service ssh,http --fields host,port,version`,
 		Run: runOutputShow,
 	}
 
 	cmd.Flags().Bool("last", false, "Show the most recent run")
 	cmd.Flags().String("run", "", "Show specific run by ID")
+	cmd.RegisterFlagCompletionFunc("run", completeRunIDs)
 	cmd.Flags().Bool("json", false, "Output in JSON format")
+	cmd.Flags().String("only", "", "Only rows with this status (open, closed, filtered, up, down)")
+	cmd.Flags().StringSlice("host", []string{}, "Only these hosts")
+	cmd.Flags().IntSlice("port", []int{}, "Only these ports")
+	cmd.Flags().StringSlice("service", []string{}, "Only these services")
+	cmd.Flags().String("compliance", "", "Only hosts of this compliance class (private, public, blocked)")
+	cmd.Flags().StringSlice("fields", []string{}, "Columns to print, e.g. host,port,service")
+	addTableFlags(cmd, true)
 
 	return cmd
 }
 
 func newOutputListCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all saved results",
-		Long:  `List all saved scan results with summary information.`,
-		Run:   runOutputList,
+		Long: `List all saved scan results with summary information.
+
+Each run shows its size on disk; the footer totals runs, hosts, open ports
+and disk usage over every run matching the filters, including those past --limit.
+
+Examples:
+  netcrate output list --tag office
+  netcrate output list --search 192.168.1.0/24
+  netcrate output list --sort open-ports --limit 10`,
+		Run: runOutputList,
 	}
+
+	cmd.Flags().StringSlice("tag", []string{}, "Only list runs with this tag (repeatable)")
+	cmd.Flags().String("search", "", "Only list runs whose ID, name, tags, targets, notes or summary contain this text")
+	cmd.Flags().String("sort", "time", "Sort by time (newest first), hosts or open-ports (most first)")
+	cmd.Flags().Int("limit", 0, "Show at most this many runs (0 for all)")
+	addTableFlags(cmd, false)
+
+	return cmd
 }
 
-func newOutputExportCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "export",
-		Short: "Export results to file",
-		Run: func(cmd *cobra.Command, args []string) {
-			cmd.Println("Output export command - Coming soon!")
-		},
+func newOutputTagCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag <run-id>",
+		Short: "Name, tag or annotate a saved run",
+		Long: `Change the name, tags or notes of a saved run.
+
+Examples:
+  netcrate output tag quick_01JAB3 --tag office --name weekly-sweep
+  netcrate output tag quick_01JAB3 --remove-tag office
+  netcrate output tag quick_01JAB3 --note "printer VLAN moved"`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeRunIDArg,
+		Run:               runOutputTag,
 	}
-}
 
-// Implementation functions
+	cmd.Flags().String("name", "", "Set the run name")
+	cmd.Flags().StringSlice("tag", []string{}, "Add tags (repeatable)")
+	cmd.Flags().StringSlice("remove-tag", []string{}, "Remove tags (repeatable)")
+	cmd.Flags().String("note", "", "Set the run notes")
 
-func runNetenvDetect(cmd *cobra.Command) {
-	// Get flags
-	jsonOutput, _ := cmd.Flags().GetBool("json")
-	pingTest, _ := cmd.Flags().GetBool("ping-test")
-	interfaceFilter, _ := cmd.Flags().GetString("interface")
+	return cmd
+}
 
-	// Detect network environment
-	result, err := netenv.DetectNetworkEnvironment()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error detecting network environment: %v\n", err)
-		os.Exit(1)
-	}
+func newOutputMergeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge <run-id> <run-id>...",
+		Short: "Combine several runs into one aggregate run",
+		Long: `Union the results of several saved runs (e.g. scans of separate VLANs) into
+one new run for combined reporting. Quick, discover and scan runs can be mixed;
+where runs overlap, the most recent observation of a host or port wins.
+The merged run is a quick run that lists its sources in merged_from.
 
-	// Filter interfaces if specified
-	if interfaceFilter != "auto" && interfaceFilter != "" {
-		var filtered []netenv.NetworkInterface
-		for _, iface := range result.Interfaces {
-			if strings.Contains(iface.Name, interfaceFilter) {
-				filtered = append(filtered, iface)
-			}
-		}
-		result.Interfaces = filtered
+Examples:
+  netcrate output merge quick-20241114-090000 quick-20241114-100000 --out office-all
+  netcrate output merge discover_01JAB3 scan_01JAB4 --tag combined`,
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeRunIDs,
+		Run:               runOutputMerge,
 	}
 
-	// Test gateway connectivity if requested
-	if pingTest {
-		for i := range result.Interfaces {
-			if result.Interfaces[i].Gateway != nil {
-				err := netenv.PingGateway(result.Interfaces[i].Gateway)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: Failed to ping gateway %s: %v\n", 
-						result.Interfaces[i].Gateway.IP, err)
-				}
-			}
-		}
-	}
+	cmd.Flags().String("out", "", "Run ID of the merged run (default: a new merged_<ULID> ID)")
+	addRunLabelFlags(cmd)
 
-	// Output results
-	if jsonOutput {
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(result); err != nil {
-			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-			os.Exit(1)
-		}
-	} else {
-		printNetenvTable(result)
-	}
+	return cmd
 }
 
-func printNetenvTable(result *netenv.DetectResult) {
-	fmt.Println("🌐 Network Environment Detection")
+func newOutputReportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report [run-id]",
+		Short: "Generate a report for a saved run",
+		Long: `Render a saved quick, discover, scan or packet run as a standalone report.
+Each stage of the run (discovery, port scan, fingerprinting) is shown as a step.
+Without a run ID the most recent run is reported.
+
+Runs that scanned ports open with an executive summary: open ports by
+severity from the risk rules, a network hygiene score out of 100 and the top
+recommended actions. Scoring and advice come from ~/.netcrate/report_policy.yaml
+or the --policy file:
+
+  weights: {critical: 25, high: 10, medium: 4, low: 1}   # points lost per open port
+  tls_issue_weight: 3                                     # per certificate issue
+  max_actions: 5
+  actions:
+    telnet: Replace Telnet with SSH on {hosts}            # by risk rule or service
+
+Organizations can brand reports with their own layouts: drop Go html/template
+files into ~/.netcrate/report-templates/ and select them by name with
+--template. A <name>.yaml next to a template is used as its summary policy.
+--print-template writes the built-in layout as a starting point; the data
+available to templates is described in docs/REPORT_TEMPLATES.md.
+
+With --bundle the report is packaged into a zip archive together with the
+evidence behind it: the run's saved files (decrypted results, deep scans, packet
+captures), its runtime logs and a manifest.json with a SHA-256 checksum of every
+file. With --redact only the masked results are bundled.
+
+Every host links to a collapsible detail view with its open ports, banners and
+fingerprints, compared with the --history most recent earlier runs: whether the
+host was seen then and which of its ports opened, closed or changed.
+
+With --diff the report also shows what changed since an earlier run: new and
+vanished hosts, opened and closed ports, and services whose product or version
+changed, most severe first.
+
+Reports are written in English or Chinese: --lang, then the report_language
+setting, then the CLI language (language setting or NETCRATE_LANG).
+
+The reports section of the config file sets the default format, theme,
+history depth and the directory reports are written to when -o is not given.
+
+Examples:
+  netcrate output report quick_01JAB3 --format html --theme dark -o report.html
+  netcrate output report --redact standard -o shared.html
+  netcrate output report --diff quick_01JAA0 quick_01JAB3 -o changes.html
+  netcrate output report quick_01JAB3 --lang zh-CN
+  netcrate output report quick_01JAB3 --format md -o findings.md
+  netcrate output report --print-template > ~/.netcrate/report-templates/corporate.html
+  netcrate output report --template corporate -o branded.html
+  netcrate output report quick_01JAB3 --bundle evidence.zip`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeRunIDArg,
+		Run:               runOutputReport,
+	}
+
+	cmd.Flags().String("format", "html", "Report format (html, md)")
+	cmd.Flags().String("theme", "default", "HTML theme: default (follows the system light/dark setting), light, dark, minimal (print/PDF)")
+	cmd.Flags().String("title", "", "Report title (default: NetCrate report for <run-id>)")
+	cmd.Flags().String("redact", "", "Mask the report with a redaction profile (light, standard, strict, custom)")
+	cmd.Flags().String("diff", "", "Compare with an earlier run and show what changed")
+	cmd.RegisterFlagCompletionFunc("diff", completeRunIDs)
+	cmd.Flags().String("policy", "", "Scoring policy for the executive summary (default: ~/.netcrate/report_policy.yaml)")
+	cmd.Flags().String("template", "", "Custom layout from ~/.netcrate/report-templates (name or .html path)")
+	cmd.Flags().Bool("print-template", false, "Print the built-in report template and exit")
+	cmd.Flags().Int("history", output.DefaultHostHistory, "Earlier runs to compare each host with in its details (0 to skip)")
+	cmd.Flags().String("lang", "", "Report language (en, zh-CN; default: report_language setting, then CLI language)")
+	cmd.Flags().String("bundle", "", "Write a zip with the report, raw results and logs for audit evidence")
+	cmd.Flags().StringP("output", "o", "", "Output file, - for stdout (default: <run-id>.html)")
+
+	return cmd
+}
+
+func newOutputExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export results to file",
+		Long: `Export a saved run in another format.
+
+Formats:
+  json       NetCrate JSON result
+  nmap-xml   nmap-compatible XML for Metasploit, Faraday, EyeWitness and similar tools
+  csv        flat table for spreadsheets and BI tools, selected with --table:
+               ports     one row per host and port
+               hosts     one row per discovered host
+               services  one row per open service and port, with the hosts running it
+  syslog     RFC 5424 syslog lines, one per live host and open port
+  cef        the same findings as CEF records, for SIEMs such as ArcSight
+
+Examples:
+  netcrate output export --last --format nmap-xml -o scan.xml
+  netcrate output export --run quick_01JAB3 --format json
+  netcrate output export --last --format csv --table ports -o ports.csv
+  netcrate output export --last --format cef -o findings.cef
+  netcrate output export --last --format csv --redact standard -o shared.csv
+
+Redaction profiles (--redact) mask results before export so they can be shared:
+  light     last IPv4 octet and IPv6 interface IDs; masked addresses are
+            numbered so hosts stay distinct (192.168.1.x#3)
+  standard  light, plus hostnames (as stable pseudonyms), banners and MAC suffixes
+  strict    standard, masking the last two IPv4 octets
+  custom    the redaction_* settings from 'netcrate config set'`,
+		Run: runOutputExport,
+	}
+
+	cmd.Flags().Bool("last", false, "Export the most recent run")
+	cmd.Flags().String("run", "", "Export specific run by ID")
+	cmd.RegisterFlagCompletionFunc("run", completeRunIDs)
+	cmd.Flags().String("format", "json", "Export format (json, nmap-xml, csv, syslog, cef)")
+	cmd.Flags().String("table", "ports", "Table for csv export (ports, hosts, services)")
+	cmd.Flags().String("redact", "", "Mask the export with a redaction profile (light, standard, strict, custom)")
+	cmd.Flags().StringP("output", "o", "-", "Output file (- for stdout)")
+
+	return cmd
+}
+
+func newOutputQueryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query [hosts|ports|services|runs]",
+		Short: "Query results across all saved runs",
+		Long: `Query the SQLite index of saved runs in ~/.netcrate/netcrate.db.
+The index is brought up to date with the saved JSON runs before each query.
+Enable 'netcrate config set results_db true' to also index runs as they are saved.
+
+Queries:
+  hosts      every host ever seen, with first/last seen and run count
+  ports      every open port ever seen (use --host to limit to one host)
+  services   open ports grouped by service
+  runs       indexed runs
+
+Tables for --sql: runs, hosts, ports (opened read-only)
+
+Examples:
+  netcrate output query hosts
+  netcrate output query ports --host 192.168.1.10
+  netcrate output query --sql "SELECT host, COUNT(*) FROM ports WHERE status='open' GROUP BY host"
+  netcrate output query --rebuild`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runOutputQuery,
+	}
+
+	cmd.Flags().String("host", "", "Limit the ports query to one host")
+	cmd.Flags().String("sql", "", "Run a raw read-only SQL query")
+	cmd.Flags().Bool("rebuild", false, "Rebuild the index from the saved runs")
+	cmd.Flags().Bool("json", false, "Output in JSON format")
+	addTableFlags(cmd, true)
+
+	return cmd
+}
+
+func newOutputHostsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hosts",
+		Short: "Show per-host profiles merged across runs",
+		Long: `Merge all saved runs into per-host profiles: first/last seen, MAC/vendor,
+hostnames, historical open ports, services, fingerprints and risk notes.
+
+Examples:
+  netcrate output hosts
+  netcrate output hosts --host 192.168.1.10
+  netcrate output hosts --json`,
+		Run: runOutputHosts,
+	}
+
+	cmd.Flags().String("host", "", "Show the full profile of one host")
+	cmd.Flags().Bool("json", false, "Output in JSON format")
+	addTableFlags(cmd, true)
+
+	return cmd
+}
+
+func newOutputPruneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove old saved runs",
+		Long: `Remove saved runs from ~/.netcrate/runs. Runs are kept newest first until a
+limit is reached. Without limit flags the configured retention policy is used;
+it is also applied automatically after every run:
+
+  netcrate config set retention_max_runs 100
+  netcrate config set retention_max_age 90d
+  netcrate config set retention_max_disk 1GB
+
+Examples:
+  netcrate output prune --older-than 30d --dry-run
+  netcrate output prune --max-runs 20
+  netcrate output prune --max-size 500MB`,
+		Run: runOutputPrune,
+	}
+
+	cmd.Flags().String("older-than", "", "Remove runs older than this age (e.g. 30d, 2w, 12h)")
+	cmd.Flags().Int("max-runs", 0, "Keep at most this many runs")
+	cmd.Flags().String("max-size", "", "Keep runs within this total size (e.g. 500MB)")
+	cmd.Flags().Bool("dry-run", false, "Show what would be removed without removing it")
+
+	return cmd
+}
+
+func newOutputEncryptCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt existing saved runs",
+		Long: `Encrypt the saved runs in ~/.netcrate/runs that are still stored in plain text.
+New runs are encrypted as they are saved once encryption is enabled:
+
+  netcrate config set encryption_key_file ~/.netcrate/key
+  netcrate config set encryption_enabled true
+
+A passphrase can be used instead of a key file, either stored in the
+configuration (encryption_passphrase) or given in $NETCRATE_PASSPHRASE.
+Output commands decrypt runs transparently when the key is available.`,
+		Args: cobra.NoArgs,
+		Run:  runOutputEncrypt,
+	}
+}
+
+// Implementation functions
+
+func runNetenvDetect(cmd *cobra.Command) {
+	// Get flags
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	pingTest, _ := cmd.Flags().GetBool("ping-test")
+	interfaceFilter, _ := cmd.Flags().GetString("interface")
+	egress, _ := cmd.Flags().GetBool("egress")
+	connectivity, _ := cmd.Flags().GetBool("connectivity")
+	bandwidth, _ := cmd.Flags().GetBool("bandwidth")
+	bandwidthPeer, _ := cmd.Flags().GetString("bandwidth-peer")
+	neighbors, _ := cmd.Flags().GetBool("neighbors")
+
+	// A bandwidth probe loads the link, so it stops with the kill switch and
+	// a peer goes through the compliance check of the ops commands
+	if bandwidth || bandwidthPeer != "" {
+		guardCommand(cmd)
+	}
+	if bandwidthPeer != "" {
+		checkOpsCompliance(cmd, "netcrate ops netenv --bandwidth-peer", packetTargetHosts([]string{bandwidthPeer}),
+			audit.Budget{Concurrency: netenv.DefaultBandwidthStreams}, nil)
+	}
+
+	if watch, _ := cmd.Flags().GetBool("watch"); watch {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		runNetenvWatch(interfaceFilter, interval, jsonOutput)
+		return
+	}
+
+	// Detect network environment
+	result, err := netenv.DetectNetworkEnvironment()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error detecting network environment: %v\n", err)
+		os.Exit(exitcode.Failed)
+	}
+
+	// Filter interfaces if specified
+	if interfaceFilter != "auto" && interfaceFilter != "" {
+		var filtered []netenv.NetworkInterface
+		for _, iface := range result.Interfaces {
+			if strings.Contains(iface.Name, interfaceFilter) {
+				filtered = append(filtered, iface)
+			}
+		}
+		result.Interfaces = filtered
+
+		var routes []netenv.Route
+		for _, route := range result.Routes {
+			if strings.Contains(route.Interface, interfaceFilter) {
+				routes = append(routes, route)
+			}
+		}
+		result.Routes = routes
+	}
+
+	// Test gateway connectivity if requested
+	if pingTest {
+		for i := range result.Interfaces {
+			if result.Interfaces[i].Gateway != nil {
+				err := netenv.PingGateway(result.Interfaces[i].Gateway)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: Failed to ping gateway %s: %v\n", 
+						result.Interfaces[i].Gateway.IP, err)
+				}
+			}
+		}
+		result.DNSHealth = netenv.CheckDNSServers(result.SystemInfo.DNSServers, dnsHealthTimeout)
+	}
+
+	if connectivity {
+		checkConnectivity(result.Interfaces)
+	}
+	if neighbors {
+		wait, _ := cmd.Flags().GetDuration("neighbor-wait")
+		discoverNeighbors(result, interfaceFilter != "auto" && interfaceFilter != "", wait)
+	}
+	if egress {
+		result.Egress = detectEgress(result)
+	}
+	if bandwidth || bandwidthPeer != "" {
+		result.Bandwidth = estimateBandwidth(result, bandwidthPeer)
+	}
+
+	// Output results
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(exitcode.Failed)
+		}
+	} else {
+		printNetenvTable(result)
+	}
+}
+
+func printNetenvTable(result *netenv.DetectResult) {
+	style.Println("🌐 Network Environment Detection")
 	fmt.Println()
 
 	// System Information
-	fmt.Printf("📋 System Information:\n")
+	style.Printf("📋 System Information:\n")
 	fmt.Printf("  Platform: %s\n", result.SystemInfo.Platform)
 	fmt.Printf("  Hostname: %s\n", result.SystemInfo.Hostname)
 	if len(result.SystemInfo.DNSServers) > 0 {
 		fmt.Printf("  DNS Servers: %s\n", strings.Join(result.SystemInfo.DNSServers, ", "))
 	}
+	for _, health := range result.DNSHealth {
+		switch health.Status {
+		case netenv.DNSHealthy:
+			style.Printf("    ✅ %s: %.1fms\n", health.Server, health.LatencyMS)
+		case netenv.DNSSlow:
+			style.Printf("    ⚠️  %s: slow, %.1fms\n", health.Server, health.LatencyMS)
+		default:
+			style.Printf("    ❌ %s: failing (%s)\n", health.Server, health.Error)
+		}
+	}
 	if result.SystemInfo.DefaultRoute != "" {
 		fmt.Printf("  Default Route: %s\n", result.SystemInfo.DefaultRoute)
 	}
+	if defaults := netenv.DefaultGateways(result.Routes); len(defaults) > 1 {
+		fmt.Printf("  Default Gateways:\n")
+		for _, route := range defaults {
+			fmt.Printf("    %s via %s (metric %d)\n", route.Gateway, route.Interface, route.Metric)
+		}
+	}
 	fmt.Println()
 
 	// Capabilities
-	fmt.Printf("🔧 Capabilities:\n")
-	fmt.Printf("  Raw Socket: %v\n", result.Capabilities.RawSocket)
-	fmt.Printf("  Promiscuous Mode: %v\n", result.Capabilities.PromiscuousMode)
-	fmt.Printf("  Packet Capture: %v\n", result.Capabilities.PacketCapture)
+	style.Printf("🔧 Capabilities:\n")
+	for _, c := range result.Capabilities.Matrix {
+		if c.Available {
+			style.Printf("  ✅ %-15s %s\n", c.Name, strings.Join(c.Enables, ", "))
+			continue
+		}
+		style.Printf("  ❌ %-15s %s\n", c.Name, strings.Join(c.Limits, "; "))
+		if c.Remedy != "" {
+			style.Printf("     💡 %s\n", c.Remedy)
+		}
+	}
 	fmt.Println()
 
+	if egress := result.Egress; egress != nil {
+		printEgress(egress)
+	}
+	if bandwidth := result.Bandwidth; bandwidth != nil {
+		printBandwidth(bandwidth)
+	}
+
 	// Network Interfaces
-	fmt.Printf("🔌 Network Interfaces (%d found):\n", len(result.Interfaces))
+	style.Printf("🔌 Network Interfaces (%d found):\n", len(result.Interfaces))
 	if result.Recommended != "" {
 		fmt.Printf("   Recommended: %s\n", result.Recommended)
 	}
@@ -520,12 +1423,46 @@ func printNetenvTable(result *netenv.DetectResult) {
 		}
 
 		fmt.Printf("%s%s (%s)\n", prefix, iface.Name, iface.DisplayName)
+		ifaceType := iface.Type
+		if iface.Overlay != "" {
+			ifaceType += " (" + iface.Overlay + ")"
+		}
 		fmt.Printf("    Type: %s | Status: %s | MTU: %d\n", 
-			iface.Type, iface.Status, iface.MTU)
+			ifaceType, iface.Status, iface.MTU)
+		if iface.SpansRemote() {
+			style.Printf("    ⚠️  Tunnel: private addresses here reach remote networks, not a local LAN\n")
+		}
 		
 		if iface.MacAddress != "" {
 			fmt.Printf("    MAC: %s\n", iface.MacAddress)
 		}
+		if wifi := iface.WiFi; wifi != nil {
+			fmt.Printf("    Wi-Fi: %s", wifi.SSID)
+			if wifi.BSSID != "" {
+				fmt.Printf(" (BSSID %s)", wifi.BSSID)
+			}
+			fmt.Println()
+			var details []string
+			if wifi.Channel > 0 {
+				details = append(details, fmt.Sprintf("channel %d", wifi.Channel))
+			}
+			if wifi.RSSI != 0 {
+				details = append(details, fmt.Sprintf("signal %d dBm", wifi.RSSI))
+			}
+			if wifi.Security != "" {
+				details = append(details, "security "+wifi.Security)
+			}
+			if len(details) > 0 {
+				fmt.Printf("    %s\n", strings.Join(details, " | "))
+			}
+		}
+
+		if c := iface.Connectivity; c != nil {
+			printConnectivity(c)
+		}
+		if n := iface.Neighbor; n != nil {
+			printNeighbor(n)
+		}
 
 		// Print addresses
 		for _, addr := range iface.Addresses {
@@ -551,211 +1488,699 @@ func printNetenvTable(result *netenv.DetectResult) {
 	if len(result.Interfaces) == 0 {
 		fmt.Println("  No active interfaces found")
 	}
-}
-
-func runDiscover(cmd *cobra.Command, args []string) {
-	// Get flags
-	jsonOutput, _ := cmd.Flags().GetBool("json")
-	methods, _ := cmd.Flags().GetStringSlice("methods")
-	iface, _ := cmd.Flags().GetString("interface")
-	rate, _ := cmd.Flags().GetInt("rate")
-	timeout, _ := cmd.Flags().GetDuration("timeout")
-	concurrency, _ := cmd.Flags().GetInt("concurrency")
-	tcpPorts, _ := cmd.Flags().GetIntSlice("tcp-ports")
-	resolve, _ := cmd.Flags().GetBool("resolve")
-	
-	// Apply rate profile if values not explicitly set
-	applyRateProfile(&rate, &concurrency, &timeout)
-	
-	// Enhanced discovery flags
-	enhanced, _ := cmd.Flags().GetBool("enhanced")
-	targetPruning, _ := cmd.Flags().GetBool("target-pruning")
-	noAdaptiveRate, _ := cmd.Flags().GetBool("no-adaptive-rate")
-	noSampling, _ := cmd.Flags().GetBool("no-sampling")
-	compatA1, _ := cmd.Flags().GetBool("compat-a1")
-
-	// Get targets from arguments
-	var targets []string
-	if len(args) == 0 {
-		targets = []string{"auto"}
-	} else {
-		targets = args
-	}
-
-	// Create discover options
-	opts := ops.DiscoverOptions{
-		Targets:          targets,
-		Methods:          methods,
-		Interface:        iface,
-		Rate:            rate,
-		Timeout:         timeout,
-		Concurrency:     concurrency,
-		TCPPorts:        tcpPorts,
-		ResolveHostnames: resolve,
-	}
 
-	// Check if we should use enhanced discovery
-	useEnhanced := enhanced || targetPruning || (!noAdaptiveRate && !compatA1) || (!noSampling && !compatA1)
-	
-	if useEnhanced && !compatA1 {
-		// Use enhanced discovery
-		enhancedOpts := ops.DiscoverEnhancedOptions{
-			DiscoverOptions:        opts,
-			EnableTargetPruning:    targetPruning || enhanced,
-			EnableSampling:         !noSampling && enhanced,
-			EnableMethodFallback:   enhanced,
-			EnableAdaptiveRate:     !noAdaptiveRate && enhanced,
-			SamplingPercent:        0.05, // 5% for large networks
-			HighLossThreshold:      0.3,  // 30%
-			DownshiftStep:          0.2,  // 20% reduction
-			UpshiftStep:            0.1,  // 10% increase
-			GoodWindowsToUpshift:   3,
-			NoAdaptiveRate:         noAdaptiveRate,
-			NoSampling:            noSampling,
-			CompatA1:              compatA1,
-		}
-		
-		// Run enhanced discovery
-		fmt.Fprintf(os.Stderr, "🚀 Starting enhanced host discovery (B1)...\n")
-		if enhancedOpts.EnableTargetPruning {
-			fmt.Fprintf(os.Stderr, "✨ Target prioritization enabled (ARP cache, gateway)\n")
+	if len(result.Routes) > 0 {
+		style.Printf("🧭 Routes (%d):\n", len(result.Routes))
+		fmt.Printf("  %-18s %-15s %-12s %s\n", "DESTINATION", "GATEWAY", "INTERFACE", "METRIC")
+		for _, route := range result.Routes {
+			gateway := route.Gateway
+			if gateway == "" {
+				gateway = "direct"
+			}
+			fmt.Printf("  %-18s %-15s %-12s %d\n", route.Destination, gateway, route.Interface, route.Metric)
 		}
-		fmt.Fprintf(os.Stderr, "Targets: %s\n", strings.Join(targets, ", "))
-		fmt.Fprintf(os.Stderr, "Methods: %s\n", strings.Join(methods, ", "))
-		fmt.Fprintf(os.Stderr, "Rate: %d pps | Concurrency: %d | Timeout: %v\n", rate, concurrency, timeout)
-		fmt.Fprintf(os.Stderr, "\n")
+	}
+}
 
-		enhancedResult, err := ops.EnhancedDiscover(enhancedOpts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error during enhanced discovery: %v\n", err)
-			os.Exit(1)
+// runNetenvWatch prints network changes as they happen, as a table or one
+// JSON object per line
+func runNetenvWatch(interfaceFilter string, interval time.Duration, jsonOutput bool) {
+	if interval < 500*time.Millisecond {
+		fmt.Fprintf(os.Stderr, "Error: watch interval must be at least 500ms, got %s\n", interval)
+		os.Exit(exitcode.Usage)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	stop := make(chan struct{})
+	go func() {
+		<-sigChan
+		close(stop)
+	}()
+
+	style.Fprintf(os.Stderr, "👀 Watching network changes every %s (Ctrl+C to stop)\n", interval)
+	if !jsonOutput {
+		fmt.Printf("%-8s  %-22s %-12s %s\n", "TIME", "EVENT", "INTERFACE", "CHANGE")
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	err := netenv.Watch(interval, stop, func(event netenv.ChangeEvent) {
+		if interfaceFilter != "auto" && interfaceFilter != "" && event.Interface != "" && !strings.Contains(event.Interface, interfaceFilter) {
+			return
 		}
-
-		// Output results
 		if jsonOutput {
-			encoder := json.NewEncoder(os.Stdout)
-			encoder.SetIndent("", "  ")
-			if err := encoder.Encode(enhancedResult); err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-				os.Exit(1)
-			}
-		} else {
-			// Print enhanced summary first
-			printEnhancedDiscoverSummary(enhancedResult)
-			// Then print regular table
-			printDiscoverTable(enhancedResult.DiscoverSummary)
-		}
-	} else {
-		// Use original discovery
-		fmt.Fprintf(os.Stderr, "🔍 Starting host discovery...\n")
-		if compatA1 {
-			fmt.Fprintf(os.Stderr, "🔄 A1 compatibility mode enabled\n")
+			encoder.Encode(event)
+			return
 		}
-		fmt.Fprintf(os.Stderr, "Targets: %s\n", strings.Join(targets, ", "))
-		fmt.Fprintf(os.Stderr, "Methods: %s\n", strings.Join(methods, ", "))
-		fmt.Fprintf(os.Stderr, "Rate: %d pps | Concurrency: %d | Timeout: %v\n", rate, concurrency, timeout)
-		fmt.Fprintf(os.Stderr, "\n")
-
-		result, err := ops.Discover(opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error during discovery: %v\n", err)
-			os.Exit(1)
+		change := event.New
+		switch {
+		case event.Old != "" && event.New != "":
+			change = event.Old + " → " + event.New
+		case event.Old != "":
+			change = event.Old
 		}
-
-		// Output results
-		if jsonOutput {
-			encoder := json.NewEncoder(os.Stdout)
-			encoder.SetIndent("", "  ")
-			if err := encoder.Encode(result); err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-				os.Exit(1)
-			}
-		} else {
-			printDiscoverTable(result)
+		iface := event.Interface
+		if iface == "" {
+			iface = "-"
 		}
+		fmt.Printf("%-8s  %-22s %-12s %s\n", event.Time.Format("15:04:05"), event.Event, iface, change)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error watching network environment: %v\n", err)
+		os.Exit(exitcode.Failed)
 	}
 }
 
-func printDiscoverTable(result *ops.DiscoverSummary) {
-	fmt.Printf("🔍 Host Discovery Results\n")
-	fmt.Printf("Run ID: %s\n", result.RunID)
-	fmt.Printf("Duration: %.1fs\n", result.Duration)
-	fmt.Printf("Targets: %d | Discovered: %d | Success Rate: %.1f%%\n", 
-		result.TargetsResolved, result.HostsDiscovered, result.SuccessRate*100)
-	fmt.Printf("Methods Used: %s\n", strings.Join(result.MethodUsed, ", "))
-	fmt.Println()
-
-	if len(result.Results) == 0 {
-		fmt.Println("No hosts discovered.")
-		return
-	}
+// connectivityTimeout bounds each reachability probe of ops netenv
+const connectivityTimeout = 5 * time.Second
 
-	// Filter and sort results - show active hosts first
-	var activeHosts []ops.DiscoverResult
-	var inactiveHosts []ops.DiscoverResult
+// dnsHealthTimeout bounds the test query to each DNS server
+const dnsHealthTimeout = 2 * time.Second
 
-	for _, r := range result.Results {
-		if r.Status == "up" {
-			activeHosts = append(activeHosts, r)
-		} else {
-			inactiveHosts = append(inactiveHosts, r)
+// checkConnectivity probes the internet from every active interface at once
+func checkConnectivity(interfaces []netenv.NetworkInterface) {
+	var wg sync.WaitGroup
+	for i := range interfaces {
+		iface := &interfaces[i]
+		if iface.Type == "loopback" || iface.Status != "up" {
+			continue
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			iface.Connectivity = netenv.CheckConnectivity(*iface, connectivityTimeout)
+		}()
 	}
+	wg.Wait()
+}
 
-	// Print active hosts
-	if len(activeHosts) > 0 {
-		fmt.Printf("✅ Active Hosts (%d):\n", len(activeHosts))
-		fmt.Printf("%-15s %-8s %-8s %-10s %s\n", "Host", "Status", "RTT", "Method", "Details")
-		fmt.Println(strings.Repeat("-", 60))
+// discoverNeighbors listens for LLDP/CDP on the filtered interfaces, or on
+// the recommended one, all at once
+func discoverNeighbors(result *netenv.DetectResult, filtered bool, wait time.Duration) {
+	if pcap := netenv.LookupCapability(result.Capabilities.Matrix, netenv.CapPacketCapture); pcap != nil && !pcap.Available {
+		style.Fprintf(os.Stderr, "⚠️  Neighbor discovery needs packet capture: %s\n", pcap.Reason())
+		return
+	}
 
-		for _, host := range activeHosts {
-			rttStr := fmt.Sprintf("%.1fms", host.RTT)
-			details := ""
-			if host.Hostname != "" {
-				details = fmt.Sprintf("(%s)", host.Hostname)
+	var wg sync.WaitGroup
+	for i := range result.Interfaces {
+		iface := &result.Interfaces[i]
+		if iface.Type == "loopback" || iface.Overlay != "" || iface.Status != "up" {
+			continue
+		}
+		if !filtered && iface.Name != result.Recommended {
+			continue
+		}
+		style.Fprintf(os.Stderr, "👂 Listening for LLDP/CDP on %s for up to %s...\n", iface.Name, wait)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			neighbor, err := netenv.DiscoverNeighbor(iface.Name, wait)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: neighbor discovery on %s: %v\n", iface.Name, err)
+				return
 			}
-			if port, ok := host.Details["tcp_port"]; ok {
-				details = fmt.Sprintf("port %v", port)
+			if neighbor == nil {
+				fmt.Fprintf(os.Stderr, "No LLDP/CDP announcement on %s within %s\n", iface.Name, wait)
 			}
+			iface.Neighbor = neighbor
+		}()
+	}
+	wg.Wait()
+}
 
-			fmt.Printf("%-15s %-8s %-8s %-10s %s\n", 
-				host.Host, host.Status, rttStr, host.Method, details)
+// printNeighbor shows the switch at the far end of an interface
+func printNeighbor(n *netenv.Neighbor) {
+	name := n.SystemName
+	if name == "" {
+		name = n.ChassisID
+	}
+	fmt.Printf("    Switch: %s", name)
+	if n.PortID != "" {
+		fmt.Printf(", port %s", n.PortID)
+		if n.PortDescription != "" && n.PortDescription != n.PortID {
+			fmt.Printf(" (%s)", n.PortDescription)
 		}
-		fmt.Println()
 	}
+	if n.VLAN > 0 {
+		fmt.Printf(", VLAN %d", n.VLAN)
+	}
+	fmt.Printf(" [%s]\n", strings.ToUpper(n.Protocol))
+	if n.ManagementIP != "" {
+		fmt.Printf("      Management: %s\n", n.ManagementIP)
+	}
+	if n.Platform != "" {
+		fmt.Printf("      Platform: %s\n", n.Platform)
+	}
+}
 
-	// Print summary statistics
-	fmt.Printf("📊 Statistics:\n")
-	fmt.Printf("  Total Sent: %d\n", result.Stats.Sent)
-	fmt.Printf("  Responses: %d\n", result.Stats.Received)
-	fmt.Printf("  Timeouts: %d\n", result.Stats.Timeouts)
-	fmt.Printf("  Errors: %d\n", result.Stats.Errors)
-	fmt.Println()
-
-	// Print method breakdown
-	if len(result.Stats.MethodBreakdown) > 0 {
-		fmt.Printf("🔧 Method Breakdown:\n")
-		for method, stats := range result.Stats.MethodBreakdown {
-			successRate := float64(0)
-			if stats.Sent > 0 {
-				successRate = float64(stats.Received) / float64(stats.Sent) * 100
-			}
-			fmt.Printf("  %s: %d/%d (%.1f%%)\n", method, stats.Received, stats.Sent, successRate)
+// printConnectivity shows the reachability of one interface
+func printConnectivity(c *netenv.Connectivity) {
+	switch {
+	case c.CaptivePortal && c.PortalURL != "":
+		style.Printf("    ⚠️  Captive portal: %s (sign in before scanning)\n", c.PortalURL)
+	case c.CaptivePortal:
+		style.Printf("    ⚠️  Captive portal (sign in before scanning)\n")
+	case c.Internet:
+		fmt.Printf("    Internet: reachable (%.0fms)\n", c.LatencyMS)
+	default:
+		fmt.Printf("    Internet: unreachable\n")
+		for _, e := range c.Errors {
+			fmt.Printf("      %s\n", e)
 		}
-		fmt.Println()
 	}
+	if c.DNSHijack {
+		style.Printf("    ⚠️  DNS hijacking: nonexistent names resolve to %s\n", c.HijackAddress)
+	}
+}
 
-	// Show inactive hosts summary (don't spam with details)
-	if len(inactiveHosts) > 0 {
-		fmt.Printf("❌ Inactive Hosts: %d\n", len(inactiveHosts))
-		fmt.Printf("   Use --json flag to see full details\n")
+// detectEgress looks up the public address with the configured endpoints,
+// asking the gateway of the recommended interface about NAT-PMP
+func detectEgress(result *netenv.DetectResult) *netenv.EgressInfo {
+	opts := netenv.EgressOptions{}
+	if cm, err := config.NewConfigManager(); err == nil {
+		opts.STUNServers = cm.GetConfig().Egress.STUNServers
+		opts.IPServices = cm.GetConfig().Egress.IPServices
+	}
+	for _, iface := range result.Interfaces {
+		if iface.Name == result.Recommended && iface.Gateway != nil {
+			opts.Gateway = iface.Gateway.IP
+		}
 	}
+	return netenv.DetectEgress(opts)
 }
 
-func runPacketSend(cmd *cobra.Command, args []string) {
-	// Get flags
-	jsonOutput, _ := cmd.Flags().GetBool("json")
-	targets, _ := cmd.Flags().GetStringSlice("targets")
+// printEgress shows what the network exposes outward
+func printEgress(egress *netenv.EgressInfo) {
+	style.Printf("🌍 Egress:\n")
+	if egress.PublicIP != "" {
+		fmt.Printf("  Public IP: %s (via %s)\n", egress.PublicIP, egress.Source)
+	} else {
+		fmt.Printf("  Public IP: unknown\n")
+	}
+	if egress.LocalIP != "" {
+		fmt.Printf("  Local IP: %s\n", egress.LocalIP)
+	}
+	fmt.Printf("  NAT: %s\n", egress.NAT)
+	if egress.UPnP {
+		fmt.Printf("  UPnP IGD: yes")
+		if egress.UPnPServer != "" {
+			fmt.Printf(" (%s)", egress.UPnPServer)
+		}
+		fmt.Println()
+	} else {
+		fmt.Printf("  UPnP IGD: no\n")
+	}
+	if egress.NATPMP {
+		fmt.Printf("  NAT-PMP: yes (external %s)\n", egress.NATPMPIP)
+	} else {
+		fmt.Printf("  NAT-PMP: no\n")
+	}
+	if egress.DoubleNAT {
+		style.Printf("  ⚠️  Double NAT: the gateway's external address is not the public one\n")
+	}
+	if egress.PublicIP == "" {
+		for _, e := range egress.Errors {
+			style.Printf("  ⚠️  %s\n", e)
+		}
+	}
+	fmt.Println()
+}
+
+// estimateBandwidth measures the throughput to a peer, or to the gateway of
+// the recommended interface, and matches the fastest configured rate profile
+// that stays within the suggested rate
+func estimateBandwidth(result *netenv.DetectResult, peer string) *netenv.BandwidthEstimate {
+	opts := netenv.BandwidthOptions{Peer: peer}
+	for _, iface := range result.Interfaces {
+		if iface.Name == result.Recommended && iface.Gateway != nil {
+			opts.Gateway = iface.Gateway.IP
+		}
+	}
+	estimate := netenv.EstimateBandwidth(opts)
+	if estimate.Bytes == 0 {
+		return estimate
+	}
+
+	if cm, err := config.NewConfigManager(); err == nil {
+		profiles := cm.GetAvailableProfiles()
+		for _, name := range cm.ProfileNames() {
+			if profiles[name].Rate > estimate.SuggestedRate && estimate.SuggestedProfile != "" {
+				break
+			}
+			estimate.SuggestedProfile = name
+		}
+	}
+	return estimate
+}
+
+// printBandwidth shows the measured throughput and the rate it suggests
+func printBandwidth(estimate *netenv.BandwidthEstimate) {
+	style.Printf("📶 Bandwidth:\n")
+	if estimate.Bytes == 0 {
+		style.Printf("  ❌ %s: %s\n", estimate.Target, estimate.Error)
+		fmt.Println()
+		return
+	}
+	if estimate.Method == netenv.BandwidthTCP {
+		fmt.Printf("  %.1f Mbit/s to %s (%d TCP streams)\n", estimate.Mbps, estimate.Target, estimate.Streams)
+	} else {
+		fmt.Printf("  %.1f Mbit/s to gateway %s (UDP burst, local link only)\n", estimate.Mbps, estimate.Target)
+	}
+	if estimate.Error != "" {
+		style.Printf("  ⚠️  %s\n", estimate.Error)
+	}
+	fmt.Printf("  Suggested rate: %d pps", estimate.SuggestedRate)
+	if estimate.SuggestedProfile != "" {
+		fmt.Printf(" (profile: %s)", estimate.SuggestedProfile)
+	}
+	fmt.Println()
+	fmt.Println()
+}
+
+// checkOpsCompliance runs the compliance check of an ops command, which also
+// records it in the audit log, and exits when the targets are refused. The
+// checker is returned to classify the results.
+func checkOpsCompliance(cmd *cobra.Command, command string, targets []string, budget audit.Budget, clamped []compliance.Clamp) *compliance.ComplianceChecker {
+	dangerousFlag, _ := cmd.Flags().GetBool("dangerous")
+
+	checker := newComplianceChecker(cmd)
+	checker.Budget = &budget
+	checker.Clamped = clamped
+
+	sessionID := fmt.Sprintf("ops-%d", time.Now().Unix())
+	complianceResult, err := checker.CheckCompliance(sessionID, "ops", command, complianceTargets(targets), dangerousFlag)
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ Compliance violation: %v\n", err)
+		os.Exit(exitcode.Blocked)
+	}
+	for _, warning := range complianceResult.Warnings {
+		style.Fprintf(os.Stderr, "⚠️  %s\n", warning)
+	}
+	if scope := complianceResult.Scope; scope != nil {
+		style.Fprintf(os.Stderr, "📜 Engagement scope: %s (authorization: %s)\n", scope.File, scope.Authorization)
+	}
+	return checker
+}
+
+// complianceTargets returns the targets compliance checks: "auto" becomes
+// the network it is detected as, or compliance.AutoDetect when there is none
+func complianceTargets(targets []string) []string {
+	checked := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if target != "auto" {
+			checked = append(checked, target)
+			continue
+		}
+		networks, err := ops.AutoTargets()
+		if err != nil || len(networks) == 0 {
+			networks = []string{compliance.AutoDetect}
+		}
+		checked = append(checked, networks...)
+	}
+	return checked
+}
+
+// failureCode is the exit status of an operation that returned err:
+// Blocked when compliance refused it, Failed otherwise
+func failureCode(err error) int {
+	if errors.Is(err, compliance.ErrBlocked) {
+		return exitcode.Blocked
+	}
+	return exitcode.Failed
+}
+
+// addFailOnFlag adds --fail-on to a command whose runs scan ports
+func addFailOnFlag(cmd *cobra.Command) {
+	cmd.Flags().String("fail-on", "", "Exit with status 5 when an open port has this risk or higher (low, medium, high, critical)")
+}
+
+// failOnThreshold returns the risk given with --fail-on, exiting on an
+// invalid one before anything is sent
+func failOnThreshold(cmd *cobra.Command) string {
+	name, _ := cmd.Flags().GetString("fail-on")
+	if name == "" {
+		return ""
+	}
+	severity, err := risk.ParseSeverity(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --fail-on: %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
+	return severity
+}
+
+// exitForRun exits with the status of a finished run unless it is OK:
+// Policy when an open port has the --fail-on risk or higher, Partial when
+// probes failed or a budget ceiling cut the ports per host
+func exitForRun(failOn string, discover *ops.DiscoverSummary, scan *ops.ScanSummary, clamped []compliance.Clamp) {
+	if failOn != "" && scan != nil {
+		riskEngine := quick.LoadRiskEngine()
+		failed := false
+		for _, result := range scan.Results {
+			if result.Status != "open" {
+				continue
+			}
+			service, version := "unknown", ""
+			if result.Service != nil {
+				service, version = result.Service.Name, result.Service.Version
+			}
+			assessment := riskEngine.Evaluate(risk.Finding{Host: result.Host, Port: result.Port, Service: service, Version: version})
+			if risk.CompareSeverity(assessment.Severity, failOn) >= 0 {
+				style.Fprintf(os.Stderr, "❌ %s:%d (%s) has %s risk, failing on %s\n", result.Host, result.Port, service, assessment.Severity, failOn)
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(exitcode.Policy)
+		}
+	}
+
+	partial := discover != nil && discover.Stats.Errors > 0
+	if scan != nil && scan.Stats.ByStatus["error"] > 0 {
+		style.Fprintf(os.Stderr, "⚠️  %d probes failed, the results are partial\n", scan.Stats.ByStatus["error"])
+		partial = true
+	}
+	for _, clamp := range clamped {
+		if clamp.Option == "ports_per_host" {
+			partial = true
+		}
+	}
+	// A run stopped by the kill switch exits with abort.ExitCode instead
+	if partial && abort.Stopped() == nil {
+		os.Exit(exitcode.Partial)
+	}
+}
+
+// complianceClassifier labels the hosts of results with their compliance
+// class, classifying each host once
+func complianceClassifier(checker *compliance.ComplianceChecker) func(string) *ops.ComplianceLabel {
+	var mu sync.Mutex
+	labels := make(map[string]*ops.ComplianceLabel)
+	return func(host string) *ops.ComplianceLabel {
+		mu.Lock()
+		defer mu.Unlock()
+		if label, ok := labels[host]; ok {
+			return label
+		}
+		class, rule := checker.Classify(host)
+		labels[host] = &ops.ComplianceLabel{Class: class, Rule: rule}
+		return labels[host]
+	}
+}
+
+func runDiscover(cmd *cobra.Command, args []string) {
+	dropRootPrivileges(cmd)
+
+	// Get flags
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	methods, _ := cmd.Flags().GetStringSlice("methods")
+	iface, _ := cmd.Flags().GetString("interface")
+	rate, _ := cmd.Flags().GetInt("rate")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	tcpPorts, _ := cmd.Flags().GetIntSlice("tcp-ports")
+	resolve, _ := cmd.Flags().GetBool("resolve")
+	tableOpts := tableOptions(cmd, discoverTableColumns, output.HostColumns)
+	
+	// Explicit targets go out through the interface the system routes them to
+	if !cmd.Flags().Changed("interface") && len(args) > 0 && args[0] != "auto" {
+		if routed := netenv.RouteInterface(args[0]); routed != "" {
+			iface = routed
+			style.Fprintf(os.Stderr, "📡 Interface: %s (route to %s)\n", iface, args[0])
+		}
+	}
+
+	// Apply rate profile if values not explicitly set
+	network := currentNetworkSettings(iface)
+	project := currentProject()
+	profile, err := applyRateProfile(cmd, network, rateOptions{Rate: &rate, Concurrency: &concurrency, Timeout: &timeout})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
+	clamps := applyComplianceCeilings(rateOptions{Rate: &rate, Concurrency: &concurrency}, &tcpPorts)
+	
+	// Enhanced discovery flags
+	enhanced, _ := cmd.Flags().GetBool("enhanced")
+	targetPruning, _ := cmd.Flags().GetBool("target-pruning")
+	noAdaptiveRate, _ := cmd.Flags().GetBool("no-adaptive-rate")
+	noSampling, _ := cmd.Flags().GetBool("no-sampling")
+	compatA1, _ := cmd.Flags().GetBool("compat-a1")
+	labels := runLabelsFromFlags(cmd)
+	series := newRepeatSeries(cmd, "hosts up", &labels)
+
+	// Get targets from arguments
+	var targets []string
+	if len(args) == 0 {
+		targets = []string{"auto"}
+	} else {
+		targets = args
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		addresses, err := ops.ResolveTargets(targets, append(network.DoNotScan, project.Scope.Exclude...))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.Usage)
+		}
+		probes := 0
+		for _, method := range methods {
+			if method == "tcp" {
+				probes += len(tcpPorts)
+			} else {
+				probes++
+			}
+		}
+		finishDryRun(cmd, dryRunPlan{
+			Command:     "netcrate ops discover",
+			Targets:     targets,
+			Addresses:   len(addresses),
+			Sample:      addresses[:min(len(addresses), dryRunSample)],
+			RateProfile: profile.Name,
+			Rate:        rate,
+			Concurrency: concurrency,
+			Timeout:     timeout,
+			Packets:     len(addresses) * probes,
+			Duration:    pacedDuration(len(addresses), rate, timeout),
+			Clamped:     clamps,
+		})
+	}
+	checker := checkOpsCompliance(cmd, "netcrate ops discover", targets, audit.Budget{
+		Profile:     profile.Name,
+		Rate:        rate,
+		Concurrency: concurrency,
+		Ports:       len(tcpPorts),
+	}, clamps)
+
+	stopHelper := startPrivilegedHelper(cmd)
+	defer stopHelper()
+
+	// Create discover options
+	opts := ops.DiscoverOptions{
+		Targets:          targets,
+		Methods:          methods,
+		Interface:        iface,
+		Rate:            rate,
+		Timeout:         timeout,
+		Concurrency:     concurrency,
+		TCPPorts:        tcpPorts,
+		ResolveHostnames: resolve,
+		Exclude:          append(network.DoNotScan, project.Scope.Exclude...),
+		Scope:            project.Scope.Allowed,
+		Classify:         complianceClassifier(checker),
+	}
+
+	sink := openResultSink(cmd)
+	if sink != nil {
+		opts.OnResult = func(result ops.DiscoverResult) { sink.Write("discover.result", result) }
+	}
+
+	// Check if we should use enhanced discovery
+	useEnhanced := enhanced || targetPruning || (!noAdaptiveRate && !compatA1) || (!noSampling && !compatA1)
+
+	// discoverOnce runs, saves and, when print is set, prints one discovery
+	discoverOnce := func(print bool) *ops.DiscoverSummary {
+		var result *ops.DiscoverSummary
+		var enhancedResult *ops.EnhancedDiscoverSummary
+		var runContext *store.RunContext
+		var err error
+
+		if useEnhanced && !compatA1 {
+			// Use enhanced discovery
+			enhancedOpts := ops.DiscoverEnhancedOptions{
+				DiscoverOptions:      opts,
+				EnableTargetPruning:  targetPruning || enhanced,
+				EnableSampling:       !noSampling && enhanced,
+				EnableMethodFallback: enhanced,
+				EnableAdaptiveRate:   !noAdaptiveRate && enhanced,
+				SamplingPercent:      0.05, // 5% for large networks
+				HighLossThreshold:    0.3,  // 30%
+				DownshiftStep:        0.2,  // 20% reduction
+				UpshiftStep:          0.1,  // 10% increase
+				GoodWindowsToUpshift: 3,
+				NoAdaptiveRate:       noAdaptiveRate,
+				NoSampling:           noSampling,
+				CompatA1:             compatA1,
+			}
+
+			// Run enhanced discovery
+			style.Fprintf(os.Stderr, "🚀 Starting enhanced host discovery (B1)...\n")
+			if enhancedOpts.EnableTargetPruning {
+				style.Fprintf(os.Stderr, "✨ Target prioritization enabled (ARP cache, gateway)\n")
+			}
+			fmt.Fprintf(os.Stderr, "Targets: %s\n", strings.Join(targets, ", "))
+			fmt.Fprintf(os.Stderr, "Methods: %s\n", strings.Join(methods, ", "))
+			fmt.Fprintf(os.Stderr, "Rate: %d pps | Concurrency: %d | Timeout: %v\n", rate, concurrency, timeout)
+			fmt.Fprintf(os.Stderr, "\n")
+
+			runContext = store.NewRunContext(iface, enhancedOpts)
+			enhancedResult, err = ops.EnhancedDiscover(enhancedOpts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error during enhanced discovery: %v\n", err)
+				os.Exit(exitcode.Failed)
+			}
+			result = enhancedResult.DiscoverSummary
+		} else {
+			// Use original discovery
+			style.Fprintf(os.Stderr, "🔍 Starting host discovery...\n")
+			if compatA1 {
+				style.Fprintf(os.Stderr, "🔄 A1 compatibility mode enabled\n")
+			}
+			fmt.Fprintf(os.Stderr, "Targets: %s\n", strings.Join(targets, ", "))
+			fmt.Fprintf(os.Stderr, "Methods: %s\n", strings.Join(methods, ", "))
+			fmt.Fprintf(os.Stderr, "Rate: %d pps | Concurrency: %d | Timeout: %v\n", rate, concurrency, timeout)
+			fmt.Fprintf(os.Stderr, "\n")
+
+			runContext = store.NewRunContext(iface, opts)
+			result, err = ops.Discover(opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error during discovery: %v\n", err)
+				os.Exit(exitcode.Failed)
+			}
+		}
+		runContext.Clamped = clamps
+		runContext.Authorization = runAuthorization(cmd)
+
+		saveOpsRun(cmd, result.RunID, func() error {
+			if enhancedResult != nil {
+				return output.SaveEnhancedDiscoverRun(enhancedResult, targets, labels, runContext)
+			}
+			return output.SaveDiscoverRun(result, targets, labels, runContext)
+		})
+		if sink != nil {
+			summary := *result
+			summary.Results = nil
+			writeResultSummary(sink, "discover.summary", &summary, series == nil)
+		}
+
+		if nmapXML, _ := cmd.Flags().GetString("nmap-xml"); nmapXML != "" {
+			if err := output.SaveNmapXML(nmapXML, output.BuildNmapRun(result, nil)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing nmap XML: %v\n", err)
+				os.Exit(exitcode.Failed)
+			}
+		}
+
+		// Output results
+		if !print {
+			return result
+		}
+		if jsonOutput {
+			var encoded interface{} = result
+			if enhancedResult != nil {
+				encoded = enhancedResult
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(encoded); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+				os.Exit(exitcode.Failed)
+			}
+		} else {
+			if enhancedResult != nil {
+				// Print enhanced summary first
+				printEnhancedDiscoverSummary(enhancedResult)
+			}
+			printDiscoverTable(result, tableOpts)
+		}
+		return result
+	}
+
+	if series != nil {
+		series.Run(func(first bool) repeatSnapshot {
+			return discoverSnapshot(discoverOnce(first && !jsonOutput))
+		})
+		stopResultSink(sink)
+		return
+	}
+	exitForRun("", discoverOnce(true), nil, clamps)
+}
+
+// printDiscoverTable prints the live hosts of a discovery as a table between
+// the run header and its statistics, paging the whole when it is long
+func printDiscoverTable(result *ops.DiscoverSummary, opts output.TableOptions) {
+	var w bytes.Buffer
+	style.Fprintf(&w, "🔍 Host Discovery Results\n")
+	fmt.Fprintf(&w, "Run ID: %s\n", result.RunID)
+	fmt.Fprintf(&w, "Duration: %.1fs\n", result.Duration)
+	fmt.Fprintf(&w, "Targets: %d | Discovered: %d | Success Rate: %.1f%%\n",
+		result.TargetsResolved, result.HostsDiscovered, result.SuccessRate*100)
+	fmt.Fprintf(&w, "Methods Used: %s\n", strings.Join(result.MethodUsed, ", "))
+	for _, fallback := range result.Fallbacks {
+		style.Fprintf(&w, "⚠️  %s\n", fallback)
+	}
+	fmt.Fprintln(&w)
+
+	if len(result.Results) == 0 {
+		fmt.Fprintln(&w, "No hosts discovered.")
+		output.Page(w.Bytes(), !opts.NoPager)
+		return
+	}
+
+	// Show active hosts in the table, only count the others
+	hosts := output.DiscoverTable(result)
+	activeHosts := hosts.Filter(output.RowFilter{Status: "up"})
+	inactiveHosts := len(hosts.Rows) - len(activeHosts.Rows)
+
+	if len(activeHosts.Rows) > 0 {
+		style.Fprintf(&w, "✅ Active Hosts (%d):\n", len(activeHosts.Rows))
+		view, _ := activeHosts.View(opts) // options are checked by tableOptions
+		output.WriteTable(&w, view, opts)
+		fmt.Fprintln(&w)
+	}
+
+	// Print summary statistics
+	style.Fprintf(&w, "📊 Statistics:\n")
+	fmt.Fprintf(&w, "  Total Sent: %d\n", result.Stats.Sent)
+	fmt.Fprintf(&w, "  Responses: %d\n", result.Stats.Received)
+	fmt.Fprintf(&w, "  Timeouts: %d\n", result.Stats.Timeouts)
+	fmt.Fprintf(&w, "  Errors: %d\n", result.Stats.Errors)
+	fmt.Fprintln(&w)
+
+	// Print method breakdown
+	if len(result.Stats.MethodBreakdown) > 0 {
+		style.Fprintf(&w, "🔧 Method Breakdown:\n")
+		for method, stats := range result.Stats.MethodBreakdown {
+			successRate := float64(0)
+			if stats.Sent > 0 {
+				successRate = float64(stats.Received) / float64(stats.Sent) * 100
+			}
+			fmt.Fprintf(&w, "  %s: %d/%d (%.1f%%)\n", method, stats.Received, stats.Sent, successRate)
+		}
+		fmt.Fprintln(&w)
+	}
+
+	// Show inactive hosts summary (don't spam with details)
+	if inactiveHosts > 0 {
+		style.Fprintf(&w, "❌ Inactive Hosts: %d\n", inactiveHosts)
+		fmt.Fprintf(&w, "   Use --json flag to see full details\n")
+	}
+	output.Page(w.Bytes(), !opts.NoPager)
+}
+
+func runPacketSend(cmd *cobra.Command, args []string) {
+	// Get flags
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	targets, _ := cmd.Flags().GetStringSlice("targets")
 	template, _ := cmd.Flags().GetString("template")
 	params, _ := cmd.Flags().GetStringToString("param")
 	count, _ := cmd.Flags().GetInt("count")
@@ -763,6 +2188,18 @@ func runPacketSend(cmd *cobra.Command, args []string) {
 	timeout, _ := cmd.Flags().GetDuration("timeout")
 	followRedirects, _ := cmd.Flags().GetBool("follow-redirects")
 	maxResponseSize, _ := cmd.Flags().GetInt("max-response-size")
+	labels := runLabelsFromFlags(cmd)
+	series := newRepeatSeries(cmd, "targets", &labels)
+
+	// Apply rate profile if values not explicitly set
+	network := currentNetworkSettings("")
+	project := currentProject()
+	profile, err := applyRateProfile(cmd, network, rateOptions{Timeout: &timeout, Interval: &interval})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
+	clamps := applyComplianceCeilings(rateOptions{Interval: &interval}, nil)
 
 	// Get targets from arguments if not provided via flags
 	if len(targets) == 0 && len(args) > 0 {
@@ -772,8 +2209,38 @@ func runPacketSend(cmd *cobra.Command, args []string) {
 	if len(targets) == 0 {
 		fmt.Fprintf(os.Stderr, "Error: No targets specified\n")
 		fmt.Fprintf(os.Stderr, "Use: netcrate ops packet send --targets 192.168.1.1:80 --template http\n")
-		os.Exit(1)
+		os.Exit(exitcode.Usage)
 	}
+	// Compliance checks the hosts packets go to, like the scan commands
+	hosts := packetTargetHosts(targets)
+	checkPacketTargets(hosts, network, project)
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		if _, ok := ops.PacketTemplates[template]; !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown template: %s\n", template)
+			os.Exit(exitcode.Usage)
+		}
+		packets := count * len(targets)
+		finishDryRun(cmd, dryRunPlan{
+			Command:     "netcrate ops packet send",
+			Targets:     hosts,
+			Addresses:   len(targets),
+			Sample:      targets[:min(len(targets), dryRunSample)],
+			Template:    template,
+			RateProfile: profile.Name,
+			Interval:    interval,
+			Timeout:     timeout,
+			Packets:     packets,
+			Duration:    time.Duration(len(targets)*(count-1))*interval + timeout,
+			Clamped:     clamps,
+		})
+	}
+
+	budget := audit.Budget{Profile: profile.Name, Packets: count * len(targets)}
+	if interval > 0 {
+		budget.Rate = int(time.Second / interval)
+	}
+	checkOpsCompliance(cmd, "netcrate ops packet send", hosts, budget, clamps)
 
 	// Convert string params to interface{} map
 	templateParams := make(map[string]interface{})
@@ -794,28 +2261,101 @@ func runPacketSend(cmd *cobra.Command, args []string) {
 	}
 
 	// Run packet sending
-	fmt.Fprintf(os.Stderr, "📦 Sending packets...\n")
+	style.Fprintf(os.Stderr, "📦 Sending packets...\n")
 	fmt.Fprintf(os.Stderr, "Template: %s\n", template)
 	fmt.Fprintf(os.Stderr, "Targets: %s\n", strings.Join(targets, ", "))
 	fmt.Fprintf(os.Stderr, "Count: %d | Interval: %v | Timeout: %v\n", count, interval, timeout)
 	fmt.Fprintf(os.Stderr, "\n")
 
-	result, err := ops.SendPackets(opts)
+	sink := openResultSink(cmd)
+	if sink != nil {
+		opts.OnResult = func(result ops.PacketResult) { sink.Write("packet.result", result) }
+	}
+
+	// The run context records secret:// references, never the secrets
+	runContext := store.NewRunContext("", opts)
+	runContext.Clamped = clamps
+	runContext.Authorization = runAuthorization(cmd)
+	resolved, secrets, err := config.ResolveSecrets(templateParams)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error sending packets: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
 	}
+	opts.TemplateParams, opts.Secrets = resolved, secrets
 
-	// Output results
-	if jsonOutput {
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(result); err != nil {
-			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-			os.Exit(1)
+	// sendOnce sends, saves and, when print is set, prints one round of packets
+	sendOnce := func(print bool) *ops.PacketSummary {
+		result, err := ops.SendPackets(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending packets: %v\n", err)
+			os.Exit(exitcode.Failed)
 		}
-	} else {
-		printPacketTable(result)
+		saveOpsRun(cmd, result.RunID, func() error {
+			return output.SavePacketRun(result, targets, labels, runContext)
+		})
+		if sink != nil {
+			summary := *result
+			summary.Results = nil
+			writeResultSummary(sink, "packet.summary", &summary, series == nil)
+		}
+
+		// Output results
+		if !print {
+			return result
+		}
+		if jsonOutput {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+				os.Exit(exitcode.Failed)
+			}
+		} else {
+			printPacketTable(result)
+		}
+		return result
+	}
+
+	if series != nil {
+		series.Run(func(first bool) repeatSnapshot {
+			return packetSnapshot(sendOnce(first && !jsonOutput))
+		})
+		stopResultSink(sink)
+		return
+	}
+	result := sendOnce(true)
+	if result.SuccessfulResponses < result.TotalPackets && abort.Stopped() == nil {
+		os.Exit(exitcode.Partial)
+	}
+}
+
+// packetTargetHosts returns the hosts of packet targets given as host:port,
+// [v6]:port or URLs
+func packetTargetHosts(targets []string) []string {
+	hosts := make([]string, 0, len(targets))
+	for _, target := range targets {
+		host := target
+		if u, err := url.Parse(target); err == nil && u.Scheme != "" && u.Host != "" {
+			host = u.Hostname()
+		} else if h, _, err := net.SplitHostPort(target); err == nil {
+			host = h
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// checkPacketTargets exits when a packet target host is outside the project
+// scope or excluded by the project or the network do_not_scan list, which
+// discovery and scans skip
+func checkPacketTargets(hosts []string, network config.NetworkSettings, project config.ProjectConfig) {
+	err := ops.CheckScope(hosts, project.Scope.Allowed)
+	if err == nil {
+		err = ops.CheckExclusions(hosts, append(network.DoNotScan, project.Scope.Exclude...))
+	}
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(exitcode.Blocked)
 	}
 }
 
@@ -827,7 +2367,7 @@ func runPacketTemplates(cmd *cobra.Command, args []string) {
 		encoder.SetIndent("", "  ")
 		if err := encoder.Encode(ops.PacketTemplates); err != nil {
 			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitcode.Failed)
 		}
 	} else {
 		printPacketTemplatesTable()
@@ -835,7 +2375,7 @@ func runPacketTemplates(cmd *cobra.Command, args []string) {
 }
 
 func printPacketTable(result *ops.PacketSummary) {
-	fmt.Printf("📦 Packet Send Results\n")
+	style.Printf("📦 Packet Send Results\n")
 	fmt.Printf("Run ID: %s\n", result.RunID)
 	fmt.Printf("Template: %s\n", result.TemplateUsed)
 	fmt.Printf("Targets: %d | Total Packets: %d | Successful: %d | Success Rate: %.1f%%\n",
@@ -856,7 +2396,7 @@ func printPacketTable(result *ops.PacketSummary) {
 
 	// Print results for each target
 	for target, results := range targetResults {
-		fmt.Printf("🎯 Target: %s\n", target)
+		style.Printf("🎯 Target: %s\n", target)
 		fmt.Printf("%-3s %-8s %-8s %-12s %s\n", "Seq", "Status", "RTT", "Method", "Details")
 		fmt.Println(strings.Repeat("-", 50))
 
@@ -881,9 +2421,9 @@ func printPacketTable(result *ops.PacketSummary) {
 
 			status := result.Status
 			if result.Status == "success" {
-				status = "✅"
+				status = style.Text("✅")
 			} else if result.Status == "error" {
-				status = "❌"
+				status = style.Text("❌")
 			}
 
 			fmt.Printf("%-3d %-8s %-8s %-12s %s\n",
@@ -893,7 +2433,7 @@ func printPacketTable(result *ops.PacketSummary) {
 	}
 
 	// Print statistics
-	fmt.Printf("📊 Statistics:\n")
+	style.Printf("📊 Statistics:\n")
 	fmt.Printf("  Average RTT: %.1fms\n", result.Stats.AvgRTT)
 	fmt.Printf("  Min RTT: %.1fms\n", result.Stats.MinRTT)
 	fmt.Printf("  Max RTT: %.1fms\n", result.Stats.MaxRTT)
@@ -902,7 +2442,7 @@ func printPacketTable(result *ops.PacketSummary) {
 
 	// Print status code breakdown for HTTP(S)
 	if len(result.Stats.ByStatusCode) > 0 {
-		fmt.Printf("🔢 HTTP Status Codes:\n")
+		style.Printf("🔢 HTTP Status Codes:\n")
 		for code, count := range result.Stats.ByStatusCode {
 			fmt.Printf("  %s: %d\n", code, count)
 		}
@@ -911,11 +2451,11 @@ func printPacketTable(result *ops.PacketSummary) {
 }
 
 func printPacketTemplatesTable() {
-	fmt.Printf("📦 Available Packet Templates\n")
+	style.Printf("📦 Available Packet Templates\n")
 	fmt.Println()
 
 	for name, template := range ops.PacketTemplates {
-		fmt.Printf("🔹 %s\n", name)
+		style.Printf("🔹 %s\n", name)
 		fmt.Printf("   Description: %s\n", template.Description)
 
 		if len(template.RequiredParams) > 0 {
@@ -927,7 +2467,7 @@ func printPacketTemplatesTable() {
 		}
 
 		if template.RequiresRawSocket {
-			fmt.Printf("   ⚠️  Requires raw socket privileges\n")
+			style.Printf("   ⚠️  Requires raw socket privileges\n")
 		}
 
 		if len(template.DefaultParams) > 0 {
@@ -942,301 +2482,1244 @@ func printPacketTemplatesTable() {
 		fmt.Println()
 	}
 
-	fmt.Printf("Usage examples:\n")
-	fmt.Printf("  netcrate ops packet send --targets 192.168.1.1:80 --template http\n")
-	fmt.Printf("  netcrate ops packet send --targets example.com:443 --template https --param path=/api\n")
-	fmt.Printf("  netcrate ops packet send --targets 192.168.1.1:22 --template connect\n")
-	fmt.Printf("  netcrate ops packet templates --json\n")
+	fmt.Printf("Usage examples:\n")
+	fmt.Printf("  netcrate ops packet send --targets 192.168.1.1:80 --template http\n")
+	fmt.Printf("  netcrate ops packet send --targets example.com:443 --template https --param path=/api\n")
+	fmt.Printf("  netcrate ops packet send --targets 192.168.1.1:22 --template connect\n")
+	fmt.Printf("  netcrate ops packet templates --json\n")
+}
+
+func runScanPorts(cmd *cobra.Command, args []string) {
+	dropRootPrivileges(cmd)
+
+	// Get flags
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	targets, _ := cmd.Flags().GetStringSlice("targets")
+	portsSpec, _ := cmd.Flags().GetString("ports")
+	scanType, _ := cmd.Flags().GetString("scan-type")
+	serviceDetection, _ := cmd.Flags().GetBool("service-detection")
+	rate, _ := cmd.Flags().GetInt("rate")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	retries, _ := cmd.Flags().GetInt("retries")
+	labels := runLabelsFromFlags(cmd)
+	series := newRepeatSeries(cmd, "open ports", &labels)
+	failOn := failOnThreshold(cmd)
+	tableOpts := tableOptions(cmd, scanTableColumns, output.PortColumns)
+	
+	// Get targets from arguments if not provided via flags
+	if len(targets) == 0 && len(args) > 0 {
+		targets = args
+	}
+
+	if len(targets) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: No targets specified\n")
+		fmt.Fprintf(os.Stderr, "Use: netcrate ops scan ports --targets 192.168.1.1,192.168.1.2 --ports top100\n")
+		os.Exit(exitcode.Usage)
+	}
+
+	// Apply rate profile if values not explicitly set, with the overrides of
+	// the network the targets are routed through
+	profile, err := applyRateProfile(cmd, currentNetworkSettings(netenv.RouteInterface(targets[0])), rateOptions{Rate: &rate, Concurrency: &concurrency, Timeout: &timeout, Retries: &retries})
+	project := currentProject()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
+
+	// Parse port specification
+	ports, err := ops.ParsePortSpec(portsSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing ports '%s': %v\n", portsSpec, err)
+		os.Exit(exitcode.Usage)
+	}
+	clamps := applyComplianceCeilings(rateOptions{Rate: &rate, Concurrency: &concurrency}, &ports)
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		addresses, err := ops.ResolveTargets(targets, project.Scope.Exclude)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.Usage)
+		}
+		probes := len(addresses) * len(ports)
+		finishDryRun(cmd, dryRunPlan{
+			Command:     "netcrate ops scan ports",
+			Targets:     targets,
+			Addresses:   len(addresses),
+			Sample:      addresses[:min(len(addresses), dryRunSample)],
+			Ports:       ports,
+			RateProfile: profile.Name,
+			Rate:        rate,
+			Concurrency: concurrency,
+			Timeout:     timeout,
+			Packets:     probes,
+			Duration:    pacedDuration(probes, rate, timeout),
+			Clamped:     clamps,
+		})
+	}
+	checker := checkOpsCompliance(cmd, "netcrate ops scan ports", targets, audit.Budget{
+		Profile:     profile.Name,
+		Rate:        rate,
+		Concurrency: concurrency,
+		Ports:       len(ports),
+	}, clamps)
+
+	// Create scan options
+	opts := ops.ScanOptions{
+		Targets:          targets,
+		Ports:            ports,
+		ScanType:         scanType,
+		ServiceDetection: serviceDetection,
+		Rate:             rate,
+		Timeout:          timeout,
+		Concurrency:      concurrency,
+		RetryCount:       retries,
+		MaxPerHost:       profile.MaxPerHost,
+		Exclude:          project.Scope.Exclude,
+		Scope:            project.Scope.Allowed,
+		Classify:         complianceClassifier(checker),
+	}
+
+	// Run port scanning
+	style.Fprintf(os.Stderr, "🔌 Starting port scan...\n")
+	fmt.Fprintf(os.Stderr, "Targets: %s\n", strings.Join(targets, ", "))
+	fmt.Fprintf(os.Stderr, "Ports: %s (%d ports)\n", portsSpec, len(ports))
+	fmt.Fprintf(os.Stderr, "Type: %s | Rate: %d pps | Concurrency: %d | Timeout: %v\n", 
+		scanType, rate, concurrency, timeout)
+	fmt.Fprintf(os.Stderr, "\n")
+
+	sink := openResultSink(cmd)
+	if sink != nil {
+		opts.OnResult = func(result ops.ScanResult) { sink.Write("scan.result", result) }
+	}
+
+	// scanOnce runs, saves and, when print is set, prints one scan
+	scanOnce := func(print bool) *ops.ScanSummary {
+		runContext := store.NewRunContext("", opts)
+		runContext.Clamped = clamps
+		runContext.Authorization = runAuthorization(cmd)
+		result, err := ops.ScanPorts(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error during port scan: %v\n", err)
+			os.Exit(exitcode.Failed)
+		}
+		saveOpsRun(cmd, result.RunID, func() error {
+			return output.SaveScanRun(result, targets, labels, runContext)
+		})
+		if sink != nil {
+			summary := *result
+			summary.Results = nil
+			writeResultSummary(sink, "scan.summary", &summary, series == nil)
+		}
+
+		if nmapXML, _ := cmd.Flags().GetString("nmap-xml"); nmapXML != "" {
+			if err := output.SaveNmapXML(nmapXML, output.BuildNmapRun(nil, result)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing nmap XML: %v\n", err)
+				os.Exit(exitcode.Failed)
+			}
+		}
+
+		// Output results
+		if !print {
+			return result
+		}
+		if jsonOutput {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+				os.Exit(exitcode.Failed)
+			}
+		} else {
+			printScanTable(result, tableOpts)
+		}
+		return result
+	}
+
+	if series != nil {
+		series.Run(func(first bool) repeatSnapshot {
+			return scanSnapshot(scanOnce(first && !jsonOutput))
+		})
+		stopResultSink(sink)
+		return
+	}
+	result := scanOnce(true)
+	if !jsonOutput {
+		offerActionMenu(cmd, finishedScan{RunID: result.RunID, Scan: result})
+	}
+	exitForRun(failOn, nil, result, clamps)
+}
+
+// printScanTable prints the open ports of a scan as a table between the run
+// header and its statistics, paging the whole when it is long
+func printScanTable(result *ops.ScanSummary, opts output.TableOptions) {
+	var w bytes.Buffer
+	style.Fprintf(&w, "🔌 Port Scan Results\n")
+	fmt.Fprintf(&w, "Run ID: %s\n", result.RunID)
+	fmt.Fprintf(&w, "Duration: %.1fs\n", result.Duration)
+	fmt.Fprintf(&w, "Targets: %d | Combinations: %d | Open Ports: %d | Success Rate: %.1f%%\n",
+		result.TargetsCount, result.TotalCombinations, result.OpenPorts,
+		result.Stats.SuccessRate*100)
+	fmt.Fprintf(&w, "Scan Type: %s\n", result.ScanTypeUsed)
+	for _, fallback := range result.Fallbacks {
+		style.Fprintf(&w, "⚠️  %s\n", fallback)
+	}
+	fmt.Fprintln(&w)
+
+	if len(result.Results) == 0 {
+		fmt.Fprintln(&w, "No results.")
+		output.Page(w.Bytes(), !opts.NoPager)
+		return
+	}
+
+	// Show open ports in the table, only count the others
+	ports := output.ScanTable(result)
+	openPorts := ports.Filter(output.RowFilter{Status: "open"})
+	otherPorts := len(ports.Rows) - len(openPorts.Rows)
+
+	if len(openPorts.Rows) > 0 {
+		style.Fprintf(&w, "✅ Open Ports (%d):\n", len(openPorts.Rows))
+		view, _ := openPorts.View(opts) // options are checked by tableOptions
+		output.WriteTable(&w, view, opts)
+		fmt.Fprintln(&w)
+	}
+
+	// Print summary statistics
+	style.Fprintf(&w, "📊 Statistics:\n")
+	fmt.Fprintf(&w, "  Hosts Scanned: %d\n", result.Stats.HostsScanned)
+	fmt.Fprintf(&w, "  Ports Scanned: %d\n", result.Stats.PortsScanned)
+	fmt.Fprintf(&w, "  Average RTT: %.1fms\n", result.Stats.AvgRTT)
+	fmt.Fprintf(&w, "  Scan Rate: %.1f pps\n", result.Stats.ScanRate)
+	fmt.Fprintln(&w)
+
+	// Print port status breakdown
+	style.Fprintf(&w, "🔧 Port Status:\n")
+	fmt.Fprintf(&w, "  Open: %d\n", result.Stats.ByStatus["open"])
+	fmt.Fprintf(&w, "  Closed: %d\n", result.Stats.ByStatus["closed"])
+	fmt.Fprintf(&w, "  Filtered: %d\n", result.Stats.ByStatus["filtered"])
+	if result.Stats.ByStatus["error"] > 0 {
+		fmt.Fprintf(&w, "  Errors: %d\n", result.Stats.ByStatus["error"])
+	}
+	fmt.Fprintln(&w)
+
+	// Print service breakdown
+	if len(result.Stats.ByService) > 0 {
+		style.Fprintf(&w, "🔍 Services Detected:\n")
+		for service, count := range result.Stats.ByService {
+			if service != "unknown" || count > 0 {
+				fmt.Fprintf(&w, "  %s: %d\n", service, count)
+			}
+		}
+		fmt.Fprintln(&w)
+	}
+
+	// Show summary for non-open ports
+	if otherPorts > 0 {
+		style.Fprintf(&w, "❌ Non-Open Ports: %d\n", otherPorts)
+		fmt.Fprintf(&w, "   Use --json flag to see full details\n")
+	}
+	output.Page(w.Bytes(), !opts.NoPager)
+}
+
+// addRunLabelFlags adds the --name, --tag and --note flags of commands that save runs
+func addRunLabelFlags(cmd *cobra.Command) {
+	cmd.Flags().String("name", "", "Name saved with the run (e.g. weekly-sweep)")
+	cmd.Flags().StringSlice("tag", []string{}, "Tags saved with the run (repeatable, e.g. --tag office)")
+	cmd.Flags().String("note", "", "Free-text note saved with the run")
+}
+
+// addSaveFlag adds the --save flag of ops commands, whose runs are saved unless
+// preferences.auto_save is off
+func addSaveFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("save", true, "Save the run to the results directory (default from preferences.auto_save)")
+}
+
+// addTransportFlags adds the per-command proxy and DNS overrides, applied by
+// applyCommandDefaults
+func addTransportFlags(cmd *cobra.Command) {
+	cmd.Flags().String("proxy", "", "Proxy for packet templates and fingerprinting, or \"direct\" (default from proxy.url)")
+	cmd.Flags().StringSlice("dns", []string{}, "DNS servers: IP, tls://host or https://url, or \"system\" (default from dns.resolvers)")
+}
+
+// guardTraffic registers commands that send traffic, those with
+// --authorized-by, with the kill switch: they are refused while it is
+// engaged and stop as soon as it is
+func guardTraffic(cmd *cobra.Command) {
+	if cmd.Flags().Lookup("authorized-by") == nil {
+		return
+	}
+	guardCommand(cmd)
+}
+
+// guardCommand registers a command with the kill switch until it finishes,
+// and exits when the switch is engaged
+func guardCommand(cmd *cobra.Command) {
+	release, err := abort.Guard(cmd.CommandPath())
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(exitcode.Blocked)
+	}
+	cobra.OnFinalize(release)
+}
+
+// addAuthorizationFlags adds the --authorized-by and --ticket flags of commands
+// that send traffic
+func addAuthorizationFlags(cmd *cobra.Command) {
+	cmd.Flags().String("authorized-by", "", "Who authorized this run, recorded in the compliance and audit logs and the run (default from compliance.authorized_by)")
+	cmd.Flags().String("ticket", "", "Ticket or change number authorizing this run (default from compliance.ticket)")
+}
+
+// runAuthorization returns who authorized a run from --authorized-by and
+// --ticket, falling back to the config, or nil when nothing is recorded
+func runAuthorization(cmd *cobra.Command) *compliance.RunAuthorization {
+	var authorization compliance.RunAuthorization
+	if cm, err := config.NewConfigManager(); err == nil {
+		authorization.AuthorizedBy = cm.GetConfig().Compliance.AuthorizedBy
+		authorization.Ticket = cm.GetConfig().Compliance.Ticket
+	}
+	if authorizedBy, _ := cmd.Flags().GetString("authorized-by"); authorizedBy != "" {
+		authorization.AuthorizedBy = authorizedBy
+	}
+	if ticket, _ := cmd.Flags().GetString("ticket"); ticket != "" {
+		authorization.Ticket = ticket
+	}
+	if authorization.IsZero() {
+		return nil
+	}
+	return &authorization
+}
+
+// policyTokenEnv supplies --policy-token, keeping the token out of shell history
+const policyTokenEnv = "NETCRATE_POLICY_TOKEN"
+
+// newComplianceChecker creates the compliance checker of a command with the
+// policy level of --policy, and exits when it cannot
+func newComplianceChecker(cmd *cobra.Command) *compliance.ComplianceChecker {
+	checker, err := compliance.NewComplianceChecker()
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ Compliance checker initialization failed: %v\n", err)
+		os.Exit(exitcode.Failed)
+	}
+	level, _ := cmd.Flags().GetString("policy")
+	origin := ""
+	if level == "" && checker.ProfileLevel() != "" {
+		// A config profile leaves the strict level only with the token, as --policy does
+		level = checker.ProfileLevel()
+		origin = " of the config profile"
+	}
+	if level != "" {
+		token, _ := cmd.Flags().GetString("policy-token")
+		if token == "" {
+			token = os.Getenv(policyTokenEnv)
+		}
+		if err := checker.OverrideLevel(level, token); err != nil {
+			style.Fprintf(os.Stderr, "❌ Compliance policy%s: %v\n", origin, err)
+			os.Exit(exitcode.Blocked)
+		}
+		fmt.Fprintf(os.Stderr, "Compliance policy for this run: %s\n", checker.Level())
+	}
+	if outside, _ := cmd.Flags().GetBool("outside-window"); outside {
+		checker.AllowOutsideWindow()
+	}
+	if authorization := runAuthorization(cmd); authorization != nil {
+		checker.Authorization = *authorization
+	}
+	return checker
+}
+
+// runLabelsFromFlags reads the flags added by addRunLabelFlags
+func runLabelsFromFlags(cmd *cobra.Command) store.Labels {
+	name, _ := cmd.Flags().GetString("name")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	note, _ := cmd.Flags().GetString("note")
+	labels := store.Labels{Name: name, Tags: tags, Notes: note}
+	// Every run of an engagement carries its project tags
+	if project, err := config.LoadProject(); err == nil && project != nil {
+		for _, tag := range project.Tags {
+			if !labels.HasTag(tag) {
+				labels.Tags = append(labels.Tags, tag)
+			}
+		}
+	}
+	return labels
+}
+
+// addResultSinkFlags adds the flags that stream results to a file as they happen
+func addResultSinkFlags(cmd *cobra.Command) {
+	cmd.Flags().String("output-file", "", "Append each result to this file as it happens")
+	cmd.Flags().String("output-format", "jsonl", "Format of --output-file (jsonl)")
+}
+
+// openResultSink opens the sinks that receive results as they happen: the
+// file requested with --output-file and the configured HTTP sink. It returns
+// nil when neither is in use.
+func openResultSink(cmd *cobra.Command) output.ResultSink {
+	var fileSink, httpSink, syslogSink output.ResultSink
+
+	if path, _ := cmd.Flags().GetString("output-file"); path != "" {
+		format, _ := cmd.Flags().GetString("output-format")
+		if format != "jsonl" {
+			fmt.Fprintf(os.Stderr, "Error: unsupported output format '%s' (supported: jsonl)\n", format)
+			os.Exit(exitcode.Usage)
+		}
+
+		sink, err := output.OpenJSONLSink(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.Failed)
+		}
+		fileSink = sink
+	}
+
+	if cm, err := config.NewConfigManager(); err == nil {
+		cfg := cm.GetConfig()
+		if cfg.Sink.URL != "" {
+			httpSink = output.NewHTTPSink(cfg.Sink)
+		}
+		if cfg.Syslog.Address != "" {
+			// An unreachable collector only warns, like a failed delivery
+			sink, err := output.DialSyslogSink(cfg.Syslog)
+			if err != nil {
+				style.Fprintf(os.Stderr, "⚠️  %v\n", err)
+			} else {
+				syslogSink = sink
+			}
+		}
+	}
+
+	return output.NewMultiSink(fileSink, httpSink, syslogSink)
+}
+
+// closeResultSink appends the final summary and closes the sink.
+// A failed write only warns; the results are still saved and printed.
+func closeResultSink(sink output.ResultSink, eventType string, summary interface{}) {
+	sink.Write(eventType, summary)
+	if err := sink.Close(); err != nil {
+		style.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+}
+
+// writeResultSummary sends the summary of a run to the sink, closing it after
+// the last run of the command
+func writeResultSummary(sink output.ResultSink, eventType string, summary interface{}, last bool) {
+	if last {
+		closeResultSink(sink, eventType, summary)
+		return
+	}
+	sink.Write(eventType, summary)
+}
+
+// stopResultSink closes a sink left open by writeResultSummary
+func stopResultSink(sink output.ResultSink) {
+	if sink == nil {
+		return
+	}
+	if err := sink.Close(); err != nil {
+		style.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+}
+
+// saveOpsRun stores an ops result in the results directory so output list/show/export
+// can find it, unless --save=false or preferences.auto_save turn saving off.
+// A failed save only warns; the results are still printed.
+func saveOpsRun(cmd *cobra.Command, runID string, save func() error) {
+	if keep, _ := cmd.Flags().GetBool("save"); !keep {
+		return
+	}
+	if err := save(); err != nil {
+		style.Fprintf(os.Stderr, "⚠️  Failed to save run %s: %v\n", runID, err)
+		return
+	}
+	style.Fprintf(os.Stderr, "💾 Saved run %s\n", runID)
+}
+
+// Helper function for string truncation
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "..."
+}
+
+// runOutputShow handles the output show command
+func runOutputShow(cmd *cobra.Command, args []string) {
+	showLast, _ := cmd.Flags().GetBool("last")
+	runID, _ := cmd.Flags().GetString("run")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	var runInfo *output.RunInfo
+	var err error
+
+	if showLast {
+		runInfo, err = output.GetLastRun()
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.last_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+	} else if runID != "" {
+		runInfo, err = output.GetRunByID(runID)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.run_not_found", runID, err))
+			os.Exit(exitcode.Usage)
+		}
+	} else {
+		// Show latest by default
+		runInfo, err = output.GetLastRun()
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.no_runs"))
+			fmt.Print(i18n.T("engine.output.first_run_hint"))
+			os.Exit(exitcode.Failed)
+		}
+	}
+
+	record, err := output.LoadRecord(runInfo)
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.load_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+
+	only, _ := cmd.Flags().GetString("only")
+	hosts, _ := cmd.Flags().GetStringSlice("host")
+	ports, _ := cmd.Flags().GetIntSlice("port")
+	services, _ := cmd.Flags().GetStringSlice("service")
+	fields, _ := cmd.Flags().GetStringSlice("fields")
+	complianceClass, _ := cmd.Flags().GetString("compliance")
+	filter := output.RowFilter{Status: only, Hosts: hosts, Ports: ports, Services: services, Compliance: complianceClass}
+
+	if !filter.IsZero() || len(fields) > 0 {
+		table, err := filteredRunTable(record, filter, fields)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.show_failed", err))
+			os.Exit(exitcode.Usage)
+		}
+		if jsonOutput {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.Encode(table)
+		} else if err := output.PrintTable(table, tableOptions(cmd, nil, table.Columns)); err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.show_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+		return
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(record.Result)
+	} else {
+		err = printRunRecord(cmd, runInfo, record)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.show_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+		output.PrintRunContext(record)
+		
+		// Show compliance summary
+		checker, err := compliance.NewComplianceChecker()
+		if err == nil {
+			if summary, err := checker.GetComplianceSummary(); err == nil && summary.TotalChecks > 0 {
+				style.Printf("\n📋 Compliance Summary:\n")
+				fmt.Printf("======================\n")
+				fmt.Printf("Total checks: %d\n", summary.TotalChecks)
+				fmt.Printf("Allowed scans: %d\n", summary.AllowedScans)
+				fmt.Printf("Blocked scans: %d\n", summary.BlockedScans)
+				fmt.Printf("Public targets: %d\n", summary.PublicTargets)
+				fmt.Printf("Private targets: %d\n", summary.PrivateTargets)
+				if summary.LastCheck != "" {
+					fmt.Printf("Last check: %s\n", summary.LastCheck)
+				}
+			}
+		}
+	}
+}
+
+// filteredRunTable picks the host or port table of a run, then applies the filter and field selection
+func filteredRunTable(record *store.RunRecord, filter output.RowFilter, fields []string) (*output.Table, error) {
+	hostRows := record.Type == store.TypeDiscover || filter.Status == "up" || filter.Status == "down"
+	if hostRows && (len(filter.Ports) > 0 || len(filter.Services) > 0) {
+		return nil, fmt.Errorf("--port and --service apply to port results, not host results")
+	}
+
+	var table *output.Table
+	var err error
+	if hostRows {
+		table, err = output.HostTable(record)
+	} else {
+		table, err = output.PortTable(record)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	table = table.Filter(filter)
+	if len(fields) > 0 {
+		return table.Select(fields)
+	}
+	return table, nil
+}
+
+// printRunRecord prints a saved run with the table of the command that
+// produced it, laid out by the table flags of cmd
+func printRunRecord(cmd *cobra.Command, runInfo *output.RunInfo, record *store.RunRecord) error {
+	switch record.Type {
+	case store.TypeDiscover:
+		var result ops.DiscoverSummary
+		if err := record.Decode(&result); err != nil {
+			return err
+		}
+		printDiscoverTable(&result, tableOptions(cmd, discoverTableColumns, output.HostColumns))
+	case store.TypeScan:
+		var result ops.ScanSummary
+		if err := record.Decode(&result); err != nil {
+			return err
+		}
+		printScanTable(&result, tableOptions(cmd, scanTableColumns, output.PortColumns))
+	case store.TypePacket:
+		var result ops.PacketSummary
+		if err := record.Decode(&result); err != nil {
+			return err
+		}
+		printPacketTable(&result)
+	default:
+		return output.PrintRunDetails(runInfo)
+	}
+	return nil
+}
+
+// printEnhancedDiscoverSummary prints summary of enhanced discovery features
+func printEnhancedDiscoverSummary(result *ops.EnhancedDiscoverSummary) {
+	style.Fprintf(os.Stderr, "📈 Enhanced Discovery Summary (B1)\n")
+	fmt.Fprintf(os.Stderr, "=====================================\n")
+	
+	// Target prioritization info
+	if result.TargetsPrioritized > 0 {
+		style.Fprintf(os.Stderr, "🎯 Target prioritization: %d targets processed\n", result.TargetsPrioritized)
+		if len(result.TargetPriorityStats) > 0 {
+			high := result.TargetPriorityStats[ops.PriorityHigh]
+			medium := result.TargetPriorityStats[ops.PriorityMedium]
+			low := result.TargetPriorityStats[ops.PriorityLow]
+			fmt.Fprintf(os.Stderr, "   Priority distribution: High=%d, Medium=%d, Low=%d\n", high, medium, low)
+		}
+	}
+	
+	// Sampling info
+	if result.SamplingUsed {
+		style.Fprintf(os.Stderr, "📊 Sampling: %.1f%% of targets, estimated density: %.2f\n", 
+			result.SamplingPercent*100, result.DensityEstimate)
+	}
+	
+	// Method fallback info
+	if result.MethodFallbackUsed {
+		style.Fprintf(os.Stderr, "🔄 Method fallback: %s → %s\n", 
+			strings.Join(result.OriginalMethods, ","), strings.Join(result.ActualMethods, ","))
+	}
+	
+	// Adaptive rate info
+	if result.AdaptiveRateUsed && len(result.RateAdjustments) > 0 {
+		style.Fprintf(os.Stderr, "⚡ Rate adjustments: %d changes\n", len(result.RateAdjustments))
+		for _, adj := range result.RateAdjustments {
+			fmt.Fprintf(os.Stderr, "   %s: %dpps → %dpps (%s)\n", 
+				adj.Timestamp.Format("15:04:05"), adj.OldRate, adj.NewRate, adj.Reason)
+		}
+	}
+	
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+// runOutputExport handles the output export command
+func runOutputExport(cmd *cobra.Command, args []string) {
+	runID, _ := cmd.Flags().GetString("run")
+	format, _ := cmd.Flags().GetString("format")
+	outputPath, _ := cmd.Flags().GetString("output")
+
+	var runInfo *output.RunInfo
+	var err error
+	if runID != "" {
+		runInfo, err = output.GetRunByID(runID)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.run_not_found", runID, err))
+			os.Exit(exitcode.Usage)
+		}
+	} else {
+		runInfo, err = output.GetLastRun()
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.last_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+	}
+
+	record, err := output.LoadRecord(runInfo)
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.load_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+
+	if profileName, _ := cmd.Flags().GetString("redact"); profileName != "" {
+		record, err = redactRecord(record, profileName)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.export_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+	}
+
+	switch format {
+	case "nmap-xml":
+		nmapRun, convErr := output.NmapRunFromRecord(record)
+		if convErr != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.export_failed", convErr))
+			os.Exit(exitcode.Failed)
+		}
+		err = output.SaveNmapXML(outputPath, nmapRun)
+	case "csv":
+		tableName, _ := cmd.Flags().GetString("table")
+		table, tableErr := output.RunTable(record, tableName)
+		if tableErr != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.export_failed", tableErr))
+			os.Exit(exitcode.Usage)
+		}
+		writer := os.Stdout
+		if outputPath != "-" {
+			file, createErr := os.Create(outputPath)
+			if createErr != nil {
+				fmt.Fprint(os.Stderr, i18n.T("engine.output.export_failed", createErr))
+				os.Exit(exitcode.Failed)
+			}
+			defer file.Close()
+			writer = file
+		}
+		err = output.WriteCSV(writer, table)
+	case "syslog", "cef":
+		writer := os.Stdout
+		if outputPath != "-" {
+			file, createErr := os.Create(outputPath)
+			if createErr != nil {
+				fmt.Fprint(os.Stderr, i18n.T("engine.output.export_failed", createErr))
+				os.Exit(exitcode.Failed)
+			}
+			defer file.Close()
+			writer = file
+		}
+		syslogFormat := format
+		if syslogFormat == "syslog" {
+			syslogFormat = "rfc5424"
+		}
+		err = output.WriteSyslogExport(writer, record, syslogFormat)
+	case "json":
+		writer := os.Stdout
+		if outputPath != "-" {
+			file, createErr := os.Create(outputPath)
+			if createErr != nil {
+				fmt.Fprint(os.Stderr, i18n.T("engine.output.export_failed", createErr))
+				os.Exit(exitcode.Failed)
+			}
+			defer file.Close()
+			writer = file
+		}
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(record)
+	default:
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.unknown_format", format))
+		os.Exit(exitcode.Usage)
+	}
+
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.export_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+
+	if outputPath != "-" {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.exported", runInfo.RunID, outputPath))
+	}
+}
+
+// reportLanguage picks the report language: --lang, then the report_language
+// setting, then the language the CLI speaks
+func reportLanguage(cmd *cobra.Command) string {
+	if lang, _ := cmd.Flags().GetString("lang"); lang != "" {
+		return lang
+	}
+	if cm, err := config.NewConfigManager(); err == nil {
+		if lang := cm.GetConfig().Preferences.ReportLanguage; lang != "" {
+			return lang
+		}
+	}
+	return i18n.Language()
+}
+
+// runOutputReport handles the output report command
+func runOutputReport(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	theme, _ := cmd.Flags().GetString("theme")
+	title, _ := cmd.Flags().GetString("title")
+	outputPath, _ := cmd.Flags().GetString("output")
+	history, _ := cmd.Flags().GetInt("history")
+
+	// The reports section of the config supplies what the flags leave out
+	reportDir := ""
+	if cm, err := config.NewConfigManager(); err == nil {
+		defaults := cm.GetConfig().Reports
+		if defaults.Format != "" && !cmd.Flags().Changed("format") {
+			format = defaults.Format
+		}
+		if defaults.Theme != "" && !cmd.Flags().Changed("theme") {
+			theme = defaults.Theme
+		}
+		if defaults.History > 0 && !cmd.Flags().Changed("history") {
+			history = defaults.History
+		}
+		reportDir = defaults.EffectiveDirectory()
+	}
+
+	if format != "html" && format != "md" {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.unknown_report_format", format, strings.Join(reports.ReportFormats(), ", ")))
+		os.Exit(exitcode.Usage)
+	}
+
+	if printTemplate, _ := cmd.Flags().GetBool("print-template"); printTemplate {
+		fmt.Print(reports.BuiltinTemplate())
+		return
+	}
+
+	// Resolve the template first so a typo fails before any work is done
+	templateFile := ""
+	policyPath, _ := cmd.Flags().GetString("policy")
+	if templateName, _ := cmd.Flags().GetString("template"); templateName != "" {
+		var templatePolicy string
+		var err error
+		templateFile, templatePolicy, err = reports.FindReportTemplate(templateName)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.report_failed", err))
+			os.Exit(exitcode.Usage)
+		}
+		if policyPath == "" {
+			policyPath = templatePolicy
+		}
+	}
+
+	var runInfo *output.RunInfo
+	var err error
+	if len(args) == 1 {
+		runInfo, err = output.GetRunByID(args[0])
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.run_not_found", args[0], err))
+			os.Exit(exitcode.Usage)
+		}
+	} else {
+		runInfo, err = output.GetLastRun()
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.last_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+	}
+
+	record, err := output.LoadRecord(runInfo)
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.load_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+	records := []*store.RunRecord{record}
+
+	baseRef, _ := cmd.Flags().GetString("diff")
+	if baseRef != "" {
+		baseInfo, err := output.GetRunByID(baseRef)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.run_not_found", baseRef, err))
+			os.Exit(exitcode.Usage)
+		}
+		base, err := output.LoadRecord(baseInfo)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.load_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+		records = append(records, base)
+	}
+
+	profileName, _ := cmd.Flags().GetString("redact")
+	redacted := profileName != ""
+	if redacted {
+		// Both runs are masked together so they can still be compared
+		records, err = redactRecords(records, profileName)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.report_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+		record = records[0]
+	}
+
+	var result *reports.ExecutionResult
+	if baseRef != "" {
+		result, err = output.DiffReportResult(records[1], record)
+	} else {
+		result, err = output.ReportResult(record)
+	}
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.report_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+
+	// Earlier runs are not masked, so redacted reports go without history
+	if !redacted {
+		if err := output.AddHostHistory(result, record, history); err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.report_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+	}
+
+	lang := reportLanguage(cmd)
+	if title == "" {
+		title = reports.Text(lang, "title.run", record.RunID)
+		if record.Name != "" {
+			title = reports.Text(lang, "title.named", record.Name)
+		}
+		if baseRef != "" {
+			title = reports.Text(lang, "title.diff", records[1].RunID, record.RunID)
+		}
+	}
+	policy, err := reports.LoadSummaryPolicy(policyPath)
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.report_failed", err))
+		os.Exit(exitcode.Usage)
+	}
+	render := func(w io.Writer) error {
+		return reports.RenderMarkdown(w, result, title, policy, lang)
+	}
+	if format == "html" {
+		reporter, err := reports.NewHTMLReporter(reports.HTMLReportConfig{
+			Title:      title,
+			Theme:      theme,
+			Standalone:   true,
+			Policy:       &policy,
+			TemplateFile: templateFile,
+			Language:     lang,
+		})
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.report_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+		render = func(w io.Writer) error {
+			return reporter.Render(w, result)
+		}
+	}
+
+	reportName := record.RunID + "." + format
+	if baseRef != "" {
+		reportName = record.RunID + "-diff." + format
+	}
+
+	bundlePath, _ := cmd.Flags().GetString("bundle")
+	if bundlePath != "" {
+		var report bytes.Buffer
+		if err := render(&report); err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.report_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+		manifest, err := output.WriteBundle(bundlePath, reportName, report.Bytes(), records, redacted)
+		if err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.report_failed", err))
+			os.Exit(exitcode.Failed)
+		}
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.bundle_written", record.RunID, bundlePath, len(manifest.Files)))
+
+		// The report is in the bundle; write it separately only when asked to
+		if outputPath == "" {
+			return
+		}
+	}
+
+	if outputPath == "" {
+		outputPath = filepath.Join(reportDir, reportName)
+	}
+	if outputPath == "-" {
+		err = render(os.Stdout)
+	} else {
+		err = writeReportFile(outputPath, render)
+	}
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.report_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+
+	if outputPath != "-" {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.report_written", record.RunID, outputPath))
+	}
 }
 
-func runScanPorts(cmd *cobra.Command, args []string) {
-	// Get flags
-	jsonOutput, _ := cmd.Flags().GetBool("json")
-	targets, _ := cmd.Flags().GetStringSlice("targets")
-	portsSpec, _ := cmd.Flags().GetString("ports")
-	scanType, _ := cmd.Flags().GetString("scan-type")
-	serviceDetection, _ := cmd.Flags().GetBool("service-detection")
-	rate, _ := cmd.Flags().GetInt("rate")
-	timeout, _ := cmd.Flags().GetDuration("timeout")
-	concurrency, _ := cmd.Flags().GetInt("concurrency")
-	retries, _ := cmd.Flags().GetInt("retries")
-	
-	// Apply rate profile if values not explicitly set
-	applyRateProfile(&rate, &concurrency, &timeout)
-
-	// Get targets from arguments if not provided via flags
-	if len(targets) == 0 && len(args) > 0 {
-		targets = args
+// writeReportFile renders a report into a file, creating its directory
+func writeReportFile(path string, render func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
 	}
-
-	if len(targets) == 0 {
-		fmt.Fprintf(os.Stderr, "Error: No targets specified\n")
-		fmt.Fprintf(os.Stderr, "Use: netcrate ops scan ports --targets 192.168.1.1,192.168.1.2 --ports top100\n")
-		os.Exit(1)
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := render(file); err != nil {
+		file.Close()
+		return err
 	}
+	return file.Close()
+}
 
-	// Parse port specification
-	ports, err := ops.ParsePortSpec(portsSpec)
+// redactRecord masks a record with the named redaction profile
+func redactRecord(record *store.RunRecord, profileName string) (*store.RunRecord, error) {
+	records, err := redactRecords([]*store.RunRecord{record}, profileName)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing ports '%s': %v\n", portsSpec, err)
-		os.Exit(1)
+		return nil, err
 	}
+	return records[0], nil
+}
 
-	// Create scan options
-	opts := ops.ScanOptions{
-		Targets:          targets,
-		Ports:            ports,
-		ScanType:         scanType,
-		ServiceDetection: serviceDetection,
-		Rate:             rate,
-		Timeout:          timeout,
-		Concurrency:      concurrency,
-		RetryCount:       retries,
+// redactRecords masks records together with the named redaction profile
+func redactRecords(records []*store.RunRecord, profileName string) ([]*store.RunRecord, error) {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return nil, err
+	}
+	profile, err := cm.GetRedactionProfile(profileName)
+	if err != nil {
+		return nil, err
 	}
+	return output.RedactRecords(records, profile)
+}
 
-	// Run port scanning
-	fmt.Fprintf(os.Stderr, "🔌 Starting port scan...\n")
-	fmt.Fprintf(os.Stderr, "Targets: %s\n", strings.Join(targets, ", "))
-	fmt.Fprintf(os.Stderr, "Ports: %s (%d ports)\n", portsSpec, len(ports))
-	fmt.Fprintf(os.Stderr, "Type: %s | Rate: %d pps | Concurrency: %d | Timeout: %v\n", 
-		scanType, rate, concurrency, timeout)
-	fmt.Fprintf(os.Stderr, "\n")
+// runOutputHosts handles the output hosts command
+func runOutputHosts(cmd *cobra.Command, args []string) {
+	host, _ := cmd.Flags().GetString("host")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
 
-	result, err := ops.ScanPorts(opts)
+	profiles, err := output.BuildHostInventory(host)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error during port scan: %v\n", err)
-		os.Exit(1)
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.hosts_failed", err))
+		os.Exit(exitcode.Failed)
 	}
 
-	// Output results
 	if jsonOutput {
 		encoder := json.NewEncoder(os.Stdout)
 		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(result); err != nil {
-			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-			os.Exit(1)
-		}
-	} else {
-		printScanTable(result)
+		encoder.Encode(profiles)
+		return
 	}
-}
-
-func printScanTable(result *ops.ScanSummary) {
-	fmt.Printf("🔌 Port Scan Results\n")
-	fmt.Printf("Run ID: %s\n", result.RunID)
-	fmt.Printf("Duration: %.1fs\n", result.Duration)
-	fmt.Printf("Targets: %d | Combinations: %d | Open Ports: %d | Success Rate: %.1f%%\n",
-		result.TargetsCount, result.TotalCombinations, result.OpenPorts, 
-		result.Stats.SuccessRate*100)
-	fmt.Printf("Scan Type: %s\n", result.ScanTypeUsed)
-	fmt.Println()
 
-	if len(result.Results) == 0 {
-		fmt.Println("No results.")
+	if host != "" {
+		output.PrintHostProfile(profiles[0])
 		return
 	}
-
-	// Filter and group results - show open ports first
-	var openPorts []ops.ScanResult
-	var otherPorts []ops.ScanResult
-
-	for _, r := range result.Results {
-		if r.Status == "open" {
-			openPorts = append(openPorts, r)
-		} else {
-			otherPorts = append(otherPorts, r)
-		}
+	if err := output.PrintHostInventory(profiles, tableOptions(cmd, nil, output.InventoryColumns)); err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.hosts_failed", err))
+		os.Exit(exitcode.Failed)
 	}
+}
 
-	// Print open ports
-	if len(openPorts) > 0 {
-		fmt.Printf("✅ Open Ports (%d):\n", len(openPorts))
-		fmt.Printf("%-15s %-6s %-8s %-8s %-12s %s\n", "Host", "Port", "Status", "RTT", "Service", "Details")
-		fmt.Println(strings.Repeat("-", 70))
-
-		for _, port := range openPorts {
-			rttStr := fmt.Sprintf("%.1fms", port.RTT)
-			service := "unknown"
-			details := ""
-
-			if port.Service != nil {
-				service = port.Service.Name
-				if port.Service.Version != "" {
-					details = port.Service.Version
-				} else if port.Service.Banner != "" {
-					details = truncateString(port.Service.Banner, 30)
-				}
-				if port.Service.Confidence < 0.7 {
-					service += "?"
-				}
-			}
+// runOutputPrune handles the output prune command
+func runOutputPrune(cmd *cobra.Command, args []string) {
+	olderThan, _ := cmd.Flags().GetString("older-than")
+	maxRuns, _ := cmd.Flags().GetInt("max-runs")
+	maxSize, _ := cmd.Flags().GetString("max-size")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
 
-			fmt.Printf("%-15s %-6d %-8s %-8s %-12s %s\n",
-				port.Host, port.Port, port.Status, rttStr, service, details)
+	var policy store.RetentionPolicy
+	var err error
+	if olderThan == "" && maxRuns == 0 && maxSize == "" {
+		policy, err = store.PolicyFromConfig()
+	} else {
+		policy.MaxRuns = maxRuns
+		if policy.MaxAge, err = config.ParseAge(olderThan); err == nil {
+			policy.MaxBytes, err = config.ParseByteSize(maxSize)
 		}
-		fmt.Println()
+	}
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.prune_failed", err))
+		os.Exit(exitcode.Usage)
+	}
+	if policy.IsZero() {
+		fmt.Print(i18n.T("engine.output.prune_no_policy"))
+		return
 	}
 
-	// Print summary statistics
-	fmt.Printf("📊 Statistics:\n")
-	fmt.Printf("  Hosts Scanned: %d\n", result.Stats.HostsScanned)
-	fmt.Printf("  Ports Scanned: %d\n", result.Stats.PortsScanned)
-	fmt.Printf("  Average RTT: %.1fms\n", result.Stats.AvgRTT)
-	fmt.Printf("  Scan Rate: %.1f pps\n", result.Stats.ScanRate)
-	fmt.Println()
-
-	// Print port status breakdown
-	fmt.Printf("🔧 Port Status:\n")
-	fmt.Printf("  Open: %d\n", result.Stats.ByStatus["open"])
-	fmt.Printf("  Closed: %d\n", result.Stats.ByStatus["closed"])
-	fmt.Printf("  Filtered: %d\n", result.Stats.ByStatus["filtered"])
-	if result.Stats.ByStatus["error"] > 0 {
-		fmt.Printf("  Errors: %d\n", result.Stats.ByStatus["error"])
+	candidates, err := store.PlanPrune(policy, "")
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.prune_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+	if len(candidates) == 0 {
+		fmt.Println(i18n.T("engine.output.prune_nothing"))
+		return
 	}
-	fmt.Println()
 
-	// Print service breakdown
-	if len(result.Stats.ByService) > 0 {
-		fmt.Printf("🔍 Services Detected:\n")
-		for service, count := range result.Stats.ByService {
-			if service != "unknown" || count > 0 {
-				fmt.Printf("  %s: %d\n", service, count)
-			}
-		}
-		fmt.Println()
+	var freed int64
+	for _, candidate := range candidates {
+		freed += candidate.Size
+		fmt.Printf("  %-24s %-10s %-20s %-10s %s\n", candidate.Record.RunID, candidate.Record.Type,
+			candidate.Record.StartTime.Format("2006-01-02 15:04:05"), output.FormatBytes(candidate.Size),
+			i18n.T("engine.output.prune_reason."+candidate.Reason))
 	}
 
-	// Show summary for non-open ports
-	nonOpenCount := len(otherPorts)
-	if nonOpenCount > 0 {
-		fmt.Printf("❌ Non-Open Ports: %d\n", nonOpenCount)
-		fmt.Printf("   Use --json flag to see full details\n")
+	if dryRun {
+		fmt.Print(i18n.T("engine.output.prune_dry_run", len(candidates), output.FormatBytes(freed)))
+		return
 	}
-}
 
-// Helper function for string truncation
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+	if err := store.Prune(candidates); err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.prune_failed", err))
+		os.Exit(exitcode.Failed)
 	}
-	return s[:maxLen] + "..."
+	fmt.Print(i18n.T("engine.output.pruned", len(candidates), output.FormatBytes(freed)))
 }
 
-// runOutputShow handles the output show command
-func runOutputShow(cmd *cobra.Command, args []string) {
-	showLast, _ := cmd.Flags().GetBool("last")
-	runID, _ := cmd.Flags().GetString("run")
+// runOutputQuery handles the output query command
+func runOutputQuery(cmd *cobra.Command, args []string) {
+	host, _ := cmd.Flags().GetString("host")
+	rawSQL, _ := cmd.Flags().GetString("sql")
+	rebuild, _ := cmd.Flags().GetBool("rebuild")
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 
-	var runInfo *output.RunInfo
-	var err error
+	index, err := store.OpenIndex()
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.query_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+	defer index.Close()
 
-	if showLast {
-		runInfo, err = output.GetLastRun()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ 获取最近运行失败: %v\n", err)
-			os.Exit(1)
-		}
-	} else if runID != "" {
-		runInfo, err = output.GetRunByID(runID)
+	if rebuild {
+		count, err := index.Rebuild()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ 找不到运行 '%s': %v\n", runID, err)
-			os.Exit(1)
+			fmt.Fprint(os.Stderr, i18n.T("engine.output.query_failed", err))
+			os.Exit(exitcode.Failed)
 		}
-	} else {
-		// Show latest by default
-		runInfo, err = output.GetLastRun()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ 没有找到保存的运行结果\n")
-			fmt.Printf("运行 'netcrate quick' 来创建你的第一次扫描\n")
-			os.Exit(1)
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.query_rebuilt", count, store.IndexPath()))
+		if rawSQL == "" && len(args) == 0 {
+			return
 		}
+	} else if _, err := index.Sync(); err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.query_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+
+	var result *store.QueryResult
+	switch {
+	case rawSQL != "":
+		result, err = index.RawQuery(rawSQL)
+	case len(args) == 1:
+		result, err = index.Query(args[0], host)
+	default:
+		result, err = index.Query("hosts", "")
+	}
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.query_failed", err))
+		os.Exit(exitcode.Usage)
 	}
 
 	if jsonOutput {
-		result, err := output.LoadQuickResult(runInfo)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ 加载结果失败: %v\n", err)
-			os.Exit(1)
-		}
 		encoder := json.NewEncoder(os.Stdout)
 		encoder.SetIndent("", "  ")
 		encoder.Encode(result)
-	} else {
-		err = output.PrintRunDetails(runInfo)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ 显示结果失败: %v\n", err)
-			os.Exit(1)
-		}
-		
-		// Show compliance summary
-		checker, err := compliance.NewComplianceChecker()
-		if err == nil {
-			if summary, err := checker.GetComplianceSummary(); err == nil && summary.TotalChecks > 0 {
-				fmt.Printf("\n📋 Compliance Summary:\n")
-				fmt.Printf("======================\n")
-				fmt.Printf("Total checks: %d\n", summary.TotalChecks)
-				fmt.Printf("Allowed scans: %d\n", summary.AllowedScans)
-				fmt.Printf("Blocked scans: %d\n", summary.BlockedScans)
-				fmt.Printf("Public targets: %d\n", summary.PublicTargets)
-				fmt.Printf("Private targets: %d\n", summary.PrivateTargets)
-				if summary.LastCheck != "" {
-					fmt.Printf("Last check: %s\n", summary.LastCheck)
-				}
-			}
-		}
+		return
 	}
+
+	printQueryTable(cmd, result)
 }
 
-// printEnhancedDiscoverSummary prints summary of enhanced discovery features
-func printEnhancedDiscoverSummary(result *ops.EnhancedDiscoverSummary) {
-	fmt.Fprintf(os.Stderr, "📈 Enhanced Discovery Summary (B1)\n")
-	fmt.Fprintf(os.Stderr, "=====================================\n")
-	
-	// Target prioritization info
-	if result.TargetsPrioritized > 0 {
-		fmt.Fprintf(os.Stderr, "🎯 Target prioritization: %d targets processed\n", result.TargetsPrioritized)
-		if len(result.TargetPriorityStats) > 0 {
-			high := result.TargetPriorityStats[ops.PriorityHigh]
-			medium := result.TargetPriorityStats[ops.PriorityMedium]
-			low := result.TargetPriorityStats[ops.PriorityLow]
-			fmt.Fprintf(os.Stderr, "   Priority distribution: High=%d, Medium=%d, Low=%d\n", high, medium, low)
+// printQueryTable prints query rows as aligned columns
+func printQueryTable(cmd *cobra.Command, result *store.QueryResult) {
+	table := &output.Table{Columns: result.Columns}
+	for _, values := range result.Rows {
+		row := make(map[string]string, len(values))
+		for i, column := range result.Columns {
+			row[column] = values[i]
 		}
+		table.Rows = append(table.Rows, row)
 	}
-	
-	// Sampling info
-	if result.SamplingUsed {
-		fmt.Fprintf(os.Stderr, "📊 Sampling: %.1f%% of targets, estimated density: %.2f\n", 
-			result.SamplingPercent*100, result.DensityEstimate)
-	}
-	
-	// Method fallback info
-	if result.MethodFallbackUsed {
-		fmt.Fprintf(os.Stderr, "🔄 Method fallback: %s → %s\n", 
-			strings.Join(result.OriginalMethods, ","), strings.Join(result.ActualMethods, ","))
-	}
-	
-	// Adaptive rate info
-	if result.AdaptiveRateUsed && len(result.RateAdjustments) > 0 {
-		fmt.Fprintf(os.Stderr, "⚡ Rate adjustments: %d changes\n", len(result.RateAdjustments))
-		for _, adj := range result.RateAdjustments {
-			fmt.Fprintf(os.Stderr, "   %s: %dpps → %dpps (%s)\n", 
-				adj.Timestamp.Format("15:04:05"), adj.OldRate, adj.NewRate, adj.Reason)
-		}
+	if err := output.PrintTable(table, tableOptions(cmd, nil, table.Columns)); err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.query_failed", err))
+		os.Exit(exitcode.Failed)
 	}
-	
-	fmt.Fprintf(os.Stderr, "\n")
 }
 
 // runOutputList handles the output list command
 func runOutputList(cmd *cobra.Command, args []string) {
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	search, _ := cmd.Flags().GetString("search")
+
 	runs, err := output.ListRuns()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ 获取运行列表失败: %v\n", err)
-		os.Exit(1)
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.list_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+
+	if len(tags) > 0 || search != "" {
+		runs = output.FilterRuns(runs, tags, search)
+	}
+
+	sortBy, _ := cmd.Flags().GetString("sort")
+	if err := output.SortRuns(runs, sortBy); err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.list_failed", err))
+		os.Exit(exitcode.Usage)
+	}
+
+	limit, _ := cmd.Flags().GetInt("limit")
+	if err := output.PrintRunsList(runs, limit, tableOptions(cmd, nil, output.RunColumns)); err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.list_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+}
+
+// runOutputEncrypt handles the output encrypt command
+func runOutputEncrypt(cmd *cobra.Command, args []string) {
+	count, err := store.EncryptAll()
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.encrypt_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+	fmt.Print(i18n.T("engine.output.encrypted", count))
+}
+
+// runOutputMerge handles the output merge command
+func runOutputMerge(cmd *cobra.Command, args []string) {
+	outID, _ := cmd.Flags().GetString("out")
+
+	merged, err := output.MergeRuns(args, outID, runLabelsFromFlags(cmd))
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.merge_failed", err))
+		os.Exit(exitcode.Failed)
 	}
 
-	output.PrintRunsList(runs)
+	fmt.Print(i18n.T("engine.output.merged", len(merged.MergedFrom), merged.RunID,
+		merged.Summary.HostsDiscovered, merged.Summary.OpenPorts))
+}
+
+// runOutputTag handles the output tag command
+func runOutputTag(cmd *cobra.Command, args []string) {
+	addTags, _ := cmd.Flags().GetStringSlice("tag")
+	removeTags, _ := cmd.Flags().GetStringSlice("remove-tag")
+
+	record, err := store.UpdateLabels(args[0], func(labels *store.Labels) {
+		if cmd.Flags().Changed("name") {
+			labels.Name, _ = cmd.Flags().GetString("name")
+		}
+		if cmd.Flags().Changed("note") {
+			labels.Notes, _ = cmd.Flags().GetString("note")
+		}
+		for _, tag := range addTags {
+			if !labels.HasTag(tag) {
+				labels.Tags = append(labels.Tags, tag)
+			}
+		}
+		remove := store.Labels{Tags: removeTags}
+		var kept []string
+		for _, tag := range labels.Tags {
+			if !remove.HasTag(tag) {
+				kept = append(kept, tag)
+			}
+		}
+		labels.Tags = kept
+	})
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.T("engine.output.tag_failed", err))
+		os.Exit(exitcode.Failed)
+	}
+
+	fmt.Print(i18n.T("engine.output.tagged", record.RunID, record.Name, strings.Join(record.Tags, ", ")))
 }
 
 // Template command implementations
@@ -1246,7 +3729,7 @@ func runTemplateList(cmd *cobra.Command, args []string) {
 	registry := templates.NewRegistry()
 	if err := registry.LoadTemplates(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading templates: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.Failed)
 	}
 
 	jsonOutput, _ := cmd.Flags().GetBool("json")
@@ -1255,13 +3738,13 @@ func runTemplateList(cmd *cobra.Command, args []string) {
 	if jsonOutput {
 		if err := json.NewEncoder(os.Stdout).Encode(templateList); err != nil {
 			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitcode.Failed)
 		}
 		return
 	}
 
 	// Table output
-	fmt.Printf("📋 Available Templates (%d)\n\n", len(templateList))
+	style.Printf("📋 Available Templates (%d)\n\n", len(templateList))
 	
 	if len(templateList) == 0 {
 		fmt.Println("No templates found.")
@@ -1273,7 +3756,7 @@ func runTemplateList(cmd *cobra.Command, args []string) {
 	}
 
 	for _, template := range templateList {
-		fmt.Printf("🔹 %s v%s (%s)\n", template.Name, template.Version, template.Source)
+		style.Printf("🔹 %s v%s (%s)\n", template.Name, template.Version, template.Source)
 		fmt.Printf("   %s\n", template.Description)
 		
 		if len(template.Tags) > 0 {
@@ -1295,18 +3778,18 @@ func runTemplateView(cmd *cobra.Command, args []string) {
 	registry := templates.NewRegistry()
 	if err := registry.LoadTemplates(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading templates: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.Failed)
 	}
 
 	template, exists := registry.Get(templateName)
 	if !exists {
 		fmt.Fprintf(os.Stderr, "Template '%s' not found.\n", templateName)
 		fmt.Fprintf(os.Stderr, "Use 'netcrate templates ls' to list available templates.\n")
-		os.Exit(1)
+		os.Exit(exitcode.Usage)
 	}
 
 	// Display template details
-	fmt.Printf("📄 Template: %s\n", template.Name)
+	style.Printf("📄 Template: %s\n", template.Name)
 	fmt.Printf("====================\n\n")
 	
 	fmt.Printf("Version: %s\n", template.Version)
@@ -1320,10 +3803,10 @@ func runTemplateView(cmd *cobra.Command, args []string) {
 	}
 	
 	if template.RequireDangerous {
-		fmt.Printf("⚠️  Requires --dangerous flag\n")
+		style.Printf("⚠️  Requires --dangerous flag\n")
 	}
 	
-	fmt.Printf("\n📋 Parameters (%d):\n", len(template.Parameters))
+	style.Printf("\n📋 Parameters (%d):\n", len(template.Parameters))
 	for _, param := range template.Parameters {
 		required := ""
 		if param.Required {
@@ -1344,17 +3827,35 @@ func runTemplateView(cmd *cobra.Command, args []string) {
 		fmt.Println()
 	}
 
-	fmt.Printf("🔄 Steps (%d):\n", len(template.Steps))
+	style.Printf("🔄 Steps (%d):\n", len(template.Steps))
 	for i, step := range template.Steps {
 		fmt.Printf("  %d. %s (%s)\n", i+1, step.Name, step.Operation)
 		
 		if step.DependsOn != "" {
 			fmt.Printf("     Depends on: %s\n", step.DependsOn)
 		}
+
+		if requires := templates.StepRequirements(step); len(requires) > 0 {
+			fmt.Printf("     Requires: %s\n", strings.Join(requires, ", "))
+		}
 		
 		if step.OnError != "" && step.OnError != "fail" {
 			fmt.Printf("     On error: %s\n", step.OnError)
 		}
+
+		if step.Operation == templates.ReportOperation {
+			if out, err := templates.ReportStep(step); err == nil {
+				format := out.Format
+				if format == "" {
+					format = "html"
+				}
+				path := out.Path
+				if path == "" {
+					path = "~/.netcrate/reports/{template}-{date}." + format
+				}
+				fmt.Printf("     Writes %s report: %s\n", format, path)
+			}
+		}
 		
 		fmt.Println()
 	}
@@ -1368,14 +3869,14 @@ func runTemplateRun(cmd *cobra.Command, args []string) {
 	registry := templates.NewRegistry()
 	if err := registry.LoadTemplates(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading templates: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.Failed)
 	}
 
 	template, exists := registry.Get(templateName)
 	if !exists {
 		fmt.Fprintf(os.Stderr, "Template '%s' not found.\n", templateName)
 		fmt.Fprintf(os.Stderr, "Use 'netcrate templates ls' to list available templates.\n")
-		os.Exit(1)
+		os.Exit(exitcode.Usage)
 	}
 
 	// Parse parameters from command line
@@ -1389,53 +3890,121 @@ func runTemplateRun(cmd *cobra.Command, args []string) {
 	}
 	
 	// Set default parameters if not provided
+	if err := applyTemplateRateProfile(cmd, template, parameters); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
 	for _, paramDef := range template.Parameters {
 		if _, exists := parameters[paramDef.Name]; !exists && paramDef.Default != nil {
 			parameters[paramDef.Name] = paramDef.Default
 		}
 	}
 
-	// Run compliance check
-	checker, err := compliance.NewComplianceChecker()
+	// Steps get the secrets; parameters keep the secret:// references
+	resolved, _, err := config.ResolveSecrets(parameters)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Compliance checker initialization failed: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
 	}
 
-	targets := checker.ParseTargetsFromTemplate(parameters)
+	// Run compliance check
+	checker := newComplianceChecker(cmd)
+
+	targets := checker.ParseTargetsFromTemplate(resolved)
 	sessionID := fmt.Sprintf("template-%s-%d", templateName, time.Now().Unix())
+	if profile, ok := parameters["rate_profile"].(string); ok {
+		checker.Budget = &audit.Budget{Profile: profile}
+	}
 	command := fmt.Sprintf("netcrate templates run %s", templateName)
 	
 	complianceResult, err := checker.CheckCompliance(sessionID, templateName, command, targets, dangerousFlag)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Compliance violation: %v\n", err)
-		os.Exit(1)
+		style.Fprintf(os.Stderr, "❌ Compliance violation: %v\n", err)
+		os.Exit(exitcode.Blocked)
 	}
 	
 	if complianceResult.Status == "blocked" {
-		fmt.Fprintf(os.Stderr, "❌ Template execution blocked by compliance rules: %s\n", complianceResult.BlockReason)
-		os.Exit(1)
+		style.Fprintf(os.Stderr, "❌ Template execution blocked by compliance rules: %s\n", complianceResult.BlockReason)
+		os.Exit(exitcode.Blocked)
+	}
+
+	// Settle what each step can do with the current privileges before
+	// running any of them
+	downgrades, err := templates.PlanRequirements(template, netenv.DetectCapabilityMatrix())
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(exitcode.Failed)
 	}
 
-	fmt.Printf("🚀 Running template: %s v%s\n", template.Name, template.Version)
+	style.Printf("🚀 Running template: %s v%s\n", template.Name, template.Version)
 	fmt.Printf("Description: %s\n", template.Description)
+	if scope := complianceResult.Scope; scope != nil {
+		fmt.Printf("Engagement scope: %s (authorization: %s)\n", scope.File, scope.Authorization)
+	}
 	
 	// Show compliance info if there are public targets
 	if len(complianceResult.PublicTargets) > 0 {
-		fmt.Printf("⚠️  Public targets detected: %v\n", complianceResult.PublicTargets)
+		style.Printf("⚠️  Public targets detected: %v\n", complianceResult.PublicTargets)
 		fmt.Printf("Risk level: %s\n", complianceResult.RiskLevel)
 		for _, warning := range complianceResult.Warnings {
-			fmt.Printf("⚠️  %s\n", warning)
+			style.Printf("⚠️  %s\n", warning)
 		}
 		fmt.Printf("\n")
 	}
 	
+	for _, downgrade := range downgrades {
+		style.Printf("⚠️  Step %s: %s unavailable, %s", downgrade.Step, downgrade.Requirement, downgrade.Change)
+		if downgrade.Reason != "" {
+			fmt.Printf(" (%s)", downgrade.Reason)
+		}
+		fmt.Println()
+	}
+
 	// TODO: Implement parameter collection and validation (C2)
 	// TODO: Implement step execution with error handling (C3)
 	
-	fmt.Printf("⚠️  Template execution not yet implemented.\n")
+	style.Printf("⚠️  Template execution not yet implemented.\n")
 	fmt.Printf("This will be completed in Step C2 (parameter validation) and C3 (execution).\n")
-	fmt.Printf("Compliance check passed ✅\n")
+	style.Printf("Compliance check passed ✅\n")
+}
+
+// applyTemplateRateProfile sets the rate_profile parameter of a template that has
+// one and was not given it with --param: to --profile, else to the profile of a
+// matching network override, else to the current profile when the template has no
+// default of its own
+func applyTemplateRateProfile(cmd *cobra.Command, template *templates.Template, parameters map[string]interface{}) error {
+	var param *templates.TemplateParameter
+	for i := range template.Parameters {
+		if template.Parameters[i].Name == "rate_profile" {
+			param = &template.Parameters[i]
+		}
+	}
+	if _, given := parameters["rate_profile"]; param == nil || given {
+		return nil
+	}
+
+	name, _ := cmd.Flags().GetString("profile")
+	if name == "" {
+		name = currentNetworkSettings("").RateProfile
+	}
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		if name != "" {
+			return err
+		}
+		return nil
+	}
+	if name == "" {
+		if param.Default != nil {
+			return nil
+		}
+		name = cm.GetConfig().CurrentRateProfile
+	}
+	if _, err := cm.GetRateProfile(name); err != nil {
+		return err
+	}
+	parameters["rate_profile"] = name
+	return nil
 }
 
 // runTemplateIndex handles the template index command
@@ -1443,7 +4012,7 @@ func runTemplateIndex(cmd *cobra.Command, args []string) {
 	registry := templates.NewRegistry()
 	if err := registry.LoadTemplates(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading templates: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.Failed)
 	}
 
 	registry.PrintIndex()
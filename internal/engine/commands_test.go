@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// newTestRoot builds the root command the way cmd/netcrate does
+func newTestRoot(args ...string) *cobra.Command {
+	root := &cobra.Command{Use: "netcrate", SilenceUsage: true}
+	AddStyleFlags(root)
+	AddGlobalFlags(root)
+	root.AddCommand(NewQuickCommand())
+	root.SetArgs(args)
+	return root
+}
+
+func TestQuickDryRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"targets", []string{"quick", "--dry-run", "--yes", "--targets", "10.99.0.0/30"}},
+		{"fail-on", []string{"quick", "--dry-run", "--yes", "--targets", "10.99.0.0/30", "--fail-on", "low"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A dry run has no run context to read clamps from and must not exit
+			if err := newTestRoot(tt.args...).Execute(); err != nil {
+				t.Fatalf("quick --dry-run: %v", err)
+			}
+		})
+	}
+}
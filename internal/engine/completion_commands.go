@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/netenv"
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/runid"
+	"github.com/netcrate/netcrate/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+// NewCompletionCommand creates the command that prints shell completion scripts
+func NewCompletionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "Print the shell completion script",
+		Long: `Completion prints the script that makes your shell complete netcrate
+commands and flags, as well as saved run IDs (output show --run <TAB>),
+template names, port set names and interface names.
+
+Bash (needs the bash-completion package):
+  source <(netcrate completion bash)
+  # every session, Linux:
+  netcrate completion bash > /etc/bash_completion.d/netcrate
+  # every session, macOS with Homebrew:
+  netcrate completion bash > $(brew --prefix)/etc/bash_completion.d/netcrate
+
+Zsh:
+  # once, if shell completion is not enabled yet:
+  echo "autoload -U compinit; compinit" >> ~/.zshrc
+  netcrate completion zsh > "${fpath[1]}/_netcrate"
+
+Fish:
+  netcrate completion fish > ~/.config/fish/completions/netcrate.fish
+
+PowerShell:
+  netcrate completion powershell | Out-String | Invoke-Expression
+  # every session: add the line above to your $PROFILE
+
+Start a new shell for the completion to take effect.`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			root := cmd.Root()
+			var err error
+			switch args[0] {
+			case "bash":
+				err = root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				err = root.GenZshCompletion(os.Stdout)
+			case "fish":
+				err = root.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				err = root.GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitcode.Failed)
+			}
+		},
+	}
+
+	return cmd
+}
+
+// completeRunIDs completes the IDs of saved runs, newest first, described by
+// their readable alias
+func completeRunIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ids, err := store.IDs()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	given := make(map[string]bool, len(args))
+	for _, arg := range args {
+		given[arg] = true
+	}
+	var completions []string
+	for _, id := range ids {
+		if !strings.HasPrefix(id, toComplete) || given[id] {
+			continue
+		}
+		if alias := runid.Alias(id); alias != "" {
+			id += "\t" + alias
+		}
+		completions = append(completions, id)
+	}
+	// Keep the shell's order, which is newest first
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeRunIDArg completes the run ID argument of a command taking at most
+// one
+func completeRunIDArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeRunIDs(cmd, args, toComplete)
+}
+
+// completeTemplateNames completes the name argument of the templates commands
+func completeTemplateNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	registry := templates.NewRegistry()
+	if err := registry.LoadTemplates(); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, template := range registry.List() {
+		if strings.HasPrefix(template.Name, toComplete) {
+			description, _, _ := strings.Cut(strings.TrimSpace(template.Description), "\n")
+			completions = append(completions, template.Name+"\t"+description)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePortSets completes the names of the predefined port sets; port
+// lists and ranges are left to the user
+func completePortSets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names := make([]string, 0, len(ops.PortSets))
+	for name := range ops.PortSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var completions []string
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, fmt.Sprintf("%s\t%d ports", name, len(ops.PortSets[name])))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeInterfaces completes the names of the active network interfaces
+func completeInterfaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	interfaces, err := netenv.GetActiveInterfaces()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, iface := range interfaces {
+		if !strings.HasPrefix(iface.Name, toComplete) {
+			continue
+		}
+		description := iface.Type
+		if len(iface.Addresses) > 0 {
+			description += " " + iface.Addresses[0].IP
+		}
+		completions = append(completions, iface.Name+"\t"+description)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeInterfacesOrAuto completes interface names for flags that also
+// accept "auto"
+func completeInterfacesOrAuto(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completions, directive := completeInterfaces(cmd, args, toComplete)
+	if strings.HasPrefix("auto", toComplete) {
+		completions = append([]string{"auto\tchoose automatically"}, completions...)
+	}
+	return completions, directive
+}
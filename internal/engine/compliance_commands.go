@@ -0,0 +1,252 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/compliance"
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// NewComplianceCommand creates the compliance command
+func NewComplianceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compliance",
+		Short: "Inspect and manage the compliance policy",
+	}
+
+	cmd.AddCommand(newComplianceBlocklistCommand())
+	cmd.AddCommand(newComplianceReportCommand())
+
+	return cmd
+}
+
+func newComplianceBlocklistCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "blocklist",
+		Short: "Manage the never-scan blocklist",
+		Long: `Targets on the never-scan blocklist are refused by every command that
+sends traffic, whatever the policy level, --dangerous or the engagement scope.
+A target is refused when any of its addresses overlaps a rule; the error
+names the rule.
+
+The built-in rules cover cloud metadata services, multicast and reserved
+space, and US Department of Defense networks, and cannot be removed. Rules
+added here are stored in compliance.blocked_ranges of the config file.
+
+Examples:
+  netcrate compliance blocklist list
+  netcrate compliance blocklist add 10.20.0.0/16
+  netcrate compliance blocklist remove 10.20.0.0/16`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List blocklist rules",
+		Args:  cobra.NoArgs,
+		RunE:  runComplianceBlocklistList,
+	}
+	listCmd.Flags().Bool("json", false, "Output in JSON format")
+	cmd.AddCommand(listCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "add <ip|cidr>",
+		Short: "Add a network to the blocklist",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runComplianceBlocklistAdd,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "remove <ip|cidr>",
+		Aliases: []string{"delete"},
+		Short:   "Remove a network added to the blocklist",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runComplianceBlocklistRemove,
+	})
+
+	return cmd
+}
+
+func runComplianceBlocklistList(cmd *cobra.Command, args []string) error {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	rules := compliance.Blocklist(cm.GetConfig().Compliance.BlockedRanges)
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rules)
+	}
+
+	fmt.Printf("%-20s %-26s %s\n", "NETWORK", "SOURCE", "NAME")
+	for _, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = "-"
+		}
+		fmt.Printf("%-20s %-26s %s\n", rule.Network, rule.Source, name)
+	}
+	return nil
+}
+
+func runComplianceBlocklistAdd(cmd *cobra.Command, args []string) error {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cm.AddBlockedRange(args[0]); err != nil {
+		return fmt.Errorf("failed to add blocklist rule: %w", err)
+	}
+	style.Printf("✅ %s added to the blocklist\n", args[0])
+	return nil
+}
+
+func runComplianceBlocklistRemove(cmd *cobra.Command, args []string) error {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cm.RemoveBlockedRange(args[0]); err != nil {
+		return fmt.Errorf("failed to remove blocklist rule: %w", err)
+	}
+	style.Printf("✅ %s removed from the blocklist\n", args[0])
+	return nil
+}
+
+func newComplianceReportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Summarize compliance checks and audited operations",
+		Long: `Summarize the compliance log and the audit log for a governance review:
+operations run, checks allowed and blocked with their reasons, runs against
+public targets, budget violations (options lowered to the policy ceilings),
+policy overrides, runs outside the scan windows, runs without --authorized-by
+or --ticket, and whether the audit log's hash chain is intact.
+
+Examples:
+  netcrate compliance report --since 30d
+  netcrate compliance report --since 90d --format html --output q3-compliance.html
+  netcrate compliance report --format json`,
+		Args: cobra.NoArgs,
+		RunE: runComplianceReport,
+	}
+
+	cmd.Flags().String("since", "", "Only include records from this long ago, e.g. 30d, 2w or 12h (default: all)")
+	cmd.Flags().String("format", "text", "Output format: text, json, html")
+	cmd.Flags().String("output", "", "Write the report to a file instead of standard output")
+
+	return cmd
+}
+
+func runComplianceReport(cmd *cobra.Command, args []string) error {
+	sinceFlag, _ := cmd.Flags().GetString("since")
+	format, _ := cmd.Flags().GetString("format")
+	outputPath, _ := cmd.Flags().GetString("output")
+
+	if format != "text" && format != "json" && format != "html" {
+		return fmt.Errorf("unknown format %q (use text, json or html)", format)
+	}
+	age, err := config.ParseAge(sinceFlag)
+	if err != nil {
+		return err
+	}
+	var since time.Time
+	if age > 0 {
+		since = time.Now().Add(-age)
+	}
+
+	checker, err := compliance.NewComplianceChecker()
+	if err != nil {
+		return err
+	}
+	report, err := checker.Report(since)
+	if err != nil {
+		return fmt.Errorf("failed to build compliance report: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	case "html":
+		err = report.WriteHTML(w)
+	default:
+		printComplianceReport(w, report)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write compliance report: %w", err)
+	}
+	if outputPath != "" {
+		style.Printf("✅ Compliance report written to %s\n", outputPath)
+	}
+	return nil
+}
+
+// printComplianceReport writes the text form of a compliance report
+func printComplianceReport(w io.Writer, report *compliance.Report) {
+	period := "all records"
+	if report.Since != nil {
+		period = "since " + report.Since.Local().Format("2006-01-02 15:04")
+	}
+	style.Fprintf(w, "📋 Compliance Report (%s)\n", period)
+	fmt.Fprintf(w, "======================\n")
+	fmt.Fprintf(w, "Operations:             %d\n", report.Operations)
+	fmt.Fprintf(w, "Compliance checks:      %d (%d allowed, %d blocked)\n", report.Checks, report.Allowed, report.Blocked)
+	fmt.Fprintf(w, "Runs on public targets: %d\n", report.PublicRuns)
+	fmt.Fprintf(w, "Budget violations:      %d\n", report.BudgetViolations)
+	fmt.Fprintf(w, "Policy overrides:       %d\n", report.Overrides)
+	fmt.Fprintf(w, "Outside scan windows:   %d\n", report.OutsideWindow)
+	fmt.Fprintf(w, "Without authorization:  %d\n", report.Unauthorized)
+	if report.AuditLog.Intact {
+		fmt.Fprintf(w, "Audit log:              intact (%d entries)\n", report.AuditLog.Entries)
+	} else {
+		style.Fprintf(w, "Audit log:              ❌ %s\n", report.AuditLog.Error)
+	}
+
+	printCounts(w, "Checks by command", report.ByCommand)
+	printCounts(w, "Checks by policy level", report.ByLevel)
+	printCounts(w, "Operations by operator", report.ByOperator)
+	if len(report.PublicTargets) > 0 {
+		fmt.Fprintf(w, "\nPublic targets: %s\n", strings.Join(report.PublicTargets, ", "))
+	}
+	printCounts(w, "Block reasons", report.BlockReasons)
+	if len(report.Violations) > 0 {
+		fmt.Fprintf(w, "\nBudget violations:\n")
+		for _, result := range report.Violations {
+			clamps := make([]string, len(result.Clamped))
+			for i, clamp := range result.Clamped {
+				clamps[i] = clamp.String()
+			}
+			fmt.Fprintf(w, "  • %s %s: %s\n", result.Timestamp.Local().Format("2006-01-02 15:04"), result.Command, strings.Join(clamps, "; "))
+		}
+	}
+}
+
+// printCounts writes a titled list of counts, nothing when it is empty
+func printCounts(w io.Writer, title string, counts []compliance.Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s:\n", title)
+	for _, count := range counts {
+		fmt.Fprintf(w, "  %5d  %s\n", count.Count, count.Value)
+	}
+}
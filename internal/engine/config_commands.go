@@ -1,11 +1,22 @@
 package engine
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/netcrate/netcrate/internal/compliance"
 	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/i18n"
+	"github.com/netcrate/netcrate/internal/style"
 	"github.com/spf13/cobra"
 )
 
@@ -26,19 +37,70 @@ Rate profiles control the speed and aggressiveness of scans:
 	// Add subcommands
 	cmd.AddCommand(NewConfigShowCommand())
 	cmd.AddCommand(NewConfigSetCommand())
+	cmd.AddCommand(NewConfigEditCommand())
+	cmd.AddCommand(NewConfigResetCommand())
 	cmd.AddCommand(NewConfigRateCommand())
+	cmd.AddCommand(NewConfigSecretCommand())
+	cmd.AddCommand(NewConfigProfileCommand())
+	cmd.AddCommand(NewConfigAliasCommand())
+
+	cmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		selectConfigProfile(cmd)
+	}
 
 	return cmd
 }
 
 // NewConfigShowCommand shows current configuration
 func NewConfigShowCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "show",
 		Short: "Show current configuration",
-		Long:  "Display the current NetCrate configuration including rate profiles and preferences.",
-		RunE:  runConfigShow,
+		Long: `Display the effective NetCrate configuration including rate profiles and
+preferences, and the sources it is built from: built-in defaults, the config
+file and environment variables such as NETCRATE_LANG.
+
+--json prints the effective configuration as JSON, with secrets hidden.
+
+--origin lists every setting that a NETCRATE_* environment variable can
+override, with its effective value and where that value comes from. Values
+are taken, in order of precedence, from command line flags, environment
+variables, ./netcrate.yaml, the config file and the built-in defaults.`,
+		RunE: runConfigShow,
 	}
+
+	cmd.Flags().Bool("json", false, "Print the effective configuration as JSON")
+	cmd.Flags().Bool("origin", false, "Show where each setting's value comes from and its environment variable")
+	return cmd
+}
+
+// NewConfigEditCommand opens the config file in an editor
+func NewConfigEditCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the config file in $EDITOR",
+		Long: `Open ~/.netcrate/config.yaml in $VISUAL or $EDITOR (vi when neither is
+set). The edited file is validated when the editor exits and only saved when it
+is valid; otherwise the error is shown with its line and you can edit it again
+or discard the changes. A config file that no longer loads can be edited too.`,
+		Args: cobra.NoArgs,
+		RunE: runConfigEdit,
+	}
+}
+
+// NewConfigResetCommand restores the default configuration
+func NewConfigResetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reset",
+		Short: "Reset configuration to defaults",
+		Long: `Restore the default configuration. The current config file is kept as
+config.yaml.bak-<time> next to it, so settings can be copied back.`,
+		Args: cobra.NoArgs,
+		RunE: runConfigReset,
+	}
+
+	cmd.Flags().BoolP("yes", "y", false, "Reset without asking for confirmation")
+	return cmd
 }
 
 // NewConfigSetCommand sets configuration values
@@ -47,11 +109,67 @@ func NewConfigSetCommand() *cobra.Command {
 		Use:   "set <key> <value>",
 		Short: "Set configuration value",
 		Long: `Set configuration values. Available keys:
-- output_format: table, json, yaml
+- output_format: table, json, yaml (json turns on --json for every command that has it)
+- results_dir: where runs are saved, absolute or ~/ path (empty for ~/.netcrate/runs)
+- auto_save: true, false (save every ops run; --save overrides it)
 - show_banners: true, false  
-- color_output: true, false
+- color_output: true, false (colors in the dashboard; --no-color and NO_COLOR turn them off)
+- emoji_output: true, false (emoji in messages; --no-emoji turns them off)
 - verbose: true, false
-- auto_confirm_dangerous: true, false`,
+- auto_confirm_dangerous: true, false
+- language: en, zh-CN
+- report_language: en, zh-CN (defaults to language)
+- quick_exclude_self: true, false
+- quick_exclude_gateway: true, false
+- do_not_scan: comma-separated IPs or CIDRs (empty to clear)
+- results_db: true, false (index runs in ~/.netcrate/netcrate.db)
+- record_public_ip: true, false (look up the public egress IP for each run's environment record)
+- notify_webhook, notify_slack, notify_discord: URL (empty to disable)
+- notify_trigger: new-critical, always
+- sink_url: HTTP(S) endpoint that receives ops results in batches (empty to disable)
+- sink_header: "Name: value" sent with every request, e.g. "Authorization: Bearer <token>"
+  (empty value removes the header)
+- sink_batch_size, sink_flush_interval, sink_retries: batching and retry (default 50, 5s, 3)
+- syslog_address: collector host:port that receives findings (empty to disable)
+- syslog_protocol: udp, tcp
+- syslog_format: rfc5424, cef
+- log_level: debug, info, warn, error (diagnostic log on stderr; --verbose and --quiet override it)
+- log_format: text, json (json writes one JSON object per log record)
+- log_persist: true, false (keep a debug log of every saved run in ~/.netcrate/logs)
+- proxy_url: http://, https:// or socks5:// proxy for packet templates and fingerprinting
+  (empty to use $HTTP_PROXY/$HTTPS_PROXY for HTTP probes, "direct" for no proxy)
+- proxy_no_proxy: comma-separated hosts, domains, IPs or CIDRs reached without the proxy
+- dns_resolvers: comma-separated DNS servers: 1.1.1.1, tls://1.1.1.1 (DNS over TLS),
+  https://dns.google/dns-query (DNS over HTTPS); empty for the system resolver
+- egress_stun_servers: comma-separated STUN servers (host:port) for ops netenv --egress
+- egress_ip_services: comma-separated https URLs answering with the public IP,
+  used when no STUN server answers (empty for the built-in ones)
+- updates_disabled: true, false (self-update never contacts GitHub, for air-gapped hosts)
+- updates_repository: GitHub owner/name self-update takes releases from (empty for the official one)
+- redaction_ip_octets: 0-4 (custom redaction profile, used with --redact custom)
+- redaction_hostnames, redaction_banners, redaction_macs: true, false
+- retention_max_runs: number of runs to keep (0 for no limit)
+- retention_max_age: e.g. 30d, 2w, 12h (empty for no limit)
+- retention_max_disk: e.g. 500MB, 2GB (empty for no limit)
+- encryption_enabled: true, false (encrypt saved runs at rest)
+- encryption_key_file: path to a key file (empty to clear)
+- encryption_passphrase: passphrase used when no key file is set
+  ($NETCRATE_PASSPHRASE takes precedence and is not stored)
+- report_theme: default, light, dark, minimal
+- report_format: html, md
+- report_dir: directory reports are written to when no output path is given
+- report_history: earlier runs compared in report host details (0 for the default of 3)
+- compliance_level: strict (private targets only, confirm every run), standard,
+  permissive (public targets with --dangerous, no typed confirmation)
+- policy_token: token that lifts the strict level for one run with --policy and
+  --policy-token; only its hash is stored (empty to remove)
+- scope_file: engagement scope file, absolute or ~/ path; targets outside it are blocked
+  (empty to clear)
+- authorized_by, ticket: who authorized scanning and the ticket or change number,
+  recorded with every run unless --authorized-by or --ticket is given (empty to clear)
+
+Compliance policy and per-command flag defaults are edited in the config file
+itself with 'netcrate config edit'.`,
 		Args: cobra.ExactArgs(2),
 		RunE: runConfigSet,
 	}
@@ -62,13 +180,24 @@ func NewConfigRateCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "rate",
 		Short: "Manage rate profiles",
-		Long:  "Manage scanning rate profiles for different speed/stealth requirements.",
+		Long: `Manage scanning rate profiles for different speed/stealth requirements.
+
+A profile sets the rate, concurrency, timeout, retries and per-host cap of
+discovery and port scans. 'rate set' selects the profile used by default;
+--profile on ops discover and ops scan ports selects one for a single run.
+
+Examples:
+  netcrate config rate list
+  netcrate config rate add office --rate 50 --concurrency 20 --max-per-host 2
+  netcrate config rate set office
+  netcrate ops scan ports --targets 10.0.0.5 --profile fast
+  netcrate config rate remove office`,
 	}
 
 	cmd.AddCommand(NewConfigRateListCommand())
 	cmd.AddCommand(NewConfigRateSetCommand())
-	cmd.AddCommand(NewConfigRateCreateCommand())
-	cmd.AddCommand(NewConfigRateDeleteCommand())
+	cmd.AddCommand(NewConfigRateAddCommand())
+	cmd.AddCommand(NewConfigRateRemoveCommand())
 
 	return cmd
 }
@@ -88,44 +217,197 @@ func NewConfigRateSetCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "set <profile-name>",
 		Short: "Set current rate profile",
-		Long: `Set the active rate profile. Available profiles:
+		Long: `Set the active rate profile. Built-in profiles:
 - slow: Conservative scanning for stealth
 - medium: Balanced scanning for general use
 - fast: Aggressive scanning for speed  
-- ludicrous: Maximum speed scanning`,
+- ludicrous: Maximum speed scanning
+
+Custom profiles added with 'config rate add' can be selected as well.`,
 		Args: cobra.ExactArgs(1),
 		RunE: runConfigRateSet,
 	}
 }
 
-// NewConfigRateCreateCommand creates a custom rate profile
-func NewConfigRateCreateCommand() *cobra.Command {
+// NewConfigRateAddCommand creates a custom rate profile
+func NewConfigRateAddCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "create <name>",
-		Short: "Create custom rate profile",
-		Long:  "Create a new custom rate profile with specified parameters.",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runConfigRateCreate,
+		Use:     "add <name>",
+		Aliases: []string{"create"},
+		Short:   "Add custom rate profile",
+		Long: `Add a custom rate profile with specified parameters. Adding a profile
+that already exists replaces it; built-in profiles cannot be redefined.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runConfigRateAdd,
 	}
 
 	cmd.Flags().Int("rate", 100, "Packets per second")
 	cmd.Flags().Int("concurrency", 100, "Number of concurrent workers")  
 	cmd.Flags().Duration("timeout", 2*time.Second, "Per-operation timeout")
 	cmd.Flags().Int("retries", 1, "Number of retry attempts")
+	cmd.Flags().Int("max-per-host", 0, "Concurrent probes per host (0 for no cap)")
 	cmd.Flags().String("description", "", "Profile description")
 
 	return cmd
 }
 
-// NewConfigRateDeleteCommand deletes a custom rate profile
-func NewConfigRateDeleteCommand() *cobra.Command {
+// NewConfigRateRemoveCommand deletes a custom rate profile
+func NewConfigRateRemoveCommand() *cobra.Command {
 	return &cobra.Command{
-		Use:   "delete <profile-name>",
-		Short: "Delete custom rate profile",
-		Long:  "Delete a custom rate profile. Built-in profiles cannot be deleted.",
+		Use:     "remove <profile-name>",
+		Aliases: []string{"delete"},
+		Short:   "Remove custom rate profile",
+		Long:    "Remove a custom rate profile. Built-in profiles cannot be removed.",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runConfigRateRemove,
+	}
+}
+
+// NewConfigProfileCommand manages config profiles
+func NewConfigProfileCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage config profiles",
+		Long: `Config profiles are named sets of settings, such as work, home or lab, laid
+over the config file: a rate profile, a compliance policy and the directories
+runs and reports are written to. Settings a profile leaves unset keep the
+values of the config file; a profile's compliance policy is edited with
+'netcrate config edit' under profiles.<name>.compliance.
+
+The current profile applies to every command. --config-profile or
+$NETCRATE_CONFIG_PROFILE select another one for a single command or shell.
+
+Examples:
+  netcrate config profile add lab --rate-profile fast --results-dir ~/lab/runs
+  netcrate config profile use lab
+  netcrate ops scan ports --targets 10.0.0.5 --config-profile work
+  netcrate config profile use none`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List config profiles",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigProfileList,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "use <name>",
+		Short: "Set the current config profile",
+		Long:  "Set the config profile every command uses. 'none' goes back to the config file alone.",
 		Args:  cobra.ExactArgs(1),
-		RunE:  runConfigRateDelete,
+		RunE:  runConfigProfileUse,
+	})
+
+	add := &cobra.Command{
+		Use:     "add <name>",
+		Aliases: []string{"create"},
+		Short:   "Add or replace a config profile",
+		Long: `Add a config profile. Adding a profile that already exists replaces its
+settings but keeps its compliance policy.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runConfigProfileAdd,
+	}
+	add.Flags().String("description", "", "Profile description")
+	add.Flags().String("rate-profile", "", "Rate profile used by default")
+	add.Flags().String("results-dir", "", "Directory runs are saved to (absolute or ~/ path)")
+	add.Flags().String("report-dir", "", "Directory reports are written to (absolute or ~/ path)")
+	cmd.AddCommand(add)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "remove <name>",
+		Aliases: []string{"delete"},
+		Short:   "Remove a config profile",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runConfigProfileRemove,
+	})
+
+	return cmd
+}
+
+// NewConfigAliasCommand manages command aliases
+func NewConfigAliasCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias",
+		Short: "Manage command aliases",
+		Long: `Aliases are shortcuts for frequent command lines, kept under aliases in the
+config file so a team can share them. 'netcrate <alias> [args...]' runs the
+command line the alias stands for, followed by the given arguments.
+
+Aliases cannot replace built-in commands and do not expand inside other
+aliases.
+
+Examples:
+  netcrate config alias set web-sweep "ops scan ports --ports web --fingerprint"
+  netcrate web-sweep --targets 10.0.0.0/24
+  netcrate config alias remove web-sweep`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List command aliases",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigAliasList,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <name> <command line>",
+		Short: "Add or replace a command alias",
+		Long: `Add an alias for a command line, written without "netcrate" and quoted as
+one argument. Setting an alias that exists replaces it.`,
+		Args: cobra.ExactArgs(2),
+		RunE: runConfigAliasSet,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "remove <name>",
+		Aliases: []string{"delete"},
+		Short:   "Remove a command alias",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runConfigAliasRemove,
+	})
+
+	return cmd
+}
+
+// NewConfigSecretCommand manages the encrypted secret vault
+func NewConfigSecretCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Manage probe credentials",
+		Long: `Store probe credentials such as SNMP communities or API tokens in an
+encrypted vault (~/.netcrate/secrets.enc). Template and packet parameters
+refer to them as secret://<name>; the secret is only substituted when the
+probe is sent, so shell history, run records and results keep the reference.
+
+Examples:
+  netcrate config secret set snmp_community
+  netcrate ops packet send --targets 10.0.0.5 --template http --param headers="Authorization: secret://api_token"
+  netcrate config secret list
+  netcrate config secret remove snmp_community`,
 	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <name> [value]",
+		Short: "Store a secret",
+		Long: `Store a secret, replacing any secret of the same name. Without a value
+it is read from standard input, which keeps it out of shell history.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: runConfigSecretSet,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List stored secrets",
+		Long:  "List the names of the stored secrets. Values are never shown.",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigSecretList,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "remove <name>",
+		Aliases: []string{"delete"},
+		Short:   "Remove a secret",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runConfigSecretRemove,
+	})
+
+	return cmd
 }
 
 // Command implementations
@@ -136,10 +418,158 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		effective := *cm.GetConfig()
+		if effective.Encryption.Passphrase != "" {
+			effective.Encryption.Passphrase = "(hidden)"
+		}
+		if len(effective.Sink.Headers) > 0 {
+			headers := make(map[string]string, len(effective.Sink.Headers))
+			for name := range effective.Sink.Headers {
+				headers[name] = "(hidden)"
+			}
+			effective.Sink.Headers = headers
+		}
+		effective.Proxy.URL = config.RedactProxyURL(effective.Proxy.URL)
+		effective.Notifications = effective.Notifications.Redacted()
+		data, err := json.MarshalIndent(effective, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode configuration: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if showOrigin, _ := cmd.Flags().GetBool("origin"); showOrigin {
+		fmt.Printf("Precedence: flags > environment > project file > config profile > config file > defaults\n\n")
+		fmt.Printf("%-36s %-20s %-14s %s\n", "Setting", "Value", "Origin", "Variable")
+		for _, origin := range cm.Origins() {
+			value := origin.Value
+			if value == "" {
+				value = "-"
+			}
+			fmt.Printf("%-36s %-20s %-14s $%s\n", origin.Key, value, origin.Origin, origin.Env)
+		}
+		return nil
+	}
+
+	fmt.Printf("Sources (later ones take precedence):\n")
+	for _, source := range cm.Sources() {
+		fmt.Printf("  • %s: %s\n", source.Name, source.Detail)
+	}
+	fmt.Println()
+
 	cm.PrintConfig()
 	return nil
 }
 
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+	// Loading creates or migrates the file; a file that fails to load is
+	// still opened so it can be fixed
+	if _, err := config.NewConfigManager(); err != nil {
+		style.Printf("⚠️  %v\n", err)
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Edit a copy so an invalid file never replaces the working config
+	draft, err := os.CreateTemp(filepath.Dir(path), "config.edit-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create edit file: %w", err)
+	}
+	defer os.Remove(draft.Name())
+	if _, err := draft.Write(original); err != nil {
+		draft.Close()
+		return fmt.Errorf("failed to create edit file: %w", err)
+	}
+	draft.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fields := strings.Fields(editor)
+		run := exec.Command(fields[0], append(fields[1:], draft.Name())...)
+		run.Stdin, run.Stdout, run.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := run.Run(); err != nil {
+			return fmt.Errorf("editor %s failed: %w", editor, err)
+		}
+
+		edited, err := os.ReadFile(draft.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read edited config: %w", err)
+		}
+		if bytes.Equal(edited, original) {
+			fmt.Println("No changes made.")
+			return nil
+		}
+
+		_, err = config.ParseConfig(edited)
+		if err == nil {
+			if err := os.WriteFile(path, edited, 0600); err != nil {
+				return fmt.Errorf("failed to write config file: %w", err)
+			}
+			style.Printf("✅ Configuration saved: %s\n", path)
+			return nil
+		}
+
+		style.Printf("❌ Invalid configuration: %v\n", err)
+		fmt.Print("Edit again? [Y/n] ")
+		answer := ""
+		if scanner.Scan() {
+			answer = strings.ToLower(strings.TrimSpace(scanner.Text()))
+		}
+		if answer == "n" || answer == "no" {
+			fmt.Println("Changes discarded.")
+			return nil
+		}
+	}
+}
+
+func runConfigReset(cmd *cobra.Command, args []string) error {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+		fmt.Printf("Reset %s to the defaults? A backup is kept. [y/N] ", path)
+		answer := ""
+		scanner := bufio.NewScanner(os.Stdin)
+		if scanner.Scan() {
+			answer = strings.ToLower(strings.TrimSpace(scanner.Text()))
+		}
+		if answer != "y" && answer != "yes" {
+			fmt.Println("Reset cancelled.")
+			return nil
+		}
+	}
+
+	backup, err := config.ResetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to reset configuration: %w", err)
+	}
+	if backup == "" {
+		style.Printf("✅ Configuration reset to defaults\n")
+		return nil
+	}
+	style.Printf("✅ Configuration reset to defaults (backup: %s)\n", backup)
+	return nil
+}
+
 func runConfigSet(cmd *cobra.Command, args []string) error {
 	key := args[0]
 	value := args[1]
@@ -149,12 +579,166 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	if strings.HasPrefix(key, "notify_") {
+		if err := cm.SetNotification(key, value); err != nil {
+			return fmt.Errorf("failed to set notification: %w", err)
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if strings.HasPrefix(key, "retention_") {
+		if err := cm.SetRetention(key, value); err != nil {
+			return fmt.Errorf("failed to set retention: %w", err)
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if strings.HasPrefix(key, "sink_") {
+		if err := cm.SetSink(key, value); err != nil {
+			return fmt.Errorf("failed to set sink: %w", err)
+		}
+		if key == "sink_header" {
+			value, _, _ = strings.Cut(value, ":")
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if strings.HasPrefix(key, "syslog_") {
+		if err := cm.SetSyslog(key, value); err != nil {
+			return fmt.Errorf("failed to set syslog: %w", err)
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if strings.HasPrefix(key, "log_") {
+		if err := cm.SetLogging(key, value); err != nil {
+			return fmt.Errorf("failed to set logging: %w", err)
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if strings.HasPrefix(key, "proxy_") || strings.HasPrefix(key, "dns_") {
+		if err := cm.SetTransport(key, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if strings.HasPrefix(key, "egress_") {
+		if err := cm.SetEgress(key, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if strings.HasPrefix(key, "updates_") {
+		if err := cm.SetUpdates(key, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if strings.HasPrefix(key, "redaction_") {
+		if err := cm.SetRedaction(key, value); err != nil {
+			return fmt.Errorf("failed to set redaction: %w", err)
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if strings.HasPrefix(key, "report_") && key != "report_language" {
+		if err := cm.SetReport(key, value); err != nil {
+			return fmt.Errorf("failed to set report option: %w", err)
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if key == "compliance_level" || key == "policy_token" || key == "authorized_by" || key == "ticket" {
+		if err := cm.SetCompliance(key, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+		if key == "policy_token" {
+			value = "(hidden)"
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if key == "scope_file" {
+		if value != "" {
+			scope, err := compliance.LoadScope(config.ComplianceConfig{ScopeFile: value}.EffectiveScopeFile())
+			if err != nil {
+				return fmt.Errorf("invalid scope file: %w", err)
+			}
+			fmt.Printf("Engagement scope: %d allowed entries (authorization: %s)\n", len(scope.Allowed), scope.Authorization)
+		}
+		if err := cm.SetScopeFile(value); err != nil {
+			return fmt.Errorf("failed to set scope file: %w", err)
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
+	if strings.HasPrefix(key, "encryption_") {
+		if err := cm.SetEncryption(key, value); err != nil {
+			return fmt.Errorf("failed to set encryption: %w", err)
+		}
+		if key == "encryption_passphrase" {
+			value = "(hidden)"
+		}
+		style.Printf("✅ Configuration updated: %s = %s\n", key, value)
+		return nil
+	}
+
 	// Parse value based on key
 	var parsedValue interface{}
 	switch key {
 	case "output_format":
 		parsedValue = value
-	case "show_banners", "color_output", "verbose", "auto_confirm_dangerous":
+	case "results_dir":
+		if value != "" && !filepath.IsAbs(value) && !strings.HasPrefix(value, "~/") {
+			return fmt.Errorf("invalid results_dir: %s (use an absolute path or one starting with ~/)", value)
+		}
+		parsedValue = value
+	case "language", "report_language":
+		supported := false
+		for _, lang := range i18n.SupportedLanguages() {
+			if lang == value {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("unsupported language: %s (available: %s)", value, strings.Join(i18n.SupportedLanguages(), ", "))
+		}
+		parsedValue = value
+	case "do_not_scan":
+		var entries []string
+		for _, entry := range strings.Split(value, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			probe := entry
+			if !strings.Contains(probe, "/") {
+				probe += "/32"
+			}
+			if _, _, err := net.ParseCIDR(probe); err != nil {
+				return fmt.Errorf("invalid do_not_scan entry: %s", entry)
+			}
+			entries = append(entries, entry)
+		}
+		parsedValue = entries
+	case "show_banners", "color_output", "verbose", "auto_confirm_dangerous", "quick_exclude_self", "quick_exclude_gateway", "results_db", "record_public_ip", "auto_save", "emoji_output":
 		parsedValue, err = strconv.ParseBool(value)
 		if err != nil {
 			return fmt.Errorf("invalid boolean value for %s: %s", key, value)
@@ -167,7 +751,7 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to set preference: %w", err)
 	}
 
-	fmt.Printf("✅ Configuration updated: %s = %v\n", key, parsedValue)
+	style.Printf("✅ Configuration updated: %s = %v\n", key, parsedValue)
 	return nil
 }
 
@@ -184,16 +768,24 @@ func runConfigRateList(cmd *cobra.Command, args []string) error {
 	fmt.Printf("=============\n")
 	fmt.Printf("Current profile: %s\n\n", current.Name)
 
-	for name, profile := range profiles {
+	for _, name := range cm.ProfileNames() {
+		profile := profiles[name]
 		status := ""
 		if name == current.Name {
 			status = " (current)"
 		}
+		if !config.IsBuiltinProfile(name) {
+			status += " [custom]"
+		}
 
+		perHost := "no per-host cap"
+		if profile.MaxPerHost > 0 {
+			perHost = fmt.Sprintf("%d per host", profile.MaxPerHost)
+		}
 		fmt.Printf("• %s%s\n", name, status)
 		fmt.Printf("  Description: %s\n", profile.Description)
-		fmt.Printf("  Rate: %d pps | Concurrency: %d workers | Timeout: %v | Retries: %d\n\n",
-			profile.Rate, profile.Concurrency, profile.Timeout, profile.Retries)
+		fmt.Printf("  Rate: %d pps | Concurrency: %d workers (%s) | Timeout: %v | Retries: %d\n\n",
+			profile.Rate, profile.Concurrency, perHost, profile.Timeout, profile.Retries)
 	}
 
 	return nil
@@ -212,20 +804,21 @@ func runConfigRateSet(cmd *cobra.Command, args []string) error {
 	}
 
 	profile := cm.GetCurrentRateProfile()
-	fmt.Printf("✅ Rate profile set to: %s\n", profileName)
+	style.Printf("✅ Rate profile set to: %s\n", profileName)
 	fmt.Printf("Settings: %d pps, %d workers, %v timeout, %d retries\n",
 		profile.Rate, profile.Concurrency, profile.Timeout, profile.Retries)
 
 	return nil
 }
 
-func runConfigRateCreate(cmd *cobra.Command, args []string) error {
+func runConfigRateAdd(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
 	rate, _ := cmd.Flags().GetInt("rate")
 	concurrency, _ := cmd.Flags().GetInt("concurrency")
 	timeout, _ := cmd.Flags().GetDuration("timeout")
 	retries, _ := cmd.Flags().GetInt("retries")
+	maxPerHost, _ := cmd.Flags().GetInt("max-per-host")
 	description, _ := cmd.Flags().GetString("description")
 
 	if description == "" {
@@ -239,6 +832,7 @@ func runConfigRateCreate(cmd *cobra.Command, args []string) error {
 		Concurrency: concurrency,
 		Timeout:     timeout,
 		Retries:     retries,
+		MaxPerHost:  maxPerHost,
 	}
 
 	cm, err := config.NewConfigManager()
@@ -250,14 +844,14 @@ func runConfigRateCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create profile: %w", err)
 	}
 
-	fmt.Printf("✅ Custom rate profile '%s' created\n", name)
+	style.Printf("✅ Custom rate profile '%s' saved\n", name)
 	fmt.Printf("Settings: %d pps, %d workers, %v timeout, %d retries\n",
 		rate, concurrency, timeout, retries)
 
 	return nil
 }
 
-func runConfigRateDelete(cmd *cobra.Command, args []string) error {
+func runConfigRateRemove(cmd *cobra.Command, args []string) error {
 	profileName := args[0]
 
 	cm, err := config.NewConfigManager()
@@ -269,6 +863,218 @@ func runConfigRateDelete(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to delete profile: %w", err)
 	}
 
-	fmt.Printf("✅ Custom rate profile '%s' deleted\n", profileName)
+	style.Printf("✅ Custom rate profile '%s' removed\n", profileName)
+	return nil
+}
+
+func runConfigSecretSet(cmd *cobra.Command, args []string) error {
+	store, err := config.OpenSecrets()
+	if err != nil {
+		return err
+	}
+
+	name, value := args[0], ""
+	if len(args) == 2 {
+		value = args[1]
+	} else {
+		if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			fmt.Printf("Value for %s: ", name)
+		}
+		scanner := bufio.NewScanner(os.Stdin)
+		if scanner.Scan() {
+			value = strings.TrimRight(scanner.Text(), "\r")
+		}
+	}
+
+	if err := store.Set(name, value); err != nil {
+		return fmt.Errorf("failed to store secret: %w", err)
+	}
+	style.Printf("✅ Secret '%s' stored; use it as %s%s\n", name, config.SecretScheme, name)
+	return nil
+}
+
+func runConfigSecretList(cmd *cobra.Command, args []string) error {
+	store, err := config.OpenSecrets()
+	if err != nil {
+		return err
+	}
+
+	names := store.Names()
+	if len(names) == 0 {
+		fmt.Println("No secrets stored. Add one with 'netcrate config secret set <name>'.")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Printf("%s%s\n", config.SecretScheme, name)
+	}
+	return nil
+}
+
+func runConfigSecretRemove(cmd *cobra.Command, args []string) error {
+	store, err := config.OpenSecrets()
+	if err != nil {
+		return err
+	}
+
+	if err := store.Remove(args[0]); err != nil {
+		return fmt.Errorf("failed to remove secret: %w", err)
+	}
+	style.Printf("✅ Secret '%s' removed\n", args[0])
+	return nil
+}
+
+func runConfigProfileList(cmd *cobra.Command, args []string) error {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	names := cm.ConfigProfileNames()
+	if len(names) == 0 {
+		fmt.Println("No config profiles. Add one with 'netcrate config profile add <name>'.")
+		return nil
+	}
+	for _, name := range names {
+		profile, _ := cm.GetConfigProfile(name)
+		status := ""
+		if name == cm.ActiveConfigProfile() {
+			status = " (active)"
+		}
+		fmt.Printf("• %s%s", name, status)
+		if profile.Description != "" {
+			fmt.Printf(": %s", profile.Description)
+		}
+		fmt.Println()
+
+		var settings []string
+		if profile.RateProfile != "" {
+			settings = append(settings, "rate profile "+profile.RateProfile)
+		}
+		if profile.Compliance != nil {
+			settings = append(settings, "own compliance policy")
+		}
+		if profile.ResultsDir != "" {
+			settings = append(settings, "runs in "+profile.ResultsDir)
+		}
+		if profile.ReportDir != "" {
+			settings = append(settings, "reports in "+profile.ReportDir)
+		}
+		if len(settings) > 0 {
+			fmt.Printf("  %s\n", strings.Join(settings, ", "))
+		}
+	}
+	return nil
+}
+
+func runConfigProfileUse(cmd *cobra.Command, args []string) error {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if err := cm.UseConfigProfile(args[0]); err != nil {
+		return fmt.Errorf("failed to set config profile: %w", err)
+	}
+	if args[0] == config.NoConfigProfile {
+		style.Printf("✅ Config profiles turned off\n")
+		return nil
+	}
+	style.Printf("✅ Config profile set to: %s\n", args[0])
+	return nil
+}
+
+func runConfigProfileAdd(cmd *cobra.Command, args []string) error {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	description, _ := cmd.Flags().GetString("description")
+	rateProfile, _ := cmd.Flags().GetString("rate-profile")
+	resultsDir, _ := cmd.Flags().GetString("results-dir")
+	reportDir, _ := cmd.Flags().GetString("report-dir")
+	profile := config.ConfigProfile{
+		Description: description,
+		RateProfile: rateProfile,
+		ResultsDir:  resultsDir,
+		ReportDir:   reportDir,
+	}
+	if err := cm.AddConfigProfile(args[0], profile); err != nil {
+		return fmt.Errorf("failed to add config profile: %w", err)
+	}
+
+	style.Printf("✅ Config profile '%s' saved\n", args[0])
+	return nil
+}
+
+func runConfigProfileRemove(cmd *cobra.Command, args []string) error {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if err := cm.RemoveConfigProfile(args[0]); err != nil {
+		return fmt.Errorf("failed to remove config profile: %w", err)
+	}
+	style.Printf("✅ Config profile '%s' removed\n", args[0])
+	return nil
+}
+
+func runConfigAliasList(cmd *cobra.Command, args []string) error {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	names := cm.AliasNames()
+	if len(names) == 0 {
+		fmt.Println("No aliases. Add one with 'netcrate config alias set <name> <command line>'.")
+		return nil
+	}
+	for _, name := range names {
+		line, _ := cm.GetAlias(name)
+		status := ""
+		if isBuiltinCommand(cmd.Root(), name) {
+			status = " (hidden by the built-in command)"
+		}
+		fmt.Printf("• %s = %s%s\n", name, line, status)
+	}
+	return nil
+}
+
+func runConfigAliasSet(cmd *cobra.Command, args []string) error {
+	name, line := args[0], args[1]
+	if isBuiltinCommand(cmd.Root(), name) {
+		return fmt.Errorf("%q is a built-in command and cannot be an alias", name)
+	}
+	expansion, err := config.SplitCommandLine(line)
+	if err != nil {
+		return fmt.Errorf("invalid command line: %w", err)
+	}
+	if len(expansion) > 0 && !isBuiltinCommand(cmd.Root(), expansion[0]) {
+		return fmt.Errorf("unknown command %q (aliases run built-in commands)", expansion[0])
+	}
+
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cm.SetAlias(name, line); err != nil {
+		return fmt.Errorf("failed to set alias: %w", err)
+	}
+	style.Printf("✅ Alias '%s' saved: netcrate %s\n", name, line)
 	return nil
-}
\ No newline at end of file
+}
+
+func runConfigAliasRemove(cmd *cobra.Command, args []string) error {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if err := cm.RemoveAlias(args[0]); err != nil {
+		return fmt.Errorf("failed to remove alias: %w", err)
+	}
+	style.Printf("✅ Alias '%s' removed\n", args[0])
+	return nil
+}
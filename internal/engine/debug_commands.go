@@ -0,0 +1,342 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/audit"
+	"github.com/netcrate/netcrate/internal/compliance"
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/netenv"
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/quick"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// NewDebugCommand creates the hidden debug command, which runs single
+// operations with fixed test settings and prints their raw results. The
+// operations still go through the config, logging, compliance ceilings,
+// project scope, exclusions and audit of the real commands.
+func NewDebugCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "debug",
+		Short:  "Run single operations with test settings (for development)",
+		Hidden: true,
+	}
+
+	cmd.AddCommand(newDebugNetenvCommand())
+	cmd.AddCommand(newDebugDiscoverCommand())
+	cmd.AddCommand(newDebugScanCommand())
+	cmd.AddCommand(newDebugPacketCommand())
+	cmd.AddCommand(newDebugQuickCommand())
+
+	return cmd
+}
+
+func newDebugNetenvCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "netenv",
+		Short: "Print the detected network environment as JSON",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println("=== Testing Network Environment Detection ===")
+			result, err := netenv.DetectNetworkEnvironment()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitcode.Failed)
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.Encode(result)
+		},
+	}
+}
+
+func newDebugDiscoverCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "discover [target|auto]",
+		Short: "Discover hosts at 10 pps, optionally with the B1 enhancements",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runDebugDiscover,
+	}
+
+	cmd.Flags().Bool("enhanced", false, "Enable all enhancements (pruning, sampling, method fallback, adaptive rate)")
+	cmd.Flags().Bool("target-pruning", false, "Enable target prioritization only")
+	cmd.Flags().Bool("compat-a1", false, "Use A1 discovery without enhancements")
+	cmd.Flags().Int("rate", 10, "Packets per second")
+	cmd.Flags().StringSlice("methods", []string{"icmp", "tcp"}, "Discovery methods (icmp,tcp,arp)")
+	addDebugComplianceFlags(cmd)
+
+	return cmd
+}
+
+func runDebugDiscover(cmd *cobra.Command, args []string) {
+	enhanced, _ := cmd.Flags().GetBool("enhanced")
+	targetPruning, _ := cmd.Flags().GetBool("target-pruning")
+	compatA1, _ := cmd.Flags().GetBool("compat-a1")
+	rate, _ := cmd.Flags().GetInt("rate")
+	methods, _ := cmd.Flags().GetStringSlice("methods")
+
+	target := "auto"
+	if len(args) > 0 {
+		target = args[0]
+	}
+	network := currentNetworkSettings("")
+	project := currentProject()
+	clamps := applyComplianceCeilings(rateOptions{Rate: &rate}, nil)
+	checker := checkOpsCompliance(cmd, "netcrate debug discover", []string{target}, audit.Budget{Rate: rate}, clamps)
+
+	opts := ops.DiscoverOptions{
+		Targets:  []string{target},
+		Rate:     rate,
+		Methods:  methods,
+		Exclude:  append(network.DoNotScan, project.Scope.Exclude...),
+		Scope:    project.Scope.Allowed,
+		Classify: complianceClassifier(checker),
+	}
+
+	if (enhanced || targetPruning) && !compatA1 {
+		fmt.Println("=== Testing Enhanced Host Discovery (B1) ===")
+		result, err := ops.EnhancedDiscover(ops.DiscoverEnhancedOptions{
+			DiscoverOptions:      opts,
+			EnableTargetPruning:  true,
+			EnableSampling:       enhanced,
+			EnableMethodFallback: enhanced,
+			EnableAdaptiveRate:   enhanced,
+			SamplingPercent:      0.05,
+			HighLossThreshold:    0.3,
+			DownshiftStep:        0.2,
+			UpshiftStep:          0.1,
+			GoodWindowsToUpshift: 3,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitcode.Failed)
+		}
+
+		if result.TargetsPrioritized > 0 {
+			fmt.Printf("Target prioritization: %d targets processed\n", result.TargetsPrioritized)
+			fmt.Printf("Priority: High=%d, Medium=%d, Low=%d\n",
+				result.TargetPriorityStats[ops.PriorityHigh],
+				result.TargetPriorityStats[ops.PriorityMedium],
+				result.TargetPriorityStats[ops.PriorityLow])
+		}
+		if result.SamplingUsed {
+			fmt.Printf("Sampling: %.1f%% sample rate, estimated density=%.2f%%\n",
+				result.SamplingPercent*100, result.DensityEstimate*100)
+		}
+		if result.MethodFallbackUsed {
+			fmt.Printf("Method fallback: %v → %v\n", result.OriginalMethods, result.ActualMethods)
+		}
+		if result.AdaptiveRateUsed {
+			fmt.Printf("Adaptive rate: %d adjustments made\n", len(result.RateAdjustments))
+			for _, adj := range result.RateAdjustments {
+				fmt.Printf("  %s: %d→%d pps (%s)\n",
+					adj.Timestamp.Format("15:04:05"), adj.OldRate, adj.NewRate, adj.Reason)
+			}
+		}
+		printDebugHosts(result.DiscoverSummary)
+		return
+	}
+
+	if compatA1 {
+		fmt.Println("=== Testing Host Discovery (A1 Compatibility) ===")
+	} else {
+		fmt.Println("=== Testing Host Discovery ===")
+	}
+	result, err := ops.Discover(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Failed)
+	}
+	printDebugHosts(result)
+}
+
+func printDebugHosts(result *ops.DiscoverSummary) {
+	fmt.Printf("Discovered %d hosts out of %d targets\n", result.HostsDiscovered, result.TargetsResolved)
+	for _, host := range result.Results {
+		if host.Status == "up" {
+			fmt.Printf("  %s - %s (%.2fms via %s)\n", host.Host, host.Status, host.RTT, host.Method)
+		}
+	}
+}
+
+func newDebugScanCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scan [targets...]",
+		Short: "Scan a few ports at 10 pps (default: 22,80,443 on 127.0.0.1)",
+		Run:   runDebugScan,
+	}
+
+	cmd.Flags().String("ports", "22,80,443", "Ports to scan")
+	cmd.Flags().Int("rate", 10, "Packets per second")
+	addDebugComplianceFlags(cmd)
+
+	return cmd
+}
+
+func runDebugScan(cmd *cobra.Command, args []string) {
+	portsSpec, _ := cmd.Flags().GetString("ports")
+	rate, _ := cmd.Flags().GetInt("rate")
+
+	targets := args
+	if len(targets) == 0 {
+		targets = []string{"127.0.0.1"}
+	}
+	ports, err := ops.ParsePortSpec(portsSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing ports '%s': %v\n", portsSpec, err)
+		os.Exit(exitcode.Usage)
+	}
+	network := currentNetworkSettings("")
+	project := currentProject()
+	clamps := applyComplianceCeilings(rateOptions{Rate: &rate}, &ports)
+	checker := checkOpsCompliance(cmd, "netcrate debug scan", targets, audit.Budget{Rate: rate, Ports: len(ports)}, clamps)
+
+	fmt.Println("=== Testing Port Scanning ===")
+	result, err := ops.ScanPorts(ops.ScanOptions{
+		Targets:  targets,
+		Ports:    ports,
+		Rate:     rate,
+		Exclude:  append(network.DoNotScan, project.Scope.Exclude...),
+		Scope:    project.Scope.Allowed,
+		Classify: complianceClassifier(checker),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Failed)
+	}
+
+	fmt.Printf("Scanned %d total combinations on %d targets\n", result.TotalCombinations, result.TargetsCount)
+	for _, portResult := range result.Results {
+		if portResult.Status == "open" {
+			service := "unknown"
+			if portResult.Service != nil {
+				service = portResult.Service.Name
+			}
+			fmt.Printf("  %s:%d - %s (%s)\n", portResult.Host, portResult.Port, portResult.Status, service)
+		}
+	}
+}
+
+func newDebugPacketCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "packet [host:port...]",
+		Short: "Send one packet from a template (default: connect to 127.0.0.1:80)",
+		Run:   runDebugPacket,
+	}
+
+	cmd.Flags().String("template", "connect", "Packet template")
+	cmd.Flags().Int("count", 1, "Packets per target")
+	addDebugComplianceFlags(cmd)
+
+	return cmd
+}
+
+func runDebugPacket(cmd *cobra.Command, args []string) {
+	template, _ := cmd.Flags().GetString("template")
+	count, _ := cmd.Flags().GetInt("count")
+
+	targets := args
+	if len(targets) == 0 {
+		targets = []string{"127.0.0.1:80"}
+	}
+
+	// The targets are checked as those of ops packet send
+	var interval time.Duration
+	hosts := packetTargetHosts(targets)
+	checkPacketTargets(hosts, currentNetworkSettings(""), currentProject())
+	clamps := applyComplianceCeilings(rateOptions{Interval: &interval}, nil)
+	budget := audit.Budget{Packets: count * len(targets)}
+	if interval > 0 {
+		budget.Rate = int(time.Second / interval)
+	}
+	checkOpsCompliance(cmd, "netcrate debug packet", hosts, budget, clamps)
+
+	fmt.Println("=== Testing Packet Sending ===")
+	result, err := ops.SendPackets(ops.PacketOptions{
+		Targets:  targets,
+		Template: template,
+		Count:    count,
+		Interval: interval,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Failed)
+	}
+
+	fmt.Printf("Sent %d total packets to %d targets\n", result.TotalPackets, result.TargetsCount)
+	for _, packetResult := range result.Results {
+		fmt.Printf("  %s - %s (%.2fms)\n", packetResult.Target, packetResult.Status, packetResult.RTT)
+	}
+}
+
+func newDebugQuickCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quick",
+		Short: "Run the quick mode pipeline, as a dry run unless --real",
+		Args:  cobra.NoArgs,
+		Run:   runDebugQuick,
+	}
+
+	cmd.Flags().Bool("real", false, "Run the scan instead of a dry run")
+	cmd.Flags().Bool("interactive", false, "Choose the port set and speed profile")
+	addDebugComplianceFlags(cmd)
+
+	return cmd
+}
+
+func runDebugQuick(cmd *cobra.Command, args []string) {
+	real, _ := cmd.Flags().GetBool("real")
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	dangerous, _ := cmd.Flags().GetBool("dangerous")
+
+	fmt.Println("=== Testing Quick Mode ===")
+	switch {
+	case real:
+		style.Println("⚠️ Running REAL network scan (this may take time)")
+	case interactive:
+		style.Println("🎛️ Running in INTERACTIVE mode")
+	default:
+		style.Println("🧪 Running DRY RUN (use '--real' for actual scan, '--interactive' for configuration)")
+	}
+
+	checker := newComplianceChecker(cmd)
+	result, err := quick.RunQuickMode(quick.QuickOptions{
+		DryRun:      !real,
+		SkipConfirm: !interactive,
+		Interactive: interactive,
+		Preflight: func(targets []string, clamped []compliance.Clamp) error {
+			checker.Clamped = clamped
+			sessionID := fmt.Sprintf("debug-%d", time.Now().Unix())
+			complianceResult, err := checker.CheckCompliance(sessionID, "debug", "netcrate debug quick", targets, dangerous)
+			if err != nil {
+				return err
+			}
+			for _, warning := range complianceResult.Warnings {
+				style.Fprintf(os.Stderr, "⚠️  %s\n", warning)
+			}
+			return nil
+		},
+		Ceilings:      compliance.LoadCeilings(),
+		Authorization: runAuthorization(cmd),
+		Classify:      complianceClassifier(checker),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failureCode(err))
+	}
+	if result != nil {
+		quick.PrintQuickSummary(result)
+	}
+}
+
+// addDebugComplianceFlags adds the flags the compliance check of a debug
+// command reads
+func addDebugComplianceFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("dangerous", false, "Allow scanning of public networks")
+	addAuthorizationFlags(cmd)
+}
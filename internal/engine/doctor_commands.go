@@ -0,0 +1,422 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/netenv"
+	"github.com/netcrate/netcrate/internal/output"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/netcrate/netcrate/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+// Doctor check results
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+const (
+	// doctorDNSTimeout bounds each DNS server check
+	doctorDNSTimeout = 2 * time.Second
+
+	// Free space in the results directory below which doctor warns and fails
+	doctorDiskWarn = 1 << 30
+	doctorDiskFail = 100 << 20
+
+	// minimumMTU is the smallest MTU every IPv4 host must accept
+	minimumMTU = 576
+)
+
+// doctorCheck is the result of one self-check
+type doctorCheck struct {
+	Area   string `json:"area"`
+	Name   string `json:"name"`
+	Status string `json:"status"` // pass, warn or fail
+	Detail string `json:"detail,omitempty"`
+	Remedy string `json:"remedy,omitempty"`
+}
+
+// doctorTool is an external command netcrate runs on a platform
+type doctorTool struct {
+	name     string
+	purpose  string
+	install  string
+	required bool // netcrate loses a core function without it
+}
+
+// doctorTools lists the external commands netcrate runs, per platform
+var doctorTools = map[string][]doctorTool{
+	"linux": {
+		{name: "ping", purpose: "ICMP discovery without raw sockets, gateway checks", install: "install iputils-ping"},
+		{name: "ip", purpose: "routing table and default route", install: "install iproute2", required: true},
+		{name: "arp", purpose: "ARP table when /proc/net/arp is unreadable", install: "install net-tools"},
+		{name: "iw", purpose: "Wi-Fi link details", install: "install iw"},
+		{name: "iwgetid", purpose: "Wi-Fi network name for per-network config", install: "install wireless-tools"},
+		{name: "sudo", purpose: "privileged helper (--privileged-helper)", install: "install sudo"},
+	},
+	"darwin": {
+		{name: "ping", purpose: "ICMP discovery without raw sockets, gateway checks"},
+		{name: "netstat", purpose: "routing table", required: true},
+		{name: "route", purpose: "default route", required: true},
+		{name: "arp", purpose: "ARP table"},
+		{name: "networksetup", purpose: "Wi-Fi network name and hardware ports"},
+		{name: "sudo", purpose: "privileged helper (--privileged-helper)"},
+	},
+	"windows": {
+		{name: "ping", purpose: "ICMP discovery without raw sockets, gateway checks"},
+		{name: "route", purpose: "routing table", required: true},
+		{name: "arp", purpose: "ARP table when PowerShell is unavailable"},
+		{name: "powershell", purpose: "neighbor table (Get-NetNeighbor)"},
+		{name: "netsh", purpose: "Wi-Fi network name and link details"},
+	},
+}
+
+// NewDoctorCommand creates the self-check command
+func NewDoctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that this system is ready to run netcrate",
+		Long: `Doctor runs a self-check and reports each result as pass, warn or fail
+with a hint on how to fix it:
+
+  privileges  raw sockets, ICMP, packet capture and low ports
+  network     active interfaces, addresses, MTU and default gateway
+  dns         every configured DNS server answers
+  storage     free space for the results directory
+  config      the config file, project file and environment overrides
+  templates   the template search paths, index and template files
+  tools       the external commands netcrate runs on this platform
+
+Warnings mark features that fall back or are unavailable; a failed check
+stops netcrate from working as configured and makes doctor exit with 5.
+
+Examples:
+  netcrate doctor
+  netcrate doctor --json`,
+		Args: cobra.NoArgs,
+		Run:  runDoctor,
+	}
+
+	cmd.Flags().Bool("json", false, "Output in JSON format")
+
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	var checks []doctorCheck
+	checks = append(checks, doctorPrivileges()...)
+	checks = append(checks, doctorNetwork()...)
+	checks = append(checks, doctorDNS()...)
+	checks = append(checks, doctorStorage()...)
+	checks = append(checks, doctorConfig()...)
+	checks = append(checks, doctorTemplates()...)
+	checks = append(checks, doctorExternalTools()...)
+
+	counts := map[string]int{}
+	for _, check := range checks {
+		counts[check.Status]++
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(struct {
+			Platform string        `json:"platform"`
+			Passed   int           `json:"passed"`
+			Warnings int           `json:"warnings"`
+			Failed   int           `json:"failed"`
+			Checks   []doctorCheck `json:"checks"`
+		}{runtime.GOOS, counts[doctorPass], counts[doctorWarn], counts[doctorFail], checks})
+	} else {
+		printDoctorChecks(checks)
+		fmt.Printf("\n%d passed, %d warnings, %d failed\n", counts[doctorPass], counts[doctorWarn], counts[doctorFail])
+	}
+
+	if counts[doctorFail] > 0 {
+		os.Exit(exitcode.Policy)
+	}
+}
+
+func printDoctorChecks(checks []doctorCheck) {
+	style.Printf("🩺 NetCrate Doctor (%s/%s)\n", runtime.GOOS, runtime.GOARCH)
+	area := ""
+	for _, check := range checks {
+		if check.Area != area {
+			area = check.Area
+			fmt.Printf("\n%s\n", area)
+		}
+		switch check.Status {
+		case doctorPass:
+			style.Printf("  ✅ %-16s %s\n", check.Name, check.Detail)
+		case doctorWarn:
+			style.Printf("  ⚠️  %-16s %s\n", check.Name, check.Detail)
+		default:
+			style.Printf("  ❌ %-16s %s\n", check.Name, check.Detail)
+		}
+		if check.Remedy != "" && check.Status != doctorPass {
+			style.Printf("     💡 %s\n", check.Remedy)
+		}
+	}
+}
+
+// doctorPrivileges reports the capability matrix; a missing capability only
+// warns, since every operation it enables has a fallback or is optional
+func doctorPrivileges() []doctorCheck {
+	var checks []doctorCheck
+	for _, capability := range netenv.DetectCapabilityMatrix() {
+		check := doctorCheck{Area: "privileges", Name: capability.Name}
+		if capability.Available {
+			check.Status = doctorPass
+			check.Detail = "enables " + strings.Join(capability.Enables, ", ")
+		} else {
+			check.Status = doctorWarn
+			check.Detail = "unavailable"
+			if capability.Error != "" {
+				check.Detail += " (" + capability.Error + ")"
+			}
+			if len(capability.Limits) > 0 {
+				check.Detail += ": " + strings.Join(capability.Limits, "; ")
+			}
+			check.Remedy = capability.Remedy
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// doctorNetwork checks that there is an interface to scan from and a route
+// beyond the local networks
+func doctorNetwork() []doctorCheck {
+	interfaces, err := netenv.GetActiveInterfaces()
+	if err != nil {
+		return []doctorCheck{{Area: "network", Name: "interfaces", Status: doctorFail, Detail: err.Error()}}
+	}
+
+	var checks []doctorCheck
+	usable := 0
+	gateway := ""
+	for _, iface := range interfaces {
+		if iface.Type == "loopback" || len(iface.Addresses) == 0 {
+			continue
+		}
+		usable++
+		if gateway == "" && iface.Gateway != nil {
+			gateway = fmt.Sprintf("%s via %s", iface.Gateway.IP, iface.Name)
+		}
+
+		check := doctorCheck{Area: "network", Name: iface.Name, Status: doctorPass}
+		addresses := make([]string, len(iface.Addresses))
+		for i, address := range iface.Addresses {
+			addresses[i] = address.Network
+		}
+		check.Detail = fmt.Sprintf("%s, %s, MTU %d", iface.Type, strings.Join(addresses, " "), iface.MTU)
+		if iface.MTU > 0 && iface.MTU < minimumMTU {
+			check.Status = doctorWarn
+			check.Detail = fmt.Sprintf("MTU %d is below the IPv4 minimum of %d", iface.MTU, minimumMTU)
+			check.Remedy = fmt.Sprintf("raise the MTU of %s", iface.Name)
+		}
+		checks = append(checks, check)
+	}
+
+	if usable == 0 {
+		return append(checks, doctorCheck{
+			Area:   "network",
+			Name:   "interfaces",
+			Status: doctorFail,
+			Detail: "no active interface has an IPv4 address",
+			Remedy: "connect to a network, or bring an interface up and give it an address",
+		})
+	}
+
+	check := doctorCheck{Area: "network", Name: "default route", Status: doctorPass, Detail: gateway}
+	if gateway == "" {
+		check.Status = doctorWarn
+		check.Detail = "no default gateway; only directly attached networks are reachable"
+		check.Remedy = "check the network connection or add a default route"
+	}
+	return append(checks, check)
+}
+
+// doctorDNS resolves a known name through every configured DNS server
+func doctorDNS() []doctorCheck {
+	env, err := netenv.DetectNetworkEnvironment()
+	if err != nil {
+		return []doctorCheck{{Area: "dns", Name: "servers", Status: doctorFail, Detail: err.Error()}}
+	}
+	servers := env.SystemInfo.DNSServers
+	if len(servers) == 0 {
+		return []doctorCheck{{
+			Area:   "dns",
+			Name:   "servers",
+			Status: doctorFail,
+			Detail: "no DNS servers configured",
+			Remedy: "configure a DNS server for the active connection",
+		}}
+	}
+
+	var checks []doctorCheck
+	failing := 0
+	for _, health := range netenv.CheckDNSServers(servers, doctorDNSTimeout) {
+		check := doctorCheck{Area: "dns", Name: health.Server}
+		switch health.Status {
+		case netenv.DNSHealthy:
+			check.Status = doctorPass
+			check.Detail = fmt.Sprintf("answered in %.0fms", health.LatencyMS)
+		case netenv.DNSSlow:
+			check.Status = doctorWarn
+			check.Detail = fmt.Sprintf("slow: answered in %.0fms", health.LatencyMS)
+			check.Remedy = "host name resolution in reports may be slow; consider a closer DNS server"
+		default:
+			failing++
+			check.Status = doctorWarn
+			check.Detail = "not answering: " + health.Error
+			check.Remedy = "remove the server from the resolver configuration or check the firewall"
+		}
+		checks = append(checks, check)
+	}
+
+	// One dead server among working ones is a warning; none working fails
+	if failing == len(checks) {
+		for i := range checks {
+			checks[i].Status = doctorFail
+		}
+	}
+	return checks
+}
+
+// doctorStorage checks the free space where runs are saved
+func doctorStorage() []doctorCheck {
+	check := doctorCheck{Area: "storage", Name: "results dir"}
+	free, err := store.FreeSpace()
+	if err != nil {
+		check.Status = doctorWarn
+		check.Detail = fmt.Sprintf("%s: %v", store.Dir(), err)
+		return []doctorCheck{check}
+	}
+
+	check.Detail = fmt.Sprintf("%s, %s free", store.Dir(), output.FormatBytes(int64(free)))
+	switch {
+	case free < doctorDiskFail:
+		check.Status = doctorFail
+		check.Remedy = "free up disk space, prune old runs (output prune) or set preferences.results_dir"
+	case free < doctorDiskWarn:
+		check.Status = doctorWarn
+		check.Remedy = "large scans may not fit; prune old runs (output prune) or set preferences.results_dir"
+	default:
+		check.Status = doctorPass
+	}
+	return []doctorCheck{check}
+}
+
+// doctorConfig loads the config the way every command does: config file,
+// config profile, project file and environment overrides
+func doctorConfig() []doctorCheck {
+	path, _ := config.ConfigPath()
+	check := doctorCheck{Area: "config", Name: "config", Status: doctorPass, Detail: path}
+	if _, err := config.NewConfigManager(); err != nil {
+		check.Status = doctorFail
+		check.Detail = err.Error()
+		check.Remedy = "fix the setting with config edit or config set, or start over with config reset"
+	}
+	checks := []doctorCheck{check}
+
+	project, err := config.LoadProject()
+	switch {
+	case err != nil:
+		checks = append(checks, doctorCheck{
+			Area:   "config",
+			Name:   "project",
+			Status: doctorFail,
+			Detail: err.Error(),
+			Remedy: "fix " + config.ProjectFileName + " in the working directory",
+		})
+	case project != nil:
+		checks = append(checks, doctorCheck{Area: "config", Name: "project", Status: doctorPass, Detail: project.Path()})
+	}
+	return checks
+}
+
+// doctorTemplates loads the templates and reports the files that do not
+func doctorTemplates() []doctorCheck {
+	registry := templates.NewRegistry()
+	registry.LoadTemplates()
+
+	var checks []doctorCheck
+	paths := registry.SearchPaths()
+	check := doctorCheck{Area: "templates", Name: "search paths", Status: doctorPass, Detail: strings.Join(paths, ", ")}
+	if len(paths) == 0 {
+		check.Status = doctorWarn
+		check.Detail = "no template directories found"
+		check.Remedy = "create ~/.netcrate/templates or set NETCRATE_TEMPLATES"
+	}
+	checks = append(checks, check)
+
+	check = doctorCheck{Area: "templates", Name: "index", Status: doctorPass}
+	check.Detail = fmt.Sprintf("%d templates in %s", len(registry.List()), registry.IndexPath())
+	if _, err := os.Stat(registry.IndexPath()); err != nil {
+		check.Status = doctorWarn
+		check.Detail = "index could not be written: " + err.Error()
+		check.Remedy = "make ~/.netcrate/cache writable; templates are reloaded on every run until then"
+	}
+	checks = append(checks, check)
+
+	broken := registry.Broken()
+	files := make([]string, 0, len(broken))
+	for path := range broken {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	for _, path := range files {
+		checks = append(checks, doctorCheck{
+			Area:   "templates",
+			Name:   filepath.Base(path),
+			Status: doctorFail,
+			Detail: fmt.Sprintf("%s: %v", path, broken[path]),
+			Remedy: "fix or remove the file; it is skipped by templates ls and run",
+		})
+	}
+	return checks
+}
+
+// doctorExternalTools looks up the commands netcrate runs on this platform
+func doctorExternalTools() []doctorCheck {
+	var checks []doctorCheck
+	for _, tool := range doctorTools[runtime.GOOS] {
+		check := doctorCheck{Area: "tools", Name: tool.name}
+		path, err := exec.LookPath(tool.name)
+		switch {
+		case err == nil:
+			check.Status = doctorPass
+			check.Detail = path
+		case tool.required:
+			check.Status = doctorFail
+			check.Detail = "not found: needed for " + tool.purpose
+		default:
+			check.Status = doctorWarn
+			check.Detail = "not found: " + tool.purpose + " unavailable"
+		}
+		if err != nil {
+			check.Remedy = tool.install
+			if check.Remedy == "" {
+				check.Remedy = fmt.Sprintf("restore %s to the PATH", tool.name)
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
@@ -0,0 +1,187 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/compliance"
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// dryRunSample is how many resolved addresses a dry run lists
+const dryRunSample = 5
+
+// dryRunPlan is what an ops run would do, shown by --dry-run instead of
+// sending anything
+type dryRunPlan struct {
+	Command     string             `json:"command"`
+	Targets     []string           `json:"targets"`   // as given
+	Addresses   int                `json:"addresses"` // after expansion and exclusions
+	Sample      []string           `json:"sample,omitempty"`
+	Ports       []int              `json:"ports,omitempty"`
+	Template    string             `json:"template,omitempty"`
+	RateProfile string             `json:"rate_profile,omitempty"`
+	Rate        int                `json:"rate,omitempty"`
+	Concurrency int                `json:"concurrency,omitempty"`
+	Timeout     time.Duration      `json:"timeout"`
+	Interval    time.Duration      `json:"interval,omitempty"`
+	Packets     int                `json:"packets"`
+	Duration    time.Duration      `json:"estimated_duration"` // pacing plus one timeout, assuming prompt answers
+	Clamped     []compliance.Clamp `json:"clamped,omitempty"`
+	Compliance  dryRunVerdict      `json:"compliance"`
+}
+
+// dryRunVerdict is what the compliance check would decide
+type dryRunVerdict struct {
+	Status   string   `json:"status"` // allowed or blocked
+	Reason   string   `json:"reason,omitempty"`
+	Policy   string   `json:"policy"`
+	Public   []string `json:"public_targets,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// addDryRunFlag adds --dry-run to an ops command that sends traffic
+func addDryRunFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("dry-run", false, "Show the resolved targets, ports, pacing, estimate and compliance verdict without sending anything")
+}
+
+// pacedDuration is how long probes take at rate per second, plus the
+// timeout of the last one
+func pacedDuration(probes, rate int, timeout time.Duration) time.Duration {
+	if rate <= 0 {
+		return timeout
+	}
+	return time.Duration(float64(probes)/float64(rate)*float64(time.Second)) + timeout
+}
+
+// finishDryRun adds the compliance verdict to a plan, prints it and exits:
+// with exitcode.Blocked when compliance would refuse the run
+func finishDryRun(cmd *cobra.Command, plan dryRunPlan) {
+	dangerous, _ := cmd.Flags().GetBool("dangerous")
+	checker := newComplianceChecker(cmd)
+	checker.Clamped = plan.Clamped
+	result := checker.Preview(plan.Command, complianceTargets(plan.Targets), dangerous)
+	plan.Compliance = dryRunVerdict{
+		Status:   result.Status,
+		Reason:   result.BlockReason,
+		Policy:   result.PolicyLevel,
+		Public:   result.PublicTargets,
+		Warnings: result.Warnings,
+	}
+
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(plan); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(exitcode.Failed)
+		}
+	} else {
+		printDryRun(plan)
+	}
+	if result.Status == compliance.StatusBlocked {
+		os.Exit(exitcode.Blocked)
+	}
+	os.Exit(exitcode.OK)
+}
+
+func printDryRun(plan dryRunPlan) {
+	style.Printf("🧪 Dry run: %s (nothing is sent)\n\n", plan.Command)
+
+	noun := "addresses"
+	if plan.Addresses == 1 {
+		noun = "address"
+	}
+	fmt.Printf("Targets:     %s → %d %s", strings.Join(plan.Targets, ", "), plan.Addresses, noun)
+	if len(plan.Sample) > 0 {
+		sample := strings.Join(plan.Sample, ", ")
+		if plan.Addresses > len(plan.Sample) {
+			sample += ", ..."
+		}
+		fmt.Printf(" (%s)", sample)
+	}
+	fmt.Println()
+	if len(plan.Ports) > 0 {
+		fmt.Printf("Ports:       %s (%d ports)\n", formatPortList(plan.Ports, 12), len(plan.Ports))
+	}
+	if plan.Template != "" {
+		fmt.Printf("Template:    %s\n", plan.Template)
+	}
+
+	pacing := []string{"profile " + valueOrDefault(plan.RateProfile, "none")}
+	if plan.Rate > 0 {
+		pacing = append(pacing, fmt.Sprintf("%d pps", plan.Rate))
+	}
+	if plan.Concurrency > 0 {
+		pacing = append(pacing, fmt.Sprintf("concurrency %d", plan.Concurrency))
+	}
+	if plan.Interval > 0 {
+		pacing = append(pacing, fmt.Sprintf("interval %v", plan.Interval))
+	}
+	pacing = append(pacing, fmt.Sprintf("timeout %v", plan.Timeout))
+	fmt.Printf("Pacing:      %s\n", strings.Join(pacing, " | "))
+	for _, clamp := range plan.Clamped {
+		style.Printf("             ⚖️  %s\n", clamp)
+	}
+	fmt.Printf("Estimate:    %d packets in about %s\n", plan.Packets, formatDryRunDuration(plan.Duration))
+
+	verdict := plan.Compliance
+	if verdict.Status == compliance.StatusBlocked {
+		style.Printf("Compliance:  ❌ blocked (%s policy): %s\n", verdict.Policy, verdict.Reason)
+	} else {
+		style.Printf("Compliance:  ✅ allowed (%s policy)\n", verdict.Policy)
+	}
+	if len(verdict.Public) > 0 {
+		fmt.Printf("             public targets: %s\n", strings.Join(verdict.Public, ", "))
+	}
+	for _, warning := range verdict.Warnings {
+		style.Printf("             ⚠️  %s\n", warning)
+	}
+}
+
+// formatPortList writes ports as ranges, such as 20-23,80,443, showing at
+// most max ranges
+func formatPortList(ports []int, max int) string {
+	sorted := append([]int(nil), ports...)
+	sort.Ints(sorted)
+
+	var ranges []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(sorted[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		}
+		i = j + 1
+	}
+	if len(ranges) > max {
+		return fmt.Sprintf("%s,... (+%d ranges)", strings.Join(ranges[:max], ","), len(ranges)-max)
+	}
+	return strings.Join(ranges, ",")
+}
+
+func formatDryRunDuration(d time.Duration) string {
+	if d < time.Second {
+		return "<1s"
+	}
+	return d.Round(time.Second).String()
+}
+
+// valueOrDefault returns value, or fallback when it is empty
+func valueOrDefault(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// AddStyleFlags adds --no-color and --no-emoji to root and applies them to
+// every command before it runs. Without the flags NO_COLOR and the
+// preferences color_output and emoji_output decide.
+func AddStyleFlags(root *cobra.Command) {
+	root.PersistentFlags().Bool("no-color", false, "Print without ANSI colors (also set by NO_COLOR)")
+	root.PersistentFlags().Bool("no-emoji", false, "Print without emoji; status emoji become [ok], [error] and [warn]")
+	cobra.OnInitialize(func() { configureStyle(root) })
+}
+
+func configureStyle(root *cobra.Command) {
+	noColor, _ := root.PersistentFlags().GetBool("no-color")
+	noEmoji, _ := root.PersistentFlags().GetBool("no-emoji")
+	opts := style.Options{Color: !noColor && !style.NoColorEnv(), Emoji: !noEmoji}
+
+	if cm, err := config.NewConfigManager(); err == nil {
+		prefs := cm.GetConfig().Preferences
+		opts.Color = opts.Color && prefs.ColorOutput
+		opts.Emoji = opts.Emoji && prefs.Emoji()
+	}
+	style.Configure(opts)
+}
@@ -0,0 +1,346 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/netcrate/netcrate/internal/audit"
+	"github.com/netcrate/netcrate/internal/privileges"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// NewPrivilegesCommand creates the privileges command
+func NewPrivilegesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "privileges",
+		Short: "Show and grant the privileges netcrate scans with",
+		Long: `Privileges shows what netcrate may do on this system (raw sockets, ICMP,
+SYN scans) and how to gain what is missing.`,
+	}
+
+	cmd.AddCommand(newPrivilegesStatusCommand())
+	cmd.AddCommand(newPrivilegesCheckCommand())
+	cmd.AddCommand(newPrivilegesGrantCommand())
+	cmd.AddCommand(newPrivilegesHelperCommand())
+
+	return cmd
+}
+
+func newPrivilegesStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the privilege level, capabilities and fallbacks",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			pm := privileges.NewPrivilegeManager()
+			if asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				encoder.Encode(pm.GetPrivilegeSummary())
+				return
+			}
+			pm.PrintPrivilegeStatus()
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Output the status in JSON format")
+
+	return cmd
+}
+
+func newPrivilegesCheckCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check whether a command will run as asked or fall back",
+		Long: `Check reports, for the features a command line needs, which will work,
+which will silently fall back to a slower or weaker method, and what to do
+(setcap, sudo, Npcap) to enable them.
+
+The command line is that of discover, scan ports, netenv or quick, without
+targets mattering; netcrate and ops may be left out.
+
+Examples:
+  netcrate privileges check --for "scan --scan-type syn"
+  netcrate privileges check --for "ops discover 10.0.0.0/24 --methods icmp,arp"
+  netcrate privileges check --for "netenv --neighbors" --json`,
+		Args: cobra.NoArgs,
+		RunE: runPrivilegesCheck,
+	}
+
+	cmd.Flags().String("for", "", "Command line to check, such as \"scan --scan-type syn\" (required)")
+	cmd.Flags().Bool("json", false, "Output in JSON format")
+
+	return cmd
+}
+
+func runPrivilegesCheck(cmd *cobra.Command, args []string) error {
+	spec, _ := cmd.Flags().GetString("for")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	if spec == "" {
+		return fmt.Errorf("--for is required, such as --for \"scan --scan-type syn\"")
+	}
+	features, err := featuresForCommand(spec)
+	if err != nil {
+		return err
+	}
+	checks := privileges.NewPrivilegeManager().CheckFeatures(features)
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Command string                    `json:"command"`
+			Checks  []privileges.FeatureCheck `json:"checks"`
+		}{spec, checks})
+	}
+
+	style.Printf("🔎 Privilege check for: %s\n\n", spec)
+	if len(checks) == 0 {
+		style.Printf("✅ Needs no special privileges\n")
+		return nil
+	}
+	for _, check := range checks {
+		switch check.Status {
+		case privileges.FeatureWorks:
+			style.Printf("✅ %-12s works\n", check.Feature)
+		case privileges.FeatureFallsBack:
+			style.Printf("⚠️  %-12s falls back to %s\n", check.Feature, check.Fallback)
+		default:
+			style.Printf("❌ %-12s unavailable\n", check.Feature)
+		}
+		if check.Reason != "" {
+			fmt.Printf("   %s\n", check.Reason)
+		}
+		if check.Remedy != "" {
+			style.Printf("   💡 %s\n", check.Remedy)
+		}
+	}
+	return nil
+}
+
+// featuresForCommand lists the features a netcrate command line needs,
+// from its command and the flags that choose methods
+func featuresForCommand(spec string) ([]string, error) {
+	var command []string
+	flags := make(map[string]string)
+	fields := strings.Fields(spec)
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if !strings.HasPrefix(field, "-") {
+			if field != "netcrate" && field != "ops" {
+				command = append(command, field)
+			}
+			continue
+		}
+		name := strings.TrimLeft(field, "-")
+		if key, value, ok := strings.Cut(name, "="); ok {
+			flags[key] = value
+		} else if i+1 < len(fields) && !strings.HasPrefix(fields[i+1], "-") {
+			flags[name] = fields[i+1]
+			i++
+		} else {
+			flags[name] = "true"
+		}
+	}
+	if len(command) == 0 {
+		return nil, fmt.Errorf("no command in %q", spec)
+	}
+
+	scanFeature := func(scanType string) string {
+		switch scanType {
+		case "connect":
+			return privileges.FeatureTCPConnect
+		case "udp":
+			return privileges.FeatureUDPScan
+		}
+		return privileges.FeatureSYNScan
+	}
+
+	var features []string
+	switch command[0] {
+	case "discover":
+		methods := "icmp,tcp"
+		if m, ok := flags["methods"]; ok {
+			methods = m
+		}
+		for _, method := range strings.Split(methods, ",") {
+			switch method {
+			case "icmp":
+				features = append(features, privileges.FeatureICMP)
+			case "tcp":
+				features = append(features, privileges.FeatureTCPConnect)
+			case "arp":
+				features = append(features, privileges.FeatureARPCache)
+			}
+		}
+	case "scan":
+		features = append(features, scanFeature(flags["scan-type"]))
+	case "netenv":
+		if flags["neighbors"] == "true" {
+			features = append(features, privileges.FeatureNeighbors)
+		}
+	case "quick":
+		features = append(features, privileges.FeatureICMP, privileges.FeatureTCPConnect, privileges.FeatureSYNScan)
+		if len(command) > 1 && command[1] == "deep" {
+			features = append(features, privileges.FeatureTraceroute)
+		}
+	default:
+		return nil, fmt.Errorf("unknown command %q; check discover, scan, netenv or quick", command[0])
+	}
+	return features, nil
+}
+
+func newPrivilegesGrantCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grant",
+		Short: "Give the netcrate binary raw socket access without root (Linux)",
+		Long: `Grant prints the setcap command that gives the netcrate binary the
+cap_net_raw and cap_net_admin capabilities, so that SYN scans, ICMP discovery
+and packet capture work without running as root. With --apply it runs the
+command, through sudo unless already root.
+
+Anyone who can execute the binary gains these capabilities, so restrict who
+may run it (for example chgrp netcrate and chmod 750). Rebuilding or
+reinstalling the binary clears them.
+
+Examples:
+  netcrate privileges grant
+  netcrate privileges grant --apply`,
+		Args: cobra.NoArgs,
+		RunE: runPrivilegesGrant,
+	}
+
+	cmd.Flags().Bool("apply", false, "Run the setcap command instead of printing it")
+	cmd.Flags().String("binary", "", "Binary to grant to (default: the running netcrate)")
+
+	return cmd
+}
+
+func runPrivilegesGrant(cmd *cobra.Command, args []string) error {
+	apply, _ := cmd.Flags().GetBool("apply")
+	binary, _ := cmd.Flags().GetString("binary")
+
+	if binary == "" {
+		path, err := privileges.BinaryPath()
+		if err != nil {
+			return fmt.Errorf("failed to locate the netcrate binary: %w", err)
+		}
+		binary = path
+	}
+	command, err := privileges.GrantCommand(binary)
+	if err != nil {
+		return err
+	}
+
+	if granted, err := privileges.FileCapabilities(binary); err == nil && len(granted) > 0 {
+		fmt.Printf("Current file capabilities of %s: %s\n", binary, strings.Join(granted, ","))
+	}
+	if strings.HasPrefix(binary, os.TempDir()) {
+		style.Fprintf(os.Stderr, "⚠️  %s is a temporary build (go run?); grant the installed binary instead\n", binary)
+	}
+
+	if !apply {
+		fmt.Printf("Run this to let netcrate use raw sockets without root:\n\n  %s\n\n", strings.Join(command, " "))
+		fmt.Printf("Or run 'netcrate privileges grant --apply' to do it now.\n")
+		return nil
+	}
+
+	fmt.Printf("$ %s\n", strings.Join(command, " "))
+	run := exec.Command(command[0], command[1:]...)
+	run.Stdin, run.Stdout, run.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := run.Run(); err != nil {
+		return fmt.Errorf("setcap failed: %w", err)
+	}
+	if err := audit.Record(audit.Entry{Command: "netcrate privileges grant", Args: command}); err != nil {
+		style.Fprintf(os.Stderr, "⚠️  audit log not written: %v\n", err)
+	}
+
+	granted, err := privileges.FileCapabilities(binary)
+	if err != nil {
+		return fmt.Errorf("failed to read back the file capabilities: %w", err)
+	}
+	style.Printf("✅ %s now has %s\n", binary, strings.Join(granted, ","))
+	return nil
+}
+
+// newPrivilegesHelperCommand is the privileged helper that StartHelper runs
+// through sudo; it is not meant to be started by hand
+func newPrivilegesHelperCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "helper",
+		Short:  "Run the privileged raw socket helper (started by --privileged-helper)",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			socket, _ := cmd.Flags().GetString("socket")
+			if socket == "" {
+				return fmt.Errorf("--socket is required")
+			}
+			return privileges.ServeHelper(socket)
+		},
+	}
+
+	cmd.Flags().String("socket", "", "Unix socket to serve")
+
+	return cmd
+}
+
+// addPrivilegedHelperFlag adds --privileged-helper to a command that sends
+// raw packets
+func addPrivilegedHelperFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("privileged-helper", false, "Send raw packets through a helper started once with sudo, instead of running netcrate as root")
+}
+
+// startPrivilegedHelper starts the helper when --privileged-helper is given
+// and the process cannot open raw sockets itself, returning the function
+// that stops it
+func startPrivilegedHelper(cmd *cobra.Command) func() {
+	if use, _ := cmd.Flags().GetBool("privileged-helper"); !use {
+		return func() {}
+	}
+	if conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0"); err == nil {
+		conn.Close()
+		return func() {}
+	}
+
+	style.Fprintf(os.Stderr, "🔑 Starting the privileged helper (sudo)...\n")
+	helper, err := privileges.StartHelper()
+	if err != nil {
+		style.Fprintf(os.Stderr, "⚠️  Privileged helper unavailable, continuing without it: %v\n", err)
+		return func() {}
+	}
+	privileges.UseHelper(helper)
+	return func() {
+		privileges.UseHelper(nil)
+		helper.Close()
+	}
+}
+
+// addKeepRootFlag adds --keep-root to a command that drops root after
+// opening its raw sockets
+func addKeepRootFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("keep-root", false, "When started with sudo, keep running as root instead of switching to your user once raw sockets are open")
+}
+
+// dropRootPrivileges gives up root at the start of a run started through
+// sudo, keeping the raw sockets it needs, unless --keep-root is given. It
+// runs before anything reads ~/.netcrate, which then is the invoking user's.
+func dropRootPrivileges(cmd *cobra.Command) {
+	if keep, _ := cmd.Flags().GetBool("keep-root"); keep {
+		return
+	}
+	drop, err := privileges.DropPrivileges()
+	if err != nil {
+		style.Fprintf(os.Stderr, "⚠️  Continuing as root: %v\n", err)
+		return
+	}
+	if drop != nil {
+		style.Fprintf(os.Stderr, "🔒 Running as %s (uid %d) with raw sockets opened as root: %s\n", drop.User, drop.ToUID, strings.Join(drop.Preopened, ", "))
+	}
+}
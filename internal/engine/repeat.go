@@ -0,0 +1,241 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/abort"
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// minRepeatInterval keeps --repeat from turning into a flood; it matches the
+// minimum of quick --watch
+const minRepeatInterval = time.Minute
+
+// addRepeatFlags adds --repeat and --repeat-keep to an ops command
+func addRepeatFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("repeat", 0, "Run again on this interval (at least 1m, e.g. 5m) until interrupted, printing what changed")
+	cmd.Flags().Int("repeat-keep", 24, "Saved runs of a --repeat series to keep; older ones are removed (0 keeps all)")
+}
+
+// repeatSeries reruns an ops command every interval. Its runs are tagged
+// repeat-<start time> so output list --tag finds them, and only the newest
+// Keep of them stay in the results directory.
+type repeatSeries struct {
+	Interval time.Duration
+	Keep     int
+	Tag      string
+	JSON     bool
+	Items    string // what a snapshot lists, e.g. "hosts up"
+}
+
+// repeatSnapshot is what one run found: items keyed by host, host:port or
+// target, each with a short description
+type repeatSnapshot struct {
+	RunID string
+	Items map[string]string
+}
+
+// repeatDelta is what changed between two runs of a series; the first run
+// of a series is compared with nothing, so everything in it is added
+type repeatDelta struct {
+	Iteration     int                  `json:"iteration"`
+	Time          time.Time            `json:"time"`
+	RunID         string               `json:"run_id"`
+	PreviousRunID string               `json:"previous_run_id,omitempty"`
+	Total         int                  `json:"total"`
+	Added         map[string]string    `json:"added,omitempty"`
+	Removed       map[string]string    `json:"removed,omitempty"`
+	Changed       map[string][2]string `json:"changed,omitempty"` // before, after
+}
+
+// newRepeatSeries reads --repeat, returning nil when it is not given. The
+// series tag is added to labels so every saved run carries it.
+func newRepeatSeries(cmd *cobra.Command, items string, labels *store.Labels) *repeatSeries {
+	interval, _ := cmd.Flags().GetDuration("repeat")
+	if interval == 0 {
+		return nil
+	}
+	if interval < minRepeatInterval {
+		fmt.Fprintf(os.Stderr, "Error: --repeat must be at least %v, got %v\n", minRepeatInterval, interval)
+		os.Exit(exitcode.Usage)
+	}
+	keep, _ := cmd.Flags().GetInt("repeat-keep")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	series := &repeatSeries{
+		Interval: interval,
+		Keep:     keep,
+		Tag:      "repeat-" + time.Now().Format("20060102-150405"),
+		JSON:     jsonOutput,
+		Items:    items,
+	}
+	labels.Tags = append(labels.Tags, series.Tag)
+	return series
+}
+
+// Run calls once until interrupted, waiting Interval between the runs, and
+// prints what changed after each. once is told whether it is the first run,
+// whose full results it prints unless the output is JSON; with --json every
+// run prints its delta as one JSON line instead.
+func (r *repeatSeries) Run(once func(first bool) repeatSnapshot) {
+	style.Fprintf(os.Stderr, "🔁 Repeating every %v until interrupted (runs tagged %s)\n\n", r.Interval, r.Tag)
+
+	var previous repeatSnapshot
+	for iteration := 1; ; iteration++ {
+		current := once(iteration == 1)
+		delta := diffSnapshots(previous, current)
+		delta.Iteration = iteration
+		delta.Time = time.Now()
+
+		if r.JSON {
+			json.NewEncoder(os.Stdout).Encode(delta)
+		} else if iteration > 1 {
+			r.printDelta(delta)
+		}
+		if r.Keep > 0 {
+			if removed, err := store.PruneTagged(r.Tag, r.Keep); err != nil {
+				style.Fprintf(os.Stderr, "⚠️  Failed to prune the runs of %s: %v\n", r.Tag, err)
+			} else if removed > 0 {
+				style.Fprintf(os.Stderr, "🧹 Removed %d older runs of %s (keeping %d)\n", removed, r.Tag, r.Keep)
+			}
+		}
+		previous = current
+
+		// Interrupts only stop the series between runs; during a run they
+		// behave as they do without --repeat
+		style.Fprintf(os.Stderr, "⏳ Next run at %s (Ctrl+C to stop)\n", time.Now().Add(r.Interval).Format("15:04:05"))
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		select {
+		case <-sigChan:
+			signal.Stop(sigChan)
+			style.Fprintf(os.Stderr, "\n⏹️  Stopped after %d runs\n", iteration)
+			return
+		case <-abort.Context().Done():
+			signal.Stop(sigChan)
+			return
+		case <-time.After(r.Interval):
+			signal.Stop(sigChan)
+		}
+	}
+}
+
+func (r *repeatSeries) printDelta(delta repeatDelta) {
+	changes := len(delta.Added) + len(delta.Removed) + len(delta.Changed)
+	style.Printf("\n🔁 Run %d at %s (%s): ", delta.Iteration, delta.Time.Format("15:04:05"), delta.RunID)
+	if changes == 0 {
+		fmt.Printf("no changes, %d %s\n", delta.Total, r.Items)
+		return
+	}
+	fmt.Printf("%d changes since %s, %d %s\n", changes, delta.PreviousRunID, delta.Total, r.Items)
+	for _, key := range sortedKeys(delta.Added) {
+		fmt.Printf("  + %-24s %s\n", key, delta.Added[key])
+	}
+	for _, key := range sortedKeys(delta.Removed) {
+		fmt.Printf("  - %-24s %s\n", key, delta.Removed[key])
+	}
+	changed := make([]string, 0, len(delta.Changed))
+	for key := range delta.Changed {
+		changed = append(changed, key)
+	}
+	sort.Strings(changed)
+	for _, key := range changed {
+		fmt.Printf("  ~ %-24s %s → %s\n", key, delta.Changed[key][0], delta.Changed[key][1])
+	}
+}
+
+// diffSnapshots compares two runs item by item
+func diffSnapshots(previous, current repeatSnapshot) repeatDelta {
+	delta := repeatDelta{
+		RunID:         current.RunID,
+		PreviousRunID: previous.RunID,
+		Total:         len(current.Items),
+		Added:         make(map[string]string),
+		Removed:       make(map[string]string),
+		Changed:       make(map[string][2]string),
+	}
+	for key, value := range current.Items {
+		before, ok := previous.Items[key]
+		switch {
+		case !ok:
+			delta.Added[key] = value
+		case before != value:
+			delta.Changed[key] = [2]string{before, value}
+		}
+	}
+	for key, value := range previous.Items {
+		if _, ok := current.Items[key]; !ok {
+			delta.Removed[key] = value
+		}
+	}
+	return delta
+}
+
+func sortedKeys(items map[string]string) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// discoverSnapshot lists the hosts that answered, with the method that found them
+func discoverSnapshot(result *ops.DiscoverSummary) repeatSnapshot {
+	snapshot := repeatSnapshot{RunID: result.RunID, Items: make(map[string]string)}
+	for _, r := range result.Results {
+		if r.Status == "up" {
+			snapshot.Items[r.Host] = "up via " + r.Method
+		}
+	}
+	return snapshot
+}
+
+// scanSnapshot lists the open ports, with the service found on them
+func scanSnapshot(result *ops.ScanSummary) repeatSnapshot {
+	snapshot := repeatSnapshot{RunID: result.RunID, Items: make(map[string]string)}
+	for _, r := range result.Results {
+		if r.Status != "open" {
+			continue
+		}
+		service := "unknown"
+		if r.Service != nil && r.Service.Name != "" {
+			service = r.Service.Name
+			if r.Service.Version != "" {
+				service += " " + r.Service.Version
+			}
+		}
+		protocol := r.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		snapshot.Items[fmt.Sprintf("%s:%d/%s", r.Host, r.Port, protocol)] = service
+	}
+	return snapshot
+}
+
+// packetSnapshot lists the outcome of each target: its status, and the
+// response status code when there is one. With --count above 1 the last
+// packet to a target decides.
+func packetSnapshot(result *ops.PacketSummary) repeatSnapshot {
+	snapshot := repeatSnapshot{RunID: result.RunID, Items: make(map[string]string)}
+	for _, r := range result.Results {
+		outcome := r.Status
+		if r.Response != nil && r.Response.StatusCode != 0 {
+			outcome += " " + strconv.Itoa(r.Response.StatusCode)
+		}
+		snapshot.Items[r.Target] = outcome
+	}
+	return snapshot
+}
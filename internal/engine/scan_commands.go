@@ -0,0 +1,341 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/audit"
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/netenv"
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/quick"
+	"github.com/netcrate/netcrate/internal/runid"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// NewScanCommand creates the scan command, which chains discovery, a port
+// scan of the live hosts and fingerprinting of their open ports
+func NewScanCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scan [targets...|auto]",
+		Short: "Discover, port scan and fingerprint targets in one run",
+		Long: `Scan finds the live hosts among the targets, scans their ports and
+fingerprints the services on the open ones, with the options of the ops
+commands and the summary of quick mode.
+
+Targets are detected from what is given:
+
+  (none) or auto      the network of the interface (--interface, else the
+                      recommended one)
+  192.168.1.0/24      a network: only the hosts discovery finds are scanned
+  192.168.1.10-50     a range: as a network
+  192.168.1.5, host   a single host: scanned even when it does not answer
+                      discovery, since many hosts drop pings
+
+The run goes through the compliance policy like the ops commands, is saved
+as a quick run for output show, report and quick deep, and exits with the
+status of the ops commands.
+
+Examples:
+  netcrate scan                                   # The local network
+  netcrate scan 10.0.0.0/24 10.0.1.5              # A network and a host
+  netcrate scan 192.168.1.10 --ports top1000      # One host, more ports
+  netcrate scan --profile fast --fingerprint=false
+  netcrate scan 10.0.0.0/24 --json --fail-on high`,
+		Run: runScan,
+	}
+
+	cmd.Flags().Bool("json", false, "Output in JSON format")
+	cmd.Flags().String("interface", "", "Network interface (default: the recommended one, or the one routing the targets)")
+	cmd.RegisterFlagCompletionFunc("interface", completeInterfaces)
+	cmd.Flags().StringSlice("methods", []string{"icmp", "tcp"}, "Discovery methods (icmp,tcp,arp)")
+	cmd.Flags().String("ports", "top100", "Ports to scan on live hosts (top100,top1000,web,database,custom)")
+	cmd.RegisterFlagCompletionFunc("ports", completePortSets)
+	cmd.Flags().String("scan-type", "auto", "Scan type (connect,syn,udp,auto)")
+	cmd.Flags().Bool("fingerprint", true, "Fingerprint services on open ports (application, version, TLS)")
+	cmd.Flags().Int("rate", 100, "Packets per second")
+	cmd.Flags().Duration("timeout", 800*time.Millisecond, "Timeout per probe")
+	cmd.Flags().Int("concurrency", 200, "Maximum concurrent operations")
+	cmd.Flags().String("profile", "", "Rate profile for this run, sets the pacing flags that are not given (default: the current profile, see config rate list)")
+	cmd.Flags().Int("retries", 1, "Retry count for failed connections")
+	cmd.Flags().Bool("dangerous", false, "Allow scanning of public networks")
+	cmd.Flags().String("nmap-xml", "", "Also write results as nmap XML to this file")
+	addFailOnFlag(cmd)
+	addActionMenuFlag(cmd)
+	addResultSinkFlags(cmd)
+	addRunLabelFlags(cmd)
+	addSaveFlag(cmd)
+	addTransportFlags(cmd)
+	addAuthorizationFlags(cmd)
+	addPrivilegedHelperFlag(cmd)
+	addKeepRootFlag(cmd)
+
+	return cmd
+}
+
+func runScan(cmd *cobra.Command, args []string) {
+	dropRootPrivileges(cmd)
+
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	iface, _ := cmd.Flags().GetString("interface")
+	methods, _ := cmd.Flags().GetStringSlice("methods")
+	portsSpec, _ := cmd.Flags().GetString("ports")
+	scanType, _ := cmd.Flags().GetString("scan-type")
+	fingerprint, _ := cmd.Flags().GetBool("fingerprint")
+	rate, _ := cmd.Flags().GetInt("rate")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	retries, _ := cmd.Flags().GetInt("retries")
+	labels := runLabelsFromFlags(cmd)
+	failOn := failOnThreshold(cmd)
+
+	// Explicit targets go out through the interface the system routes them to
+	if iface == "" && len(args) > 0 && args[0] != "auto" {
+		iface = netenv.RouteInterface(args[0])
+	}
+	targets, hosts, selected, err := scanTargets(args, iface)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
+	if selected != nil {
+		iface = selected.Name
+	}
+
+	network := currentNetworkSettings(iface)
+	project := currentProject()
+	profile, err := applyRateProfile(cmd, network, rateOptions{Rate: &rate, Concurrency: &concurrency, Timeout: &timeout, Retries: &retries})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
+	ports, err := ops.ParsePortSpec(portsSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing ports '%s': %v\n", portsSpec, err)
+		os.Exit(exitcode.Usage)
+	}
+	clamps := applyComplianceCeilings(rateOptions{Rate: &rate, Concurrency: &concurrency}, &ports)
+	checker := checkOpsCompliance(cmd, "netcrate scan", targets, audit.Budget{
+		Profile:     profile.Name,
+		Rate:        rate,
+		Concurrency: concurrency,
+		Ports:       len(ports),
+	}, clamps)
+
+	stopHelper := startPrivilegedHelper(cmd)
+	defer stopHelper()
+
+	exclude := append(network.DoNotScan, project.Scope.Exclude...)
+	discoverOpts := ops.DiscoverOptions{
+		Targets:          targets,
+		Methods:          methods,
+		Interface:        iface,
+		Rate:             rate,
+		Timeout:          timeout,
+		Concurrency:      concurrency,
+		TCPPorts:         []int{22, 80, 443},
+		ResolveHostnames: true,
+		Exclude:          exclude,
+		Scope:            project.Scope.Allowed,
+		Classify:         complianceClassifier(checker),
+	}
+	scanOpts := ops.ScanOptions{
+		Ports:            ports,
+		ScanType:         scanType,
+		ServiceDetection: true,
+		Rate:             rate,
+		Timeout:          timeout,
+		Concurrency:      concurrency,
+		RetryCount:       retries,
+		MaxPerHost:       profile.MaxPerHost,
+		Exclude:          exclude,
+		Scope:            project.Scope.Allowed,
+		Classify:         discoverOpts.Classify,
+	}
+
+	sink := openResultSink(cmd)
+	if sink != nil {
+		discoverOpts.OnResult = func(result ops.DiscoverResult) { sink.Write("discover.result", result) }
+		scanOpts.OnResult = func(result ops.ScanResult) { sink.Write("scan.result", result) }
+	}
+
+	startTime := time.Now()
+	result := &quick.QuickResult{
+		RunID:       runid.NewAt("quick", startTime),
+		Interface:   selected,
+		TargetCIDR:  strings.Join(targets, ","),
+		TargetCIDRs: targets,
+		PortSet:     portsSpec,
+		Profile:     profile.Name,
+		Fingerprint: fingerprint,
+		Excludes:    exclude,
+		Command:     "scan",
+		Labels:      labels,
+		StartTime:   startTime,
+	}
+	result.Context = store.NewRunContext(iface, struct {
+		Discover    ops.DiscoverOptions `json:"discover"`
+		Scan        ops.ScanOptions     `json:"scan"`
+		Fingerprint bool                `json:"fingerprint"`
+	}{discoverOpts, scanOpts, fingerprint})
+	result.Context.Clamped = clamps
+	result.Context.Authorization = runAuthorization(cmd)
+
+	style.Fprintf(os.Stderr, "🔍 Discovering hosts...\n")
+	fmt.Fprintf(os.Stderr, "Targets: %s\n", strings.Join(targets, ", "))
+	fmt.Fprintf(os.Stderr, "Methods: %s | Rate: %d pps | Concurrency: %d | Timeout: %v\n\n", strings.Join(methods, ","), rate, concurrency, timeout)
+	discovered, err := ops.EnhancedDiscover(ops.DiscoverEnhancedOptions{
+		DiscoverOptions:      discoverOpts,
+		EnableTargetPruning:  true,
+		EnableAdaptiveRate:   true,
+		HighLossThreshold:    0.3,
+		DownshiftStep:        0.2,
+		UpshiftStep:          0.1,
+		GoodWindowsToUpshift: 3,
+		NoSampling:           true,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error during discovery: %v\n", err)
+		os.Exit(exitcode.Failed)
+	}
+	result.DiscoverResult = discovered.DiscoverSummary
+	style.Fprintf(os.Stderr, "✅ %d hosts up (%.1fs)\n\n", discovered.HostsDiscovered, discovered.Duration)
+
+	// Live hosts, then the single hosts discovery did not see
+	seen := make(map[string]bool)
+	for _, host := range discovered.Results {
+		if host.Status == "up" && !seen[host.Host] {
+			seen[host.Host] = true
+			scanOpts.Targets = append(scanOpts.Targets, host.Host)
+		}
+	}
+	for _, host := range hosts {
+		if !seen[host] {
+			seen[host] = true
+			scanOpts.Targets = append(scanOpts.Targets, host)
+		}
+	}
+
+	if len(scanOpts.Targets) > 0 {
+		style.Fprintf(os.Stderr, "🔌 Scanning %d ports on %d hosts (%s)...\n", len(ports), len(scanOpts.Targets), scanType)
+		scanned, err := ops.ScanPorts(scanOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error during port scan: %v\n", err)
+			os.Exit(exitcode.Failed)
+		}
+		result.ScanResult = scanned
+		style.Fprintf(os.Stderr, "✅ %d open ports (%.1fs)\n\n", scanned.OpenPorts, scanned.Duration)
+
+		if fingerprint && scanned.OpenPorts > 0 {
+			style.Fprintf(os.Stderr, "🧬 Fingerprinting open ports...\n")
+			result.Fingerprints = quick.FingerprintOpenPorts(scanned)
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "No live hosts to scan\n")
+	}
+
+	quick.Summarize(result)
+	result.Status = "complete"
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(startTime).Seconds()
+
+	saveOpsRun(cmd, result.RunID, func() error {
+		return quick.SaveResult(result)
+	})
+	if sink != nil {
+		closeResultSink(sink, "quick.summary", &result.Summary)
+	}
+
+	if nmapXML, _ := cmd.Flags().GetString("nmap-xml"); nmapXML != "" {
+		if err := output.SaveNmapXML(nmapXML, output.BuildNmapRun(result.DiscoverResult, result.ScanResult)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing nmap XML: %v\n", err)
+			os.Exit(exitcode.Failed)
+		}
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(exitcode.Failed)
+		}
+	} else {
+		quick.PrintQuickSummary(result)
+		offerActionMenu(cmd, finishedScan{RunID: result.RunID, Scan: result.ScanResult, Fingerprinted: len(result.Fingerprints) > 0})
+	}
+	exitForRun(failOn, result.DiscoverResult, result.ScanResult, clamps)
+}
+
+// scanTargets detects what the scan command was given. With no targets or
+// "auto" it scans the network of the interface named iface, else of the
+// recommended one, and returns that interface. Otherwise CIDRs and ranges
+// are networks, and IPs and hostnames single hosts, which are also returned
+// in hosts.
+func scanTargets(args []string, iface string) (targets, hosts []string, selected *netenv.NetworkInterface, err error) {
+	auto := isAutoTargets(args)
+	if auto && iface == "" {
+		env, err := netenv.DetectNetworkEnvironment()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		iface = env.Recommended
+	}
+	if iface != "" {
+		interfaces, err := netenv.GetActiveInterfaces()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for i := range interfaces {
+			if interfaces[i].Name == iface {
+				selected = &interfaces[i]
+			}
+		}
+	}
+
+	if auto {
+		if selected == nil || len(selected.Addresses) == 0 {
+			return nil, nil, nil, fmt.Errorf("interface %q is not up or has no IPv4 address; give the targets to scan", iface)
+		}
+		_, ipnet, err := net.ParseCIDR(selected.Addresses[0].Network)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to parse network of %s: %w", selected.Name, err)
+		}
+		style.Fprintf(os.Stderr, "🎯 Targets: %s (network of %s)\n", ipnet, selected.Name)
+		return []string{ipnet.String()}, nil, selected, nil
+	}
+
+	for _, arg := range args {
+		for _, target := range strings.Split(arg, ",") {
+			target = strings.TrimSpace(target)
+			switch {
+			case target == "":
+				continue
+			case target == "auto":
+				return nil, nil, nil, fmt.Errorf("auto cannot be combined with other targets")
+			case strings.Contains(target, "/"), strings.Contains(target, "-") && net.ParseIP(strings.SplitN(target, "-", 2)[0]) != nil:
+				targets = append(targets, target)
+			default:
+				targets = append(targets, target)
+				hosts = append(hosts, target)
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return nil, nil, nil, fmt.Errorf("no targets specified")
+	}
+	return targets, hosts, selected, nil
+}
+
+// isAutoTargets reports whether the scan command was asked to detect its
+// targets
+func isAutoTargets(args []string) bool {
+	return len(args) == 0 || (len(args) == 1 && args[0] == "auto")
+}
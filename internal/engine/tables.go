@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"os"
+
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/output"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/spf13/cobra"
+)
+
+// Columns printed by default in the terminal tables of live and saved runs;
+// --columns picks any of output.HostColumns or output.PortColumns instead
+var (
+	discoverTableColumns = []string{"host", "status", "rtt_ms", "method", "hostname"}
+	scanTableColumns     = []string{"host", "port", "status", "rtt_ms", "service", "version"}
+)
+
+// addTableFlags adds the flags controlling how result tables are printed.
+// Commands with their own --sort leave out the column sort.
+func addTableFlags(cmd *cobra.Command, withSort bool) {
+	cmd.Flags().StringSlice("columns", []string{}, "Columns to print, in order, e.g. host,port,service (an unknown name lists the available ones)")
+	if withSort {
+		cmd.Flags().StringSlice("sort", []string{}, "Sort rows by these columns; prefix one with '-' for descending, e.g. -rtt_ms")
+	}
+	cmd.Flags().Bool("no-truncate", false, "Print long cells in full instead of cutting them off")
+	cmd.Flags().Bool("no-pager", false, "Never page long output through $PAGER")
+}
+
+// tableOptions reads the table flags; defaults are the columns printed when
+// --columns is not given and available the columns the table has. Unknown
+// columns exit with exitcode.Usage before anything runs.
+func tableOptions(cmd *cobra.Command, defaults, available []string) output.TableOptions {
+	opts := output.TableOptions{Columns: defaults}
+	if columns, _ := cmd.Flags().GetStringSlice("columns"); len(columns) > 0 {
+		opts.Columns = columns
+	}
+	// Fails, leaving Sort empty, on commands whose --sort is not a column list
+	opts.Sort, _ = cmd.Flags().GetStringSlice("sort")
+	opts.NoTruncate, _ = cmd.Flags().GetBool("no-truncate")
+	opts.NoPager, _ = cmd.Flags().GetBool("no-pager")
+
+	if _, err := (&output.Table{Columns: available}).View(opts); err != nil {
+		style.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
+	return opts
+}
@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/audit"
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/logging"
+	"github.com/netcrate/netcrate/internal/netenv"
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// NewTUICommand creates the interactive dashboard command
+func NewTUICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Interactive dashboard for discovery, scans and follow-up actions",
+		Long: `Tui opens a full-screen dashboard: pick an interface and targets, watch
+discovery and the port scan of the live hosts as results come in, then browse
+the hosts and fingerprint or deep scan one of them with a key press.
+
+Every action goes through the compliance policy like the ops commands do;
+when it asks for a confirmation the dashboard hands the terminal back for the
+prompt.`,
+		Args: cobra.NoArgs,
+		Run:  runTUI,
+	}
+
+	cmd.Flags().String("interface", "", "Interface selected at start (default: the recommended one)")
+	cmd.Flags().String("ports", "top100", "Ports scanned on every live host (top100,top1000,web,database,custom)")
+	cmd.RegisterFlagCompletionFunc("interface", completeInterfaces)
+	cmd.RegisterFlagCompletionFunc("ports", completePortSets)
+	cmd.Flags().String("scan-type", "auto", "Scan type (connect,syn,auto)")
+	cmd.Flags().Int("rate", 100, "Packets per second")
+	cmd.Flags().Duration("timeout", 800*time.Millisecond, "Timeout per probe")
+	cmd.Flags().Int("concurrency", 200, "Maximum concurrent operations")
+	cmd.Flags().String("profile", "", "Rate profile for this session, sets the pacing flags that are not given (default: the current profile, see config rate list)")
+	cmd.Flags().Bool("dangerous", false, "Allow scanning of public networks")
+	addRunLabelFlags(cmd)
+	addSaveFlag(cmd)
+	addTransportFlags(cmd)
+	addAuthorizationFlags(cmd)
+	addPrivilegedHelperFlag(cmd)
+	addKeepRootFlag(cmd)
+
+	return cmd
+}
+
+func runTUI(cmd *cobra.Command, args []string) {
+	dropRootPrivileges(cmd)
+
+	iface, _ := cmd.Flags().GetString("interface")
+	portsSpec, _ := cmd.Flags().GetString("ports")
+	scanType, _ := cmd.Flags().GetString("scan-type")
+	rate, _ := cmd.Flags().GetInt("rate")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	dangerous, _ := cmd.Flags().GetBool("dangerous")
+	save, _ := cmd.Flags().GetBool("save")
+	labels := runLabelsFromFlags(cmd)
+
+	env, err := netenv.DetectNetworkEnvironment()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Failed)
+	}
+	if iface == "" {
+		iface = env.Recommended
+	}
+
+	network := currentNetworkSettings(iface)
+	project := currentProject()
+	profile, err := applyRateProfile(cmd, network, rateOptions{Rate: &rate, Concurrency: &concurrency, Timeout: &timeout})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
+	ports, err := ops.ParsePortSpec(portsSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing ports '%s': %v\n", portsSpec, err)
+		os.Exit(exitcode.Usage)
+	}
+	clamps := applyComplianceCeilings(rateOptions{Rate: &rate, Concurrency: &concurrency}, &ports)
+
+	stopHelper := startPrivilegedHelper(cmd)
+	defer stopHelper()
+
+	opts := tui.Options{
+		Interfaces:  env.Interfaces,
+		Recommended: iface,
+		Ports:       ports,
+		ScanType:    scanType,
+		Rate:        rate,
+		Concurrency: concurrency,
+		Timeout:     timeout,
+		Exclude:     append(network.DoNotScan, project.Scope.Exclude...),
+		Scope:       project.Scope.Allowed,
+
+		// Each action is checked and audited like the ops command it runs
+		Authorize: func(command string, targets []string, probed int) (tui.Authorization, error) {
+			checker := newComplianceChecker(cmd)
+			checker.Budget = &audit.Budget{
+				Profile:     profile.Name,
+				Rate:        rate,
+				Concurrency: concurrency,
+				Ports:       probed,
+			}
+			checker.Clamped = clamps
+
+			sessionID := fmt.Sprintf("tui-%d", time.Now().Unix())
+			result, err := checker.CheckCompliance(sessionID, "tui", command, targets, dangerous)
+			if err != nil {
+				return tui.Authorization{}, err
+			}
+			return tui.Authorization{
+				Warnings: result.Warnings,
+				Classify: complianceClassifier(checker),
+			}, nil
+		},
+	}
+
+	if save {
+		context := func(iface string, options interface{}) *store.RunContext {
+			runContext := store.NewRunContext(iface, options)
+			runContext.Clamped = clamps
+			runContext.Authorization = runAuthorization(cmd)
+			return runContext
+		}
+		opts.SaveDiscover = func(summary *ops.DiscoverSummary, options ops.DiscoverOptions) error {
+			return output.SaveDiscoverRun(summary, options.Targets, labels, context(options.Interface, options))
+		}
+		opts.SaveScan = func(summary *ops.ScanSummary, options ops.ScanOptions) error {
+			return output.SaveScanRun(summary, options.Targets, labels, context("", options))
+		}
+	}
+
+	// The dashboard owns the terminal; persisted run logs still get the records
+	logging.Configure(logging.Options{Output: io.Discard})
+	if err := tui.Run(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Failed)
+	}
+}
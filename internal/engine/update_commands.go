@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/style"
+	"github.com/netcrate/netcrate/internal/update"
+	"github.com/netcrate/netcrate/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// NewSelfUpdateCommand creates the command that updates netcrate in place
+func NewSelfUpdateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Update netcrate to the latest release",
+		Long: `Self-update looks up the latest release on GitHub and, when it is newer
+than this one, downloads the archive for this platform and replaces the
+running binary with the netcrate in it.
+
+Nothing is installed unless the release's checksums.txt carries a valid
+signature of the release key built into netcrate and the archive matches
+its checksum. Homebrew installs are updated with "brew upgrade netcrate".
+
+Set updates.disabled (config set updates_disabled true) on air-gapped hosts:
+self-update then never contacts GitHub. The proxy settings apply to the
+download.
+
+Examples:
+  netcrate self-update --check
+  netcrate self-update
+  sudo netcrate self-update --yes   # when the binary is owned by root`,
+		Args: cobra.NoArgs,
+		Run:  runSelfUpdate,
+	}
+
+	cmd.Flags().Bool("check", false, "Only report whether a newer release exists")
+	cmd.Flags().BoolP("yes", "y", false, "Update without asking for confirmation")
+	cmd.Flags().String("proxy", "", "Proxy for the download, or \"direct\" (default from proxy.url)")
+
+	return cmd
+}
+
+func runSelfUpdate(cmd *cobra.Command, args []string) {
+	check, _ := cmd.Flags().GetBool("check")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.Usage)
+	}
+	updates := cm.GetConfig().Updates
+	if updates.Disabled {
+		style.Fprintf(os.Stderr, "❌ Update checks are disabled (updates.disabled); install new releases by hand\n")
+		os.Exit(exitcode.Usage)
+	}
+
+	client := update.NewClient(updates.Repository)
+	release, err := client.Latest()
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(exitcode.Failed)
+	}
+
+	current := version.Version
+	if !update.Newer(current, release.Version()) {
+		style.Printf("✅ NetCrate %s is the latest release\n", current)
+		return
+	}
+	style.Printf("🆕 NetCrate %s is available, this is %s\n", release.Version(), current)
+	if release.URL != "" {
+		fmt.Printf("   Release notes: %s\n", release.URL)
+	}
+	if check {
+		return
+	}
+
+	exe, err := update.Executable()
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ Cannot find the netcrate binary: %v\n", err)
+		os.Exit(exitcode.Failed)
+	}
+	if strings.Contains(exe, "/Cellar/") {
+		style.Fprintf(os.Stderr, "❌ %s is managed by Homebrew; update it with: brew upgrade netcrate\n", exe)
+		os.Exit(exitcode.Usage)
+	}
+
+	if !yes {
+		fmt.Printf("Replace %s with NetCrate %s? [y/N] ", exe, release.Version())
+		answer := ""
+		scanner := bufio.NewScanner(os.Stdin)
+		if scanner.Scan() {
+			answer = strings.ToLower(strings.TrimSpace(scanner.Text()))
+		}
+		if answer != "y" && answer != "yes" {
+			fmt.Println("Update cancelled.")
+			return
+		}
+	}
+
+	style.Fprintf(os.Stderr, "⬇️  Downloading %s...\n", update.ArchiveName(release.Version()))
+	binary, err := client.Fetch(release)
+	if err != nil {
+		style.Fprintf(os.Stderr, "❌ Update refused: %v\n", err)
+		os.Exit(exitcode.Failed)
+	}
+	if err := update.Install(exe, binary); err != nil {
+		style.Fprintf(os.Stderr, "❌ Failed to replace %s: %v\n", exe, err)
+		if os.IsPermission(err) {
+			fmt.Fprintf(os.Stderr, "   Run the update as the owner of the binary, e.g. with sudo\n")
+		}
+		os.Exit(exitcode.Failed)
+	}
+	style.Printf("✅ Updated %s to NetCrate %s (signature and checksum verified)\n", exe, release.Version())
+}
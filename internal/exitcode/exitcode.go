@@ -0,0 +1,28 @@
+// Package exitcode is the exit status contract of the quick, scan, ops,
+// templates and output commands, so that scripts can tell a refused run from
+// a failed one and a clean result from one with gaps:
+//
+//	0   the command succeeded
+//	1   usage: invalid flags, arguments or configuration, or an unknown run
+//	    or template
+//	2   the operation failed, or its results could not be written
+//	3   compliance refused the run: scope, blocklist, policy level, scan
+//	    window, budget, a declined confirmation or the kill switch
+//	4   the run finished with partial results: some probes or packets
+//	    failed, or a budget ceiling cut the ports per host
+//	5   a check failed: --fail-on found an open port at or above its risk,
+//	    audit verify found a tampered log or doctor found a problem
+//	130 the run was interrupted or stopped by the kill switch
+//
+// Code 4 never hides a failure: a run that failed outright exits with 2.
+package exitcode
+
+const (
+	OK          = 0
+	Usage       = 1
+	Failed      = 2
+	Blocked     = 3
+	Partial     = 4
+	Policy      = 5
+	Interrupted = 130
+)
@@ -0,0 +1,94 @@
+// Package i18n provides the message catalog for user-facing strings
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/style"
+)
+
+// DefaultLanguage is used when no language is configured
+const DefaultLanguage = "en"
+
+var (
+	mu       sync.RWMutex
+	language string
+	loadOnce sync.Once
+)
+
+// catalogs maps language codes to message catalogs
+var catalogs = map[string]map[string]string{
+	"en":    messagesEN,
+	"zh-CN": messagesZhCN,
+}
+
+// SupportedLanguages returns the available language codes
+func SupportedLanguages() []string {
+	return []string{"en", "zh-CN"}
+}
+
+// SetLanguage selects the active catalog. Unknown languages fall back to English.
+func SetLanguage(lang string) {
+	loadOnce.Do(func() {})
+
+	mu.Lock()
+	defer mu.Unlock()
+	language = normalizeLanguage(lang)
+}
+
+// Language returns the active language code
+func Language() string {
+	loadOnce.Do(loadLanguage)
+
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T returns the message for key in the active language, formatted with
+// args and styled for output (see style.Text)
+func T(key string, args ...interface{}) string {
+	lang := Language()
+
+	msg, ok := catalogs[lang][key]
+	if !ok {
+		msg, ok = catalogs[DefaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) == 0 {
+		return style.Text(msg)
+	}
+	return style.Text(fmt.Sprintf(msg, args...))
+}
+
+// loadLanguage reads the language from NETCRATE_LANG or the config file
+func loadLanguage() {
+	lang := os.Getenv("NETCRATE_LANG")
+	if lang == "" {
+		if cm, err := config.NewConfigManager(); err == nil {
+			lang = cm.GetConfig().Preferences.Language
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	language = normalizeLanguage(lang)
+}
+
+// normalizeLanguage maps language tags such as "zh", "zh_CN" or "zh-cn" to a catalog key
+func normalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+	switch {
+	case strings.HasPrefix(lang, "zh"):
+		return "zh-CN"
+	default:
+		return DefaultLanguage
+	}
+}
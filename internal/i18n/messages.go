@@ -0,0 +1,487 @@
+package i18n
+
+// messagesEN is the English catalog and the fallback for missing keys
+var messagesEN = map[string]string{
+	"quick.step.detect_interface":     "\n[1/4] 🔍 Detecting network interface...",
+	"quick.step.target_network":       "\n[2/4] 🎯 Calculating target network...",
+	"quick.step.configure":            "\n[2.5/4] ⚙️ Scan configuration",
+	"quick.step.confirm":              "\n[3/4] ⚙️ Confirm configuration",
+	"quick.step.execute":              "\n[4/4] 🔍 Running scan pipeline...",
+	"quick.cancelled":                 "\n❌ Cancelled by user",
+	"quick.dry_run":                   "🧪 [DRY RUN] Skipping execution",
+	"quick.save_failed":               "⚠️ Failed to save results: %v\n",
+	"quick.iface.not_found":           "network interface not found: %s",
+	"quick.iface.down":                "network interface %s is not up",
+	"quick.iface.forced":              "✅ Using interface: %s (%s)\n",
+	"quick.iface.routed":              "✅ Using interface: %s (%s), the route to %s\n",
+	"quick.iface.overlay":             "⚠️  %s is a %s tunnel: %s reaches remote networks and infrastructure through it, not a local LAN. Make sure the whole range is in scope.\n",
+	"quick.iface.virtual":             "⚠️  %s is a %s network on this host: %s only holds local containers or virtual machines\n",
+	"quick.iface.ip":                  "   IP address: %s\n",
+	"quick.iface.none":                "no usable network interface detected",
+	"quick.iface.selected":            "✅ Selected interface: %s (%s)\n",
+	"quick.iface.multiple":            "\n📡 Multiple private interfaces detected:",
+	"quick.iface.no_gateway":          "no gateway",
+	"quick.iface.gateway_rtt":         "gateway %s (%.1fms)",
+	"quick.iface.gateway_unreachable": "gateway %s (unreachable)",
+	"quick.default_mark":              " [default]",
+	"quick.choose_range":              "Choose (1-%d) [default: 1]: ",
+	"quick.iface.invalid_choice":      "Invalid choice, using default interface (%s)\n",
+	"quick.public_network":            "⚠️ Public address detected: %s\nFor safety, quick mode only scans private networks\nTo scan public networks use: netcrate ops discover --dangerous",
+	"quick.target_selected":           "✅ Target network: %s\n",
+	"quick.config.interface":          "📡 Interface: %s (%s)\n",
+	"quick.config.local_ip":           "📍 Local IP: %s\n",
+	"quick.config.target":             "🎯 Target network: %s\n",
+	"quick.config.discovery":          "🔍 Host discovery: ICMP + TCP (22,80,443)\n",
+	"quick.config.excludes":           "🚫 Excluded: %s\n",
+	"quick.config.ports":              "📊 Port scan: %s\n",
+	"quick.config.profile":            "⚡ Speed profile: %s\n",
+	"quick.estimate.header":           "\n📈 Estimate:\n",
+	"quick.estimate.probes":           "   %d addresses, ~%d discovery probes + ~%d scan probes (assuming %d%% of hosts are live)\n",
+	"quick.estimate.duration":         "   ⏱️  Estimated duration: ~%s (up to %s if every host is live)\n",
+	"quick.estimate.bandwidth":        "   📶 Expected bandwidth: ~%s\n",
+	"quick.estimate.alternative":      "   ↔️  With the %s profile: ~%s, ~%s\n",
+	"quick.deep.host":                 "🎯 Host: %s\n",
+	"quick.deep.previous_ports":       "📋 Previously open ports: %s\n",
+	"quick.deep.plan":                 "\n📈 Deep dive will probe %d ports (up to %s), then fingerprint, audit TLS and trace the route\n",
+	"quick.deep.phase.scan":           "\n🔍 Phase 1: Full port range scan",
+	"quick.deep.phase.fingerprint":    "\n🔍 Phase 2: Service fingerprinting",
+	"quick.deep.phase.tls":            "\n🔐 Phase 3: TLS audit",
+	"quick.deep.phase.traceroute":     "\n🛰️ Phase 4: Traceroute",
+	"quick.deep.tls_failed":           "⚠️ TLS audit of port %d failed: %v\n",
+	"quick.deep.traceroute_failed":    "⚠️ Traceroute failed: %v\n",
+	"quick.deep.new_ports":            "\n🆕 Ports not seen in the quick run: %s\n",
+	"quick.deep.tls":                  "\n🔐 TLS audit:",
+	"quick.deep.traceroute":           "\n🛰️ Route:",
+	"quick.portset.top100":            "top100 (%d most common ports)",
+	"quick.portset.top1000":           "top1000 (%d most common ports)",
+	"quick.portset.web":               "web (%d web service ports)",
+	"quick.portset.database":          "database (%d database ports)",
+	"quick.portset.common":            "common (%d common service ports)",
+	"quick.portset.other":             "%s (%d ports)",
+	"quick.profile.safe":              "safe - safe mode (%d pps, %d concurrent)",
+	"quick.profile.fast":              "fast - fast mode (%d pps, %d concurrent)",
+	"quick.profile.custom":            "custom - custom (%d pps, %d concurrent)",
+	"quick.profile.other":             "%s (%d pps, %d concurrent)",
+	"quick.confirm_prompt":            "\nPress Enter to continue, 'q' to quit: ",
+	"quick.phase.discovery":           "\n🔍 Phase 1: Host discovery",
+	"quick.phase.discovery_done":      "✅ Found %d live hosts (%.1fs)\n",
+	"quick.checkpoint_failed":         "⚠️ Failed to save discovery results: %v\n",
+	"quick.enhanced.prioritized":      "   🎯 Target prioritization: %d targets (high=%d, medium=%d, low=%d)\n",
+	"quick.enhanced.rate":             "   ⚡ Adaptive rate: %d adjustments, final rate %d pps\n",
+	"quick.enhanced.methods":          "   🔄 Method fallback: using %s\n",
+	"quick.no_live_hosts":             "⚠️ No live hosts found, skipping port scan",
+	"quick.phase.scan":                "\n🔍 Phase 2: Port scan",
+	"quick.phase.scan_done":           "✅ Scan complete: %d open ports (%.1fs)\n",
+	"quick.phase.fingerprint":         "\n🔍 Phase 3: Service fingerprinting",
+	"quick.phase.fingerprint_done":    "✅ Fingerprinting complete: %d services (%.1fs)\n",
+	"quick.fingerprint_truncated":     "⚠️ Too many open ports, fingerprinting the first %d only\n",
+	"quick.risk_rules_failed":         "⚠️ Failed to load risk rules, using built-in rules: %v\n",
+	"quick.saved":                     "✅ Results saved to: %s\n",
+	"quick.notify.title":              "NetCrate quick run %s finished (%s)",
+	"quick.notify.title_critical":     "⚠️ NetCrate: %d new high-risk ports on %s",
+	"quick.notify.counts":             "%d hosts, %d open ports",
+	"quick.notify.sent":               "📣 Notification sent",
+	"quick.notify.failed":             "⚠️ %v\n",
+	"quick.select.portset":            "\n📊 Choose a port set:",
+	"quick.select.portset.1":          "  1. top100    - 100 most common ports (default)",
+	"quick.select.portset.2":          "  2. top1000   - 1000 most common ports",
+	"quick.select.portset.3":          "  3. web       - Web service ports",
+	"quick.select.portset.4":          "  4. database  - Database ports",
+	"quick.select.portset.5":          "  5. common    - Common service ports",
+	"quick.invalid_choice":            "Invalid choice, using default (%s)\n",
+	"quick.select.portset_done":       "✅ Port set: %s\n",
+	"quick.select.profile":            "\n⚡ Choose a speed profile:",
+	"quick.select.profile.1":          "  1. safe   - Safe mode (100pps, 200 concurrent) [default]",
+	"quick.select.profile.2":          "  2. fast   - Fast mode (400pps, 800 concurrent)",
+	"quick.select.profile.3":          "  3. custom - Custom parameters",
+	"quick.select.profile_done":       "✅ Speed profile: %s\n",
+	"quick.select.custom":             "\n🔧 Custom rate parameters:",
+	"quick.select.custom_rate":        "Scan rate (pps) [default: 100]: ",
+	"quick.invalid_input":             "Invalid input, using default %d\n",
+	"quick.select.custom_concurrency": "Concurrency [default: 200]: ",
+	"quick.select.custom_done":        "✅ Custom profile: %dpps, %d concurrent\n",
+	"quick.summary.title":             "\n🎉 Scan complete!",
+	"quick.summary.run_id":            "Run ID: %s\n",
+	"quick.summary.target":            "Target network: %s\n",
+	"quick.summary.duration":          "Total time: %.1f seconds\n",
+	"quick.summary.clamped":           "Compliance ceiling: %s\n",
+	"quick.summary.results":           "\n📊 Scan results",
+	"quick.summary.hosts":             "Live hosts: %d\n",
+	"quick.summary.open_ports":        "Open ports: %d\n",
+	"quick.summary.enhanced":          "\n✨ Discovery enhancements:",
+	"quick.summary.live_hosts":        "\n🟢 Live hosts:",
+	"quick.summary.services":          "\n🔧 Services found:",
+	"quick.summary.service_count":     "  • %s: %d instances\n",
+	"quick.summary.critical":          "\n⚠️ Critical ports (need attention):",
+	"quick.summary.critical_port":     "  • %s:%d (%s) - %s risk\n",
+	"quick.summary.fingerprints":      "\n🧬 Service fingerprints:",
+	"quick.summary.details":           "\n💾 Details: netcrate output show --run %s\n",
+	"quick.summary.devices":           "\n🗂️ Device inventory:",
+	"quick.summary.devices.category":  "Category",
+	"quick.summary.devices.host":      "Host",
+	"quick.summary.devices.mac":       "MAC",
+	"quick.summary.devices.vendor":    "Vendor",
+	"quick.summary.devices.name":      "Name",
+	"quick.resume.run":                "♻️ Resuming run: %s\n",
+	"quick.resume.discovery":          "✅ Reusing discovery results: %d live hosts\n",
+	"quick.resume.confirm":            "\n⚙️ Confirm configuration",
+	"quick.resume.interrupted":        "\n⚠️ Port scan interrupted, discovery results have been saved\n",
+	"quick.resume.hint":               "   Resume with: netcrate quick --resume %s\n",
+	"quick.watch.iteration":           "\n👀 Watch mode: round %d (%s)\n",
+	"quick.watch.failed":              "⚠️ This round failed: %v\n",
+	"quick.watch.log_failed":          "⚠️ Failed to write change log: %v\n",
+	"quick.watch.next":                "\n⏱️ Next scan: %s (Ctrl+C to quit)\n",
+	"quick.watch.stopped":             "\n👋 Watch stopped",
+	"quick.watch.no_changes":          "✅ No changes since the previous round",
+	"quick.watch.changes":             "\n🔔 Network changes detected (%s → %s)\n",
+	"quick.watch.new_host":            "  + New host: %s\n",
+	"quick.watch.gone_host":           "  - Host offline: %s\n",
+	"quick.watch.new_port":            "  + New open port: %s\n",
+	"quick.watch.closed_port":         "  - Port closed: %s\n",
+
+	"engine.quick.resume_failed":          "❌ Failed to resume quick mode: %v\n",
+	"engine.quick.watch_failed":           "❌ Watch mode failed: %v\n",
+	"engine.quick.failed":                 "❌ Quick mode failed: %v\n",
+	"engine.quick.deep_failed":            "❌ Deep dive failed: %v\n",
+	"engine.quick.trends_failed":          "❌ Failed to compute trends: %v\n",
+	"engine.config.load_warning":          "⚠️  Configuration not applied: %v\n",
+	"engine.config.network_overrides":     "🏢 Network overrides applied: %s\n",
+	"engine.config.project":               "📁 Using project file %s\n",
+	"engine.config.project_invalid":       "❌ Invalid project file: %v\n",
+	"engine.config.bad_default":           "❌ Invalid default in config (defaults.%s.%s): %v\n",
+	"engine.output.last_failed":           "❌ Failed to get the latest run: %v\n",
+	"engine.output.run_not_found":         "❌ Run '%s' not found: %v\n",
+	"engine.output.no_runs":               "❌ No saved runs found\n",
+	"engine.output.first_run_hint":        "Run 'netcrate quick' to create your first scan\n",
+	"engine.output.load_failed":           "❌ Failed to load results: %v\n",
+	"engine.output.show_failed":           "❌ Failed to show results: %v\n",
+	"engine.output.list_failed":           "❌ Failed to list runs: %v\n",
+	"engine.output.export_failed":         "❌ Export failed: %v\n",
+	"engine.output.unknown_format":        "❌ Unknown export format: %s (available: json, nmap-xml, csv, syslog, cef)\n",
+	"engine.output.exported":              "✅ Exported %s to %s\n",
+	"engine.output.query_failed":          "❌ Query failed: %v\n",
+	"engine.output.query_rebuilt":         "✅ Indexed %d runs into %s\n",
+	"engine.output.hosts_failed":          "❌ Failed to build host inventory: %v\n",
+	"engine.output.prune_failed":          "❌ Prune failed: %v\n",
+	"engine.output.prune_no_policy":       "No retention limits given or configured.\nUse --older-than, --max-runs or --max-size, or set retention_max_* with 'netcrate config set'.\n",
+	"engine.output.prune_nothing":         "Nothing to prune.",
+	"engine.output.prune_reason.age":      "too old",
+	"engine.output.prune_reason.count":    "over run limit",
+	"engine.output.prune_reason.disk":     "over size limit",
+	"engine.output.prune_dry_run":         "\n🔍 Dry run: %d runs (%s) would be removed\n",
+	"engine.output.pruned":                "\n🗑️ Removed %d runs, freed %s\n",
+	"engine.output.tag_failed":            "❌ Failed to update run: %v\n",
+	"engine.output.tagged":                "✅ Updated %s (name: %s, tags: %s)\n",
+	"engine.output.encrypt_failed":        "❌ Failed to encrypt runs: %v\n",
+	"engine.output.encrypted":             "🔒 Encrypted %d run files\n",
+	"engine.output.merge_failed":          "❌ Failed to merge runs: %v\n",
+	"engine.output.merged":                "✅ Merged %d runs into %s (%d hosts, %d open ports)\n",
+	"engine.output.report_failed":         "❌ Report generation failed: %v\n",
+	"engine.output.report_written":        "✅ Report for %s written to %s\n",
+	"engine.output.unknown_report_format": "❌ Unknown report format: %s (available: %s)\n",
+	"engine.output.bundle_written":        "✅ Evidence bundle for %s written to %s (%d files)\n",
+
+	"output.summary.partial":          "Interrupted after discovery (%d hosts), resumable",
+	"output.summary.no_hosts":         "No hosts discovered",
+	"output.summary.hosts":            "%d hosts",
+	"output.summary.ports":            "%d ports",
+	"output.summary.critical":         "%d critical",
+	"output.summary.responses":        "%d/%d responses",
+	"output.list.empty":               "No saved runs found.",
+	"output.list.first_run_hint":      "Run 'netcrate quick' to create your first scan.",
+	"output.list.title":               "📁 Saved Runs (%d total)\n",
+	"output.list.col.run_id":          "Run ID",
+	"output.list.col.type":            "Type",
+	"output.list.col.duration":        "Duration",
+	"output.list.col.date":            "Date",
+	"output.list.col.size":            "Size",
+	"output.list.col.summary":         "Summary",
+	"output.list.show_hint":           "\nUse 'netcrate output show --run <run-id>' to view details (a unique ID prefix or <type>-<date>-<time> alias also works)\n",
+	"output.list.last_hint":           "Use 'netcrate output show --last' to view the latest run\n",
+	"output.list.limited":             "Showing %d of %d runs (use --limit 0 for all)\n",
+	"output.list.totals":              "Total: %d runs, %d hosts, %d open ports, %s on disk\n",
+	"output.trends.title":             "📈 Quick Mode Trends (%d runs)\n",
+	"output.trends.col.hosts":         "Hosts",
+	"output.trends.col.ports":         "Open ports",
+	"output.trends.col.critical":      "Critical",
+	"output.trends.services":          "\n🔧 Services over time:",
+	"output.trends.hosts":             "\n🖥️ Hosts:",
+	"output.trends.col.host":          "Host",
+	"output.trends.col.first_seen":    "First seen",
+	"output.trends.col.last_seen":     "Last seen",
+	"output.trends.col.seen_in":       "Runs",
+	"output.table.empty":              "No matching results.",
+	"output.table.rows":               "\n%d rows\n",
+	"output.hosts.empty":              "No hosts found in saved runs.",
+	"output.hosts.title":              "🖥️ Host Inventory (%d hosts)\n",
+	"output.hosts.col.mac":            "MAC",
+	"output.hosts.col.name":           "Name",
+	"output.hosts.col.ports":          "Ports",
+	"output.hosts.col.risk":           "Risks",
+	"output.hosts.detail_hint":        "\nUse 'netcrate output hosts --host <ip>' to view a host profile\n",
+	"output.hosts.profile_title":      "🖥️ Host %s\n",
+	"output.hosts.seen":               "Seen: %s → %s (%d runs)\n",
+	"output.hosts.mac":                "MAC: %s %s\n",
+	"output.hosts.category":           "Category: %s\n",
+	"output.hosts.names":              "Names: %s\n",
+	"output.hosts.services":           "Services: %s\n",
+	"output.hosts.ports_title":        "\n🔌 Ports (first seen → last seen, runs):",
+	"output.hosts.state_open":         "open",
+	"output.hosts.state_closed":       "closed",
+	"output.hosts.fingerprints_title": "\n🔍 Fingerprints:",
+	"output.hosts.risk_title":         "\n⚠️ Risk notes:",
+	"output.hosts.runs":               "\nRuns: %s\n",
+	"output.context.title":            "\n🧭 Run context:",
+	"output.context.version":          "  NetCrate: %s\n",
+	"output.context.host":             "  Host: %s (%s)\n",
+	"output.context.interface":        "  Interface: %s %s %s\n",
+	"output.context.gateway":          "  Gateway: %s\n",
+	"output.context.ssid":             "  Wi-Fi: %s\n",
+	"output.context.public_ip":        "  Public IP: %s\n",
+	"output.context.scope":            "  Authorization: %s (scope %s)\n",
+	"output.context.authorized_by":    "  Authorized by: %s\n",
+	"output.context.clamped":          "  Compliance ceiling: %s\n",
+}
+
+// messagesZhCN is the Simplified Chinese catalog
+var messagesZhCN = map[string]string{
+	"quick.step.detect_interface":     "\n[1/4] 🔍 自动检测网络接口...",
+	"quick.step.target_network":       "\n[2/4] 🎯 计算目标网段...",
+	"quick.step.configure":            "\n[2.5/4] ⚙️ 扫描配置",
+	"quick.step.confirm":              "\n[3/4] ⚙️ 配置确认",
+	"quick.step.execute":              "\n[4/4] 🔍 执行扫描流水线...",
+	"quick.cancelled":                 "\n❌ 用户取消操作",
+	"quick.dry_run":                   "🧪 [DRY RUN] 跳过实际执行",
+	"quick.save_failed":               "⚠️ 结果保存失败: %v\n",
+	"quick.iface.not_found":           "未找到网络接口: %s",
+	"quick.iface.down":                "网络接口 %s 未启用",
+	"quick.iface.forced":              "✅ 使用指定接口: %s (%s)\n",
+	"quick.iface.routed":              "✅ 使用接口: %s (%s), 即到 %s 的路由\n",
+	"quick.iface.overlay":             "⚠️  %s 是 %s 隧道: %s 经隧道通向远程网络和基础设施, 而非本地局域网。请确认整个网段都在授权范围内。\n",
+	"quick.iface.virtual":             "⚠️  %s 是本机的 %s 网络: %s 中只有本地容器或虚拟机\n",
+	"quick.iface.ip":                  "   IP地址: %s\n",
+	"quick.iface.none":                "未检测到可用的网络接口",
+	"quick.iface.selected":            "✅ 自动选择接口: %s (%s)\n",
+	"quick.iface.multiple":            "\n📡 检测到多个私网接口:",
+	"quick.iface.no_gateway":          "无网关",
+	"quick.iface.gateway_rtt":         "网关 %s (%.1fms)",
+	"quick.iface.gateway_unreachable": "网关 %s (不可达)",
+	"quick.default_mark":              " [默认]",
+	"quick.choose_range":              "请选择 (1-%d) [默认: 1]: ",
+	"quick.iface.invalid_choice":      "无效选择，使用默认接口 (%s)\n",
+	"quick.public_network":            "⚠️ 检测到公网地址 %s\n为了安全，Quick模式只能扫描私网地址\n如需扫描公网，请使用: netcrate ops discover --dangerous",
+	"quick.target_selected":           "✅ 目标网段: %s\n",
+	"quick.config.interface":          "📡 接口: %s (%s)\n",
+	"quick.config.local_ip":           "📍 本机IP: %s\n",
+	"quick.config.target":             "🎯 目标网段: %s\n",
+	"quick.config.discovery":          "🔍 主机发现: ICMP + TCP (22,80,443)\n",
+	"quick.config.excludes":           "🚫 排除: %s\n",
+	"quick.config.ports":              "📊 端口扫描: %s\n",
+	"quick.config.profile":            "⚡ 速率档位: %s\n",
+	"quick.estimate.header":           "\n📈 预估:\n",
+	"quick.estimate.probes":           "   %d 个地址, 约 %d 个发现探测 + 约 %d 个扫描探测 (假设 %d%% 主机存活)\n",
+	"quick.estimate.duration":         "   ⏱️  预计耗时: 约 %s (所有主机存活时最多 %s)\n",
+	"quick.estimate.bandwidth":        "   📶 预计带宽: 约 %s\n",
+	"quick.estimate.alternative":      "   ↔️  使用 %s 档位: 约 %s, 约 %s\n",
+	"quick.deep.host":                 "🎯 主机: %s\n",
+	"quick.deep.previous_ports":       "📋 此前开放端口: %s\n",
+	"quick.deep.plan":                 "\n📈 深度分析将探测 %d 个端口 (最多 %s), 随后进行指纹识别、TLS 审计和路由追踪\n",
+	"quick.deep.phase.scan":           "\n🔍 阶段 1: 全端口扫描",
+	"quick.deep.phase.fingerprint":    "\n🔍 阶段 2: 服务指纹识别",
+	"quick.deep.phase.tls":            "\n🔐 阶段 3: TLS 审计",
+	"quick.deep.phase.traceroute":     "\n🛰️ 阶段 4: 路由追踪",
+	"quick.deep.tls_failed":           "⚠️ 端口 %d 的 TLS 审计失败: %v\n",
+	"quick.deep.traceroute_failed":    "⚠️ 路由追踪失败: %v\n",
+	"quick.deep.new_ports":            "\n🆕 快速扫描中未发现的端口: %s\n",
+	"quick.deep.tls":                  "\n🔐 TLS 审计:",
+	"quick.deep.traceroute":           "\n🛰️ 路由:",
+	"quick.portset.top100":            "top100 (%d 个最常用端口)",
+	"quick.portset.top1000":           "top1000 (%d 个最常用端口)",
+	"quick.portset.web":               "web (%d 个Web服务端口)",
+	"quick.portset.database":          "database (%d 个数据库端口)",
+	"quick.portset.common":            "common (%d 个通用服务端口)",
+	"quick.portset.other":             "%s (%d 个端口)",
+	"quick.profile.safe":              "safe - 安全模式 (%d pps, %d 并发)",
+	"quick.profile.fast":              "fast - 快速模式 (%d pps, %d 并发)",
+	"quick.profile.custom":            "custom - 自定义 (%d pps, %d 并发)",
+	"quick.profile.other":             "%s (%d pps, %d 并发)",
+	"quick.confirm_prompt":            "\n按 Enter 继续，输入 'q' 退出: ",
+	"quick.phase.discovery":           "\n🔍 阶段 1: 主机发现",
+	"quick.phase.discovery_done":      "✅ 发现 %d 个活跃主机 (耗时 %.1fs)\n",
+	"quick.checkpoint_failed":         "⚠️ 发现阶段结果保存失败: %v\n",
+	"quick.enhanced.prioritized":      "   🎯 目标优先级: %d 个目标 (高=%d, 中=%d, 低=%d)\n",
+	"quick.enhanced.rate":             "   ⚡ 自适应速率: 调整 %d 次, 最终速率 %d pps\n",
+	"quick.enhanced.methods":          "   🔄 方法回退: 使用 %s\n",
+	"quick.no_live_hosts":             "⚠️ 未发现活跃主机，跳过端口扫描",
+	"quick.phase.scan":                "\n🔍 阶段 2: 端口扫描",
+	"quick.phase.scan_done":           "✅ 扫描完成：发现 %d 个开放端口 (耗时 %.1fs)\n",
+	"quick.phase.fingerprint":         "\n🔍 阶段 3: 服务指纹识别",
+	"quick.phase.fingerprint_done":    "✅ 识别完成：%d 个服务 (耗时 %.1fs)\n",
+	"quick.fingerprint_truncated":     "⚠️ 开放端口过多，仅识别前 %d 个\n",
+	"quick.risk_rules_failed":         "⚠️ 风险规则加载失败，使用内置规则: %v\n",
+	"quick.saved":                     "✅ 结果已保存到: %s\n",
+	"quick.notify.title":              "NetCrate 快速扫描 %s 已完成 (%s)",
+	"quick.notify.title_critical":     "⚠️ NetCrate: %[2]s 上新出现 %[1]d 个高风险端口",
+	"quick.notify.counts":             "%d 个主机, %d 个开放端口",
+	"quick.notify.sent":               "📣 通知已发送",
+	"quick.notify.failed":             "⚠️ %v\n",
+	"quick.select.portset":            "\n📊 选择端口集:",
+	"quick.select.portset.1":          "  1. top100    - 最常用100个端口 (默认)",
+	"quick.select.portset.2":          "  2. top1000   - 最常用1000个端口",
+	"quick.select.portset.3":          "  3. web       - Web服务端口",
+	"quick.select.portset.4":          "  4. database  - 数据库端口",
+	"quick.select.portset.5":          "  5. common    - 通用服务端口",
+	"quick.invalid_choice":            "无效选择，使用默认值 (%s)\n",
+	"quick.select.portset_done":       "✅ 端口集: %s\n",
+	"quick.select.profile":            "\n⚡ 选择速率档位:",
+	"quick.select.profile.1":          "  1. safe   - 安全模式 (100pps, 200并发) [默认]",
+	"quick.select.profile.2":          "  2. fast   - 快速模式 (400pps, 800并发)",
+	"quick.select.profile.3":          "  3. custom - 自定义参数",
+	"quick.select.profile_done":       "✅ 速率档位: %s\n",
+	"quick.select.custom":             "\n🔧 自定义速率参数:",
+	"quick.select.custom_rate":        "扫描速率 (pps) [默认: 100]: ",
+	"quick.invalid_input":             "无效输入，使用默认值 %d\n",
+	"quick.select.custom_concurrency": "并发数 [默认: 200]: ",
+	"quick.select.custom_done":        "✅ 自定义档位: %dpps, %d并发\n",
+	"quick.summary.title":             "\n🎉 扫描完成！",
+	"quick.summary.run_id":            "运行ID: %s\n",
+	"quick.summary.target":            "目标网段: %s\n",
+	"quick.summary.duration":          "总耗时: %.1f 秒\n",
+	"quick.summary.clamped":           "合规上限: %s\n",
+	"quick.summary.results":           "\n📊 扫描结果",
+	"quick.summary.hosts":             "活跃主机: %d\n",
+	"quick.summary.open_ports":        "开放端口: %d\n",
+	"quick.summary.enhanced":          "\n✨ 发现增强:",
+	"quick.summary.live_hosts":        "\n🟢 活跃主机列表:",
+	"quick.summary.services":          "\n🔧 发现的服务:",
+	"quick.summary.service_count":     "  • %s: %d 个实例\n",
+	"quick.summary.critical":          "\n⚠️ 关键端口 (需要注意):",
+	"quick.summary.critical_port":     "  • %s:%d (%s) - %s 风险\n",
+	"quick.summary.fingerprints":      "\n🧬 服务指纹:",
+	"quick.summary.details":           "\n💾 详细结果: netcrate output show --run %s\n",
+	"quick.summary.devices":           "\n🗂️ 设备清单:",
+	"quick.summary.devices.category":  "类别",
+	"quick.summary.devices.host":      "主机",
+	"quick.summary.devices.mac":       "MAC",
+	"quick.summary.devices.vendor":    "厂商",
+	"quick.summary.devices.name":      "名称",
+	"quick.resume.run":                "♻️ 继续运行: %s\n",
+	"quick.resume.discovery":          "✅ 复用发现阶段结果: %d 个活跃主机\n",
+	"quick.resume.confirm":            "\n⚙️ 配置确认",
+	"quick.resume.interrupted":        "\n⚠️ 端口扫描被中断，发现阶段结果已保存\n",
+	"quick.resume.hint":               "   继续扫描: netcrate quick --resume %s\n",
+	"quick.watch.iteration":           "\n👀 监控模式: 第 %d 轮 (%s)\n",
+	"quick.watch.failed":              "⚠️ 本轮扫描失败: %v\n",
+	"quick.watch.log_failed":          "⚠️ 变更日志写入失败: %v\n",
+	"quick.watch.next":                "\n⏱️ 下一轮扫描: %s (Ctrl+C 退出)\n",
+	"quick.watch.stopped":             "\n👋 监控已停止",
+	"quick.watch.no_changes":          "✅ 与上一轮相比无变化",
+	"quick.watch.changes":             "\n🔔 检测到网络变化 (%s → %s)\n",
+	"quick.watch.new_host":            "  + 新主机: %s\n",
+	"quick.watch.gone_host":           "  - 主机离线: %s\n",
+	"quick.watch.new_port":            "  + 新开放端口: %s\n",
+	"quick.watch.closed_port":         "  - 端口关闭: %s\n",
+
+	"engine.quick.resume_failed":          "❌ Quick模式恢复失败: %v\n",
+	"engine.quick.watch_failed":           "❌ 监控模式执行失败: %v\n",
+	"engine.quick.failed":                 "❌ Quick模式执行失败: %v\n",
+	"engine.quick.deep_failed":            "❌ 深度分析执行失败: %v\n",
+	"engine.quick.trends_failed":          "❌ 趋势统计失败: %v\n",
+	"engine.config.load_warning":          "⚠️  未应用配置: %v\n",
+	"engine.config.network_overrides":     "🏢 已应用网络覆盖配置: %s\n",
+	"engine.config.project":               "📁 使用项目文件 %s\n",
+	"engine.config.project_invalid":       "❌ 项目文件无效: %v\n",
+	"engine.config.bad_default":           "❌ 配置中的默认值无效 (defaults.%s.%s): %v\n",
+	"engine.output.last_failed":           "❌ 获取最近运行失败: %v\n",
+	"engine.output.run_not_found":         "❌ 找不到运行 '%s': %v\n",
+	"engine.output.no_runs":               "❌ 没有找到保存的运行结果\n",
+	"engine.output.first_run_hint":        "运行 'netcrate quick' 来创建你的第一次扫描\n",
+	"engine.output.load_failed":           "❌ 加载结果失败: %v\n",
+	"engine.output.show_failed":           "❌ 显示结果失败: %v\n",
+	"engine.output.list_failed":           "❌ 获取运行列表失败: %v\n",
+	"engine.output.export_failed":         "❌ 导出失败: %v\n",
+	"engine.output.unknown_format":        "❌ 未知的导出格式: %s (可用: json, nmap-xml, csv, syslog, cef)\n",
+	"engine.output.exported":              "✅ 已将 %s 导出到 %s\n",
+	"engine.output.query_failed":          "❌ 查询失败: %v\n",
+	"engine.output.query_rebuilt":         "✅ 已将 %d 次运行索引到 %s\n",
+	"engine.output.hosts_failed":          "❌ 构建主机清单失败: %v\n",
+	"engine.output.prune_failed":          "❌ 清理失败: %v\n",
+	"engine.output.prune_no_policy":       "未指定或配置保留限制。\n请使用 --older-than、--max-runs 或 --max-size，或通过 'netcrate config set' 设置 retention_max_*。\n",
+	"engine.output.prune_nothing":         "没有需要清理的运行。",
+	"engine.output.prune_reason.age":      "已过期",
+	"engine.output.prune_reason.count":    "超出数量限制",
+	"engine.output.prune_reason.disk":     "超出空间限制",
+	"engine.output.prune_dry_run":         "\n🔍 试运行: 将删除 %d 次运行 (%s)\n",
+	"engine.output.pruned":                "\n🗑️ 已删除 %d 次运行，释放 %s\n",
+	"engine.output.tag_failed":            "❌ 更新运行失败: %v\n",
+	"engine.output.tagged":                "✅ 已更新 %s (名称: %s, 标签: %s)\n",
+	"engine.output.encrypt_failed":        "❌ 加密运行失败: %v\n",
+	"engine.output.encrypted":             "🔒 已加密 %d 个运行文件\n",
+	"engine.output.merge_failed":          "❌ 合并运行失败: %v\n",
+	"engine.output.merged":                "✅ 已将 %d 次运行合并为 %s (%d 个主机, %d 个开放端口)\n",
+	"engine.output.report_failed":         "❌ 生成报告失败: %v\n",
+	"engine.output.report_written":        "✅ 已将 %s 的报告写入 %s\n",
+	"engine.output.unknown_report_format": "❌ 未知的报告格式: %s (可用: %s)\n",
+	"engine.output.bundle_written":        "✅ 已将 %s 的证据包写入 %s（%d 个文件）\n",
+
+	"output.summary.partial":          "发现阶段后中断 (%d 个主机)，可恢复",
+	"output.summary.no_hosts":         "未发现主机",
+	"output.summary.hosts":            "%d 个主机",
+	"output.summary.ports":            "%d 个端口",
+	"output.summary.critical":         "%d 个关键",
+	"output.summary.responses":        "%d/%d 个响应",
+	"output.list.empty":               "没有找到保存的运行结果。",
+	"output.list.first_run_hint":      "运行 'netcrate quick' 来创建你的第一次扫描。",
+	"output.list.title":               "📁 已保存的运行 (共 %d 个)\n",
+	"output.list.col.run_id":          "运行ID",
+	"output.list.col.type":            "类型",
+	"output.list.col.duration":        "耗时",
+	"output.list.col.date":            "日期",
+	"output.list.col.size":            "大小",
+	"output.list.col.summary":         "摘要",
+	"output.list.show_hint":           "\n使用 'netcrate output show --run <run-id>' 查看详情（也可使用唯一的ID前缀或 <类型>-<日期>-<时间> 别名）\n",
+	"output.list.last_hint":           "使用 'netcrate output show --last' 查看最近一次运行\n",
+	"output.list.limited":             "显示 %d / %d 个运行（使用 --limit 0 显示全部）\n",
+	"output.list.totals":              "合计: %d 个运行, %d 台主机, %d 个开放端口, 占用磁盘 %s\n",
+	"output.trends.title":             "📈 Quick模式趋势 (%d 次运行)\n",
+	"output.trends.col.hosts":         "主机",
+	"output.trends.col.ports":         "开放端口",
+	"output.trends.col.critical":      "关键",
+	"output.trends.services":          "\n🔧 服务变化:",
+	"output.trends.hosts":             "\n🖥️ 主机:",
+	"output.trends.col.host":          "主机",
+	"output.trends.col.first_seen":    "首次发现",
+	"output.trends.col.last_seen":     "最近发现",
+	"output.trends.col.seen_in":       "次数",
+	"output.table.empty":              "没有匹配的结果。",
+	"output.table.rows":               "\n共 %d 行\n",
+	"output.hosts.empty":              "已保存的运行中没有主机。",
+	"output.hosts.title":              "🖥️ 主机清单 (%d 个主机)\n",
+	"output.hosts.col.mac":            "MAC",
+	"output.hosts.col.name":           "名称",
+	"output.hosts.col.ports":          "端口",
+	"output.hosts.col.risk":           "风险",
+	"output.hosts.detail_hint":        "\n使用 'netcrate output hosts --host <ip>' 查看主机详情\n",
+	"output.hosts.profile_title":      "🖥️ 主机 %s\n",
+	"output.hosts.seen":               "发现时间: %s → %s (%d 次运行)\n",
+	"output.hosts.mac":                "MAC: %s %s\n",
+	"output.hosts.category":           "类别: %s\n",
+	"output.hosts.names":              "名称: %s\n",
+	"output.hosts.services":           "服务: %s\n",
+	"output.hosts.ports_title":        "\n🔌 端口 (首次发现 → 最近发现, 次数):",
+	"output.hosts.state_open":         "开放",
+	"output.hosts.state_closed":       "已关闭",
+	"output.hosts.fingerprints_title": "\n🔍 指纹:",
+	"output.hosts.risk_title":         "\n⚠️ 风险提示:",
+	"output.hosts.runs":               "\n运行: %s\n",
+	"output.context.title":            "\n🧭 运行环境:",
+	"output.context.version":          "  NetCrate: %s\n",
+	"output.context.host":             "  主机: %s (%s)\n",
+	"output.context.interface":        "  接口: %s %s %s\n",
+	"output.context.gateway":          "  网关: %s\n",
+	"output.context.ssid":             "  Wi-Fi: %s\n",
+	"output.context.public_ip":        "  公网 IP: %s\n",
+	"output.context.scope":            "  授权: %s (范围 %s)\n",
+	"output.context.authorized_by":    "  批准人: %s\n",
+	"output.context.clamped":          "  合规上限: %s\n",
+}
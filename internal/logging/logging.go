@@ -0,0 +1,171 @@
+// Package logging is the diagnostic log of netcrate: leveled records tagged
+// with the component that wrote them, written to stderr as text or JSON
+// lines and, when persistence is on, kept with every saved run.
+//
+// Components take their logger once, usually in a package variable:
+//
+//	var log = logging.Component("discover")
+//
+//	log.Debug("testing method", "method", method)
+//
+// Loggers follow Configure, so they may be created before the command line
+// is parsed.
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configure the console log
+type Options struct {
+	Level  slog.Level
+	Format string    // "text" (default) or "json"
+	Output io.Writer // default os.Stderr
+}
+
+var (
+	mu      sync.RWMutex
+	level                = new(slog.LevelVar) // Info until configured
+	console slog.Handler = newTextHandler(os.Stderr, level)
+
+	// Records of the run in progress, kept when persistence is on
+	runLog *bytes.Buffer
+	runDir string
+	runRec slog.Handler
+)
+
+// Configure sets the level and format of the console log
+func Configure(opts Options) error {
+	output := opts.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	var handler slog.Handler
+	switch opts.Format {
+	case "", "text":
+		handler = newTextHandler(output, level)
+	case "json":
+		handler = slog.NewJSONHandler(output, &slog.HandlerOptions{Level: level})
+	default:
+		return fmt.Errorf("invalid log format: %s (expected text or json)", opts.Format)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	level.Set(opts.Level)
+	console = handler
+	return nil
+}
+
+// ParseLevel parses a level name: debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level: %s (expected debug, info, warn or error)", name)
+}
+
+// Component returns the logger of a module, whose records carry
+// component=<name>
+func Component(name string) *slog.Logger {
+	return slog.New(&dispatcher{}).With("component", name)
+}
+
+// PersistRuns keeps a debug log of every run saved from now on: the records
+// written until a run is saved go to dir/netcrate-<run-id>-<time>.log, where
+// report bundles pick them up
+func PersistRuns(dir string) {
+	mu.Lock()
+	defer mu.Unlock()
+	runDir = dir
+	runLog = new(bytes.Buffer)
+	runRec = slog.NewJSONHandler(runLog, &slog.HandlerOptions{Level: slog.LevelDebug})
+}
+
+// SaveRun writes the records collected since the last saved run as the log
+// of runID and starts collecting for the next run. The log is passed through
+// seal, which encrypts it along with the run when encryption is on. It returns
+// the path of the log, or "" when persistence is off or nothing was logged.
+func SaveRun(runID string, seal func([]byte) ([]byte, error)) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if runLog == nil || runLog.Len() == 0 {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	path := filepath.Join(runDir, fmt.Sprintf("netcrate-%s-%s.log", runID, time.Now().Format("20060102-150405")))
+	data, err := seal(runLog.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt run log: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write run log: %w", err)
+	}
+	runLog.Reset()
+	return path, nil
+}
+
+// dispatcher sends records to the console and the run log as configured
+// when they are written. It keeps the attributes and groups added to its
+// logger and applies them to whichever handlers are current.
+type dispatcher struct {
+	derive []func(slog.Handler) slog.Handler
+}
+
+func (d *dispatcher) Enabled(ctx context.Context, l slog.Level) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return l >= level.Level() || runRec != nil
+}
+
+func (d *dispatcher) Handle(ctx context.Context, r slog.Record) error {
+	mu.RLock()
+	defer mu.RUnlock()
+	var err error
+	if r.Level >= level.Level() {
+		err = d.apply(console).Handle(ctx, r.Clone())
+	}
+	if runRec != nil {
+		d.apply(runRec).Handle(ctx, r.Clone())
+	}
+	return err
+}
+
+func (d *dispatcher) apply(h slog.Handler) slog.Handler {
+	for _, derive := range d.derive {
+		h = derive(h)
+	}
+	return h
+}
+
+func (d *dispatcher) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return d.with(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+}
+
+func (d *dispatcher) WithGroup(name string) slog.Handler {
+	return d.with(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+}
+
+func (d *dispatcher) with(derive func(slog.Handler) slog.Handler) *dispatcher {
+	return &dispatcher{derive: append(d.derive[:len(d.derive):len(d.derive)], derive)}
+}
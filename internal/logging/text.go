@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// textHandler writes records for people reading the terminal:
+//
+//	[INFO] discover: running main discovery targets=254
+type textHandler struct {
+	mu        *sync.Mutex
+	w         io.Writer
+	level     slog.Leveler
+	component string
+	attrs     string // preformatted " key=value" pairs
+	prefix    string // open groups, "group."
+}
+
+func newTextHandler(w io.Writer, level slog.Leveler) *textHandler {
+	return &textHandler{mu: new(sync.Mutex), w: w, level: level}
+}
+
+func (h *textHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *textHandler) Handle(ctx context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString("[" + r.Level.String() + "] ")
+	if h.component != "" {
+		b.WriteString(h.component + ": ")
+	}
+	b.WriteString(r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	var b strings.Builder
+	for _, a := range attrs {
+		if a.Key == "component" && h.prefix == "" {
+			clone.component = a.Value.String()
+			continue
+		}
+		writeAttr(&b, h.prefix, a)
+	}
+	clone.attrs += b.String()
+	return &clone
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix += name + "."
+	return &clone
+}
+
+// writeAttr appends " key=value", flattening groups into dotted keys
+func writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	value := a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, member := range value.Group() {
+			writeAttr(b, prefix, member)
+		}
+		return
+	}
+
+	text := value.String()
+	if text == "" || strings.ContainsAny(text, " \t\n\"=") {
+		text = strconv.Quote(text)
+	}
+	b.WriteString(" " + prefix + a.Key + "=" + text)
+}
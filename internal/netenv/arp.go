@@ -0,0 +1,128 @@
+package netenv
+
+import (
+	"bytes"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ARPEntry is an entry of the system's neighbor cache: the ARP table for
+// IPv4 and the neighbor discovery (ND) table for IPv6
+type ARPEntry struct {
+	IP        string `json:"ip"`
+	MAC       string `json:"mac"` // lower-case, colon-separated
+	Interface string `json:"interface,omitempty"`
+	State     string `json:"state,omitempty"` // reachable, stale, delay, probe or permanent, where the platform reports it
+	Family    string `json:"family"`          // ipv4 or ipv6
+}
+
+// GetARPTable reads the neighbor cache: from netlink on Linux, the routing
+// sysctl on macOS and the BSDs and Get-NetNeighbor on Windows, falling back
+// to "arp -a". Only resolved unicast entries are returned, ordered by
+// family and address.
+func GetARPTable() ([]ARPEntry, error) {
+	entries, err := readARPTable()
+	if err != nil {
+		return nil, err
+	}
+	var resolved []ARPEntry
+	for _, entry := range entries {
+		mac, err := net.ParseMAC(entry.MAC)
+		if err != nil || len(mac) != 6 || mac[0]&1 != 0 || bytes.Equal(mac, make(net.HardwareAddr, 6)) {
+			// Incomplete, broadcast or multicast
+			continue
+		}
+		entry.MAC = mac.String()
+		if entry.Family == "" {
+			entry.Family = "ipv4"
+			if ip := net.ParseIP(entry.IP); ip != nil && ip.To4() == nil {
+				entry.Family = "ipv6"
+			}
+		}
+		resolved = append(resolved, entry)
+	}
+
+	sort.SliceStable(resolved, func(i, j int) bool {
+		if resolved[i].Family != resolved[j].Family {
+			return resolved[i].Family < resolved[j].Family
+		}
+		return bytes.Compare(net.ParseIP(resolved[i].IP), net.ParseIP(resolved[j].IP)) < 0
+	})
+	return resolved, nil
+}
+
+// ARPTableMACs maps the addresses of a neighbor cache to their MAC
+func ARPTableMACs(entries []ARPEntry) map[string]string {
+	macs := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		macs[entry.IP] = entry.MAC
+	}
+	return macs
+}
+
+var (
+	arpMACPattern = regexp.MustCompile(`([0-9a-fA-F]{1,2}[:-]){5}[0-9a-fA-F]{1,2}`)
+	arpIPPattern  = regexp.MustCompile(`\(([0-9.]+)\)`)
+)
+
+// parseArpA reads the output of "arp -a", whose format varies by OS:
+//
+//	macOS:   host (192.168.1.1) at aa:bb:cc:dd:ee:ff on en0 ifscope [ethernet]
+//	Linux:   host (192.168.1.1) at aa:bb:cc:dd:ee:ff [ether] on eth0
+//	Windows: Interface: 192.168.1.23 --- 0xb
+//	           192.168.1.1           aa-bb-cc-dd-ee-ff     dynamic
+func parseArpA(output string) []ARPEntry {
+	var entries []ARPEntry
+	windowsInterface := ""
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "Interface:" && len(fields) > 1 {
+			windowsInterface = fields[1]
+			if name, ok := interfaceNamesByIP()[fields[1]]; ok {
+				windowsInterface = name
+			}
+			continue
+		}
+
+		mac := arpMACPattern.FindString(line)
+		if mac == "" {
+			continue
+		}
+		// Pad single-digit octets, as macOS prints them
+		octets := strings.FieldsFunc(mac, func(r rune) bool { return r == ':' || r == '-' })
+		for i, octet := range octets {
+			if len(octet) == 1 {
+				octets[i] = "0" + octet
+			}
+		}
+		entry := ARPEntry{MAC: strings.Join(octets, ":")}
+
+		if matches := arpIPPattern.FindStringSubmatch(line); len(matches) == 2 {
+			entry.IP = matches[1]
+			for i, field := range fields {
+				if field == "on" && i+1 < len(fields) {
+					entry.Interface = fields[i+1]
+				}
+			}
+		} else if net.ParseIP(fields[0]) != nil {
+			entry.IP = fields[0]
+			entry.Interface = windowsInterface
+			if len(fields) > 2 && fields[2] == "static" {
+				entry.State = "permanent"
+			}
+		}
+		if net.ParseIP(entry.IP) == nil {
+			continue
+		}
+		if strings.Contains(line, "permanent") {
+			entry.State = "permanent"
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,58 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package netenv
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+
+	"golang.org/x/net/route"
+)
+
+// readARPTable dumps the link-layer routes (RTF_LLINFO) through the routing
+// sysctl, the same source as "arp -a" and "ndp -a"
+func readARPTable() ([]ARPEntry, error) {
+	var entries []ARPEntry
+	for _, family := range []int{syscall.AF_INET, syscall.AF_INET6} {
+		rib, err := route.FetchRIB(family, syscall.NET_RT_FLAGS, syscall.RTF_LLINFO)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read neighbor table: %w", err)
+		}
+		messages, err := route.ParseRIB(syscall.NET_RT_FLAGS, rib)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse neighbor table: %w", err)
+		}
+		for _, message := range messages {
+			m, ok := message.(*route.RouteMessage)
+			if !ok || len(m.Addrs) <= syscall.RTAX_GATEWAY {
+				continue
+			}
+			link, ok := m.Addrs[syscall.RTAX_GATEWAY].(*route.LinkAddr)
+			if !ok || len(link.Addr) == 0 {
+				continue
+			}
+			entry := ARPEntry{MAC: net.HardwareAddr(link.Addr).String(), Interface: link.Name}
+			switch dst := m.Addrs[syscall.RTAX_DST].(type) {
+			case *route.Inet4Addr:
+				entry.IP = net.IP(dst.IP[:]).String()
+				entry.Family = "ipv4"
+			case *route.Inet6Addr:
+				entry.IP = net.IP(dst.IP[:]).String()
+				entry.Family = "ipv6"
+			default:
+				continue
+			}
+			if entry.Interface == "" {
+				if iface, err := net.InterfaceByIndex(m.Index); err == nil {
+					entry.Interface = iface.Name
+				}
+			}
+			if m.Flags&syscall.RTF_STATIC != 0 {
+				entry.State = "permanent"
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
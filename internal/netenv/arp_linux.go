@@ -0,0 +1,88 @@
+package netenv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os/exec"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// neighborStates names the NUD states of the Linux neighbor cache
+var neighborStates = map[uint16]string{
+	unix.NUD_REACHABLE: "reachable",
+	unix.NUD_STALE:     "stale",
+	unix.NUD_DELAY:     "delay",
+	unix.NUD_PROBE:     "probe",
+	unix.NUD_PERMANENT: "permanent",
+	unix.NUD_NOARP:     "noarp",
+}
+
+// readARPTable dumps the neighbor cache over netlink (RTM_GETNEIGH), the
+// same source as "ip neigh"
+func readARPTable() ([]ARPEntry, error) {
+	rib, err := syscall.NetlinkRIB(unix.RTM_GETNEIGH, unix.AF_UNSPEC)
+	if err != nil {
+		if output, arpErr := exec.Command("arp", "-a").Output(); arpErr == nil {
+			return parseArpA(string(output)), nil
+		}
+		return nil, fmt.Errorf("failed to read neighbor table: %w", err)
+	}
+	messages, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse neighbor table: %w", err)
+	}
+
+	names := make(map[int]string)
+	var entries []ARPEntry
+	for _, message := range messages {
+		if message.Header.Type != unix.RTM_NEWNEIGH || len(message.Data) < unix.SizeofNdMsg {
+			continue
+		}
+		ndmsg := (*unix.NdMsg)(unsafe.Pointer(&message.Data[0]))
+		entry := ARPEntry{State: neighborStates[ndmsg.State]}
+		switch ndmsg.Family {
+		case unix.AF_INET:
+			entry.Family = "ipv4"
+		case unix.AF_INET6:
+			entry.Family = "ipv6"
+		default:
+			continue
+		}
+
+		// Route attributes: 16-bit length and type, padded to 4 bytes
+		attributes := message.Data[unix.SizeofNdMsg:]
+		for len(attributes) >= unix.SizeofRtAttr {
+			length := int(binary.NativeEndian.Uint16(attributes[0:2]))
+			kind := binary.NativeEndian.Uint16(attributes[2:4])
+			if length < unix.SizeofRtAttr || length > len(attributes) {
+				break
+			}
+			value := attributes[unix.SizeofRtAttr:length]
+			switch kind {
+			case unix.NDA_DST:
+				entry.IP = net.IP(value).String()
+			case unix.NDA_LLADDR:
+				entry.MAC = net.HardwareAddr(value).String()
+			}
+			aligned := (length + unix.NLMSG_ALIGNTO - 1) &^ (unix.NLMSG_ALIGNTO - 1)
+			if aligned > len(attributes) {
+				break
+			}
+			attributes = attributes[aligned:]
+		}
+
+		index := int(ndmsg.Ifindex)
+		if _, ok := names[index]; !ok {
+			if iface, err := net.InterfaceByIndex(index); err == nil {
+				names[index] = iface.Name
+			}
+		}
+		entry.Interface = names[index]
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
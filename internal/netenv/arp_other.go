@@ -0,0 +1,17 @@
+//go:build !linux && !windows && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package netenv
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// readARPTable falls back to "arp -a" where there is no native source
+func readARPTable() ([]ARPEntry, error) {
+	output, err := exec.Command("arp", "-a").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read neighbor table: %w", err)
+	}
+	return parseArpA(string(output)), nil
+}
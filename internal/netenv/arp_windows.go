@@ -0,0 +1,67 @@
+package netenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// getNetNeighbor lists the neighbor cache with string-valued enums; the
+// iphlpapi table behind it has no Go binding in the standard library
+const getNetNeighbor = `Get-NetNeighbor -ErrorAction SilentlyContinue | ` +
+	`Select-Object IPAddress, LinkLayerAddress, InterfaceAlias, ` +
+	`@{n='State';e={$_.State.ToString()}}, @{n='AddressFamily';e={$_.AddressFamily.ToString()}} | ` +
+	`ConvertTo-Json -Compress`
+
+// readARPTable asks PowerShell for Get-NetNeighbor, falling back to
+// "arp -a" on systems without the NetTCPIP module
+func readARPTable() ([]ARPEntry, error) {
+	output, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", getNetNeighbor).Output()
+	if err == nil {
+		if entries, err := parseNetNeighbor(output); err == nil {
+			return entries, nil
+		}
+	}
+	output, err = exec.Command("arp", "-a").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read neighbor table: %w", err)
+	}
+	return parseArpA(string(output)), nil
+}
+
+// parseNetNeighbor reads the JSON of getNetNeighbor, which is an object
+// rather than an array when there is a single entry
+func parseNetNeighbor(output []byte) ([]ARPEntry, error) {
+	type neighbor struct {
+		IPAddress        string
+		LinkLayerAddress string
+		InterfaceAlias   string
+		State            string
+		AddressFamily    string
+	}
+	var neighbors []neighbor
+	trimmed := strings.TrimSpace(string(output))
+	if strings.HasPrefix(trimmed, "{") {
+		trimmed = "[" + trimmed + "]"
+	}
+	if err := json.Unmarshal([]byte(trimmed), &neighbors); err != nil {
+		return nil, err
+	}
+
+	var entries []ARPEntry
+	for _, n := range neighbors {
+		entry := ARPEntry{
+			IP:        n.IPAddress,
+			MAC:       n.LinkLayerAddress,
+			Interface: n.InterfaceAlias,
+			State:     strings.ToLower(n.State),
+			Family:    "ipv4",
+		}
+		if n.AddressFamily == "IPv6" {
+			entry.Family = "ipv6"
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
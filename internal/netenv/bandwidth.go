@@ -0,0 +1,170 @@
+package netenv
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultBandwidthDuration is how long the probe sends
+	DefaultBandwidthDuration = 2 * time.Second
+
+	// DefaultBandwidthStreams is the number of parallel TCP connections to a
+	// peer; one stream rarely fills a link with some latency
+	DefaultBandwidthStreams = 4
+
+	// discardPort is the discard service; gateways drop datagrams to it
+	discardPort = "9"
+
+	// udpBurstPayload keeps the burst datagrams below common tunnel MTUs
+	udpBurstPayload = 1200
+
+	// scanProbeBits is the size of a port scan probe on the wire: a TCP SYN
+	// with Ethernet framing, preamble and inter-frame gap
+	scanProbeBits = 84 * 8
+
+	// scanLinkShare is the share of the link a scan may take without
+	// crowding out other traffic or tripping rate limits
+	scanLinkShare = 0.1
+)
+
+// Bandwidth probe methods
+const (
+	BandwidthTCP = "tcp" // parallel streams to a discard or iperf-style peer
+	BandwidthUDP = "udp" // a burst to the gateway's discard port
+)
+
+// BandwidthOptions selects where the throughput probe sends
+type BandwidthOptions struct {
+	Peer     string // host:port accepting and discarding TCP data; empty for a UDP burst to Gateway
+	Gateway  string
+	Duration time.Duration
+	Streams  int
+}
+
+// BandwidthEstimate is the measured send rate towards a peer or the gateway
+type BandwidthEstimate struct {
+	Target           string  `json:"target"`
+	Method           string  `json:"method"` // tcp or udp
+	Streams          int     `json:"streams,omitempty"`
+	Mbps             float64 `json:"mbps"`
+	Bytes            int64   `json:"bytes"`
+	DurationMS       float64 `json:"duration_ms"`
+	SuggestedRate    int     `json:"suggested_rate"`              // probes per second
+	SuggestedProfile string  `json:"suggested_profile,omitempty"` // filled in from the configured rate profiles
+	Error            string  `json:"error,omitempty"`
+}
+
+// EstimateBandwidth sends data as fast as it can for a short while and
+// reports the rate reached. With a peer it opens parallel TCP streams, which
+// measure the whole path; without one it sends a UDP burst to the gateway,
+// which only tells how fast the local link takes packets. Only the second
+// half of the run is counted, so filling the socket buffers at the start
+// does not inflate the rate.
+func EstimateBandwidth(opts BandwidthOptions) *BandwidthEstimate {
+	if opts.Duration <= 0 {
+		opts.Duration = DefaultBandwidthDuration
+	}
+	if opts.Streams <= 0 {
+		opts.Streams = DefaultBandwidthStreams
+	}
+
+	var conns []net.Conn
+	estimate := &BandwidthEstimate{}
+	if opts.Peer != "" {
+		estimate.Target = opts.Peer
+		estimate.Method = BandwidthTCP
+		estimate.Streams = opts.Streams
+		for i := 0; i < opts.Streams; i++ {
+			conn, err := net.DialTimeout("tcp", opts.Peer, 3*time.Second)
+			if err != nil {
+				estimate.Error = fmt.Sprintf("failed to connect to %s: %v", opts.Peer, err)
+				break
+			}
+			conns = append(conns, conn)
+		}
+	} else {
+		if opts.Gateway == "" {
+			estimate.Error = "no gateway and no peer to send to"
+			return estimate
+		}
+		estimate.Target = opts.Gateway
+		estimate.Method = BandwidthUDP
+		conn, err := net.Dial("udp", net.JoinHostPort(opts.Gateway, discardPort))
+		if err != nil {
+			estimate.Error = fmt.Sprintf("failed to send to %s: %v", opts.Gateway, err)
+		} else {
+			conns = append(conns, conn)
+		}
+	}
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+	if estimate.Error != "" {
+		return estimate
+	}
+
+	udp := estimate.Method == BandwidthUDP
+	size := 32 * 1024
+	if udp {
+		size = udpBurstPayload
+	}
+	var sent int64
+	var failure atomic.Value
+	start := time.Now()
+	deadline := start.Add(opts.Duration)
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func(conn net.Conn) {
+			defer wg.Done()
+			buffer := make([]byte, size)
+			conn.SetWriteDeadline(deadline)
+			for time.Now().Before(deadline) {
+				n, err := conn.Write(buffer)
+				atomic.AddInt64(&sent, int64(n))
+				if err != nil && udp && errors.Is(err, syscall.ECONNREFUSED) {
+					// The gateway answered an earlier datagram with port
+					// unreachable; the burst still goes out
+					continue
+				}
+				if err != nil {
+					if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+						failure.Store(err.Error())
+					}
+					return
+				}
+			}
+		}(conn)
+	}
+
+	time.Sleep(opts.Duration / 2)
+	halfway := atomic.LoadInt64(&sent)
+	midpoint := time.Now()
+	wg.Wait()
+	elapsed := time.Since(midpoint)
+
+	if e, ok := failure.Load().(string); ok {
+		estimate.Error = e
+	}
+	estimate.Bytes = atomic.LoadInt64(&sent) - halfway
+	estimate.DurationMS = float64(elapsed.Microseconds()) / 1000
+	if elapsed > 0 {
+		estimate.Mbps = float64(estimate.Bytes) * 8 / elapsed.Seconds() / 1e6
+	}
+	estimate.SuggestedRate = SuggestedScanRate(estimate.Mbps)
+	return estimate
+}
+
+// SuggestedScanRate converts a link capacity in Mbit/s into the probes per
+// second a scan can send while taking a small share of it
+func SuggestedScanRate(mbps float64) int {
+	return int(mbps * 1e6 * scanLinkShare / scanProbeBits)
+}
@@ -0,0 +1,260 @@
+package netenv
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+
+	"golang.org/x/net/icmp"
+)
+
+// Capabilities reported in the capability matrix
+const (
+	CapICMPDatagram  = "icmp_datagram"
+	CapRawIPv4       = "raw_ipv4"
+	CapRawIPv6       = "raw_ipv6"
+	CapPacketCapture = "pcap"
+	CapLowPorts      = "bind_low_ports"
+)
+
+// Capability is one entry of the capability matrix: whether the process has
+// it, how that was found out and what depends on it
+type Capability struct {
+	Name      string   `json:"name"`
+	Available bool     `json:"available"`
+	Detection string   `json:"detection"`        // how it was probed
+	Enables   []string `json:"enables"`          // operations it makes possible
+	Limits    []string `json:"limits,omitempty"` // what falls back while it is missing
+	Error     string   `json:"error,omitempty"`
+	Remedy    string   `json:"remedy,omitempty"` // how to gain it on this platform
+}
+
+// DetectCapabilityMatrix probes what the process may do on the network, in
+// a fixed order
+func DetectCapabilityMatrix() []Capability {
+	raw := detectRawIPv4()
+	return []Capability{
+		detectICMPDatagram(),
+		raw,
+		detectRawIPv6(),
+		detectPacketCapture(raw),
+		detectLowPorts(),
+	}
+}
+
+// LookupCapability returns the named entry of a matrix, or nil
+func LookupCapability(matrix []Capability, name string) *Capability {
+	for i := range matrix {
+		if matrix[i].Name == name {
+			return &matrix[i]
+		}
+	}
+	return nil
+}
+
+// Reason says why a capability is missing and how to gain it, for fallback
+// messages such as "SYN scan unavailable, using TCP connect: <reason>"
+func (c Capability) Reason() string {
+	reason := c.Name + " unavailable"
+	if c.Error != "" {
+		reason = fmt.Sprintf("%s (%s)", reason, c.Error)
+	}
+	if c.Remedy != "" {
+		reason += "; " + c.Remedy
+	}
+	return reason
+}
+
+func detectICMPDatagram() Capability {
+	c := Capability{
+		Name:      CapICMPDatagram,
+		Detection: "opened an unprivileged ICMP datagram socket (udp4)",
+		Enables:   []string{"ICMP echo and traceroute without root"},
+	}
+	conn, err := icmp.ListenPacket("udp4", "0.0.0.0")
+	if err != nil {
+		c.Error = err.Error()
+		c.Limits = []string{"traceroute needs a raw socket"}
+		if runtime.GOOS == "linux" {
+			c.Remedy = "add your group to the net.ipv4.ping_group_range sysctl"
+		}
+		return c
+	}
+	conn.Close()
+	c.Available = true
+	return c
+}
+
+func detectRawIPv4() Capability {
+	c := Capability{
+		Name:      CapRawIPv4,
+		Detection: "opened a raw ICMP socket (ip4:icmp)",
+		Enables:   []string{"SYN scan", "native ICMP discovery", "ARP discovery", "crafted packets"},
+	}
+	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		c.Error = err.Error()
+		c.Limits = []string{
+			"SYN scan falls back to TCP connect",
+			"ICMP discovery falls back to the system ping command",
+			"ARP discovery is unavailable",
+		}
+		c.Remedy = rawSocketRemedy()
+		return c
+	}
+	conn.Close()
+	c.Available = true
+	return c
+}
+
+func detectRawIPv6() Capability {
+	c := Capability{
+		Name:      CapRawIPv6,
+		Detection: "opened a raw ICMPv6 socket (ip6:ipv6-icmp)",
+		Enables:   []string{"ICMPv6 echo and neighbor discovery on IPv6 networks"},
+	}
+	conn, err := icmp.ListenPacket("ip6:ipv6-icmp", "::")
+	if err != nil {
+		c.Error = err.Error()
+		c.Limits = []string{"IPv6 hosts are only found by TCP connect"}
+		c.Remedy = rawSocketRemedy()
+		return c
+	}
+	conn.Close()
+	c.Available = true
+	return c
+}
+
+// detectPacketCapture checks for the platform's capture facility: AF_PACKET
+// on Linux, which needs the same privilege as a raw socket, the BPF devices
+// on macOS and the BSDs, and Npcap or WinPcap on Windows
+func detectPacketCapture(raw Capability) Capability {
+	c := Capability{
+		Name:    CapPacketCapture,
+		Enables: []string{"passive discovery (ARP, LLDP/CDP listening)", "promiscuous mode"},
+	}
+	switch runtime.GOOS {
+	case "linux":
+		c.Detection = "AF_PACKET capture needs CAP_NET_RAW, as does the raw IPv4 socket"
+		c.Available = raw.Available
+		c.Error = raw.Error
+	case "darwin", "freebsd", "openbsd", "netbsd":
+		c.Detection = "opened a /dev/bpf device"
+		devices, _ := filepath.Glob("/dev/bpf*")
+		c.Error = "no /dev/bpf devices"
+		for _, device := range devices {
+			f, err := os.OpenFile(device, os.O_RDWR, 0)
+			if err == nil {
+				f.Close()
+				c.Detection = "opened " + device
+				if os.Geteuid() != 0 && chmodBPFInstalled() {
+					c.Detection += ", which Wireshark's ChmodBPF opened to its group"
+				}
+				c.Available = true
+				c.Error = ""
+				break
+			}
+			c.Error = err.Error()
+			if !errors.Is(err, syscall.EBUSY) {
+				if reason, remedy := diagnoseBPFAccess(device); reason != "" {
+					c.Error += "; " + reason
+					c.Remedy = remedy
+				}
+				break
+			}
+		}
+	case "windows":
+		c.Detection = "found the Npcap or WinPcap driver library"
+		if library := NpcapLibrary(); library != "" {
+			c.Detection = "found " + library
+			c.Available = true
+		} else {
+			c.Error = "wpcap.dll not found"
+		}
+	default:
+		c.Detection = "not detected on " + runtime.GOOS
+	}
+
+	if !c.Available {
+		c.Limits = []string{"passive discovery is unavailable; hosts are only found by probing"}
+		switch runtime.GOOS {
+		case "windows":
+			c.Remedy = "install Npcap from https://npcap.com"
+		case "darwin":
+			if c.Remedy == "" {
+				c.Remedy = "run with sudo, or install Wireshark's ChmodBPF helper"
+			}
+		default:
+			c.Remedy = rawSocketRemedy()
+		}
+	}
+	return c
+}
+
+// NpcapLibrary returns the path of the Npcap (or legacy WinPcap) capture
+// library on Windows, or "" when neither is installed
+func NpcapLibrary() string {
+	if runtime.GOOS != "windows" {
+		return ""
+	}
+	root := os.Getenv("SystemRoot")
+	for _, library := range []string{
+		filepath.Join(root, "System32", "Npcap", "wpcap.dll"),
+		filepath.Join(root, "System32", "wpcap.dll"),
+	} {
+		if _, err := os.Stat(library); err == nil {
+			return library
+		}
+	}
+	return ""
+}
+
+// detectLowPorts binds a UDP port below 1024 on the loopback address,
+// moving on while the ports are taken
+func detectLowPorts() Capability {
+	c := Capability{
+		Name:    CapLowPorts,
+		Enables: []string{"fixed source ports below 1024 (such as 53 or 88) that some firewalls trust"},
+	}
+	for port := 1023; port > 1013; port-- {
+		address := fmt.Sprintf("127.0.0.1:%d", port)
+		conn, err := net.ListenPacket("udp4", address)
+		if err == nil {
+			conn.Close()
+			c.Detection = "bound " + address
+			c.Available = true
+			return c
+		}
+		c.Detection = "tried to bind " + address
+		c.Error = err.Error()
+		if !errors.Is(err, syscall.EADDRINUSE) {
+			break
+		}
+	}
+
+	c.Limits = []string{"probes use ephemeral source ports"}
+	switch runtime.GOOS {
+	case "linux":
+		c.Remedy = "grant CAP_NET_BIND_SERVICE or lower the net.ipv4.ip_unprivileged_port_start sysctl"
+	case "windows":
+		c.Remedy = "run as Administrator"
+	default:
+		c.Remedy = "run with sudo"
+	}
+	return c
+}
+
+// rawSocketRemedy says how to get raw sockets on this platform
+func rawSocketRemedy() string {
+	switch runtime.GOOS {
+	case "linux":
+		return "run as root or grant CAP_NET_RAW with: netcrate privileges grant"
+	case "windows":
+		return "run as Administrator"
+	}
+	return "run with sudo"
+}
@@ -0,0 +1,69 @@
+package netenv
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// Where Wireshark installs ChmodBPF, the launch daemon that hands the BPF
+// devices to the access_bpf group at boot
+var chmodBPFPaths = []string{
+	"/Library/LaunchDaemons/org.wireshark.ChmodBPF.plist",
+	"/Library/Application Support/Wireshark/ChmodBPF/ChmodBPF",
+}
+
+// chmodBPFInstalled reports whether Wireshark's ChmodBPF helper is installed
+func chmodBPFInstalled() bool {
+	for _, path := range chmodBPFPaths {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// diagnoseBPFAccess explains why device could not be opened for capture,
+// step by step through what ChmodBPF sets up: the helper itself, the group
+// permissions it applies at boot, the user's membership of that group and
+// whether this login session has picked the membership up yet
+func diagnoseBPFAccess(device string) (reason, remedy string) {
+	info, err := os.Stat(device)
+	if err != nil {
+		return "", ""
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", ""
+	}
+
+	if !chmodBPFInstalled() {
+		return "the BPF devices are only accessible to root",
+			"install Wireshark's ChmodBPF helper (brew install --cask wireshark-chmodbpf, or \"Install ChmodBPF\" from the Wireshark disk image), then log out and back in; or run with sudo"
+	}
+	if stat.Gid == 0 || info.Mode().Perm()&0060 != 0060 {
+		return "ChmodBPF is installed but has not opened the BPF devices to its group since boot",
+			"start it with: sudo launchctl kickstart -k system/org.wireshark.ChmodBPF"
+	}
+
+	group := strconv.Itoa(int(stat.Gid))
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+	groups, _ := os.Getgroups()
+	for _, gid := range append(groups, os.Getegid()) {
+		if uint32(gid) == stat.Gid {
+			// In the group and still refused; nothing ChmodBPF can fix
+			return "", ""
+		}
+	}
+	// The directory service knows memberships this session does not have yet
+	if exec.Command("dseditgroup", "-o", "checkmember", group).Run() == nil {
+		return "this login session started before you joined " + group,
+			"log out and back in so the " + group + " membership applies"
+	}
+	return "you are not a member of " + group + ", which ChmodBPF gives the BPF devices to",
+		"add yourself with: sudo dseditgroup -o edit -a $USER -t user " + group + ", then log out and back in"
+}
@@ -0,0 +1,13 @@
+//go:build !darwin
+
+package netenv
+
+// chmodBPFInstalled reports false: ChmodBPF is a macOS helper
+func chmodBPFInstalled() bool {
+	return false
+}
+
+// diagnoseBPFAccess has nothing to add outside macOS
+func diagnoseBPFAccess(device string) (reason, remedy string) {
+	return "", ""
+}
@@ -0,0 +1,116 @@
+package netenv
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// connectivityProbe answers 204 No Content when reached directly; a
+	// captive portal redirects it or serves its login page instead
+	connectivityProbe = "http://connectivitycheck.gstatic.com/generate_204"
+
+	// nxdomainZone has no wildcard records, so a random name under it must
+	// not resolve
+	nxdomainZone = "example.com"
+)
+
+// Connectivity is what an interface reaches beyond the local network
+type Connectivity struct {
+	Internet      bool     `json:"internet"`                 // the probe got its 204
+	CaptivePortal bool     `json:"captive_portal"`           // the probe was redirected or answered by someone else
+	PortalURL     string   `json:"portal_url,omitempty"`     // where a portal redirected to
+	DNSHijack     bool     `json:"dns_hijack"`               // the resolver answers for names that do not exist
+	HijackAddress string   `json:"hijack_address,omitempty"` // what it answered with
+	LatencyMS     float64  `json:"latency_ms,omitempty"`     // of the probe
+	Errors        []string `json:"errors,omitempty"`
+}
+
+// CheckConnectivity probes internet reachability from the first IPv4
+// address of an interface: it fetches a 204 page without following
+// redirects to spot captive portals, and resolves a name that does not
+// exist to spot DNS servers that rewrite NXDOMAIN. Binding the source
+// address selects the interface where the routing table allows it.
+func CheckConnectivity(iface NetworkInterface, timeout time.Duration) *Connectivity {
+	result := &Connectivity{}
+
+	var source net.IP
+	for _, address := range iface.Addresses {
+		if ip := net.ParseIP(address.IP); ip != nil && ip.To4() != nil {
+			source = ip
+			break
+		}
+	}
+	if source == nil {
+		result.Errors = append(result.Errors, "no IPv4 address")
+		return result
+	}
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialer := net.Dialer{Timeout: timeout, LocalAddr: &net.UDPAddr{IP: source}}
+			if strings.HasPrefix(network, "tcp") {
+				dialer.LocalAddr = &net.TCPAddr{IP: source}
+			}
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+
+	// DNS hijacking: a random name in a zone without wildcards
+	label := make([]byte, 8)
+	rand.Read(label)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if addresses, err := resolver.LookupHost(ctx, hex.EncodeToString(label)+"."+nxdomainZone); err == nil && len(addresses) > 0 {
+		result.DNSHijack = true
+		result.HijackAddress = addresses[0]
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy: nil, // a proxy would hide the portal
+			DialContext: (&net.Dialer{
+				Timeout:   timeout,
+				LocalAddr: &net.TCPAddr{IP: source},
+				Resolver:  resolver,
+			}).DialContext,
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	start := time.Now()
+	resp, err := client.Get(connectivityProbe)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("connectivity probe: %v", err))
+		return result
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		result.Internet = true
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		result.CaptivePortal = true
+		result.PortalURL = resp.Header.Get("Location")
+	case resp.StatusCode == http.StatusOK:
+		// A login page served in place of the 204
+		result.CaptivePortal = true
+	default:
+		result.Errors = append(result.Errors, fmt.Sprintf("connectivity probe: %s", resp.Status))
+	}
+	return result
+}
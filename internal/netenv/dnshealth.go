@@ -0,0 +1,88 @@
+package netenv
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// dnsHealthName is resolved through each DNS server; it always exists
+	dnsHealthName = "example.com"
+
+	// slowResolver is the answer time above which a DNS server counts as slow
+	slowResolver = 200 * time.Millisecond
+)
+
+// DNS server states reported by CheckDNSServers
+const (
+	DNSHealthy = "ok"
+	DNSSlow    = "slow"
+	DNSFailing = "failing"
+)
+
+// DNSServerHealth is the result of resolving a known name through one DNS
+// server
+type DNSServerHealth struct {
+	Server    string  `json:"server"`
+	Status    string  `json:"status"` // ok, slow or failing
+	LatencyMS float64 `json:"latency_ms,omitempty"`
+	Answer    string  `json:"answer,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// CheckDNSServers resolves a known name through each server at once,
+// bypassing the system's resolver order and caches
+func CheckDNSServers(servers []string, timeout time.Duration) []DNSServerHealth {
+	results := make([]DNSServerHealth, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func(i int, server string) {
+			defer wg.Done()
+			results[i] = checkDNSServer(server, timeout)
+		}(i, server)
+	}
+	wg.Wait()
+	return results
+}
+
+func checkDNSServer(server string, timeout time.Duration) DNSServerHealth {
+	health := DNSServerHealth{Server: server}
+	address := net.JoinHostPort(server, "53")
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			dialer := net.Dialer{Timeout: timeout}
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	addresses, err := resolver.LookupHost(ctx, dnsHealthName)
+	elapsed := time.Since(start)
+	if err != nil {
+		health.Status = DNSFailing
+		health.Error = err.Error()
+		// The error names the system resolver the dial was redirected from
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			health.Error = dnsErr.Err
+		}
+		return health
+	}
+
+	health.LatencyMS = float64(elapsed.Microseconds()) / 1000
+	if len(addresses) > 0 {
+		health.Answer = addresses[0]
+	}
+	health.Status = DNSHealthy
+	if elapsed > slowResolver {
+		health.Status = DNSSlow
+	}
+	return health
+}
@@ -0,0 +1,402 @@
+package netenv
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSTUNServers answer STUN binding requests when none are configured
+var DefaultSTUNServers = []string{
+	"stun.l.google.com:19302",
+	"stun1.l.google.com:19302",
+	"stun.cloudflare.com:3478",
+}
+
+// DefaultIPServices are asked for the public address when no STUN server
+// answers, e.g. because outbound UDP is filtered
+var DefaultIPServices = []string{
+	publicIPService,
+	"https://ifconfig.me/ip",
+	"https://icanhazip.com",
+}
+
+// NAT behaviours reported by DetectEgress
+const (
+	NATNone                = "none"                 // the public address is on this host
+	NATEndpointIndependent = "endpoint-independent" // same mapping for every destination (full, restricted or port-restricted cone)
+	NATSymmetric           = "symmetric"            // a new mapping per destination; peer-to-peer traffic rarely gets through
+	NATUnknown             = "unknown"              // translated, but fewer than two STUN servers answered
+)
+
+const (
+	stunMagicCookie     = 0x2112A442
+	stunBindingRequest  = 0x0001
+	stunBindingResponse = 0x0101
+	stunMappedAddress   = 0x0001
+	stunXORMapped       = 0x0020
+
+	ssdpAddress = "239.255.255.250:1900"
+	natPMPPort  = 5351
+)
+
+// EgressOptions selects the endpoints DetectEgress contacts
+type EgressOptions struct {
+	STUNServers []string      // host:port; DefaultSTUNServers when empty
+	IPServices  []string      // DefaultIPServices when empty
+	Timeout     time.Duration // per probe
+	Gateway     string        // default gateway, asked over NAT-PMP
+}
+
+// EgressInfo describes how this host reaches the internet and what its
+// network exposes outward
+type EgressInfo struct {
+	PublicIP   string   `json:"public_ip,omitempty"`
+	Source     string   `json:"source,omitempty"`   // STUN server or service that reported it
+	LocalIP    string   `json:"local_ip,omitempty"` // source address of outbound traffic
+	NAT        string   `json:"nat"`
+	UPnP       bool     `json:"upnp"`                  // an Internet Gateway Device answered SSDP
+	UPnPServer string   `json:"upnp_server,omitempty"` // its SERVER header
+	NATPMP     bool     `json:"natpmp"`                // the gateway answers NAT-PMP
+	NATPMPIP   string   `json:"natpmp_external_ip,omitempty"`
+	DoubleNAT  bool     `json:"double_nat,omitempty"` // the gateway's external address is not the public one (e.g. carrier-grade NAT)
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// stunMapping is the address a STUN server saw a request come from
+type stunMapping struct {
+	server  string
+	address *net.UDPAddr
+}
+
+// DetectEgress looks up the public address over STUN, falling back to the
+// HTTPS services, classifies the NAT in between and checks whether the
+// gateway offers UPnP or NAT-PMP port mapping. It contacts external servers.
+func DetectEgress(opts EgressOptions) *EgressInfo {
+	if len(opts.STUNServers) == 0 {
+		opts.STUNServers = DefaultSTUNServers
+	}
+	if len(opts.IPServices) == 0 {
+		opts.IPServices = DefaultIPServices
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 3 * time.Second
+	}
+
+	info := &EgressInfo{NAT: NATUnknown}
+
+	// The port mapping probes only wait for answers, so run them meanwhile
+	var wg sync.WaitGroup
+	wg.Add(2)
+	var upnpServer string
+	var upnpErr error
+	go func() {
+		defer wg.Done()
+		upnpServer, upnpErr = discoverIGD(opts.Timeout)
+	}()
+	var natpmpIP string
+	var natpmpErr error
+	go func() {
+		defer wg.Done()
+		if opts.Gateway == "" {
+			natpmpErr = errors.New("no default gateway")
+			return
+		}
+		natpmpIP, natpmpErr = natPMPExternalAddress(opts.Gateway, opts.Timeout)
+	}()
+
+	mappings, localPort, errs := querySTUN(opts.STUNServers, opts.Timeout)
+	info.Errors = append(info.Errors, errs...)
+	if len(mappings) > 0 {
+		info.PublicIP = mappings[0].address.IP.String()
+		info.Source = "stun:" + mappings[0].server
+		info.LocalIP = localAddress(mappings[0].server)
+		info.NAT = classifyNAT(mappings, info.LocalIP, localPort)
+	} else {
+		for _, service := range opts.IPServices {
+			address, err := lookupPublicIPAt(service, opts.Timeout)
+			if err != nil {
+				info.Errors = append(info.Errors, err.Error())
+				continue
+			}
+			info.PublicIP = address
+			info.Source = service
+			if u, err := url.Parse(service); err == nil {
+				info.LocalIP = localAddress(u.Host)
+			}
+			if address == info.LocalIP {
+				info.NAT = NATNone
+			}
+			break
+		}
+	}
+
+	wg.Wait()
+	if upnpErr == nil {
+		info.UPnP = true
+		info.UPnPServer = upnpServer
+	}
+	if natpmpErr == nil {
+		info.NATPMP = true
+		info.NATPMPIP = natpmpIP
+		info.DoubleNAT = info.PublicIP != "" && natpmpIP != info.PublicIP
+	}
+
+	return info
+}
+
+// querySTUN sends binding requests from one socket until two servers at
+// different addresses have answered, returning their mappings and the
+// local port they saw
+func querySTUN(servers []string, timeout time.Duration) ([]stunMapping, int, []string) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, 0, []string{fmt.Sprintf("STUN: %v", err)}
+	}
+	defer conn.Close()
+	localPort := conn.LocalAddr().(*net.UDPAddr).Port
+
+	var mappings []stunMapping
+	var errs []string
+	asked := make(map[string]bool)
+	for _, server := range servers {
+		if len(mappings) == 2 {
+			break
+		}
+		addr, err := net.ResolveUDPAddr("udp4", server)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("STUN %s: %v", server, err))
+			continue
+		}
+		if asked[addr.String()] {
+			continue
+		}
+		asked[addr.String()] = true
+
+		mapped, err := stunBinding(conn, addr, timeout)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("STUN %s: %v", server, err))
+			continue
+		}
+		mappings = append(mappings, stunMapping{server: server, address: mapped})
+	}
+	return mappings, localPort, errs
+}
+
+// stunBinding sends a STUN binding request (RFC 5389) and returns the
+// mapped address of the answer
+func stunBinding(conn *net.UDPConn, server *net.UDPAddr, timeout time.Duration) (*net.UDPAddr, error) {
+	request := make([]byte, 20)
+	binary.BigEndian.PutUint16(request[0:2], stunBindingRequest)
+	binary.BigEndian.PutUint32(request[4:8], stunMagicCookie)
+	if _, err := rand.Read(request[8:20]); err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteToUDP(request, server); err != nil {
+		return nil, err
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	buffer := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			return nil, err
+		}
+		// Skip late answers to an earlier request
+		if !from.IP.Equal(server.IP) || n < 20 || !bytes.Equal(buffer[8:20], request[8:20]) {
+			continue
+		}
+		return parseSTUNResponse(buffer[:n])
+	}
+}
+
+// parseSTUNResponse returns the (XOR-)MAPPED-ADDRESS of a binding response
+func parseSTUNResponse(message []byte) (*net.UDPAddr, error) {
+	if len(message) < 20 || binary.BigEndian.Uint16(message[0:2]) != stunBindingResponse {
+		return nil, errors.New("not a STUN binding response")
+	}
+	length := int(binary.BigEndian.Uint16(message[2:4]))
+	if 20+length > len(message) {
+		return nil, errors.New("truncated STUN response")
+	}
+
+	var mapped *net.UDPAddr
+	attributes := message[20 : 20+length]
+	for len(attributes) >= 4 {
+		kind := binary.BigEndian.Uint16(attributes[0:2])
+		size := int(binary.BigEndian.Uint16(attributes[2:4]))
+		if 4+size > len(attributes) {
+			break
+		}
+		value := attributes[4 : 4+size]
+		switch kind {
+		case stunXORMapped:
+			if address := stunAddress(value, message[4:20]); address != nil {
+				// XOR-MAPPED-ADDRESS takes precedence
+				return address, nil
+			}
+		case stunMappedAddress:
+			mapped = stunAddress(value, nil)
+		}
+		// Attributes are padded to four bytes
+		next := 4 + (size+3)&^3
+		if next > len(attributes) {
+			break
+		}
+		attributes = attributes[next:]
+	}
+	if mapped == nil {
+		return nil, errors.New("STUN response without a mapped address")
+	}
+	return mapped, nil
+}
+
+// stunAddress decodes an address attribute; key is the magic cookie and
+// transaction ID an XOR-MAPPED-ADDRESS is obfuscated with
+func stunAddress(value, key []byte) *net.UDPAddr {
+	if len(value) < 8 {
+		return nil
+	}
+	size := net.IPv4len
+	if value[1] == 0x02 {
+		size = net.IPv6len
+	}
+	if len(value) < 4+size {
+		return nil
+	}
+	port := binary.BigEndian.Uint16(value[2:4])
+	ip := make(net.IP, size)
+	copy(ip, value[4:4+size])
+	if key != nil {
+		port ^= uint16(stunMagicCookie >> 16)
+		for i := range ip {
+			ip[i] ^= key[i]
+		}
+	}
+	return &net.UDPAddr{IP: ip, Port: int(port)}
+}
+
+// classifyNAT compares the mappings two STUN servers saw with the local
+// address they were sent from
+func classifyNAT(mappings []stunMapping, localIP string, localPort int) string {
+	first := mappings[0].address
+	if first.IP.String() == localIP && first.Port == localPort {
+		return NATNone
+	}
+	if len(mappings) < 2 {
+		return NATUnknown
+	}
+	second := mappings[1].address
+	if first.IP.Equal(second.IP) && first.Port == second.Port {
+		return NATEndpointIndependent
+	}
+	return NATSymmetric
+}
+
+// localAddress returns the source address this host uses towards a
+// host:port or host; connecting a UDP socket sends nothing
+func localAddress(host string) string {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "443")
+	}
+	conn, err := net.Dial("udp4", host)
+	if err != nil {
+		return ""
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String()
+}
+
+// lookupPublicIPAt asks an HTTP(S) service for the public address
+func lookupPublicIPAt(service string, timeout time.Duration) (string, error) {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(service)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", service, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", service, resp.Status)
+	}
+
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	address := strings.TrimSpace(line)
+	if net.ParseIP(address) == nil {
+		return "", fmt.Errorf("%s returned %q", service, address)
+	}
+	return address, nil
+}
+
+// discoverIGD multicasts an SSDP search for a UPnP Internet Gateway Device
+// and returns the SERVER header of the first answer
+func discoverIGD(timeout time.Duration) (string, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	group, err := net.ResolveUDPAddr("udp4", ssdpAddress)
+	if err != nil {
+		return "", err
+	}
+	search := "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + ssdpAddress + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 2\r\n" +
+		"ST: urn:schemas-upnp-org:device:InternetGatewayDevice:1\r\n\r\n"
+	if _, err := conn.WriteToUDP([]byte(search), group); err != nil {
+		return "", err
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	buffer := make([]byte, 2048)
+	n, _, err := conn.ReadFromUDP(buffer)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buffer[:n])), nil)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get("Server"), nil
+}
+
+// natPMPExternalAddress asks the gateway for its external address over
+// NAT-PMP (RFC 6886)
+func natPMPExternalAddress(gateway string, timeout time.Duration) (string, error) {
+	conn, err := net.DialTimeout("udp4", net.JoinHostPort(gateway, fmt.Sprint(natPMPPort)), timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	// Version 0, opcode 0: external address request
+	if _, err := conn.Write([]byte{0, 0}); err != nil {
+		return "", err
+	}
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	response := make([]byte, 16)
+	n, err := conn.Read(response)
+	if err != nil {
+		return "", err
+	}
+	if n < 12 || response[1] != 128 {
+		return "", errors.New("not a NAT-PMP response")
+	}
+	if code := binary.BigEndian.Uint16(response[2:4]); code != 0 {
+		return "", fmt.Errorf("NAT-PMP result code %d", code)
+	}
+	return net.IP(response[8:12]).String(), nil
+}
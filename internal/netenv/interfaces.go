@@ -12,14 +12,18 @@ import (
 
 // NetworkInterface represents a network interface
 type NetworkInterface struct {
-	Name         string    `json:"name"`
-	DisplayName  string    `json:"display_name"`
-	MacAddress   string    `json:"mac_address"`
-	MTU          int       `json:"mtu"`
-	Status       string    `json:"status"`
-	Type         string    `json:"type"`
-	Addresses    []Address `json:"addresses"`
-	Gateway      *Gateway  `json:"gateway,omitempty"`
+	Name         string        `json:"name"`
+	DisplayName  string        `json:"display_name"`
+	MacAddress   string        `json:"mac_address"`
+	MTU          int           `json:"mtu"`
+	Status       string        `json:"status"`
+	Type         string        `json:"type"`
+	Addresses    []Address     `json:"addresses"`
+	Gateway      *Gateway      `json:"gateway,omitempty"`
+	Overlay      string        `json:"overlay,omitempty"`      // VPN or virtual network technology, e.g. wireguard, docker
+	WiFi         *WiFiInfo     `json:"wifi,omitempty"`         // set by DetectNetworkEnvironment for wireless interfaces
+	Connectivity *Connectivity `json:"connectivity,omitempty"` // set by CheckConnectivity
+	Neighbor     *Neighbor     `json:"neighbor,omitempty"`     // switch announced over LLDP/CDP, set by DiscoverNeighbor
 }
 
 // Address represents an IP address configuration
@@ -44,6 +48,10 @@ type DetectResult struct {
 	Recommended   string            `json:"recommended"`
 	SystemInfo    SystemInfo        `json:"system_info"`
 	Capabilities  Capabilities      `json:"capabilities"`
+	Routes        []Route           `json:"routes,omitempty"`
+	DNSHealth     []DNSServerHealth `json:"dns_health,omitempty"` // only with --ping-test
+	Egress        *EgressInfo       `json:"egress,omitempty"` // only with --egress
+	Bandwidth     *BandwidthEstimate `json:"bandwidth,omitempty"` // only with --bandwidth
 }
 
 // SystemInfo represents system network information
@@ -56,9 +64,10 @@ type SystemInfo struct {
 
 // Capabilities represents network capabilities
 type Capabilities struct {
-	RawSocket        bool `json:"raw_socket"`
-	PromiscuousMode  bool `json:"promiscuous_mode"`
-	PacketCapture    bool `json:"packet_capture"`
+	RawSocket        bool         `json:"raw_socket"`
+	PromiscuousMode  bool         `json:"promiscuous_mode"`
+	PacketCapture    bool         `json:"packet_capture"`
+	Matrix           []Capability `json:"matrix"` // per-capability detail behind the flags above
 }
 
 // GetActiveInterfaces returns all active network interfaces
@@ -68,6 +77,16 @@ func GetActiveInterfaces() ([]NetworkInterface, error) {
 		return nil, fmt.Errorf("failed to get network interfaces: %w", err)
 	}
 
+	// Each interface gets the gateway of its own default route; without a
+	// routing table every interface shares the system default gateway
+	routes, _ := GetRoutes()
+	gateways := make(map[string]string)
+	for _, route := range DefaultGateways(routes) {
+		if _, ok := gateways[route.Interface]; !ok && route.Gateway != "" {
+			gateways[route.Interface] = route.Gateway
+		}
+	}
+
 	var result []NetworkInterface
 	for _, iface := range interfaces {
 		if iface.Flags&net.FlagUp == 0 {
@@ -123,11 +142,20 @@ func GetActiveInterfaces() ([]NetworkInterface, error) {
 			netIface.Addresses = append(netIface.Addresses, address)
 		}
 
+		if netIface.Type != "loopback" {
+			if ifaceType, overlay := classifyOverlay(netIface.Name, netIface.Addresses); overlay != "" {
+				netIface.Type = ifaceType
+				netIface.Overlay = overlay
+			}
+		}
+
 		// Only include interfaces with at least one IPv4 address
 		if len(netIface.Addresses) > 0 {
-			// Try to detect gateway
-			gateway := detectGateway(netIface.Name)
-			if gateway != nil {
+			if len(routes) > 0 {
+				if gateway, ok := gateways[netIface.Name]; ok {
+					netIface.Gateway = &Gateway{IP: gateway}
+				}
+			} else if gateway := detectGateway(netIface.Name); gateway != nil {
 				netIface.Gateway = gateway
 			}
 
@@ -145,6 +173,8 @@ func DetectNetworkEnvironment() (*DetectResult, error) {
 		return nil, fmt.Errorf("failed to detect interfaces: %w", err)
 	}
 
+	routes, _ := GetRoutes()
+
 	// Get system information
 	hostname, _ := exec.Command("hostname").Output()
 	
@@ -154,12 +184,28 @@ func DetectNetworkEnvironment() (*DetectResult, error) {
 		DNSServers:   detectDNSServers(),
 		DefaultRoute: detectDefaultRoute(),
 	}
+	if defaults := DefaultGateways(routes); len(defaults) > 0 {
+		systemInfo.DefaultRoute = defaults[0].Interface
+	}
 
 	// Detect capabilities
+	matrix := DetectCapabilityMatrix()
 	capabilities := Capabilities{
-		RawSocket:       checkRawSocketCapability(),
-		PromiscuousMode: checkPromiscuousCapability(),
-		PacketCapture:   checkPacketCaptureCapability(),
+		RawSocket:       LookupCapability(matrix, CapRawIPv4).Available,
+		PromiscuousMode: LookupCapability(matrix, CapPacketCapture).Available,
+		PacketCapture:   LookupCapability(matrix, CapPacketCapture).Available,
+		Matrix:          matrix,
+	}
+
+	// Wireless link details; en* on macOS may be either kind
+	for i := range interfaces {
+		if interfaces[i].Type == "loopback" || interfaces[i].Overlay != "" {
+			continue
+		}
+		if wifi := DetectWiFi(interfaces[i].Name); wifi != nil {
+			interfaces[i].WiFi = wifi
+			interfaces[i].Type = "wireless"
+		}
 	}
 
 	// Find recommended interface
@@ -170,6 +216,7 @@ func DetectNetworkEnvironment() (*DetectResult, error) {
 		Recommended:  recommended,
 		SystemInfo:   systemInfo,
 		Capabilities: capabilities,
+		Routes:       routes,
 	}, nil
 }
 
@@ -292,6 +339,19 @@ func detectGateway(interfaceName string) *Gateway {
 		cmd = exec.Command("route", "-n", "get", "default")
 	case "linux":
 		cmd = exec.Command("ip", "route", "show", "default")
+	case "windows":
+		// No command prints the default route alone; take it from the
+		// routing table
+		routes, err := GetRoutes()
+		if err != nil {
+			return nil
+		}
+		for _, route := range routes {
+			if route.IsDefault() && route.Gateway != "" && route.Interface == interfaceName {
+				return &Gateway{IP: route.Gateway}
+			}
+		}
+		return nil
 	default:
 		return nil
 	}
@@ -336,6 +396,11 @@ func detectGateway(interfaceName string) *Gateway {
 func detectDNSServers() []string {
 	var servers []string
 	
+	if runtime.GOOS == "windows" {
+		servers, _ = iphlpapiDNSServers()
+		return servers
+	}
+
 	// Try to read from /etc/resolv.conf (Unix-like systems)
 	if content, err := exec.Command("cat", "/etc/resolv.conf").Output(); err == nil {
 		lines := strings.Split(string(content), "\n")
@@ -397,26 +462,6 @@ func findRecommendedInterface(interfaces []NetworkInterface) string {
 	return candidates[0].Name
 }
 
-func checkRawSocketCapability() bool {
-	// Try to create a raw socket (this will fail without privileges)
-	conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0")
-	if err != nil {
-		return false
-	}
-	conn.Close()
-	return true
-}
-
-func checkPromiscuousCapability() bool {
-	// This is a simplified check - actual implementation would be more complex
-	return checkRawSocketCapability()
-}
-
-func checkPacketCaptureCapability() bool {
-	// Check if we can open packet capture (simplified)
-	return checkRawSocketCapability()
-}
-
 // PingGateway attempts to ping the gateway to measure RTT
 func PingGateway(gateway *Gateway) error {
 	if gateway == nil || gateway.IP == "" {
@@ -430,6 +475,8 @@ func PingGateway(gateway *Gateway) error {
 	switch runtime.GOOS {
 	case "darwin", "linux":
 		cmd = exec.Command("ping", "-c", "1", "-W", "1000", gateway.IP)
+	case "windows":
+		cmd = exec.Command("ping", "-n", "1", "-w", "1000", gateway.IP)
 	default:
 		return fmt.Errorf("ping not supported on %s", runtime.GOOS)
 	}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package netenv
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func iphlpapiRoutes() ([]Route, error) {
+	return nil, fmt.Errorf("iphlpapi is not available on %s", runtime.GOOS)
+}
+
+func iphlpapiDNSServers() ([]string, error) {
+	return nil, fmt.Errorf("iphlpapi is not available on %s", runtime.GOOS)
+}
@@ -0,0 +1,119 @@
+package netenv
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetIpForwardTable = windows.NewLazySystemDLL("iphlpapi.dll").NewProc("GetIpForwardTable")
+
+const (
+	// ipForwardRowSize is the size of a MIB_IPFORWARDROW: fourteen DWORDs
+	ipForwardRowSize = 14 * 4
+
+	// GetAdaptersAddresses flags missing from x/sys/windows
+	gaaFlagSkipAnycast   = 0x2
+	gaaFlagSkipMulticast = 0x4
+)
+
+// iphlpapiRoutes reads the IPv4 routing table with GetIpForwardTable, which
+// needs no console and no parsing of localized output
+func iphlpapiRoutes() ([]Route, error) {
+	var size uint32
+	var buffer []byte
+	for {
+		var table *byte
+		if len(buffer) > 0 {
+			table = &buffer[0]
+		}
+		r, _, _ := procGetIpForwardTable.Call(uintptr(unsafe.Pointer(table)), uintptr(unsafe.Pointer(&size)), 1)
+		if r == 0 {
+			break
+		}
+		if syscall.Errno(r) != windows.ERROR_INSUFFICIENT_BUFFER {
+			return nil, fmt.Errorf("GetIpForwardTable: %w", syscall.Errno(r))
+		}
+		buffer = make([]byte, size)
+	}
+	if len(buffer) < 4 {
+		return nil, nil
+	}
+
+	count := int(binary.LittleEndian.Uint32(buffer[:4]))
+	var routes []Route
+	for i := 0; i < count; i++ {
+		row := buffer[4+i*ipForwardRowSize:]
+		if len(row) < ipForwardRowSize {
+			break
+		}
+		// Addresses are in network byte order; the other fields in host order
+		dword := func(n int) uint32 { return binary.LittleEndian.Uint32(row[n*4:]) }
+		destination := net.IP(append([]byte(nil), row[0:4]...))
+		mask := net.IPMask(append([]byte(nil), row[4:8]...))
+		nextHop := net.IP(append([]byte(nil), row[12:16]...))
+		ones, _ := mask.Size()
+
+		route := Route{
+			Destination: fmt.Sprintf("%s/%d", destination, ones),
+			Metric:      int(dword(9)),
+		}
+		if iface, err := net.InterfaceByIndex(int(dword(4))); err == nil {
+			route.Interface = iface.Name
+		}
+		// Type 4 is an indirect route through a gateway; direct routes
+		// carry the local address as the next hop
+		if dword(5) == 4 {
+			route.Gateway = nextHop.String()
+		} else if !nextHop.IsUnspecified() {
+			route.Source = nextHop.String()
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// iphlpapiDNSServers lists the DNS servers of the adapters that are up,
+// from GetAdaptersAddresses
+func iphlpapiDNSServers() ([]string, error) {
+	size := uint32(15 * 1024)
+	var buffer []byte
+	for {
+		buffer = make([]byte, size)
+		err := windows.GetAdaptersAddresses(windows.AF_UNSPEC, gaaFlagSkipAnycast|gaaFlagSkipMulticast, 0,
+			(*windows.IpAdapterAddresses)(unsafe.Pointer(&buffer[0])), &size)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, windows.ERROR_BUFFER_OVERFLOW) {
+			return nil, fmt.Errorf("GetAdaptersAddresses: %w", err)
+		}
+	}
+
+	var servers []string
+	seen := make(map[string]bool)
+	for adapter := (*windows.IpAdapterAddresses)(unsafe.Pointer(&buffer[0])); adapter != nil; adapter = adapter.Next {
+		if adapter.OperStatus != windows.IfOperStatusUp {
+			continue
+		}
+		for dns := adapter.FirstDnsServerAddress; dns != nil; dns = dns.Next {
+			ip := dns.Address.IP()
+			if ip == nil || seen[ip.String()] {
+				continue
+			}
+			// Site-local IPv6 placeholders that Windows lists when no
+			// server is configured
+			if ip.Equal(net.ParseIP("fec0:0:0:ffff::1")) || ip.Equal(net.ParseIP("fec0:0:0:ffff::2")) || ip.Equal(net.ParseIP("fec0:0:0:ffff::3")) {
+				continue
+			}
+			seen[ip.String()] = true
+			servers = append(servers, ip.String())
+		}
+	}
+	return servers, nil
+}
@@ -0,0 +1,102 @@
+package netenv
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// mdnsAddr is the IPv4 multicast DNS group
+var mdnsAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// LookupMDNSName performs a multicast DNS reverse lookup for an IPv4 address
+// and returns the advertised .local name, if any.
+func LookupMDNSName(ip string, timeout time.Duration) (string, error) {
+	parsed := net.ParseIP(ip).To4()
+	if parsed == nil {
+		return "", fmt.Errorf("invalid IPv4 address: %s", ip)
+	}
+
+	reverse := fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa.", parsed[3], parsed[2], parsed[1], parsed[0])
+	name, err := dnsmessage.NewName(reverse)
+	if err != nil {
+		return "", err
+	}
+
+	// Ask for a unicast response (QU bit) so the reply comes back to our socket
+	msg := dnsmessage.Message{
+		Questions: []dnsmessage.Question{{
+			Name:  name,
+			Type:  dnsmessage.TypePTR,
+			Class: dnsmessage.ClassINET | 0x8000,
+		}},
+	}
+	query, err := msg.Pack()
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if _, err := conn.WriteToUDP(query, mdnsAddr); err != nil {
+		return "", err
+	}
+	conn.SetReadDeadline(time.Now().Add(timeout))
+
+	buf := make([]byte, 1500)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return "", err
+		}
+
+		var resp dnsmessage.Message
+		if err := resp.Unpack(buf[:n]); err != nil {
+			continue
+		}
+		for _, answer := range resp.Answers {
+			if ptr, ok := answer.Body.(*dnsmessage.PTRResource); ok && strings.EqualFold(answer.Header.Name.String(), reverse) {
+				return strings.TrimSuffix(ptr.PTR.String(), "."), nil
+			}
+		}
+	}
+}
+
+// LookupMDNSNames resolves mDNS names for several hosts concurrently.
+// Hosts that do not answer are omitted from the result.
+func LookupMDNSNames(hosts []string, timeout time.Duration, concurrency int) map[string]string {
+	if concurrency <= 0 {
+		concurrency = 16
+	}
+
+	names := make(map[string]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for _, host := range hosts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(host string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if name, err := LookupMDNSName(host, timeout); err == nil && name != "" {
+				mu.Lock()
+				names[host] = name
+				mu.Unlock()
+			}
+		}(host)
+	}
+
+	wg.Wait()
+	return names
+}
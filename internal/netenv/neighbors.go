@@ -0,0 +1,221 @@
+package netenv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
+	"time"
+)
+
+// DefaultNeighborWait covers one LLDP announcement, which switches send
+// every 30 seconds by default; CDP announces every 60
+const DefaultNeighborWait = 31 * time.Second
+
+// Link-layer discovery protocols reported in Neighbor.Protocol
+const (
+	NeighborLLDP = "lldp"
+	NeighborCDP  = "cdp"
+)
+
+const etherTypeLLDP = 0x88cc
+
+var (
+	// lldpMulticast is the nearest-bridge address, which switches do not
+	// forward
+	lldpMulticast = net.HardwareAddr{0x01, 0x80, 0xc2, 0x00, 0x00, 0x0e}
+	cdpMulticast  = net.HardwareAddr{0x01, 0x00, 0x0c, 0xcc, 0xcc, 0xcc}
+
+	// cdpSNAP is the LLC/SNAP header of CDP: Cisco OUI, protocol 0x2000
+	cdpSNAP = []byte{0xaa, 0xaa, 0x03, 0x00, 0x00, 0x0c, 0x20, 0x00}
+)
+
+// Neighbor is the switch (or other device) at the far end of a link, as it
+// announces itself over LLDP or CDP
+type Neighbor struct {
+	Protocol          string `json:"protocol"` // lldp or cdp
+	SourceMAC         string `json:"source_mac"`
+	ChassisID         string `json:"chassis_id,omitempty"`
+	SystemName        string `json:"system_name,omitempty"`
+	SystemDescription string `json:"system_description,omitempty"`
+	PortID            string `json:"port_id,omitempty"`
+	PortDescription   string `json:"port_description,omitempty"`
+	Platform          string `json:"platform,omitempty"` // hardware model, CDP only
+	VLAN              int    `json:"vlan,omitempty"`     // port or native VLAN
+	ManagementIP      string `json:"management_ip,omitempty"`
+}
+
+// DiscoverNeighbor listens on an interface for an LLDP or CDP announcement
+// and returns the first one, or nil when none arrives within wait. It needs
+// packet capture; see the pcap entry of DetectCapabilityMatrix.
+func DiscoverNeighbor(iface string, wait time.Duration) (*Neighbor, error) {
+	if wait <= 0 {
+		wait = DefaultNeighborWait
+	}
+	return listenNeighbor(iface, wait)
+}
+
+// parseDiscoveryFrame decodes an Ethernet frame carrying LLDP or CDP,
+// returning nil for any other frame
+func parseDiscoveryFrame(frame []byte) *Neighbor {
+	if len(frame) < 14 {
+		return nil
+	}
+	destination := net.HardwareAddr(frame[0:6])
+	source := net.HardwareAddr(frame[6:12]).String()
+	etherType := binary.BigEndian.Uint16(frame[12:14])
+	payload := frame[14:]
+	if etherType == 0x8100 && len(frame) >= 18 {
+		// 802.1Q tagged
+		etherType = binary.BigEndian.Uint16(frame[16:18])
+		payload = frame[18:]
+	}
+
+	switch {
+	case etherType == etherTypeLLDP:
+		neighbor := parseLLDP(payload)
+		neighbor.SourceMAC = source
+		return neighbor
+	case bytes.Equal(destination, cdpMulticast) && etherType <= 1500 && bytes.HasPrefix(payload, cdpSNAP):
+		// An 802.3 length field rather than an EtherType
+		neighbor := parseCDP(payload[len(cdpSNAP):])
+		if neighbor == nil {
+			return nil
+		}
+		neighbor.SourceMAC = source
+		return neighbor
+	}
+	return nil
+}
+
+// parseLLDP reads the TLVs of an LLDP data unit: a 7-bit type and a 9-bit
+// length, then the value
+func parseLLDP(data []byte) *Neighbor {
+	neighbor := &Neighbor{Protocol: NeighborLLDP}
+	for len(data) >= 2 {
+		header := binary.BigEndian.Uint16(data[:2])
+		tlvType, length := int(header>>9), int(header&0x1ff)
+		if tlvType == 0 || len(data) < 2+length {
+			break
+		}
+		value := data[2 : 2+length]
+		data = data[2+length:]
+
+		switch tlvType {
+		case 1: // chassis ID
+			if len(value) > 1 {
+				neighbor.ChassisID = lldpID(value[0], value[1:], 4, 5)
+			}
+		case 2: // port ID
+			if len(value) > 1 {
+				neighbor.PortID = lldpID(value[0], value[1:], 3, 4)
+			}
+		case 4:
+			neighbor.PortDescription = printable(value)
+		case 5:
+			neighbor.SystemName = printable(value)
+		case 6:
+			neighbor.SystemDescription = printable(value)
+		case 8: // management address: length, subtype (1 = IPv4), address
+			if len(value) >= 6 && value[0] == 5 && value[1] == 1 {
+				neighbor.ManagementIP = net.IP(value[2:6]).String()
+			}
+		case 127: // organizationally specific; IEEE 802.1 subtype 1 is the port VLAN ID
+			if len(value) >= 6 && bytes.Equal(value[:3], []byte{0x00, 0x80, 0xc2}) && value[3] == 1 {
+				neighbor.VLAN = int(binary.BigEndian.Uint16(value[4:6]))
+			}
+		}
+	}
+	return neighbor
+}
+
+// lldpID formats a chassis or port ID: a MAC or network address when the
+// subtype says so, otherwise the text. The subtype numbers differ between
+// chassis and port IDs.
+func lldpID(subtype byte, value []byte, macSubtype, addressSubtype byte) string {
+	if subtype == macSubtype && len(value) == 6 {
+		return net.HardwareAddr(value).String()
+	}
+	if subtype == addressSubtype && len(value) == 5 && value[0] == 1 {
+		// A network address: family 1 (IPv4)
+		return net.IP(value[1:]).String()
+	}
+	return printable(value)
+}
+
+// parseCDP reads a CDP packet after its SNAP header: version, TTL and
+// checksum, then TLVs with a 16-bit type and a 16-bit length that counts
+// the header
+func parseCDP(data []byte) *Neighbor {
+	if len(data) < 4 {
+		return nil
+	}
+	neighbor := &Neighbor{Protocol: NeighborCDP}
+	data = data[4:]
+	for len(data) >= 4 {
+		tlvType := binary.BigEndian.Uint16(data[:2])
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if length < 4 || len(data) < length {
+			break
+		}
+		value := data[4:length]
+		data = data[length:]
+
+		switch tlvType {
+		case 0x0001:
+			neighbor.SystemName = printable(value)
+			neighbor.ChassisID = neighbor.SystemName
+		case 0x0002:
+			neighbor.ManagementIP = cdpFirstIPv4(value)
+		case 0x0003:
+			neighbor.PortID = printable(value)
+		case 0x0005:
+			neighbor.SystemDescription = printable(value)
+		case 0x0006:
+			neighbor.Platform = printable(value)
+		case 0x000a:
+			if len(value) >= 2 {
+				neighbor.VLAN = int(binary.BigEndian.Uint16(value[:2]))
+			}
+		}
+	}
+	return neighbor
+}
+
+// cdpFirstIPv4 returns the first IPv4 address of a CDP address list: a
+// count, then protocol type, protocol length, protocol, address length and
+// address for each
+func cdpFirstIPv4(value []byte) string {
+	if len(value) < 4 {
+		return ""
+	}
+	count := int(binary.BigEndian.Uint32(value[:4]))
+	value = value[4:]
+	for i := 0; i < count && len(value) >= 2; i++ {
+		protoLen := int(value[1])
+		if len(value) < 2+protoLen+2 {
+			break
+		}
+		protocol := value[2 : 2+protoLen]
+		addrLen := int(binary.BigEndian.Uint16(value[2+protoLen : 4+protoLen]))
+		value = value[4+protoLen:]
+		if len(value) < addrLen {
+			break
+		}
+		if len(protocol) == 1 && protocol[0] == 0xcc && addrLen == 4 {
+			return net.IP(value[:4]).String()
+		}
+		value = value[addrLen:]
+	}
+	return ""
+}
+
+// printable trims a TLV string and drops control characters
+func printable(value []byte) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\n' {
+			return -1
+		}
+		return r
+	}, string(value)))
+}
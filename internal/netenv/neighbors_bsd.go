@@ -0,0 +1,116 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package netenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/net/bpf"
+)
+
+// listenNeighbor reads frames from a /dev/bpf device attached to the
+// interface, filtered down to LLDP and CDP. Promiscuous mode makes the NIC
+// pass the multicast groups up.
+func listenNeighbor(name string, wait time.Duration) (*Neighbor, error) {
+	device, err := openBPF()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a BPF device: %w", err)
+	}
+	defer device.Close()
+	fd := int(device.Fd())
+
+	if err := syscall.SetBpfInterface(fd, name); err != nil {
+		return nil, fmt.Errorf("failed to attach to %s: %w", name, err)
+	}
+	filter, err := bpf.Assemble(neighborFilter())
+	if err != nil {
+		return nil, err
+	}
+	program := make([]syscall.BpfInsn, len(filter))
+	for i, instruction := range filter {
+		program[i] = syscall.BpfInsn{Code: instruction.Op, Jt: instruction.Jt, Jf: instruction.Jf, K: instruction.K}
+	}
+	if err := syscall.SetBpf(fd, program); err != nil {
+		return nil, fmt.Errorf("failed to set the capture filter: %w", err)
+	}
+	// Best effort, like the multicast membership on Linux
+	syscall.SetBpfPromisc(fd, 1)
+	syscall.SetBpfImmediate(fd, 1)
+	// Wake up once a second to check the deadline
+	if err := syscall.SetBpfTimeout(fd, &syscall.Timeval{Sec: 1}); err != nil {
+		return nil, err
+	}
+	length, err := syscall.BpfBuflen(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(wait)
+	buffer := make([]byte, length)
+	for time.Now().Before(deadline) {
+		n, err := syscall.Read(fd, buffer)
+		if err != nil {
+			if err == syscall.EINTR || err == syscall.EAGAIN {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read from %s: %w", name, err)
+		}
+		// A read returns every frame captured since the last one, each
+		// behind a bpf_hdr and padded to the word alignment
+		for offset := 0; offset+int(unsafe.Sizeof(syscall.BpfHdr{})) <= n; {
+			header := (*syscall.BpfHdr)(unsafe.Pointer(&buffer[offset]))
+			start := offset + int(header.Hdrlen)
+			end := start + int(header.Caplen)
+			if end > n {
+				break
+			}
+			if neighbor := parseDiscoveryFrame(buffer[start:end]); neighbor != nil {
+				return neighbor, nil
+			}
+			offset += bpfWordAlign(int(header.Hdrlen) + int(header.Caplen))
+		}
+	}
+	return nil, nil
+}
+
+// neighborFilter passes LLDP frames, by EtherType, and CDP frames, by their
+// multicast destination, as CDP frames carry a length instead of a type
+func neighborFilter() []bpf.Instruction {
+	return []bpf.Instruction{
+		bpf.LoadAbsolute{Off: 12, Size: 2}, // EtherType
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: etherTypeLLDP, SkipTrue: 4},
+		bpf.LoadAbsolute{Off: 0, Size: 4}, // destination MAC
+		bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: 0x01000ccc, SkipTrue: 3},
+		bpf.LoadAbsolute{Off: 4, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: 0xcccc, SkipTrue: 1},
+		bpf.RetConstant{Val: 65535},
+		bpf.RetConstant{Val: 0},
+	}
+}
+
+// openBPF opens the first free /dev/bpf device; each one serves a single
+// reader and is busy while another process holds it
+func openBPF() (*os.File, error) {
+	devices, _ := filepath.Glob("/dev/bpf*")
+	err := errors.New("no /dev/bpf devices")
+	for _, device := range devices {
+		var f *os.File
+		if f, err = os.OpenFile(device, os.O_RDWR, 0); err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, syscall.EBUSY) {
+			break
+		}
+	}
+	return nil, err
+}
+
+func bpfWordAlign(x int) int {
+	return (x + syscall.BPF_ALIGNMENT - 1) &^ (syscall.BPF_ALIGNMENT - 1)
+}
@@ -0,0 +1,59 @@
+package netenv
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// listenNeighbor reads frames from a packet socket bound to the interface,
+// joined to the LLDP and CDP multicast groups so the NIC passes them up
+func listenNeighbor(name string, wait time.Duration) (*Neighbor, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	protocol := htons(unix.ETH_P_ALL)
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, int(protocol))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open packet socket: %w", err)
+	}
+	defer unix.Close(fd)
+	if err := unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: protocol, Ifindex: iface.Index}); err != nil {
+		return nil, fmt.Errorf("failed to bind to %s: %w", name, err)
+	}
+	for _, group := range []net.HardwareAddr{lldpMulticast, cdpMulticast} {
+		mreq := &unix.PacketMreq{Ifindex: int32(iface.Index), Type: unix.PACKET_MR_MULTICAST, Alen: uint16(len(group))}
+		copy(mreq.Address[:], group)
+		// Best effort: many drivers deliver these groups anyway
+		unix.SetsockoptPacketMreq(fd, unix.SOL_PACKET, unix.PACKET_ADD_MEMBERSHIP, mreq)
+	}
+
+	deadline := time.Now().Add(wait)
+	buffer := make([]byte, 9216)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, nil
+		}
+		timeout := unix.NsecToTimeval(remaining.Nanoseconds())
+		unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout)
+		n, _, err := unix.Recvfrom(fd, buffer, 0)
+		if err != nil {
+			if err == unix.EAGAIN || err == unix.EINTR {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read from %s: %w", name, err)
+		}
+		if neighbor := parseDiscoveryFrame(buffer[:n]); neighbor != nil {
+			return neighbor, nil
+		}
+	}
+}
+
+// htons converts to network byte order, as packet sockets take the protocol
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package netenv
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// listenNeighbor needs a capture facility this build does not use yet
+func listenNeighbor(name string, wait time.Duration) (*Neighbor, error) {
+	return nil, fmt.Errorf("listening for LLDP/CDP is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,88 @@
+package netenv
+
+import (
+	"net"
+	"strings"
+)
+
+// Overlay technologies reported in NetworkInterface.Overlay
+const (
+	OverlayWireGuard = "wireguard"
+	OverlayTailscale = "tailscale"
+	OverlayZeroTier  = "zerotier"
+	OverlayIPsec     = "ipsec"
+	OverlayPPP       = "ppp"
+	OverlayTunnel    = "tunnel" // tun/tap/utun: OpenVPN, IKEv2 and other system VPNs
+	OverlayDocker    = "docker"
+	OverlayBridge    = "bridge" // libvirt, VMware, VirtualBox, LXD and Kubernetes networks
+)
+
+// tailscaleRange is the shared address space Tailscale assigns from
+var tailscaleRange = &net.IPNet{IP: net.IPv4(100, 64, 0, 0).To4(), Mask: net.CIDRMask(10, 32)}
+
+// overlayPrefixes maps interface name prefixes to their overlay, checked in
+// order
+var overlayPrefixes = []struct {
+	prefix  string
+	overlay string
+}{
+	{"tailscale", OverlayTailscale},
+	{"wg", OverlayWireGuard},
+	{"zt", OverlayZeroTier},
+	{"feth", OverlayZeroTier}, // ZeroTier on macOS
+	{"ipsec", OverlayIPsec},
+	{"xfrm", OverlayIPsec},
+	{"ppp", OverlayPPP},
+	{"utun", OverlayTunnel},
+	{"tun", OverlayTunnel},
+	{"tap", OverlayTunnel},
+	{"docker", OverlayDocker},
+	{"br-", OverlayDocker}, // user-defined Docker networks
+	{"virbr", OverlayBridge},
+	{"vmnet", OverlayBridge},
+	{"vboxnet", OverlayBridge},
+	{"lxdbr", OverlayBridge},
+	{"lxcbr", OverlayBridge},
+	{"cni", OverlayBridge},
+	{"flannel", OverlayBridge},
+	{"cali", OverlayBridge},
+	{"weave", OverlayBridge},
+	{"vxlan", OverlayBridge},
+	{"veth", OverlayBridge},
+}
+
+// classifyOverlay recognizes VPN and virtual interfaces by name, and
+// Tailscale by its address range on a generic tunnel. It returns the
+// interface type ("vpn" or "virtual") and the overlay, or empty strings for
+// a physical interface.
+func classifyOverlay(name string, addresses []Address) (string, string) {
+	overlay := ""
+	for _, entry := range overlayPrefixes {
+		if strings.HasPrefix(name, entry.prefix) {
+			overlay = entry.overlay
+			break
+		}
+	}
+	if overlay == OverlayTunnel {
+		for _, address := range addresses {
+			if ip := net.ParseIP(address.IP); ip != nil && tailscaleRange.Contains(ip) {
+				overlay = OverlayTailscale
+				break
+			}
+		}
+	}
+
+	switch overlay {
+	case "":
+		return "", ""
+	case OverlayDocker, OverlayBridge:
+		return "virtual", overlay
+	}
+	return "vpn", overlay
+}
+
+// SpansRemote reports whether the interface is a tunnel: its private
+// addresses reach remote networks and infrastructure rather than a local LAN
+func (iface NetworkInterface) SpansRemote() bool {
+	return iface.Type == "vpn"
+}
@@ -0,0 +1,335 @@
+package netenv
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Route is an IPv4 entry of the system routing table
+type Route struct {
+	Destination string `json:"destination"`       // CIDR; 0.0.0.0/0 is a default route
+	Gateway     string `json:"gateway,omitempty"` // empty for directly connected networks
+	Interface   string `json:"interface"`
+	Metric      int    `json:"metric"`
+	Source      string `json:"source,omitempty"` // preferred source address, where the platform reports it
+}
+
+// IsDefault reports whether the route is a default route
+func (r Route) IsDefault() bool {
+	return r.Destination == "0.0.0.0/0"
+}
+
+// GetRoutes reads the IPv4 routing table with ip (Linux), netstat (macOS)
+// or iphlpapi (Windows, falling back to route print)
+func GetRoutes() ([]Route, error) {
+	switch runtime.GOOS {
+	case "linux":
+		output, err := exec.Command("ip", "-4", "route", "show", "table", "main").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read routing table: %w", err)
+		}
+		return parseIPRoute(string(output)), nil
+	case "darwin", "freebsd", "openbsd", "netbsd":
+		output, err := exec.Command("netstat", "-rn", "-f", "inet").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read routing table: %w", err)
+		}
+		return parseNetstatRoutes(string(output)), nil
+	case "windows":
+		if routes, err := iphlpapiRoutes(); err == nil {
+			return routes, nil
+		}
+		output, err := exec.Command("route", "print", "-4").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read routing table: %w", err)
+		}
+		return parseRoutePrint(string(output), interfaceNamesByIP()), nil
+	}
+	return nil, fmt.Errorf("reading the routing table is not supported on %s", runtime.GOOS)
+}
+
+// parseIPRoute reads the output of "ip -4 route show":
+//
+//	default via 192.168.1.1 dev wlan0 proto dhcp metric 600
+//	192.168.1.0/24 dev wlan0 proto kernel scope link src 192.168.1.23 metric 600
+func parseIPRoute(output string) []Route {
+	var routes []Route
+	var multipath Route
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var route Route
+		switch {
+		case fields[0] == "nexthop":
+			// One path of the multipath route on the lines before
+			route = multipath
+		case fields[0] == "default" || net.ParseIP(fields[0]) != nil:
+			route = Route{Destination: normalizeDestination(fields[0])}
+		default:
+			// Typed routes (blackhole, unreachable, prohibit, ...) carry no traffic
+			if _, _, err := net.ParseCIDR(fields[0]); err != nil {
+				continue
+			}
+			route = Route{Destination: fields[0]}
+		}
+		for i := 1; i+1 < len(fields); i++ {
+			switch fields[i] {
+			case "via":
+				route.Gateway = fields[i+1]
+			case "dev":
+				route.Interface = fields[i+1]
+			case "src":
+				route.Source = fields[i+1]
+			case "metric":
+				route.Metric, _ = strconv.Atoi(fields[i+1])
+			}
+		}
+		if route.Interface != "" {
+			routes = append(routes, route)
+		} else if fields[0] != "nexthop" {
+			multipath = route
+		}
+	}
+	return routes
+}
+
+// parseNetstatRoutes reads the output of "netstat -rn -f inet" on macOS and
+// the BSDs, where destinations may be abbreviated ("192.168.1" is a /24)
+//
+//	Destination        Gateway            Flags        Netif Expire
+//	default            192.168.1.1        UGScg          en0
+//	192.168.1          link#6             UCS            en0      !
+func parseNetstatRoutes(output string) []Route {
+	var routes []Route
+	columns := map[string]int{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "Destination" {
+			for i, name := range fields {
+				columns[name] = i
+			}
+			continue
+		}
+		netif, ok := columns["Netif"]
+		if !ok || len(fields) <= netif {
+			continue
+		}
+
+		destination := expandBSDDestination(fields[0])
+		if destination == "" {
+			continue
+		}
+		route := Route{Destination: destination, Interface: fields[netif]}
+		// Directly connected routes name a link or a MAC address instead
+		if gateway := fields[columns["Gateway"]]; net.ParseIP(gateway) != nil {
+			route.Gateway = gateway
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// expandBSDDestination turns "default", "10", "192.168.1" or "10.8/16" into
+// a CIDR, returning "" for anything else
+func expandBSDDestination(destination string) string {
+	if destination == "default" {
+		return "0.0.0.0/0"
+	}
+	address, bits, hasBits := strings.Cut(destination, "/")
+	octets := strings.Split(address, ".")
+	if len(octets) > 4 {
+		return ""
+	}
+	prefix := len(octets) * 8
+	for len(octets) < 4 {
+		octets = append(octets, "0")
+	}
+	ip := net.ParseIP(strings.Join(octets, ".")).To4()
+	if ip == nil {
+		return ""
+	}
+	if hasBits {
+		n, err := strconv.Atoi(bits)
+		if err != nil || n < 0 || n > 32 {
+			return ""
+		}
+		prefix = n
+	}
+	return fmt.Sprintf("%s/%d", ip.Mask(net.CIDRMask(prefix, 32)), prefix)
+}
+
+// parseRoutePrint reads the "Active Routes" of "route print -4", naming
+// interfaces by their address:
+//
+//	Network Destination        Netmask          Gateway       Interface  Metric
+//	          0.0.0.0          0.0.0.0      192.168.1.1    192.168.1.23     25
+//	      192.168.1.0    255.255.255.0         On-link     192.168.1.23    281
+func parseRoutePrint(output string, names map[string]string) []Route {
+	var routes []Route
+	active := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Active Routes"):
+			active = true
+			continue
+		case strings.HasPrefix(line, "Persistent Routes"), strings.HasPrefix(line, "==="):
+			active = false
+			continue
+		}
+		fields := strings.Fields(line)
+		if !active || len(fields) != 5 {
+			continue
+		}
+		destination := net.ParseIP(fields[0]).To4()
+		mask := net.ParseIP(fields[1]).To4()
+		if destination == nil || mask == nil {
+			continue
+		}
+		ones, _ := net.IPMask(mask).Size()
+		route := Route{
+			Destination: fmt.Sprintf("%s/%d", destination, ones),
+			Interface:   fields[3],
+			Source:      fields[3],
+		}
+		if name, ok := names[fields[3]]; ok {
+			route.Interface = name
+		}
+		if net.ParseIP(fields[2]) != nil {
+			route.Gateway = fields[2]
+		}
+		route.Metric, _ = strconv.Atoi(fields[4])
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// interfaceNamesByIP maps the local IPv4 addresses to their interface
+func interfaceNamesByIP() map[string]string {
+	names := make(map[string]string)
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return names
+	}
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() != nil {
+				names[ipnet.IP.String()] = iface.Name
+			}
+		}
+	}
+	return names
+}
+
+// normalizeDestination writes "default" and bare addresses as CIDRs
+func normalizeDestination(destination string) string {
+	if destination == "default" {
+		return "0.0.0.0/0"
+	}
+	if !strings.Contains(destination, "/") {
+		return destination + "/32"
+	}
+	return destination
+}
+
+// LookupRoute returns the route the system uses towards ip: the longest
+// matching prefix, then the lowest metric. It returns nil when no route
+// matches.
+func LookupRoute(routes []Route, ip net.IP) *Route {
+	var best *Route
+	bestOnes := -1
+	for i := range routes {
+		_, network, err := net.ParseCIDR(routes[i].Destination)
+		if err != nil || !network.Contains(ip) {
+			continue
+		}
+		ones, _ := network.Mask.Size()
+		if ones > bestOnes || (ones == bestOnes && routes[i].Metric < best.Metric) {
+			best = &routes[i]
+			bestOnes = ones
+		}
+	}
+	return best
+}
+
+// DefaultGateways returns the default routes, lowest metric first; a host
+// with several uplinks has one per interface
+func DefaultGateways(routes []Route) []Route {
+	var defaults []Route
+	for _, route := range routes {
+		if route.IsDefault() {
+			defaults = append(defaults, route)
+		}
+	}
+	sort.SliceStable(defaults, func(i, j int) bool { return defaults[i].Metric < defaults[j].Metric })
+	return defaults
+}
+
+// RouteInterface returns the interface the system routes a target through.
+// The target may be an IP, a CIDR, a range ("10.0.0.1-10.0.0.9") or a
+// hostname; it returns "" when the target cannot be resolved or no route
+// matches.
+func RouteInterface(target string) string {
+	host := target
+	if address, _, found := strings.Cut(host, "/"); found {
+		host = address
+	}
+	if start, _, found := strings.Cut(host, "-"); found && net.ParseIP(start) != nil {
+		host = start
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		addresses, err := net.LookupIP(host)
+		if err != nil {
+			return ""
+		}
+		for _, address := range addresses {
+			if address.To4() != nil {
+				ip = address
+				break
+			}
+		}
+	}
+	if ip == nil || ip.To4() == nil {
+		return ""
+	}
+
+	// Local addresses live in separate tables (Linux) or as host routes
+	// through the loopback interface, so look them up directly
+	if name, ok := interfaceNamesByIP()[ip.String()]; ok {
+		return name
+	}
+	if ip.IsLoopback() {
+		if interfaces, err := net.Interfaces(); err == nil {
+			for _, iface := range interfaces {
+				if iface.Flags&net.FlagLoopback != 0 {
+					return iface.Name
+				}
+			}
+		}
+		return ""
+	}
+
+	routes, err := GetRoutes()
+	if err != nil {
+		return ""
+	}
+	if route := LookupRoute(routes, ip.To4()); route != nil {
+		return route.Interface
+	}
+	return ""
+}
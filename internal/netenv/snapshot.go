@@ -0,0 +1,155 @@
+package netenv
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// publicIPService answers a plain GET with the caller's public address
+const publicIPService = "https://api.ipify.org"
+
+// Snapshot is a compact record of the network a run was made from
+type Snapshot struct {
+	Hostname   string   `json:"hostname,omitempty"`
+	Platform   string   `json:"platform"`
+	Interface  string   `json:"interface,omitempty"`
+	Address    string   `json:"address,omitempty"` // CIDR of the interface
+	MAC        string   `json:"mac,omitempty"`
+	Gateway    string   `json:"gateway,omitempty"`
+	SSID       string   `json:"ssid,omitempty"`
+	DNSServers []string `json:"dns_servers,omitempty"`
+	PublicIP   string   `json:"public_ip,omitempty"`
+}
+
+// TakeSnapshot records the local network context. ifaceName selects the
+// interface; when empty the interface holding the default route is used.
+// The public egress address is only looked up when publicIP is set, since
+// it contacts an external service.
+func TakeSnapshot(ifaceName string, publicIP bool) *Snapshot {
+	hostname, _ := os.Hostname()
+	snapshot := &Snapshot{
+		Hostname:   hostname,
+		Platform:   fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		DNSServers: detectDNSServers(),
+	}
+
+	if interfaces, err := GetActiveInterfaces(); err == nil {
+		if iface := snapshotInterface(interfaces, ifaceName); iface != nil {
+			snapshot.Interface = iface.Name
+			snapshot.MAC = iface.MacAddress
+			if len(iface.Addresses) > 0 {
+				address := iface.Addresses[0]
+				snapshot.Address = address.IP
+				if _, ipnet, err := net.ParseCIDR(address.Network); err == nil {
+					ones, _ := ipnet.Mask.Size()
+					snapshot.Address = fmt.Sprintf("%s/%d", address.IP, ones)
+				}
+			}
+			if iface.Gateway != nil {
+				snapshot.Gateway = iface.Gateway.IP
+			}
+			snapshot.SSID = DetectSSID(iface.Name)
+		}
+	}
+
+	if publicIP {
+		if address, err := LookupPublicIP(3 * time.Second); err == nil {
+			snapshot.PublicIP = address
+		}
+	}
+
+	return snapshot
+}
+
+// snapshotInterface picks the named interface, else the one with a gateway, else the first
+func snapshotInterface(interfaces []NetworkInterface, name string) *NetworkInterface {
+	var fallback *NetworkInterface
+	for i := range interfaces {
+		iface := &interfaces[i]
+		if iface.Type == "loopback" {
+			continue
+		}
+		if name != "" {
+			if iface.Name == name {
+				return iface
+			}
+			continue
+		}
+		if iface.Gateway != nil {
+			return iface
+		}
+		if fallback == nil {
+			fallback = iface
+		}
+	}
+	return fallback
+}
+
+// DetectSSID returns the Wi-Fi network the interface is joined to, or ""
+// when it is not wireless or the platform tools are unavailable
+func DetectSSID(ifaceName string) string {
+	var output []byte
+	var err error
+
+	switch runtime.GOOS {
+	case "linux":
+		output, err = exec.Command("iwgetid", ifaceName, "-r").Output()
+	case "darwin":
+		output, err = exec.Command("networksetup", "-getairportnetwork", ifaceName).Output()
+		if err == nil {
+			// "Current Wi-Fi Network: <ssid>"
+			if _, ssid, found := strings.Cut(string(output), ": "); found {
+				return strings.TrimSpace(ssid)
+			}
+			return ""
+		}
+	case "windows":
+		output, err = exec.Command("netsh", "wlan", "show", "interfaces").Output()
+		if err == nil {
+			for _, line := range strings.Split(string(output), "\n") {
+				key, value, found := strings.Cut(line, ":")
+				if found && strings.TrimSpace(key) == "SSID" {
+					return strings.TrimSpace(value)
+				}
+			}
+			return ""
+		}
+	}
+
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// LookupPublicIP asks an external service for the public egress address
+func LookupPublicIP(timeout time.Duration) (string, error) {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(publicIPService)
+	if err != nil {
+		return "", fmt.Errorf("public IP lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("public IP lookup failed: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+	if err != nil {
+		return "", fmt.Errorf("public IP lookup failed: %w", err)
+	}
+
+	address := strings.TrimSpace(string(body))
+	if net.ParseIP(address) == nil {
+		return "", fmt.Errorf("public IP lookup returned %q", address)
+	}
+	return address, nil
+}
@@ -0,0 +1,152 @@
+package netenv
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// TraceHop is a single hop on the path to a target
+type TraceHop struct {
+	TTL     int     `json:"ttl"`
+	Address string  `json:"address,omitempty"` // empty when the hop did not answer
+	RTT     float64 `json:"rtt,omitempty"`     // milliseconds
+	Reached bool    `json:"reached,omitempty"`
+}
+
+// Traceroute sends ICMP echo requests with increasing TTL towards an IPv4 target.
+// A raw ICMP socket is used when privileges allow; otherwise it falls back to an
+// unprivileged ICMP datagram socket, where intermediate hops may not report back.
+func Traceroute(target string, maxHops int, timeout time.Duration) ([]TraceHop, error) {
+	dst := net.ParseIP(target).To4()
+	if dst == nil {
+		return nil, fmt.Errorf("invalid IPv4 address: %s", target)
+	}
+	if maxHops <= 0 {
+		maxHops = 30
+	}
+	if timeout == 0 {
+		timeout = time.Second
+	}
+
+	network, conn, err := listenICMP()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var dstAddr net.Addr = &net.IPAddr{IP: dst}
+	if network == "udp4" {
+		dstAddr = &net.UDPAddr{IP: dst}
+	}
+
+	id := os.Getpid() & 0xffff
+	var hops []TraceHop
+
+	for ttl := 1; ttl <= maxHops; ttl++ {
+		hop := TraceHop{TTL: ttl}
+
+		if err := conn.IPv4PacketConn().SetTTL(ttl); err != nil {
+			return hops, fmt.Errorf("failed to set TTL: %w", err)
+		}
+
+		msg := icmp.Message{
+			Type: ipv4.ICMPTypeEcho,
+			Body: &icmp.Echo{ID: id, Seq: ttl, Data: []byte("netcrate")},
+		}
+		packet, err := msg.Marshal(nil)
+		if err != nil {
+			return hops, err
+		}
+
+		start := time.Now()
+		if _, err := conn.WriteTo(packet, dstAddr); err != nil {
+			return hops, fmt.Errorf("failed to send probe: %w", err)
+		}
+
+		peer, reached := readHopReply(conn, ttl, start.Add(timeout))
+		if peer != "" {
+			hop.Address = peer
+			hop.RTT = float64(time.Since(start).Nanoseconds()) / 1e6
+			hop.Reached = reached
+		}
+		hops = append(hops, hop)
+
+		if reached {
+			break
+		}
+	}
+
+	return hops, nil
+}
+
+// listenICMP opens a raw ICMP socket, falling back to an unprivileged datagram socket
+func listenICMP() (string, *icmp.PacketConn, error) {
+	if conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0"); err == nil {
+		return "ip4:icmp", conn, nil
+	}
+
+	conn, err := icmp.ListenPacket("udp4", "0.0.0.0")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open ICMP socket: %w", err)
+	}
+	return "udp4", conn, nil
+}
+
+// readHopReply waits for the reply matching seq and returns the responding address
+// and whether it came from the destination itself
+func readHopReply(conn *icmp.PacketConn, seq int, deadline time.Time) (string, bool) {
+	conn.SetReadDeadline(deadline)
+	buf := make([]byte, 1500)
+
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return "", false
+		}
+
+		reply, err := icmp.ParseMessage(1, buf[:n])
+		if err != nil {
+			continue
+		}
+
+		switch body := reply.Body.(type) {
+		case *icmp.Echo:
+			if reply.Type == ipv4.ICMPTypeEchoReply && body.Seq == seq {
+				return hostOnly(peer), true
+			}
+		case *icmp.TimeExceeded:
+			if quotedEchoSeq(body.Data) == seq {
+				return hostOnly(peer), false
+			}
+		}
+	}
+}
+
+// quotedEchoSeq extracts the echo sequence number from the original datagram
+// quoted inside an ICMP error message
+func quotedEchoSeq(data []byte) int {
+	if len(data) < 20 {
+		return -1
+	}
+	headerLen := int(data[0]&0x0f) * 4
+	if len(data) < headerLen+8 {
+		return -1
+	}
+	return int(data[headerLen+6])<<8 | int(data[headerLen+7])
+}
+
+func hostOnly(addr net.Addr) string {
+	switch a := addr.(type) {
+	case *net.IPAddr:
+		return a.IP.String()
+	case *net.UDPAddr:
+		return a.IP.String()
+	default:
+		return addr.String()
+	}
+}
@@ -0,0 +1,196 @@
+package netenv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Change events reported by Watch
+const (
+	EventInterfaceUp    = "interface_up"
+	EventInterfaceDown  = "interface_down"
+	EventVPNUp          = "vpn_up"
+	EventVPNDown        = "vpn_down"
+	EventAddressAdded   = "address_added"
+	EventAddressRemoved = "address_removed"
+	EventGatewayChanged = "gateway_changed"
+	EventDefaultRoute   = "default_route_changed"
+	EventWiFiChanged    = "wifi_changed"
+	EventDNSChanged     = "dns_changed"
+)
+
+// ChangeEvent is one change of the network environment
+type ChangeEvent struct {
+	Time      time.Time `json:"time"`
+	Event     string    `json:"event"`
+	Interface string    `json:"interface,omitempty"`
+	Old       string    `json:"old,omitempty"`
+	New       string    `json:"new,omitempty"`
+}
+
+// NetworkState is what Watch compares between two polls
+type NetworkState struct {
+	Interfaces   map[string]NetworkInterface
+	WiFi         map[string]string // interface -> "ssid (bssid)"
+	DefaultRoute string            // "gateway via interface"
+	DNSServers   []string
+}
+
+// CurrentState reads the interfaces, Wi-Fi links, default route and DNS
+// servers
+func CurrentState() (*NetworkState, error) {
+	interfaces, err := GetActiveInterfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &NetworkState{
+		Interfaces: make(map[string]NetworkInterface),
+		WiFi:       make(map[string]string),
+		DNSServers: detectDNSServers(),
+	}
+	for _, iface := range interfaces {
+		state.Interfaces[iface.Name] = iface
+		if iface.Type == "loopback" || iface.Overlay != "" {
+			continue
+		}
+		if wifi := DetectWiFi(iface.Name); wifi != nil {
+			link := wifi.SSID
+			if wifi.BSSID != "" {
+				link += " (" + wifi.BSSID + ")"
+			}
+			state.WiFi[iface.Name] = link
+		}
+	}
+	if routes, err := GetRoutes(); err == nil {
+		if defaults := DefaultGateways(routes); len(defaults) > 0 {
+			state.DefaultRoute = fmt.Sprintf("%s via %s", defaults[0].Gateway, defaults[0].Interface)
+		}
+	}
+	return state, nil
+}
+
+// DiffStates lists the changes from one state to the next
+func DiffStates(previous, current *NetworkState) []ChangeEvent {
+	now := time.Now()
+	var events []ChangeEvent
+	add := func(event, iface, from, to string) {
+		events = append(events, ChangeEvent{Time: now, Event: event, Interface: iface, Old: from, New: to})
+	}
+
+	names := make(map[string]bool)
+	for name := range previous.Interfaces {
+		names[name] = true
+	}
+	for name := range current.Interfaces {
+		names[name] = true
+	}
+	for _, name := range sortedKeys(names) {
+		before, wasUp := previous.Interfaces[name]
+		after, isUp := current.Interfaces[name]
+		switch {
+		case !wasUp:
+			if after.Type == "vpn" {
+				add(EventVPNUp, name, "", addressList(after))
+			} else {
+				add(EventInterfaceUp, name, "", addressList(after))
+			}
+			continue
+		case !isUp:
+			if before.Type == "vpn" {
+				add(EventVPNDown, name, addressList(before), "")
+			} else {
+				add(EventInterfaceDown, name, addressList(before), "")
+			}
+			continue
+		}
+
+		// A DHCP renewal that changes the lease shows up as a removed and
+		// an added address
+		beforeSet, afterSet := addressSet(before), addressSet(after)
+		for _, address := range sortedKeys(beforeSet) {
+			if !afterSet[address] {
+				add(EventAddressRemoved, name, address, "")
+			}
+		}
+		for _, address := range sortedKeys(afterSet) {
+			if !beforeSet[address] {
+				add(EventAddressAdded, name, "", address)
+			}
+		}
+		if g1, g2 := gatewayOf(before), gatewayOf(after); g1 != g2 {
+			add(EventGatewayChanged, name, g1, g2)
+		}
+	}
+
+	for _, name := range sortedKeys(names) {
+		if previous.WiFi[name] != current.WiFi[name] {
+			add(EventWiFiChanged, name, previous.WiFi[name], current.WiFi[name])
+		}
+	}
+	if previous.DefaultRoute != current.DefaultRoute {
+		add(EventDefaultRoute, "", previous.DefaultRoute, current.DefaultRoute)
+	}
+	if from, to := strings.Join(previous.DNSServers, ", "), strings.Join(current.DNSServers, ", "); from != to {
+		add(EventDNSChanged, "", from, to)
+	}
+	return events
+}
+
+// Watch polls the network environment every interval and calls emit with
+// each change until stop is closed
+func Watch(interval time.Duration, stop <-chan struct{}, emit func(ChangeEvent)) error {
+	previous, err := CurrentState()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+		current, err := CurrentState()
+		if err != nil {
+			// Interfaces can vanish mid-read while a link flaps; try again
+			continue
+		}
+		for _, event := range DiffStates(previous, current) {
+			emit(event)
+		}
+		previous = current
+	}
+}
+
+func addressSet(iface NetworkInterface) map[string]bool {
+	set := make(map[string]bool)
+	for _, address := range iface.Addresses {
+		set[address.Network] = true
+	}
+	return set
+}
+
+func addressList(iface NetworkInterface) string {
+	return strings.Join(sortedKeys(addressSet(iface)), ", ")
+}
+
+func gatewayOf(iface NetworkInterface) string {
+	if iface.Gateway == nil {
+		return ""
+	}
+	return iface.Gateway.IP
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
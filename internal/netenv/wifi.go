@@ -0,0 +1,254 @@
+package netenv
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// airportPath is the macOS tool that reports the current Wi-Fi link
+const airportPath = "/System/Library/PrivateFrameworks/Apple80211.framework/Versions/Current/Resources/airport"
+
+// WiFiInfo describes the wireless network an interface is joined to
+type WiFiInfo struct {
+	SSID      string `json:"ssid"`
+	BSSID     string `json:"bssid,omitempty"`
+	Channel   int    `json:"channel,omitempty"`
+	Frequency int    `json:"frequency_mhz,omitempty"`
+	RSSI      int    `json:"rssi_dbm,omitempty"` // signal strength, e.g. -55
+	Security  string `json:"security,omitempty"` // e.g. WPA2-PSK, open
+}
+
+// DetectWiFi returns the Wi-Fi link of an interface, or nil when it is not
+// wireless, not associated, or the platform tools (iw, airport, netsh) are
+// unavailable
+func DetectWiFi(ifaceName string) *WiFiInfo {
+	var info *WiFiInfo
+	switch runtime.GOOS {
+	case "linux":
+		info = detectWiFiLinux(ifaceName)
+	case "darwin":
+		info = detectWiFiDarwin(ifaceName)
+	case "windows":
+		info = detectWiFiWindows(ifaceName)
+	}
+	if info == nil || info.SSID == "" {
+		return nil
+	}
+	if info.Channel == 0 && info.Frequency > 0 {
+		info.Channel = channelOf(info.Frequency)
+	}
+	return info
+}
+
+func detectWiFiLinux(ifaceName string) *WiFiInfo {
+	if _, err := os.Stat(filepath.Join("/sys/class/net", ifaceName, "wireless")); err != nil {
+		return nil
+	}
+	output, err := exec.Command("iw", "dev", ifaceName, "link").Output()
+	if err != nil {
+		// Without iw, at least the SSID
+		if ssid := DetectSSID(ifaceName); ssid != "" {
+			return &WiFiInfo{SSID: ssid}
+		}
+		return nil
+	}
+	info := parseIWLink(string(output))
+	if info != nil {
+		if status, err := exec.Command("wpa_cli", "-i", ifaceName, "status").Output(); err == nil {
+			info.Security = parseWPAStatus(string(status))
+		}
+	}
+	return info
+}
+
+// parseIWLink reads the output of "iw dev <iface> link":
+//
+//	Connected to aa:bb:cc:dd:ee:ff (on wlan0)
+//		SSID: example
+//		freq: 5180
+//		signal: -52 dBm
+func parseIWLink(output string) *WiFiInfo {
+	if !strings.HasPrefix(strings.TrimSpace(output), "Connected to ") {
+		return nil
+	}
+	info := &WiFiInfo{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "Connected to "); ok {
+			info.BSSID, _, _ = strings.Cut(rest, " ")
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "SSID":
+			info.SSID = value
+		case "freq":
+			mhz, _ := strconv.ParseFloat(value, 64)
+			info.Frequency = int(mhz)
+		case "signal":
+			if fields := strings.Fields(value); len(fields) > 0 {
+				info.RSSI, _ = strconv.Atoi(fields[0])
+			}
+		}
+	}
+	return info
+}
+
+// parseWPAStatus returns the key management of "wpa_cli status", such as
+// WPA2-PSK, or "open" when none is used
+func parseWPAStatus(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "key_mgmt="); ok {
+			if value == "NONE" {
+				return "open"
+			}
+			return value
+		}
+	}
+	return ""
+}
+
+func detectWiFiDarwin(ifaceName string) *WiFiInfo {
+	// airport reports the Wi-Fi interface only, so check that it is this one
+	ports, err := exec.Command("networksetup", "-listallhardwareports").Output()
+	if err != nil || wifiDevice(string(ports)) != ifaceName {
+		return nil
+	}
+	output, err := exec.Command(airportPath, "-I").Output()
+	if err != nil {
+		// airport was removed in macOS 14.4
+		if ssid := DetectSSID(ifaceName); ssid != "" {
+			return &WiFiInfo{SSID: ssid}
+		}
+		return nil
+	}
+	return parseAirport(string(output))
+}
+
+// wifiDevice returns the device of the Wi-Fi hardware port in the output
+// of "networksetup -listallhardwareports"
+func wifiDevice(output string) string {
+	wifi := false
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Hardware Port":
+			wifi = value == "Wi-Fi" || value == "AirPort"
+		case "Device":
+			if wifi {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// parseAirport reads the output of "airport -I"
+func parseAirport(output string) *WiFiInfo {
+	info := &WiFiInfo{}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "SSID":
+			info.SSID = value
+		case "BSSID":
+			info.BSSID = value
+		case "agrCtlRSSI":
+			info.RSSI, _ = strconv.Atoi(value)
+		case "channel":
+			// "36,80": primary channel and width
+			channel, _, _ := strings.Cut(value, ",")
+			info.Channel, _ = strconv.Atoi(channel)
+		case "link auth":
+			info.Security = strings.ToUpper(value)
+			if value == "none" {
+				info.Security = "open"
+			}
+		}
+	}
+	return info
+}
+
+func detectWiFiWindows(ifaceName string) *WiFiInfo {
+	output, err := exec.Command("netsh", "wlan", "show", "interfaces").Output()
+	if err != nil {
+		return nil
+	}
+	return parseNetsh(string(output), ifaceName)
+}
+
+// parseNetsh reads the block of one interface in the output of
+// "netsh wlan show interfaces"
+func parseNetsh(output, ifaceName string) *WiFiInfo {
+	var info *WiFiInfo
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "Name" {
+			if info != nil {
+				break
+			}
+			if value == ifaceName {
+				info = &WiFiInfo{}
+			}
+			continue
+		}
+		if info == nil {
+			continue
+		}
+		switch key {
+		case "State":
+			if value != "connected" {
+				return nil
+			}
+		case "SSID":
+			info.SSID = value
+		case "BSSID", "AP BSSID":
+			info.BSSID = value
+		case "Channel":
+			info.Channel, _ = strconv.Atoi(value)
+		case "Signal":
+			// A quality percentage; 100% is about -50 dBm and 0% -100 dBm
+			if percent, err := strconv.Atoi(strings.TrimSuffix(value, "%")); err == nil {
+				info.RSSI = percent/2 - 100
+			}
+		case "Authentication":
+			info.Security = value
+		}
+	}
+	return info
+}
+
+// channelOf converts a frequency in MHz to its Wi-Fi channel
+func channelOf(mhz int) int {
+	switch {
+	case mhz == 2484:
+		return 14
+	case mhz >= 2412 && mhz < 2484:
+		return (mhz - 2407) / 5
+	case mhz >= 5955 && mhz <= 7115:
+		return (mhz - 5950) / 5
+	case mhz >= 5000 && mhz < 5955:
+		return (mhz - 5000) / 5
+	}
+	return 0
+}
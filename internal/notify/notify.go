@@ -0,0 +1,99 @@
+// Package notify delivers run completion alerts to webhooks, Slack and Discord
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/config"
+)
+
+// discordContentLimit is the maximum message length Discord accepts
+const discordContentLimit = 2000
+
+// Message is a notification about a completed run
+type Message struct {
+	Event   string      `json:"event"`
+	Title   string      `json:"title"`
+	Text    string      `json:"text"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// Notifier sends messages to the configured destinations
+type Notifier struct {
+	config config.NotificationConfig
+	client *http.Client
+}
+
+// New creates a notifier for the given configuration
+func New(cfg config.NotificationConfig) *Notifier {
+	return &Notifier{
+		config: cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Enabled reports whether any destination is configured
+func (n *Notifier) Enabled() bool {
+	return n.config.WebhookURL != "" || n.config.SlackURL != "" || n.config.DiscordURL != ""
+}
+
+// Send delivers the message to every configured destination.
+// All destinations are attempted; failures are combined into one error.
+func (n *Notifier) Send(msg Message) error {
+	var failures []string
+
+	if n.config.WebhookURL != "" {
+		if err := n.post(n.config.WebhookURL, msg); err != nil {
+			failures = append(failures, fmt.Sprintf("webhook: %v", err))
+		}
+	}
+
+	text := msg.Title
+	if msg.Text != "" {
+		text += "\n" + msg.Text
+	}
+
+	if n.config.SlackURL != "" {
+		if err := n.post(n.config.SlackURL, map[string]string{"text": text}); err != nil {
+			failures = append(failures, fmt.Sprintf("slack: %v", err))
+		}
+	}
+
+	if n.config.DiscordURL != "" {
+		if len(text) > discordContentLimit {
+			text = text[:discordContentLimit-3] + "..."
+		}
+		if err := n.post(n.config.DiscordURL, map[string]string{"content": text}); err != nil {
+			failures = append(failures, fmt.Sprintf("discord: %v", err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("notification failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// post sends body as JSON and treats any non-2xx status as an error
+func (n *Notifier) post(url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	resp, err := n.client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -5,15 +5,24 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
 	"os/exec"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/netcrate/netcrate/internal/abort"
 	"github.com/netcrate/netcrate/internal/netenv"
 	"github.com/netcrate/netcrate/internal/privileges"
+	"github.com/netcrate/netcrate/internal/progress"
+	"github.com/netcrate/netcrate/internal/runid"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 )
 
 // DiscoverOptions contains configuration for host discovery
@@ -26,6 +35,13 @@ type DiscoverOptions struct {
 	Concurrency int       `json:"concurrency"`
 	TCPPorts    []int     `json:"tcp_ports"`
 	ResolveHostnames bool `json:"resolve_hostnames"`
+	Exclude     []string  `json:"exclude,omitempty"` // IPs or CIDRs never probed
+	Scope       []string  `json:"scope,omitempty"`   // IPs or CIDRs targets must lie in; empty allows all
+
+	// OnResult, if set, is called with each result as it is collected
+	OnResult func(DiscoverResult) `json:"-"`
+	// Classify, if set, labels each result with its compliance class
+	Classify func(host string) *ComplianceLabel `json:"-"`
 }
 
 // DiscoverResult represents the result of host discovery
@@ -37,6 +53,7 @@ type DiscoverResult struct {
 	Details   map[string]interface{} `json:"details"`
 	Timestamp time.Time         `json:"timestamp"`
 	Hostname  string            `json:"hostname,omitempty"`
+	Compliance *ComplianceLabel `json:"compliance,omitempty"`
 }
 
 // DiscoverSummary provides summary statistics
@@ -55,6 +72,7 @@ type DiscoverSummary struct {
 	Stats            DiscoverStats     `json:"stats"`
 	PrivilegeMode    string            `json:"privilege_mode"`
 	FallbackReasons  []string          `json:"fallback_reasons,omitempty"`
+	Fallbacks        []string          `json:"fallbacks,omitempty"` // methods this run could not use, and why
 	PrivilegeSummary map[string]interface{} `json:"privilege_summary,omitempty"`
 }
 
@@ -76,7 +94,7 @@ type MethodStats struct {
 // Discover performs host discovery on the specified targets
 func Discover(opts DiscoverOptions) (*DiscoverSummary, error) {
 	startTime := time.Now()
-	runID := fmt.Sprintf("discover_%d", startTime.Unix())
+	runID := runid.NewAt("discover", startTime)
 
 	// Initialize privilege manager for capability detection
 	pm := privileges.NewPrivilegeManager()
@@ -91,6 +109,20 @@ func Discover(opts DiscoverOptions) (*DiscoverSummary, error) {
 		return nil, fmt.Errorf("no valid targets specified")
 	}
 
+	if err := CheckScope(targets, opts.Scope); err != nil {
+		return nil, err
+	}
+
+	if len(opts.Exclude) > 0 {
+		targets, err = excludeTargets(targets, opts.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		if len(targets) == 0 {
+			return nil, fmt.Errorf("all targets are excluded")
+		}
+	}
+
 	// Set defaults
 	if opts.Rate == 0 {
 		opts.Rate = 100
@@ -101,14 +133,23 @@ func Discover(opts DiscoverOptions) (*DiscoverSummary, error) {
 	if opts.Concurrency == 0 {
 		opts.Concurrency = 200
 	}
+	var fallbacks []string
 	if len(opts.Methods) == 0 {
 		// Use optimal methods based on privileges
 		if pm.HasCapability(privileges.CapabilityICMP) {
 			opts.Methods = []string{"icmp", "tcp"}
 		} else if pm.HasCapability(privileges.CapabilitySystemPing) {
 			opts.Methods = []string{"ping", "tcp"}
+			fallbacks = append(fallbacks, capabilityFallback("native ICMP unavailable, using the system ping command", netenv.CapRawIPv4))
 		} else {
 			opts.Methods = []string{"tcp"}
+			fallbacks = append(fallbacks, capabilityFallback("ICMP unavailable, using TCP connect only", netenv.CapRawIPv4))
+		}
+	} else if !pm.HasCapability(privileges.CapabilityICMP) {
+		for _, method := range opts.Methods {
+			if method == "icmp" {
+				fallbacks = append(fallbacks, capabilityFallback("native ICMP unavailable, using the system ping command", netenv.CapRawIPv4))
+			}
 		}
 	}
 	if len(opts.TCPPorts) == 0 {
@@ -116,7 +157,7 @@ func Discover(opts DiscoverOptions) (*DiscoverSummary, error) {
 	}
 
 	// Create context for cancellation
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(abort.Context())
 	defer cancel()
 
 	// Rate limiter
@@ -133,6 +174,9 @@ func Discover(opts DiscoverOptions) (*DiscoverSummary, error) {
 	var stats DiscoverStats
 	stats.MethodBreakdown = make(map[string]MethodStats)
 
+	phase := progress.Start("discover", len(targets))
+	defer phase.End()
+
 	// Start discovery workers
 	for _, target := range targets {
 		wg.Add(1)
@@ -174,7 +218,14 @@ func Discover(opts DiscoverOptions) (*DiscoverSummary, error) {
 	// Collect results
 	var allResults []DiscoverResult
 	for result := range results {
+		if opts.Classify != nil {
+			result.Compliance = opts.Classify(result.Host)
+		}
 		allResults = append(allResults, result)
+		if opts.OnResult != nil {
+			opts.OnResult(result)
+		}
+		phase.Add(1)
 		
 		// Update stats
 		stats.Sent++
@@ -219,12 +270,22 @@ func Discover(opts DiscoverOptions) (*DiscoverSummary, error) {
 		Stats:            stats,
 		PrivilegeMode:    pm.GetLevel().String(),
 		FallbackReasons:  pm.GetFallbackReasons(),
+		Fallbacks:        fallbacks,
 		PrivilegeSummary: pm.GetPrivilegeSummary(),
 	}
 
 	return summary, nil
 }
 
+// capabilityFallback explains a method fallback with the capability behind
+// it, as the capability matrix found it
+func capabilityFallback(fallback, capability string) string {
+	if c := netenv.LookupCapability(netenv.DetectCapabilityMatrix(), capability); c != nil && !c.Available {
+		return fallback + ": " + c.Reason()
+	}
+	return fallback
+}
+
 func parseTargets(targets []string) ([]string, error) {
 	var result []string
 
@@ -232,25 +293,16 @@ func parseTargets(targets []string) ([]string, error) {
 		switch {
 		case target == "auto":
 			// Auto-detect current network
-			interfaces, err := netenv.GetActiveInterfaces()
+			networks, err := AutoTargets()
 			if err != nil {
-				return nil, fmt.Errorf("failed to auto-detect network: %w", err)
+				return nil, err
 			}
-			
-			for _, iface := range interfaces {
-				if iface.Type != "loopback" && len(iface.Addresses) > 0 {
-					for _, addr := range iface.Addresses {
-						if strings.Contains(addr.Network, "/") {
-							expanded, err := expandCIDR(addr.Network)
-							if err != nil {
-								continue
-							}
-							result = append(result, expanded...)
-							break // Only use first address per interface
-						}
-					}
-					break // Only use first suitable interface
+			for _, network := range networks {
+				expanded, err := expandCIDR(network)
+				if err != nil {
+					continue
 				}
+				result = append(result, expanded...)
 			}
 
 		case strings.Contains(target, "/"):
@@ -286,6 +338,84 @@ func parseTargets(targets []string) ([]string, error) {
 	return result, nil
 }
 
+// AutoTargets returns the networks the target "auto" stands for: that of
+// the first address of the first active interface that is not a loopback
+func AutoTargets() ([]string, error) {
+	interfaces, err := netenv.GetActiveInterfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to auto-detect network: %w", err)
+	}
+
+	for _, iface := range interfaces {
+		if iface.Type != "loopback" && len(iface.Addresses) > 0 {
+			for _, addr := range iface.Addresses {
+				if strings.Contains(addr.Network, "/") {
+					_, network, err := net.ParseCIDR(addr.Network)
+					if err != nil {
+						continue
+					}
+					return []string{network.String()}, nil // Only use first address per interface
+				}
+			}
+			break // Only use first suitable interface
+		}
+	}
+	return nil, nil
+}
+
+// ResolveTargets expands targets to addresses as Discover does, without the
+// excluded ones, to show what a run would probe
+func ResolveTargets(targets, exclude []string) ([]string, error) {
+	resolved, err := parseTargets(targets)
+	if err != nil {
+		return nil, err
+	}
+	if len(exclude) == 0 {
+		return resolved, nil
+	}
+	return excludeTargets(resolved, exclude)
+}
+
+// excludeTargets removes targets matching any excluded IP or CIDR
+func excludeTargets(targets []string, exclude []string) ([]string, error) {
+	var networks []*net.IPNet
+	for _, entry := range exclude {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() == nil {
+				entry += "/128"
+			} else {
+				entry += "/32"
+			}
+		}
+		_, ipnet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclusion %s: %w", entry, err)
+		}
+		networks = append(networks, ipnet)
+	}
+
+	var result []string
+	for _, target := range targets {
+		ip := net.ParseIP(target)
+		excluded := false
+		for _, ipnet := range networks {
+			if ip != nil && ipnet.Contains(ip) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			result = append(result, target)
+		}
+	}
+
+	return result, nil
+}
+
 func expandCIDR(cidr string) ([]string, error) {
 	_, ipnet, err := net.ParseCIDR(cidr)
 	if err != nil {
@@ -401,11 +531,67 @@ func discoverSingleTarget(ctx context.Context, target string, opts DiscoverOptio
 	return result
 }
 
+// icmpSequence numbers the echo requests of this process
+var icmpSequence uint32
+
 func tryICMP(ctx context.Context, target string, timeout time.Duration) (bool, time.Duration, map[string]interface{}) {
-	// Try native ICMP socket first
-	// This would require raw socket implementation
-	// For now, fall back to system ping
-	return trySystemPing(ctx, target, timeout)
+	// Try native ICMP socket first, opened directly or by the privileged
+	// helper; fall back to system ping without one
+	addr, err := net.ResolveIPAddr("ip", target)
+	if err != nil {
+		return false, 0, map[string]interface{}{"error": err.Error()}
+	}
+	network, proto, address := "ip4:icmp", 1, "0.0.0.0"
+	var echoType icmp.Type = ipv4.ICMPTypeEcho
+	if addr.IP.To4() == nil {
+		network, proto, address = "ip6:ipv6-icmp", 58, "::"
+		echoType = ipv6.ICMPTypeEchoRequest
+	}
+	conn, err := privileges.ListenRawPacket(network, address)
+	if err != nil {
+		return trySystemPing(ctx, target, timeout)
+	}
+	defer conn.Close()
+
+	id := os.Getpid() & 0xffff
+	seq := int(atomic.AddUint32(&icmpSequence, 1) & 0xffff)
+	request, err := (&icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("netcrate")},
+	}).Marshal(nil)
+	if err != nil {
+		return false, 0, map[string]interface{}{"error": err.Error()}
+	}
+
+	start := time.Now()
+	if _, err := conn.WriteTo(request, addr); err != nil {
+		return false, 0, map[string]interface{}{"error": err.Error(), "method": "icmp"}
+	}
+	deadline := start.Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetReadDeadline(deadline)
+
+	buffer := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buffer)
+		if err != nil {
+			return false, time.Since(start), map[string]interface{}{"error": "timeout", "method": "icmp"}
+		}
+		// Every raw ICMP socket sees every reply; keep only ours
+		fromIP, ok := from.(*net.IPAddr)
+		if !ok || !fromIP.IP.Equal(addr.IP) {
+			continue
+		}
+		reply, err := icmp.ParseMessage(proto, buffer[:n])
+		if err != nil || (reply.Type != ipv4.ICMPTypeEchoReply && reply.Type != ipv6.ICMPTypeEchoReply) {
+			continue
+		}
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.ID == id && echo.Seq == seq {
+			return true, time.Since(start), map[string]interface{}{"method": "icmp"}
+		}
+	}
 }
 
 func trySystemPing(ctx context.Context, target string, timeout time.Duration) (bool, time.Duration, map[string]interface{}) {
@@ -413,6 +599,9 @@ func trySystemPing(ctx context.Context, target string, timeout time.Duration) (b
 	
 	// Use system ping command
 	cmd := exec.CommandContext(ctx, "ping", "-c", "1", "-W", fmt.Sprintf("%d", int(timeout/time.Millisecond)), target)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "ping", "-n", "1", "-w", fmt.Sprintf("%d", int(timeout/time.Millisecond)), target)
+	}
 	output, err := cmd.Output()
 	
 	rtt := time.Since(start)
@@ -420,6 +609,11 @@ func trySystemPing(ctx context.Context, target string, timeout time.Duration) (b
 	if err != nil {
 		return false, rtt, map[string]interface{}{"error": err.Error(), "fallback_used": "system_ping"}
 	}
+	// Windows ping exits 0 when a router answers "destination host
+	// unreachable"; only an echo reply carries a TTL
+	if runtime.GOOS == "windows" && !strings.Contains(string(output), "TTL=") {
+		return false, rtt, map[string]interface{}{"error": "no echo reply", "fallback_used": "system_ping"}
+	}
 
 	// Parse RTT from ping output
 	if realRTT := parseRTTFromPing(string(output)); realRTT > 0 {
@@ -515,8 +709,8 @@ func getBroadcastAddress(ipnet *net.IPNet) net.IP {
 }
 
 func parseRTTFromPing(output string) time.Duration {
-	// Parse RTT from ping output like "time=1.234 ms"
-	re := regexp.MustCompile(`time=([0-9.]+)\s*ms`)
+	// Parse RTT from ping output like "time=1.234 ms", or "time<1ms" on Windows
+	re := regexp.MustCompile(`time[=<]([0-9.]+)\s*ms`)
 	matches := re.FindStringSubmatch(output)
 	if len(matches) >= 2 {
 		if rtt, err := strconv.ParseFloat(matches[1], 64); err == nil {
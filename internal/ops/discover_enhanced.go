@@ -6,14 +6,16 @@ import (
 	"math/rand"
 	"net"
 	"os/exec"
-	"regexp"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/netcrate/netcrate/internal/logging"
 	"github.com/netcrate/netcrate/internal/netenv"
 )
 
+var log = logging.Component("discover")
+
 // TargetPriority represents priority levels for target ordering
 type TargetPriority int
 
@@ -111,30 +113,27 @@ func prioritizeTargets(targets []string, interfaceName string) ([]PrioritizedTar
 	var prioritized []PrioritizedTarget
 	
 	// Get ARP cache entries
-	arpEntries, err := getARPCache()
+	arpEntries, err := getARPCache(interfaceName)
 	if err != nil {
-		fmt.Printf("[DEBUG] Failed to get ARP cache: %v\n", err)
+		log.Debug("failed to get ARP cache", "error", err)
 		arpEntries = make(map[string]string) // Continue without ARP cache
 	}
 	
 	// Get gateway information
 	gateway, err := getDefaultGateway(interfaceName)
 	if err != nil {
-		fmt.Printf("[DEBUG] Failed to get gateway: %v\n", err)
+		log.Debug("failed to get gateway", "error", err)
 		gateway = ""
 	}
 	
 	// Get local network info for adjacency calculation
 	localNetworks, err := getLocalNetworks(interfaceName)
 	if err != nil {
-		fmt.Printf("[DEBUG] Failed to get local networks: %v\n", err)
+		log.Debug("failed to get local networks", "error", err)
 		localNetworks = []string{}
 	}
 	
-	fmt.Printf("[DEBUG] Target prioritization:\n")
-	fmt.Printf("  ARP entries: %d\n", len(arpEntries))
-	fmt.Printf("  Gateway: %s\n", gateway)
-	fmt.Printf("  Local networks: %v\n", localNetworks)
+	log.Debug("target prioritization", "arp_entries", len(arpEntries), "gateway", gateway, "local_networks", localNetworks)
 	
 	// Categorize targets
 	for _, target := range targets {
@@ -180,62 +179,55 @@ func prioritizeTargets(targets []string, interfaceName string) ([]PrioritizedTar
 	for _, pt := range prioritized {
 		stats[pt.Priority]++
 	}
-	fmt.Printf("[DEBUG] Prioritization stats: High=%d, Medium=%d, Low=%d\n", 
-		stats[PriorityHigh], stats[PriorityMedium], stats[PriorityLow])
+	log.Debug("prioritization stats", "high", stats[PriorityHigh], "medium", stats[PriorityMedium], "low", stats[PriorityLow])
 	
 	return prioritized, nil
 }
 
-// getARPCache retrieves system ARP cache entries
-func getARPCache() (map[string]string, error) {
-	cmd := exec.Command("arp", "-a")
-	output, err := cmd.Output()
+// getARPCache maps the addresses in the system neighbor cache to their MAC,
+// keeping only the given interface's entries when one is named
+func getARPCache(interfaceName string) (map[string]string, error) {
+	entries, err := netenv.GetARPTable()
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute arp command: %w", err)
+		return nil, err
 	}
-	
-	entries := make(map[string]string)
-	
-	// Parse ARP output (format varies by OS)
-	// macOS: hostname (192.168.1.1) at aa:bb:cc:dd:ee:ff on en0 ifscope [ethernet]
-	// Linux: 192.168.1.1 ether aa:bb:cc:dd:ee:ff C en0
-	lines := strings.Split(string(output), "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		
-		// Extract IP address - look for pattern like (192.168.1.1) or standalone IP
-		var ip string
-		
-		// Try macOS format first: hostname (IP) at MAC
-		if strings.Contains(line, "(") && strings.Contains(line, ")") {
-			re := regexp.MustCompile(`\(([0-9.]+)\)`)
-			matches := re.FindStringSubmatch(line)
-			if len(matches) >= 2 {
-				ip = matches[1]
-			}
-		} else {
-			// Try Linux format: IP ether MAC
-			parts := strings.Fields(line)
-			if len(parts) >= 1 && net.ParseIP(parts[0]) != nil {
-				ip = parts[0]
+	if interfaceName != "" {
+		var onInterface []netenv.ARPEntry
+		for _, entry := range entries {
+			if entry.Interface == interfaceName {
+				onInterface = append(onInterface, entry)
 			}
 		}
-		
-		if ip != "" && net.ParseIP(ip) != nil {
-			entries[ip] = line // Store full line for debugging
-		}
+		entries = onInterface
 	}
-	
-	return entries, nil
+	return netenv.ARPTableMACs(entries), nil
+}
+
+// LookupMACAddresses returns a map of IP address to MAC address from the
+// system neighbor cache, in upper-case colon-separated form
+func LookupMACAddresses() map[string]string {
+	macs, err := getARPCache("")
+	if err != nil {
+		return make(map[string]string)
+	}
+	for ip, mac := range macs {
+		macs[ip] = strings.ToUpper(mac)
+	}
+	return macs
 }
 
 // getDefaultGateway gets the default gateway IP
 func getDefaultGateway(interfaceName string) (string, error) {
-	// Try netstat first (cross-platform)
+	// The routing table knows the gateway of each interface
+	if routes, err := netenv.GetRoutes(); err == nil {
+		for _, route := range netenv.DefaultGateways(routes) {
+			if route.Gateway != "" && (interfaceName == "" || interfaceName == "auto" || route.Interface == interfaceName) {
+				return route.Gateway, nil
+			}
+		}
+	}
+
+	// Try netstat (cross-platform)
 	cmd := exec.Command("netstat", "-rn")
 	output, err := cmd.Output()
 	if err == nil {
@@ -468,7 +460,7 @@ func runSampling(sampleTargets []PrioritizedTarget, opts DiscoverOptions, method
 		}, nil
 	}
 	
-	fmt.Printf("[INFO] Running sampling with %d targets\n", len(sampleTargets))
+	log.Debug("running sampling", "targets", len(sampleTargets))
 	
 	// Convert sample targets to string slice
 	targetStrings := make([]string, len(sampleTargets))
@@ -478,6 +470,7 @@ func runSampling(sampleTargets []PrioritizedTarget, opts DiscoverOptions, method
 	
 	// Create sampling options with faster rate
 	samplingOpts := opts
+	samplingOpts.OnResult = nil // sample probes are not part of the results
 	samplingOpts.Targets = convertIPsToRanges(targetStrings)
 	samplingOpts.Methods = methods // Use provided methods
 	if samplingOpts.Rate < 50 {
@@ -581,14 +574,14 @@ func detectMethodAvailability(testTargets []string, originalMethods []string) ([
 		return originalMethods, false
 	}
 	
-	fmt.Printf("[INFO] Testing method availability with %d test targets\n", len(testTargets))
+	log.Debug("testing method availability", "test_targets", len(testTargets))
 	
 	var availableMethods []string
 	fallbackUsed := false
 	
 	// Test each method with a small sample
 	for _, method := range originalMethods {
-		fmt.Printf("[DEBUG] Testing method: %s\n", method)
+		log.Debug("testing method", "method", method)
 		
 		// Use first few targets for testing
 		testCount := len(testTargets)
@@ -605,14 +598,14 @@ func detectMethodAvailability(testTargets []string, originalMethods []string) ([
 		
 		result, err := Discover(testOpts)
 		if err != nil {
-			fmt.Printf("[WARN] Method %s failed: %v, skipping\n", method, err)
+			log.Warn("method failed, skipping", "method", method, "error", err)
 			fallbackUsed = true
 			continue
 		}
 		
 		// Check if method is working effectively
 		if result == nil {
-			fmt.Printf("[WARN] Method %s returned no results, skipping\n", method)
+			log.Warn("method returned no results, skipping", "method", method)
 			fallbackUsed = true
 			continue
 		}
@@ -629,30 +622,30 @@ func detectMethodAvailability(testTargets []string, originalMethods []string) ([
 			// ICMP should work if we get any responses or if we don't get permission errors
 			if result.HostsDiscovered > 0 || !containsPermissionError(err) {
 				availableMethods = append(availableMethods, method)
-				fmt.Printf("[INFO] Method %s available (success rate: %.2f%%)\n", method, successRate*100)
+				log.Debug("method available", "method", method, "success_rate", successRate)
 			} else {
-				fmt.Printf("[WARN] ICMP method appears to have permission issues, falling back\n")
+				log.Warn("ICMP method appears to have permission issues, falling back")
 				fallbackUsed = true
 			}
 		} else if method == "tcp" {
 			// TCP should always work as a fallback
 			availableMethods = append(availableMethods, method)
-			fmt.Printf("[INFO] Method %s available (success rate: %.2f%%)\n", method, successRate*100)
+			log.Debug("method available", "method", method, "success_rate", successRate)
 		} else {
 			// For other methods, use basic availability check
 			availableMethods = append(availableMethods, method)
-			fmt.Printf("[INFO] Method %s available\n", method)
+			log.Debug("method available", "method", method)
 		}
 	}
 	
 	// Ensure we always have at least TCP as a fallback
 	if len(availableMethods) == 0 {
-		fmt.Printf("[WARN] No methods available, forcing TCP fallback\n")
+		log.Warn("no methods available, forcing TCP fallback")
 		availableMethods = []string{"tcp"}
 		fallbackUsed = true
 	}
 	
-	fmt.Printf("[INFO] Available methods: %v (fallback used: %t)\n", availableMethods, fallbackUsed)
+	log.Debug("available methods", "methods", availableMethods, "fallback_used", fallbackUsed)
 	return availableMethods, fallbackUsed
 }
 
@@ -727,8 +720,7 @@ func (arc *AdaptiveRateController) calculateOptimalRate(windowStats WindowStats)
 		arc.RateAdjustments = append(arc.RateAdjustments, adjustment)
 		arc.GoodWindowsCount = 0 // Reset good windows counter
 		
-		fmt.Printf("[INFO] Adaptive rate: %d→%d pps (loss=%.1f%%, timeout=%.1f%%)\n", 
-			arc.CurrentRate, newRate, lossRate*100, timeoutRate*100)
+		log.Info("adaptive rate lowered", "from_pps", arc.CurrentRate, "to_pps", newRate, "loss_rate", lossRate, "timeout_rate", timeoutRate)
 			
 		arc.CurrentRate = newRate
 		arc.LastAdjustment = time.Now()
@@ -760,8 +752,7 @@ func (arc *AdaptiveRateController) calculateOptimalRate(windowStats WindowStats)
 				}
 				arc.RateAdjustments = append(arc.RateAdjustments, adjustment)
 				
-				fmt.Printf("[INFO] Adaptive rate: %d→%d pps (recovery after %d good windows)\n", 
-					arc.CurrentRate, newRate, arc.GoodWindowsCount)
+				log.Info("adaptive rate recovered", "from_pps", arc.CurrentRate, "to_pps", newRate, "good_windows", arc.GoodWindowsCount)
 					
 				arc.CurrentRate = newRate
 				arc.LastAdjustment = time.Now()
@@ -791,7 +782,7 @@ func simulateAdaptiveRateDiscovery(opts DiscoverEnhancedOptions, targets []strin
 		windowCount = 10 // Limit simulation to 10 windows
 	}
 	
-	fmt.Printf("[INFO] Simulating adaptive rate control over %d windows\n", windowCount)
+	log.Debug("simulating adaptive rate control", "windows", windowCount)
 	
 	for window := 0; window < windowCount; window++ {
 		// Simulate network conditions
@@ -848,7 +839,7 @@ func deduplicateAndCalibrateResults(originalSummary *DiscoverSummary, enhanced *
 		return originalSummary
 	}
 	
-	fmt.Printf("[INFO] Deduplicating and calibrating results (%d original results)\n", len(originalSummary.Results))
+	log.Debug("deduplicating and calibrating results", "results", len(originalSummary.Results))
 	
 	// Group results by host for deduplication
 	hostResults := make(map[string][]DiscoverResult)
@@ -899,10 +890,8 @@ func deduplicateAndCalibrateResults(originalSummary *DiscoverSummary, enhanced *
 		calibratedSummary.SuccessRate = float64(aliveHosts) / float64(calibratedSummary.TargetsResolved)
 	}
 	
-	fmt.Printf("[INFO] Result calibration: %d hosts processed, %d duplicates removed\n", 
-		hostsProcessed, duplicatesRemoved)
-	fmt.Printf("[INFO] Final stats: %d alive hosts, %.1f%% success rate\n", 
-		aliveHosts, calibratedSummary.SuccessRate*100)
+	log.Debug("result calibration", "hosts", hostsProcessed, "duplicates_removed", duplicatesRemoved,
+		"alive", aliveHosts, "success_rate", calibratedSummary.SuccessRate)
 		
 	return &calibratedSummary
 }
@@ -994,12 +983,27 @@ func EnhancedDiscover(opts DiscoverEnhancedOptions) (*EnhancedDiscoverSummary, e
 		return nil, fmt.Errorf("failed to parse targets: %w", err)
 	}
 	
+	if err := CheckScope(targets, opts.Scope); err != nil {
+		return nil, err
+	}
+	
+	// Drop excluded targets before sampling or prioritization can probe them
+	if len(opts.Exclude) > 0 {
+		targets, err = excludeTargets(targets, opts.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		if len(targets) == 0 {
+			return nil, fmt.Errorf("all targets are excluded")
+		}
+	}
+	
 	var prioritizedTargets []PrioritizedTarget
 	
 	if opts.EnableTargetPruning {
 		prioritizedTargets, err = prioritizeTargets(targets, opts.Interface)
 		if err != nil {
-			fmt.Printf("[WARN] Target prioritization failed, using original order: %v\n", err)
+			log.Warn("target prioritization failed, using original order", "error", err)
 			// Convert to prioritized format with low priority
 			for _, target := range targets {
 				prioritizedTargets = append(prioritizedTargets, PrioritizedTarget{
@@ -1020,14 +1024,13 @@ func EnhancedDiscover(opts DiscoverEnhancedOptions) (*EnhancedDiscoverSummary, e
 		}
 	}
 	
-	fmt.Printf("[INFO] Enhanced Discover starting with %d targets\n", len(prioritizedTargets))
+	log.Debug("enhanced discover starting", "targets", len(prioritizedTargets))
 	if opts.EnableTargetPruning {
 		priorityStats := make(map[TargetPriority]int)
 		for _, pt := range prioritizedTargets {
 			priorityStats[pt.Priority]++
 		}
-		fmt.Printf("[INFO] Priority distribution: High=%d, Medium=%d, Low=%d\n",
-			priorityStats[PriorityHigh], priorityStats[PriorityMedium], priorityStats[PriorityLow])
+		log.Debug("priority distribution", "high", priorityStats[PriorityHigh], "medium", priorityStats[PriorityMedium], "low", priorityStats[PriorityLow])
 	}
 	
 	// B1-3: Method fallback - test method availability if enabled
@@ -1058,7 +1061,7 @@ func EnhancedDiscover(opts DiscoverEnhancedOptions) (*EnhancedDiscoverSummary, e
 						 (networkScale == ScaleLarge || networkScale == ScaleXLarge)
 	
 	if shouldUseSampling {
-		fmt.Printf("[INFO] Large network detected (%d targets), using sampling strategy\n", len(prioritizedTargets))
+		log.Info("large network, sampling first", "targets", len(prioritizedTargets))
 		
 		// Calculate sample size
 		sampleSize := calculateSampleSize(len(prioritizedTargets), opts.SamplingPercent)
@@ -1069,15 +1072,14 @@ func EnhancedDiscover(opts DiscoverEnhancedOptions) (*EnhancedDiscoverSummary, e
 		// Run sampling
 		samplingResult, err = runSampling(sampleTargets, opts.DiscoverOptions, actualMethods)
 		if err != nil {
-			fmt.Printf("[WARN] Sampling failed, proceeding with full scan: %v\n", err)
+			log.Warn("sampling failed, proceeding with full scan", "error", err)
 			shouldUseSampling = false
 		} else {
-			fmt.Printf("[INFO] Sampling results: density=%.2f%%, confidence=%.2f, action=%s\n",
-				samplingResult.DensityEstimate*100, samplingResult.Confidence, samplingResult.RecommendAction)
+			log.Info("sampling results", "density", samplingResult.DensityEstimate, "confidence", samplingResult.Confidence, "action", samplingResult.RecommendAction)
 			
 			// Handle sampling recommendations
 			if samplingResult.RecommendAction == "terminate_low_density" {
-				fmt.Printf("[INFO] Very low density network detected, terminating scan early\n")
+				log.Info("very low density network, terminating scan early")
 				
 				// Create minimal summary with sampling results
 				enhancedSummary := &EnhancedDiscoverSummary{
@@ -1117,7 +1119,7 @@ func EnhancedDiscover(opts DiscoverEnhancedOptions) (*EnhancedDiscoverSummary, e
 	var finalTargets []PrioritizedTarget
 	if shouldUseSampling && samplingResult != nil && samplingResult.RecommendAction == "sparse_scan_mode" {
 		// In sparse mode, focus on high priority targets and some medium priority ones
-		fmt.Printf("[INFO] Using sparse scan mode due to low density\n")
+		log.Info("using sparse scan mode due to low density")
 		for _, pt := range prioritizedTargets {
 			if pt.Priority == PriorityHigh || 
 			   (pt.Priority == PriorityMedium && rand.Float64() < 0.3) { // 30% of medium priority
@@ -1150,7 +1152,7 @@ func EnhancedDiscover(opts DiscoverEnhancedOptions) (*EnhancedDiscoverSummary, e
 	}
 	
 	// Call original discover
-	fmt.Printf("[INFO] Running main discovery on %d targets\n", len(finalTargets))
+	log.Debug("running main discovery", "targets", len(finalTargets))
 	originalSummary, err := Discover(enhancedOpts)
 	if err != nil {
 		return nil, err
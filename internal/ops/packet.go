@@ -11,6 +11,11 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/netcrate/netcrate/internal/abort"
+	"github.com/netcrate/netcrate/internal/progress"
+	"github.com/netcrate/netcrate/internal/runid"
+	"github.com/netcrate/netcrate/internal/transport"
 )
 
 // PacketOptions contains configuration for packet sending
@@ -23,6 +28,13 @@ type PacketOptions struct {
 	Timeout            time.Duration          `json:"timeout"`
 	FollowRedirects    bool                   `json:"follow_redirects"`
 	MaxResponseSize    int                    `json:"max_response_size"`
+
+	// Secrets are parameter values that must not appear in results, keyed by
+	// the reference shown in their place (e.g. secret://api_token)
+	Secrets map[string]string `json:"-"`
+
+	// OnResult, if set, is called with each result as it is collected
+	OnResult func(PacketResult) `json:"-"`
 }
 
 // PacketResult represents the result of packet sending
@@ -70,6 +82,9 @@ type ErrorInfo struct {
 // PacketSummary provides summary of packet sending results
 type PacketSummary struct {
 	RunID               string                    `json:"run_id"`
+	StartTime           time.Time                 `json:"start_time"`
+	EndTime             time.Time                 `json:"end_time"`
+	Duration            float64                   `json:"duration"`
 	TemplateUsed        string                    `json:"template_used"`
 	TargetsCount        int                       `json:"targets_count"`
 	TotalPackets        int                       `json:"total_packets"`
@@ -173,7 +188,7 @@ var PacketTemplates = map[string]PacketTemplate{
 // SendPackets sends packets using the specified template
 func SendPackets(opts PacketOptions) (*PacketSummary, error) {
 	startTime := time.Now()
-	runID := fmt.Sprintf("packet_%d", startTime.Unix())
+	runID := runid.NewAt("packet", startTime)
 
 	// Validate inputs
 	if len(opts.Targets) == 0 {
@@ -226,14 +241,29 @@ func SendPackets(opts PacketOptions) (*PacketSummary, error) {
 	stats.ByTemplate = make(map[string]int)
 	stats.MinRTT = float64(^uint(0) >> 1) // Max float64
 
+	phase := progress.Start("packet", len(opts.Targets)*opts.Count)
+	defer phase.End()
+
+	stop := abort.Context()
+sending:
 	for _, target := range opts.Targets {
 		for i := 0; i < opts.Count; i++ {
 			if i > 0 {
-				time.Sleep(opts.Interval)
+				select {
+				case <-time.After(opts.Interval):
+				case <-stop.Done():
+				}
+			}
+			if stop.Err() != nil {
+				break sending
 			}
 
 			result := sendSinglePacket(target, i+1, opts.Template, opts)
 			allResults = append(allResults, result)
+			if opts.OnResult != nil {
+				opts.OnResult(result)
+			}
+			phase.Add(1)
 
 			// Update statistics
 			if result.Status == "success" {
@@ -271,8 +301,12 @@ func SendPackets(opts PacketOptions) (*PacketSummary, error) {
 		stats.SuccessRate = float64(successCount) / float64(len(allResults))
 	}
 
+	endTime := time.Now()
 	summary := &PacketSummary{
 		RunID:               runID,
+		StartTime:           startTime,
+		EndTime:             endTime,
+		Duration:            endTime.Sub(startTime).Seconds(),
 		TemplateUsed:        opts.Template,
 		TargetsCount:        len(opts.Targets),
 		TotalPackets:        len(allResults),
@@ -293,7 +327,7 @@ func sendSinglePacket(target string, sequence int, templateName string, opts Pac
 		Timestamp: start,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	ctx, cancel := context.WithTimeout(abort.Context(), opts.Timeout)
 	defer cancel()
 
 	switch templateName {
@@ -319,9 +353,36 @@ func sendSinglePacket(target string, sequence int, templateName string, opts Pac
 	}
 
 	result.RTT = float64(time.Since(start)) / float64(time.Millisecond)
+	redactSecrets(&result, opts.Secrets)
 	return result
 }
 
+// redactSecrets replaces secret values in what a result records of the
+// exchange by their references
+func redactSecrets(result *PacketResult, secrets map[string]string) {
+	if len(secrets) == 0 {
+		return
+	}
+	redact := func(text string) string {
+		for ref, secret := range secrets {
+			text = strings.ReplaceAll(text, secret, ref)
+		}
+		return text
+	}
+	for key, value := range result.Request.Headers {
+		result.Request.Headers[key] = redact(value)
+	}
+	if result.Response != nil {
+		for key, value := range result.Response.Headers {
+			result.Response.Headers[key] = redact(value)
+		}
+		result.Response.BodyPreview = redact(result.Response.BodyPreview)
+	}
+	if result.Error != nil {
+		result.Error.Message = redact(result.Error.Message)
+	}
+}
+
 func sendSynPacket(ctx context.Context, target string, sequence int, opts PacketOptions) PacketResult {
 	// SYN packets require raw socket privileges
 	// For now, fall back to connect scan
@@ -338,7 +399,7 @@ func sendConnectPacket(ctx context.Context, target string, sequence int, opts Pa
 		},
 	}
 
-	conn, err := net.DialTimeout("tcp", target, opts.Timeout)
+	conn, err := transport.DialTimeout("tcp", target, opts.Timeout)
 	if err != nil {
 		result.Error = &ErrorInfo{
 			Type:    "connection_failed",
@@ -434,14 +495,15 @@ func sendHTTPPacket(ctx context.Context, target string, sequence int, opts Packe
 		},
 	}
 
+	// Proxy settings apply to both; only HTTPS needs a TLS config
+	var tlsConfig *tls.Config
 	if useHTTPS {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: getBoolParam(opts.TemplateParams, "verify_cert", false) == false,
-				ServerName:         getStringParam(opts.TemplateParams, "sni", host),
-			},
+		tlsConfig = &tls.Config{
+			InsecureSkipVerify: getBoolParam(opts.TemplateParams, "verify_cert", false) == false,
+			ServerName:         getStringParam(opts.TemplateParams, "sni", host),
 		}
 	}
+	client.Transport = transport.HTTPTransport(tlsConfig)
 
 	// Send request
 	resp, err := client.Do(req)
@@ -515,7 +577,7 @@ func sendTLSPacket(ctx context.Context, target string, sequence int, opts Packet
 		ServerName:         getStringParam(opts.TemplateParams, "sni", host),
 	}
 
-	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: opts.Timeout}, "tcp", target, config)
+	conn, err := transport.DialTLS(target, opts.Timeout, config)
 	if err != nil {
 		result.Error = &ErrorInfo{
 			Type:    "tls_handshake_failed",
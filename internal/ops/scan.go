@@ -9,7 +9,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/netcrate/netcrate/internal/abort"
+	"github.com/netcrate/netcrate/internal/netenv"
 	"github.com/netcrate/netcrate/internal/privileges"
+	"github.com/netcrate/netcrate/internal/progress"
+	"github.com/netcrate/netcrate/internal/runid"
 )
 
 // ScanOptions contains configuration for port scanning
@@ -22,6 +26,14 @@ type ScanOptions struct {
 	Timeout           time.Duration `json:"timeout"`
 	Concurrency       int           `json:"concurrency"`
 	RetryCount        int           `json:"retry_count"`
+	MaxPerHost        int           `json:"max_per_host,omitempty"` // concurrent probes per host, 0 for no cap
+	Exclude           []string      `json:"exclude,omitempty"`      // IPs or CIDRs never probed
+	Scope             []string      `json:"scope,omitempty"`        // IPs or CIDRs targets must lie in; empty allows all
+
+	// OnResult, if set, is called with each result as it is collected
+	OnResult func(ScanResult) `json:"-"`
+	// Classify, if set, labels each result with its compliance class
+	Classify func(host string) *ComplianceLabel `json:"-"`
 }
 
 // ScanResult represents the result of a port scan
@@ -33,6 +45,7 @@ type ScanResult struct {
 	RTT       float64                `json:"rtt"`      // milliseconds
 	Service   *ServiceInfo           `json:"service,omitempty"`
 	Timestamp time.Time              `json:"timestamp"`
+	Compliance *ComplianceLabel      `json:"compliance,omitempty"`
 }
 
 // ServiceInfo contains detected service information
@@ -60,6 +73,7 @@ type ScanSummary struct {
 	Stats            ScanStats         `json:"stats"`
 	PrivilegeMode    string            `json:"privilege_mode"`
 	FallbackReasons  []string          `json:"fallback_reasons,omitempty"`
+	Fallbacks        []string          `json:"fallbacks,omitempty"` // scan types this run could not use, and why
 	PrivilegeSummary map[string]interface{} `json:"privilege_summary,omitempty"`
 }
 
@@ -189,7 +203,7 @@ func init() {
 // ScanPorts performs port scanning on the specified targets
 func ScanPorts(opts ScanOptions) (*ScanSummary, error) {
 	startTime := time.Now()
-	runID := fmt.Sprintf("scan_%d", startTime.Unix())
+	runID := runid.NewAt("scan", startTime)
 
 	// Initialize privilege manager for capability detection
 	pm := privileges.NewPrivilegeManager()
@@ -201,6 +215,19 @@ func ScanPorts(opts ScanOptions) (*ScanSummary, error) {
 	if len(opts.Ports) == 0 {
 		return nil, fmt.Errorf("no ports specified")
 	}
+	if err := CheckScope(opts.Targets, opts.Scope); err != nil {
+		return nil, err
+	}
+	if len(opts.Exclude) > 0 {
+		targets, err := excludeTargets(opts.Targets, opts.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		if len(targets) == 0 {
+			return nil, fmt.Errorf("all targets are excluded")
+		}
+		opts.Targets = targets
+	}
 
 	// Set defaults
 	if opts.Rate == 0 {
@@ -221,17 +248,30 @@ func ScanPorts(opts ScanOptions) (*ScanSummary, error) {
 
 	// Determine actual scan type based on privileges
 	actualScanType := determineScanType(opts.ScanType, pm)
+	var fallbacks []string
+	if (opts.ScanType == "syn" || opts.ScanType == "auto") && actualScanType != "syn" {
+		fallbacks = append(fallbacks, capabilityFallback("SYN scan unavailable, using TCP connect", netenv.CapRawIPv4))
+	}
+	var syn *synScanner
+	if actualScanType == "syn" {
+		var err error
+		if syn, err = newSYNScanner(); err != nil {
+			actualScanType = "connect"
+			fallbacks = append(fallbacks, fmt.Sprintf("SYN scan unavailable, using TCP connect: %v", err))
+		} else {
+			defer syn.Close()
+		}
+	}
 
 	// Create context for cancellation
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(abort.Context())
 	defer cancel()
 
 	// Calculate total combinations
 	totalCombinations := len(opts.Targets) * len(opts.Ports)
 
 	// Rate limiter
-	rateLimiter := time.NewTicker(time.Second / time.Duration(opts.Rate))
-	defer rateLimiter.Stop()
+	rateLimiter := newRatePacer(ctx, opts.Rate)
 
 	// Results channel
 	results := make(chan ScanResult, opts.Concurrency)
@@ -239,11 +279,22 @@ func ScanPorts(opts ScanOptions) (*ScanSummary, error) {
 	// Semaphore for concurrency control
 	sem := make(chan struct{}, opts.Concurrency)
 
+	// Per-host semaphores keep a single host from taking every worker
+	hostSems := make(map[string]chan struct{})
+	if opts.MaxPerHost > 0 {
+		for _, target := range opts.Targets {
+			hostSems[target] = make(chan struct{}, opts.MaxPerHost)
+		}
+	}
+
 	var wg sync.WaitGroup
 	var stats ScanStats
 	stats.ByStatus = make(map[string]int)
 	stats.ByService = make(map[string]int)
 
+	phase := progress.Start("scan", totalCombinations)
+	defer phase.End()
+
 	// Start scanning workers
 	for _, target := range opts.Targets {
 		for _, port := range opts.Ports {
@@ -252,22 +303,34 @@ func ScanPorts(opts ScanOptions) (*ScanSummary, error) {
 			go func(target string, port int) {
 				defer wg.Done()
 				
+				if hostSem, ok := hostSems[target]; ok {
+					select {
+					case hostSem <- struct{}{}:
+					case <-ctx.Done():
+						return
+					}
+					defer func() { <-hostSem }()
+				}
+
 				// Rate limiting
 				select {
-				case <-rateLimiter.C:
+				case <-rateLimiter:
 				case <-ctx.Done():
 					return
 				}
 
-				// Concurrency control
-				select {
-				case sem <- struct{}{}:
-				case <-ctx.Done():
-					return
+				// Concurrency control; SYN probes hold no socket while they
+				// wait, so the rate limiter alone paces them
+				if syn == nil {
+					select {
+					case sem <- struct{}{}:
+					case <-ctx.Done():
+						return
+					}
+					defer func() { <-sem }()
 				}
-				defer func() { <-sem }()
 
-				result := scanSinglePort(ctx, target, port, actualScanType, opts)
+				result := scanSinglePort(ctx, target, port, actualScanType, syn, opts)
 				
 				select {
 				case results <- result:
@@ -290,7 +353,14 @@ func ScanPorts(opts ScanOptions) (*ScanSummary, error) {
 	uniqueHosts := make(map[string]bool)
 
 	for result := range results {
+		if opts.Classify != nil {
+			result.Compliance = opts.Classify(result.Host)
+		}
 		allResults = append(allResults, result)
+		if opts.OnResult != nil {
+			opts.OnResult(result)
+		}
+		phase.Add(1)
 		totalRTT += result.RTT
 		uniqueHosts[result.Host] = true
 
@@ -331,6 +401,7 @@ func ScanPorts(opts ScanOptions) (*ScanSummary, error) {
 		Stats:             stats,
 		PrivilegeMode:     pm.GetLevel().String(),
 		FallbackReasons:   pm.GetFallbackReasons(),
+		Fallbacks:         fallbacks,
 		PrivilegeSummary:  pm.GetPrivilegeSummary(),
 	}
 
@@ -396,6 +467,37 @@ func ParsePortSpec(spec string) ([]int, error) {
 	return result, nil
 }
 
+// newRatePacer hands out rate permits per second. Timers rarely fire more
+// often than every millisecond, so at higher rates each tick releases a
+// burst of permits instead of one.
+func newRatePacer(ctx context.Context, rate int) <-chan struct{} {
+	interval := time.Second / time.Duration(rate)
+	burst := 1
+	if interval < time.Millisecond {
+		burst = (rate + 999) / 1000
+		interval = time.Second * time.Duration(burst) / time.Duration(rate)
+	}
+	permits := make(chan struct{}, burst)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			for i := 0; i < burst; i++ {
+				select {
+				case permits <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return permits
+}
+
 func determineScanType(requested string, pm *privileges.PrivilegeManager) string {
 	switch requested {
 	case "syn":
@@ -418,7 +520,7 @@ func determineScanType(requested string, pm *privileges.PrivilegeManager) string
 	}
 }
 
-func scanSinglePort(ctx context.Context, target string, port int, scanType string, opts ScanOptions) ScanResult {
+func scanSinglePort(ctx context.Context, target string, port int, scanType string, syn *synScanner, opts ScanOptions) ScanResult {
 	result := ScanResult{
 		Host:      target,
 		Port:      port,
@@ -431,7 +533,7 @@ func scanSinglePort(ctx context.Context, target string, port int, scanType strin
 	case "connect":
 		result = tcpConnectScan(ctx, target, port, opts.Timeout, opts.ServiceDetection)
 	case "syn":
-		result = tcpSynScan(ctx, target, port, opts.Timeout)
+		result = syn.probe(ctx, target, port, opts.Timeout)
 	case "udp":
 		result = udpScan(ctx, target, port, opts.Timeout)
 	default:
@@ -463,7 +565,7 @@ func tcpConnectScan(ctx context.Context, target string, port int, timeout time.D
 		Timestamp: start,
 	}
 
-	address := fmt.Sprintf("%s:%d", target, port)
+	address := net.JoinHostPort(target, strconv.Itoa(port))
 	conn, err := net.DialTimeout("tcp", address, timeout)
 	result.RTT = float64(time.Since(start)) / float64(time.Millisecond)
 
@@ -492,21 +594,6 @@ func tcpConnectScan(ctx context.Context, target string, port int, timeout time.D
 	return result
 }
 
-func tcpSynScan(ctx context.Context, target string, port int, timeout time.Duration) ScanResult {
-	// SYN scanning requires raw socket privileges
-	// For now, fall back to connect scan
-	// TODO: Implement actual SYN scanning with raw sockets
-	result := tcpConnectScan(ctx, target, port, timeout, false)
-	// Mark that we fell back to connect scan
-	if result.Status == "open" {
-		if result.Service == nil {
-			result.Service = &ServiceInfo{}
-		}
-		result.Service.Banner = "[fallback: connect scan used instead of SYN]"
-	}
-	return result
-}
-
 func udpScan(ctx context.Context, target string, port int, timeout time.Duration) ScanResult {
 	start := time.Now()
 	result := ScanResult{
@@ -517,7 +604,7 @@ func udpScan(ctx context.Context, target string, port int, timeout time.Duration
 		Timestamp: start,
 	}
 
-	address := fmt.Sprintf("%s:%d", target, port)
+	address := net.JoinHostPort(target, strconv.Itoa(port))
 	conn, err := net.DialTimeout("udp", address, timeout)
 	result.RTT = float64(time.Since(start)) / float64(time.Millisecond)
 
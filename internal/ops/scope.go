@@ -0,0 +1,134 @@
+package ops
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ComplianceLabel is how the compliance policy classifies the host of a
+// result, so that exports and SIEM pipelines can filter on it
+type ComplianceLabel struct {
+	Class string `json:"class"`          // private, public or blocked
+	Rule  string `json:"rule,omitempty"` // the range or rule that decided it
+}
+
+// CheckScope returns an error naming the first target outside scope, a list
+// of IPs or CIDRs. Targets are IPs, CIDRs or hostnames; a CIDR must lie
+// entirely inside one scope network and a hostname must resolve only to
+// addresses in scope. An empty scope allows every target.
+func CheckScope(targets []string, scope []string) error {
+	if len(scope) == 0 {
+		return nil
+	}
+	networks, err := parseNetworks(scope)
+	if err != nil {
+		return fmt.Errorf("invalid scope: %w", err)
+	}
+
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		if strings.Contains(target, "/") {
+			_, ipnet, err := net.ParseCIDR(target)
+			if err != nil || !networkInScope(ipnet, networks) {
+				return fmt.Errorf("target %s is outside the engagement scope (%s)", target, strings.Join(scope, ", "))
+			}
+			continue
+		}
+
+		addresses := []net.IP{net.ParseIP(target)}
+		if addresses[0] == nil {
+			if addresses, err = net.LookupIP(target); err != nil {
+				return fmt.Errorf("cannot check %s against the engagement scope: %w", target, err)
+			}
+		}
+		for _, ip := range addresses {
+			if !ipInNetworks(ip, networks) {
+				return fmt.Errorf("target %s is outside the engagement scope (%s)", target, strings.Join(scope, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// CheckExclusions returns an error naming the first target that is one of
+// exclude, a list of IPs or CIDRs. Targets are IPs or hostnames; a hostname
+// is refused when any of its addresses is excluded. Unlike the exclusions of
+// a discovery or scan, which skip hosts of a range, this refuses targets
+// given one by one, such as those of packets.
+func CheckExclusions(targets []string, exclude []string) error {
+	if len(exclude) == 0 {
+		return nil
+	}
+	networks, err := parseNetworks(exclude)
+	if err != nil {
+		return fmt.Errorf("invalid exclusion: %w", err)
+	}
+
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		addresses := []net.IP{net.ParseIP(target)}
+		if addresses[0] == nil {
+			if addresses, err = net.LookupIP(target); err != nil {
+				return fmt.Errorf("cannot check %s against the exclusions: %w", target, err)
+			}
+		}
+		for _, ip := range addresses {
+			if ipInNetworks(ip, networks) {
+				return fmt.Errorf("target %s is excluded from scanning (%s)", target, strings.Join(exclude, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// parseNetworks parses a list of IPs or CIDRs, treating IPs as single hosts
+func parseNetworks(entries []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() == nil {
+				entry += "/128"
+			} else {
+				entry += "/32"
+			}
+		}
+		_, ipnet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP or CIDR %s", entry)
+		}
+		networks = append(networks, ipnet)
+	}
+	return networks, nil
+}
+
+func ipInNetworks(ip net.IP, networks []*net.IPNet) bool {
+	for _, ipnet := range networks {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// networkInScope reports whether a network lies inside one of networks
+func networkInScope(target *net.IPNet, networks []*net.IPNet) bool {
+	targetOnes, targetBits := target.Mask.Size()
+	for _, ipnet := range networks {
+		ones, bits := ipnet.Mask.Size()
+		if bits == targetBits && ones <= targetOnes && ipnet.Contains(target.IP) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package ops
+
+import "testing"
+
+func TestCheckExclusions(t *testing.T) {
+	exclude := []string{"10.0.0.0/24", "192.168.1.1", "fd00::1"}
+	tests := []struct {
+		target  string
+		refused bool
+	}{
+		{"10.0.0.7", true},
+		{"10.0.1.7", false},
+		{"192.168.1.1", true},
+		{"192.168.1.2", false},
+		{"fd00::1", true},
+		{"fd00::2", false},
+		{"localhost", false},
+	}
+	for _, tt := range tests {
+		err := CheckExclusions([]string{tt.target}, exclude)
+		if (err != nil) != tt.refused {
+			t.Errorf("CheckExclusions(%s) = %v, want refused %v", tt.target, err, tt.refused)
+		}
+	}
+	if err := CheckExclusions([]string{"10.0.0.7"}, nil); err != nil {
+		t.Errorf("CheckExclusions without exclusions = %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package ops
+
+import (
+	"net"
+	"sync/atomic"
+	"unsafe"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// openSYNSource captures the answers with AF_PACKET and a BPF filter, so
+// the kernel hands over only TCP segments to the scanner's port that carry
+// ACK or RST. Without the privilege for that (after dropping root, or
+// through the privileged helper) it reads the raw TCP socket instead.
+func openSYNSource(port uint16, conn net.PacketConn) (synSource, error) {
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, int(htons(unix.ETH_P_IP)))
+	if err != nil {
+		return connSource{conn}, nil
+	}
+	filter, err := bpf.Assemble(synFilter(port))
+	if err == nil {
+		err = unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, &unix.SockFprog{
+			Len:    uint16(len(filter)),
+			Filter: (*unix.SockFilter)(unsafe.Pointer(&filter[0])),
+		})
+	}
+	if err == nil {
+		// Wake up now and then to notice Close
+		err = unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &unix.Timeval{Usec: 200000})
+	}
+	if err == nil {
+		// Room for a burst of answers at high probe rates
+		unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_RCVBUF, 4<<20)
+	}
+	if err != nil {
+		unix.Close(fd)
+		return connSource{conn}, nil
+	}
+	return &packetSource{fd: fd}, nil
+}
+
+// synFilter accepts IPv4 TCP segments, not fragments, to port with ACK or
+// RST set; the packet starts at the IP header on a SOCK_DGRAM socket
+func synFilter(port uint16) []bpf.Instruction {
+	return []bpf.Instruction{
+		bpf.LoadAbsolute{Off: 9, Size: 1}, // protocol
+		bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: unix.IPPROTO_TCP, SkipTrue: 8},
+		bpf.LoadAbsolute{Off: 6, Size: 2}, // fragment offset
+		bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x1fff, SkipTrue: 6},
+		bpf.LoadMemShift{Off: 0},          // X = IP header length
+		bpf.LoadIndirect{Off: 2, Size: 2}, // TCP destination port
+		bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: uint32(port), SkipTrue: 3},
+		bpf.LoadIndirect{Off: 13, Size: 1}, // TCP flags
+		bpf.JumpIf{Cond: bpf.JumpBitsNotSet, Val: tcpFlagACK | tcpFlagRST, SkipTrue: 1},
+		bpf.RetConstant{Val: 65535},
+		bpf.RetConstant{Val: 0},
+	}
+}
+
+// packetSource reads IPv4 packets from an AF_PACKET socket
+type packetSource struct {
+	fd     int
+	closed atomic.Bool
+}
+
+func (p *packetSource) read(buffer []byte) (net.IP, []byte, error) {
+	for {
+		if p.closed.Load() {
+			// Closed here rather than in Close, so the descriptor cannot be
+			// reused while Recvfrom still waits on it
+			unix.Close(p.fd)
+			return nil, nil, net.ErrClosed
+		}
+		n, from, err := unix.Recvfrom(p.fd, buffer, 0)
+		if err == unix.EAGAIN || err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			unix.Close(p.fd)
+			return nil, nil, net.ErrClosed
+		}
+		if ll, ok := from.(*unix.SockaddrLinklayer); ok && ll.Pkttype == unix.PACKET_OUTGOING {
+			continue
+		}
+		if n < 20 {
+			continue
+		}
+		headerLength := int(buffer[0]&0x0f) * 4
+		if headerLength < 20 || n < headerLength {
+			continue
+		}
+		return net.IP(buffer[12:16]), buffer[headerLength:n], nil
+	}
+}
+
+// Close stops the reading within the receive timeout
+func (p *packetSource) Close() error {
+	p.closed.Store(true)
+	return nil
+}
+
+// htons converts to network byte order, as packet sockets take the protocol
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
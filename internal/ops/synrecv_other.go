@@ -0,0 +1,15 @@
+//go:build !linux
+
+package ops
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+)
+
+// openSYNSource fails outside Linux, where raw TCP sockets do not receive
+// the answers and no capture path is wired up
+func openSYNSource(port uint16, conn net.PacketConn) (synSource, error) {
+	return nil, fmt.Errorf("capturing SYN scan answers is not supported on %s", runtime.GOOS)
+}
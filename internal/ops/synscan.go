@@ -0,0 +1,279 @@
+package ops
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/privileges"
+)
+
+// TCP flags the SYN scanner sends and looks for
+const (
+	tcpFlagSYN = 0x02
+	tcpFlagRST = 0x04
+	tcpFlagACK = 0x10
+)
+
+// synSource delivers the TCP segments sent to the scanner's source port,
+// with the address they came from
+type synSource interface {
+	read(buffer []byte) (net.IP, []byte, error)
+	Close() error
+}
+
+// synKey identifies a probe by the target's address and port
+type synKey struct {
+	ip   [4]byte
+	port uint16
+}
+
+// synProbe is a probe waiting for its answer
+type synProbe struct {
+	seq    uint32
+	status chan string
+}
+
+// synScanner sends SYN probes from one raw socket and matches every answer
+// in a single receive loop, so a probe costs a packet and a map entry
+// rather than a connection. Probes carry a fixed source port and a sequence
+// number derived from a per-scan secret; an answer counts only when it
+// acknowledges that number. The kernel answers SYN-ACKs with a RST, as no
+// socket owns the source port.
+type synScanner struct {
+	conn   net.PacketConn
+	source synSource
+	port   uint16
+	secret [8]byte
+
+	mu      sync.Mutex
+	pending map[synKey]*synProbe
+
+	localMu sync.Mutex
+	local   map[string]net.IP // source address towards each target
+}
+
+// newSYNScanner opens the raw socket, directly or through the privileged
+// helper, and the receive path for the answers
+func newSYNScanner() (*synScanner, error) {
+	conn, err := privileges.ListenRawPacket("ip4:tcp", "0.0.0.0")
+	if err != nil {
+		return nil, fmt.Errorf("raw TCP socket: %w", err)
+	}
+	s := &synScanner{
+		conn:    conn,
+		pending: make(map[synKey]*synProbe),
+		local:   make(map[string]net.IP),
+	}
+	rand.Read(s.secret[:])
+	var port [2]byte
+	rand.Read(port[:])
+	s.port = 40000 + binary.BigEndian.Uint16(port[:])%20000
+
+	if s.source, err = openSYNSource(s.port, conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go s.receive()
+	return s, nil
+}
+
+// Close stops the receive loop and releases the sockets
+func (s *synScanner) Close() {
+	s.source.Close()
+	s.conn.Close()
+}
+
+// probe sends one SYN and waits for a SYN-ACK (open), a RST (closed) or the
+// timeout (filtered)
+func (s *synScanner) probe(ctx context.Context, target string, port int, timeout time.Duration) ScanResult {
+	start := time.Now()
+	result := ScanResult{
+		Host:      target,
+		Port:      port,
+		Status:    "filtered",
+		Protocol:  "tcp",
+		Timestamp: start,
+	}
+
+	addr, err := net.ResolveIPAddr("ip4", target)
+	if err != nil {
+		result.Status = "error"
+		return result
+	}
+	dst := addr.IP.To4()
+	src, err := s.localAddress(dst)
+	if err != nil {
+		result.Status = "error"
+		return result
+	}
+
+	key := synKey{port: uint16(port)}
+	copy(key.ip[:], dst)
+	waiting := &synProbe{seq: s.sequence(dst, uint16(port)), status: make(chan string, 1)}
+	s.mu.Lock()
+	s.pending[key] = waiting
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, key)
+		s.mu.Unlock()
+	}()
+
+	segment := buildSYN(src, dst, s.port, uint16(port), waiting.seq)
+	if _, err := s.conn.WriteTo(segment, &net.IPAddr{IP: dst}); err != nil {
+		result.Status = "error"
+		return result
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case status := <-waiting.status:
+		result.Status = status
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	result.RTT = float64(time.Since(start)) / float64(time.Millisecond)
+	return result
+}
+
+// receive matches answers to pending probes until the source is closed
+func (s *synScanner) receive() {
+	buffer := make([]byte, 65536)
+	for {
+		from, segment, err := s.source.read(buffer)
+		if err != nil {
+			if err == net.ErrClosed {
+				return
+			}
+			continue
+		}
+		if len(segment) < 20 || binary.BigEndian.Uint16(segment[2:4]) != s.port {
+			continue
+		}
+		ip4 := from.To4()
+		if ip4 == nil {
+			continue
+		}
+		key := synKey{port: binary.BigEndian.Uint16(segment[0:2])}
+		copy(key.ip[:], ip4)
+		flags := segment[13]
+		ack := binary.BigEndian.Uint32(segment[8:12])
+
+		s.mu.Lock()
+		waiting := s.pending[key]
+		s.mu.Unlock()
+		if waiting == nil || ack != waiting.seq+1 {
+			continue
+		}
+		status := ""
+		switch {
+		case flags&(tcpFlagSYN|tcpFlagACK) == tcpFlagSYN|tcpFlagACK:
+			status = "open"
+		case flags&tcpFlagRST != 0:
+			status = "closed"
+		default:
+			continue
+		}
+		select {
+		case waiting.status <- status:
+		default:
+		}
+	}
+}
+
+// sequence derives a probe's sequence number from the scan secret
+func (s *synScanner) sequence(dst net.IP, port uint16) uint32 {
+	h := fnv.New32a()
+	h.Write(s.secret[:])
+	h.Write(dst)
+	binary.Write(h, binary.BigEndian, port)
+	return h.Sum32()
+}
+
+// localAddress finds the source address the kernel uses towards a target,
+// which the TCP checksum covers
+func (s *synScanner) localAddress(dst net.IP) (net.IP, error) {
+	s.localMu.Lock()
+	defer s.localMu.Unlock()
+	if ip, ok := s.local[dst.String()]; ok {
+		return ip, nil
+	}
+	// Connecting a UDP socket sends nothing but picks the route
+	conn, err := net.Dial("udp4", net.JoinHostPort(dst.String(), "9"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	ip := conn.LocalAddr().(*net.UDPAddr).IP.To4()
+	s.local[dst.String()] = ip
+	return ip, nil
+}
+
+// buildSYN builds a TCP SYN segment with an MSS option, as operating
+// systems send, and its checksum over the IPv4 pseudo-header
+func buildSYN(src, dst net.IP, srcPort, dstPort uint16, seq uint32) []byte {
+	segment := make([]byte, 24)
+	binary.BigEndian.PutUint16(segment[0:2], srcPort)
+	binary.BigEndian.PutUint16(segment[2:4], dstPort)
+	binary.BigEndian.PutUint32(segment[4:8], seq)
+	segment[12] = 6 << 4 // data offset: six 32-bit words
+	segment[13] = tcpFlagSYN
+	binary.BigEndian.PutUint16(segment[14:16], 1024) // window
+	segment[20], segment[21] = 2, 4                  // MSS option
+	binary.BigEndian.PutUint16(segment[22:24], 1460)
+
+	pseudo := make([]byte, 0, 12+len(segment))
+	pseudo = append(pseudo, src.To4()...)
+	pseudo = append(pseudo, dst.To4()...)
+	pseudo = append(pseudo, 0, 6, 0, byte(len(segment)))
+	pseudo = append(pseudo, segment...)
+	binary.BigEndian.PutUint16(segment[16:18], internetChecksum(pseudo))
+	return segment
+}
+
+// internetChecksum is the one's complement sum of RFC 1071
+func internetChecksum(data []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(data[i:]))
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// connSource reads answers from the raw TCP socket itself, which on Linux
+// receives a copy of every incoming TCP segment; the kernel strips the IPv4
+// header
+type connSource struct {
+	conn net.PacketConn
+}
+
+func (c connSource) read(buffer []byte) (net.IP, []byte, error) {
+	n, from, err := c.conn.ReadFrom(buffer)
+	if err != nil {
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return nil, nil, err
+		}
+		return nil, nil, net.ErrClosed
+	}
+	addr, ok := from.(*net.IPAddr)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected address %v", from)
+	}
+	return addr.IP, buffer[:n], nil
+}
+
+// Close leaves the socket to synScanner.Close
+func (c connSource) Close() error { return nil }
@@ -0,0 +1,171 @@
+package output
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/version"
+)
+
+// BundleManifest describes an evidence bundle. It is stored in the bundle
+// as manifest.json with a checksum for every other file, so auditors can
+// check nothing was altered after the bundle was made.
+type BundleManifest struct {
+	CreatedAt       time.Time    `json:"created_at"`
+	NetcrateVersion string       `json:"netcrate_version"`
+	Runs            []string     `json:"runs"`
+	Redacted        bool         `json:"redacted"`
+	Files           []BundleFile `json:"files"`
+}
+
+// BundleFile is one file of an evidence bundle
+type BundleFile struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// WriteBundle writes a zip archive holding the report and the evidence
+// behind it: every file of each run's directory (results, deep scans,
+// packet captures), decrypted, and the runtime logs of the runs. Redacted
+// records are bundled as their masked result only, since the other files
+// are not masked.
+func WriteBundle(path, reportName string, report []byte, records []*store.RunRecord, redacted bool) (*BundleManifest, error) {
+	manifest := &BundleManifest{
+		CreatedAt:       time.Now(),
+		NetcrateVersion: version.Version,
+		Redacted:        redacted,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	create := func(name string) (io.Writer, error) {
+		return archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: manifest.CreatedAt})
+	}
+	add := func(name string, data []byte) error {
+		w, err := create(name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, BundleFile{Name: name, Size: len(data), SHA256: hex.EncodeToString(sum[:])})
+		return nil
+	}
+
+	if err := add(reportName, report); err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		manifest.Runs = append(manifest.Runs, record.RunID)
+		prefix := "runs/" + record.RunID + "/"
+
+		if redacted {
+			data, err := json.MarshalIndent(record, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode run record: %w", err)
+			}
+			if err := add(prefix+"result.json", append(data, '\n')); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		runFiles, err := bundleRunFiles(record.RunID)
+		if err != nil {
+			return nil, err
+		}
+		for _, runFile := range runFiles {
+			data, err := store.ReadRunFile(runFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", runFile, err)
+			}
+			rel, _ := filepath.Rel(store.RunDir(record.RunID), runFile)
+			if err := add(prefix+filepath.ToSlash(rel), data); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, logFile := range runLogFiles(record.RunID) {
+			data, err := store.ReadRunFile(logFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", logFile, err)
+			}
+			if err := add("logs/"+filepath.Base(logFile), data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bundle manifest: %w", err)
+	}
+	w, err := create("manifest.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to add manifest to bundle: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to add manifest to bundle: %w", err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return manifest, file.Close()
+}
+
+// bundleRunFiles lists the files of a run directory
+func bundleRunFiles(runID string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(store.RunDir(runID), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run files: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// runLogFiles lists the runtime logs written for a run, which carry the
+// run ID in their name (netcrate-<run-id>-<time>.log)
+func runLogFiles(runID string) []string {
+	homeDir, _ := os.UserHomeDir()
+	matches, _ := filepath.Glob(filepath.Join(homeDir, ".netcrate", "logs", "*.log"))
+	var logs []string
+	for _, match := range matches {
+		if strings.Contains(filepath.Base(match), runID) {
+			logs = append(logs, match)
+		}
+	}
+	sort.Strings(logs)
+	return logs
+}
@@ -0,0 +1,158 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/version"
+)
+
+// maxSinkBackoff caps the delay between delivery attempts
+const maxSinkBackoff = 30 * time.Second
+
+// sinkBatch is the body of every request the HTTP sink sends
+type sinkBatch struct {
+	Source string      `json:"source"`
+	Events []SinkEvent `json:"events"`
+}
+
+// HTTPSink POSTs events in batches to an HTTP endpoint. A batch is sent when
+// it is full, when the flush interval passes, and on Close. Failed requests
+// are retried with exponential backoff; a batch that still fails is dropped
+// so a dead endpoint cannot stall the operation.
+type HTTPSink struct {
+	cfg    config.SinkConfig
+	client *http.Client
+
+	mu      sync.Mutex
+	pending []SinkEvent
+	err     error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewHTTPSink starts a sink for the configured endpoint
+func NewHTTPSink(cfg config.SinkConfig) *HTTPSink {
+	sink := &HTTPSink{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go sink.flushPeriodically()
+	return sink
+}
+
+// Write queues an event and sends the batch once it is full
+func (s *HTTPSink) Write(eventType string, data interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, SinkEvent{Type: eventType, Time: time.Now(), Data: data})
+	if len(s.pending) >= s.cfg.EffectiveBatchSize() {
+		s.flushLocked()
+	}
+}
+
+// Close sends the remaining events and returns the first delivery error
+func (s *HTTPSink) Close() error {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushLocked()
+	return s.err
+}
+
+// flushPeriodically sends partial batches so results arrive while a long run is still going
+func (s *HTTPSink) flushPeriodically() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.EffectiveFlushInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			s.flushLocked()
+			s.mu.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// flushLocked sends the pending events; s.mu must be held
+func (s *HTTPSink) flushLocked() {
+	if len(s.pending) == 0 {
+		return
+	}
+	events := s.pending
+	s.pending = nil
+
+	if err := s.send(events); err != nil && s.err == nil {
+		s.err = fmt.Errorf("result sink: %d events not delivered: %w", len(events), err)
+	}
+}
+
+// send posts one batch, retrying transient failures with exponential backoff
+func (s *HTTPSink) send(events []SinkEvent) error {
+	body, err := json.Marshal(sinkBatch{Source: "netcrate/" + version.Version, Events: events})
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+
+	backoff := time.Second
+	var lastErr error
+	for attempt := 0; attempt <= s.cfg.EffectiveRetries(); attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > maxSinkBackoff {
+				backoff = maxSinkBackoff
+			}
+		}
+
+		retry, err := s.post(body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+// post sends the body once and reports whether a failure is worth retrying
+func (s *HTTPSink) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "netcrate/"+version.Version)
+	for name, value := range s.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
@@ -0,0 +1,407 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/i18n"
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/quick"
+	"github.com/netcrate/netcrate/internal/risk"
+	"github.com/netcrate/netcrate/internal/services"
+	"github.com/netcrate/netcrate/internal/style"
+)
+
+// HostPort is a port that was open on a host in at least one run
+type HostPort struct {
+	Port      int       `json:"port"`
+	Protocol  string    `json:"protocol"`
+	Service   string    `json:"service"`
+	Product   string    `json:"product,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	SeenIn    int       `json:"seen_in"`
+	StillOpen bool      `json:"still_open"` // open in the latest run that scanned the host
+	Risk      string    `json:"risk,omitempty"`
+}
+
+// HostProfile merges everything known about one host across saved runs
+type HostProfile struct {
+	Host         string                          `json:"host"`
+	FirstSeen    time.Time                       `json:"first_seen"`
+	LastSeen     time.Time                       `json:"last_seen"`
+	SeenIn       int                             `json:"seen_in"`
+	Runs         []string                        `json:"runs"`
+	MAC          string                          `json:"mac,omitempty"`
+	Vendor       string                          `json:"vendor,omitempty"`
+	Category     string                          `json:"category,omitempty"`
+	Hostnames    []string                        `json:"hostnames,omitempty"`
+	Ports        []HostPort                      `json:"ports,omitempty"`
+	Services     []string                        `json:"services,omitempty"`
+	Fingerprints []*services.ProtocolFingerprint `json:"fingerprints,omitempty"`
+	RiskNotes    []string                        `json:"risk_notes,omitempty"`
+
+	lastScanned  time.Time
+	ports        map[string]*HostPort
+	fingerprints map[int]*services.ProtocolFingerprint
+}
+
+// inventoryBuilder accumulates host profiles while runs are replayed oldest first
+type inventoryBuilder struct {
+	hosts map[string]*HostProfile
+}
+
+// BuildHostInventory merges all saved runs into per-host profiles.
+// When host is set, only that host's profile is returned.
+func BuildHostInventory(host string) ([]*HostProfile, error) {
+	runs, err := ListRuns()
+	if err != nil {
+		return nil, err
+	}
+
+	builder := &inventoryBuilder{hosts: make(map[string]*HostProfile)}
+	for i := len(runs) - 1; i >= 0; i-- {
+		record, err := LoadRecord(&runs[i])
+		if err != nil {
+			continue
+		}
+		builder.addRecord(record)
+	}
+
+	riskEngine := quick.LoadRiskEngine()
+	var profiles []*HostProfile
+	for _, profile := range builder.hosts {
+		if host != "" && profile.Host != host {
+			continue
+		}
+		profile.finish(riskEngine)
+		profiles = append(profiles, profile)
+	}
+
+	if host != "" && len(profiles) == 0 {
+		return nil, fmt.Errorf("host %s not found in any saved run", host)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return compareIPs(profiles[i].Host, profiles[j].Host)
+	})
+
+	return profiles, nil
+}
+
+// addRecord folds one run into the inventory
+func (b *inventoryBuilder) addRecord(record *store.RunRecord) {
+	seen := record.StartTime
+
+	switch record.Type {
+	case store.TypeQuick:
+		var result quick.QuickResult
+		if err := record.Decode(&result); err != nil {
+			return
+		}
+		if result.DiscoverResult != nil {
+			b.addDiscovery(record.RunID, seen, result.DiscoverResult.Results)
+		}
+		if result.ScanResult != nil {
+			b.addScan(record.RunID, seen, result.ScanResult.Results)
+		}
+		for _, device := range result.Summary.Devices {
+			profile := b.touch(device.Host, record.RunID, seen)
+			if device.MAC != "" {
+				profile.MAC, profile.Vendor = device.MAC, device.Vendor
+			}
+			if device.Category != "" {
+				profile.Category = device.Category
+			}
+			profile.addHostname(device.Hostname)
+			profile.addHostname(device.MDNSName)
+		}
+		for _, fp := range result.Fingerprints {
+			if fp == nil || fp.Service == "" || fp.Service == "unknown" {
+				continue
+			}
+			if profile, ok := b.hosts[fp.Host]; ok {
+				profile.fingerprints[fp.Port] = fp
+			}
+		}
+	case store.TypeDiscover:
+		var result ops.DiscoverSummary
+		if err := record.Decode(&result); err == nil {
+			b.addDiscovery(record.RunID, seen, result.Results)
+		}
+	case store.TypeScan:
+		var result ops.ScanSummary
+		if err := record.Decode(&result); err == nil {
+			b.addScan(record.RunID, seen, result.Results)
+		}
+	}
+}
+
+// addDiscovery records the hosts a discovery found up
+func (b *inventoryBuilder) addDiscovery(runID string, seen time.Time, results []ops.DiscoverResult) {
+	for _, result := range results {
+		if result.Status != "up" {
+			continue
+		}
+		profile := b.touch(result.Host, runID, seen)
+		profile.addHostname(result.Hostname)
+	}
+}
+
+// addScan records open ports and when each host was last scanned
+func (b *inventoryBuilder) addScan(runID string, seen time.Time, results []ops.ScanResult) {
+	for _, result := range results {
+		if profile, ok := b.hosts[result.Host]; ok && seen.After(profile.lastScanned) {
+			profile.lastScanned = seen
+		}
+		if result.Status != "open" {
+			continue
+		}
+
+		profile := b.touch(result.Host, runID, seen)
+		profile.lastScanned = seen
+
+		protocol := result.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		key := fmt.Sprintf("%d/%s", result.Port, protocol)
+		port, ok := profile.ports[key]
+		if !ok {
+			port = &HostPort{Port: result.Port, Protocol: protocol, FirstSeen: seen}
+			profile.ports[key] = port
+		}
+		if !port.LastSeen.Equal(seen) {
+			port.SeenIn++
+		}
+		port.LastSeen = seen
+		if result.Service != nil && result.Service.Name != "" {
+			port.Service = result.Service.Name
+			if result.Service.Version != "" {
+				port.Version = result.Service.Version
+			}
+		}
+	}
+}
+
+// touch returns the profile for host, counting runID as a sighting
+func (b *inventoryBuilder) touch(host, runID string, seen time.Time) *HostProfile {
+	profile, ok := b.hosts[host]
+	if !ok {
+		profile = &HostProfile{
+			Host:         host,
+			FirstSeen:    seen,
+			ports:        make(map[string]*HostPort),
+			fingerprints: make(map[int]*services.ProtocolFingerprint),
+		}
+		b.hosts[host] = profile
+	}
+
+	if len(profile.Runs) == 0 || profile.Runs[len(profile.Runs)-1] != runID {
+		profile.Runs = append(profile.Runs, runID)
+		profile.SeenIn++
+	}
+	profile.LastSeen = seen
+	return profile
+}
+
+// addHostname records a name once
+func (p *HostProfile) addHostname(name string) {
+	if name == "" {
+		return
+	}
+	for _, existing := range p.Hostnames {
+		if existing == name {
+			return
+		}
+	}
+	p.Hostnames = append(p.Hostnames, name)
+}
+
+// finish flattens ports and fingerprints and assesses the ports that are still open
+func (p *HostProfile) finish(riskEngine *risk.Engine) {
+	serviceSet := make(map[string]bool)
+	for _, port := range p.ports {
+		if fp, ok := p.fingerprints[port.Port]; ok {
+			port.Service = fp.Service
+			port.Product = fp.Application
+			if fp.Version != "" {
+				port.Version = fp.Version
+			}
+		}
+		if port.Service == "" {
+			port.Service = "unknown"
+		}
+		port.StillOpen = !port.LastSeen.Before(p.lastScanned)
+		serviceSet[port.Service] = true
+
+		if port.StillOpen {
+			assessment := riskEngine.Evaluate(risk.Finding{
+				Host:    p.Host,
+				Port:    port.Port,
+				Service: port.Service,
+				Version: port.Version,
+			})
+			if assessment.Severity != "low" {
+				port.Risk = assessment.Severity
+				p.RiskNotes = append(p.RiskNotes, fmt.Sprintf("%d/%s %s (%s): %s",
+					port.Port, port.Protocol, port.Service, assessment.Severity, assessment.Rationale))
+			}
+		}
+		p.Ports = append(p.Ports, *port)
+	}
+
+	sort.Slice(p.Ports, func(i, j int) bool {
+		return p.Ports[i].Port < p.Ports[j].Port
+	})
+	sort.Strings(p.RiskNotes)
+
+	for service := range serviceSet {
+		if service != "unknown" {
+			p.Services = append(p.Services, service)
+		}
+	}
+	sort.Strings(p.Services)
+
+	for _, port := range p.Ports {
+		if fp, ok := p.fingerprints[port.Port]; ok {
+			p.Fingerprints = append(p.Fingerprints, fp)
+		}
+	}
+}
+
+// openPortCount returns the number of ports open in the latest scan
+func (p *HostProfile) openPortCount() int {
+	count := 0
+	for _, port := range p.Ports {
+		if port.StillOpen {
+			count++
+		}
+	}
+	return count
+}
+
+// InventoryColumns are the columns of the host inventory table
+var InventoryColumns = []string{"host", "mac", "name", "last_seen", "seen_in", "ports", "risks"}
+
+// InventoryTable lists one row per host profile
+func InventoryTable(profiles []*HostProfile) *Table {
+	table := &Table{
+		Columns: InventoryColumns,
+		Headers: map[string]string{
+			"host":      i18n.T("output.trends.col.host"),
+			"mac":       i18n.T("output.hosts.col.mac"),
+			"name":      i18n.T("output.hosts.col.name"),
+			"last_seen": i18n.T("output.trends.col.last_seen"),
+			"seen_in":   i18n.T("output.trends.col.seen_in"),
+			"ports":     i18n.T("output.hosts.col.ports"),
+			"risks":     i18n.T("output.hosts.col.risk"),
+		},
+	}
+	for _, profile := range profiles {
+		name := ""
+		if len(profile.Hostnames) > 0 {
+			name = profile.Hostnames[0]
+		}
+		table.Rows = append(table.Rows, map[string]string{
+			"host":      profile.Host,
+			"mac":       profile.MAC,
+			"name":      name,
+			"last_seen": profile.LastSeen.Format("2006-01-02 15:04"),
+			"seen_in":   strconv.Itoa(profile.SeenIn),
+			"ports":     strconv.Itoa(profile.openPortCount()),
+			"risks":     strconv.Itoa(len(profile.RiskNotes)),
+		})
+	}
+	return table
+}
+
+// PrintHostInventory displays one line per host, paging long inventories
+func PrintHostInventory(profiles []*HostProfile, opts TableOptions) error {
+	if len(profiles) == 0 {
+		fmt.Println(i18n.T("output.hosts.empty"))
+		return nil
+	}
+	table, err := InventoryTable(profiles).View(opts)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprint(&buf, i18n.T("output.hosts.title", len(profiles)))
+	fmt.Fprintln(&buf, "========================")
+	WriteTable(&buf, table, opts)
+	fmt.Fprint(&buf, i18n.T("output.hosts.detail_hint"))
+	return Page(buf.Bytes(), !opts.NoPager)
+}
+
+// PrintHostProfile displays everything known about one host
+func PrintHostProfile(profile *HostProfile) {
+	fmt.Print(i18n.T("output.hosts.profile_title", profile.Host))
+	fmt.Println("========================")
+	fmt.Print(i18n.T("output.hosts.seen", profile.FirstSeen.Format("2006-01-02 15:04"),
+		profile.LastSeen.Format("2006-01-02 15:04"), profile.SeenIn))
+	if profile.MAC != "" {
+		fmt.Print(i18n.T("output.hosts.mac", profile.MAC, profile.Vendor))
+	}
+	if profile.Category != "" {
+		fmt.Print(i18n.T("output.hosts.category", profile.Category))
+	}
+	if len(profile.Hostnames) > 0 {
+		fmt.Print(i18n.T("output.hosts.names", strings.Join(profile.Hostnames, ", ")))
+	}
+	if len(profile.Services) > 0 {
+		fmt.Print(i18n.T("output.hosts.services", strings.Join(profile.Services, ", ")))
+	}
+
+	if len(profile.Ports) > 0 {
+		fmt.Println(i18n.T("output.hosts.ports_title"))
+		for _, port := range profile.Ports {
+			state := i18n.T("output.hosts.state_open")
+			if !port.StillOpen {
+				state = i18n.T("output.hosts.state_closed")
+			}
+			detail := strings.TrimSpace(port.Product + " " + port.Version)
+			fmt.Printf("  %-10s %-12s %-10s %-28s %s → %s (%d)\n",
+				fmt.Sprintf("%d/%s", port.Port, port.Protocol), port.Service, state, truncate(detail, 28),
+				port.FirstSeen.Format("2006-01-02"), port.LastSeen.Format("2006-01-02"), port.SeenIn)
+		}
+	}
+
+	if len(profile.Fingerprints) > 0 {
+		fmt.Println(i18n.T("output.hosts.fingerprints_title"))
+		for _, fp := range profile.Fingerprints {
+			line := fmt.Sprintf("  • %d %s", fp.Port, fp.Service)
+			if fp.Application != "" {
+				line += " " + strings.TrimSpace(fp.Application+" "+fp.Version)
+			}
+			if fp.TLS != nil {
+				line += fmt.Sprintf(" [%s]", fp.TLS.Version)
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if len(profile.RiskNotes) > 0 {
+		fmt.Println(i18n.T("output.hosts.risk_title"))
+		for _, note := range profile.RiskNotes {
+			style.Printf("  ⚠️  %s\n", note)
+		}
+	}
+
+	fmt.Print(i18n.T("output.hosts.runs", strings.Join(profile.Runs, ", ")))
+}
+
+// truncate shortens s to max characters with an ellipsis
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max-3] + "..."
+}
@@ -1,15 +1,15 @@
 package output
 
 import (
-	"encoding/json"
+	"bytes"
 	"fmt"
-	"io/fs"
-	"os"
-	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/netcrate/netcrate/internal/i18n"
+	"github.com/netcrate/netcrate/internal/output/store"
 	"github.com/netcrate/netcrate/internal/quick"
 )
 
@@ -18,55 +18,130 @@ type RunInfo struct {
 	RunID     string    `json:"run_id"`
 	StartTime time.Time `json:"start_time"`
 	Duration  float64   `json:"duration"`
-	Type      string    `json:"type"`      // "quick", "ops"
+	Type      string    `json:"type"`      // "quick", "discover", "scan", "packet"
+	Status    string    `json:"status,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+	Targets   []string  `json:"targets,omitempty"`
 	Summary   string    `json:"summary"`   // Brief description
+	Hosts     int       `json:"hosts"`
+	OpenPorts int       `json:"open_ports"`
+	DiskUsage int64     `json:"disk_usage"` // Bytes in the run directory
 	FilePath  string    `json:"file_path"` // Path to result file
+
+	record *store.RunRecord
 }
 
 // ListRuns returns all saved runs from ~/.netcrate/runs/
 func ListRuns() ([]RunInfo, error) {
-	homeDir, err := os.UserHomeDir()
+	records, skipped, err := store.List()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
 
-	runsDir := filepath.Join(homeDir, ".netcrate", "runs")
-	
-	// Check if runs directory exists
-	if _, err := os.Stat(runsDir); os.IsNotExist(err) {
-		return []RunInfo{}, nil // No runs yet
+	for path, err := range skipped {
+		fmt.Printf("Warning: Failed to parse %s: %v\n", path, err)
 	}
 
-	var runs []RunInfo
+	runs := make([]RunInfo, 0, len(records))
+	for _, record := range records {
+		hosts, openPorts := runCounts(record)
+		runs = append(runs, RunInfo{
+			RunID:     record.RunID,
+			StartTime: record.StartTime,
+			Duration:  record.Duration,
+			Type:      record.Type,
+			Status:    record.Status,
+			Name:      record.Name,
+			Tags:      record.Tags,
+			Notes:     record.Notes,
+			Targets:   record.Targets,
+			Summary:   generateSummary(record),
+			Hosts:     hosts,
+			OpenPorts: openPorts,
+			DiskUsage: store.RunSize(record.RunID),
+			FilePath:  record.FilePath,
+			record:    record,
+		})
+	}
 
-	err = filepath.WalkDir(runsDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+	return runs, nil
+}
 
-		// Look for result.json files
-		if d.Name() == "result.json" {
-			runInfo, err := parseRunFile(path)
-			if err != nil {
-				fmt.Printf("Warning: Failed to parse %s: %v\n", path, err)
-				return nil // Skip this file but continue
-			}
-			runs = append(runs, runInfo)
-		}
+// runCounts returns the live hosts and open ports of a run. Records written
+// before the envelope existed carry no counts, so their result is decoded.
+func runCounts(record *store.RunRecord) (hosts, openPorts int) {
+	if record.Counts != nil || record.Type != store.TypeQuick {
+		return record.Counts["hosts"], record.Counts["open_ports"]
+	}
 
-		return nil
-	})
+	var result quick.QuickResult
+	if err := record.Decode(&result); err != nil {
+		return 0, 0
+	}
+	return result.Summary.HostsDiscovered, result.Summary.OpenPorts
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to scan runs directory: %w", err)
+// SortRuns orders runs by "time" (newest first), "hosts" or "open-ports"
+// (most first). Ties keep the newest run first.
+func SortRuns(runs []RunInfo, by string) error {
+	var less func(a, b *RunInfo) bool
+	switch by {
+	case "", "time":
+		less = func(a, b *RunInfo) bool { return a.StartTime.After(b.StartTime) }
+	case "hosts":
+		less = func(a, b *RunInfo) bool { return a.Hosts > b.Hosts }
+	case "open-ports":
+		less = func(a, b *RunInfo) bool { return a.OpenPorts > b.OpenPorts }
+	default:
+		return fmt.Errorf("unknown sort key '%s' (use time, hosts or open-ports)", by)
 	}
 
-	// Sort by start time, newest first
-	sort.Slice(runs, func(i, j int) bool {
-		return runs[i].StartTime.After(runs[j].StartTime)
+	sort.SliceStable(runs, func(i, j int) bool {
+		a, b := &runs[i], &runs[j]
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.StartTime.After(b.StartTime)
 	})
+	return nil
+}
 
-	return runs, nil
+// FilterRuns keeps runs carrying every tag in tags and matching the
+// free-text search over run ID, name, tags, targets, notes and summary
+func FilterRuns(runs []RunInfo, tags []string, search string) []RunInfo {
+	search = strings.ToLower(strings.TrimSpace(search))
+
+	var filtered []RunInfo
+	for _, run := range runs {
+		labels := store.Labels{Name: run.Name, Tags: run.Tags, Notes: run.Notes}
+		matched := true
+		for _, tag := range tags {
+			if !labels.HasTag(tag) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		if search != "" {
+			text := strings.ToLower(strings.Join([]string{
+				run.RunID, run.Name, strings.Join(run.Tags, " "), strings.Join(run.Targets, " "), run.Notes, run.Summary,
+			}, " "))
+			if !strings.Contains(text, search) {
+				continue
+			}
+		}
+
+		filtered = append(filtered, run)
+	}
+	return filtered
 }
 
 // GetLastRun returns the most recent run
@@ -83,8 +158,13 @@ func GetLastRun() (*RunInfo, error) {
 	return &runs[0], nil
 }
 
-// GetRunByID finds a specific run by its ID
-func GetRunByID(runID string) (*RunInfo, error) {
+// GetRunByID finds a specific run by its ID, alias or a unique ID prefix
+func GetRunByID(ref string) (*RunInfo, error) {
+	runID, err := store.Resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+
 	runs, err := ListRuns()
 	if err != nil {
 		return nil, err
@@ -99,100 +179,196 @@ func GetRunByID(runID string) (*RunInfo, error) {
 	return nil, fmt.Errorf("run with ID '%s' not found", runID)
 }
 
+// LoadRecord returns the stored record of a run
+func LoadRecord(runInfo *RunInfo) (*store.RunRecord, error) {
+	if runInfo.record != nil {
+		return runInfo.record, nil
+	}
+
+	record, err := store.LoadFile(runInfo.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open result file: %w", err)
+	}
+	runInfo.record = record
+	return record, nil
+}
+
 // LoadQuickResult loads a quick mode result from file
 func LoadQuickResult(runInfo *RunInfo) (*quick.QuickResult, error) {
-	file, err := os.Open(runInfo.FilePath)
+	record, err := LoadRecord(runInfo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open result file: %w", err)
+		return nil, err
+	}
+	if record.Type != store.TypeQuick {
+		return nil, fmt.Errorf("run '%s' is a %s run, not a quick run", record.RunID, record.Type)
 	}
-	defer file.Close()
 
 	var result quick.QuickResult
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&result)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode result: %w", err)
+	if err := record.Decode(&result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
 }
 
-// parseRunFile extracts metadata from a result.json file
-func parseRunFile(filePath string) (RunInfo, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return RunInfo{}, err
+// generateSummary creates a brief description of the run results
+func generateSummary(record *store.RunRecord) string {
+	if record.Type != store.TypeQuick {
+		return generateCountsSummary(record)
 	}
-	defer file.Close()
 
 	var result quick.QuickResult
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&result)
-	if err != nil {
-		return RunInfo{}, err
+	if err := record.Decode(&result); err != nil {
+		return ""
 	}
 
-	// Generate summary
-	summary := generateSummary(&result)
-
-	return RunInfo{
-		RunID:     result.RunID,
-		StartTime: result.StartTime,
-		Duration:  result.Duration,
-		Type:      "quick",
-		Summary:   summary,
-		FilePath:  filePath,
-	}, nil
-}
+	if result.Status == "partial" && result.DiscoverResult != nil {
+		return i18n.T("output.summary.partial", result.DiscoverResult.HostsDiscovered)
+	}
 
-// generateSummary creates a brief description of the run results
-func generateSummary(result *quick.QuickResult) string {
 	if result.Summary.HostsDiscovered == 0 {
-		return "No hosts discovered"
+		return i18n.T("output.summary.no_hosts")
 	}
 
 	parts := []string{
-		fmt.Sprintf("%d hosts", result.Summary.HostsDiscovered),
+		i18n.T("output.summary.hosts", result.Summary.HostsDiscovered),
 	}
 
 	if result.Summary.OpenPorts > 0 {
-		parts = append(parts, fmt.Sprintf("%d ports", result.Summary.OpenPorts))
+		parts = append(parts, i18n.T("output.summary.ports", result.Summary.OpenPorts))
 	}
 
 	if len(result.Summary.CriticalPorts) > 0 {
-		parts = append(parts, fmt.Sprintf("%d critical", len(result.Summary.CriticalPorts)))
+		parts = append(parts, i18n.T("output.summary.critical", len(result.Summary.CriticalPorts)))
 	}
 
 	return strings.Join(parts, ", ")
 }
 
-// PrintRunsList displays a formatted list of runs
-func PrintRunsList(runs []RunInfo) {
-	if len(runs) == 0 {
-		fmt.Println("No saved runs found.")
-		fmt.Println("Run 'netcrate quick' to create your first scan.")
-		return
+// generateCountsSummary describes discover, scan and packet runs from their record counts
+func generateCountsSummary(record *store.RunRecord) string {
+	var parts []string
+
+	switch record.Type {
+	case store.TypeDiscover:
+		if record.Counts["hosts"] == 0 {
+			return i18n.T("output.summary.no_hosts")
+		}
+		parts = append(parts, i18n.T("output.summary.hosts", record.Counts["hosts"]))
+	case store.TypeScan:
+		parts = append(parts, i18n.T("output.summary.hosts", record.Counts["hosts"]))
+		parts = append(parts, i18n.T("output.summary.ports", record.Counts["open_ports"]))
+	case store.TypePacket:
+		parts = append(parts, i18n.T("output.summary.responses", record.Counts["responses"], record.Counts["packets"]))
 	}
 
-	fmt.Printf("📁 Saved Runs (%d total)\n", len(runs))
-	fmt.Println("========================")
-	fmt.Printf("%-20s %-12s %-8s %-25s %s\n", 
-		"Run ID", "Type", "Duration", "Date", "Summary")
-	fmt.Println(strings.Repeat("-", 85))
+	if len(record.Targets) > 0 {
+		parts = append(parts, strings.Join(record.Targets, ","))
+	}
+
+	return strings.Join(parts, ", ")
+}
 
+// RunColumns are the columns of the run list table
+var RunColumns = []string{"run_id", "type", "duration", "size", "date", "hosts", "open_ports", "summary"}
+
+// RunsTable lists one row per run
+func RunsTable(runs []RunInfo) *Table {
+	table := &Table{
+		Columns: RunColumns,
+		Headers: map[string]string{
+			"run_id":     i18n.T("output.list.col.run_id"),
+			"type":       i18n.T("output.list.col.type"),
+			"duration":   i18n.T("output.list.col.duration"),
+			"size":       i18n.T("output.list.col.size"),
+			"date":       i18n.T("output.list.col.date"),
+			"hosts":      i18n.T("output.trends.col.hosts"),
+			"open_ports": i18n.T("output.trends.col.ports"),
+			"summary":    i18n.T("output.list.col.summary"),
+		},
+	}
 	for _, run := range runs {
-		durationStr := fmt.Sprintf("%.1fs", run.Duration)
-		dateStr := run.StartTime.Format("2006-01-02 15:04:05")
-		
-		fmt.Printf("%-20s %-12s %-8s %-25s %s\n",
-			run.RunID, run.Type, durationStr, dateStr, run.Summary)
+		summary := run.Summary
+		if run.Name != "" {
+			summary = fmt.Sprintf("[%s] %s", run.Name, summary)
+		}
+		for _, tag := range run.Tags {
+			summary += " #" + tag
+		}
+
+		table.Rows = append(table.Rows, map[string]string{
+			"run_id":     run.RunID,
+			"type":       run.Type,
+			"duration":   fmt.Sprintf("%.1fs", run.Duration),
+			"size":       FormatBytes(run.DiskUsage),
+			"date":       run.StartTime.Format("2006-01-02 15:04:05"),
+			"hosts":      strconv.Itoa(run.Hosts),
+			"open_ports": strconv.Itoa(run.OpenPorts),
+			"summary":    summary,
+		})
 	}
+	return table
+}
 
-	fmt.Printf("\nUse 'netcrate output show --run <run-id>' to view details\n")
-	fmt.Printf("Use 'netcrate output show --last' to view the latest run\n")
+// PrintRunsList displays a formatted list of runs, at most limit of them
+// when limit is positive, followed by totals over all runs given. Long
+// lists are paged.
+func PrintRunsList(runs []RunInfo, limit int, opts TableOptions) error {
+	if len(runs) == 0 {
+		fmt.Println(i18n.T("output.list.empty"))
+		fmt.Println(i18n.T("output.list.first_run_hint"))
+		return nil
+	}
+
+	var totalHosts, totalOpenPorts int
+	var totalSize int64
+	for _, run := range runs {
+		totalHosts += run.Hosts
+		totalOpenPorts += run.OpenPorts
+		totalSize += run.DiskUsage
+	}
+
+	shown := runs
+	if limit > 0 && limit < len(runs) {
+		shown = runs[:limit]
+	}
+	table, err := RunsTable(shown).View(opts)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprint(&buf, i18n.T("output.list.title", len(runs)))
+	fmt.Fprintln(&buf, "========================")
+	WriteTable(&buf, table, opts)
+	fmt.Fprintln(&buf)
+	if len(shown) < len(runs) {
+		fmt.Fprint(&buf, i18n.T("output.list.limited", len(shown), len(runs)))
+	}
+	fmt.Fprint(&buf, i18n.T("output.list.totals", len(runs), totalHosts, totalOpenPorts, FormatBytes(totalSize)))
+
+	fmt.Fprint(&buf, i18n.T("output.list.show_hint"))
+	fmt.Fprint(&buf, i18n.T("output.list.last_hint"))
+	return Page(buf.Bytes(), !opts.NoPager)
 }
 
-// PrintRunDetails displays detailed information about a specific run
+// FormatBytes renders a size with a binary unit, e.g. "1.5 MB"
+func FormatBytes(size int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
+
+// PrintRunDetails displays detailed information about a quick mode run.
+// Discover, scan and packet runs are printed by their own commands' tables.
 func PrintRunDetails(runInfo *RunInfo) error {
 	result, err := LoadQuickResult(runInfo)
 	if err != nil {
@@ -205,28 +381,56 @@ func PrintRunDetails(runInfo *RunInfo) error {
 	return nil
 }
 
+// PrintRunContext shows the environment and version a run was made with
+func PrintRunContext(record *store.RunRecord) {
+	context := record.Context
+	if context == nil {
+		return
+	}
+
+	fmt.Println(i18n.T("output.context.title"))
+	if context.Version != "" {
+		fmt.Print(i18n.T("output.context.version", context.Version))
+	}
+	if env := context.Environment; env != nil {
+		if env.Hostname != "" {
+			fmt.Print(i18n.T("output.context.host", env.Hostname, env.Platform))
+		}
+		if env.Interface != "" {
+			fmt.Print(i18n.T("output.context.interface", env.Interface, env.Address, env.MAC))
+		}
+		if env.Gateway != "" {
+			fmt.Print(i18n.T("output.context.gateway", env.Gateway))
+		}
+		if env.SSID != "" {
+			fmt.Print(i18n.T("output.context.ssid", env.SSID))
+		}
+		if env.PublicIP != "" {
+			fmt.Print(i18n.T("output.context.public_ip", env.PublicIP))
+		}
+	}
+	if scope := context.Scope; scope != nil {
+		fmt.Print(i18n.T("output.context.scope", scope.Authorization, scope.File))
+	}
+	if authorization := context.Authorization; authorization != nil {
+		fmt.Print(i18n.T("output.context.authorized_by", authorization))
+	}
+	for _, clamp := range context.Clamped {
+		fmt.Print(i18n.T("output.context.clamped", clamp))
+	}
+}
+
 // CleanOldRuns removes runs older than the specified number of days
 func CleanOldRuns(daysToKeep int) (int, error) {
-	runs, err := ListRuns()
+	policy := store.RetentionPolicy{MaxAge: time.Duration(daysToKeep) * 24 * time.Hour}
+	candidates, err := store.PlanPrune(policy, "")
 	if err != nil {
 		return 0, err
 	}
 
-	cutoffTime := time.Now().AddDate(0, 0, -daysToKeep)
-	var cleaned int
-
-	for _, run := range runs {
-		if run.StartTime.Before(cutoffTime) {
-			// Delete the entire run directory
-			runDir := filepath.Dir(run.FilePath)
-			err := os.RemoveAll(runDir)
-			if err != nil {
-				fmt.Printf("Warning: Failed to remove %s: %v\n", runDir, err)
-				continue
-			}
-			cleaned++
-		}
+	if err := store.Prune(candidates); err != nil {
+		return 0, err
 	}
 
-	return cleaned, nil
-}
\ No newline at end of file
+	return len(candidates), nil
+}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/quick"
+	"github.com/netcrate/netcrate/internal/runid"
+)
+
+// MergeRuns unions the results of several saved runs into a new quick run
+// named outID (a new merged_<ULID> ID when empty) and saves it
+func MergeRuns(runIDs []string, outID string, labels store.Labels) (*quick.QuickResult, error) {
+	if outID == "" {
+		outID = runid.New("merged")
+	}
+	if strings.ContainsAny(outID, `/\`) || strings.HasPrefix(outID, ".") {
+		return nil, fmt.Errorf("invalid run ID: %s", outID)
+	}
+	if _, err := os.Stat(store.RunDir(outID)); err == nil {
+		return nil, fmt.Errorf("run '%s' already exists", outID)
+	}
+
+	var parts []*quick.QuickResult
+	seen := make(map[string]bool)
+	for _, runID := range runIDs {
+		record, err := store.Load(runID)
+		if err != nil {
+			return nil, err
+		}
+		// Different prefixes or an alias may name the same run
+		if seen[record.RunID] {
+			continue
+		}
+		seen[record.RunID] = true
+		part, err := mergePart(record)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+
+	merged, err := quick.MergeResults(outID, parts)
+	if err != nil {
+		return nil, err
+	}
+	merged.Labels = labels
+	if err := quick.SaveResult(merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// mergePart converts a saved run into the quick result shape MergeResults expects
+func mergePart(record *store.RunRecord) (*quick.QuickResult, error) {
+	part := &quick.QuickResult{
+		RunID:       record.RunID,
+		TargetCIDRs: record.Targets,
+		StartTime:   record.StartTime,
+		EndTime:     record.EndTime,
+		Duration:    record.Duration,
+	}
+
+	switch record.Type {
+	case store.TypeQuick:
+		if err := record.Decode(part); err != nil {
+			return nil, err
+		}
+	case store.TypeDiscover:
+		var result ops.DiscoverSummary
+		if err := record.Decode(&result); err != nil {
+			return nil, err
+		}
+		part.DiscoverResult = &result
+	case store.TypeScan:
+		var result ops.ScanSummary
+		if err := record.Decode(&result); err != nil {
+			return nil, err
+		}
+		part.ScanResult = &result
+	default:
+		return nil, fmt.Errorf("%s runs cannot be merged (%s)", record.Type, record.RunID)
+	}
+	return part, nil
+}
@@ -0,0 +1,453 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/quick"
+	"github.com/netcrate/netcrate/internal/version"
+)
+
+// NmapRun is the root element of an nmap XML document (xmloutputversion 1.05).
+// Importers such as Metasploit's db_import and Faraday key on this layout.
+type NmapRun struct {
+	XMLName          xml.Name      `xml:"nmaprun"`
+	Scanner          string        `xml:"scanner,attr"`
+	Args             string        `xml:"args,attr"`
+	Start            int64         `xml:"start,attr"`
+	StartStr         string        `xml:"startstr,attr"`
+	Version          string        `xml:"version,attr"`
+	XMLOutputVersion string        `xml:"xmloutputversion,attr"`
+	ScanInfo         *NmapScanInfo `xml:"scaninfo,omitempty"`
+	Hosts            []NmapHost    `xml:"host"`
+	RunStats         NmapRunStats  `xml:"runstats"`
+}
+
+// NmapScanInfo describes the scan type and port list
+type NmapScanInfo struct {
+	Type        string `xml:"type,attr"`
+	Protocol    string `xml:"protocol,attr"`
+	NumServices int    `xml:"numservices,attr"`
+	Services    string `xml:"services,attr"`
+}
+
+// NmapHost is a single scanned host
+type NmapHost struct {
+	StartTime int64         `xml:"starttime,attr,omitempty"`
+	EndTime   int64         `xml:"endtime,attr,omitempty"`
+	Status    NmapStatus    `xml:"status"`
+	Addresses []NmapAddress `xml:"address"`
+	Hostnames NmapHostnames `xml:"hostnames"`
+	Ports     *NmapPorts    `xml:"ports,omitempty"`
+	Times     *NmapTimes    `xml:"times,omitempty"`
+}
+
+// NmapStatus is the host state
+type NmapStatus struct {
+	State     string `xml:"state,attr"`
+	Reason    string `xml:"reason,attr"`
+	ReasonTTL int    `xml:"reason_ttl,attr"`
+}
+
+// NmapAddress is an IP or MAC address
+type NmapAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+	Vendor   string `xml:"vendor,attr,omitempty"`
+}
+
+// NmapHostnames wraps the hostname list
+type NmapHostnames struct {
+	Hostnames []NmapHostname `xml:"hostname"`
+}
+
+// NmapHostname is a resolved name
+type NmapHostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// NmapPorts holds the port table of a host
+type NmapPorts struct {
+	ExtraPorts []NmapExtraPorts `xml:"extraports"`
+	Ports      []NmapPort       `xml:"port"`
+}
+
+// NmapExtraPorts summarizes ports not listed individually
+type NmapExtraPorts struct {
+	State string `xml:"state,attr"`
+	Count int    `xml:"count,attr"`
+}
+
+// NmapPort is a single port entry
+type NmapPort struct {
+	Protocol string       `xml:"protocol,attr"`
+	PortID   int          `xml:"portid,attr"`
+	State    NmapState    `xml:"state"`
+	Service  *NmapService `xml:"service,omitempty"`
+}
+
+// NmapState is the port state
+type NmapState struct {
+	State     string `xml:"state,attr"`
+	Reason    string `xml:"reason,attr"`
+	ReasonTTL int    `xml:"reason_ttl,attr"`
+}
+
+// NmapService describes the detected service
+type NmapService struct {
+	Name      string `xml:"name,attr"`
+	Product   string `xml:"product,attr,omitempty"`
+	Version   string `xml:"version,attr,omitempty"`
+	ExtraInfo string `xml:"extrainfo,attr,omitempty"`
+	Tunnel    string `xml:"tunnel,attr,omitempty"`
+	Method    string `xml:"method,attr"`
+	Conf      int    `xml:"conf,attr"`
+}
+
+// NmapTimes carries round-trip timing in microseconds
+type NmapTimes struct {
+	SRTT   int `xml:"srtt,attr"`
+	RTTVar int `xml:"rttvar,attr"`
+	To     int `xml:"to,attr"`
+}
+
+// NmapRunStats closes the document
+type NmapRunStats struct {
+	Finished NmapFinished  `xml:"finished"`
+	Hosts    NmapHostStats `xml:"hosts"`
+}
+
+// NmapFinished records when the run ended
+type NmapFinished struct {
+	Time    int64   `xml:"time,attr"`
+	TimeStr string  `xml:"timestr,attr"`
+	Elapsed float64 `xml:"elapsed,attr"`
+	Summary string  `xml:"summary,attr"`
+	Exit    string  `xml:"exit,attr"`
+}
+
+// NmapHostStats counts hosts by state
+type NmapHostStats struct {
+	Up    int `xml:"up,attr"`
+	Down  int `xml:"down,attr"`
+	Total int `xml:"total,attr"`
+}
+
+// BuildNmapRun converts discovery and scan results into an nmap document.
+// Either summary may be nil; only hosts that are up are listed, as nmap does.
+func BuildNmapRun(discover *ops.DiscoverSummary, scan *ops.ScanSummary) *NmapRun {
+	run := &NmapRun{
+		Scanner:          "netcrate",
+		Version:          version.Version,
+		XMLOutputVersion: "1.05",
+	}
+
+	var start, end time.Time
+	var args []string
+	hosts := make(map[string]*NmapHost)
+	var order []string
+	down := 0
+
+	hostFor := func(addr string) *NmapHost {
+		if host, ok := hosts[addr]; ok {
+			return host
+		}
+		addrType := "ipv4"
+		if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+			addrType = "ipv6"
+		}
+		host := &NmapHost{
+			Status:    NmapStatus{State: "up", Reason: "user-set"},
+			Addresses: []NmapAddress{{Addr: addr, AddrType: addrType}},
+		}
+		hosts[addr] = host
+		order = append(order, addr)
+		return host
+	}
+
+	if discover != nil {
+		start, end = discover.StartTime, discover.EndTime
+		args = append(args, "discover", discover.TargetsInput)
+		for _, result := range discover.Results {
+			if result.Status != "up" {
+				down++
+				continue
+			}
+			host := hostFor(result.Host)
+			host.Status.Reason = discoveryReason(result.Method)
+			if !result.Timestamp.IsZero() {
+				host.StartTime = result.Timestamp.Unix()
+				host.EndTime = result.Timestamp.Unix()
+			}
+			if result.Hostname != "" {
+				host.Hostnames.Hostnames = append(host.Hostnames.Hostnames, NmapHostname{Name: result.Hostname, Type: "PTR"})
+			}
+			if result.RTT > 0 {
+				srtt := int(result.RTT * 1000)
+				host.Times = &NmapTimes{SRTT: srtt, RTTVar: srtt / 2, To: srtt * 4}
+			}
+		}
+	}
+
+	if scan != nil {
+		if start.IsZero() || (!scan.StartTime.IsZero() && scan.StartTime.Before(start)) {
+			start = scan.StartTime
+		}
+		if scan.EndTime.After(end) {
+			end = scan.EndTime
+		}
+		args = append(args, "scan", scan.ScanTypeUsed)
+
+		protocol := "tcp"
+		portSet := make(map[int]bool)
+		extra := make(map[string]map[string]int)
+
+		for _, result := range scan.Results {
+			portSet[result.Port] = true
+			if result.Protocol != "" {
+				protocol = result.Protocol
+			}
+
+			if result.Status != "open" {
+				if extra[result.Host] == nil {
+					extra[result.Host] = make(map[string]int)
+				}
+				extra[result.Host][result.Status]++
+				continue
+			}
+
+			host := hostFor(result.Host)
+			if host.Ports == nil {
+				host.Ports = &NmapPorts{}
+			}
+			port := NmapPort{
+				Protocol: result.Protocol,
+				PortID:   result.Port,
+				State:    NmapState{State: "open", Reason: scanReason(scan.ScanTypeUsed)},
+			}
+			if port.Protocol == "" {
+				port.Protocol = "tcp"
+			}
+			if result.Service != nil && result.Service.Name != "" {
+				port.Service = &NmapService{
+					Name:    result.Service.Name,
+					Version: result.Service.Version,
+					Method:  "probed",
+					Conf:    int(result.Service.Confidence * 10),
+				}
+			} else {
+				port.Service = &NmapService{Name: "unknown", Method: "table", Conf: 3}
+			}
+			host.Ports.Ports = append(host.Ports.Ports, port)
+		}
+
+		// Closed and filtered ports are summarized per host, like nmap's default output
+		for addr, states := range extra {
+			host, ok := hosts[addr]
+			if !ok {
+				continue
+			}
+			if host.Ports == nil {
+				host.Ports = &NmapPorts{}
+			}
+			for _, state := range []string{"closed", "filtered", "error"} {
+				if count := states[state]; count > 0 {
+					host.Ports.ExtraPorts = append(host.Ports.ExtraPorts, NmapExtraPorts{State: state, Count: count})
+				}
+			}
+		}
+
+		ports := make([]int, 0, len(portSet))
+		for port := range portSet {
+			ports = append(ports, port)
+		}
+		sort.Ints(ports)
+		run.ScanInfo = &NmapScanInfo{
+			Type:        nmapScanType(scan.ScanTypeUsed),
+			Protocol:    protocol,
+			NumServices: len(ports),
+			Services:    compressPorts(ports),
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		return compareIPs(order[i], order[j])
+	})
+	for _, addr := range order {
+		host := hosts[addr]
+		if host.Ports != nil {
+			sort.Slice(host.Ports.Ports, func(i, j int) bool {
+				return host.Ports.Ports[i].PortID < host.Ports.Ports[j].PortID
+			})
+		}
+		run.Hosts = append(run.Hosts, *host)
+	}
+
+	run.Args = "netcrate " + strings.TrimSpace(strings.Join(args, " "))
+	run.Start = start.Unix()
+	run.StartStr = start.Format(time.ANSIC)
+	run.RunStats = NmapRunStats{
+		Finished: NmapFinished{
+			Time:    end.Unix(),
+			TimeStr: end.Format(time.ANSIC),
+			Elapsed: end.Sub(start).Seconds(),
+			Exit:    "success",
+		},
+		Hosts: NmapHostStats{Up: len(run.Hosts), Down: down, Total: len(run.Hosts) + down},
+	}
+	run.RunStats.Finished.Summary = fmt.Sprintf("NetCrate done at %s; %d IP addresses (%d hosts up) scanned in %.2f seconds",
+		run.RunStats.Finished.TimeStr, run.RunStats.Hosts.Total, run.RunStats.Hosts.Up, run.RunStats.Finished.Elapsed)
+
+	return run
+}
+
+// NmapRunFromQuick converts a quick mode result, enriching services with
+// fingerprint data and hosts with MAC addresses from device classification
+func NmapRunFromQuick(result *quick.QuickResult) *NmapRun {
+	run := BuildNmapRun(result.DiscoverResult, result.ScanResult)
+	run.Args = fmt.Sprintf("netcrate quick %s", result.TargetCIDR)
+
+	devices := make(map[string]quick.DeviceInfo)
+	for _, device := range result.Summary.Devices {
+		devices[device.Host] = device
+	}
+
+	for i := range run.Hosts {
+		host := &run.Hosts[i]
+		addr := host.Addresses[0].Addr
+
+		if device, ok := devices[addr]; ok {
+			if device.MAC != "" {
+				host.Addresses = append(host.Addresses, NmapAddress{Addr: device.MAC, AddrType: "mac", Vendor: device.Vendor})
+			}
+			if device.MDNSName != "" {
+				host.Hostnames.Hostnames = append(host.Hostnames.Hostnames, NmapHostname{Name: device.MDNSName, Type: "user"})
+			}
+		}
+
+		if host.Ports == nil {
+			continue
+		}
+		for j := range host.Ports.Ports {
+			port := &host.Ports.Ports[j]
+			for _, fp := range result.Fingerprints {
+				if fp == nil || fp.Host != addr || fp.Port != port.PortID || fp.Service == "" || fp.Service == "unknown" {
+					continue
+				}
+				port.Service = &NmapService{
+					Name:    fp.Service,
+					Product: fp.Application,
+					Version: fp.Version,
+					Method:  "probed",
+					Conf:    fp.Confidence / 10,
+				}
+				if fp.TLS != nil {
+					port.Service.Tunnel = "ssl"
+				}
+				break
+			}
+		}
+	}
+
+	return run
+}
+
+// WriteNmapXML writes the document with the standard XML header and doctype
+func WriteNmapXML(w io.Writer, run *NmapRun) error {
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE nmaprun>\n"); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(run); err != nil {
+		return fmt.Errorf("failed to encode nmap XML: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// discoveryReason maps a discovery method to nmap's host status reason
+func discoveryReason(method string) string {
+	switch method {
+	case "icmp", "ping":
+		return "echo-reply"
+	case "tcp":
+		return "syn-ack"
+	case "arp":
+		return "arp-response"
+	default:
+		return "user-set"
+	}
+}
+
+// scanReason maps the scan type to nmap's port state reason
+func scanReason(scanType string) string {
+	if scanType == "udp" {
+		return "udp-response"
+	}
+	return "syn-ack"
+}
+
+// nmapScanType maps NetCrate scan types to nmap scaninfo types
+func nmapScanType(scanType string) string {
+	switch scanType {
+	case "syn":
+		return "syn"
+	case "udp":
+		return "udp"
+	default:
+		return "connect"
+	}
+}
+
+// compressPorts renders a sorted port list in nmap's "1-3,80,443" form
+func compressPorts(ports []int) string {
+	var parts []string
+	for i := 0; i < len(ports); {
+		j := i
+		for j+1 < len(ports) && ports[j+1] == ports[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, fmt.Sprintf("%d", ports[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", ports[i], ports[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// compareIPs orders addresses numerically, falling back to string order
+func compareIPs(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a < b
+	}
+	return strings.Compare(string(ipA.To16()), string(ipB.To16())) < 0
+}
+
+// SaveNmapXML writes the document to path, or to stdout when path is "-"
+func SaveNmapXML(path string, run *NmapRun) error {
+	if path == "-" {
+		return WriteNmapXML(os.Stdout, run)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	return WriteNmapXML(file, run)
+}
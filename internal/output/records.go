@@ -0,0 +1,110 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/quick"
+)
+
+// SaveDiscoverRun stores a host discovery result in ~/.netcrate/runs
+func SaveDiscoverRun(result *ops.DiscoverSummary, targets []string, labels store.Labels, context *store.RunContext) error {
+	return store.Save(discoverRecord(result, targets, labels, context), result)
+}
+
+// SaveEnhancedDiscoverRun stores an enhanced discovery result, keeping the
+// enhancement details alongside the embedded discovery summary
+func SaveEnhancedDiscoverRun(result *ops.EnhancedDiscoverSummary, targets []string, labels store.Labels, context *store.RunContext) error {
+	return store.Save(discoverRecord(result.DiscoverSummary, targets, labels, context), result)
+}
+
+// discoverRecord builds the record envelope for a discovery result
+func discoverRecord(result *ops.DiscoverSummary, targets []string, labels store.Labels, context *store.RunContext) *store.RunRecord {
+	return &store.RunRecord{
+		RunID:     result.RunID,
+		Type:      store.TypeDiscover,
+		Command:   "ops discover",
+		StartTime: result.StartTime,
+		EndTime:   result.EndTime,
+		Duration:  result.Duration,
+		Status:    "complete",
+		Targets:   targets,
+		Counts: map[string]int{
+			"targets": result.TargetsResolved,
+			"hosts":   result.HostsDiscovered,
+		},
+		Labels:  labels,
+		Context: context,
+	}
+}
+
+// SaveScanRun stores a port scan result in ~/.netcrate/runs
+func SaveScanRun(result *ops.ScanSummary, targets []string, labels store.Labels, context *store.RunContext) error {
+	record := &store.RunRecord{
+		RunID:     result.RunID,
+		Type:      store.TypeScan,
+		Command:   "ops scan ports",
+		StartTime: result.StartTime,
+		EndTime:   result.EndTime,
+		Duration:  result.Duration,
+		Status:    "complete",
+		Targets:   targets,
+		Counts: map[string]int{
+			"hosts":          result.Stats.HostsScanned,
+			"open_ports":     result.OpenPorts,
+			"closed_ports":   result.ClosedPorts,
+			"filtered_ports": result.FilteredPorts,
+		},
+		Labels:  labels,
+		Context: context,
+	}
+	return store.Save(record, result)
+}
+
+// SavePacketRun stores a packet send result in ~/.netcrate/runs
+func SavePacketRun(result *ops.PacketSummary, targets []string, labels store.Labels, context *store.RunContext) error {
+	record := &store.RunRecord{
+		RunID:     result.RunID,
+		Type:      store.TypePacket,
+		Command:   "ops packet send",
+		StartTime: result.StartTime,
+		EndTime:   result.EndTime,
+		Duration:  result.Duration,
+		Status:    "complete",
+		Targets:   targets,
+		Counts: map[string]int{
+			"packets":   result.TotalPackets,
+			"responses": result.SuccessfulResponses,
+		},
+		Labels:  labels,
+		Context: context,
+	}
+	return store.Save(record, result)
+}
+
+// NmapRunFromRecord converts any stored run that holds host or port data
+func NmapRunFromRecord(record *store.RunRecord) (*NmapRun, error) {
+	switch record.Type {
+	case store.TypeQuick:
+		var result quick.QuickResult
+		if err := record.Decode(&result); err != nil {
+			return nil, err
+		}
+		return NmapRunFromQuick(&result), nil
+	case store.TypeDiscover:
+		var result ops.DiscoverSummary
+		if err := record.Decode(&result); err != nil {
+			return nil, err
+		}
+		return BuildNmapRun(&result, nil), nil
+	case store.TypeScan:
+		var result ops.ScanSummary
+		if err := record.Decode(&result); err != nil {
+			return nil, err
+		}
+		return BuildNmapRun(nil, &result), nil
+	default:
+		return nil, fmt.Errorf("%s runs cannot be exported as nmap XML", record.Type)
+	}
+}
@@ -0,0 +1,242 @@
+package output
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/output/store"
+)
+
+var (
+	ipv4Pattern = regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`)
+	ipv6Pattern = regexp.MustCompile(`(?i)\b[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}\b`)
+)
+
+// Field names whose values are masked as a whole, by category
+var (
+	hostnameFields = map[string]bool{
+		"hostname": true, "hostnames": true, "mdns_name": true, "common_name": true,
+		"sans": true, "subject": true, "issuer": true, "cert_subject": true, "ssid": true,
+	}
+	bannerFields = map[string]bool{
+		"banner": true, "title": true, "server": true, "headers": true,
+		"host_key": true, "server_version": true, "body_preview": true,
+	}
+	macFields = map[string]bool{"mac": true, "mac_address": true}
+)
+
+// redactor masks one record. Masked addresses are numbered (10.1.2.x#1,
+// 10.1.2.x#2) and hostnames become pseudonyms, both stable within the record,
+// so distinct hosts stay distinct and can be followed across results.
+type redactor struct {
+	profile   config.RedactionProfile
+	salt      []byte
+	addresses map[string]int
+}
+
+// RedactRecord returns a copy of record masked according to profile. Every
+// string in the record, including targets, labels and the run context, is
+// searched for IP addresses; hostnames, banners and MACs are masked by the
+// field that holds them.
+func RedactRecord(record *store.RunRecord, profile config.RedactionProfile) (*store.RunRecord, error) {
+	redacted, err := RedactRecords([]*store.RunRecord{record}, profile)
+	if err != nil {
+		return nil, err
+	}
+	return redacted[0], nil
+}
+
+// RedactRecords masks several records together, so an address or hostname
+// is masked the same way in all of them and the runs can still be compared
+func RedactRecords(records []*store.RunRecord, profile config.RedactionProfile) ([]*store.RunRecord, error) {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redact run: %w", err)
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to redact run: %w", err)
+	}
+
+	r := &redactor{profile: profile, salt: make([]byte, 16)}
+	rand.Read(r.salt)
+	r.numberAddresses(tree)
+	tree = r.walk(tree, nil)
+
+	data, err = json.Marshal(tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redact run: %w", err)
+	}
+	var redacted []*store.RunRecord
+	if err := json.Unmarshal(data, &redacted); err != nil {
+		return nil, fmt.Errorf("failed to redact run: %w", err)
+	}
+	for i := range redacted {
+		redacted[i].FilePath = records[i].FilePath
+	}
+	return redacted, nil
+}
+
+// walk masks v. mask is set below a field that is masked as a whole.
+func (r *redactor) walk(v interface{}, mask func(string) string) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(value))
+		for key, child := range value {
+			childMask := mask
+			if childMask == nil {
+				childMask = r.fieldMask(key)
+			}
+			// Map keys may be addresses too
+			masked[r.maskIPs(key)] = r.walk(child, childMask)
+		}
+		return masked
+	case []interface{}:
+		for i, child := range value {
+			value[i] = r.walk(child, mask)
+		}
+		return value
+	case string:
+		if mask != nil {
+			return mask(value)
+		}
+		return r.maskIPs(value)
+	}
+	return v
+}
+
+// fieldMask returns the masker for a field name, or nil
+func (r *redactor) fieldMask(field string) func(string) string {
+	switch {
+	case r.profile.Hostnames && hostnameFields[field]:
+		return r.pseudonym
+	case r.profile.Banners && bannerFields[field]:
+		return func(s string) string {
+			if s == "" {
+				return s
+			}
+			return "[redacted]"
+		}
+	case r.profile.MACs && macFields[field]:
+		return maskMAC
+	}
+	return nil
+}
+
+// pseudonym replaces a name with a salted hash, e.g. "host-3f9a1c"
+func (r *redactor) pseudonym(name string) string {
+	if name == "" {
+		return name
+	}
+	sum := sha256.Sum256(append(r.salt, strings.ToLower(name)...))
+	return "host-" + hex.EncodeToString(sum[:3])
+}
+
+// maskIPs masks the trailing octets of IPv4 addresses and the interface ID
+// of IPv6 addresses found in s
+func (r *redactor) maskIPs(s string) string {
+	octets := r.profile.IPOctets
+	if octets <= 0 {
+		return s
+	}
+
+	s = ipv4Pattern.ReplaceAllStringFunc(s, func(match string) string {
+		ip := net.ParseIP(match)
+		if ip == nil || ip.To4() == nil {
+			return match // e.g. a version number like 1.2.300.4
+		}
+		parts := strings.Split(match, ".")
+		for i := len(parts) - octets; i < len(parts); i++ {
+			if i >= 0 {
+				parts[i] = "x"
+			}
+		}
+		return strings.Join(parts, ".") + r.addressNumber(ip)
+	})
+
+	return ipv6Pattern.ReplaceAllStringFunc(s, func(match string) string {
+		ip := net.ParseIP(match)
+		if ip == nil || ip.To4() != nil {
+			return match // times like 10:00:00 and MACs do not parse
+		}
+		number := r.addressNumber(ip)
+		ip = ip.To16()
+		return fmt.Sprintf("%x:%x:%x:%x:x:x:x:x",
+			uint16(ip[0])<<8|uint16(ip[1]), uint16(ip[2])<<8|uint16(ip[3]),
+			uint16(ip[4])<<8|uint16(ip[5]), uint16(ip[6])<<8|uint16(ip[7])) + number
+	})
+}
+
+// numberAddresses numbers every address in tree in address order, so the
+// numbering does not depend on where an address first appears
+func (r *redactor) numberAddresses(tree interface{}) {
+	seen := make(map[string]net.IP)
+	var collect func(v interface{})
+	addString := func(s string) {
+		for _, pattern := range []*regexp.Regexp{ipv4Pattern, ipv6Pattern} {
+			for _, match := range pattern.FindAllString(s, -1) {
+				if ip := net.ParseIP(match); ip != nil {
+					seen[ip.String()] = ip.To16()
+				}
+			}
+		}
+	}
+	collect = func(v interface{}) {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			for key, child := range value {
+				addString(key)
+				collect(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				collect(child)
+			}
+		case string:
+			addString(value)
+		}
+	}
+	collect(tree)
+
+	ips := make([]net.IP, 0, len(seen))
+	for _, ip := range seen {
+		ips = append(ips, ip)
+	}
+	sort.Slice(ips, func(i, j int) bool { return bytes.Compare(ips[i], ips[j]) < 0 })
+
+	r.addresses = make(map[string]int, len(ips))
+	for i, ip := range ips {
+		r.addresses[ip.String()] = i + 1
+	}
+}
+
+// addressNumber returns the suffix that tells a masked address apart. The
+// "#" keeps it from reading as part of the last octet.
+func (r *redactor) addressNumber(ip net.IP) string {
+	return fmt.Sprintf("#%d", r.addresses[ip.String()])
+}
+
+// maskMAC keeps the vendor prefix (OUI) of a MAC address
+func maskMAC(mac string) string {
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) < 3 {
+		if mac == "" {
+			return mac
+		}
+		return "[redacted]"
+	}
+	masked := hw[:3].String()
+	for i := 3; i < len(hw); i++ {
+		masked += ":xx"
+	}
+	return masked
+}
@@ -0,0 +1,188 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/output/store"
+)
+
+type redactedHost struct {
+	Host     string `json:"host"`
+	Hostname string `json:"hostname"`
+	Banner   string `json:"banner"`
+	MAC      string `json:"mac"`
+	Version  string `json:"version"`
+}
+
+func redactTestRecord(t *testing.T) *store.RunRecord {
+	t.Helper()
+	result, err := json.Marshal(map[string]interface{}{
+		"results": []map[string]string{
+			{"host": "192.168.1.10", "hostname": "nas.lan", "banner": "SSH-2.0-OpenSSH_9.6", "mac": "aa:bb:cc:dd:ee:ff", "version": "1.2.300.4"},
+			{"host": "192.168.1.2"},
+			{"host": "fe80::1:2:3:4"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &store.RunRecord{RunID: "scan_1", Type: store.TypeScan, Targets: []string{"192.168.1.0/24"}, Result: result}
+}
+
+func redactedHosts(t *testing.T, record *store.RunRecord) []redactedHost {
+	t.Helper()
+	var result struct {
+		Results []redactedHost `json:"results"`
+	}
+	if err := record.Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	return result.Results
+}
+
+func TestRedactAddresses(t *testing.T) {
+	// Addresses are numbered in address order: 192.168.1.0, .2, .10, fe80::
+	tests := []struct {
+		name    string
+		octets  int
+		targets string
+		hosts   []string
+	}{
+		{"light", 1, "192.168.1.x#1/24", []string{"192.168.1.x#3", "192.168.1.x#2", "fe80:0:0:0:x:x:x:x#4"}},
+		{"strict", 2, "192.168.x.x#1/24", []string{"192.168.x.x#3", "192.168.x.x#2", "fe80:0:0:0:x:x:x:x#4"}},
+		{"all octets", 4, "x.x.x.x#1/24", []string{"x.x.x.x#3", "x.x.x.x#2", "fe80:0:0:0:x:x:x:x#4"}},
+		{"none", 0, "192.168.1.0/24", []string{"192.168.1.10", "192.168.1.2", "fe80::1:2:3:4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted, err := RedactRecord(redactTestRecord(t), config.RedactionProfile{IPOctets: tt.octets})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(redacted.Targets, ","); got != tt.targets {
+				t.Errorf("targets = %s, want %s", got, tt.targets)
+			}
+			hosts := redactedHosts(t, redacted)
+			for i, want := range tt.hosts {
+				if hosts[i].Host != want {
+					t.Errorf("host %d = %s, want %s", i, hosts[i].Host, want)
+				}
+			}
+			// Version numbers that are not addresses are left alone
+			if hosts[0].Version != "1.2.300.4" {
+				t.Errorf("version = %s, want 1.2.300.4", hosts[0].Version)
+			}
+		})
+	}
+}
+
+func TestRedactFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  config.RedactionProfile
+		hostname func(string) bool
+		banner   string
+		mac      string
+	}{
+		{
+			name:     "addresses only",
+			profile:  config.RedactionProfile{IPOctets: 1},
+			hostname: func(s string) bool { return s == "nas.lan" },
+			banner:   "SSH-2.0-OpenSSH_9.6",
+			mac:      "aa:bb:cc:dd:ee:ff",
+		},
+		{
+			name:     "hostnames, banners and MACs",
+			profile:  config.RedactionProfile{IPOctets: 1, Hostnames: true, Banners: true, MACs: true},
+			hostname: func(s string) bool { return strings.HasPrefix(s, "host-") && len(s) == len("host-")+6 },
+			banner:   "[redacted]",
+			mac:      "aa:bb:cc:xx:xx:xx",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted, err := RedactRecord(redactTestRecord(t), tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			host := redactedHosts(t, redacted)[0]
+			if !tt.hostname(host.Hostname) {
+				t.Errorf("hostname = %s", host.Hostname)
+			}
+			if host.Banner != tt.banner {
+				t.Errorf("banner = %s, want %s", host.Banner, tt.banner)
+			}
+			if host.MAC != tt.mac {
+				t.Errorf("mac = %s, want %s", host.MAC, tt.mac)
+			}
+		})
+	}
+}
+
+func TestRedactRecordsMasksAlike(t *testing.T) {
+	profile := config.RedactionProfile{IPOctets: 1, Hostnames: true}
+	redacted, err := RedactRecords([]*store.RunRecord{redactTestRecord(t), redactTestRecord(t)}, profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := redactedHosts(t, redacted[0]), redactedHosts(t, redacted[1])
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("host %d masked as %+v and %+v", i, first[i], second[i])
+		}
+	}
+}
+
+func TestRedactionProfiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		profile  string
+		host     string
+		hostname bool // masked
+		banner   string
+		mac      string
+	}{
+		{"light", "192.168.1.x#3", false, "SSH-2.0-OpenSSH_9.6", "aa:bb:cc:dd:ee:ff"},
+		{"standard", "192.168.1.x#3", true, "[redacted]", "aa:bb:cc:xx:xx:xx"},
+		{"strict", "192.168.x.x#3", true, "[redacted]", "aa:bb:cc:xx:xx:xx"},
+		// The custom profile is empty until configured
+		{"custom", "192.168.1.10", false, "SSH-2.0-OpenSSH_9.6", "aa:bb:cc:dd:ee:ff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			profile, err := cm.GetRedactionProfile(tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			redacted, err := RedactRecord(redactTestRecord(t), profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			host := redactedHosts(t, redacted)[0]
+			if host.Host != tt.host {
+				t.Errorf("host = %s, want %s", host.Host, tt.host)
+			}
+			if masked := host.Hostname != "nas.lan"; masked != tt.hostname {
+				t.Errorf("hostname = %s", host.Hostname)
+			}
+			if host.Banner != tt.banner {
+				t.Errorf("banner = %s, want %s", host.Banner, tt.banner)
+			}
+			if host.MAC != tt.mac {
+				t.Errorf("mac = %s, want %s", host.MAC, tt.mac)
+			}
+		})
+	}
+
+	if _, err := cm.GetRedactionProfile("everything"); err == nil {
+		t.Error("GetRedactionProfile(everything) succeeded, want an error")
+	}
+}
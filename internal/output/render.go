@@ -0,0 +1,208 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/netcrate/netcrate/internal/i18n"
+	"golang.org/x/term"
+)
+
+// MaxCellWidth is the widest a cell is printed, in characters, unless
+// truncation is turned off
+const MaxCellWidth = 40
+
+// defaultPager is run when $PAGER is unset: quit if the output fits on one
+// screen, keep colors and leave the output on the screen afterwards
+const defaultPager = "less -FRX"
+
+// TableOptions control how a table is printed in the terminal
+type TableOptions struct {
+	Columns    []string // columns to print, in order; empty for the table's defaults
+	Sort       []string // columns to sort by; a leading '-' sorts descending
+	NoTruncate bool     // print long cells in full instead of cutting them at MaxCellWidth
+	NoPager    bool     // never page, even when the output is taller than the terminal
+}
+
+// View returns the table with the columns and row order of opts. Unlike
+// Select it keeps every row, so it only changes what is printed.
+func (t *Table) View(opts TableOptions) (*Table, error) {
+	view := &Table{Columns: t.Columns, Headers: t.Headers, Rows: t.Rows}
+	if len(opts.Columns) > 0 {
+		for _, column := range opts.Columns {
+			if !containsString(t.Columns, column) {
+				return nil, fmt.Errorf("unknown column %q (available: %s)", column, strings.Join(t.Columns, ", "))
+			}
+		}
+		view.Columns = opts.Columns
+	}
+
+	if len(opts.Sort) > 0 {
+		type key struct {
+			column     string
+			descending bool
+		}
+		keys := make([]key, 0, len(opts.Sort))
+		for _, s := range opts.Sort {
+			k := key{column: strings.TrimPrefix(s, "-"), descending: strings.HasPrefix(s, "-")}
+			if !containsString(t.Columns, k.column) {
+				return nil, fmt.Errorf("unknown sort column %q (available: %s)", k.column, strings.Join(t.Columns, ", "))
+			}
+			keys = append(keys, k)
+		}
+
+		view.Rows = append([]map[string]string(nil), t.Rows...)
+		sort.SliceStable(view.Rows, func(i, j int) bool {
+			for _, k := range keys {
+				c := compareCells(view.Rows[i][k.column], view.Rows[j][k.column])
+				if c == 0 {
+					continue
+				}
+				if k.descending {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+	return view, nil
+}
+
+// compareCells orders numbers numerically, addresses by their bytes and
+// everything else as text. Empty cells sort first.
+func compareCells(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if x, errA := strconv.ParseFloat(a, 64); errA == nil {
+		if y, errB := strconv.ParseFloat(b, 64); errB == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if net.ParseIP(a) != nil && net.ParseIP(b) != nil {
+		if compareIPs(a, b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// WriteTable writes the table as aligned columns under a header
+func WriteTable(w io.Writer, t *Table, opts TableOptions) {
+	if len(t.Rows) == 0 {
+		fmt.Fprintln(w, i18n.T("output.table.empty"))
+		return
+	}
+
+	cell := func(value string) string {
+		if opts.NoTruncate || utf8.RuneCountInString(value) <= MaxCellWidth {
+			return value
+		}
+		return string([]rune(value)[:MaxCellWidth-3]) + "..."
+	}
+
+	headers := make([]string, len(t.Columns))
+	widths := make([]int, len(t.Columns))
+	for i, column := range t.Columns {
+		headers[i] = column
+		if label, ok := t.Headers[column]; ok {
+			headers[i] = label
+		}
+		widths[i] = utf8.RuneCountInString(headers[i])
+		for _, row := range t.Rows {
+			if n := utf8.RuneCountInString(cell(row[column])); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	printRow := func(value func(i int) string) {
+		cells := make([]string, len(t.Columns))
+		for i := range t.Columns {
+			v := value(i)
+			cells[i] = v + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(v))
+		}
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+
+	printRow(func(i int) string { return headers[i] })
+	total := 0
+	for _, width := range widths {
+		total += width + 2
+	}
+	fmt.Fprintln(w, strings.Repeat("-", total-2))
+	for _, row := range t.Rows {
+		printRow(func(i int) string { return cell(row[t.Columns[i]]) })
+	}
+}
+
+// PrintTable displays the table as aligned columns with a row count, paging
+// it when it is taller than the terminal
+func PrintTable(t *Table, opts TableOptions) error {
+	view, err := t.View(opts)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	WriteTable(&buf, view, opts)
+	if len(view.Rows) > 0 {
+		fmt.Fprint(&buf, i18n.T("output.table.rows", len(view.Rows)))
+	}
+	return Page(buf.Bytes(), !opts.NoPager)
+}
+
+// Page writes content to stdout. When paging is enabled, stdout is a
+// terminal and content has more lines than fit on it, content goes through
+// $PAGER (less -FRX when unset; set PAGER= to turn paging off) instead.
+func Page(content []byte, enabled bool) error {
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		pager = defaultPager
+	}
+	args := strings.Fields(pager)
+	if !enabled || len(args) == 0 || !tallerThanTerminal(content) {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		// No pager installed: print as if paging were off
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+	return cmd.Wait()
+}
+
+// tallerThanTerminal reports whether stdout is a terminal with fewer rows
+// than content has lines
+func tallerThanTerminal(content []byte) bool {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return false
+	}
+	_, height, err := term.GetSize(fd)
+	if err != nil || height <= 0 {
+		return false
+	}
+	return bytes.Count(content, []byte("\n")) >= height
+}
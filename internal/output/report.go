@@ -0,0 +1,170 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/quick"
+	"github.com/netcrate/netcrate/internal/reports"
+)
+
+// ReportResult maps a saved run onto the execution result the HTML reporter
+// renders. Each stage of the run (discovery, port scan, fingerprinting,
+// packet send) becomes a step; discovery and scan runs also carry their
+// hosts, services, risk and certificate findings.
+func ReportResult(record *store.RunRecord) (*reports.ExecutionResult, error) {
+	result := &reports.ExecutionResult{
+		SessionID:    record.RunID,
+		TemplateName: record.Command,
+		StartTime:    record.StartTime,
+		EndTime:      record.EndTime,
+		Duration:     secondsDuration(record.Duration),
+		Status:       reportStatus(record.Status),
+		Parameters:   map[string]interface{}{"type": record.Type},
+		StepResults:  make(map[string]*reports.StepResultData),
+		ResultPath:   record.FilePath,
+		Tags:         record.Tags,
+	}
+	if len(record.Targets) > 0 {
+		result.Parameters["targets"] = record.Targets
+	}
+	if record.Name != "" {
+		result.Parameters["name"] = record.Name
+	}
+	if record.Notes != "" {
+		result.Parameters["notes"] = record.Notes
+	}
+	if record.Context != nil && record.Context.Version != "" {
+		result.Parameters["netcrate_version"] = record.Context.Version
+	}
+	if record.Context != nil && record.Context.Authorization != nil {
+		if authorizedBy := record.Context.Authorization.AuthorizedBy; authorizedBy != "" {
+			result.Parameters["authorized_by"] = authorizedBy
+		}
+		if ticket := record.Context.Authorization.Ticket; ticket != "" {
+			result.Parameters["ticket"] = ticket
+		}
+	}
+
+	switch record.Type {
+	case store.TypeQuick:
+		var run quick.QuickResult
+		if err := record.Decode(&run); err != nil {
+			return nil, err
+		}
+		addDiscoverStep(result, run.DiscoverResult)
+		if run.ScanResult != nil {
+			addScanStep(result, run.ScanResult)
+		} else {
+			addStep(result, &reports.StepResultData{
+				Name:      "scan",
+				Status:    "skipped",
+				StartTime: record.EndTime,
+				EndTime:   record.EndTime,
+				Message:   "Port scan did not run",
+			})
+		}
+		if len(run.Fingerprints) > 0 {
+			addStep(result, &reports.StepResultData{
+				Name:      "fingerprint",
+				Status:    "completed",
+				StartTime: record.EndTime,
+				EndTime:   record.EndTime,
+				Message:   fmt.Sprintf("%d services fingerprinted", len(run.Fingerprints)),
+			})
+		}
+		result.Scan = buildScanReport(run.DiscoverResult, run.ScanResult, run.Fingerprints, run.Summary.Devices)
+	case store.TypeDiscover:
+		var run ops.DiscoverSummary
+		if err := record.Decode(&run); err != nil {
+			return nil, err
+		}
+		addDiscoverStep(result, &run)
+		result.Scan = buildScanReport(&run, nil, nil, nil)
+	case store.TypeScan:
+		var run ops.ScanSummary
+		if err := record.Decode(&run); err != nil {
+			return nil, err
+		}
+		addScanStep(result, &run)
+		result.Scan = buildScanReport(nil, &run, nil, nil)
+	case store.TypePacket:
+		var run ops.PacketSummary
+		if err := record.Decode(&run); err != nil {
+			return nil, err
+		}
+		status := "completed"
+		if run.SuccessfulResponses == 0 {
+			status = "failed"
+		}
+		addStep(result, &reports.StepResultData{
+			Name:      "packet",
+			Status:    status,
+			StartTime: run.StartTime,
+			EndTime:   run.EndTime,
+			Duration:  secondsDuration(run.Duration),
+			Message: fmt.Sprintf("%d of %d packets answered (template %s)",
+				run.SuccessfulResponses, run.TotalPackets, run.TemplateUsed),
+		})
+	default:
+		return nil, fmt.Errorf("%s runs cannot be reported", record.Type)
+	}
+
+	return result, nil
+}
+
+func addDiscoverStep(result *reports.ExecutionResult, run *ops.DiscoverSummary) {
+	if run == nil {
+		return
+	}
+	addStep(result, &reports.StepResultData{
+		Name:      "discover",
+		Status:    "completed",
+		StartTime: run.StartTime,
+		EndTime:   run.EndTime,
+		Duration:  secondsDuration(run.Duration),
+		Message:   fmt.Sprintf("%d of %d hosts up", run.HostsDiscovered, run.TargetsResolved),
+	})
+}
+
+func addScanStep(result *reports.ExecutionResult, run *ops.ScanSummary) {
+	addStep(result, &reports.StepResultData{
+		Name:      "scan",
+		Status:    "completed",
+		StartTime: run.StartTime,
+		EndTime:   run.EndTime,
+		Duration:  secondsDuration(run.Duration),
+		Message: fmt.Sprintf("%d open, %d closed, %d filtered ports on %d hosts",
+			run.OpenPorts, run.ClosedPorts, run.FilteredPorts, run.TargetsCount),
+	})
+}
+
+// addStep records a step and keeps the step counters in line
+func addStep(result *reports.ExecutionResult, step *reports.StepResultData) {
+	result.StepResults[step.Name] = step
+	result.TotalSteps++
+	switch step.Status {
+	case "completed":
+		result.CompletedSteps++
+	case "failed":
+		result.FailedSteps++
+		result.ErrorCount++
+	case "skipped":
+		result.SkippedSteps++
+	}
+}
+
+// reportStatus maps a run status onto the reporter's step vocabulary
+func reportStatus(status string) string {
+	if status == "complete" || status == "" {
+		return "completed"
+	}
+	return status
+}
+
+// secondsDuration renders fractional seconds as a Go duration string
+func secondsDuration(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
@@ -0,0 +1,141 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/reports"
+	"github.com/netcrate/netcrate/internal/risk"
+)
+
+// DiffReportResult reports the target run together with what changed since
+// the base run: hosts that appeared or went away, ports that opened or
+// closed and services whose product or version changed.
+func DiffReportResult(base, target *store.RunRecord) (*reports.ExecutionResult, error) {
+	before, err := ReportResult(base)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ReportResult(target)
+	if err != nil {
+		return nil, err
+	}
+	if before.Scan == nil || result.Scan == nil {
+		return nil, fmt.Errorf("only discover, scan and quick runs can be compared")
+	}
+
+	result.Parameters["compared_to"] = base.RunID
+	result.Diff = diffScanReports(before.Scan, result.Scan)
+	result.Diff.BaseRunID = base.RunID
+	result.Diff.BaseTime = base.StartTime
+	return result, nil
+}
+
+// diffScanReports compares the hosts and open ports of two runs. Ports are
+// only compared when both runs scanned them, so a discovery run compared
+// with a scan does not report every port as newly opened.
+func diffScanReports(before, after *reports.ScanReport) *reports.DiffReport {
+	diff := &reports.DiffReport{PortsCompared: before.Scanned && after.Scanned}
+
+	beforeHosts := make(map[string]reports.HostReport, len(before.Hosts))
+	for _, h := range before.Hosts {
+		beforeHosts[h.Host] = h
+	}
+	afterHosts := make(map[string]reports.HostReport, len(after.Hosts))
+	for _, h := range after.Hosts {
+		afterHosts[h.Host] = h
+		if _, ok := beforeHosts[h.Host]; !ok {
+			diff.AddedHosts = append(diff.AddedHosts, h)
+		}
+	}
+	for _, h := range before.Hosts {
+		if _, ok := afterHosts[h.Host]; !ok {
+			diff.RemovedHosts = append(diff.RemovedHosts, h)
+		}
+	}
+
+	if !diff.PortsCompared {
+		return diff
+	}
+
+	beforePorts := portsByKey(before.Hosts)
+	afterPorts := portsByKey(after.Hosts)
+	for key, port := range afterPorts {
+		previous, ok := beforePorts[key]
+		change := reports.PortChange{
+			Host:     key.host,
+			Port:     port.Port,
+			Protocol: port.Protocol,
+			After:    serviceLabel(port),
+			Severity: port.Risk,
+		}
+		switch {
+		case !ok:
+			diff.OpenedPorts = append(diff.OpenedPorts, change)
+		case serviceLabel(previous) != change.After:
+			change.Before = serviceLabel(previous)
+			diff.ChangedServices = append(diff.ChangedServices, change)
+		}
+	}
+	for key, port := range beforePorts {
+		if _, ok := afterPorts[key]; !ok {
+			diff.ClosedPorts = append(diff.ClosedPorts, reports.PortChange{
+				Host:     key.host,
+				Port:     port.Port,
+				Protocol: port.Protocol,
+				Before:   serviceLabel(port),
+				Severity: port.Risk,
+			})
+		}
+	}
+
+	sortPortChanges(diff.OpenedPorts)
+	sortPortChanges(diff.ClosedPorts)
+	sortPortChanges(diff.ChangedServices)
+	return diff
+}
+
+// portKey identifies an open port across runs
+type portKey struct {
+	host     string
+	port     int
+	protocol string
+}
+
+func portsByKey(hosts []reports.HostReport) map[portKey]reports.PortReport {
+	ports := make(map[portKey]reports.PortReport)
+	for _, h := range hosts {
+		for _, port := range h.OpenPorts {
+			ports[portKey{h.Host, port.Port, port.Protocol}] = port
+		}
+	}
+	return ports
+}
+
+// serviceLabel describes a port's service, e.g. "ssh OpenSSH 8.9p1"
+func serviceLabel(port reports.PortReport) string {
+	parts := []string{port.Service}
+	if port.Product != "" {
+		parts = append(parts, port.Product)
+	}
+	if port.Version != "" {
+		parts = append(parts, port.Version)
+	}
+	return strings.Join(parts, " ")
+}
+
+// sortPortChanges orders changes most severe first, then by host and port
+func sortPortChanges(changes []reports.PortChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if c := risk.CompareSeverity(a.Severity, b.Severity); c != 0 {
+			return c > 0
+		}
+		if a.Host != b.Host {
+			return compareIPs(a.Host, b.Host)
+		}
+		return a.Port < b.Port
+	})
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/reports"
+)
+
+// DefaultHostHistory is how many earlier runs the host detail view compares
+// against by default
+const DefaultHostHistory = 3
+
+// AddHostHistory compares every host of a report with up to limit earlier
+// discover, scan and quick runs, most recent first, so the detail view of a
+// host shows whether it was seen before and which of its ports changed.
+// Runs that cannot be read are left out.
+func AddHostHistory(result *reports.ExecutionResult, record *store.RunRecord, limit int) error {
+	if result.Scan == nil || limit <= 0 {
+		return nil
+	}
+
+	runs, err := ListRuns()
+	if err != nil {
+		return fmt.Errorf("failed to list earlier runs: %w", err)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].StartTime.After(runs[j].StartTime) })
+
+	for i := range runs {
+		run := &runs[i]
+		if len(result.Scan.Hosts) == 0 || limit == 0 {
+			break
+		}
+		if run.RunID == record.RunID || !run.StartTime.Before(record.StartTime) {
+			continue
+		}
+		switch run.Type {
+		case store.TypeQuick, store.TypeDiscover, store.TypeScan:
+		default:
+			continue
+		}
+
+		earlier, err := LoadRecord(run)
+		if err != nil {
+			continue
+		}
+		before, err := ReportResult(earlier)
+		if err != nil || before.Scan == nil {
+			continue
+		}
+		addSightings(result.Scan, before.Scan, earlier)
+		limit--
+	}
+	return nil
+}
+
+// addSightings records how an earlier run saw each host of a report
+func addSightings(scan, before *reports.ScanReport, earlier *store.RunRecord) {
+	diff := diffScanReports(before, scan)
+
+	seen := make(map[string]bool, len(before.Hosts))
+	for _, h := range before.Hosts {
+		seen[h.Host] = true
+	}
+	opened := portChangesByHost(diff.OpenedPorts)
+	closed := portChangesByHost(diff.ClosedPorts)
+	changed := portChangesByHost(diff.ChangedServices)
+
+	for i := range scan.Hosts {
+		h := &scan.Hosts[i]
+		sighting := reports.HostSighting{
+			RunID:         earlier.RunID,
+			StartTime:     earlier.StartTime,
+			Seen:          seen[h.Host],
+			PortsCompared: diff.PortsCompared,
+		}
+		if sighting.Seen {
+			sighting.Opened = opened[h.Host]
+			sighting.Closed = closed[h.Host]
+			sighting.Changed = changed[h.Host]
+		}
+		h.History = append(h.History, sighting)
+	}
+}
+
+// portChangesByHost groups port changes by host as "port/protocol" labels,
+// with the service change for changed services
+func portChangesByHost(changes []reports.PortChange) map[string][]string {
+	byHost := make(map[string][]string)
+	for _, change := range changes {
+		entry := fmt.Sprintf("%d/%s", change.Port, change.Protocol)
+		if change.Before != "" && change.After != "" {
+			entry += fmt.Sprintf(" (%s → %s)", change.Before, change.After)
+		}
+		byHost[change.Host] = append(byHost[change.Host], entry)
+	}
+	return byHost
+}
@@ -0,0 +1,344 @@
+package output
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/quick"
+	"github.com/netcrate/netcrate/internal/reports"
+	"github.com/netcrate/netcrate/internal/risk"
+	"github.com/netcrate/netcrate/internal/services"
+)
+
+// maxReportServices caps the top services chart
+const maxReportServices = 10
+
+// certExpiryWarning is how close to expiry a certificate is flagged
+const certExpiryWarning = 30 * 24 * time.Hour
+
+// severityPalette lists severities most severe first, with chart colors
+var severityPalette = []struct{ severity, color string }{
+	{"critical", "#8b0000"},
+	{"high", "#dc3545"},
+	{"medium", "#fd7e14"},
+	{"low", "#28a745"},
+}
+
+// buildScanReport gathers the network findings of a run for the report.
+// Any argument may be nil or empty.
+func buildScanReport(discover *ops.DiscoverSummary, scan *ops.ScanSummary,
+	fingerprints []*services.ProtocolFingerprint, devices []quick.DeviceInfo) *reports.ScanReport {
+
+	hosts := make(map[string]*reports.HostReport)
+	host := func(address string) *reports.HostReport {
+		h, ok := hosts[address]
+		if !ok {
+			h = &reports.HostReport{Host: address}
+			hosts[address] = h
+		}
+		return h
+	}
+
+	if discover != nil {
+		for _, r := range discover.Results {
+			if r.Status != "up" {
+				continue
+			}
+			h := host(r.Host)
+			h.Hostname = r.Hostname
+			if mac, ok := r.Details["mac"].(string); ok {
+				h.MAC = mac
+			}
+		}
+	}
+	for _, device := range devices {
+		h := host(device.Host)
+		if device.MAC != "" {
+			h.MAC = device.MAC
+		}
+		if h.Hostname == "" {
+			h.Hostname = device.Hostname
+		}
+		h.Vendor = device.Vendor
+		h.Category = device.Category
+	}
+
+	products := make(map[string]*services.ProtocolFingerprint)
+	for _, fp := range fingerprints {
+		if fp != nil {
+			products[fmt.Sprintf("%s:%d", fp.Host, fp.Port)] = fp
+		}
+	}
+
+	report := &reports.ScanReport{}
+	riskEngine := quick.LoadRiskEngine()
+	severityCounts := make(map[string]int)
+	serviceCounts := make(map[string]int)
+
+	if scan != nil {
+		report.Scanned = true
+		for _, r := range scan.Results {
+			if r.Status != "open" {
+				continue
+			}
+			port := reports.PortReport{Port: r.Port, Protocol: r.Protocol}
+			if port.Protocol == "" {
+				port.Protocol = "tcp"
+			}
+			if r.Service != nil {
+				port.Service = r.Service.Name
+				port.Version = r.Service.Version
+				port.Banner = r.Service.Banner
+			}
+			if fp, ok := products[fmt.Sprintf("%s:%d", r.Host, r.Port)]; ok {
+				if fp.Service != "" && fp.Service != "unknown" {
+					port.Service = fp.Service
+				}
+				port.Product = fp.Application
+				if fp.Version != "" {
+					port.Version = fp.Version
+				}
+				port.Details = fingerprintDetails(fp)
+			}
+			if port.Service == "" {
+				port.Service = "unknown"
+			}
+
+			assessment := riskEngine.Evaluate(risk.Finding{
+				Host:    r.Host,
+				Port:    r.Port,
+				Service: port.Service,
+				Version: port.Version,
+			})
+			port.Risk = assessment.Severity
+			severityCounts[port.Risk]++
+			serviceCounts[port.Service]++
+			if risk.CompareSeverity(port.Risk, "low") > 0 {
+				report.RiskFindings = append(report.RiskFindings, reports.RiskFinding{
+					Host:      r.Host,
+					Port:      r.Port,
+					Service:   port.Service,
+					Severity:  port.Risk,
+					Rationale: assessment.Rationale,
+					Rule:      assessment.Rule,
+				})
+			}
+
+			h := host(r.Host)
+			h.OpenPorts = append(h.OpenPorts, port)
+			if h.Risk == "" || risk.CompareSeverity(port.Risk, h.Risk) > 0 {
+				h.Risk = port.Risk
+			}
+			report.OpenPorts++
+		}
+	}
+
+	for _, h := range hosts {
+		sort.Slice(h.OpenPorts, func(i, j int) bool { return h.OpenPorts[i].Port < h.OpenPorts[j].Port })
+		report.Hosts = append(report.Hosts, *h)
+	}
+	sort.Slice(report.Hosts, func(i, j int) bool {
+		return compareIPs(report.Hosts[i].Host, report.Hosts[j].Host)
+	})
+	report.HostsUp = len(report.Hosts)
+
+	sort.Slice(report.RiskFindings, func(i, j int) bool {
+		a, b := report.RiskFindings[i], report.RiskFindings[j]
+		if c := risk.CompareSeverity(a.Severity, b.Severity); c != 0 {
+			return c > 0
+		}
+		if a.Host != b.Host {
+			return compareIPs(a.Host, b.Host)
+		}
+		return a.Port < b.Port
+	})
+
+	for _, level := range severityPalette {
+		if n := severityCounts[level.severity]; n > 0 {
+			report.Risk = append(report.Risk, reports.ChartPoint{Label: level.severity, Value: float64(n), Color: level.color})
+		}
+	}
+
+	report.TopServices = topServices(serviceCounts, maxReportServices)
+	report.TLS = tlsFindings(fingerprints)
+	report.Subnets = subnetSummaries(report.Hosts)
+
+	return report
+}
+
+// fingerprintDetails flattens what the fingerprinter learned about a service
+// into labelled values for the host detail view
+func fingerprintDetails(fp *services.ProtocolFingerprint) map[string]string {
+	details := make(map[string]string)
+	set := func(key, value string) {
+		if value != "" {
+			details[key] = value
+		}
+	}
+
+	if fp.HTTP != nil {
+		set("http.status", fp.HTTP.Status)
+		set("http.server", fp.HTTP.Server)
+		set("http.title", fp.HTTP.Title)
+		set("http.technologies", strings.Join(fp.HTTP.Technologies, ", "))
+		set("http.redirect", fp.HTTP.RedirectURL)
+	}
+	if fp.TLS != nil {
+		set("tls.version", fp.TLS.Version)
+		set("tls.cipher", fp.TLS.CipherSuite)
+		if cert := fp.TLS.Certificate; cert != nil {
+			set("tls.common_name", cert.CommonName)
+			set("tls.sans", strings.Join(cert.SANs, ", "))
+			set("tls.fingerprint", cert.Fingerprint)
+		}
+	}
+	if fp.SSH != nil {
+		set("ssh.version", fp.SSH.Version)
+		set("ssh.implementation", fp.SSH.Implementation)
+		set("ssh.host_key", fp.SSH.HostKey)
+	}
+	if fp.MySQL != nil {
+		set("mysql.server_version", fp.MySQL.ServerVersion)
+		set("mysql.auth_method", fp.MySQL.AuthMethod)
+	}
+	for key, value := range fp.Metadata {
+		set(key, value)
+	}
+	if fp.Confidence > 0 {
+		details["confidence"] = fmt.Sprintf("%d%%", fp.Confidence)
+	}
+
+	if len(details) == 0 {
+		return nil
+	}
+	return details
+}
+
+// topServices returns the most common services as chart points
+func topServices(counts map[string]int, limit int) []reports.ChartPoint {
+	var points []reports.ChartPoint
+	for service, n := range counts {
+		points = append(points, reports.ChartPoint{Label: service, Value: float64(n), Color: "#3498db"})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Value != points[j].Value {
+			return points[i].Value > points[j].Value
+		}
+		return points[i].Label < points[j].Label
+	})
+	if limit > 0 && len(points) > limit {
+		points = points[:limit]
+	}
+	return points
+}
+
+// tlsFindings lists the certificates seen by the fingerprinter, flagging
+// expired, soon to expire and self-signed ones and outdated protocols
+func tlsFindings(fingerprints []*services.ProtocolFingerprint) []reports.TLSFinding {
+	var findings []reports.TLSFinding
+	now := time.Now()
+
+	for _, fp := range fingerprints {
+		if fp == nil || fp.TLS == nil {
+			continue
+		}
+		finding := reports.TLSFinding{
+			Host:    fp.Host,
+			Port:    fp.Port,
+			Version: fp.TLS.Version,
+			Cipher:  fp.TLS.CipherSuite,
+		}
+		switch fp.TLS.Version {
+		case "SSL 3.0", "TLS 1.0", "TLS 1.1":
+			finding.Issues = append(finding.Issues, "outdated protocol")
+		}
+
+		if cert := fp.TLS.Certificate; cert != nil {
+			finding.Subject = cert.Subject
+			if finding.Subject == "" {
+				finding.Subject = cert.CommonName
+			}
+			finding.Issuer = cert.Issuer
+			finding.NotAfter = cert.NotAfter
+
+			switch {
+			case cert.NotAfter.IsZero():
+			case cert.NotAfter.Before(now):
+				finding.Issues = append(finding.Issues, "expired")
+			case cert.NotAfter.Before(now.Add(certExpiryWarning)):
+				days := int(cert.NotAfter.Sub(now).Hours() / 24)
+				finding.Issues = append(finding.Issues, fmt.Sprintf("expires in %d days", days))
+			}
+			if cert.Subject != "" && cert.Subject == cert.Issuer {
+				finding.Issues = append(finding.Issues, "self-signed")
+			}
+		}
+
+		findings = append(findings, finding)
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Host != findings[j].Host {
+			return compareIPs(findings[i].Host, findings[j].Host)
+		}
+		return findings[i].Port < findings[j].Port
+	})
+	return findings
+}
+
+// subnetSummaries groups hosts by /24 (IPv4) or /64 (IPv6)
+func subnetSummaries(hosts []reports.HostReport) []reports.SubnetSummary {
+	type subnet struct {
+		summary  reports.SubnetSummary
+		services map[string]int
+	}
+	subnets := make(map[string]*subnet)
+	var order []string
+
+	for _, h := range hosts {
+		key := subnetOf(h.Host)
+		s, ok := subnets[key]
+		if !ok {
+			s = &subnet{summary: reports.SubnetSummary{Subnet: key}, services: make(map[string]int)}
+			subnets[key] = s
+			order = append(order, key)
+		}
+		s.summary.HostsUp++
+		s.summary.OpenPorts += len(h.OpenPorts)
+		for _, port := range h.OpenPorts {
+			s.services[port.Service]++
+		}
+	}
+
+	var summaries []reports.SubnetSummary
+	for _, key := range order {
+		s := subnets[key]
+		var names []string
+		for _, point := range topServices(s.services, 3) {
+			names = append(names, fmt.Sprintf("%s (%d)", point.Label, int(point.Value)))
+		}
+		s.summary.TopServices = strings.Join(names, ", ")
+		summaries = append(summaries, s.summary)
+	}
+	return summaries
+}
+
+// subnetOf returns the /24 or /64 network of an address. Addresses that do
+// not parse, such as redacted ones, are grouped by their leading octets.
+func subnetOf(address string) string {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		if i := strings.LastIndex(address, "."); i > 0 {
+			return address[:i] + ".*"
+		}
+		return address
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return (&net.IPNet{IP: ip4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}).String()
+}
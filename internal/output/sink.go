@@ -0,0 +1,108 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ResultSink receives results while an operation runs
+type ResultSink interface {
+	// Write records an event; it must not fail the operation
+	Write(eventType string, data interface{})
+	// Close flushes pending events and returns the first delivery error
+	Close() error
+}
+
+// SinkEvent is a single result or summary delivered to a sink
+type SinkEvent struct {
+	Type string      `json:"type"` // e.g. "discover.result", "scan.summary"
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// JSONLSink appends results to a file as they happen, one JSON object per
+// line. Each line is synced to disk so external processors can tail the
+// file and nothing already written is lost if the run crashes.
+type JSONLSink struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	err     error
+}
+
+// OpenJSONLSink opens path for appending, creating it if needed
+func OpenJSONLSink(path string) (*JSONLSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+	return &JSONLSink{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Write appends an event. After the first failure further writes are
+// dropped; the error is reported by Close.
+func (s *JSONLSink) Write(eventType string, data interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return
+	}
+	if err := s.encoder.Encode(SinkEvent{Type: eventType, Time: time.Now(), Data: data}); err != nil {
+		s.err = fmt.Errorf("failed to write to output file: %w", err)
+		return
+	}
+	s.err = s.file.Sync()
+}
+
+// Close closes the file and returns the first write error, if any
+func (s *JSONLSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	closeErr := s.file.Close()
+	if s.err != nil {
+		return s.err
+	}
+	return closeErr
+}
+
+// multiSink fans events out to several sinks
+type multiSink []ResultSink
+
+// NewMultiSink combines sinks, skipping nil ones. It returns nil when no
+// sink remains.
+func NewMultiSink(sinks ...ResultSink) ResultSink {
+	var combined multiSink
+	for _, sink := range sinks {
+		if sink != nil {
+			combined = append(combined, sink)
+		}
+	}
+	switch len(combined) {
+	case 0:
+		return nil
+	case 1:
+		return combined[0]
+	}
+	return combined
+}
+
+func (m multiSink) Write(eventType string, data interface{}) {
+	for _, sink := range m {
+		sink.Write(eventType, data)
+	}
+}
+
+func (m multiSink) Close() error {
+	var firstErr error
+	for _, sink := range m {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"encoding/json"
+
+	"github.com/netcrate/netcrate/internal/compliance"
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/netenv"
+	"github.com/netcrate/netcrate/internal/version"
+)
+
+// RunContext records how and from where a run was made, so its results can
+// still be interpreted months later
+type RunContext struct {
+	Version       string                       `json:"netcrate_version"`
+	Environment   *netenv.Snapshot             `json:"environment,omitempty"`
+	Options       json.RawMessage              `json:"options,omitempty"`       // effective options of the operation
+	Scope         *compliance.ScopeReference   `json:"scope,omitempty"`         // engagement scope in force
+	Clamped       []compliance.Clamp           `json:"clamped,omitempty"`       // options lowered to compliance ceilings
+	Authorization *compliance.RunAuthorization `json:"authorization,omitempty"` // who authorized the run
+}
+
+// NewRunContext captures the current environment and the effective options.
+// iface names the interface the run uses; empty selects the default route.
+func NewRunContext(iface string, options interface{}) *RunContext {
+	publicIP := false
+	var scope *compliance.ScopeReference
+	if cm, err := config.NewConfigManager(); err == nil {
+		publicIP = cm.GetConfig().Preferences.RecordPublicIP
+		if path := cm.GetConfig().Compliance.EffectiveScopeFile(); path != "" {
+			if s, err := compliance.LoadScope(path); err == nil {
+				scope = s.Reference()
+			}
+		}
+	}
+
+	context := &RunContext{
+		Version:     version.Version,
+		Environment: netenv.TakeSnapshot(iface, publicIP),
+		Scope:       scope,
+	}
+	if options != nil {
+		if data, err := json.Marshal(options); err == nil {
+			context.Options = data
+		}
+	}
+	return context
+}
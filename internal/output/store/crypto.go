@@ -0,0 +1,269 @@
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/netcrate/netcrate/internal/config"
+)
+
+// Encrypted run files are laid out as magic | salt | nonce | AES-256-GCM
+// ciphertext. The key is derived from the configured key file or passphrase
+// with PBKDF2-HMAC-SHA256 and a per-file salt.
+const (
+	saltSize      = 16
+	kdfIterations = 100000
+)
+
+var encryptedMagic = []byte("NCENC1")
+
+// ErrNoKey is returned when reading an encrypted run without a configured key
+var ErrNoKey = errors.New("run is encrypted and no key is configured (set encryption_key_file, encryption_passphrase or $" + config.PassphraseEnv + ")")
+
+// derivedKeys caches PBKDF2 output so listing many runs stays fast
+var (
+	derivedKeys   = make(map[string][]byte)
+	derivedKeysMu sync.Mutex
+)
+
+// IsEncrypted reports whether data is an encrypted run file
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedMagic)
+}
+
+// encryptionSettings returns whether encryption is enabled and the key material, if any
+func encryptionSettings() (bool, []byte, error) {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return false, nil, err
+	}
+	encryption := cm.GetConfig().Encryption
+	secret, err := encryption.Secret()
+	return encryption.Enabled, secret, err
+}
+
+// seal encrypts data when encryption is enabled and returns it unchanged otherwise
+func seal(data []byte) ([]byte, error) {
+	enabled, secret, err := encryptionSettings()
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return data, nil
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("encryption is enabled but no key file or passphrase is configured")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(secret, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{}, encryptedMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, data, encryptedMagic), nil
+}
+
+// unseal decrypts an encrypted run file and returns plain files unchanged
+func unseal(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	_, secret, err := encryptionSettings()
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, ErrNoKey
+	}
+
+	data = data[len(encryptedMagic):]
+	if len(data) < saltSize {
+		return nil, fmt.Errorf("encrypted run file is truncated")
+	}
+	salt, data := data[:saltSize], data[saltSize:]
+	gcm, err := newGCM(secret, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted run file is truncated")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plain, err := gcm.Open(nil, nonce, ciphertext, encryptedMagic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt run file: wrong key or corrupted data")
+	}
+	return plain, nil
+}
+
+// newGCM derives the file key from secret and salt
+func newGCM(secret, salt []byte) (cipher.AEAD, error) {
+	secretSum := sha256.Sum256(secret)
+	cacheKey := string(secretSum[:]) + string(salt)
+
+	derivedKeysMu.Lock()
+	key, ok := derivedKeys[cacheKey]
+	if !ok {
+		key = pbkdf2SHA256(secret, salt, kdfIterations, 32)
+		derivedKeys[cacheKey] = key
+	}
+	derivedKeysMu.Unlock()
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 implements PBKDF2 (RFC 8018) with HMAC-SHA256
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		var counter [4]byte
+		binary.BigEndian.PutUint32(counter[:], block)
+		prf.Write(counter[:])
+		u := prf.Sum(nil)
+
+		t := append([]byte{}, u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
+
+// WriteRunFile stores v as indented JSON in a run directory, encrypted when
+// encryption is enabled, and returns the file path
+func WriteRunFile(runID, name string, v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	if err := os.MkdirAll(RunDir(runID), 0755); err != nil {
+		return "", fmt.Errorf("failed to create run directory: %w", err)
+	}
+	path := filepath.Join(RunDir(runID), name)
+	if err := WriteSealed(path, append(data, '\n')); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return path, nil
+}
+
+// WriteSealed writes data to path, readable by the owner only and encrypted
+// when encryption is enabled. Files kept beside the runs, such as run logs
+// and the template history, are written with it.
+func WriteSealed(path string, data []byte) error {
+	sealed, err := seal(data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, sealed, 0600)
+}
+
+// AppendSealed appends data to the file at path. An encrypted file is
+// decrypted and sealed again as a whole, so it stays a single sealed file.
+func AppendSealed(path string, data []byte) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	existing, err = unseal(existing)
+	if err != nil {
+		return err
+	}
+	return WriteSealed(path, append(existing, data...))
+}
+
+// ReadRunFile reads a file from a run directory, decrypting it if needed
+func ReadRunFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return unseal(data)
+}
+
+// EncryptAll encrypts every plain file in the saved runs with the configured
+// key, removes the results index and returns the number of files encrypted
+func EncryptAll() (int, error) {
+	enabled, secret, err := encryptionSettings()
+	if err != nil {
+		return 0, err
+	}
+	if !enabled || secret == nil {
+		return 0, fmt.Errorf("encryption is not enabled or no key is configured")
+	}
+
+	encrypted := 0
+	err = filepath.WalkDir(Dir(), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if IsEncrypted(data) {
+			return nil
+		}
+		sealed, err := seal(data)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, sealed, 0600); err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", path, err)
+		}
+		encrypted++
+		return nil
+	})
+	if err != nil {
+		return encrypted, err
+	}
+
+	// The index holds the results of the runs in the clear
+	if err := os.Remove(IndexPath()); err != nil && !os.IsNotExist(err) {
+		return encrypted, fmt.Errorf("failed to remove the results index: %w", err)
+	}
+	return encrypted, nil
+}
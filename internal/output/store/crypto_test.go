@@ -0,0 +1,152 @@
+package store
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/netcrate/netcrate/internal/config"
+)
+
+// enableTestEncryption turns on encryption with a passphrase in a fresh home
+func enableTestEncryption(t *testing.T, passphrase string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("NETCRATE_ENCRYPT", "true")
+	t.Setenv(config.PassphraseEnv, passphrase)
+}
+
+func TestSealRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"json", []byte(`{"run_id":"scan_1","results":[]}`)},
+		{"empty", []byte{}},
+		{"binary", []byte{0, 1, 2, 0xff, '\n'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enableTestEncryption(t, "correct horse")
+			sealed, err := seal(tt.data)
+			if err != nil {
+				t.Fatalf("seal: %v", err)
+			}
+			if !IsEncrypted(sealed) {
+				t.Fatal("sealed data is not marked encrypted")
+			}
+			if len(tt.data) > 0 && bytes.Contains(sealed, tt.data) {
+				t.Error("sealed data contains the plaintext")
+			}
+			plain, err := unseal(sealed)
+			if err != nil {
+				t.Fatalf("unseal: %v", err)
+			}
+			if !bytes.Equal(plain, tt.data) {
+				t.Errorf("unseal = %q, want %q", plain, tt.data)
+			}
+		})
+	}
+}
+
+func TestSealWithoutEncryption(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("NETCRATE_ENCRYPT", "false")
+	data := []byte(`{"run_id":"scan_1"}`)
+	sealed, err := seal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sealed, data) {
+		t.Errorf("seal changed data with encryption disabled: %q", sealed)
+	}
+	// Plain files read back unchanged
+	if plain, err := unseal(data); err != nil || !bytes.Equal(plain, data) {
+		t.Errorf("unseal = %q, %v", plain, err)
+	}
+}
+
+func TestUnsealFailures(t *testing.T) {
+	enableTestEncryption(t, "correct horse")
+	sealed, err := seal([]byte(`{"run_id":"scan_1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+		wantErr    error
+	}{
+		{"wrong passphrase", sealed, "battery staple", nil},
+		{"no key", sealed, "", ErrNoKey},
+		{"corrupt ciphertext", flipLastByte(sealed), "correct horse", nil},
+		{"truncated", sealed[:len(encryptedMagic)+saltSize+4], "correct horse", nil},
+		{"magic only", encryptedMagic, "correct horse", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.PassphraseEnv, tt.passphrase)
+			plain, err := unseal(tt.data)
+			if err == nil {
+				t.Fatalf("unseal succeeded with %q", plain)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("unseal error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func flipLastByte(data []byte) []byte {
+	corrupt := append([]byte{}, data...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	return corrupt
+}
+
+func TestAppendSealed(t *testing.T) {
+	enableTestEncryption(t, "correct horse")
+	path := filepath.Join(t.TempDir(), "watch.log")
+	for _, line := range []string{"first\n", "second\n"} {
+		if err := AppendSealed(path, []byte(line)); err != nil {
+			t.Fatalf("AppendSealed: %v", err)
+		}
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(raw) {
+		t.Error("appended file is not encrypted")
+	}
+	plain, err := ReadRunFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain) != "first\nsecond\n" {
+		t.Errorf("ReadRunFile = %q", plain)
+	}
+}
+
+func TestPBKDF2SHA256(t *testing.T) {
+	// Test vectors from RFC 7914, section 11
+	tests := []struct {
+		password, salt string
+		iterations     int
+		want           string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+	for _, tt := range tests {
+		got := hex.EncodeToString(pbkdf2SHA256([]byte(tt.password), []byte(tt.salt), tt.iterations, 64))
+		if got != tt.want {
+			t.Errorf("pbkdf2SHA256(%s, %s, %d) = %s, want %s", tt.password, tt.salt, tt.iterations, got, tt.want)
+		}
+	}
+}
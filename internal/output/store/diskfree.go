@@ -0,0 +1,23 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FreeSpace returns the bytes available to netcrate on the filesystem that
+// holds the results directory, which need not exist yet
+func FreeSpace() (uint64, error) {
+	dir := Dir()
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return freeSpace(dir)
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !windows
+
+package store
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func freeSpace(dir string) (uint64, error) {
+	return 0, fmt.Errorf("free space is not available on %s", runtime.GOOS)
+}
@@ -0,0 +1,15 @@
+package store
+
+import "golang.org/x/sys/windows"
+
+func freeSpace(dir string) (uint64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	var available uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &available, nil, nil); err != nil {
+		return 0, err
+	}
+	return available, nil
+}
@@ -0,0 +1,389 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/netcrate/netcrate/internal/config"
+)
+
+// indexSchema creates the tables of the results index. The JSON run files
+// remain the source of truth; the index can always be rebuilt from them.
+const indexSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	run_id     TEXT PRIMARY KEY,
+	type       TEXT NOT NULL,
+	command    TEXT,
+	start_time TEXT,
+	end_time   TEXT,
+	duration   REAL,
+	status     TEXT,
+	targets    TEXT
+);
+CREATE TABLE IF NOT EXISTS hosts (
+	run_id   TEXT NOT NULL REFERENCES runs(run_id) ON DELETE CASCADE,
+	host     TEXT NOT NULL,
+	status   TEXT,
+	method   TEXT,
+	rtt      REAL,
+	hostname TEXT,
+	seen     TEXT
+);
+CREATE TABLE IF NOT EXISTS ports (
+	run_id   TEXT NOT NULL REFERENCES runs(run_id) ON DELETE CASCADE,
+	host     TEXT NOT NULL,
+	port     INTEGER NOT NULL,
+	protocol TEXT,
+	status   TEXT,
+	service  TEXT,
+	version  TEXT,
+	rtt      REAL,
+	seen     TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_hosts_host ON hosts(host);
+CREATE INDEX IF NOT EXISTS idx_ports_host ON ports(host, port);
+`
+
+// Index is the optional SQLite index over saved runs
+type Index struct {
+	db *sql.DB
+}
+
+// ErrIndexEncrypted is returned by OpenIndex when encryption is enabled
+var ErrIndexEncrypted = errors.New("the results index is not kept while encryption is enabled")
+
+// IndexPath returns ~/.netcrate/netcrate.db
+func IndexPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".netcrate", "netcrate.db")
+}
+
+// OpenIndex opens the results index, creating it if needed. The index keeps
+// hosts, ports and services in the clear, so it is not available while
+// encryption is enabled.
+func OpenIndex() (*Index, error) {
+	if enabled, _, err := encryptionSettings(); err == nil && enabled {
+		return nil, ErrIndexEncrypted
+	}
+	if err := os.MkdirAll(filepath.Dir(IndexPath()), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+IndexPath()+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results index: %w", err)
+	}
+	if _, err := db.Exec(indexSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize results index: %w", err)
+	}
+
+	return &Index{db: db}, nil
+}
+
+// Close closes the index database
+func (idx *Index) Close() error {
+	return idx.db.Close()
+}
+
+// indexEnabled reports whether runs should be indexed as they are saved
+func indexEnabled() bool {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return false
+	}
+	return cm.GetConfig().Preferences.ResultsDB
+}
+
+// indexOnSave adds a freshly saved record to the index when it is enabled.
+// Failures do not fail the save: the next Sync picks up anything that was
+// missed. They are logged, as a build without the SQLite driver never
+// indexes anything.
+func indexOnSave(record *RunRecord) {
+	if !indexEnabled() {
+		return
+	}
+	idx, err := OpenIndex()
+	if err != nil {
+		if !errors.Is(err, ErrIndexEncrypted) {
+			log.Warn("results index unavailable", "error", err)
+		}
+		return
+	}
+	defer idx.Close()
+	if err := idx.Add(record); err != nil {
+		log.Warn("failed to index run", "run", record.RunID, "error", err)
+	}
+}
+
+// indexedEntry covers the fields of discovery and port scan results the index stores
+type indexedEntry struct {
+	Host     string  `json:"host"`
+	Port     int     `json:"port"`
+	Protocol string  `json:"protocol"`
+	Status   string  `json:"status"`
+	Method   string  `json:"method"`
+	RTT      float64 `json:"rtt"`
+	Hostname string  `json:"hostname"`
+	Service  *struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"service"`
+}
+
+// indexedResult matches discover, scan and quick results alike
+type indexedResult struct {
+	Results        []indexedEntry `json:"results"`
+	DiscoverResult *struct {
+		Results []indexedEntry `json:"results"`
+	} `json:"discover_result"`
+	ScanResult *struct {
+		Results []indexedEntry `json:"results"`
+	} `json:"scan_result"`
+}
+
+// Add indexes a record, replacing any previous rows for the same run
+func (idx *Index) Add(record *RunRecord) error {
+	var entries []indexedEntry
+	if record.Type != TypePacket {
+		var result indexedResult
+		if err := record.Decode(&result); err != nil {
+			return err
+		}
+		entries = result.Results
+		if result.DiscoverResult != nil {
+			entries = append(entries, result.DiscoverResult.Results...)
+		}
+		if result.ScanResult != nil {
+			entries = append(entries, result.ScanResult.Results...)
+		}
+	}
+
+	tx, err := idx.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM runs WHERE run_id = ?`, record.RunID); err != nil {
+		return err
+	}
+
+	seen := record.StartTime.UTC().Format(time.RFC3339)
+	_, err = tx.Exec(`INSERT INTO runs (run_id, type, command, start_time, end_time, duration, status, targets)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.RunID, record.Type, record.Command, seen, record.EndTime.UTC().Format(time.RFC3339),
+		record.Duration, record.Status, strings.Join(record.Targets, ","))
+	if err != nil {
+		return err
+	}
+
+	hostsSeen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Port > 0 {
+			service, version := "", ""
+			if entry.Service != nil {
+				service, version = entry.Service.Name, entry.Service.Version
+			}
+			_, err = tx.Exec(`INSERT INTO ports (run_id, host, port, protocol, status, service, version, rtt, seen)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				record.RunID, entry.Host, entry.Port, entry.Protocol, entry.Status, service, version, entry.RTT, seen)
+			if err != nil {
+				return err
+			}
+			if entry.Status != "open" {
+				continue
+			}
+			// An open port means the host was up even if discovery did not list it
+			entry = indexedEntry{Host: entry.Host, Status: "up", Method: "scan"}
+		}
+
+		if entry.Status != "up" || hostsSeen[entry.Host] {
+			continue
+		}
+		hostsSeen[entry.Host] = true
+		_, err = tx.Exec(`INSERT INTO hosts (run_id, host, status, method, rtt, hostname, seen)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			record.RunID, entry.Host, entry.Status, entry.Method, entry.RTT, entry.Hostname, seen)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Sync indexes saved runs that are missing from the index and drops runs
+// whose files have been removed. It returns the number of runs (re)indexed.
+func (idx *Index) Sync() (int, error) {
+	records, _, err := List()
+	if err != nil {
+		return 0, err
+	}
+
+	// run ID -> indexed status, so resumed runs are indexed again
+	indexed := make(map[string]string)
+	rows, err := idx.db.Query(`SELECT run_id, COALESCE(status, '') FROM runs`)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var runID, status string
+		if err := rows.Scan(&runID, &status); err == nil {
+			indexed[runID] = status
+		}
+	}
+	rows.Close()
+
+	added := 0
+	for _, record := range records {
+		status, ok := indexed[record.RunID]
+		delete(indexed, record.RunID)
+		if ok && status == record.Status {
+			continue
+		}
+		if err := idx.Add(record); err != nil {
+			return added, fmt.Errorf("failed to index run %s: %w", record.RunID, err)
+		}
+		added++
+	}
+
+	for runID := range indexed {
+		if err := idx.Remove(runID); err != nil {
+			return added, err
+		}
+	}
+
+	return added, nil
+}
+
+// Remove drops runs from the index
+func (idx *Index) Remove(runIDs ...string) error {
+	for _, runID := range runIDs {
+		if _, err := idx.db.Exec(`DELETE FROM runs WHERE run_id = ?`, runID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rebuild drops every indexed run and indexes all saved runs again
+func (idx *Index) Rebuild() (int, error) {
+	if _, err := idx.db.Exec(`DELETE FROM runs`); err != nil {
+		return 0, err
+	}
+	return idx.Sync()
+}
+
+// QueryResult is a generic table returned by index queries
+type QueryResult struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Common queries exposed by `netcrate output query`
+var namedQueries = map[string]string{
+	"hosts": `SELECT host, MAX(hostname) AS hostname, MIN(seen) AS first_seen, MAX(seen) AS last_seen,
+		COUNT(DISTINCT run_id) AS runs
+		FROM hosts GROUP BY host ORDER BY last_seen DESC, host`,
+	"ports": `SELECT host, port, protocol, MAX(service) AS service, MAX(version) AS version,
+		MIN(seen) AS first_seen, MAX(seen) AS last_seen, COUNT(DISTINCT run_id) AS runs
+		FROM ports WHERE status = 'open' AND (? = '' OR host = ?)
+		GROUP BY host, port, protocol ORDER BY host, port`,
+	"services": `SELECT COALESCE(NULLIF(service, ''), 'unknown') AS service, COUNT(DISTINCT host) AS hosts,
+		COUNT(DISTINCT host || ':' || port) AS ports, MAX(seen) AS last_seen
+		FROM ports WHERE status = 'open' GROUP BY 1 ORDER BY hosts DESC, service`,
+	"runs": `SELECT run_id, type, start_time, duration, status, targets FROM runs ORDER BY start_time DESC`,
+}
+
+// NamedQueries lists the names accepted by Query
+func NamedQueries() []string {
+	return []string{"hosts", "ports", "services", "runs"}
+}
+
+// Query runs one of the common queries. host filters the "ports" query.
+func (idx *Index) Query(name, host string) (*QueryResult, error) {
+	query, ok := namedQueries[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown query: %s (expected one of %s)", name, strings.Join(NamedQueries(), ", "))
+	}
+
+	var args []interface{}
+	if name == "ports" {
+		args = []interface{}{host, host}
+	}
+	return idx.collect(query, args...)
+}
+
+// RawQuery runs a read-only SQL statement against the index
+func (idx *Index) RawQuery(statement string) (*QueryResult, error) {
+	ro, err := sql.Open("sqlite", "file:"+IndexPath()+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer ro.Close()
+
+	return (&Index{db: ro}).collect(statement)
+}
+
+// collect runs a query and renders every value as a string
+func (idx *Index) collect(query string, args ...interface{}) (*QueryResult, error) {
+	rows, err := idx.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{Columns: columns}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make([]string, len(columns))
+		for i, value := range values {
+			switch v := value.(type) {
+			case nil:
+				row[i] = ""
+			case []byte:
+				row[i] = string(v)
+			default:
+				row[i] = fmt.Sprint(v)
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, rows.Err()
+}
+
+// MarshalJSON renders rows as objects keyed by column name
+func (r *QueryResult) MarshalJSON() ([]byte, error) {
+	objects := make([]map[string]string, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		object := make(map[string]string, len(r.Columns))
+		for i, column := range r.Columns {
+			object[column] = row[i]
+		}
+		objects = append(objects, object)
+	}
+	return json.Marshal(objects)
+}
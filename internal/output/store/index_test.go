@@ -0,0 +1,171 @@
+package store
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func openTestIndex(t *testing.T) *Index {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	idx, err := OpenIndex()
+	if err != nil {
+		t.Fatalf("OpenIndex: %v", err)
+	}
+	t.Cleanup(func() { idx.Close() })
+	return idx
+}
+
+func testRecord(t *testing.T, runID, runType string, start time.Time, result interface{}) *RunRecord {
+	t.Helper()
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &RunRecord{
+		RunID:     runID,
+		Type:      runType,
+		StartTime: start,
+		EndTime:   start.Add(time.Second),
+		Duration:  1,
+		Status:    "completed",
+		Targets:   []string{"192.168.1.0/24"},
+		Result:    data,
+	}
+}
+
+func TestIndexQueries(t *testing.T) {
+	idx := openTestIndex(t)
+	first := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	second, third, fourth := first.Add(time.Hour), first.Add(2*time.Hour), first.Add(3*time.Hour)
+
+	records := []*RunRecord{
+		testRecord(t, "discover_1", TypeDiscover, first, map[string]interface{}{
+			"results": []map[string]interface{}{
+				{"host": "192.168.1.1", "status": "up", "method": "icmp", "hostname": "router"},
+				{"host": "192.168.1.9", "status": "down", "method": "icmp"},
+			},
+		}),
+		testRecord(t, "scan_1", TypeScan, second, map[string]interface{}{
+			"results": []map[string]interface{}{
+				{"host": "192.168.1.1", "port": 22, "protocol": "tcp", "status": "open",
+					"service": map[string]string{"name": "ssh", "version": "OpenSSH 9.6"}},
+				{"host": "192.168.1.1", "port": 23, "protocol": "tcp", "status": "closed"},
+				{"host": "192.168.1.2", "port": 80, "protocol": "tcp", "status": "open"},
+			},
+		}),
+		testRecord(t, "quick_1", TypeQuick, third, map[string]interface{}{
+			"discover_result": map[string]interface{}{
+				"results": []map[string]interface{}{{"host": "192.168.1.3", "status": "up", "method": "tcp"}},
+			},
+			"scan_result": map[string]interface{}{
+				"results": []map[string]interface{}{{"host": "192.168.1.3", "port": 443, "protocol": "tcp", "status": "open"}},
+			},
+		}),
+		testRecord(t, "packet_1", TypePacket, fourth, map[string]interface{}{"sent": 3}),
+	}
+	for _, record := range records {
+		if err := idx.Add(record); err != nil {
+			t.Fatalf("Add(%s): %v", record.RunID, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		host   string
+		column string
+		want   []string
+	}{
+		{"hosts up or with open ports", "hosts", "", "host", []string{"192.168.1.3", "192.168.1.1", "192.168.1.2"}},
+		{"open ports only", "ports", "", "port", []string{"22", "80", "443"}},
+		{"ports of one host", "ports", "192.168.1.1", "service", []string{"ssh"}},
+		{"services", "services", "", "service", []string{"unknown", "ssh"}},
+		{"every run", "runs", "", "run_id", []string{"packet_1", "quick_1", "scan_1", "discover_1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := idx.Query(tt.query, tt.host)
+			if err != nil {
+				t.Fatalf("Query(%s): %v", tt.query, err)
+			}
+			if got := column(t, result, tt.column); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.column, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexReplacesAndRemovesRuns(t *testing.T) {
+	idx := openTestIndex(t)
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	open := func(port int) map[string]interface{} {
+		return map[string]interface{}{
+			"results": []map[string]interface{}{{"host": "10.0.0.1", "port": port, "protocol": "tcp", "status": "open"}},
+		}
+	}
+
+	if err := idx.Add(testRecord(t, "scan_1", TypeScan, start, open(22))); err != nil {
+		t.Fatal(err)
+	}
+	// Indexing a run again replaces its rows, as Sync does for resumed runs
+	if err := idx.Add(testRecord(t, "scan_1", TypeScan, start, open(8080))); err != nil {
+		t.Fatal(err)
+	}
+	result, err := idx.Query("ports", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := column(t, result, "port"); !reflect.DeepEqual(got, []string{"8080"}) {
+		t.Errorf("ports after re-adding = %v, want [8080]", got)
+	}
+
+	if err := idx.Remove("scan_1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range NamedQueries() {
+		result, err := idx.Query(query, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Rows) != 0 {
+			t.Errorf("%s after Remove = %v, want no rows", query, result.Rows)
+		}
+	}
+}
+
+func TestIndexRejectsUnknownQuery(t *testing.T) {
+	idx := openTestIndex(t)
+	if _, err := idx.Query("everything", ""); err == nil {
+		t.Error("Query(everything) succeeded, want an error")
+	}
+}
+
+func TestQueryResultJSON(t *testing.T) {
+	result := &QueryResult{Columns: []string{"host", "port"}, Rows: [][]string{{"10.0.0.1", "22"}}}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"host":"10.0.0.1","port":"22"}]`; string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
+
+// column returns the values of one column of a query result
+func column(t *testing.T, result *QueryResult, name string) []string {
+	t.Helper()
+	for i, c := range result.Columns {
+		if c == name {
+			values := []string{}
+			for _, row := range result.Rows {
+				values = append(values, row[i])
+			}
+			return values
+		}
+	}
+	t.Fatalf("no column %s in %v", name, result.Columns)
+	return nil
+}
@@ -0,0 +1,172 @@
+package store
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/config"
+)
+
+// RetentionPolicy limits the saved runs; zero fields are not enforced
+type RetentionPolicy struct {
+	MaxRuns  int
+	MaxAge   time.Duration
+	MaxBytes int64
+}
+
+// IsZero reports whether the policy enforces nothing
+func (p RetentionPolicy) IsZero() bool {
+	return p.MaxRuns == 0 && p.MaxAge == 0 && p.MaxBytes == 0
+}
+
+// PolicyFromConfig builds the policy configured under "retention"
+func PolicyFromConfig() (RetentionPolicy, error) {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return RetentionPolicy{}, err
+	}
+	retention := cm.GetConfig().Retention
+
+	maxAge, err := config.ParseAge(retention.MaxAge)
+	if err != nil {
+		return RetentionPolicy{}, err
+	}
+	maxBytes, err := config.ParseByteSize(retention.MaxDisk)
+	if err != nil {
+		return RetentionPolicy{}, err
+	}
+
+	return RetentionPolicy{MaxRuns: retention.MaxRuns, MaxAge: maxAge, MaxBytes: maxBytes}, nil
+}
+
+// PruneCandidate is a run the policy would remove
+type PruneCandidate struct {
+	Record *RunRecord
+	Size   int64
+	Reason string // "age", "count" or "disk"
+}
+
+// PlanPrune lists the runs that violate the policy. Runs are kept newest
+// first until a limit is reached; keep names a run that is never removed.
+func PlanPrune(policy RetentionPolicy, keep string) ([]PruneCandidate, error) {
+	records, _, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []PruneCandidate
+	var keptRuns int
+	var keptBytes int64
+	now := time.Now()
+
+	for _, record := range records {
+		size := dirSize(RunDir(record.RunID))
+
+		if record.RunID == keep {
+			keptRuns++
+			keptBytes += size
+			continue
+		}
+
+		reason := ""
+		switch {
+		case policy.MaxAge > 0 && now.Sub(record.StartTime) > policy.MaxAge:
+			reason = "age"
+		case policy.MaxRuns > 0 && keptRuns >= policy.MaxRuns:
+			reason = "count"
+		case policy.MaxBytes > 0 && keptBytes+size > policy.MaxBytes:
+			reason = "disk"
+		}
+
+		if reason != "" {
+			candidates = append(candidates, PruneCandidate{Record: record, Size: size, Reason: reason})
+			continue
+		}
+		keptRuns++
+		keptBytes += size
+	}
+
+	return candidates, nil
+}
+
+// Prune removes the candidates' run directories and drops them from the index
+func Prune(candidates []PruneCandidate) error {
+	var removed []string
+	for _, candidate := range candidates {
+		if err := os.RemoveAll(RunDir(candidate.Record.RunID)); err != nil {
+			return fmt.Errorf("failed to remove run %s: %w", candidate.Record.RunID, err)
+		}
+		removed = append(removed, candidate.Record.RunID)
+	}
+
+	if len(removed) > 0 {
+		if _, err := os.Stat(IndexPath()); err == nil {
+			if idx, err := OpenIndex(); err == nil {
+				idx.Remove(removed...)
+				idx.Close()
+			}
+		}
+	}
+	return nil
+}
+
+// PruneTagged keeps the keep newest runs carrying tag and removes the older
+// ones, returning how many were removed. It bounds the history of a series
+// of runs, such as the iterations of --repeat.
+func PruneTagged(tag string, keep int) (int, error) {
+	records, _, err := List()
+	if err != nil {
+		return 0, err
+	}
+
+	var candidates []PruneCandidate
+	kept := 0
+	for _, record := range records {
+		if !record.HasTag(tag) {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		candidates = append(candidates, PruneCandidate{Record: record, Size: dirSize(RunDir(record.RunID)), Reason: "count"})
+	}
+	return len(candidates), Prune(candidates)
+}
+
+// enforceRetention applies the configured policy after a run is saved.
+// The run that was just saved is always kept.
+func enforceRetention(keep string) {
+	policy, err := PolicyFromConfig()
+	if err != nil || policy.IsZero() {
+		return
+	}
+	candidates, err := PlanPrune(policy, keep)
+	if err != nil {
+		return
+	}
+	Prune(candidates)
+}
+
+// dirSize returns the total size of the files under dir
+// RunSize returns the bytes a run occupies on disk
+func RunSize(runID string) int64 {
+	return dirSize(RunDir(runID))
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && !d.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
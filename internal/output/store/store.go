@@ -0,0 +1,327 @@
+// Package store persists run results in the results directory,
+// ~/.netcrate/runs unless configured otherwise, in a common RunRecord
+// envelope, so every operation can be listed, shown and exported the same
+// way. It has no dependencies on the operation packages so that
+// both quick mode and the output commands can use it.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/logging"
+	"github.com/netcrate/netcrate/internal/runid"
+)
+
+var log = logging.Component("store")
+
+// SchemaVersion is the current RunRecord format version
+const SchemaVersion = 1
+
+// Run types
+const (
+	TypeQuick    = "quick"
+	TypeDiscover = "discover"
+	TypeScan     = "scan"
+	TypePacket   = "packet"
+)
+
+// resultFileName is the file holding the record inside a run directory
+const resultFileName = "result.json"
+
+// Labels are user-supplied names, tags and notes attached to a run
+type Labels struct {
+	Name  string   `json:"name,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+	Notes string   `json:"notes,omitempty"`
+}
+
+// HasTag reports whether the labels include tag (case-insensitive)
+func (l Labels) HasTag(tag string) bool {
+	for _, t := range l.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunRecord is the envelope every saved run is wrapped in
+type RunRecord struct {
+	SchemaVersion int            `json:"schema_version"`
+	RunID         string         `json:"run_id"`
+	Type          string         `json:"type"`
+	Command       string         `json:"command,omitempty"`
+	StartTime     time.Time      `json:"start_time"`
+	EndTime       time.Time      `json:"end_time"`
+	Duration      float64        `json:"duration"`
+	Status        string         `json:"status"`
+	Targets       []string       `json:"targets,omitempty"`
+	Counts        map[string]int `json:"counts,omitempty"`
+	Labels
+	Context *RunContext     `json:"context,omitempty"`
+	Result  json.RawMessage `json:"result"`
+
+	// FilePath is where the record was loaded from; not persisted
+	FilePath string `json:"-"`
+}
+
+var (
+	dirOnce sync.Once
+	dir     string
+)
+
+// Dir returns the results directory: preferences.results_dir when set, else
+// ~/.netcrate/runs. It is read from the config once per process.
+func Dir() string {
+	dirOnce.Do(func() {
+		if cm, err := config.NewConfigManager(); err == nil {
+			dir = cm.GetConfig().Preferences.EffectiveResultsDir()
+		}
+		if dir == "" {
+			homeDir, _ := os.UserHomeDir()
+			dir = filepath.Join(homeDir, ".netcrate", "runs")
+		}
+	})
+	return dir
+}
+
+// RunDir returns the directory of a single run
+func RunDir(runID string) string {
+	return filepath.Join(Dir(), runID)
+}
+
+// ResultPath returns the record file of a single run
+func ResultPath(runID string) string {
+	return filepath.Join(RunDir(runID), resultFileName)
+}
+
+// Save encodes result into the record and writes it to the run directory
+func Save(record *RunRecord, result interface{}) error {
+	if record.RunID == "" {
+		return fmt.Errorf("run record has no run ID")
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	record.Result = data
+	record.SchemaVersion = SchemaVersion
+	if record.Duration == 0 && !record.EndTime.IsZero() {
+		record.Duration = record.EndTime.Sub(record.StartTime).Seconds()
+	}
+
+	runDir := RunDir(record.RunID)
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return fmt.Errorf("failed to create run directory: %w", err)
+	}
+
+	encoded, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run record: %w", err)
+	}
+	path := filepath.Join(runDir, resultFileName)
+	if err := WriteSealed(path, append(encoded, '\n')); err != nil {
+		return fmt.Errorf("failed to write result file: %w", err)
+	}
+
+	record.FilePath = path
+	indexOnSave(record)
+	enforceRetention(record.RunID)
+	// Keep the debug log of the run next to the others when logging.persist
+	// is on; losing it does not fail the save
+	if _, err := logging.SaveRun(record.RunID, seal); err != nil {
+		log.Warn("failed to keep run log", "run", record.RunID, "error", err)
+	}
+	return nil
+}
+
+// Load reads the record of a run by ID, alias or unique prefix (see Resolve)
+func Load(ref string) (*RunRecord, error) {
+	runID, err := Resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	record, err := LoadFile(ResultPath(runID))
+	if err != nil && os.IsNotExist(err) {
+		return nil, fmt.Errorf("run with ID '%s' not found", runID)
+	}
+	return record, err
+}
+
+// Resolve expands a run reference to a saved run's ID. A reference is a
+// full ID, an alias such as quick-20261017-093015, or a prefix unique among
+// saved runs; the prefix may leave out the type, e.g. "01JAB3" for
+// scan_01JAB3X5Q9M2T7KZ0R4W8YV6CD. Prefixes are case-insensitive.
+func Resolve(ref string) (string, error) {
+	if ref == "" {
+		return "", fmt.Errorf("no run ID given")
+	}
+	if _, err := os.Stat(ResultPath(ref)); err == nil {
+		return ref, nil
+	}
+
+	entries, err := os.ReadDir(Dir())
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read runs directory: %w", err)
+	}
+
+	var aliased, prefixed []string
+	lowerRef := strings.ToLower(ref)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		id := entry.Name()
+		if runid.Alias(id) == ref {
+			aliased = append(aliased, id)
+			continue
+		}
+		lowerID := strings.ToLower(id)
+		_, suffix, _ := strings.Cut(lowerID, "_")
+		if strings.HasPrefix(lowerID, lowerRef) || (suffix != "" && strings.HasPrefix(suffix, lowerRef)) {
+			prefixed = append(prefixed, id)
+		}
+	}
+
+	matches := aliased
+	if len(matches) == 0 {
+		matches = prefixed
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("run with ID '%s' not found", ref)
+	case 1:
+		return matches[0], nil
+	}
+	sort.Strings(matches)
+	return "", fmt.Errorf("run reference '%s' is ambiguous; it matches %s", ref, strings.Join(matches, ", "))
+}
+
+// LoadFile reads a record file, decrypting it if needed. Files written before
+// the envelope existed hold a bare quick mode result and are wrapped as a
+// quick record.
+func LoadFile(path string) (*RunRecord, error) {
+	data, err := ReadRunFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var record RunRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse result file: %w", err)
+	}
+
+	if record.SchemaVersion == 0 {
+		var legacy struct {
+			TargetCIDR string `json:"target_cidr"`
+		}
+		json.Unmarshal(data, &legacy)
+
+		record.Type = TypeQuick
+		record.Command = "quick"
+		record.Result = data
+		if legacy.TargetCIDR != "" {
+			record.Targets = []string{legacy.TargetCIDR}
+		}
+	}
+
+	record.FilePath = path
+	return &record, nil
+}
+
+// UpdateLabels rewrites a run's labels, keeping its result untouched
+func UpdateLabels(runID string, update func(*Labels)) (*RunRecord, error) {
+	record, err := Load(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	update(&record.Labels)
+	if err := Save(record, record.Result); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Decode unmarshals the wrapped result into v
+func (r *RunRecord) Decode(v interface{}) error {
+	if len(r.Result) == 0 {
+		return fmt.Errorf("run %s has no result data", r.RunID)
+	}
+	if err := json.Unmarshal(r.Result, v); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", r.Type, err)
+	}
+	return nil
+}
+
+// List loads every saved record, newest first. Unreadable runs are returned
+// in skipped so callers can decide whether to warn about them.
+func List() (records []*RunRecord, skipped map[string]error, err error) {
+	entries, err := os.ReadDir(Dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil // No runs yet
+		}
+		return nil, nil, fmt.Errorf("failed to read runs directory: %w", err)
+	}
+
+	skipped = make(map[string]error)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(Dir(), entry.Name(), resultFileName)
+		record, err := LoadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				skipped[path] = err
+			}
+			continue
+		}
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].StartTime.After(records[j].StartTime)
+	})
+
+	return records, skipped, nil
+}
+
+// IDs returns the IDs of the saved runs, newest first by the time in the ID.
+// Unlike List it reads no records, so it stays fast with many or encrypted runs.
+func IDs() ([]string, error) {
+	entries, err := os.ReadDir(Dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read runs directory: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(Dir(), entry.Name(), resultFileName)); err == nil {
+			ids = append(ids, entry.Name())
+		}
+	}
+
+	sort.SliceStable(ids, func(i, j int) bool {
+		ti, _ := runid.Time(ids[i])
+		tj, _ := runid.Time(ids[j])
+		return ti.After(tj)
+	})
+	return ids, nil
+}
@@ -0,0 +1,358 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/quick"
+	"github.com/netcrate/netcrate/internal/risk"
+	"github.com/netcrate/netcrate/internal/version"
+)
+
+const (
+	// syslogFacility is local0; collectors usually route on it
+	syslogFacility = 16
+	// syslogSDID names the structured data element. 32473 is the private
+	// enterprise number reserved for documentation (RFC 5612).
+	syslogSDID = "netcrate@32473"
+	syslogApp  = "netcrate"
+)
+
+// syslogField is one detail of a finding, with its RFC 5424 parameter name
+// and the CEF extension key it maps to
+type syslogField struct {
+	name  string
+	cef   string
+	value string
+}
+
+// syslogFinding is a result worth reporting to a SOC
+type syslogFinding struct {
+	signature string // CEF signature ID and RFC 5424 MSGID
+	name      string
+	severity  string // info, low, medium, high, critical
+	time      time.Time
+	fields    []syslogField
+}
+
+// SyslogFormatter renders findings as RFC 5424 syslog messages, or as CEF
+// records carried in the message body of an RFC 5424 header
+type SyslogFormatter struct {
+	format   string // "rfc5424" or "cef"
+	hostname string
+	procID   string
+	risk     *risk.Engine
+}
+
+// NewSyslogFormatter creates a formatter for "rfc5424" or "cef"
+func NewSyslogFormatter(format string) (*SyslogFormatter, error) {
+	if format != "rfc5424" && format != "cef" {
+		return nil, fmt.Errorf("unsupported syslog format: %s (expected rfc5424 or cef)", format)
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	return &SyslogFormatter{
+		format:   format,
+		hostname: strings.ReplaceAll(hostname, " ", "_"),
+		procID:   strconv.Itoa(os.Getpid()),
+		risk:     quick.LoadRiskEngine(),
+	}, nil
+}
+
+// Format renders a result or summary. Results that are not findings, such
+// as closed ports or hosts that did not answer, return ok == false.
+func (f *SyslogFormatter) Format(data interface{}) (string, bool) {
+	finding := f.finding(data)
+	if finding == nil {
+		return "", false
+	}
+
+	header := fmt.Sprintf("<%d>1 %s %s %s %s %s",
+		syslogFacility*8+syslogSeverity(finding.severity),
+		finding.time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		f.hostname, syslogApp, f.procID, finding.signature)
+
+	if f.format == "cef" {
+		return header + " - " + cefRecord(finding), true
+	}
+
+	var sd strings.Builder
+	sd.WriteString("[" + syslogSDID)
+	fmt.Fprintf(&sd, " severity=\"%s\"", finding.severity)
+	for _, field := range finding.fields {
+		fmt.Fprintf(&sd, " %s=\"%s\"", field.name, escapeSDValue(field.value))
+	}
+	sd.WriteString("]")
+
+	return header + " " + sd.String() + " " + finding.name, true
+}
+
+// finding extracts the reportable part of a sink event
+func (f *SyslogFormatter) finding(data interface{}) *syslogFinding {
+	switch r := data.(type) {
+	case ops.DiscoverResult:
+		if r.Status != "up" {
+			return nil
+		}
+		fields := []syslogField{{"host", "dst", r.Host}}
+		if r.Hostname != "" {
+			fields = append(fields, syslogField{"hostname", "dhost", r.Hostname})
+		}
+		fields = append(fields,
+			syslogField{"method", "cs1", r.Method},
+			syslogField{"rtt_ms", "cn1", fmt.Sprintf("%.0f", r.RTT)})
+		fields = append(fields, complianceFields(r.Compliance)...)
+		return &syslogFinding{
+			signature: "host-up",
+			name:      fmt.Sprintf("Host %s is up", r.Host),
+			severity:  "info",
+			time:      eventTime(r.Timestamp),
+			fields:    fields,
+		}
+	case ops.ScanResult:
+		if r.Status != "open" {
+			return nil
+		}
+		protocol := r.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		service, serviceVersion := "", ""
+		if r.Service != nil {
+			service, serviceVersion = r.Service.Name, r.Service.Version
+		}
+		assessment := f.risk.Evaluate(risk.Finding{Host: r.Host, Port: r.Port, Service: service, Version: serviceVersion})
+		fields := []syslogField{
+			{"host", "dst", r.Host},
+			{"port", "dpt", strconv.Itoa(r.Port)},
+			{"protocol", "proto", strings.ToUpper(protocol)},
+		}
+		if service != "" {
+			fields = append(fields, syslogField{"service", "app", service})
+		}
+		if serviceVersion != "" {
+			fields = append(fields, syslogField{"version", "cs2", serviceVersion})
+		}
+		fields = append(fields, complianceFields(r.Compliance)...)
+		name := fmt.Sprintf("Open port %s:%d/%s", r.Host, r.Port, protocol)
+		if service != "" {
+			name += " " + service
+		}
+		return &syslogFinding{
+			signature: "port-open",
+			name:      name,
+			severity:  assessment.Severity,
+			time:      eventTime(r.Timestamp),
+			fields:    fields,
+		}
+	case *ops.DiscoverSummary:
+		return summaryFinding("discover-summary", fmt.Sprintf("Discovery finished: %d of %d hosts up",
+			r.HostsDiscovered, r.TargetsResolved), r.RunID, r.EndTime, r.HostsDiscovered)
+	case *ops.ScanSummary:
+		return summaryFinding("scan-summary", fmt.Sprintf("Port scan finished: %d open ports on %d hosts",
+			r.OpenPorts, r.TargetsCount), r.RunID, r.EndTime, r.OpenPorts)
+	case *ops.PacketSummary:
+		return summaryFinding("packet-summary", fmt.Sprintf("Packet run finished: %d of %d packets answered",
+			r.SuccessfulResponses, r.TotalPackets), r.RunID, r.EndTime, r.SuccessfulResponses)
+	}
+	return nil
+}
+
+// complianceFields carries the compliance class of a result, if it has one
+func complianceFields(label *ops.ComplianceLabel) []syslogField {
+	if label == nil {
+		return nil
+	}
+	fields := []syslogField{{"compliance", "cs3", label.Class}}
+	if label.Rule != "" {
+		fields = append(fields, syslogField{"compliance_rule", "cs4", label.Rule})
+	}
+	return fields
+}
+
+func summaryFinding(signature, name, runID string, end time.Time, count int) *syslogFinding {
+	return &syslogFinding{
+		signature: signature,
+		name:      name,
+		severity:  "info",
+		time:      eventTime(end),
+		fields: []syslogField{
+			{"run_id", "externalId", runID},
+			{"count", "cnt", strconv.Itoa(count)},
+		},
+	}
+}
+
+func eventTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
+
+// syslogSeverity maps a risk severity to an RFC 5424 severity
+func syslogSeverity(severity string) int {
+	switch severity {
+	case "critical":
+		return 2 // critical
+	case "high":
+		return 4 // warning
+	case "medium":
+		return 5 // notice
+	}
+	return 6 // informational
+}
+
+// cefSeverity maps a risk severity to the CEF 0-10 scale
+func cefSeverity(severity string) int {
+	switch severity {
+	case "critical":
+		return 10
+	case "high":
+		return 8
+	case "medium":
+		return 5
+	case "low":
+		return 3
+	}
+	return 1
+}
+
+// cefRecord renders a finding as a CEF:0 record
+func cefRecord(finding *syslogFinding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|NetCrate|NetCrate|%s|%s|%s|%d|",
+		escapeCEFHeader(version.Version), escapeCEFHeader(finding.signature),
+		escapeCEFHeader(finding.name), cefSeverity(finding.severity))
+
+	fmt.Fprintf(&b, "rt=%d", finding.time.UnixMilli())
+	for _, field := range finding.fields {
+		// Custom fields (cs1, cn1, ...) carry their meaning in a label
+		if len(field.cef) == 3 && (field.cef[:2] == "cs" || field.cef[:2] == "cn") && field.cef[2] >= '1' && field.cef[2] <= '9' {
+			fmt.Fprintf(&b, " %sLabel=%s", field.cef, escapeCEFValue(field.name))
+		}
+		fmt.Fprintf(&b, " %s=%s", field.cef, escapeCEFValue(field.value))
+	}
+	return b.String()
+}
+
+func escapeCEFHeader(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ").Replace(s)
+}
+
+func escapeCEFValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
+
+func escapeSDValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+// SyslogSink forwards findings to a syslog collector as they happen. UDP
+// sends one datagram per message; TCP uses octet-counting framing
+// (RFC 6587). After the first delivery failure further messages are
+// dropped and the error is reported by Close.
+type SyslogSink struct {
+	formatter *SyslogFormatter
+	framed    bool
+
+	mu   sync.Mutex
+	conn net.Conn
+	err  error
+}
+
+// DialSyslogSink connects to the configured collector
+func DialSyslogSink(cfg config.SyslogConfig) (*SyslogSink, error) {
+	formatter, err := NewSyslogFormatter(cfg.EffectiveFormat())
+	if err != nil {
+		return nil, err
+	}
+	protocol := cfg.EffectiveProtocol()
+	conn, err := net.DialTimeout(protocol, cfg.Address, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog collector %s: %w", cfg.Address, err)
+	}
+	return &SyslogSink{formatter: formatter, framed: protocol == "tcp", conn: conn}, nil
+}
+
+// Write sends the event if it is a finding
+func (s *SyslogSink) Write(eventType string, data interface{}) {
+	message, ok := s.formatter.Format(data)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return
+	}
+	if s.framed {
+		message = fmt.Sprintf("%d %s", len(message), message)
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(s.conn, message); err != nil {
+		s.err = fmt.Errorf("failed to send to syslog collector: %w", err)
+	}
+}
+
+// Close closes the connection and returns the first delivery error, if any
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	closeErr := s.conn.Close()
+	if s.err != nil {
+		return s.err
+	}
+	return closeErr
+}
+
+// WriteSyslogExport writes the findings of a saved quick, discover or scan
+// run as syslog or CEF lines, one per line, for replay into a collector
+func WriteSyslogExport(w io.Writer, record *store.RunRecord, format string) error {
+	if record.Type != store.TypeQuick && record.Type != store.TypeDiscover && record.Type != store.TypeScan {
+		return fmt.Errorf("%s runs have no findings to export", record.Type)
+	}
+	formatter, err := NewSyslogFormatter(format)
+	if err != nil {
+		return err
+	}
+	part, err := mergePart(record)
+	if err != nil {
+		return err
+	}
+
+	var events []interface{}
+	if part.DiscoverResult != nil {
+		for _, host := range part.DiscoverResult.Results {
+			events = append(events, host)
+		}
+	}
+	if part.ScanResult != nil {
+		for _, port := range part.ScanResult.Results {
+			events = append(events, port)
+		}
+	}
+
+	for _, event := range events {
+		if line, ok := formatter.Format(event); ok {
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
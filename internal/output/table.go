@@ -0,0 +1,385 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/quick"
+)
+
+// Column sets of the flat tables
+var (
+	PortColumns    = []string{"run_id", "host", "port", "protocol", "status", "service", "product", "version", "rtt_ms", "compliance", "compliance_rule"}
+	HostColumns    = []string{"run_id", "host", "status", "method", "rtt_ms", "hostname", "mac", "vendor", "open_ports", "compliance", "compliance_rule"}
+	ServiceColumns = []string{"run_id", "service", "protocol", "port", "host_count", "hosts"}
+)
+
+// Table is a flat, one-row-per-item view of a run
+type Table struct {
+	Columns []string
+	Headers map[string]string // display labels of columns printed under another name
+	Rows    []map[string]string
+}
+
+// RowFilter narrows a table. Empty fields match everything.
+type RowFilter struct {
+	Status     string   // e.g. "open", "closed", "up"
+	Hosts      []string // exact addresses
+	Ports      []int
+	Services   []string // case-insensitive
+	Compliance string   // compliance class: private, public or blocked
+}
+
+// IsZero reports whether the filter matches every row
+func (f RowFilter) IsZero() bool {
+	return f.Status == "" && len(f.Hosts) == 0 && len(f.Ports) == 0 && len(f.Services) == 0 && f.Compliance == ""
+}
+
+// PortTable flattens the port results of a quick or scan run
+func PortTable(record *store.RunRecord) (*Table, error) {
+	var results []ops.ScanResult
+	fingerprints := make(map[string][2]string)
+
+	switch record.Type {
+	case store.TypeQuick:
+		var result quick.QuickResult
+		if err := record.Decode(&result); err != nil {
+			return nil, err
+		}
+		if result.ScanResult != nil {
+			results = result.ScanResult.Results
+		}
+		for _, fp := range result.Fingerprints {
+			if fp != nil && fp.Service != "" && fp.Service != "unknown" {
+				fingerprints[fmt.Sprintf("%s:%d", fp.Host, fp.Port)] = [2]string{fp.Application, fp.Version}
+			}
+		}
+	case store.TypeScan:
+		var result ops.ScanSummary
+		if err := record.Decode(&result); err != nil {
+			return nil, err
+		}
+		results = result.Results
+	default:
+		return nil, fmt.Errorf("%s runs have no port results", record.Type)
+	}
+
+	return portRows(record.RunID, results, fingerprints), nil
+}
+
+// ScanTable flattens the results of a port scan that has just finished
+func ScanTable(result *ops.ScanSummary) *Table {
+	return portRows(result.RunID, result.Results, nil)
+}
+
+// portRows builds the port table; fingerprints maps host:port to the
+// product and version found for it
+func portRows(runID string, results []ops.ScanResult, fingerprints map[string][2]string) *Table {
+	table := &Table{Columns: PortColumns}
+	for _, r := range results {
+		row := map[string]string{
+			"run_id":   runID,
+			"host":     r.Host,
+			"port":     strconv.Itoa(r.Port),
+			"protocol": r.Protocol,
+			"status":   r.Status,
+			"rtt_ms":   fmt.Sprintf("%.1f", r.RTT),
+		}
+		if row["protocol"] == "" {
+			row["protocol"] = "tcp"
+		}
+		if r.Service != nil {
+			row["service"] = r.Service.Name
+			row["version"] = r.Service.Version
+		}
+		if r.Compliance != nil {
+			row["compliance"], row["compliance_rule"] = r.Compliance.Class, r.Compliance.Rule
+		}
+		if fp, ok := fingerprints[fmt.Sprintf("%s:%d", r.Host, r.Port)]; ok {
+			row["product"] = fp[0]
+			if fp[1] != "" {
+				row["version"] = fp[1]
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+// HostTable flattens the discovered hosts of a quick or discover run
+func HostTable(record *store.RunRecord) (*Table, error) {
+	var results []ops.DiscoverResult
+	devices := make(map[string]quick.DeviceInfo)
+	openPorts := make(map[string]int)
+
+	switch record.Type {
+	case store.TypeQuick:
+		var result quick.QuickResult
+		if err := record.Decode(&result); err != nil {
+			return nil, err
+		}
+		if result.DiscoverResult != nil {
+			results = result.DiscoverResult.Results
+		}
+		for _, device := range result.Summary.Devices {
+			devices[device.Host] = device
+		}
+		if result.ScanResult != nil {
+			for _, r := range result.ScanResult.Results {
+				if r.Status == "open" {
+					openPorts[r.Host]++
+				}
+			}
+		}
+	case store.TypeDiscover:
+		var result ops.DiscoverSummary
+		if err := record.Decode(&result); err != nil {
+			return nil, err
+		}
+		results = result.Results
+	default:
+		return nil, fmt.Errorf("%s runs have no host results", record.Type)
+	}
+
+	return hostRows(record.RunID, results, devices, openPorts), nil
+}
+
+// DiscoverTable flattens the results of a host discovery that has just finished
+func DiscoverTable(result *ops.DiscoverSummary) *Table {
+	return hostRows(result.RunID, result.Results, nil, nil)
+}
+
+// hostRows builds the host table from the discovered hosts, the device
+// details and the open port counts known for them
+func hostRows(runID string, results []ops.DiscoverResult, devices map[string]quick.DeviceInfo, openPorts map[string]int) *Table {
+	table := &Table{Columns: HostColumns}
+	for _, r := range results {
+		row := map[string]string{
+			"run_id":     runID,
+			"host":       r.Host,
+			"status":     r.Status,
+			"method":     r.Method,
+			"rtt_ms":     fmt.Sprintf("%.1f", r.RTT),
+			"hostname":   r.Hostname,
+			"open_ports": strconv.Itoa(openPorts[r.Host]),
+		}
+		if r.Compliance != nil {
+			row["compliance"], row["compliance_rule"] = r.Compliance.Class, r.Compliance.Rule
+		}
+		if device, ok := devices[r.Host]; ok {
+			row["mac"], row["vendor"] = device.MAC, device.Vendor
+			if row["hostname"] == "" {
+				row["hostname"] = device.MDNSName
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+// ServiceTable groups the open ports of a quick or scan run by service and port
+func ServiceTable(record *store.RunRecord) (*Table, error) {
+	ports, err := PortTable(record)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := make(map[string]map[string]string)
+	hosts := make(map[string][]string)
+	var keys []string
+	for _, row := range ports.Filter(RowFilter{Status: "open"}).Rows {
+		service := row["service"]
+		if service == "" {
+			service = "unknown"
+		}
+		key := service + "/" + row["protocol"] + "/" + row["port"]
+		if _, ok := grouped[key]; !ok {
+			grouped[key] = map[string]string{
+				"run_id":   record.RunID,
+				"service":  service,
+				"protocol": row["protocol"],
+				"port":     row["port"],
+			}
+			keys = append(keys, key)
+		}
+		hosts[key] = append(hosts[key], row["host"])
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := grouped[keys[i]], grouped[keys[j]]
+		if a["service"] != b["service"] {
+			return a["service"] < b["service"]
+		}
+		portA, _ := strconv.Atoi(a["port"])
+		portB, _ := strconv.Atoi(b["port"])
+		return portA < portB
+	})
+
+	table := &Table{Columns: ServiceColumns}
+	for _, key := range keys {
+		row := grouped[key]
+		sort.Slice(hosts[key], func(i, j int) bool { return compareIPs(hosts[key][i], hosts[key][j]) })
+		row["host_count"] = strconv.Itoa(len(hosts[key]))
+		row["hosts"] = strings.Join(hosts[key], " ")
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// RunTable returns the named flat table ("ports", "hosts" or "services") of a run
+func RunTable(record *store.RunRecord, name string) (*Table, error) {
+	switch name {
+	case "ports":
+		return PortTable(record)
+	case "hosts":
+		return HostTable(record)
+	case "services":
+		return ServiceTable(record)
+	default:
+		return nil, fmt.Errorf("unknown table: %s (expected ports, hosts or services)", name)
+	}
+}
+
+// WriteCSV writes the table with a header row. Cells that a spreadsheet
+// would run as a formula are neutralised, since banners, products and
+// versions come from the scanned hosts.
+func WriteCSV(w io.Writer, t *Table) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(t.Columns); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		values := make([]string, len(t.Columns))
+		for i, column := range t.Columns {
+			values[i] = csvCell(row[column])
+		}
+		if err := writer.Write(values); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvCell prefixes a value starting with a formula character (=, +, -, @,
+// tab or carriage return) with a quote, so spreadsheets show it as text.
+// Numbers such as -1 are left alone.
+func csvCell(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + value
+}
+
+// Filter returns the rows matching f
+func (t *Table) Filter(f RowFilter) *Table {
+	filtered := &Table{Columns: t.Columns}
+	for _, row := range t.Rows {
+		if f.matches(row) {
+			filtered.Rows = append(filtered.Rows, row)
+		}
+	}
+	return filtered
+}
+
+// matches applies each non-empty criterion to a row
+func (f RowFilter) matches(row map[string]string) bool {
+	if f.Status != "" && !strings.EqualFold(row["status"], f.Status) {
+		return false
+	}
+	if f.Compliance != "" && !strings.EqualFold(row["compliance"], f.Compliance) {
+		return false
+	}
+	if len(f.Hosts) > 0 && !containsString(f.Hosts, row["host"]) {
+		return false
+	}
+	if len(f.Ports) > 0 {
+		port, _ := strconv.Atoi(row["port"])
+		found := false
+		for _, p := range f.Ports {
+			if p == port {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Services) > 0 {
+		found := false
+		for _, service := range f.Services {
+			if strings.EqualFold(service, row["service"]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Select keeps only the given columns, dropping rows that become duplicates
+func (t *Table) Select(fields []string) (*Table, error) {
+	for _, field := range fields {
+		if !containsString(t.Columns, field) {
+			return nil, fmt.Errorf("unknown field %q (available: %s)", field, strings.Join(t.Columns, ", "))
+		}
+	}
+
+	selected := &Table{Columns: fields}
+	seen := make(map[string]bool)
+	for _, row := range t.Rows {
+		values := make([]string, len(fields))
+		for i, field := range fields {
+			values[i] = row[field]
+		}
+		key := strings.Join(values, "\x00")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		newRow := make(map[string]string, len(fields))
+		for i, field := range fields {
+			newRow[field] = values[i]
+		}
+		selected.Rows = append(selected.Rows, newRow)
+	}
+	return selected, nil
+}
+
+// MarshalJSON renders the rows as objects with only the table's columns
+func (t *Table) MarshalJSON() ([]byte, error) {
+	rows := make([]map[string]string, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		object := make(map[string]string, len(t.Columns))
+		for _, column := range t.Columns {
+			object[column] = row[column]
+		}
+		rows = append(rows, object)
+	}
+	return json.Marshal(rows)
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
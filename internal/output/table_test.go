@@ -0,0 +1,42 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+func TestWriteCSVNeutralisesFormulas(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"OpenSSH", "OpenSSH"},
+		{"=HYPERLINK(\"http://evil\",\"x\")", "'=HYPERLINK(\"http://evil\",\"x\")"},
+		{"+cmd|' /C calc'!A0", "'+cmd|' /C calc'!A0"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tx", "'\tx"},
+		{"-1", "-1"},
+		{"1.2.3", "1.2.3"},
+		{"", ""},
+	}
+	table := &Table{Columns: []string{"host", "version"}}
+	for _, tt := range tests {
+		table.Rows = append(table.Rows, map[string]string{"host": "10.0.0.1", "version": tt.value})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range tests {
+		if got := records[i+1][1]; got != tt.want {
+			t.Errorf("cell %q written as %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
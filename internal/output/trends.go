@@ -0,0 +1,199 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/i18n"
+)
+
+// RunTrendPoint holds the headline numbers of one quick run
+type RunTrendPoint struct {
+	RunID     string         `json:"run_id"`
+	StartTime time.Time      `json:"start_time"`
+	Target    string         `json:"target"`
+	Hosts     int            `json:"hosts"`
+	OpenPorts int            `json:"open_ports"`
+	Critical  int            `json:"critical"`
+	Services  map[string]int `json:"services"`
+}
+
+// HostTrend tracks when a host was seen across runs
+type HostTrend struct {
+	Host      string    `json:"host"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	SeenIn    int       `json:"seen_in"`
+	OpenPorts int       `json:"open_ports"` // in the latest run that saw the host
+}
+
+// ServiceTrend tracks how often a service was seen in each run
+type ServiceTrend struct {
+	Service string `json:"service"`
+	Counts  []int  `json:"counts"` // one entry per run, oldest first
+}
+
+// QuickTrends aggregates several quick runs, oldest first
+type QuickTrends struct {
+	Runs     []RunTrendPoint `json:"runs"`
+	Hosts    []HostTrend     `json:"hosts"`
+	Services []ServiceTrend  `json:"services"`
+}
+
+// ComputeQuickTrends aggregates the last limit completed quick runs
+func ComputeQuickTrends(limit int) (*QuickTrends, error) {
+	runs, err := ListRuns()
+	if err != nil {
+		return nil, err
+	}
+
+	trends := &QuickTrends{}
+	hosts := make(map[string]*HostTrend)
+	serviceNames := make(map[string]bool)
+
+	// ListRuns returns newest first; collect up to limit completed quick runs
+	var selected []RunInfo
+	for _, run := range runs {
+		if run.Type != "quick" {
+			continue
+		}
+		if limit > 0 && len(selected) >= limit {
+			break
+		}
+		selected = append(selected, run)
+	}
+
+	for i := len(selected) - 1; i >= 0; i-- {
+		result, err := LoadQuickResult(&selected[i])
+		if err != nil || result.Status == "partial" {
+			continue
+		}
+
+		point := RunTrendPoint{
+			RunID:     result.RunID,
+			StartTime: result.StartTime,
+			Target:    result.TargetCIDR,
+			Hosts:     result.Summary.HostsDiscovered,
+			OpenPorts: result.Summary.OpenPorts,
+			Critical:  len(result.Summary.CriticalPorts),
+			Services:  result.Summary.TopServices,
+		}
+		trends.Runs = append(trends.Runs, point)
+
+		for service := range point.Services {
+			serviceNames[service] = true
+		}
+
+		portsByHost := make(map[string]int)
+		if result.ScanResult != nil {
+			for _, portResult := range result.ScanResult.Results {
+				if portResult.Status == "open" {
+					portsByHost[portResult.Host]++
+				}
+			}
+		}
+
+		for _, host := range result.Summary.LiveHosts {
+			trend, ok := hosts[host]
+			if !ok {
+				trend = &HostTrend{Host: host, FirstSeen: result.StartTime}
+				hosts[host] = trend
+			}
+			trend.LastSeen = result.StartTime
+			trend.SeenIn++
+			trend.OpenPorts = portsByHost[host]
+		}
+	}
+
+	if len(trends.Runs) == 0 {
+		return nil, fmt.Errorf("no completed quick runs found")
+	}
+
+	for _, trend := range hosts {
+		trends.Hosts = append(trends.Hosts, *trend)
+	}
+	sort.Slice(trends.Hosts, func(i, j int) bool {
+		if !trends.Hosts[i].LastSeen.Equal(trends.Hosts[j].LastSeen) {
+			return trends.Hosts[i].LastSeen.After(trends.Hosts[j].LastSeen)
+		}
+		return trends.Hosts[i].Host < trends.Hosts[j].Host
+	})
+
+	for service := range serviceNames {
+		trend := ServiceTrend{Service: service}
+		for _, point := range trends.Runs {
+			trend.Counts = append(trend.Counts, point.Services[service])
+		}
+		trends.Services = append(trends.Services, trend)
+	}
+	sort.Slice(trends.Services, func(i, j int) bool {
+		a, b := trends.Services[i], trends.Services[j]
+		lastA, lastB := a.Counts[len(a.Counts)-1], b.Counts[len(b.Counts)-1]
+		if lastA != lastB {
+			return lastA > lastB
+		}
+		return a.Service < b.Service
+	})
+
+	return trends, nil
+}
+
+// PrintQuickTrends displays run, service and host trends
+func PrintQuickTrends(trends *QuickTrends) {
+	fmt.Print(i18n.T("output.trends.title", len(trends.Runs)))
+	fmt.Println("========================")
+	fmt.Printf("%-20s %-17s %-12s %-12s %s\n",
+		i18n.T("output.list.col.run_id"), i18n.T("output.list.col.date"),
+		i18n.T("output.trends.col.hosts"), i18n.T("output.trends.col.ports"), i18n.T("output.trends.col.critical"))
+	fmt.Println(strings.Repeat("-", 75))
+
+	for i, point := range trends.Runs {
+		hosts, ports := fmt.Sprintf("%d", point.Hosts), fmt.Sprintf("%d", point.OpenPorts)
+		if i > 0 {
+			hosts += formatDelta(point.Hosts - trends.Runs[i-1].Hosts)
+			ports += formatDelta(point.OpenPorts - trends.Runs[i-1].OpenPorts)
+		}
+		fmt.Printf("%-20s %-17s %-12s %-12s %d\n",
+			point.RunID, point.StartTime.Format("2006-01-02 15:04"), hosts, ports, point.Critical)
+	}
+
+	if len(trends.Services) > 0 {
+		fmt.Println(i18n.T("output.trends.services"))
+		for i, service := range trends.Services {
+			if i >= 10 {
+				break
+			}
+			counts := make([]string, len(service.Counts))
+			for j, count := range service.Counts {
+				counts[j] = fmt.Sprintf("%d", count)
+			}
+			fmt.Printf("  • %-14s %s\n", service.Service, strings.Join(counts, " → "))
+		}
+	}
+
+	if len(trends.Hosts) > 0 {
+		fmt.Println(i18n.T("output.trends.hosts"))
+		fmt.Printf("  %-16s %-17s %-17s %-8s %s\n",
+			i18n.T("output.trends.col.host"), i18n.T("output.trends.col.first_seen"),
+			i18n.T("output.trends.col.last_seen"), i18n.T("output.trends.col.seen_in"), i18n.T("output.trends.col.ports"))
+		for _, host := range trends.Hosts {
+			fmt.Printf("  %-16s %-17s %-17s %-8s %d\n",
+				host.Host, host.FirstSeen.Format("2006-01-02 15:04"), host.LastSeen.Format("2006-01-02 15:04"),
+				fmt.Sprintf("%d/%d", host.SeenIn, len(trends.Runs)), host.OpenPorts)
+		}
+	}
+}
+
+// formatDelta renders a change relative to the previous run, e.g. " (+2)"
+func formatDelta(delta int) string {
+	switch {
+	case delta > 0:
+		return fmt.Sprintf(" (+%d)", delta)
+	case delta < 0:
+		return fmt.Sprintf(" (%d)", delta)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,115 @@
+package privileges
+
+import (
+	"os/exec"
+	"runtime"
+
+	"github.com/netcrate/netcrate/internal/netenv"
+)
+
+// Features a command may need, as checked by CheckFeatures
+const (
+	FeatureSYNScan    = "syn_scan"
+	FeatureICMP       = "icmp"
+	FeatureTCPConnect = "tcp_connect"
+	FeatureUDPScan    = "udp_scan"
+	FeatureARPCache   = "arp"
+	FeatureNeighbors  = "neighbors"
+	FeatureTraceroute = "traceroute"
+)
+
+// Outcomes of a feature check
+const (
+	FeatureWorks       = "works"
+	FeatureFallsBack   = "fallback"
+	FeatureUnavailable = "unavailable"
+)
+
+// FeatureCheck says whether a feature will work as asked, what runs instead
+// when it does not and how to enable it
+type FeatureCheck struct {
+	Feature  string `json:"feature"`
+	Status   string `json:"status"`             // works, fallback or unavailable
+	Fallback string `json:"fallback,omitempty"` // what runs instead
+	Reason   string `json:"reason,omitempty"`
+	Remedy   string `json:"remedy,omitempty"`
+}
+
+// CheckFeatures reports, for each feature, what a run would actually do
+// with the current privileges, naming the fallbacks a run takes silently
+func (pm *PrivilegeManager) CheckFeatures(features []string) []FeatureCheck {
+	matrix := netenv.DetectCapabilityMatrix()
+	raw := netenv.LookupCapability(matrix, netenv.CapRawIPv4)
+
+	var checks []FeatureCheck
+	for _, feature := range features {
+		check := FeatureCheck{Feature: feature, Status: FeatureWorks}
+		switch feature {
+		case FeatureSYNScan:
+			if pm.HasCapability(CapabilitySYN) {
+				break
+			}
+			check.Status, check.Fallback = FeatureFallsBack, "TCP connect scan"
+			if runtime.GOOS == "windows" && pm.hasRawSocket && !pm.hasNpcap {
+				check.Reason = "Windows blocks raw TCP sends without Npcap"
+				check.Remedy = "install Npcap from https://npcap.com"
+			} else {
+				check.Reason, check.Remedy = raw.Error, raw.Remedy
+			}
+
+		case FeatureICMP:
+			if pm.HasCapability(CapabilityICMP) {
+				break
+			}
+			check.Status, check.Fallback = FeatureFallsBack, "TCP connect probes only"
+			if pm.HasCapability(CapabilitySystemPing) {
+				check.Fallback = "the system ping command"
+			}
+			check.Reason, check.Remedy = raw.Error, raw.Remedy
+			if runtime.GOOS != "windows" {
+				check.Remedy += ", or run discover with --privileged-helper"
+			}
+
+		case FeatureTCPConnect:
+
+		case FeatureUDPScan:
+			if !pm.HasCapability(CapabilityUDP) {
+				check.Status, check.Reason = FeatureUnavailable, "UDP sockets cannot be opened"
+			}
+
+		case FeatureARPCache:
+			// Reads the system ARP cache, which needs no privileges
+			if _, err := exec.LookPath("arp"); err != nil {
+				check.Status, check.Reason = FeatureUnavailable, "the arp command is not installed"
+			}
+
+		case FeatureNeighbors:
+			switch runtime.GOOS {
+			case "linux", "darwin", "dragonfly", "freebsd", "netbsd", "openbsd":
+				if pcap := netenv.LookupCapability(matrix, netenv.CapPacketCapture); !pcap.Available {
+					check.Status, check.Reason, check.Remedy = FeatureUnavailable, pcap.Error, pcap.Remedy
+				}
+			default:
+				check.Status, check.Reason = FeatureUnavailable, "listening for LLDP/CDP is only supported on Linux, macOS and the BSDs"
+			}
+
+		case FeatureTraceroute:
+			if raw.Available {
+				break
+			}
+			datagram := netenv.LookupCapability(matrix, netenv.CapICMPDatagram)
+			if datagram.Available {
+				check.Status = FeatureFallsBack
+				check.Fallback = "an unprivileged ICMP datagram socket, where intermediate hops may not answer"
+			} else {
+				check.Status = FeatureUnavailable
+			}
+			check.Reason, check.Remedy = raw.Error, raw.Remedy
+
+		default:
+			check.Status, check.Reason = FeatureUnavailable, "unknown feature"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
@@ -0,0 +1,79 @@
+package privileges
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PrivilegeDrop records the switch from root to the user who ran sudo
+type PrivilegeDrop struct {
+	FromUID   int       `json:"from_uid"`
+	ToUID     int       `json:"to_uid"`
+	ToGID     int       `json:"to_gid"`
+	User      string    `json:"user,omitempty"`
+	Home      string    `json:"home,omitempty"`      // HOME from then on
+	Preopened []string  `json:"preopened,omitempty"` // raw sockets kept across the drop
+	At        time.Time `json:"at"`
+}
+
+var (
+	dropMu      sync.Mutex
+	currentDrop *PrivilegeDrop
+)
+
+// DropPrivileges opens the PreopenedNetworks and then switches from root to
+// the user who invoked sudo, named by SUDO_UID and SUDO_GID, for the rest
+// of the run. HOME moves to that user's home, so that configuration, saved
+// runs and the audit log are theirs. It returns nil without doing anything
+// when the process is not root or was not started through sudo.
+func DropPrivileges() (*PrivilegeDrop, error) {
+	if !isElevated() || os.Getenv("SUDO_UID") == "" || os.Getenv("SUDO_UID") == "0" {
+		return nil, nil
+	}
+	uid, err := strconv.Atoi(os.Getenv("SUDO_UID"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SUDO_UID: %w", err)
+	}
+	gid, err := strconv.Atoi(os.Getenv("SUDO_GID"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SUDO_GID: %w", err)
+	}
+
+	drop := &PrivilegeDrop{FromUID: os.Geteuid(), ToUID: uid, ToGID: gid}
+	var groups []int
+	if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		drop.User, drop.Home = u.Username, u.HomeDir
+		if ids, err := u.GroupIds(); err == nil {
+			for _, id := range ids {
+				if g, err := strconv.Atoi(id); err == nil {
+					groups = append(groups, g)
+				}
+			}
+		}
+	}
+
+	drop.Preopened = PreopenRawSockets(PreopenedNetworks)
+	if err := switchUser(uid, gid, groups); err != nil {
+		return nil, fmt.Errorf("failed to drop privileges to uid %d: %w", uid, err)
+	}
+	if drop.Home != "" {
+		os.Setenv("HOME", drop.Home)
+	}
+	drop.At = time.Now()
+
+	dropMu.Lock()
+	currentDrop = drop
+	dropMu.Unlock()
+	return drop, nil
+}
+
+// CurrentDrop returns the privilege drop of this process, or nil
+func CurrentDrop() *PrivilegeDrop {
+	dropMu.Lock()
+	defer dropMu.Unlock()
+	return currentDrop
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package privileges
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// switchUser sets the groups, then the group and user IDs, and checks that
+// root cannot be regained
+func switchUser(uid, gid int, groups []int) error {
+	if err := syscall.Setgroups(groups); err != nil {
+		return err
+	}
+	if err := syscall.Setgid(gid); err != nil {
+		return err
+	}
+	if err := syscall.Setuid(uid); err != nil {
+		return err
+	}
+	if syscall.Setuid(0) == nil {
+		return fmt.Errorf("root could be regained after setuid")
+	}
+	return nil
+}
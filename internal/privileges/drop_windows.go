@@ -0,0 +1,8 @@
+package privileges
+
+import "fmt"
+
+// switchUser is not possible on Windows, where sudo does not start netcrate
+func switchUser(uid, gid int, groups []int) error {
+	return fmt.Errorf("dropping privileges is not supported on Windows")
+}
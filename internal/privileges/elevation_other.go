@@ -0,0 +1,22 @@
+//go:build !windows
+
+package privileges
+
+import (
+	"os"
+	"syscall"
+)
+
+// isElevated reports whether the process runs as root
+func isElevated() bool {
+	return os.Geteuid() == 0
+}
+
+// openRawSocket opens and closes a raw ICMP socket
+func openRawSocket() error {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_ICMP)
+	if err != nil {
+		return err
+	}
+	return syscall.Close(fd)
+}
@@ -0,0 +1,23 @@
+package privileges
+
+import (
+	"net"
+
+	"golang.org/x/sys/windows"
+)
+
+// isElevated reports whether the process token is elevated, i.e. running
+// as Administrator past UAC
+func isElevated() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
+
+// openRawSocket opens and closes a raw ICMP socket. Administrators may open
+// raw IP sockets on Windows, though not send TCP through them.
+func openRawSocket() error {
+	conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
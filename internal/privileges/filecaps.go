@@ -0,0 +1,94 @@
+package privileges
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Linux capabilities that matter to netcrate, by their setcap names
+const (
+	FileCapNetBindService = "cap_net_bind_service"
+	FileCapNetAdmin       = "cap_net_admin"
+	FileCapNetRaw         = "cap_net_raw"
+)
+
+// GrantedFileCapabilities are what "netcrate privileges grant" sets: raw
+// sockets for SYN scans and ICMP, and admin for promiscuous capture
+var GrantedFileCapabilities = []string{FileCapNetRaw, FileCapNetAdmin}
+
+// fileCapBits maps the capabilities above to their bit in the kernel's
+// capability sets
+var fileCapBits = map[string]uint{
+	FileCapNetBindService: 10,
+	FileCapNetAdmin:       12,
+	FileCapNetRaw:         13,
+}
+
+const (
+	vfsCapRevisionMask  = 0xff000000
+	vfsCapRevision1     = 0x01000000
+	vfsCapFlagEffective = 0x000001
+)
+
+// BinaryPath returns the resolved path of the running netcrate binary
+func BinaryPath() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// FileCapabilities lists the capabilities a binary gains when executed:
+// those in the permitted set of its security.capability attribute, provided
+// the effective flag is set as "setcap ...+ep" does. It returns nil on
+// platforms without file capabilities.
+func FileCapabilities(path string) ([]string, error) {
+	data, err := readCapabilityXattr(path)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return parseVFSCapData(data)
+}
+
+// parseVFSCapData reads a vfs_cap_data attribute: a little-endian magic
+// holding the revision and flags, then the permitted and inheritable sets
+// (the low 32 bits are enough for the network capabilities)
+func parseVFSCapData(data []byte) ([]string, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("security.capability too short (%d bytes)", len(data))
+	}
+	magic := binary.LittleEndian.Uint32(data[0:4])
+	if magic&vfsCapRevisionMask < vfsCapRevision1 {
+		return nil, fmt.Errorf("unknown security.capability revision %#x", magic&vfsCapRevisionMask)
+	}
+	if magic&vfsCapFlagEffective == 0 {
+		return nil, nil
+	}
+	permitted := binary.LittleEndian.Uint32(data[4:8])
+
+	var caps []string
+	for _, name := range []string{FileCapNetBindService, FileCapNetAdmin, FileCapNetRaw} {
+		if permitted&(1<<fileCapBits[name]) != 0 {
+			caps = append(caps, name)
+		}
+	}
+	return caps, nil
+}
+
+// GrantCommand returns the setcap command line that gives a binary the
+// GrantedFileCapabilities, prefixed with sudo unless running as root
+func GrantCommand(path string) ([]string, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("file capabilities are a Linux feature; on %s run netcrate with elevated privileges instead", runtime.GOOS)
+	}
+	command := []string{"setcap", strings.Join(GrantedFileCapabilities, ",") + "+ep", path}
+	if !isElevated() {
+		command = append([]string{"sudo"}, command...)
+	}
+	return command, nil
+}
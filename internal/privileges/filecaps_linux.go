@@ -0,0 +1,21 @@
+package privileges
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// readCapabilityXattr returns the security.capability attribute of a file,
+// or nil when it has none
+func readCapabilityXattr(path string) ([]byte, error) {
+	buffer := make([]byte, 64)
+	n, err := unix.Getxattr(path, "security.capability", buffer)
+	if errors.Is(err, unix.ENODATA) || errors.Is(err, unix.ENOTSUP) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buffer[:n], nil
+}
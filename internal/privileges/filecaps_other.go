@@ -0,0 +1,8 @@
+//go:build !linux
+
+package privileges
+
+// readCapabilityXattr returns nil: file capabilities only exist on Linux
+func readCapabilityXattr(path string) ([]byte, error) {
+	return nil, nil
+}
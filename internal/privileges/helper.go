@@ -0,0 +1,383 @@
+package privileges
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HelperStartTimeout bounds how long StartHelper waits for the helper to
+// listen, which includes typing the sudo password
+const HelperStartTimeout = 60 * time.Second
+
+// helperNetworks are the only sockets the helper opens: raw IP sockets for
+// ICMP, TCP and UDP probes
+var helperNetworks = map[string]bool{
+	"ip4:icmp":      true,
+	"ip6:ipv6-icmp": true,
+	"ip4:tcp":       true,
+	"ip6:tcp":       true,
+	"ip4:udp":       true,
+	"ip6:udp":       true,
+}
+
+// Messages between the main process and the helper
+const (
+	helperOpControl = "control" // holds the helper alive; it exits when this connection closes
+	helperOpOpen    = "open"    // opens a raw socket for the connection
+	helperOpOpened  = "opened"
+	helperOpSend    = "send"
+	helperOpPacket  = "packet" // a packet the raw socket received
+	helperOpError   = "error"
+)
+
+// helperMessage is one JSON message on a helper connection
+type helperMessage struct {
+	Op      string `json:"op"`
+	Network string `json:"network,omitempty"`
+	Address string `json:"address,omitempty"` // local address to open, or the peer of a packet
+	Payload []byte `json:"payload,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Helper is a privileged helper process that sends and receives raw packets
+// for an unprivileged netcrate. It is started once through sudo and serves
+// a unix socket in a directory only the invoking user can enter; nothing but
+// raw socket I/O runs with root.
+type Helper struct {
+	dir     string
+	socket  string
+	cmd     *exec.Cmd
+	control net.Conn
+}
+
+var (
+	activeHelperMu sync.Mutex
+	activeHelper   *Helper
+)
+
+// StartHelper starts the privileged helper with sudo, which may prompt for
+// a password on the terminal, and waits until it accepts connections
+func StartHelper() (*Helper, error) {
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("the privileged helper needs sudo; run netcrate as Administrator instead")
+	}
+	binary, err := BinaryPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the netcrate binary: %w", err)
+	}
+	dir, err := os.MkdirTemp("", "netcrate-helper-")
+	if err != nil {
+		return nil, err
+	}
+	h := &Helper{dir: dir, socket: filepath.Join(dir, "helper.sock")}
+
+	h.cmd = exec.Command("sudo", binary, "privileges", "helper", "--socket", h.socket)
+	h.cmd.Stdin, h.cmd.Stdout, h.cmd.Stderr = os.Stdin, os.Stderr, os.Stderr
+	if err := h.cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to start the privileged helper: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- h.cmd.Wait() }()
+
+	deadline := time.Now().Add(HelperStartTimeout)
+	for {
+		conn, err := net.Dial("unix", h.socket)
+		if err == nil {
+			if err = json.NewEncoder(conn).Encode(helperMessage{Op: helperOpControl}); err == nil {
+				h.control = conn
+				return h, nil
+			}
+			conn.Close()
+		}
+		select {
+		case err := <-exited:
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("privileged helper exited: %v", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			h.cmd.Process.Kill()
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("privileged helper did not start within %s", HelperStartTimeout)
+		}
+	}
+}
+
+// ListenPacket opens a raw socket in the helper, as net.ListenPacket would;
+// network is one of ip4:icmp, ip6:ipv6-icmp, ip4:tcp, ip6:tcp, ip4:udp or
+// ip6:udp
+func (h *Helper) ListenPacket(network, address string) (net.PacketConn, error) {
+	conn, err := net.Dial("unix", h.socket)
+	if err != nil {
+		return nil, fmt.Errorf("privileged helper unreachable: %w", err)
+	}
+	decoder := json.NewDecoder(conn)
+	if err := json.NewEncoder(conn).Encode(helperMessage{Op: helperOpOpen, Network: network, Address: address}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	var reply helperMessage
+	if err := decoder.Decode(&reply); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("privileged helper: %w", err)
+	}
+	if reply.Op != helperOpOpened {
+		conn.Close()
+		return nil, fmt.Errorf("privileged helper: %s", reply.Error)
+	}
+	return newHelperConn(conn, decoder), nil
+}
+
+// Close stops the helper and removes its socket directory
+func (h *Helper) Close() error {
+	h.control.Close()
+	done := make(chan struct{})
+	go func() {
+		h.cmd.Process.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+	}
+	return os.RemoveAll(h.dir)
+}
+
+// UseHelper makes ListenRawPacket fall back to a helper, or stops it with nil
+func UseHelper(h *Helper) {
+	activeHelperMu.Lock()
+	defer activeHelperMu.Unlock()
+	activeHelper = h
+}
+
+// ActiveHelper returns the helper set with UseHelper, or nil
+func ActiveHelper() *Helper {
+	activeHelperMu.Lock()
+	defer activeHelperMu.Unlock()
+	return activeHelper
+}
+
+// ListenRawPacket opens a raw socket: a view of one opened before
+// privileges were dropped, a socket of its own when the process may, or
+// one in the active privileged helper
+func ListenRawPacket(network, address string) (net.PacketConn, error) {
+	if socket := preopenedRawSocket(network); socket != nil {
+		return socket.open(), nil
+	}
+	conn, err := net.ListenPacket(network, address)
+	if err == nil {
+		return conn, nil
+	}
+	if h := ActiveHelper(); h != nil {
+		return h.ListenPacket(network, address)
+	}
+	return nil, err
+}
+
+// ServeHelper runs the helper side: it listens on socketPath, which is
+// handed to the invoking sudo user, and relays raw packets for each
+// connection until the control connection closes
+func ServeHelper(socketPath string) error {
+	if !isElevated() {
+		return fmt.Errorf("the privileged helper must run as root")
+	}
+	uid, _ := strconv.Atoi(os.Getenv("SUDO_UID"))
+	gid, _ := strconv.Atoi(os.Getenv("SUDO_GID"))
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	if err := os.Chown(socketPath, uid, gid); err != nil {
+		return err
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		<-done
+		listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-done:
+				return nil
+			default:
+				return err
+			}
+		}
+		if err := checkHelperPeer(conn, uid); err != nil {
+			conn.Close()
+			continue
+		}
+		go func() {
+			if serveHelperConn(conn) {
+				once.Do(func() { close(done) })
+			}
+		}()
+	}
+}
+
+// serveHelperConn serves one connection, reporting whether it was the
+// control connection
+func serveHelperConn(conn net.Conn) bool {
+	defer conn.Close()
+	decoder := json.NewDecoder(conn)
+	var first helperMessage
+	if err := decoder.Decode(&first); err != nil {
+		return false
+	}
+
+	var mu sync.Mutex
+	encoder := json.NewEncoder(conn)
+	reply := func(message helperMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return encoder.Encode(message)
+	}
+
+	switch first.Op {
+	case helperOpControl:
+		// Block until the main process goes away
+		var message helperMessage
+		for decoder.Decode(&message) == nil {
+		}
+		return true
+	case helperOpOpen:
+	default:
+		reply(helperMessage{Op: helperOpError, Error: "unknown operation " + first.Op})
+		return false
+	}
+
+	if !helperNetworks[first.Network] {
+		reply(helperMessage{Op: helperOpError, Error: "network not allowed: " + first.Network})
+		return false
+	}
+	raw, err := net.ListenPacket(first.Network, first.Address)
+	if err != nil {
+		reply(helperMessage{Op: helperOpError, Error: err.Error()})
+		return false
+	}
+	defer raw.Close()
+	if reply(helperMessage{Op: helperOpOpened}) != nil {
+		return false
+	}
+
+	go func() {
+		buffer := make([]byte, 65536)
+		for {
+			n, from, err := raw.ReadFrom(buffer)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			if reply(helperMessage{Op: helperOpPacket, Address: from.String(), Payload: buffer[:n]}) != nil {
+				return
+			}
+		}
+	}()
+	for {
+		var message helperMessage
+		if err := decoder.Decode(&message); err != nil {
+			return false
+		}
+		if message.Op != helperOpSend {
+			continue
+		}
+		to, err := net.ResolveIPAddr("ip", message.Address)
+		if err == nil {
+			_, err = raw.WriteTo(message.Payload, to)
+		}
+		if err != nil {
+			reply(helperMessage{Op: helperOpError, Error: err.Error()})
+		}
+	}
+}
+
+// helperConn is a raw socket in the helper, seen as a net.PacketConn
+type helperConn struct {
+	*packetQueue
+	conn    net.Conn
+	mu      sync.Mutex
+	encoder *json.Encoder
+
+	errMu     sync.Mutex
+	lastError error
+}
+
+func newHelperConn(conn net.Conn, decoder *json.Decoder) *helperConn {
+	c := &helperConn{
+		packetQueue: newPacketQueue(),
+		conn:        conn,
+		encoder:     json.NewEncoder(conn),
+	}
+	go func() {
+		defer c.Close()
+		for {
+			var message helperMessage
+			if err := decoder.Decode(&message); err != nil {
+				return
+			}
+			switch message.Op {
+			case helperOpPacket:
+				from, _ := net.ResolveIPAddr("ip", message.Address)
+				c.push(from, message.Payload)
+			case helperOpError:
+				c.errMu.Lock()
+				c.lastError = errors.New(message.Error)
+				c.errMu.Unlock()
+			}
+		}
+	}()
+	return c
+}
+
+func (c *helperConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	c.errMu.Lock()
+	err := c.lastError
+	c.lastError = nil
+	c.errMu.Unlock()
+	if err != nil {
+		// A send failed in the helper after the last WriteTo returned
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.encoder.Encode(helperMessage{Op: helperOpSend, Address: addr.String(), Payload: b}); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *helperConn) Close() error {
+	c.close()
+	return c.conn.Close()
+}
+
+func (c *helperConn) LocalAddr() net.Addr { return c.conn.LocalAddr() }
+
+func (c *helperConn) SetDeadline(t time.Time) error {
+	c.SetReadDeadline(t)
+	return c.conn.SetWriteDeadline(t)
+}
+
+func (c *helperConn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
@@ -0,0 +1,31 @@
+package privileges
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// checkHelperPeer admits only the invoking user (or root) to the helper,
+// by the credentials the kernel records for a unix socket peer
+func checkHelperPeer(conn net.Conn, uid int) error {
+	raw, err := conn.(*net.UnixConn).SyscallConn()
+	if err != nil {
+		return err
+	}
+	var cred *unix.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return err
+	}
+	if credErr != nil {
+		return credErr
+	}
+	if int(cred.Uid) != uid && cred.Uid != 0 {
+		return fmt.Errorf("peer uid %d is not %d", cred.Uid, uid)
+	}
+	return nil
+}
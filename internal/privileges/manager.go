@@ -3,11 +3,13 @@ package privileges
 import (
 	"fmt"
 	"net"
-	"os"
 	"os/exec"
 	"runtime"
-	"syscall"
+	"strings"
 	"time"
+
+	"github.com/netcrate/netcrate/internal/netenv"
+	"github.com/netcrate/netcrate/internal/style"
 )
 
 // PrivilegeLevel represents the current privilege level
@@ -41,6 +43,9 @@ type PrivilegeManager struct {
 	isRoot             bool
 	hasRawSocket       bool
 	hasICMPSocket      bool
+	hasNpcap           bool     // Windows: raw TCP sends are blocked without it
+	fileCapabilities   []string // Linux: capabilities set on the binary with setcap
+	viaHelper          bool     // raw sockets come from the privileged helper
 	canPing            bool
 	canCreateRawSocket bool
 }
@@ -74,12 +79,22 @@ func (pm *PrivilegeManager) detectPrivileges() {
 	// Check if running as root/admin
 	pm.isRoot = pm.checkRootPrivileges()
 	
+	// Without root, capabilities on the binary may still grant raw sockets
+	if binary, err := BinaryPath(); err == nil {
+		pm.fileCapabilities, _ = FileCapabilities(binary)
+	}
+	
 	// Test raw socket creation
 	pm.hasRawSocket = pm.testRawSocketCapability()
 	pm.capabilities[CapabilityRawSocket] = pm.hasRawSocket
 	
-	// Test ICMP socket creation
-	pm.hasICMPSocket = pm.testICMPCapability()
+	// Test ICMP socket creation, which the privileged helper can stand in for
+	if ActiveHelper() != nil && !pm.hasRawSocket {
+		pm.viaHelper = true
+		pm.hasICMPSocket = true
+	} else {
+		pm.hasICMPSocket = pm.testICMPCapability()
+	}
 	pm.capabilities[CapabilityICMP] = pm.hasICMPSocket
 	
 	// Test system ping availability
@@ -92,53 +107,39 @@ func (pm *PrivilegeManager) detectPrivileges() {
 	// UDP is usually available
 	pm.capabilities[CapabilityUDP] = pm.testUDPCapability()
 	
-	// SYN scan requires raw sockets
+	// SYN scan requires raw sockets, and on Windows a capture driver to
+	// send TCP through
 	pm.capabilities[CapabilitySYN] = pm.hasRawSocket
+	if runtime.GOOS == "windows" {
+		pm.hasNpcap = netenv.NpcapLibrary() != ""
+		if pm.hasRawSocket && !pm.hasNpcap {
+			pm.capabilities[CapabilitySYN] = false
+			pm.fallbackReasons = append(pm.fallbackReasons, "Windows blocks raw TCP sends; SYN scan needs Npcap")
+		}
+	}
 }
 
 // checkRootPrivileges checks if running with root/administrator privileges
 func (pm *PrivilegeManager) checkRootPrivileges() bool {
-	switch runtime.GOOS {
-	case "windows":
-		// On Windows, check if running as administrator
-		_, err := os.Open("\\\\.\\PHYSICALDRIVE0")
-		return err == nil
-	case "darwin", "linux":
-		return os.Geteuid() == 0
-	default:
-		return false
-	}
+	return isElevated()
 }
 
 // testRawSocketCapability tests if raw sockets can be created
 func (pm *PrivilegeManager) testRawSocketCapability() bool {
+	// Sockets opened before dropping root stay usable
+	if preopenedRawSocket("ip4:icmp") != nil {
+		return true
+	}
+	
 	// Try to create a raw socket
 	switch runtime.GOOS {
-	case "linux":
-		// Try SOCK_RAW with IPPROTO_ICMP
-		fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_ICMP)
-		if err != nil {
+	case "linux", "darwin", "windows":
+		if err := openRawSocket(); err != nil {
 			pm.fallbackReasons = append(pm.fallbackReasons, fmt.Sprintf("raw socket creation failed: %v", err))
 			return false
 		}
-		syscall.Close(fd)
 		return true
 		
-	case "darwin":
-		// On macOS, try creating raw socket
-		fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_ICMP)
-		if err != nil {
-			pm.fallbackReasons = append(pm.fallbackReasons, fmt.Sprintf("raw socket creation failed: %v", err))
-			return false
-		}
-		syscall.Close(fd)
-		return true
-		
-	case "windows":
-		// Windows raw socket support is limited and complex
-		pm.fallbackReasons = append(pm.fallbackReasons, "Windows raw socket support limited")
-		return false
-		
 	default:
 		pm.fallbackReasons = append(pm.fallbackReasons, fmt.Sprintf("raw socket support unknown for OS: %s", runtime.GOOS))
 		return false
@@ -147,6 +148,10 @@ func (pm *PrivilegeManager) testRawSocketCapability() bool {
 
 // testICMPCapability tests ICMP socket capability
 func (pm *PrivilegeManager) testICMPCapability() bool {
+	if preopenedRawSocket("ip4:icmp") != nil {
+		return true
+	}
+	
 	// Try creating an ICMP connection
 	conn, err := net.Dial("ip4:icmp", "127.0.0.1")
 	if err != nil {
@@ -191,7 +196,7 @@ func (pm *PrivilegeManager) testUDPCapability() bool {
 
 // determineLevel determines the overall privilege level based on capabilities
 func (pm *PrivilegeManager) determineLevel() {
-	if pm.hasRawSocket && pm.hasICMPSocket {
+	if pm.capabilities[CapabilitySYN] && pm.hasICMPSocket {
 		pm.level = PrivilegeLevelFull
 	} else if pm.canPing || pm.capabilities[CapabilityTCPConnect] {
 		pm.level = PrivilegeLevelDegraded
@@ -211,6 +216,12 @@ func (pm *PrivilegeManager) GetLevel() PrivilegeLevel {
 	return pm.level
 }
 
+// FileCapabilities returns the Linux capabilities granted to the binary
+// with setcap, such as cap_net_raw
+func (pm *PrivilegeManager) FileCapabilities() []string {
+	return pm.fileCapabilities
+}
+
 // HasCapability checks if a specific capability is available
 func (pm *PrivilegeManager) HasCapability(capability string) bool {
 	return pm.capabilities[capability]
@@ -249,7 +260,12 @@ func (pm *PrivilegeManager) SuggestPrivilegeElevation() []string {
 	
 	if pm.level != PrivilegeLevelFull {
 		switch runtime.GOOS {
-		case "linux", "darwin":
+		case "linux":
+			suggestions = append(suggestions, "Run with sudo for full capabilities: sudo netcrate ...")
+			if !pm.hasRawSocket {
+				suggestions = append(suggestions, "Or grant raw socket access to the binary without root: netcrate privileges grant")
+			}
+		case "darwin":
 			suggestions = append(suggestions, "Run with sudo for full capabilities: sudo netcrate ...")
 			if !pm.hasRawSocket {
 				suggestions = append(suggestions, "Raw socket access requires root privileges")
@@ -262,25 +278,37 @@ func (pm *PrivilegeManager) SuggestPrivilegeElevation() []string {
 			suggestions = append(suggestions, "Current mode will use fallback methods (slower but functional)")
 		}
 	}
+	if runtime.GOOS == "windows" && !pm.hasNpcap {
+		suggestions = append(suggestions, "Install Npcap (https://npcap.com) for SYN scans and packet capture")
+	}
 	
 	return suggestions
 }
 
 // PrintPrivilegeStatus prints a comprehensive privilege status report
 func (pm *PrivilegeManager) PrintPrivilegeStatus() {
-	fmt.Printf("🔒 Privilege Status Report\n")
+	style.Printf("🔒 Privilege Status Report\n")
 	fmt.Printf("==========================\n")
 	fmt.Printf("Level: %s\n", pm.level.String())
 	fmt.Printf("Root/Admin: %v\n", pm.isRoot)
+	if drop := CurrentDrop(); drop != nil {
+		fmt.Printf("Dropped root: now uid %d (%s), keeping %s\n", drop.ToUID, drop.User, strings.Join(drop.Preopened, ","))
+	}
+	if pm.viaHelper {
+		fmt.Printf("Privileged helper: ICMP runs through it\n")
+	}
+	if len(pm.fileCapabilities) > 0 {
+		fmt.Printf("File capabilities: %s\n", strings.Join(pm.fileCapabilities, ","))
+	}
 	fmt.Printf("Detection time: %s\n", pm.detectionTime.Format("2006-01-02 15:04:05"))
 	fmt.Printf("OS: %s\n", runtime.GOOS)
 	
 	fmt.Printf("\nCapabilities:\n")
 	fmt.Printf("-------------\n")
 	for capability, available := range pm.capabilities {
-		status := "❌"
+		status := style.Text("❌")
 		if available {
-			status = "✅"
+			status = style.Text("✅")
 		}
 		fmt.Printf("%s %s\n", status, capability)
 	}
@@ -289,7 +317,7 @@ func (pm *PrivilegeManager) PrintPrivilegeStatus() {
 		fmt.Printf("\nFallback Reasons:\n")
 		fmt.Printf("-----------------\n")
 		for _, reason := range pm.fallbackReasons {
-			fmt.Printf("⚠️  %s\n", reason)
+			style.Printf("⚠️  %s\n", reason)
 		}
 	}
 	
@@ -298,7 +326,7 @@ func (pm *PrivilegeManager) PrintPrivilegeStatus() {
 		fmt.Printf("\nSuggestions:\n")
 		fmt.Printf("------------\n")
 		for _, suggestion := range suggestions {
-			fmt.Printf("💡 %s\n", suggestion)
+			style.Printf("💡 %s\n", suggestion)
 		}
 	}
 	
@@ -359,15 +387,22 @@ func (pm *PrivilegeManager) GetScanMethodRecommendation() map[string]string {
 
 // GetPrivilegeSummary returns a summary suitable for inclusion in scan results
 func (pm *PrivilegeManager) GetPrivilegeSummary() map[string]interface{} {
-	return map[string]interface{}{
+	summary := map[string]interface{}{
 		"privilege_mode":     pm.level.String(),
 		"is_root":           pm.isRoot,
+		"file_capabilities": pm.fileCapabilities,
+		"privileged_helper": pm.viaHelper,
 		"detection_time":    pm.detectionTime,
 		"os":               runtime.GOOS,
 		"capabilities":     pm.capabilities,
 		"fallback_reasons": pm.fallbackReasons,
 		"available_methods": pm.GetAvailableCapabilities(),
 	}
+	if drop := CurrentDrop(); drop != nil {
+		// Recorded when the run gave up root after opening its sockets
+		summary["privilege_drop"] = drop
+	}
+	return summary
 }
 
 // IsPrivileged returns true if running with elevated privileges
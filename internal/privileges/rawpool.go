@@ -0,0 +1,173 @@
+package privileges
+
+import (
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// PreopenedNetworks are the raw sockets DropPrivileges opens before giving
+// up root: ICMP for discovery and TCP for SYN scans
+var PreopenedNetworks = []string{"ip4:icmp", "ip6:ipv6-icmp", "ip4:tcp"}
+
+var (
+	rawPoolMu sync.Mutex
+	rawPool   = make(map[string]*sharedRawSocket)
+)
+
+// PreopenRawSockets opens raw sockets to keep for the rest of the run, so
+// that they outlive a privilege drop, and returns the networks it opened
+func PreopenRawSockets(networks []string) []string {
+	rawPoolMu.Lock()
+	defer rawPoolMu.Unlock()
+
+	var opened []string
+	for _, network := range networks {
+		if rawPool[network] != nil {
+			opened = append(opened, network)
+			continue
+		}
+		address := "0.0.0.0"
+		if network[:3] == "ip6" {
+			address = "::"
+		}
+		conn, err := net.ListenPacket(network, address)
+		if err != nil {
+			continue
+		}
+		socket := &sharedRawSocket{conn: conn, views: make(map[*sharedConn]bool)}
+		go socket.fanOut()
+		rawPool[network] = socket
+		opened = append(opened, network)
+	}
+	return opened
+}
+
+// preopenedRawSocket returns the pre-opened socket for a network, or nil
+func preopenedRawSocket(network string) *sharedRawSocket {
+	rawPoolMu.Lock()
+	defer rawPoolMu.Unlock()
+	return rawPool[network]
+}
+
+// sharedRawSocket is a pre-opened raw socket that several probes use at
+// once. Each gets a view receiving a copy of every packet, as it would with
+// a raw socket of its own.
+type sharedRawSocket struct {
+	conn  net.PacketConn
+	mu    sync.Mutex
+	views map[*sharedConn]bool
+}
+
+func (s *sharedRawSocket) open() *sharedConn {
+	view := &sharedConn{packetQueue: newPacketQueue(), socket: s}
+	s.mu.Lock()
+	s.views[view] = true
+	s.mu.Unlock()
+	return view
+}
+
+func (s *sharedRawSocket) fanOut() {
+	buffer := make([]byte, 65536)
+	for {
+		n, from, err := s.conn.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		for view := range s.views {
+			view.push(from, buffer[:n])
+		}
+		s.mu.Unlock()
+	}
+}
+
+// sharedConn is a view of a sharedRawSocket
+type sharedConn struct {
+	*packetQueue
+	socket *sharedRawSocket
+}
+
+func (c *sharedConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	return c.socket.conn.WriteTo(b, addr)
+}
+
+func (c *sharedConn) Close() error {
+	c.socket.mu.Lock()
+	delete(c.socket.views, c)
+	c.socket.mu.Unlock()
+	c.close()
+	return nil
+}
+
+func (c *sharedConn) LocalAddr() net.Addr { return c.socket.conn.LocalAddr() }
+
+func (c *sharedConn) SetDeadline(t time.Time) error { return c.SetReadDeadline(t) }
+
+// SetWriteDeadline is a no-op: writes to a raw socket do not block
+func (c *sharedConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// queuedPacket is a packet waiting in a packetQueue
+type queuedPacket struct {
+	from    net.Addr
+	payload []byte
+}
+
+// packetQueue is the receiving half of a net.PacketConn whose packets are
+// delivered by another goroutine: from the helper or a shared raw socket
+type packetQueue struct {
+	packets chan queuedPacket
+	closed  chan struct{}
+	once    sync.Once
+
+	deadlineMu sync.Mutex
+	deadline   time.Time
+}
+
+func newPacketQueue() *packetQueue {
+	return &packetQueue{
+		packets: make(chan queuedPacket, 256),
+		closed:  make(chan struct{}),
+	}
+}
+
+// push queues a copy of a packet, dropping it as a full socket buffer would
+func (q *packetQueue) push(from net.Addr, payload []byte) {
+	select {
+	case q.packets <- queuedPacket{from: from, payload: append([]byte(nil), payload...)}:
+	default:
+	}
+}
+
+func (q *packetQueue) close() {
+	q.once.Do(func() { close(q.closed) })
+}
+
+func (q *packetQueue) ReadFrom(b []byte) (int, net.Addr, error) {
+	q.deadlineMu.Lock()
+	deadline := q.deadline
+	q.deadlineMu.Unlock()
+
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case packet := <-q.packets:
+		return copy(b, packet.payload), packet.from, nil
+	case <-timeout:
+		return 0, nil, os.ErrDeadlineExceeded
+	case <-q.closed:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+func (q *packetQueue) SetReadDeadline(t time.Time) error {
+	q.deadlineMu.Lock()
+	defer q.deadlineMu.Unlock()
+	q.deadline = t
+	return nil
+}
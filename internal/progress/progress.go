@@ -0,0 +1,149 @@
+// Package progress reports how far the phases of a long-running command
+// have come, for wrappers and UIs that should not parse the human output.
+// With --progress json every phase writes NDJSON events to stderr: one when
+// it starts, one per second while it runs and one when it ends.
+//
+//	{"event":"progress","time":"...","command":"ops scan ports","phase":"scan","done":350,"total":1000,"rate":98.4,"eta_seconds":6.6}
+//
+// Operations start a phase where they know its size; phases of a command
+// that does not report progress cost nothing.
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event types
+const (
+	EventStart    = "phase_start"
+	EventProgress = "progress"
+	EventEnd      = "phase_end"
+)
+
+// Event is one line of the progress stream
+type Event struct {
+	Event   string    `json:"event"`
+	Time    time.Time `json:"time"`
+	Command string    `json:"command,omitempty"`
+	Phase   string    `json:"phase"`
+	Done    int64     `json:"done"`
+	Total   int64     `json:"total"`                 // 0 when unknown
+	Rate    float64   `json:"rate"`                  // items per second since the phase started
+	ETA     float64   `json:"eta_seconds,omitempty"` // estimated seconds left, when known
+}
+
+// Interval is how often a running phase reports
+var Interval = time.Second
+
+var (
+	mu      sync.Mutex
+	output  io.Writer // nil when progress is off
+	command string
+)
+
+// Enable writes the progress of the phases started from now on to w,
+// naming command in every event
+func Enable(w io.Writer, name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	output = w
+	command = name
+}
+
+// Disable stops reporting progress
+func Disable() {
+	mu.Lock()
+	defer mu.Unlock()
+	output = nil
+}
+
+// Phase is a running phase of the command. Its methods may be called on a
+// nil Phase, which is what Start returns while progress is off.
+type Phase struct {
+	name  string
+	total int64
+	done  atomic.Int64
+	start time.Time
+	stop  chan struct{}
+	once  sync.Once
+}
+
+// Start begins a phase of total items (0 when unknown) and reports it until
+// End
+func Start(name string, total int) *Phase {
+	mu.Lock()
+	enabled := output != nil
+	mu.Unlock()
+	if !enabled {
+		return nil
+	}
+
+	p := &Phase{name: name, total: int64(total), start: time.Now(), stop: make(chan struct{})}
+	p.emit(EventStart)
+	go func() {
+		ticker := time.NewTicker(Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.emit(EventProgress)
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// Add counts n more items done
+func (p *Phase) Add(n int) {
+	if p == nil {
+		return
+	}
+	p.done.Add(int64(n))
+}
+
+// End reports the phase as finished; later calls do nothing
+func (p *Phase) End() {
+	if p == nil {
+		return
+	}
+	p.once.Do(func() {
+		close(p.stop)
+		p.emit(EventEnd)
+	})
+}
+
+func (p *Phase) emit(kind string) {
+	now := time.Now()
+	done := p.done.Load()
+	event := Event{Event: kind, Time: now, Phase: p.name, Done: done, Total: p.total}
+	if elapsed := now.Sub(p.start).Seconds(); elapsed > 0 {
+		event.Rate = round(float64(done) / elapsed)
+	}
+	if kind != EventEnd && event.Rate > 0 && p.total > done {
+		event.ETA = round(float64(p.total-done) / event.Rate)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if output == nil {
+		return
+	}
+	event.Command = command
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	output.Write(append(line, '\n'))
+}
+
+// round keeps two decimals
+func round(x float64) float64 {
+	return math.Round(x*100) / 100
+}
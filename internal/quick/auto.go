@@ -2,22 +2,59 @@ package quick
 
 import (
 	"bufio"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/netcrate/netcrate/internal/compliance"
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/i18n"
 	"github.com/netcrate/netcrate/internal/netenv"
 	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/risk"
+	"github.com/netcrate/netcrate/internal/runid"
+	"github.com/netcrate/netcrate/internal/services"
+	"github.com/netcrate/netcrate/internal/style"
 )
 
+// maxFingerprintTargets bounds the number of open ports fingerprinted in a single run
+const maxFingerprintTargets = 256
+
+// QuickOptions holds the user-supplied options for a quick mode run
+type QuickOptions struct {
+	DryRun      bool
+	SkipConfirm bool
+	Interactive bool
+	Targets     []string // Explicit target ranges, overrides auto-detection
+	CIDRLimit   int      // Prefix length applied to the auto-detected network (0 = as detected)
+	Fingerprint bool     // Run the service fingerprinting stage on open ports
+	Interface   string   // Force a specific network interface by name
+	ExcludeSelf    bool     // Never probe the local machine's addresses
+	ExcludeGateway bool     // Never probe the default gateway
+	Exclude        []string // Additional IPs or CIDRs that must not be scanned
+	CompatA1       bool     // Use plain A1 discovery without B1 enhancements
+	Labels         store.Labels // Name, tags and notes saved with the run
+	MaxRate        int      // Cap on packets per second whatever the speed profile (0 = none)
+	MaxConcurrency int      // Cap on concurrent workers whatever the speed profile (0 = none)
+	Scope          []string // IPs or CIDRs every target must lie in (empty = no limit)
+	Preflight      func(targets []string, clamped []compliance.Clamp) error // Checks the calculated targets and budget before anything is sent (nil = none)
+	Ceilings       compliance.Ceilings // Hard limits of the compliance policy, applied after the caps
+	Authorization  *compliance.RunAuthorization // Who authorized the run, recorded in its context (nil = not recorded)
+	Classify       func(host string) *ops.ComplianceLabel // Labels results with their compliance class (nil = unlabeled)
+}
+
 // QuickConfig holds configuration for quick mode
 type QuickConfig struct {
 	Interface    *netenv.NetworkInterface
 	TargetCIDR   string
+	TargetCIDRs  []string
+	CIDRLimit    int
 	PortSet      string // "top100", "top1000", "web", "database", "custom"
 	Profile      string // "safe", "fast", "custom"
 	DiscoverOpts ops.DiscoverOptions
@@ -26,6 +63,25 @@ type QuickConfig struct {
 	DryRun       bool
 	SkipConfirm  bool
 	Interactive  bool   // Enable interactive mode
+	Fingerprint  bool   // Enable service fingerprinting stage
+	Excludes     []string // IPs or CIDRs removed from the target set
+	CompatA1     bool     // Disable enhanced discovery
+	MaxRate        int // Caps applied to the speed profile (0 = none)
+	MaxConcurrency int
+	Scope          []string // Engagement scope targets must lie in
+	Ceilings       compliance.Ceilings
+	Clamped        []compliance.Clamp // Options lowered to the ceilings by applyConfiguration
+	Classify       func(host string) *ops.ComplianceLabel
+}
+
+// quickRunOptions are the effective options recorded with a quick run
+type quickRunOptions struct {
+	Discover    ops.DiscoverOptions `json:"discover"`
+	Scan        ops.ScanOptions     `json:"scan"`
+	PortSet     string              `json:"port_set"`
+	Profile     string              `json:"profile"`
+	Fingerprint bool                `json:"fingerprint"`
+	CompatA1    bool                `json:"compat_a1,omitempty"`
 }
 
 // QuickResult holds the complete results of quick mode execution
@@ -33,14 +89,39 @@ type QuickResult struct {
 	RunID         string                `json:"run_id"`
 	Interface     *netenv.NetworkInterface `json:"interface"`
 	TargetCIDR    string                `json:"target_cidr"`
+	TargetCIDRs   []string              `json:"target_cidrs,omitempty"`
+	Status        string                `json:"status,omitempty"` // "partial" after discovery, "complete" when finished
+	PortSet       string                `json:"port_set,omitempty"`
+	Profile       string                `json:"profile,omitempty"`
+	Fingerprint   bool                  `json:"fingerprint,omitempty"`
+	Excludes      []string              `json:"excludes,omitempty"`
+	Enhancements  *DiscoveryEnhancements `json:"discovery_enhancements,omitempty"`
+	MergedFrom    []string              `json:"merged_from,omitempty"` // source runs of an aggregate made by `output merge`
+	Command       string                `json:"command,omitempty"` // command that made the run when not quick mode, e.g. "scan"
+	Context       *store.RunContext     `json:"-"` // stored in the run record envelope
+	store.Labels
 	StartTime     time.Time             `json:"start_time"`
 	EndTime       time.Time             `json:"end_time"`
 	Duration      float64               `json:"duration"`
 	DiscoverResult *ops.DiscoverSummary `json:"discover_result"`
 	ScanResult     *ops.ScanSummary     `json:"scan_result"`
+	Fingerprints   []*services.ProtocolFingerprint `json:"fingerprints,omitempty"`
 	Summary        QuickSummary          `json:"summary"`
 }
 
+// DiscoveryEnhancements records which B1 discovery enhancements were applied
+type DiscoveryEnhancements struct {
+	TargetsPrioritized int      `json:"targets_prioritized"`
+	HighPriority       int      `json:"high_priority"`
+	MediumPriority     int      `json:"medium_priority"`
+	LowPriority        int      `json:"low_priority"`
+	AdaptiveRateUsed   bool     `json:"adaptive_rate_used"`
+	RateAdjustments    int      `json:"rate_adjustments"`
+	FinalRate          int      `json:"final_rate,omitempty"`
+	MethodFallbackUsed bool     `json:"method_fallback_used"`
+	ActualMethods      []string `json:"actual_methods,omitempty"`
+}
+
 // QuickSummary provides a high-level overview
 type QuickSummary struct {
 	HostsDiscovered int               `json:"hosts_discovered"`
@@ -48,6 +129,20 @@ type QuickSummary struct {
 	TopServices     map[string]int    `json:"top_services"`
 	LiveHosts       []string          `json:"live_hosts"`
 	CriticalPorts   []CriticalPort    `json:"critical_ports"`
+	Services        []ServiceDetail   `json:"services,omitempty"`
+	Devices         []DeviceInfo      `json:"devices,omitempty"`
+}
+
+// ServiceDetail describes an application identified by the fingerprinting stage
+type ServiceDetail struct {
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Service     string `json:"service"`
+	Application string `json:"application,omitempty"`
+	Version     string `json:"version,omitempty"`
+	TLSVersion  string `json:"tls_version,omitempty"`
+	CertSubject string `json:"cert_subject,omitempty"`
+	Confidence  int    `json:"confidence"`
 }
 
 // CriticalPort represents a notable open port
@@ -55,40 +150,58 @@ type CriticalPort struct {
 	Host    string `json:"host"`
 	Port    int    `json:"port"`
 	Service string `json:"service"`
-	Risk    string `json:"risk"` // "low", "medium", "high"
+	Risk      string `json:"risk"` // "low", "medium", "high", "critical"
+	Rationale string `json:"rationale,omitempty"`
 }
 
 // RunQuickMode executes the complete quick mode workflow
-func RunQuickMode(dryRun bool, skipConfirm bool, interactive bool) (*QuickResult, error) {
+func RunQuickMode(opts QuickOptions) (*QuickResult, error) {
+	dryRun, skipConfirm, interactive := opts.DryRun, opts.SkipConfirm, opts.Interactive
 	startTime := time.Now()
-	runID := fmt.Sprintf("quick_%d", startTime.Unix())
+	runID := runid.NewAt("quick", startTime)
 
-	fmt.Println("🚀 NetCrate Quick Mode")
+	style.Println("🚀 NetCrate Quick Mode")
 	fmt.Println("======================")
 
 	// Step 1: Auto-detect network interface
-	fmt.Println("\n[1/4] 🔍 自动检测网络接口...")
+	fmt.Println(i18n.T("quick.step.detect_interface"))
 	
-	config, err := autoDetectInterface()
+	config, err := autoDetectInterface(opts.Interface, opts.Targets, !skipConfirm)
 	if err != nil {
 		return nil, fmt.Errorf("interface detection failed: %w", err)
 	}
+	warnOverlay(config.Interface)
 	
 	config.DryRun = dryRun
 	config.SkipConfirm = skipConfirm
 	config.Interactive = interactive
+	config.TargetCIDRs = opts.Targets
+	config.CIDRLimit = opts.CIDRLimit
+	config.Fingerprint = opts.Fingerprint
+	config.Excludes = buildExclusions(config.Interface, opts)
+	config.CompatA1 = opts.CompatA1
+	config.MaxRate = opts.MaxRate
+	config.MaxConcurrency = opts.MaxConcurrency
+	config.Scope = opts.Scope
+	config.Ceilings = opts.Ceilings
+	config.Classify = opts.Classify
 
 	// Step 2: Calculate target network
-	fmt.Println("\n[2/4] 🎯 计算目标网段...")
+	fmt.Println(i18n.T("quick.step.target_network"))
 	
 	err = calculateTargetNetwork(config)
 	if err != nil {
 		return nil, fmt.Errorf("target calculation failed: %w", err)
 	}
+	if opts.Preflight != nil {
+		if err := opts.Preflight(config.TargetCIDRs, config.Clamped); err != nil {
+			return nil, fmt.Errorf("compliance pre-flight failed: %w", err)
+		}
+	}
 
 	// Step 2.5: Interactive configuration selection
 	if interactive && !skipConfirm {
-		fmt.Println("\n[2.5/4] ⚙️ 扫描配置")
+		fmt.Println(i18n.T("quick.step.configure"))
 		err = interactiveConfiguration(config)
 		if err != nil {
 			return nil, fmt.Errorf("configuration selection failed: %w", err)
@@ -97,53 +210,87 @@ func RunQuickMode(dryRun bool, skipConfirm bool, interactive bool) (*QuickResult
 
 	// Step 3: Show configuration and get confirmation
 	if !skipConfirm {
-		fmt.Println("\n[3/4] ⚙️ 配置确认")
+		fmt.Println(i18n.T("quick.step.confirm"))
 		fmt.Println("==================")
 		printConfiguration(config)
 		
 		if !getUserConfirmation() {
-			fmt.Println("\n❌ 用户取消操作")
+			fmt.Println(i18n.T("quick.cancelled"))
 			return nil, fmt.Errorf("user cancelled")
 		}
 	}
 
 	// Step 4: Execute scan pipeline
-	fmt.Println("\n[4/4] 🔍 执行扫描流水线...")
+	fmt.Println(i18n.T("quick.step.execute"))
 	
 	if dryRun {
-		fmt.Println("🧪 [DRY RUN] 跳过实际执行")
+		fmt.Println(i18n.T("quick.dry_run"))
+		if skipConfirm {
+			printEstimate(config)
+		}
 		return &QuickResult{
 			RunID:      runID,
 			Interface:  config.Interface,
-			TargetCIDR: config.TargetCIDR,
-			StartTime:  startTime,
-			EndTime:    time.Now(),
+			TargetCIDR:  config.TargetCIDR,
+			TargetCIDRs: config.TargetCIDRs,
+			StartTime:   startTime,
+			EndTime:     time.Now(),
 		}, nil
 	}
 
-	result, err := executeScanPipeline(config)
+	result := &QuickResult{
+		RunID:       runID,
+		Interface:   config.Interface,
+		TargetCIDR:  config.TargetCIDR,
+		TargetCIDRs: config.TargetCIDRs,
+		PortSet:     config.PortSet,
+		Profile:     config.Profile,
+		Fingerprint: config.Fingerprint,
+		Excludes:    config.Excludes,
+		Labels:      opts.Labels,
+		StartTime:   startTime,
+	}
+
+	interfaceName := ""
+	if config.Interface != nil {
+		interfaceName = config.Interface.Name
+	}
+	result.Context = store.NewRunContext(interfaceName, quickRunOptions{
+		Discover:    config.DiscoverOpts,
+		Scan:        config.ScanOpts,
+		PortSet:     config.PortSet,
+		Profile:     config.Profile,
+		Fingerprint: config.Fingerprint,
+		CompatA1:    config.CompatA1,
+	})
+	result.Context.Clamped = config.Clamped
+	result.Context.Authorization = opts.Authorization
+
+	err = executeScanPipeline(config, result)
 	if err != nil {
 		return nil, fmt.Errorf("scan pipeline failed: %w", err)
 	}
 
-	result.RunID = runID
-	result.Interface = config.Interface
-	result.TargetCIDR = config.TargetCIDR
-	result.StartTime = startTime
+	result.Status = "complete"
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(startTime).Seconds()
 
 	// Save results
 	err = saveResults(result)
 	if err != nil {
-		fmt.Printf("⚠️ 结果保存失败: %v\n", err)
+		fmt.Print(i18n.T("quick.save_failed", err))
 	}
 
+	notifyCompletion(result)
+
 	return result, nil
 }
 
-// autoDetectInterface automatically selects the best network interface
-func autoDetectInterface() (*QuickConfig, error) {
+// autoDetectInterface automatically selects the best network interface.
+// A forced interface name always wins, then the interface the system routes
+// explicit targets through; when several private interfaces are up and
+// prompting is allowed, the user picks one from a list.
+func autoDetectInterface(forceName string, targets []string, allowPrompt bool) (*QuickConfig, error) {
 	// Get network environment
 	netEnv, err := netenv.DetectNetworkEnvironment()
 	if err != nil {
@@ -154,33 +301,56 @@ func autoDetectInterface() (*QuickConfig, error) {
 		return nil, fmt.Errorf("no network interfaces found")
 	}
 
-	// Find the best private network interface
 	var selectedInterface *netenv.NetworkInterface
-	
-	// Priority: private networks first, then any active interface
-	for _, iface := range netEnv.Interfaces {
-		if iface.Status != "up" {
-			continue
-		}
-		
-		for _, addr := range iface.Addresses {
-			ip := net.ParseIP(addr.IP)
-			if ip != nil && isPrivateIP(ip) {
-				selectedInterface = &iface
+
+	if forceName != "" {
+		for i := range netEnv.Interfaces {
+			if netEnv.Interfaces[i].Name == forceName {
+				selectedInterface = &netEnv.Interfaces[i]
 				break
 			}
 		}
-		
-		if selectedInterface != nil {
-			break
+		if selectedInterface == nil {
+			return nil, errors.New(i18n.T("quick.iface.not_found", forceName))
+		}
+		if selectedInterface.Status != "up" {
+			return nil, errors.New(i18n.T("quick.iface.down", forceName))
 		}
+
+		fmt.Print(i18n.T("quick.iface.forced", selectedInterface.Name, selectedInterface.DisplayName))
+		if len(selectedInterface.Addresses) > 0 {
+			fmt.Print(i18n.T("quick.iface.ip", selectedInterface.Addresses[0].IP))
+		}
+		return &QuickConfig{Interface: selectedInterface}, nil
+	}
+
+	if len(targets) > 0 {
+		if routed := netenv.RouteInterface(targets[0]); routed != "" {
+			for i := range netEnv.Interfaces {
+				if iface := &netEnv.Interfaces[i]; iface.Name == routed && iface.Status == "up" {
+					fmt.Print(i18n.T("quick.iface.routed", iface.Name, iface.DisplayName, targets[0]))
+					if len(iface.Addresses) > 0 {
+						fmt.Print(i18n.T("quick.iface.ip", iface.Addresses[0].IP))
+					}
+					return &QuickConfig{Interface: iface}, nil
+				}
+			}
+		}
+	}
+
+	// Priority: private networks first, then any active interface
+	candidates := privateInterfaces(netEnv.Interfaces)
+	if len(candidates) > 1 && allowPrompt {
+		selectedInterface = selectInterface(candidates)
+	} else if len(candidates) > 0 {
+		selectedInterface = candidates[0]
 	}
 
 	// If no private interface, use the recommended one
 	if selectedInterface == nil {
-		for _, iface := range netEnv.Interfaces {
+		for i, iface := range netEnv.Interfaces {
 			if iface.Name == netEnv.Recommended && iface.Status == "up" {
-				selectedInterface = &iface
+				selectedInterface = &netEnv.Interfaces[i]
 				break
 			}
 		}
@@ -188,23 +358,23 @@ func autoDetectInterface() (*QuickConfig, error) {
 
 	// If still no interface, use the first active one
 	if selectedInterface == nil {
-		for _, iface := range netEnv.Interfaces {
+		for i, iface := range netEnv.Interfaces {
 			if iface.Status == "up" {
-				selectedInterface = &iface
+				selectedInterface = &netEnv.Interfaces[i]
 				break
 			}
 		}
 	}
 
 	if selectedInterface == nil {
-		return nil, fmt.Errorf("未检测到可用的网络接口")
+		return nil, errors.New(i18n.T("quick.iface.none"))
 	}
 
-	fmt.Printf("✅ 自动选择接口: %s (%s)\n", 
-		selectedInterface.Name, selectedInterface.DisplayName)
+	fmt.Print(i18n.T("quick.iface.selected", 
+		selectedInterface.Name, selectedInterface.DisplayName))
 	
 	if len(selectedInterface.Addresses) > 0 {
-		fmt.Printf("   IP地址: %s\n", selectedInterface.Addresses[0].IP)
+		fmt.Print(i18n.T("quick.iface.ip", selectedInterface.Addresses[0].IP))
 	}
 
 	return &QuickConfig{
@@ -212,38 +382,112 @@ func autoDetectInterface() (*QuickConfig, error) {
 	}, nil
 }
 
-// calculateTargetNetwork derives the target CIDR from interface information
-func calculateTargetNetwork(config *QuickConfig) error {
-	if len(config.Interface.Addresses) == 0 {
-		return fmt.Errorf("selected interface has no IP addresses")
+// warnOverlay warns when the chosen interface is a VPN or virtual network,
+// whose private range is not the LAN the operator probably means
+func warnOverlay(iface *netenv.NetworkInterface) {
+	if iface == nil || iface.Overlay == "" {
+		return
+	}
+	network := "-"
+	if len(iface.Addresses) > 0 {
+		network = iface.Addresses[0].Network
 	}
+	if iface.SpansRemote() {
+		fmt.Print(i18n.T("quick.iface.overlay", iface.Name, iface.Overlay, network))
+	} else {
+		fmt.Print(i18n.T("quick.iface.virtual", iface.Name, iface.Overlay, network))
+	}
+}
 
-	addr := config.Interface.Addresses[0]
-	
-	// Parse the network CIDR
-	if !strings.Contains(addr.Network, "/") {
-		return fmt.Errorf("invalid network format: %s", addr.Network)
+// privateInterfaces returns the up interfaces that carry a private IPv4 address
+func privateInterfaces(interfaces []netenv.NetworkInterface) []*netenv.NetworkInterface {
+	var candidates []*netenv.NetworkInterface
+	for i, iface := range interfaces {
+		if iface.Status != "up" {
+			continue
+		}
+		for _, addr := range iface.Addresses {
+			ip := net.ParseIP(addr.IP)
+			if ip != nil && isPrivateIP(ip) {
+				candidates = append(candidates, &interfaces[i])
+				break
+			}
+		}
 	}
+	return candidates
+}
 
-	// Extract network address
-	_, ipnet, err := net.ParseCIDR(addr.Network)
+// selectInterface prompts the user to choose between several candidate interfaces
+func selectInterface(candidates []*netenv.NetworkInterface) *netenv.NetworkInterface {
+	fmt.Println(i18n.T("quick.iface.multiple"))
+	for i, iface := range candidates {
+		ip := "-"
+		if len(iface.Addresses) > 0 {
+			ip = iface.Addresses[0].IP
+		}
+
+		gateway := i18n.T("quick.iface.no_gateway")
+		if iface.Gateway != nil && iface.Gateway.IP != "" {
+			if err := netenv.PingGateway(iface.Gateway); err == nil {
+				gateway = i18n.T("quick.iface.gateway_rtt", iface.Gateway.IP, iface.Gateway.RTT)
+			} else {
+				gateway = i18n.T("quick.iface.gateway_unreachable", iface.Gateway.IP)
+			}
+		}
+
+		defaultMark := ""
+		if i == 0 {
+			defaultMark = i18n.T("quick.default_mark")
+		}
+		fmt.Printf("  %d. %-10s %-15s %-10s %s%s\n", i+1, iface.Name, ip, iface.Type, gateway, defaultMark)
+	}
+
+	fmt.Print(i18n.T("quick.choose_range", len(candidates)))
+
+	scanner := bufio.NewScanner(os.Stdin)
+	if scanner.Scan() {
+		choice := strings.TrimSpace(scanner.Text())
+		if choice != "" {
+			var index int
+			if n, err := fmt.Sscanf(choice, "%d", &index); err == nil && n == 1 && index >= 1 && index <= len(candidates) {
+				return candidates[index-1]
+			}
+			fmt.Print(i18n.T("quick.iface.invalid_choice", candidates[0].Name))
+		}
+	}
+
+	return candidates[0]
+}
+
+// calculateTargetNetwork derives the target CIDRs from explicit targets or interface information
+func calculateTargetNetwork(config *QuickConfig) error {
+	var targets []string
+	var err error
+
+	if len(config.TargetCIDRs) > 0 {
+		targets, err = normalizeTargets(config.TargetCIDRs, config.CIDRLimit)
+	} else {
+		targets, err = deriveInterfaceNetwork(config.Interface, config.CIDRLimit)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to parse network CIDR: %w", err)
+		return err
 	}
 
-	targetCIDR := ipnet.String()
-	
-	// Safety check: ensure it's a private network
-	if !isPrivateNetwork(ipnet) {
-		return fmt.Errorf("⚠️ 检测到公网地址 %s\n"+
-			"为了安全，Quick模式只能扫描私网地址\n"+
-			"如需扫描公网，请使用: netcrate ops discover --dangerous", 
-			targetCIDR)
+	// Safety check: ensure every target is a private network
+	for _, target := range targets {
+		_, ipnet, _ := net.ParseCIDR(target)
+		if !isPrivateNetwork(ipnet) {
+			return compliance.Blocked(i18n.T("quick.public_network", target))
+		}
+	}
+	if err := ops.CheckScope(targets, config.Scope); err != nil {
+		return err
 	}
 
-	config.TargetCIDR = targetCIDR
+	config.TargetCIDRs = targets
+	config.TargetCIDR = strings.Join(targets, ",")
 	
-	fmt.Printf("✅ 目标网段: %s\n", targetCIDR)
+	fmt.Print(i18n.T("quick.target_selected", config.TargetCIDR))
 	
 	// Set default configuration
 	config.PortSet = "top100"  // Default port set
@@ -257,40 +501,156 @@ func calculateTargetNetwork(config *QuickConfig) error {
 	return nil
 }
 
+// buildExclusions collects the addresses quick mode must not probe: the local
+// machine, the gateway and the user's do-not-scan list
+func buildExclusions(iface *netenv.NetworkInterface, opts QuickOptions) []string {
+	var excludes []string
+	seen := make(map[string]bool)
+	add := func(entry string) {
+		entry = strings.TrimSpace(entry)
+		if entry != "" && !seen[entry] {
+			seen[entry] = true
+			excludes = append(excludes, entry)
+		}
+	}
+
+	if opts.ExcludeSelf && iface != nil {
+		for _, addr := range iface.Addresses {
+			add(addr.IP)
+		}
+	}
+	if opts.ExcludeGateway && iface != nil && iface.Gateway != nil {
+		add(iface.Gateway.IP)
+	}
+	for _, entry := range opts.Exclude {
+		add(entry)
+	}
+
+	return excludes
+}
+
+// deriveInterfaceNetwork returns the network of the interface's first address,
+// resized to cidrLimit when it is set
+func deriveInterfaceNetwork(iface *netenv.NetworkInterface, cidrLimit int) ([]string, error) {
+	if len(iface.Addresses) == 0 {
+		return nil, fmt.Errorf("selected interface has no IP addresses")
+	}
+
+	addr := iface.Addresses[0]
+	
+	// Parse the network CIDR
+	if !strings.Contains(addr.Network, "/") {
+		return nil, fmt.Errorf("invalid network format: %s", addr.Network)
+	}
+
+	// Extract network address
+	ip, ipnet, err := net.ParseCIDR(addr.Network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse network CIDR: %w", err)
+	}
+
+	if cidrLimit > 0 {
+		if parsed := net.ParseIP(addr.IP); parsed != nil {
+			ip = parsed
+		}
+		ipnet = &net.IPNet{IP: ip.Mask(net.CIDRMask(cidrLimit, 32)), Mask: net.CIDRMask(cidrLimit, 32)}
+	}
+
+	return []string{ipnet.String()}, nil
+}
+
+// normalizeTargets parses user-supplied targets into CIDRs, treating bare IPs
+// as /32 and rejecting ranges wider than cidrLimit
+func normalizeTargets(targets []string, cidrLimit int) ([]string, error) {
+	var result []string
+	seen := make(map[string]bool)
+
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		if !strings.Contains(target, "/") {
+			target += "/32"
+		}
+
+		_, ipnet, err := net.ParseCIDR(target)
+		if err != nil || ipnet.IP.To4() == nil {
+			return nil, fmt.Errorf("invalid target %s: must be an IPv4 address or CIDR", target)
+		}
+
+		ones, _ := ipnet.Mask.Size()
+		if cidrLimit > 0 && ones < cidrLimit {
+			return nil, fmt.Errorf("target %s is wider than --cidr-limit /%d", ipnet.String(), cidrLimit)
+		}
+
+		if !seen[ipnet.String()] {
+			seen[ipnet.String()] = true
+			result = append(result, ipnet.String())
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no valid targets specified")
+	}
+
+	return result, nil
+}
+
+// ParseCIDRLimit parses a prefix length given as "/22" or "22"
+func ParseCIDRLimit(value string) (int, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "/")
+	if value == "" {
+		return 0, nil
+	}
+
+	var limit int
+	if n, err := fmt.Sscanf(value, "%d", &limit); err != nil || n != 1 || limit < 8 || limit > 32 {
+		return 0, fmt.Errorf("invalid CIDR limit %q: expected a prefix length between /8 and /32", value)
+	}
+
+	return limit, nil
+}
+
 // printConfiguration displays the configuration for user confirmation
 func printConfiguration(config *QuickConfig) {
-	fmt.Printf("📡 接口: %s (%s)\n", config.Interface.Name, config.Interface.DisplayName)
+	fmt.Print(i18n.T("quick.config.interface", config.Interface.Name, config.Interface.DisplayName))
 	if len(config.Interface.Addresses) > 0 {
-		fmt.Printf("📍 本机IP: %s\n", config.Interface.Addresses[0].IP)
+		fmt.Print(i18n.T("quick.config.local_ip", config.Interface.Addresses[0].IP))
+	}
+	fmt.Print(i18n.T("quick.config.target", config.TargetCIDR))
+	fmt.Print(i18n.T("quick.config.discovery"))
+	if len(config.Excludes) > 0 {
+		fmt.Print(i18n.T("quick.config.excludes", strings.Join(config.Excludes, ", ")))
 	}
-	fmt.Printf("🎯 目标网段: %s\n", config.TargetCIDR)
-	fmt.Printf("🔍 主机发现: ICMP + TCP (22,80,443)\n")
 	
 	// Display port set information
 	portCount := len(config.ScanOpts.Ports)
 	portSetDesc := getPortSetDescription(config.PortSet, portCount)
-	fmt.Printf("📊 端口扫描: %s\n", portSetDesc)
+	fmt.Print(i18n.T("quick.config.ports", portSetDesc))
 	
 	// Display speed profile information  
 	profileDesc := getProfileDescription(config.Profile, config.DiscoverOpts.Rate, config.DiscoverOpts.Concurrency)
-	fmt.Printf("⚡ 速率档位: %s\n", profileDesc)
+	fmt.Print(i18n.T("quick.config.profile", profileDesc))
+
+	printEstimate(config)
 }
 
 // getPortSetDescription returns a human-readable description of the port set
 func getPortSetDescription(portSet string, portCount int) string {
 	switch portSet {
 	case "top100":
-		return fmt.Sprintf("top100 (%d 个最常用端口)", portCount)
+		return i18n.T("quick.portset.top100", portCount)
 	case "top1000":
-		return fmt.Sprintf("top1000 (%d 个最常用端口)", portCount)
+		return i18n.T("quick.portset.top1000", portCount)
 	case "web":
-		return fmt.Sprintf("web (%d 个Web服务端口)", portCount)
+		return i18n.T("quick.portset.web", portCount)
 	case "database":
-		return fmt.Sprintf("database (%d 个数据库端口)", portCount)
+		return i18n.T("quick.portset.database", portCount)
 	case "common":
-		return fmt.Sprintf("common (%d 个通用服务端口)", portCount)
+		return i18n.T("quick.portset.common", portCount)
 	default:
-		return fmt.Sprintf("%s (%d 个端口)", portSet, portCount)
+		return i18n.T("quick.portset.other", portSet, portCount)
 	}
 }
 
@@ -298,19 +658,19 @@ func getPortSetDescription(portSet string, portCount int) string {
 func getProfileDescription(profile string, rate, concurrency int) string {
 	switch {
 	case profile == "safe":
-		return fmt.Sprintf("safe - 安全模式 (%d pps, %d 并发)", rate, concurrency)
+		return i18n.T("quick.profile.safe", rate, concurrency)
 	case profile == "fast":
-		return fmt.Sprintf("fast - 快速模式 (%d pps, %d 并发)", rate, concurrency)
+		return i18n.T("quick.profile.fast", rate, concurrency)
 	case strings.HasPrefix(profile, "custom-"):
-		return fmt.Sprintf("custom - 自定义 (%d pps, %d 并发)", rate, concurrency)
+		return i18n.T("quick.profile.custom", rate, concurrency)
 	default:
-		return fmt.Sprintf("%s (%d pps, %d 并发)", profile, rate, concurrency)
+		return i18n.T("quick.profile.other", profile, rate, concurrency)
 	}
 }
 
 // getUserConfirmation prompts user for confirmation
 func getUserConfirmation() bool {
-	fmt.Printf("\n按 Enter 继续，输入 'q' 退出: ")
+	fmt.Print(i18n.T("quick.confirm_prompt"))
 	
 	scanner := bufio.NewScanner(os.Stdin)
 	if scanner.Scan() {
@@ -324,22 +684,83 @@ func getUserConfirmation() bool {
 }
 
 // executeScanPipeline runs the discovery and scanning operations
-func executeScanPipeline(config *QuickConfig) (*QuickResult, error) {
-	result := &QuickResult{}
-
+func executeScanPipeline(config *QuickConfig, result *QuickResult) error {
 	// Phase 1: Host Discovery
-	fmt.Println("\n🔍 阶段 1: 主机发现")
+	fmt.Println(i18n.T("quick.phase.discovery"))
 	fmt.Println("==================")
 	
-	discoverResult, err := ops.Discover(config.DiscoverOpts)
+	enhancedResult, err := ops.EnhancedDiscover(ops.DiscoverEnhancedOptions{
+		DiscoverOptions:      config.DiscoverOpts,
+		EnableTargetPruning:  true,
+		EnableAdaptiveRate:   true,
+		HighLossThreshold:    0.3,
+		DownshiftStep:        0.2,
+		UpshiftStep:          0.1,
+		GoodWindowsToUpshift: 3,
+		NoSampling:           true,
+		CompatA1:             config.CompatA1,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("host discovery failed: %w", err)
+		return fmt.Errorf("host discovery failed: %w", err)
 	}
 	
+	discoverResult := enhancedResult.DiscoverSummary
 	result.DiscoverResult = discoverResult
+	if !config.CompatA1 {
+		result.Enhancements = summarizeEnhancements(enhancedResult)
+	}
 	
-	fmt.Printf("✅ 发现 %d 个活跃主机 (耗时 %.1fs)\n", 
-		discoverResult.HostsDiscovered, discoverResult.Duration)
+	fmt.Print(i18n.T("quick.phase.discovery_done", 
+		discoverResult.HostsDiscovered, discoverResult.Duration))
+	printEnhancements(result.Enhancements)
+
+	// Checkpoint discovery so an interrupted scan can be resumed
+	result.Status = "partial"
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime).Seconds()
+	if err := writeResultFile(result); err != nil {
+		fmt.Print(i18n.T("quick.checkpoint_failed", err))
+	}
+
+	return executeScanStages(config, result)
+}
+
+// summarizeEnhancements condenses an enhanced discovery summary for the quick result
+func summarizeEnhancements(enhanced *ops.EnhancedDiscoverSummary) *DiscoveryEnhancements {
+	summary := &DiscoveryEnhancements{
+		TargetsPrioritized: enhanced.TargetsPrioritized,
+		HighPriority:       enhanced.TargetPriorityStats[ops.PriorityHigh],
+		MediumPriority:     enhanced.TargetPriorityStats[ops.PriorityMedium],
+		LowPriority:        enhanced.TargetPriorityStats[ops.PriorityLow],
+		AdaptiveRateUsed:   enhanced.AdaptiveRateUsed,
+		RateAdjustments:    len(enhanced.RateAdjustments),
+		MethodFallbackUsed: enhanced.MethodFallbackUsed,
+		ActualMethods:      enhanced.ActualMethods,
+	}
+	if n := len(enhanced.RateAdjustments); n > 0 {
+		summary.FinalRate = enhanced.RateAdjustments[n-1].NewRate
+	}
+	return summary
+}
+
+// printEnhancements reports the discovery enhancements that were applied
+func printEnhancements(enhancements *DiscoveryEnhancements) {
+	if enhancements == nil {
+		return
+	}
+	fmt.Print(i18n.T("quick.enhanced.prioritized", enhancements.TargetsPrioritized,
+		enhancements.HighPriority, enhancements.MediumPriority, enhancements.LowPriority))
+	if enhancements.AdaptiveRateUsed && enhancements.RateAdjustments > 0 {
+		fmt.Print(i18n.T("quick.enhanced.rate", enhancements.RateAdjustments, enhancements.FinalRate))
+	}
+	if enhancements.MethodFallbackUsed {
+		fmt.Print(i18n.T("quick.enhanced.methods", strings.Join(enhancements.ActualMethods, ",")))
+	}
+}
+
+// executeScanStages runs port scanning and fingerprinting on the hosts found by discovery
+func executeScanStages(config *QuickConfig, result *QuickResult) error {
+	discoverResult := result.DiscoverResult
 
 	// Extract live hosts for port scanning
 	var liveHosts []string
@@ -350,34 +771,112 @@ func executeScanPipeline(config *QuickConfig) (*QuickResult, error) {
 	}
 
 	if len(liveHosts) == 0 {
-		fmt.Println("⚠️ 未发现活跃主机，跳过端口扫描")
+		fmt.Println(i18n.T("quick.no_live_hosts"))
 		result.Summary = QuickSummary{
 			HostsDiscovered: 0,
 			LiveHosts:       liveHosts,
 		}
-		return result, nil
+		return nil
 	}
 
 	// Phase 2: Port Scanning
-	fmt.Println("\n🔍 阶段 2: 端口扫描")
+	fmt.Println(i18n.T("quick.phase.scan"))
 	fmt.Println("==================")
 	
 	config.ScanOpts.Targets = liveHosts
-	
+
+	stopInterruptHandler := handleScanInterrupt(result.RunID)
 	scanResult, err := ops.ScanPorts(config.ScanOpts)
+	stopInterruptHandler()
 	if err != nil {
-		return nil, fmt.Errorf("port scanning failed: %w", err)
+		return fmt.Errorf("port scanning failed: %w", err)
 	}
 	
 	result.ScanResult = scanResult
 	
-	fmt.Printf("✅ 扫描完成：发现 %d 个开放端口 (耗时 %.1fs)\n", 
-		scanResult.OpenPorts, scanResult.Duration)
+	fmt.Print(i18n.T("quick.phase.scan_done", 
+		scanResult.OpenPorts, scanResult.Duration))
 
 	// Generate summary
 	result.Summary = generateSummary(discoverResult, scanResult)
+
+	// Phase 3: Service fingerprinting (optional)
+	if config.Fingerprint && scanResult.OpenPorts > 0 {
+		fmt.Println(i18n.T("quick.phase.fingerprint"))
+		fmt.Println("======================")
+
+		fingerprintStart := time.Now()
+		result.Fingerprints = fingerprintOpenPorts(scanResult)
+		result.Summary.Services = summarizeFingerprints(result.Fingerprints)
+
+		fmt.Print(i18n.T("quick.phase.fingerprint_done",
+			len(result.Summary.Services), time.Since(fingerprintStart).Seconds()))
+	}
+
+	// Classify discovered hosts into device categories
+	gatewayIP := ""
+	if config.Interface != nil && config.Interface.Gateway != nil {
+		gatewayIP = config.Interface.Gateway.IP
+	}
+	result.Summary.Devices = classifyDevices(result, gatewayIP)
 	
-	return result, nil
+	return nil
+}
+
+// fingerprintOpenPorts runs protocol fingerprinting against open ports, bounded by maxFingerprintTargets
+func fingerprintOpenPorts(scanResult *ops.ScanSummary) []*services.ProtocolFingerprint {
+	var targets []services.Target
+	for _, portResult := range scanResult.Results {
+		if portResult.Status != "open" {
+			continue
+		}
+		if len(targets) >= maxFingerprintTargets {
+			fmt.Print(i18n.T("quick.fingerprint_truncated", maxFingerprintTargets))
+			break
+		}
+		targets = append(targets, services.Target{Host: portResult.Host, Port: portResult.Port})
+	}
+
+	fingerprinter := services.NewProtocolFingerprinter(services.FingerprintConfig{
+		Timeout: 3 * time.Second,
+	})
+
+	return fingerprinter.FingerprintMultiple(targets, 20)
+}
+
+// summarizeFingerprints converts fingerprint results into summary entries
+func summarizeFingerprints(fingerprints []*services.ProtocolFingerprint) []ServiceDetail {
+	details := make([]ServiceDetail, 0, len(fingerprints))
+	for _, fp := range fingerprints {
+		if fp == nil || fp.Service == "" || fp.Service == "unknown" {
+			continue
+		}
+
+		detail := ServiceDetail{
+			Host:        fp.Host,
+			Port:        fp.Port,
+			Service:     fp.Service,
+			Application: fp.Application,
+			Version:     fp.Version,
+			Confidence:  fp.Confidence,
+		}
+		if fp.TLS != nil {
+			detail.TLSVersion = fp.TLS.Version
+			if fp.TLS.Certificate != nil {
+				detail.CertSubject = fp.TLS.Certificate.CommonName
+			}
+		}
+		details = append(details, detail)
+	}
+
+	sort.Slice(details, func(i, j int) bool {
+		if details[i].Host != details[j].Host {
+			return details[i].Host < details[j].Host
+		}
+		return details[i].Port < details[j].Port
+	})
+
+	return details
 }
 
 // generateSummary creates a high-level summary of results
@@ -397,25 +896,35 @@ func generateSummary(discoverResult *ops.DiscoverSummary, scanResult *ops.ScanSu
 		}
 	}
 
+	riskEngine := LoadRiskEngine()
+
 	// Analyze port scan results
 	for _, portResult := range scanResult.Results {
 		if portResult.Status == "open" {
 			service := "unknown"
+			version := ""
 			if portResult.Service != nil {
 				service = portResult.Service.Name
+				version = portResult.Service.Version
 			}
 			
 			// Count services
 			summary.TopServices[service]++
 			
 			// Identify critical ports
-			risk := assessPortRisk(portResult.Port, service)
-			if risk != "low" {
+			assessment := riskEngine.Evaluate(risk.Finding{
+				Host:    portResult.Host,
+				Port:    portResult.Port,
+				Service: service,
+				Version: version,
+			})
+			if assessment.Severity != "low" {
 				summary.CriticalPorts = append(summary.CriticalPorts, CriticalPort{
-					Host:    portResult.Host,
-					Port:    portResult.Port,
-					Service: service,
-					Risk:    risk,
+					Host:      portResult.Host,
+					Port:      portResult.Port,
+					Service:   service,
+					Risk:      assessment.Severity,
+					Rationale: assessment.Rationale,
 				})
 			}
 		}
@@ -424,73 +933,74 @@ func generateSummary(discoverResult *ops.DiscoverSummary, scanResult *ops.ScanSu
 	return summary
 }
 
-// assessPortRisk evaluates the security risk level of an open port
-func assessPortRisk(port int, service string) string {
-	// High risk ports
-	highRiskPorts := map[int]bool{
-		21: true,  // FTP
-		22: true,  // SSH (if exposed publicly)
-		23: true,  // Telnet
-		135: true, // RPC
-		139: true, // NetBIOS
-		445: true, // SMB
-		3389: true, // RDP
+// LoadRiskEngine loads the risk rules configured in preferences, falling back to the built-in rules
+func LoadRiskEngine() *risk.Engine {
+	rulesFile := ""
+	if cm, err := config.NewConfigManager(); err == nil {
+		rulesFile = cm.GetConfig().Preferences.RiskRulesFile
 	}
 
-	// Medium risk ports
-	mediumRiskPorts := map[int]bool{
-		80:   true, // HTTP
-		443:  true, // HTTPS
-		3306: true, // MySQL
-		5432: true, // PostgreSQL
-		27017: true, // MongoDB
-	}
-
-	if highRiskPorts[port] {
-		return "high"
-	}
-	if mediumRiskPorts[port] {
-		return "medium"
+	engine, err := risk.LoadEngine(rulesFile)
+	if err != nil {
+		fmt.Print(i18n.T("quick.risk_rules_failed", err))
+		engine, _ = risk.NewEngine(risk.DefaultRules)
 	}
-	return "low"
+	return engine
 }
 
 // saveResults saves the results to ~/.netcrate/runs/
 func saveResults(result *QuickResult) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+	if err := writeResultFile(result); err != nil {
+		return err
 	}
 
-	runsDir := filepath.Join(homeDir, ".netcrate", "runs")
-	err = os.MkdirAll(runsDir, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to create runs directory: %w", err)
-	}
+	fmt.Print(i18n.T("quick.saved", filepath.Dir(resultFilePath(result.RunID))))
+	return nil
+}
 
-	runDir := filepath.Join(runsDir, result.RunID)
-	err = os.MkdirAll(runDir, 0755)
-	if err != nil {
-		return fmt.Errorf("failed to create run directory: %w", err)
+// runsDirPath returns ~/.netcrate/runs
+func runsDirPath() string {
+	return store.Dir()
+}
+
+// resultFilePath returns ~/.netcrate/runs/<runID>/result.json
+func resultFilePath(runID string) string {
+	return store.ResultPath(runID)
+}
+
+// writeResultFile wraps the result in a run record and writes it to its run directory
+func writeResultFile(result *QuickResult) error {
+	targets := result.TargetCIDRs
+	if len(targets) == 0 && result.TargetCIDR != "" {
+		targets = []string{result.TargetCIDR}
 	}
 
-	// Save main result as JSON
-	resultFile := filepath.Join(runDir, "result.json")
-	file, err := os.Create(resultFile)
-	if err != nil {
-		return fmt.Errorf("failed to create result file: %w", err)
+	command := "quick"
+	if len(result.MergedFrom) > 0 {
+		command = "merge"
+	} else if result.Command != "" {
+		command = result.Command
 	}
-	defer file.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	err = encoder.Encode(result)
-	if err != nil {
-		return fmt.Errorf("failed to encode result: %w", err)
+	record := &store.RunRecord{
+		RunID:     result.RunID,
+		Type:      store.TypeQuick,
+		Command:   command,
+		StartTime: result.StartTime,
+		EndTime:   result.EndTime,
+		Duration:  result.Duration,
+		Status:    result.Status,
+		Targets:   targets,
+		Counts: map[string]int{
+			"hosts":      result.Summary.HostsDiscovered,
+			"open_ports": result.Summary.OpenPorts,
+			"critical":   len(result.Summary.CriticalPorts),
+		},
+		Labels:  result.Labels,
+		Context: result.Context,
 	}
 
-	fmt.Printf("✅ 结果已保存到: %s\n", runDir)
-	return nil
+	return store.Save(record, result)
 }
 
 // Helper functions
@@ -537,14 +1047,14 @@ func interactiveConfiguration(config *QuickConfig) error {
 
 // selectPortSet prompts user to select a port set
 func selectPortSet(config *QuickConfig) error {
-	fmt.Println("\n📊 选择端口集:")
-	fmt.Println("  1. top100    - 最常用100个端口 (默认)")
-	fmt.Println("  2. top1000   - 最常用1000个端口")
-	fmt.Println("  3. web       - Web服务端口")
-	fmt.Println("  4. database  - 数据库端口")
-	fmt.Println("  5. common    - 通用服务端口")
+	fmt.Println(i18n.T("quick.select.portset"))
+	fmt.Println(i18n.T("quick.select.portset.1"))
+	fmt.Println(i18n.T("quick.select.portset.2"))
+	fmt.Println(i18n.T("quick.select.portset.3"))
+	fmt.Println(i18n.T("quick.select.portset.4"))
+	fmt.Println(i18n.T("quick.select.portset.5"))
 	
-	fmt.Printf("请选择 (1-5) [默认: 1]: ")
+	fmt.Print(i18n.T("quick.choose_range", 5))
 	
 	scanner := bufio.NewScanner(os.Stdin)
 	if scanner.Scan() {
@@ -562,23 +1072,23 @@ func selectPortSet(config *QuickConfig) error {
 		case "5":
 			config.PortSet = "common"
 		default:
-			fmt.Printf("无效选择，使用默认值 (top100)\n")
+			fmt.Print(i18n.T("quick.invalid_choice", "top100"))
 			config.PortSet = "top100"
 		}
 	}
 	
-	fmt.Printf("✅ 端口集: %s\n", config.PortSet)
+	fmt.Print(i18n.T("quick.select.portset_done", config.PortSet))
 	return nil
 }
 
 // selectSpeedProfile prompts user to select a speed profile
 func selectSpeedProfile(config *QuickConfig) error {
-	fmt.Println("\n⚡ 选择速率档位:")
-	fmt.Println("  1. safe   - 安全模式 (100pps, 200并发) [默认]")
-	fmt.Println("  2. fast   - 快速模式 (400pps, 800并发)")
-	fmt.Println("  3. custom - 自定义参数")
+	fmt.Println(i18n.T("quick.select.profile"))
+	fmt.Println(i18n.T("quick.select.profile.1"))
+	fmt.Println(i18n.T("quick.select.profile.2"))
+	fmt.Println(i18n.T("quick.select.profile.3"))
 	
-	fmt.Printf("请选择 (1-3) [默认: 1]: ")
+	fmt.Print(i18n.T("quick.choose_range", 3))
 	
 	scanner := bufio.NewScanner(os.Stdin)
 	if scanner.Scan() {
@@ -593,41 +1103,41 @@ func selectSpeedProfile(config *QuickConfig) error {
 			config.Profile = "custom"
 			return selectCustomProfile(config)
 		default:
-			fmt.Printf("无效选择，使用默认值 (safe)\n")
+			fmt.Print(i18n.T("quick.invalid_choice", "safe"))
 			config.Profile = "safe"
 		}
 	}
 	
-	fmt.Printf("✅ 速率档位: %s\n", config.Profile)
+	fmt.Print(i18n.T("quick.select.profile_done", config.Profile))
 	return nil
 }
 
 // selectCustomProfile prompts for custom rate settings
 func selectCustomProfile(config *QuickConfig) error {
-	fmt.Println("\n🔧 自定义速率参数:")
+	fmt.Println(i18n.T("quick.select.custom"))
 	
 	// Get custom rate
-	fmt.Printf("扫描速率 (pps) [默认: 100]: ")
+	fmt.Print(i18n.T("quick.select.custom_rate"))
 	scanner := bufio.NewScanner(os.Stdin)
 	rate := 100
 	if scanner.Scan() {
 		input := strings.TrimSpace(scanner.Text())
 		if input != "" {
 			if r, err := fmt.Sscanf(input, "%d", &rate); err != nil || r != 1 {
-				fmt.Printf("无效输入，使用默认值 100\n")
+				fmt.Print(i18n.T("quick.invalid_input", 100))
 				rate = 100
 			}
 		}
 	}
 	
 	// Get custom concurrency
-	fmt.Printf("并发数 [默认: 200]: ")
+	fmt.Print(i18n.T("quick.select.custom_concurrency"))
 	concurrency := 200
 	if scanner.Scan() {
 		input := strings.TrimSpace(scanner.Text())
 		if input != "" {
 			if r, err := fmt.Sscanf(input, "%d", &concurrency); err != nil || r != 1 {
-				fmt.Printf("无效输入，使用默认值 200\n")
+				fmt.Print(i18n.T("quick.invalid_input", 200))
 				concurrency = 200
 			}
 		}
@@ -636,7 +1146,7 @@ func selectCustomProfile(config *QuickConfig) error {
 	// Store custom values in a special profile format
 	config.Profile = fmt.Sprintf("custom-%d-%d", rate, concurrency)
 	
-	fmt.Printf("✅ 自定义档位: %dpps, %d并发\n", rate, concurrency)
+	fmt.Print(i18n.T("quick.select.custom_done", rate, concurrency))
 	return nil
 }
 
@@ -655,14 +1165,25 @@ func applyConfiguration(config *QuickConfig) error {
 	
 	// Parse speed profile
 	rate, concurrency := parseSpeedProfile(config.Profile)
+	if config.MaxRate > 0 && rate > config.MaxRate {
+		rate = config.MaxRate
+	}
+	if config.MaxConcurrency > 0 && concurrency > config.MaxConcurrency {
+		concurrency = config.MaxConcurrency
+	}
+	config.Clamped = config.Ceilings.Apply(&rate, &concurrency, &ports)
 	
 	// Configure discovery options
 	config.DiscoverOpts = ops.DiscoverOptions{
-		Targets:     []string{config.TargetCIDR},
+		Targets:     config.TargetCIDRs,
 		Methods:     []string{"icmp", "tcp"},
 		Rate:        rate,
 		Concurrency: concurrency,
 		TCPPorts:    []int{22, 80, 443},
+		ResolveHostnames: true,
+		Exclude:     config.Excludes,
+		Scope:       config.Scope,
+		Classify:    config.Classify,
 	}
 
 	// Configure scan options
@@ -672,6 +1193,9 @@ func applyConfiguration(config *QuickConfig) error {
 		ServiceDetection: true,
 		Rate:             rate,
 		Concurrency:      concurrency,
+		Exclude:          config.Excludes,
+		Scope:            config.Scope,
+		Classify:         config.Classify,
 	}
 	
 	return nil
@@ -701,38 +1225,97 @@ func parseSpeedProfile(profile string) (int, int) {
 
 // PrintQuickSummary displays a formatted summary of results
 func PrintQuickSummary(result *QuickResult) {
-	fmt.Println("\n🎉 扫描完成！")
+	fmt.Println(i18n.T("quick.summary.title"))
 	fmt.Println("==============")
 	
-	fmt.Printf("运行ID: %s\n", result.RunID)
-	fmt.Printf("目标网段: %s\n", result.TargetCIDR)
-	fmt.Printf("总耗时: %.1f 秒\n", result.Duration)
+	fmt.Print(i18n.T("quick.summary.run_id", result.RunID))
+	fmt.Print(i18n.T("quick.summary.target", result.TargetCIDR))
+	fmt.Print(i18n.T("quick.summary.duration", result.Duration))
+	if result.Context != nil {
+		for _, clamp := range result.Context.Clamped {
+			fmt.Print(i18n.T("quick.summary.clamped", clamp))
+		}
+	}
 	
-	fmt.Println("\n📊 扫描结果")
+	fmt.Println(i18n.T("quick.summary.results"))
 	fmt.Println("============")
-	fmt.Printf("活跃主机: %d\n", result.Summary.HostsDiscovered)
-	fmt.Printf("开放端口: %d\n", result.Summary.OpenPorts)
+	fmt.Print(i18n.T("quick.summary.hosts", result.Summary.HostsDiscovered))
+	fmt.Print(i18n.T("quick.summary.open_ports", result.Summary.OpenPorts))
+	if result.Enhancements != nil {
+		fmt.Println(i18n.T("quick.summary.enhanced"))
+		printEnhancements(result.Enhancements)
+	}
 	
 	if len(result.Summary.LiveHosts) > 0 {
-		fmt.Println("\n🟢 活跃主机列表:")
+		fmt.Println(i18n.T("quick.summary.live_hosts"))
 		for _, host := range result.Summary.LiveHosts {
 			fmt.Printf("  • %s\n", host)
 		}
 	}
 	
 	if len(result.Summary.TopServices) > 0 {
-		fmt.Println("\n🔧 发现的服务:")
+		fmt.Println(i18n.T("quick.summary.services"))
 		for service, count := range result.Summary.TopServices {
-			fmt.Printf("  • %s: %d 个实例\n", service, count)
+			fmt.Print(i18n.T("quick.summary.service_count", service, count))
 		}
 	}
 	
 	if len(result.Summary.CriticalPorts) > 0 {
-		fmt.Println("\n⚠️ 关键端口 (需要注意):")
+		fmt.Println(i18n.T("quick.summary.critical"))
 		for _, cp := range result.Summary.CriticalPorts {
-			fmt.Printf("  • %s:%d (%s) - %s 风险\n", cp.Host, cp.Port, cp.Service, cp.Risk)
+			fmt.Print(i18n.T("quick.summary.critical_port", cp.Host, cp.Port, cp.Service, cp.Risk))
+			if cp.Rationale != "" {
+				fmt.Printf("    %s\n", cp.Rationale)
+			}
 		}
 	}
 	
-	fmt.Printf("\n💾 详细结果: netcrate output show --run %s\n", result.RunID)
+	if len(result.Summary.Services) > 0 {
+		fmt.Println(i18n.T("quick.summary.fingerprints"))
+		for _, svc := range result.Summary.Services {
+			app := svc.Application
+			if app == "" {
+				app = "-"
+			}
+			line := fmt.Sprintf("  • %s:%d %s (%s", svc.Host, svc.Port, svc.Service, app)
+			if svc.Version != "" {
+				line += " " + svc.Version
+			}
+			line += ")"
+			if svc.TLSVersion != "" {
+				line += fmt.Sprintf(" [%s", svc.TLSVersion)
+				if svc.CertSubject != "" {
+					line += ", CN=" + svc.CertSubject
+				}
+				line += "]"
+			}
+			fmt.Println(line)
+		}
+	}
+	
+	if len(result.Summary.Devices) > 0 {
+		fmt.Println(i18n.T("quick.summary.devices"))
+		fmt.Printf("  %-12s %-16s %-18s %-12s %s\n",
+			i18n.T("quick.summary.devices.category"), i18n.T("quick.summary.devices.host"),
+			i18n.T("quick.summary.devices.mac"), i18n.T("quick.summary.devices.vendor"),
+			i18n.T("quick.summary.devices.name"))
+		for _, device := range result.Summary.Devices {
+			name := device.Hostname
+			if name == "" {
+				name = device.MDNSName
+			}
+			fmt.Printf("  %-12s %-16s %-18s %-12s %s\n",
+				device.Category, device.Host, valueOrDash(device.MAC), valueOrDash(device.Vendor), valueOrDash(name))
+		}
+	}
+	
+	fmt.Print(i18n.T("quick.summary.details", result.RunID))
+}
+
+// valueOrDash returns "-" for empty table cells
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
 }
\ No newline at end of file
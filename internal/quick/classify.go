@@ -0,0 +1,260 @@
+package quick
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/netenv"
+	"github.com/netcrate/netcrate/internal/ops"
+)
+
+// Device categories
+const (
+	DeviceRouter      = "router"
+	DevicePrinter     = "printer"
+	DeviceNAS         = "nas"
+	DeviceCamera      = "camera"
+	DevicePhone       = "phone"
+	DeviceServer      = "server"
+	DeviceWorkstation = "workstation"
+	DeviceUnknown     = "unknown"
+)
+
+// DeviceInfo describes a classified host
+type DeviceInfo struct {
+	Host     string   `json:"host"`
+	MAC      string   `json:"mac,omitempty"`
+	Vendor   string   `json:"vendor,omitempty"`
+	Hostname string   `json:"hostname,omitempty"`
+	MDNSName string   `json:"mdns_name,omitempty"`
+	Category string   `json:"category"`
+	Reasons  []string `json:"reasons,omitempty"`
+}
+
+// ouiVendor maps an OUI prefix to a vendor and an optional category hint
+type ouiVendor struct {
+	Vendor   string
+	Category string
+}
+
+// knownOUIs is a small table of vendors that strongly suggest a device category
+var knownOUIs = map[string]ouiVendor{
+	"00:11:32": {"Synology", DeviceNAS},
+	"00:08:9B": {"QNAP", DeviceNAS},
+	"B8:27:EB": {"Raspberry Pi", DeviceServer},
+	"DC:A6:32": {"Raspberry Pi", DeviceServer},
+	"E4:5F:01": {"Raspberry Pi", DeviceServer},
+	"00:50:56": {"VMware", DeviceServer},
+	"00:0C:29": {"VMware", DeviceServer},
+	"24:A4:3C": {"Ubiquiti", DeviceRouter},
+	"F0:9F:C2": {"Ubiquiti", DeviceRouter},
+	"80:2A:A8": {"Ubiquiti", DeviceRouter},
+	"50:C7:BF": {"TP-Link", DeviceRouter},
+	"14:CC:20": {"TP-Link", DeviceRouter},
+	"44:19:B6": {"Hikvision", DeviceCamera},
+	"BC:AD:28": {"Hikvision", DeviceCamera},
+	"3C:EF:8C": {"Dahua", DeviceCamera},
+	"00:40:8C": {"Axis", DeviceCamera},
+	"AC:CC:8E": {"Axis", DeviceCamera},
+	"00:80:77": {"Brother", DevicePrinter},
+	"00:26:AB": {"Epson", DevicePrinter},
+	"F0:18:98": {"Apple", ""},
+	"F4:F5:D8": {"Google", ""},
+}
+
+// portHints maps well-known ports to the category they suggest and a weight
+var portHints = map[int]struct {
+	Category string
+	Weight   int
+}{
+	9100:  {DevicePrinter, 3},
+	515:   {DevicePrinter, 3},
+	631:   {DevicePrinter, 3},
+	554:   {DeviceCamera, 3},
+	8554:  {DeviceCamera, 3},
+	62078: {DevicePhone, 3},
+	548:   {DeviceNAS, 2},
+	2049:  {DeviceNAS, 2},
+	5000:  {DeviceNAS, 1},
+	5001:  {DeviceNAS, 1},
+	53:    {DeviceRouter, 2},
+	1900:  {DeviceRouter, 1},
+	3389:  {DeviceWorkstation, 2},
+	5900:  {DeviceWorkstation, 1},
+	22:    {DeviceServer, 1},
+	25:    {DeviceServer, 2},
+	3306:  {DeviceServer, 2},
+	5432:  {DeviceServer, 2},
+	6379:  {DeviceServer, 2},
+	27017: {DeviceServer, 2},
+}
+
+// keywordHints maps hostname/application keywords to categories
+var keywordHints = map[string]string{
+	"printer":     DevicePrinter,
+	"laserjet":    DevicePrinter,
+	"officejet":   DevicePrinter,
+	"epson":       DevicePrinter,
+	"brother":     DevicePrinter,
+	"cups":        DevicePrinter,
+	"nas":         DeviceNAS,
+	"synology":    DeviceNAS,
+	"diskstation": DeviceNAS,
+	"qnap":        DeviceNAS,
+	"truenas":     DeviceNAS,
+	"cam":         DeviceCamera,
+	"hikvision":   DeviceCamera,
+	"iphone":      DevicePhone,
+	"ipad":        DevicePhone,
+	"android":     DevicePhone,
+	"galaxy":      DevicePhone,
+	"pixel":       DevicePhone,
+	"router":      DeviceRouter,
+	"gateway":     DeviceRouter,
+	"openwrt":     DeviceRouter,
+	"routeros":    DeviceRouter,
+	"mikrotik":    DeviceRouter,
+	"fritz":       DeviceRouter,
+	"desktop":     DeviceWorkstation,
+	"laptop":      DeviceWorkstation,
+	"macbook":     DeviceWorkstation,
+	"imac":        DeviceWorkstation,
+	"server":      DeviceServer,
+	"srv":         DeviceServer,
+}
+
+// classifyDevices assigns a device category to every live host in the result
+func classifyDevices(result *QuickResult, gatewayIP string) []DeviceInfo {
+	if result.DiscoverResult == nil {
+		return nil
+	}
+
+	macs := ops.LookupMACAddresses()
+	mdnsNames := netenv.LookupMDNSNames(result.Summary.LiveHosts, 800*time.Millisecond, 32)
+
+	hostnames := make(map[string]string)
+	for _, hostResult := range result.DiscoverResult.Results {
+		if hostResult.Status == "up" && hostResult.Hostname != "" {
+			hostnames[hostResult.Host] = hostResult.Hostname
+		}
+	}
+
+	openPorts := make(map[string][]int)
+	if result.ScanResult != nil {
+		for _, portResult := range result.ScanResult.Results {
+			if portResult.Status == "open" {
+				openPorts[portResult.Host] = append(openPorts[portResult.Host], portResult.Port)
+			}
+		}
+	}
+
+	applications := make(map[string][]string)
+	for _, fp := range result.Fingerprints {
+		if fp == nil {
+			continue
+		}
+		if fp.Application != "" {
+			applications[fp.Host] = append(applications[fp.Host], fp.Application)
+		}
+		if fp.HTTP != nil {
+			applications[fp.Host] = append(applications[fp.Host], fp.HTTP.Server, fp.HTTP.Title)
+		}
+	}
+
+	var devices []DeviceInfo
+	for _, host := range result.Summary.LiveHosts {
+		device := DeviceInfo{
+			Host:     host,
+			MAC:      macs[host],
+			Hostname: hostnames[host],
+			MDNSName: mdnsNames[host],
+		}
+
+		scores := make(map[string]int)
+		addScore := func(category string, weight int, reason string) {
+			if category == "" {
+				return
+			}
+			scores[category] += weight
+			device.Reasons = append(device.Reasons, reason)
+		}
+
+		if host == gatewayIP {
+			addScore(DeviceRouter, 4, "default gateway")
+		}
+
+		if len(device.MAC) >= 8 {
+			if vendor, ok := knownOUIs[device.MAC[:8]]; ok {
+				device.Vendor = vendor.Vendor
+				addScore(vendor.Category, 3, "vendor "+vendor.Vendor)
+			}
+		}
+
+		for _, port := range openPorts[host] {
+			if hint, ok := portHints[port]; ok {
+				addScore(hint.Category, hint.Weight, fmt.Sprintf("port %d", port))
+			}
+		}
+		if hasPort(openPorts[host], 445) && !hasAnyPort(openPorts[host], 22, 25, 3306, 5432) {
+			addScore(DeviceWorkstation, 1, "smb without server ports")
+		}
+
+		texts := append([]string{device.Hostname, device.MDNSName}, applications[host]...)
+		for _, text := range texts {
+			lower := strings.ToLower(text)
+			if lower == "" {
+				continue
+			}
+			for keyword, category := range keywordHints {
+				if strings.Contains(lower, keyword) {
+					addScore(category, 2, fmt.Sprintf("%q matches %s", text, keyword))
+				}
+			}
+		}
+
+		device.Category = bestCategory(scores)
+		devices = append(devices, device)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].Category != devices[j].Category {
+			return devices[i].Category < devices[j].Category
+		}
+		return devices[i].Host < devices[j].Host
+	})
+
+	return devices
+}
+
+// bestCategory returns the highest-scoring category, breaking ties alphabetically
+func bestCategory(scores map[string]int) string {
+	best := DeviceUnknown
+	bestScore := 0
+	for category, score := range scores {
+		if score > bestScore || (score == bestScore && category < best) {
+			best = category
+			bestScore = score
+		}
+	}
+	return best
+}
+
+func hasPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPort(ports []int, candidates ...int) bool {
+	for _, candidate := range candidates {
+		if hasPort(ports, candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,267 @@
+package quick
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/compliance"
+	"github.com/netcrate/netcrate/internal/i18n"
+	"github.com/netcrate/netcrate/internal/netenv"
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/services"
+	"github.com/netcrate/netcrate/internal/style"
+)
+
+// DeepOptions controls a single-host deep dive
+type DeepOptions struct {
+	RunID       string // quick run to attach to; defaults to the latest run that saw the host
+	SkipConfirm bool
+	DryRun      bool
+	MaxHops     int
+}
+
+// DeepResult holds the results of a deep dive on one host
+type DeepResult struct {
+	RunID         string                          `json:"run_id"`
+	Host          string                          `json:"host"`
+	StartTime     time.Time                       `json:"start_time"`
+	EndTime       time.Time                       `json:"end_time"`
+	Duration      float64                         `json:"duration"`
+	PreviousPorts []int                           `json:"previous_ports,omitempty"`
+	ScanResult    *ops.ScanSummary                `json:"scan_result"`
+	Fingerprints  []*services.ProtocolFingerprint `json:"fingerprints,omitempty"`
+	TLSAudits     []*services.TLSAudit            `json:"tls_audits,omitempty"`
+	Traceroute    []netenv.TraceHop               `json:"traceroute,omitempty"`
+	NewPorts      []int                           `json:"new_ports,omitempty"`
+}
+
+// RunDeepDive runs an intensive single-host workup (full port range, fingerprinting,
+// TLS audit and traceroute) and stores it in the quick run that discovered the host
+func RunDeepDive(host string, opts DeepOptions) (*DeepResult, error) {
+	ip := net.ParseIP(host)
+	if ip == nil || ip.To4() == nil {
+		return nil, fmt.Errorf("invalid host %s: must be an IPv4 address", host)
+	}
+	if !isPrivateIP(ip) {
+		return nil, compliance.Blocked(i18n.T("quick.public_network", host))
+	}
+
+	var previous *QuickResult
+	var err error
+	if opts.RunID != "" {
+		previous, err = loadResultFile(opts.RunID)
+	} else {
+		previous, err = findLatestRunForHost(host)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	style.Println("🚀 NetCrate Quick Mode (deep)")
+	fmt.Println("=============================")
+	fmt.Print(i18n.T("quick.deep.host", host))
+	fmt.Print(i18n.T("quick.resume.run", previous.RunID))
+
+	result := &DeepResult{
+		RunID:         previous.RunID,
+		Host:          host,
+		PreviousPorts: hostOpenPorts(previous, host),
+	}
+	fmt.Print(i18n.T("quick.deep.previous_ports", formatPorts(result.PreviousPorts)))
+
+	rate, concurrency := parseSpeedProfile(previous.Profile)
+	ports, _ := ops.ParsePortSpec("1-65535")
+	scanOpts := ops.ScanOptions{
+		Targets:          []string{host},
+		Ports:            ports,
+		ServiceDetection: true,
+		Rate:             rate,
+		Concurrency:      concurrency,
+	}
+
+	if !opts.SkipConfirm {
+		estimate := EstimateScan([]string{host + "/32"}, len(ports), rate)
+		fmt.Print(i18n.T("quick.deep.plan", len(ports), formatEstimateDuration(estimate.MaxDuration)))
+		if !getUserConfirmation() {
+			fmt.Println(i18n.T("quick.cancelled"))
+			return nil, fmt.Errorf("user cancelled")
+		}
+	}
+
+	if opts.DryRun {
+		fmt.Println(i18n.T("quick.dry_run"))
+		return result, nil
+	}
+
+	result.StartTime = time.Now()
+
+	// Phase 1: Full port range
+	fmt.Println(i18n.T("quick.deep.phase.scan"))
+	scanResult, err := ops.ScanPorts(scanOpts)
+	if err != nil {
+		return nil, fmt.Errorf("port scanning failed: %w", err)
+	}
+	result.ScanResult = scanResult
+	fmt.Print(i18n.T("quick.phase.scan_done", scanResult.OpenPorts, scanResult.Duration))
+
+	seen := toSet(intsToStrings(result.PreviousPorts))
+	for _, port := range hostOpenPorts(&QuickResult{ScanResult: scanResult}, host) {
+		if !seen[fmt.Sprintf("%d", port)] {
+			result.NewPorts = append(result.NewPorts, port)
+		}
+	}
+
+	// Phase 2: Fingerprinting
+	fmt.Println(i18n.T("quick.deep.phase.fingerprint"))
+	fingerprintStart := time.Now()
+	result.Fingerprints = fingerprintOpenPorts(scanResult)
+	fmt.Print(i18n.T("quick.phase.fingerprint_done", len(result.Fingerprints), time.Since(fingerprintStart).Seconds()))
+
+	// Phase 3: TLS audit on every port that completed a TLS handshake
+	fmt.Println(i18n.T("quick.deep.phase.tls"))
+	for _, fp := range result.Fingerprints {
+		if fp == nil || fp.TLS == nil {
+			continue
+		}
+		audit, err := services.AuditTLS(fp.Host, fp.Port, 5*time.Second)
+		if err != nil {
+			fmt.Print(i18n.T("quick.deep.tls_failed", fp.Port, err))
+			continue
+		}
+		result.TLSAudits = append(result.TLSAudits, audit)
+	}
+
+	// Phase 4: Traceroute
+	fmt.Println(i18n.T("quick.deep.phase.traceroute"))
+	hops, err := netenv.Traceroute(host, opts.MaxHops, time.Second)
+	if err != nil {
+		fmt.Print(i18n.T("quick.deep.traceroute_failed", err))
+	}
+	result.Traceroute = hops
+
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime).Seconds()
+
+	path, err := writeDeepResult(result)
+	if err != nil {
+		fmt.Print(i18n.T("quick.save_failed", err))
+	} else {
+		fmt.Print(i18n.T("quick.saved", path))
+	}
+
+	return result, nil
+}
+
+// PrintDeepSummary displays the results of a deep dive
+func PrintDeepSummary(result *DeepResult) {
+	fmt.Println(i18n.T("quick.summary.title"))
+	fmt.Println("==============")
+	fmt.Print(i18n.T("quick.deep.host", result.Host))
+	fmt.Print(i18n.T("quick.summary.duration", result.Duration))
+
+	if result.ScanResult != nil {
+		fmt.Print(i18n.T("quick.summary.open_ports", result.ScanResult.OpenPorts))
+		for _, portResult := range result.ScanResult.Results {
+			if portResult.Status != "open" {
+				continue
+			}
+			service := "-"
+			if portResult.Service != nil {
+				service = portResult.Service.Name
+			}
+			fmt.Printf("  • %d/%s %s\n", portResult.Port, portResult.Protocol, service)
+		}
+	}
+
+	if len(result.NewPorts) > 0 {
+		fmt.Print(i18n.T("quick.deep.new_ports", formatPorts(result.NewPorts)))
+	}
+
+	if len(result.TLSAudits) > 0 {
+		fmt.Println(i18n.T("quick.deep.tls"))
+		for _, audit := range result.TLSAudits {
+			fmt.Printf("  • %d: %s\n", audit.Port, strings.Join(audit.SupportedVersions, ", "))
+			for _, issue := range audit.Issues {
+				style.Printf("    ⚠️ %s\n", issue)
+			}
+		}
+	}
+
+	if len(result.Traceroute) > 0 {
+		fmt.Println(i18n.T("quick.deep.traceroute"))
+		for _, hop := range result.Traceroute {
+			if hop.Address == "" {
+				fmt.Printf("  %2d  *\n", hop.TTL)
+				continue
+			}
+			fmt.Printf("  %2d  %-16s %.1fms\n", hop.TTL, hop.Address, hop.RTT)
+		}
+	}
+}
+
+// findLatestRunForHost returns the most recent quick run in which host was live
+func findLatestRunForHost(host string) (*QuickResult, error) {
+	entries, err := os.ReadDir(runsDirPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runs directory: %w", err)
+	}
+
+	sortRunsNewestFirst(entries)
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "quick_") {
+			continue
+		}
+		result, err := loadResultFile(entry.Name())
+		if err != nil {
+			continue
+		}
+		for _, live := range result.Summary.LiveHosts {
+			if live == host {
+				return result, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no quick run has seen host %s; run 'netcrate quick' first or pass --run", host)
+}
+
+// hostOpenPorts returns the sorted open ports recorded for host
+func hostOpenPorts(result *QuickResult, host string) []int {
+	var ports []int
+	if result.ScanResult == nil {
+		return ports
+	}
+	for _, portResult := range result.ScanResult.Results {
+		if portResult.Host == host && portResult.Status == "open" {
+			ports = append(ports, portResult.Port)
+		}
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// writeDeepResult stores the deep dive next to the quick result as deep_<host>.json
+func writeDeepResult(result *DeepResult) (string, error) {
+	return store.WriteRunFile(result.RunID, fmt.Sprintf("deep_%s.json", result.Host), result)
+}
+
+func formatPorts(ports []int) string {
+	if len(ports) == 0 {
+		return "-"
+	}
+	return strings.Join(intsToStrings(ports), ",")
+}
+
+func intsToStrings(values []int) []string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = fmt.Sprintf("%d", v)
+	}
+	return strs
+}
@@ -0,0 +1,114 @@
+package quick
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/i18n"
+)
+
+const (
+	// discoveryProbesPerHost covers one ICMP echo plus TCP probes to 22, 80 and 443
+	discoveryProbesPerHost = 4
+	// bytesPerProbe approximates a probe and its reply on the wire
+	bytesPerProbe = 128
+	// expectedLiveRatio is the share of addresses assumed to answer discovery
+	expectedLiveRatio = 0.25
+)
+
+// ScanEstimate is a rough probe and time budget for a quick run
+type ScanEstimate struct {
+	Addresses       int           `json:"addresses"`
+	DiscoveryProbes int           `json:"discovery_probes"`
+	ScanProbes      int           `json:"scan_probes"`     // assuming expectedLiveRatio of hosts are live
+	MaxScanProbes   int           `json:"max_scan_probes"` // if every address is live
+	Rate            int           `json:"rate"`
+	Duration        time.Duration `json:"duration"`
+	MaxDuration     time.Duration `json:"max_duration"`
+	BytesPerSecond  int           `json:"bytes_per_second"`
+}
+
+// EstimateScan computes the probe budget for the given targets, port count and rate
+func EstimateScan(targets []string, portCount, rate int) ScanEstimate {
+	estimate := ScanEstimate{Rate: rate}
+	for _, target := range targets {
+		estimate.Addresses += countHostAddresses(target)
+	}
+
+	expectedLive := int(float64(estimate.Addresses)*expectedLiveRatio + 0.5)
+	if expectedLive == 0 && estimate.Addresses > 0 {
+		expectedLive = 1
+	}
+
+	estimate.DiscoveryProbes = estimate.Addresses * discoveryProbesPerHost
+	estimate.ScanProbes = expectedLive * portCount
+	estimate.MaxScanProbes = estimate.Addresses * portCount
+
+	if rate > 0 {
+		estimate.Duration = probeDuration(estimate.DiscoveryProbes+estimate.ScanProbes, rate)
+		estimate.MaxDuration = probeDuration(estimate.DiscoveryProbes+estimate.MaxScanProbes, rate)
+		estimate.BytesPerSecond = rate * bytesPerProbe
+	}
+
+	return estimate
+}
+
+// countHostAddresses returns the number of scannable addresses in a CIDR,
+// excluding network and broadcast addresses for prefixes shorter than /31
+func countHostAddresses(cidr string) int {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return 0
+	}
+
+	ones, bits := ipnet.Mask.Size()
+	size := 1 << uint(bits-ones)
+	if bits-ones >= 2 {
+		size -= 2
+	}
+	return size
+}
+
+func probeDuration(probes, rate int) time.Duration {
+	return time.Duration(float64(probes) / float64(rate) * float64(time.Second)).Round(time.Second)
+}
+
+// printEstimate shows the probe budget for the selected profile alongside the
+// built-in profiles so users can compare them before confirming
+func printEstimate(config *QuickConfig) {
+	portCount := len(config.ScanOpts.Ports)
+	estimate := EstimateScan(config.TargetCIDRs, portCount, config.ScanOpts.Rate)
+
+	fmt.Print(i18n.T("quick.estimate.header"))
+	fmt.Print(i18n.T("quick.estimate.probes", estimate.Addresses, estimate.DiscoveryProbes, estimate.ScanProbes, int(expectedLiveRatio*100)))
+	fmt.Print(i18n.T("quick.estimate.duration", formatEstimateDuration(estimate.Duration), formatEstimateDuration(estimate.MaxDuration)))
+	fmt.Print(i18n.T("quick.estimate.bandwidth", formatBandwidth(estimate.BytesPerSecond)))
+
+	for _, profile := range []string{"safe", "fast"} {
+		if profile == config.Profile {
+			continue
+		}
+		rate, _ := parseSpeedProfile(profile)
+		alt := EstimateScan(config.TargetCIDRs, portCount, rate)
+		fmt.Print(i18n.T("quick.estimate.alternative", profile, formatEstimateDuration(alt.Duration), formatBandwidth(alt.BytesPerSecond)))
+	}
+}
+
+func formatEstimateDuration(d time.Duration) string {
+	if d < time.Second {
+		return "<1s"
+	}
+	return d.String()
+}
+
+func formatBandwidth(bytesPerSecond int) string {
+	switch {
+	case bytesPerSecond >= 1024*1024:
+		return fmt.Sprintf("%.1f MB/s", float64(bytesPerSecond)/(1024*1024))
+	case bytesPerSecond >= 1024:
+		return fmt.Sprintf("%.1f KB/s", float64(bytesPerSecond)/1024)
+	default:
+		return fmt.Sprintf("%d B/s", bytesPerSecond)
+	}
+}
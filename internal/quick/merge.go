@@ -0,0 +1,210 @@
+package quick
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/services"
+	"github.com/netcrate/netcrate/internal/version"
+)
+
+// MergeResults unions several runs into one aggregate result. Parts are
+// applied in start time order, so a later observation of the same host or
+// port replaces an earlier one. A part may carry only a discovery or only a
+// port scan, as converted from standalone discover and scan runs.
+func MergeResults(runID string, parts []*QuickResult) (*QuickResult, error) {
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("at least two runs are required to merge")
+	}
+
+	sort.SliceStable(parts, func(i, j int) bool {
+		return parts[i].StartTime.Before(parts[j].StartTime)
+	})
+
+	merged := &QuickResult{
+		RunID:     runID,
+		Status:    "complete",
+		StartTime: parts[0].StartTime,
+		Context:   &store.RunContext{Version: version.Version},
+	}
+
+	hosts := make(map[string]ops.DiscoverResult)
+	ports := make(map[string]ops.ScanResult)
+	fingerprints := make(map[string]*services.ProtocolFingerprint)
+	devices := make(map[string]DeviceInfo)
+	var hostOrder, portOrder, fingerprintOrder []string
+	var methods []string
+	targetsSeen := make(map[string]bool)
+
+	for _, part := range parts {
+		merged.MergedFrom = append(merged.MergedFrom, part.RunID)
+		merged.Duration += part.Duration
+		for _, t := range []time.Time{part.StartTime, part.EndTime} {
+			if t.After(merged.EndTime) {
+				merged.EndTime = t
+			}
+		}
+
+		targets := part.TargetCIDRs
+		if len(targets) == 0 && part.TargetCIDR != "" {
+			targets = []string{part.TargetCIDR}
+		}
+		for _, target := range targets {
+			if !targetsSeen[target] {
+				targetsSeen[target] = true
+				merged.TargetCIDRs = append(merged.TargetCIDRs, target)
+			}
+		}
+
+		if part.DiscoverResult != nil {
+			for _, method := range part.DiscoverResult.MethodUsed {
+				if !containsMethod(methods, method) {
+					methods = append(methods, method)
+				}
+			}
+			for _, host := range part.DiscoverResult.Results {
+				previous, seen := hosts[host.Host]
+				if !seen {
+					hostOrder = append(hostOrder, host.Host)
+				}
+				// A host seen up in any run stays up in the aggregate
+				if !seen || host.Status == "up" || previous.Status != "up" {
+					hosts[host.Host] = host
+				}
+			}
+		}
+
+		if part.ScanResult != nil {
+			for _, port := range part.ScanResult.Results {
+				protocol := port.Protocol
+				if protocol == "" {
+					protocol = "tcp"
+				}
+				key := fmt.Sprintf("%s/%d/%s", port.Host, port.Port, protocol)
+				if _, seen := ports[key]; !seen {
+					portOrder = append(portOrder, key)
+				}
+				ports[key] = port
+			}
+		}
+
+		for _, fp := range part.Fingerprints {
+			if fp == nil {
+				continue
+			}
+			key := fmt.Sprintf("%s:%d", fp.Host, fp.Port)
+			if _, seen := fingerprints[key]; !seen {
+				fingerprintOrder = append(fingerprintOrder, key)
+			}
+			fingerprints[key] = fp
+		}
+
+		for _, device := range part.Summary.Devices {
+			devices[device.Host] = device
+		}
+	}
+
+	// Hosts only known from a port scan were evidently up
+	for _, key := range portOrder {
+		port := ports[key]
+		if port.Status != "open" {
+			continue
+		}
+		if host, seen := hosts[port.Host]; !seen || host.Status != "up" {
+			if !seen {
+				hostOrder = append(hostOrder, port.Host)
+			}
+			hosts[port.Host] = ops.DiscoverResult{Host: port.Host, Status: "up", Method: "scan", Timestamp: port.Timestamp}
+		}
+	}
+
+	discover := &ops.DiscoverSummary{
+		RunID:        runID,
+		StartTime:    merged.StartTime,
+		EndTime:      merged.EndTime,
+		Duration:     merged.Duration,
+		TargetsInput: strings.Join(merged.TargetCIDRs, ","),
+		MethodUsed:   methods,
+	}
+	for _, host := range hostOrder {
+		result := hosts[host]
+		discover.Results = append(discover.Results, result)
+		if result.Status == "up" {
+			discover.HostsDiscovered++
+		}
+	}
+	discover.TargetsResolved = len(discover.Results)
+	if discover.TargetsResolved > 0 {
+		discover.SuccessRate = float64(discover.HostsDiscovered) / float64(discover.TargetsResolved)
+	}
+
+	scan := &ops.ScanSummary{
+		RunID:     runID,
+		StartTime: merged.StartTime,
+		EndTime:   merged.EndTime,
+		Duration:  merged.Duration,
+	}
+	scannedHosts := make(map[string]bool)
+	for _, key := range portOrder {
+		port := ports[key]
+		scan.Results = append(scan.Results, port)
+		scannedHosts[port.Host] = true
+		switch port.Status {
+		case "open":
+			scan.OpenPorts++
+		case "closed":
+			scan.ClosedPorts++
+		default:
+			scan.FilteredPorts++
+		}
+	}
+	scan.TargetsCount = len(scannedHosts)
+	scan.TotalCombinations = len(scan.Results)
+
+	merged.DiscoverResult = discover
+	merged.ScanResult = scan
+	for _, key := range fingerprintOrder {
+		merged.Fingerprints = append(merged.Fingerprints, fingerprints[key])
+	}
+	if len(merged.TargetCIDRs) == 1 {
+		merged.TargetCIDR = merged.TargetCIDRs[0]
+	} else {
+		merged.TargetCIDR = strings.Join(merged.TargetCIDRs, ", ")
+	}
+
+	merged.Summary = generateSummary(discover, scan)
+	merged.Summary.Services = summarizeFingerprints(merged.Fingerprints)
+	for _, host := range merged.Summary.LiveHosts {
+		if device, ok := devices[host]; ok {
+			merged.Summary.Devices = append(merged.Summary.Devices, device)
+		}
+	}
+	sort.Slice(merged.Summary.Devices, func(i, j int) bool {
+		a, b := merged.Summary.Devices[i], merged.Summary.Devices[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		return a.Host < b.Host
+	})
+
+	return merged, nil
+}
+
+// SaveResult writes a result built outside RunQuickMode, such as a merged
+// run or one made by the scan command
+func SaveResult(result *QuickResult) error {
+	return writeResultFile(result)
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
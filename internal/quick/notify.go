@@ -0,0 +1,136 @@
+package quick
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/config"
+	"github.com/netcrate/netcrate/internal/i18n"
+	"github.com/netcrate/netcrate/internal/notify"
+	"github.com/netcrate/netcrate/internal/risk"
+	"github.com/netcrate/netcrate/internal/runid"
+)
+
+// QuickNotification is the webhook payload sent when a quick run completes
+type QuickNotification struct {
+	RunID         string         `json:"run_id"`
+	PreviousRunID string         `json:"previous_run_id,omitempty"`
+	Target        string         `json:"target"`
+	Summary       QuickSummary   `json:"summary"`
+	NewCritical   []CriticalPort `json:"new_critical,omitempty"`
+}
+
+// notifyCompletion sends the configured notifications for a finished run.
+// With the default "new-critical" trigger, nothing is sent unless a high or
+// critical port appeared that the previous run did not have.
+func notifyCompletion(result *QuickResult) {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return
+	}
+	cfg := cm.GetConfig().Notifications
+
+	notifier := notify.New(cfg)
+	if !notifier.Enabled() {
+		return
+	}
+
+	previous := findPreviousRun(result.RunID)
+	newCritical := newCriticalPorts(previous, result)
+	if cfg.Trigger != "always" && len(newCritical) == 0 {
+		return
+	}
+
+	payload := QuickNotification{
+		RunID:       result.RunID,
+		Target:      result.TargetCIDR,
+		Summary:     result.Summary,
+		NewCritical: newCritical,
+	}
+	if previous != nil {
+		payload.PreviousRunID = previous.RunID
+	}
+
+	var lines []string
+	lines = append(lines, i18n.T("quick.notify.counts", result.Summary.HostsDiscovered, result.Summary.OpenPorts))
+	for _, cp := range newCritical {
+		lines = append(lines, fmt.Sprintf("• %s:%d %s (%s)", cp.Host, cp.Port, cp.Service, cp.Risk))
+	}
+
+	msg := notify.Message{
+		Event:   "quick.complete",
+		Title:   i18n.T("quick.notify.title", result.RunID, result.TargetCIDR),
+		Text:    strings.Join(lines, "\n"),
+		Payload: payload,
+	}
+	if len(newCritical) > 0 {
+		msg.Title = i18n.T("quick.notify.title_critical", len(newCritical), result.TargetCIDR)
+	}
+
+	if err := notifier.Send(msg); err != nil {
+		fmt.Print(i18n.T("quick.notify.failed", err))
+		return
+	}
+	fmt.Println(i18n.T("quick.notify.sent"))
+}
+
+// newCriticalPorts returns high and critical ports in current that previous did not report
+func newCriticalPorts(previous, current *QuickResult) []CriticalPort {
+	known := make(map[string]bool)
+	if previous != nil {
+		for _, cp := range previous.Summary.CriticalPorts {
+			known[fmt.Sprintf("%s:%d", cp.Host, cp.Port)] = true
+		}
+	}
+
+	var result []CriticalPort
+	for _, cp := range current.Summary.CriticalPorts {
+		if risk.CompareSeverity(cp.Risk, "high") < 0 {
+			continue
+		}
+		if !known[fmt.Sprintf("%s:%d", cp.Host, cp.Port)] {
+			result = append(result, cp)
+		}
+	}
+	return result
+}
+
+// findPreviousRun returns the most recent completed quick run before runID, if any
+func findPreviousRun(runID string) *QuickResult {
+	entries, err := os.ReadDir(runsDirPath())
+	if err != nil {
+		return nil
+	}
+
+	sortRunsNewestFirst(entries)
+	started := runStartTime(runID)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || !strings.HasPrefix(name, "quick_") || !runStartTime(name).Before(started) {
+			continue
+		}
+		result, err := loadResultFile(name)
+		if err == nil && result.Status == "complete" {
+			return result
+		}
+	}
+	return nil
+}
+
+// sortRunsNewestFirst orders run directories by the start time in their IDs.
+// Names alone do not sort: older IDs hold unix seconds, newer ones a ULID.
+func sortRunsNewestFirst(entries []os.DirEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return runStartTime(entries[i].Name()).After(runStartTime(entries[j].Name()))
+	})
+}
+
+// runStartTime returns the start time encoded in a run ID, or the zero time
+func runStartTime(id string) time.Time {
+	t, _ := runid.Time(id)
+	return t
+}
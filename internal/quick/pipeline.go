@@ -0,0 +1,43 @@
+package quick
+
+import (
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/services"
+)
+
+// The scan command runs the discovery, port scan and fingerprint chain of
+// quick mode with its own options and stores the outcome as a quick result,
+// so output show, report, merge and trends and quick deep treat both alike.
+
+// Summarize fills in the summary of a result assembled outside the wizard
+// from its discovery, port scan and fingerprints
+func Summarize(result *QuickResult) {
+	if result.DiscoverResult == nil {
+		return
+	}
+	if result.ScanResult == nil {
+		result.Summary = QuickSummary{HostsDiscovered: result.DiscoverResult.HostsDiscovered}
+		for _, host := range result.DiscoverResult.Results {
+			if host.Status == "up" {
+				result.Summary.LiveHosts = append(result.Summary.LiveHosts, host.Host)
+			}
+		}
+		return
+	}
+
+	result.Summary = generateSummary(result.DiscoverResult, result.ScanResult)
+	if len(result.Fingerprints) > 0 {
+		result.Summary.Services = summarizeFingerprints(result.Fingerprints)
+	}
+	gatewayIP := ""
+	if result.Interface != nil && result.Interface.Gateway != nil {
+		gatewayIP = result.Interface.Gateway.IP
+	}
+	result.Summary.Devices = classifyDevices(result, gatewayIP)
+}
+
+// FingerprintOpenPorts identifies the applications on the open ports of a
+// scan, as quick --fingerprint does
+func FingerprintOpenPorts(scanResult *ops.ScanSummary) []*services.ProtocolFingerprint {
+	return fingerprintOpenPorts(scanResult)
+}
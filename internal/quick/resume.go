@@ -0,0 +1,194 @@
+package quick
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/exitcode"
+	"github.com/netcrate/netcrate/internal/i18n"
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/output/store"
+	"github.com/netcrate/netcrate/internal/style"
+)
+
+// ResumeQuickMode continues an interrupted quick run from the port scanning stage
+func ResumeQuickMode(runID string, opts QuickOptions) (*QuickResult, error) {
+	result, err := loadResultFile(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Status == "complete" {
+		return nil, fmt.Errorf("run %s is already complete", runID)
+	}
+	if result.DiscoverResult == nil {
+		return nil, fmt.Errorf("run %s has no saved discovery results to resume from", runID)
+	}
+
+	style.Println("🚀 NetCrate Quick Mode (resume)")
+	fmt.Println("===============================")
+	fmt.Print(i18n.T("quick.resume.run", result.RunID))
+	fmt.Print(i18n.T("quick.config.target", result.TargetCIDR))
+	fmt.Print(i18n.T("quick.resume.discovery", result.DiscoverResult.HostsDiscovered))
+
+	config := &QuickConfig{
+		Interface:      result.Interface,
+		TargetCIDR:     result.TargetCIDR,
+		TargetCIDRs:    result.TargetCIDRs,
+		PortSet:        result.PortSet,
+		Profile:        result.Profile,
+		SkipConfirm:    opts.SkipConfirm,
+		Fingerprint:    result.Fingerprint || opts.Fingerprint,
+		MaxRate:        opts.MaxRate,
+		MaxConcurrency: opts.MaxConcurrency,
+		Scope:          opts.Scope,
+		Ceilings:       opts.Ceilings,
+		Classify:       opts.Classify,
+	}
+	// The exclusions of this invocation apply to the hosts the interrupted
+	// run discovered, along with the ones that run was started with
+	config.Excludes = mergeExclusions(result.Excludes, buildExclusions(config.Interface, opts))
+	if err := excludeDiscovered(result.DiscoverResult, config.Excludes); err != nil {
+		return nil, err
+	}
+	result.Excludes = config.Excludes
+	if len(config.TargetCIDRs) == 0 && config.TargetCIDR != "" {
+		config.TargetCIDRs = []string{config.TargetCIDR}
+	}
+	if config.Profile == "" {
+		config.Profile = "safe"
+	}
+	if err := applyConfiguration(config); err != nil {
+		return nil, fmt.Errorf("failed to apply configuration: %w", err)
+	}
+	if opts.Preflight != nil {
+		if err := opts.Preflight(config.TargetCIDRs, config.Clamped); err != nil {
+			return nil, fmt.Errorf("compliance pre-flight failed: %w", err)
+		}
+	}
+
+	if !opts.SkipConfirm {
+		fmt.Println(i18n.T("quick.resume.confirm"))
+		fmt.Println("==================")
+		printConfiguration(config)
+
+		if !getUserConfirmation() {
+			fmt.Println(i18n.T("quick.cancelled"))
+			return nil, fmt.Errorf("user cancelled")
+		}
+	}
+
+	if opts.DryRun {
+		fmt.Println(i18n.T("quick.dry_run"))
+		return result, nil
+	}
+
+	result.Fingerprint = config.Fingerprint
+	if result.Context != nil {
+		result.Context.Clamped = config.Clamped
+		if opts.Authorization != nil {
+			result.Context.Authorization = opts.Authorization
+		}
+	}
+	if err := executeScanStages(config, result); err != nil {
+		return nil, fmt.Errorf("scan pipeline failed: %w", err)
+	}
+
+	result.Status = "complete"
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime).Seconds()
+
+	if err := saveResults(result); err != nil {
+		fmt.Print(i18n.T("quick.save_failed", err))
+	}
+
+	notifyCompletion(result)
+
+	return result, nil
+}
+
+// mergeExclusions returns the entries of both lists, once each
+func mergeExclusions(saved, current []string) []string {
+	merged := append([]string{}, saved...)
+	seen := toSet(saved)
+	for _, entry := range current {
+		if !seen[entry] {
+			seen[entry] = true
+			merged = append(merged, entry)
+		}
+	}
+	return merged
+}
+
+// excludeDiscovered drops the excluded hosts from saved discovery results,
+// so a resumed run does not scan them
+func excludeDiscovered(discover *ops.DiscoverSummary, excludes []string) error {
+	if len(excludes) == 0 {
+		return nil
+	}
+	hosts := make([]string, 0, len(discover.Results))
+	for _, hostResult := range discover.Results {
+		hosts = append(hosts, hostResult.Host)
+	}
+	kept, err := ops.ResolveTargets(hosts, excludes)
+	if err != nil {
+		return fmt.Errorf("invalid exclusion: %w", err)
+	}
+
+	keep := toSet(kept)
+	var results []ops.DiscoverResult
+	for _, hostResult := range discover.Results {
+		if keep[hostResult.Host] {
+			results = append(results, hostResult)
+		} else if hostResult.Status == "up" {
+			discover.HostsDiscovered--
+		}
+	}
+	discover.Results = results
+	return nil
+}
+
+// loadResultFile reads a saved quick result by run ID
+func loadResultFile(runID string) (*QuickResult, error) {
+	record, err := store.Load(runID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Type != store.TypeQuick {
+		return nil, fmt.Errorf("run '%s' is a %s run, not a quick run", record.RunID, record.Type)
+	}
+
+	var result QuickResult
+	if err := record.Decode(&result); err != nil {
+		return nil, err
+	}
+	result.Context = record.Context
+
+	return &result, nil
+}
+
+// handleScanInterrupt reports how to resume if the scan stage is interrupted.
+// The returned function must be called once the stage completes.
+func handleScanInterrupt(runID string) func() {
+	sigChan := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-sigChan:
+			fmt.Print(i18n.T("quick.resume.interrupted"))
+			fmt.Print(i18n.T("quick.resume.hint", runID))
+			os.Exit(exitcode.Interrupted)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
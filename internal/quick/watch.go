@@ -0,0 +1,177 @@
+package quick
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/i18n"
+	"github.com/netcrate/netcrate/internal/output/store"
+)
+
+// QuickDiff describes the changes between two quick mode runs
+type QuickDiff struct {
+	Timestamp     time.Time `json:"timestamp"`
+	PreviousRunID string    `json:"previous_run_id"`
+	CurrentRunID  string    `json:"current_run_id"`
+	NewHosts      []string  `json:"new_hosts,omitempty"`
+	GoneHosts     []string  `json:"gone_hosts,omitempty"`
+	NewPorts      []string  `json:"new_ports,omitempty"`    // host:port
+	ClosedPorts   []string  `json:"closed_ports,omitempty"` // host:port
+}
+
+// HasChanges reports whether the diff contains any change
+func (d *QuickDiff) HasChanges() bool {
+	return len(d.NewHosts) > 0 || len(d.GoneHosts) > 0 || len(d.NewPorts) > 0 || len(d.ClosedPorts) > 0
+}
+
+// DiffResults compares two quick mode results
+func DiffResults(previous, current *QuickResult) *QuickDiff {
+	diff := &QuickDiff{
+		Timestamp:     time.Now(),
+		PreviousRunID: previous.RunID,
+		CurrentRunID:  current.RunID,
+	}
+
+	prevHosts := toSet(previous.Summary.LiveHosts)
+	currHosts := toSet(current.Summary.LiveHosts)
+	diff.NewHosts = setDifference(currHosts, prevHosts)
+	diff.GoneHosts = setDifference(prevHosts, currHosts)
+
+	prevPorts := toSet(openPortKeys(previous))
+	currPorts := toSet(openPortKeys(current))
+	diff.NewPorts = setDifference(currPorts, prevPorts)
+	diff.ClosedPorts = setDifference(prevPorts, currPorts)
+
+	return diff
+}
+
+// WatchQuickMode repeats the quick pipeline on an interval and reports changes between runs
+func WatchQuickMode(opts QuickOptions, interval time.Duration) error {
+	if interval < time.Minute {
+		return fmt.Errorf("watch interval must be at least 1m, got %s", interval)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	var previous *QuickResult
+	for iteration := 1; ; iteration++ {
+		fmt.Print(i18n.T("quick.watch.iteration", iteration, time.Now().Format("2006-01-02 15:04:05")))
+
+		result, err := RunQuickMode(opts)
+		if err != nil {
+			fmt.Print(i18n.T("quick.watch.failed", err))
+		} else if previous == nil {
+			PrintQuickSummary(result)
+			previous = result
+		} else {
+			diff := DiffResults(previous, result)
+			printDiff(diff)
+			if err := logDiff(diff); err != nil {
+				fmt.Print(i18n.T("quick.watch.log_failed", err))
+			}
+			previous = result
+		}
+
+		// Later iterations run unattended
+		opts.SkipConfirm = true
+		opts.Interactive = false
+
+		fmt.Print(i18n.T("quick.watch.next", time.Now().Add(interval).Format("15:04:05")))
+		select {
+		case <-sigChan:
+			fmt.Println(i18n.T("quick.watch.stopped"))
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// printDiff prints only the changes between two runs
+func printDiff(diff *QuickDiff) {
+	if !diff.HasChanges() {
+		fmt.Println(i18n.T("quick.watch.no_changes"))
+		return
+	}
+
+	fmt.Print(i18n.T("quick.watch.changes", diff.PreviousRunID, diff.CurrentRunID))
+	for _, host := range diff.NewHosts {
+		fmt.Print(i18n.T("quick.watch.new_host", host))
+	}
+	for _, host := range diff.GoneHosts {
+		fmt.Print(i18n.T("quick.watch.gone_host", host))
+	}
+	for _, port := range diff.NewPorts {
+		fmt.Print(i18n.T("quick.watch.new_port", port))
+	}
+	for _, port := range diff.ClosedPorts {
+		fmt.Print(i18n.T("quick.watch.closed_port", port))
+	}
+}
+
+// logDiff appends a diff to ~/.netcrate/logs/quick-watch.log as a JSON line
+func logDiff(diff *QuickDiff) error {
+	if !diff.HasChanges() {
+		return nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	logDir := filepath.Join(homeDir, ".netcrate", "logs")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	line, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("failed to encode watch diff: %w", err)
+	}
+	// Encrypted like the runs it compares when encryption is on
+	if err := store.AppendSealed(filepath.Join(logDir, "quick-watch.log"), append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write watch log: %w", err)
+	}
+	return nil
+}
+
+// openPortKeys returns host:port keys for all open ports in a result
+func openPortKeys(result *QuickResult) []string {
+	var keys []string
+	if result.ScanResult == nil {
+		return keys
+	}
+	for _, portResult := range result.ScanResult.Results {
+		if portResult.Status == "open" {
+			keys = append(keys, fmt.Sprintf("%s:%d", portResult.Host, portResult.Port))
+		}
+	}
+	return keys
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+func setDifference(a, b map[string]bool) []string {
+	var result []string
+	for item := range a {
+		if !b[item] {
+			result = append(result, item)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
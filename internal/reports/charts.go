@@ -0,0 +1,242 @@
+package reports
+
+// chartsTemplate draws the report charts from the ChartData embedded in the
+// page. The script is plain inline JavaScript rendering SVG, so reports stay
+// self-contained and open offline without any external library.
+const chartsTemplate = `{{define "charts"}}
+<script>
+(function () {
+    var data = {{.Charts}};
+    var text = {{chartText}};
+    var svgNS = "http://www.w3.org/2000/svg";
+
+    var tooltip = document.createElement("div");
+    tooltip.className = "chart-tooltip";
+    document.body.appendChild(tooltip);
+
+    function showTip(evt, text) {
+        tooltip.textContent = text;
+        tooltip.style.display = "block";
+        tooltip.style.left = (evt.pageX + 12) + "px";
+        tooltip.style.top = (evt.pageY + 12) + "px";
+    }
+
+    function hideTip() {
+        tooltip.style.display = "none";
+    }
+
+    // format fills the %s and %d verbs of a catalog string in order
+    function format(msg) {
+        var args = arguments, i = 1;
+        return msg.replace(/%%|%[sd]/g, function (verb) {
+            return verb === "%%" ? "%" : args[i++];
+        });
+    }
+
+    function svg(tag, attrs) {
+        var el = document.createElementNS(svgNS, tag);
+        for (var key in attrs) {
+            el.setAttribute(key, attrs[key]);
+        }
+        return el;
+    }
+
+    function hover(el, text) {
+        el.addEventListener("mousemove", function (evt) { showTip(evt, text); });
+        el.addEventListener("mouseleave", hideTip);
+    }
+
+    function empty(el) {
+        el.textContent = text.no_data;
+    }
+
+    // Donut chart with a legend; clicking a legend entry hides its slice
+    function donut(el, points) {
+        points = (points || []).filter(function (p) { return p.value > 0; });
+        if (!points.length) {
+            return empty(el);
+        }
+        var hidden = {};
+        el.textContent = "";
+        var root = svg("svg", { viewBox: "0 0 320 200" });
+        var legend = document.createElement("div");
+        legend.className = "chart-legend";
+        el.appendChild(root);
+        el.appendChild(legend);
+
+        function draw() {
+            while (root.firstChild) {
+                root.removeChild(root.firstChild);
+            }
+            var visible = points.filter(function (p) { return !hidden[p.label]; });
+            var total = visible.reduce(function (sum, p) { return sum + p.value; }, 0);
+            var angle = -Math.PI / 2;
+            visible.forEach(function (p) {
+                var slice = p.value / total * Math.PI * 2;
+                var path;
+                if (visible.length === 1) {
+                    path = svg("circle", { cx: 100, cy: 100, r: 70, fill: "none", stroke: p.color, "stroke-width": 40 });
+                } else {
+                    var end = angle + slice;
+                    var large = slice > Math.PI ? 1 : 0;
+                    var d = "M " + (100 + 90 * Math.cos(angle)) + " " + (100 + 90 * Math.sin(angle)) +
+                        " A 90 90 0 " + large + " 1 " + (100 + 90 * Math.cos(end)) + " " + (100 + 90 * Math.sin(end)) +
+                        " L " + (100 + 50 * Math.cos(end)) + " " + (100 + 50 * Math.sin(end)) +
+                        " A 50 50 0 " + large + " 0 " + (100 + 50 * Math.cos(angle)) + " " + (100 + 50 * Math.sin(angle)) + " Z";
+                    path = svg("path", { d: d, fill: p.color });
+                    angle = end;
+                }
+                hover(path, p.label + ": " + p.value + " (" + (p.value / total * 100).toFixed(1) + "%)");
+                root.appendChild(path);
+            });
+            var label = svg("text", { x: 100, y: 106, "text-anchor": "middle", "font-size": 18, fill: "currentColor" });
+            label.textContent = total;
+            root.appendChild(label);
+        }
+
+        points.forEach(function (p) {
+            var item = document.createElement("span");
+            item.className = "chart-legend-item";
+            item.innerHTML = "<i></i>";
+            item.firstChild.style.background = p.color;
+            item.appendChild(document.createTextNode(p.label + " (" + p.value + ")"));
+            item.addEventListener("click", function () {
+                var shown = points.filter(function (q) { return !hidden[q.label]; }).length;
+                if (!hidden[p.label] && shown === 1) {
+                    return; // keep at least one slice
+                }
+                hidden[p.label] = !hidden[p.label];
+                item.classList.toggle("off", hidden[p.label]);
+                draw();
+            });
+            legend.appendChild(item);
+        });
+        draw();
+    }
+
+    // Horizontal bar chart
+    function bars(el, points) {
+        points = points || [];
+        if (!points.length) {
+            return empty(el);
+        }
+        el.textContent = "";
+        var rowHeight = 24;
+        var height = points.length * rowHeight + 10;
+        var max = Math.max.apply(null, points.map(function (p) { return p.value; }));
+        var root = svg("svg", { viewBox: "0 0 600 " + height });
+        el.style.height = Math.max(height, 120) + "px";
+        points.forEach(function (p, i) {
+            var y = i * rowHeight + 5;
+            var name = svg("text", { x: 110, y: y + 15, "text-anchor": "end", "font-size": 12, fill: "currentColor" });
+            name.textContent = p.label;
+            var width = max > 0 ? p.value / max * 430 : 0;
+            var bar = svg("rect", { x: 120, y: y + 2, width: Math.max(width, 2), height: rowHeight - 6, rx: 3, fill: p.color || "#3498db" });
+            var value = svg("text", { x: 126 + width, y: y + 15, "font-size": 12, fill: "currentColor" });
+            value.textContent = p.value;
+            hover(bar, p.label + ": " + p.value);
+            root.appendChild(name);
+            root.appendChild(bar);
+            root.appendChild(value);
+        });
+        el.appendChild(root);
+    }
+
+    // Gantt chart of the steps: one row each, positioned by start offset and
+    // duration on a shared time axis, with a band showing how many steps run
+    // at once so parallel and serial phases stand out
+    function timeline(el, points, summary) {
+        points = points || [];
+        if (!points.length) {
+            return empty(el);
+        }
+        el.textContent = "";
+        var span = Math.max(summary.span_ns / 1e6, 1);
+        var left = 120, width = 440, rowHeight = 28, top = 24;
+        var height = top + points.length * rowHeight + 30;
+        var root = svg("svg", { viewBox: "0 0 600 " + height });
+        el.style.height = Math.max(height, 120) + "px";
+
+        function x(ms) {
+            return left + ms / span * width;
+        }
+
+        // Axis with round tick intervals
+        var steps = [1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 2000, 5000, 10000, 15000, 30000,
+            60000, 120000, 300000, 600000, 900000, 1800000, 3600000];
+        var tick = steps[steps.length - 1];
+        for (var i = 0; i < steps.length; i++) {
+            if (span / steps[i] <= 8) {
+                tick = steps[i];
+                break;
+            }
+        }
+        for (var t = 0; t <= span + 1e-9; t += tick) {
+            root.appendChild(svg("line", { x1: x(t), y1: top - 4, x2: x(t), y2: height - 24, stroke: "currentColor", "stroke-opacity": 0.15 }));
+            var label = svg("text", { x: x(t), y: height - 10, "text-anchor": "middle", "font-size": 10, fill: "currentColor" });
+            label.textContent = tick < 1000 ? "+" + t + "ms" : "+" + (t / 1000) + "s";
+            root.appendChild(label);
+        }
+
+        // Concurrency band: darker where more steps overlap
+        var edges = [];
+        points.forEach(function (p) {
+            var start = p.offset_ns / 1e6, end = start + p.duration_ns / 1e6;
+            if (end > start) {
+                edges.push([start, 1], [end, -1]);
+            }
+        });
+        edges.sort(function (a, b) { return a[0] - b[0] || a[1] - b[1]; });
+        var active = 0, max = Math.max(summary.max_concurrency, 1);
+        edges.forEach(function (edge, i) {
+            active += edge[1];
+            var next = edges[i + 1];
+            if (next && active > 0 && next[0] > edge[0]) {
+                var band = svg("rect", { x: x(edge[0]), y: 4, width: x(next[0]) - x(edge[0]), height: 10,
+                    fill: "#3498db", "fill-opacity": 0.2 + 0.8 * active / max });
+                hover(band, format(text.running, active));
+                root.appendChild(band);
+            }
+        });
+        var bandLabel = svg("text", { x: left - 10, y: 13, "text-anchor": "end", "font-size": 10, fill: "currentColor" });
+        bandLabel.textContent = text.concurrency;
+        root.appendChild(bandLabel);
+
+        points.forEach(function (p, i) {
+            var y = top + i * rowHeight;
+            var start = p.offset_ns / 1e6, duration = p.duration_ns / 1e6;
+            var name = svg("text", { x: left - 10, y: y + 17, "text-anchor": "end", "font-size": 12, fill: "currentColor" });
+            name.textContent = p.name;
+            var bar = svg("rect", { x: x(start), y: y + 4, width: Math.max(duration / span * width, 3), height: rowHeight - 8, rx: 3, fill: p.color });
+            if (p.slowest) {
+                bar.setAttribute("stroke", "#fd7e14");
+                bar.setAttribute("stroke-width", 2);
+            }
+            var tip = format(text.step_tip, p.name, p.status, (start / 1000).toFixed(2),
+                (duration / 1000).toFixed(2), p.share.toFixed(0));
+            if (p.overlaps && p.overlaps.length) {
+                tip += format(text.alongside, p.overlaps.join(", "));
+            }
+            hover(bar, tip);
+            var value = svg("text", { x: Math.min(x(start + duration) + 4, 560), y: y + 17, "font-size": 10, fill: "currentColor" });
+            value.textContent = duration < 1000 ? duration.toFixed(0) + "ms" : (duration / 1000).toFixed(1) + "s";
+            root.appendChild(name);
+            root.appendChild(bar);
+            root.appendChild(value);
+        });
+        el.appendChild(root);
+    }
+
+    function render(id, draw, points, summary) {
+        var el = document.getElementById(id);
+        if (el) {
+            draw(el, points, summary);
+        }
+    }
+
+    render("chart-status", donut, data.step_status);
+    render("chart-services", bars, data.services);
+    render("chart-timeline", timeline, data.timeline, data.timeline_summary);
+})();
+</script>
+{{end}}`
@@ -3,12 +3,18 @@ package reports
 import (
 	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/netcrate/netcrate/internal/logging"
 )
 
+var log = logging.Component("reports")
+
 // HTMLReportConfig configures HTML report generation
 type HTMLReportConfig struct {
 	Title       string
@@ -16,6 +22,9 @@ type HTMLReportConfig struct {
 	Theme       string // "default", "dark", "minimal"
 	IncludeLogs bool
 	Standalone  bool // Include CSS/JS inline vs external links
+	Policy      *SummaryPolicy // Scoring for the executive summary (default: DefaultSummaryPolicy)
+	TemplateFile string        // Custom html/template file replacing the built-in layout
+	Language    string         // Report language, "en" (default) or "zh-CN"
 }
 
 // HTMLReporter generates HTML reports from execution results
@@ -32,6 +41,7 @@ type ReportData struct {
 	Summary     *ReportSummary
 	Steps       []StepReportData
 	Charts      ChartData
+	Executive   *ExecutiveSummary // set for runs that scanned ports
 	Logs        []LogEntry
 }
 
@@ -53,6 +63,8 @@ type ExecutionResult struct {
 	LogPath        string                 `json:"log_path"`
 	ResultPath     string                 `json:"result_path"`
 	Tags           []string               `json:"tags"`
+	Scan           *ScanReport            `json:"scan,omitempty"` // set for discovery and scan runs
+	Diff           *DiffReport            `json:"diff,omitempty"` // set when compared with an earlier run
 }
 
 // StepResultData represents step execution data
@@ -89,27 +101,34 @@ type ReportSummary struct {
 	TagCount       int
 }
 
-// ChartData contains data for charts
+// ChartData contains data for charts. It is embedded in the report as JSON
+// for the chart script.
 type ChartData struct {
-	StepStatusData   []ChartPoint
-	StepDurationData []ChartPoint
-	TimelineData     []TimelinePoint
+	StepStatusData   []ChartPoint    `json:"step_status"`
+	StepDurationData []ChartPoint    `json:"step_duration,omitempty"`
+	ServiceData      []ChartPoint    `json:"services,omitempty"` // open ports per service, for scan runs
+	TimelineData     []TimelinePoint `json:"timeline"`
+	Timeline         TimelineSummary `json:"timeline_summary"`
 }
 
 // ChartPoint represents a data point for charts
 type ChartPoint struct {
-	Label string
-	Value float64
-	Color string
+	Label string  `json:"label"`
+	Value float64 `json:"value"`
+	Color string  `json:"color"`
 }
 
 // TimelinePoint represents a timeline data point
 type TimelinePoint struct {
-	Name      string
-	StartTime time.Time
-	Duration  time.Duration
-	Status    string
-	Color     string
+	Name      string        `json:"name"`
+	StartTime time.Time     `json:"start_time"`
+	Duration  time.Duration `json:"duration_ns"`
+	Status    string        `json:"status"`
+	Color     string        `json:"color"`
+	Offset    time.Duration `json:"offset_ns"` // since the first step started
+	Share     float64       `json:"share"`     // percentage of the timeline span
+	Overlaps  []string      `json:"overlaps,omitempty"` // steps running at the same time
+	Slowest   bool          `json:"slowest"`
 }
 
 // LogEntry represents a log entry
@@ -124,18 +143,31 @@ type LogEntry struct {
 
 // NewHTMLReporter creates a new HTML reporter
 func NewHTMLReporter(config HTMLReportConfig) (*HTMLReporter, error) {
+	config.Language = normalizeReportLanguage(config.Language)
 	if config.Title == "" {
-		config.Title = "NetCrate Execution Report"
+		config.Title = Text(config.Language, "title.default")
 	}
 	if config.Theme == "" {
 		config.Theme = "default"
 	}
+	if err := validateTheme(config.Theme); err != nil {
+		return nil, err
+	}
 	
 	reporter := &HTMLReporter{
 		config: config,
 	}
 	
 	// Parse HTML template
+	source := htmlTemplate
+	if config.TemplateFile != "" {
+		data, err := os.ReadFile(config.TemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report template: %w", err)
+		}
+		source = string(data)
+	}
+
 	tmpl, err := template.New("report").Funcs(template.FuncMap{
 		"formatTime":     formatTime,
 		"formatDuration": formatDuration,
@@ -143,9 +175,37 @@ func NewHTMLReporter(config HTMLReportConfig) (*HTMLReporter, error) {
 		"formatJSON":     formatJSON,
 		"colorForStatus": colorForStatus,
 		"percentage":     percentage,
-	}).Parse(htmlTemplate)
+		"severityColor":  severityColor,
+		"maxValue":       maxValue,
+		"scoreColor":     scoreColor,
+		"themeCSS":       themeCSS,
+		"t": func(key string, args ...interface{}) string {
+			return Text(config.Language, key, args...)
+		},
+		"label": func(vocabulary, value string) string {
+			return label(config.Language, vocabulary, value)
+		},
+		"issue": func(issue string) string {
+			return issueText(config.Language, issue)
+		},
+		"chartText": func() map[string]string {
+			text := make(map[string]string)
+			for key := range reportMessagesEN {
+				if strings.HasPrefix(key, "chart.") {
+					text[strings.TrimPrefix(key, "chart.")] = Text(config.Language, key)
+				}
+			}
+			return text
+		},
+	}).Parse(source)
 	
 	if err != nil {
+		if config.TemplateFile != "" {
+			return nil, fmt.Errorf("failed to parse report template %s: %w", config.TemplateFile, err)
+		}
+		return nil, err
+	}
+	if _, err := tmpl.Parse(chartsTemplate); err != nil {
 		return nil, err
 	}
 	
@@ -155,6 +215,23 @@ func NewHTMLReporter(config HTMLReportConfig) (*HTMLReporter, error) {
 
 // GenerateReport generates HTML report from execution result
 func (hr *HTMLReporter) GenerateReport(result *ExecutionResult, outputPath string) error {
+	// Create output directory
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+	
+	// Generate HTML
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	
+	return hr.Render(file, result)
+}
+
+// Render writes the HTML report for an execution result to w
+func (hr *HTMLReporter) Render(w io.Writer, result *ExecutionResult) error {
 	// Prepare report data
 	reportData := &ReportData{
 		Config:      hr.config,
@@ -164,30 +241,25 @@ func (hr *HTMLReporter) GenerateReport(result *ExecutionResult, outputPath strin
 		Steps:       hr.generateStepData(result),
 		Charts:      hr.generateChartData(result),
 	}
+	if result.Scan != nil && result.Scan.Scanned {
+		policy := DefaultSummaryPolicy
+		if hr.config.Policy != nil {
+			policy = *hr.config.Policy
+		}
+		reportData.Executive = BuildExecutiveSummary(result.Scan, policy, hr.config.Language)
+	}
 	
 	// Load logs if requested
 	if hr.config.IncludeLogs && result.LogPath != "" {
 		logs, err := hr.loadLogs(result.LogPath)
 		if err != nil {
-			fmt.Printf("[WARN] Failed to load logs: %v\n", err)
+			log.Warn("failed to load logs", "path", result.LogPath, "error", err)
 		} else {
 			reportData.Logs = logs
 		}
 	}
 	
-	// Create output directory
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return err
-	}
-	
-	// Generate HTML
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	
-	return hr.template.Execute(file, reportData)
+	return hr.template.Execute(w, reportData)
 }
 
 // generateSummary creates report summary
@@ -216,7 +288,7 @@ func (hr *HTMLReporter) generateSummary(result *ExecutionResult) *ReportSummary
 func (hr *HTMLReporter) generateStepData(result *ExecutionResult) []StepReportData {
 	var steps []StepReportData
 	
-	for _, stepResult := range result.StepResults {
+	for _, stepResult := range orderedSteps(result) {
 		stepData := StepReportData{
 			Name:        stepResult.Name,
 			Status:      stepResult.Status,
@@ -242,15 +314,21 @@ func (hr *HTMLReporter) generateStepData(result *ExecutionResult) []StepReportDa
 func (hr *HTMLReporter) generateChartData(result *ExecutionResult) ChartData {
 	// Step status distribution
 	statusData := []ChartPoint{
-		{Label: "Completed", Value: float64(result.CompletedSteps), Color: "#28a745"},
-		{Label: "Failed", Value: float64(result.FailedSteps), Color: "#dc3545"},
-		{Label: "Skipped", Value: float64(result.SkippedSteps), Color: "#ffc107"},
+		{Label: Text(hr.config.Language, "chart.status.completed"), Value: float64(result.CompletedSteps), Color: "#28a745"},
+		{Label: Text(hr.config.Language, "chart.status.failed"), Value: float64(result.FailedSteps), Color: "#dc3545"},
+		{Label: Text(hr.config.Language, "chart.status.skipped"), Value: float64(result.SkippedSteps), Color: "#ffc107"},
 	}
 	
 	// Timeline data
 	var timelineData []TimelinePoint
-	for _, stepResult := range result.StepResults {
-		duration, _ := time.ParseDuration(stepResult.Duration)
+	for _, stepResult := range orderedSteps(result) {
+		if stepResult.StartTime.IsZero() {
+			continue // nothing to place on the timeline
+		}
+		duration, err := time.ParseDuration(stepResult.Duration)
+		if err != nil && stepResult.EndTime.After(stepResult.StartTime) {
+			duration = stepResult.EndTime.Sub(stepResult.StartTime)
+		}
 		timelineData = append(timelineData, TimelinePoint{
 			Name:      stepResult.Name,
 			StartTime: stepResult.StartTime,
@@ -260,10 +338,30 @@ func (hr *HTMLReporter) generateChartData(result *ExecutionResult) ChartData {
 		})
 	}
 	
-	return ChartData{
+	charts := ChartData{
 		StepStatusData: statusData,
 		TimelineData:   timelineData,
+		Timeline:       annotateTimeline(timelineData),
+	}
+	if result.Scan != nil {
+		charts.ServiceData = result.Scan.TopServices
 	}
+	return charts
+}
+
+// orderedSteps returns the step results in the order they started
+func orderedSteps(result *ExecutionResult) []*StepResultData {
+	steps := make([]*StepResultData, 0, len(result.StepResults))
+	for _, step := range result.StepResults {
+		steps = append(steps, step)
+	}
+	sort.Slice(steps, func(i, j int) bool {
+		if !steps[i].StartTime.Equal(steps[j].StartTime) {
+			return steps[i].StartTime.Before(steps[j].StartTime)
+		}
+		return steps[i].Name < steps[j].Name
+	})
+	return steps
 }
 
 // loadLogs loads log entries from file
@@ -356,12 +454,15 @@ func percentage(value, total float64) float64 {
 
 // HTML Template
 const htmlTemplate = `<!DOCTYPE html>
-<html lang="en">
+<html lang="{{.Config.Language}}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    {{if eq .Config.Theme "default"}}<meta name="color-scheme" content="light dark">{{end}}
     <title>{{.Config.Title}}</title>
     <style>
+        {{themeCSS .Config.Theme}}
+        
         * {
             margin: 0;
             padding: 0;
@@ -369,52 +470,53 @@ const htmlTemplate = `<!DOCTYPE html>
         }
         
         body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+            font-family: var(--nc-font);
+            font-size: var(--nc-font-size);
             line-height: 1.6;
-            color: #333;
-            background-color: #f8f9fa;
+            color: var(--nc-text);
+            background-color: var(--nc-bg);
         }
         
         .container {
             max-width: 1200px;
             margin: 0 auto;
-            padding: 20px;
+            padding: var(--nc-space);
         }
         
         .header {
-            background: white;
-            border-radius: 8px;
-            padding: 30px;
-            margin-bottom: 20px;
-            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            background: var(--nc-surface);
+            border-radius: var(--nc-radius);
+            padding: var(--nc-space-lg);
+            margin-bottom: var(--nc-space);
+            box-shadow: var(--nc-shadow);
         }
         
         .header h1 {
-            color: #2c3e50;
+            color: var(--nc-heading);
             margin-bottom: 10px;
         }
         
         .header .meta {
-            color: #666;
+            color: var(--nc-muted);
             font-size: 14px;
         }
         
         .summary {
             display: grid;
             grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
-            gap: 20px;
-            margin-bottom: 20px;
+            gap: var(--nc-space);
+            margin-bottom: var(--nc-space);
         }
         
         .summary-card {
-            background: white;
-            border-radius: 8px;
+            background: var(--nc-surface);
+            border-radius: var(--nc-radius);
             padding: 20px;
-            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            box-shadow: var(--nc-shadow);
         }
         
         .summary-card h3 {
-            color: #2c3e50;
+            color: var(--nc-heading);
             margin-bottom: 10px;
             font-size: 14px;
             text-transform: uppercase;
@@ -424,7 +526,7 @@ const htmlTemplate = `<!DOCTYPE html>
         .summary-card .value {
             font-size: 24px;
             font-weight: bold;
-            color: #3498db;
+            color: var(--nc-accent);
         }
         
         .status-success { color: #28a745; }
@@ -434,18 +536,18 @@ const htmlTemplate = `<!DOCTYPE html>
         .status-secondary { color: #6c757d; }
         
         .section {
-            background: white;
-            border-radius: 8px;
-            padding: 30px;
-            margin-bottom: 20px;
-            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            background: var(--nc-surface);
+            border-radius: var(--nc-radius);
+            padding: var(--nc-space-lg);
+            margin-bottom: var(--nc-space);
+            box-shadow: var(--nc-shadow);
         }
         
         .section h2 {
-            color: #2c3e50;
-            margin-bottom: 20px;
+            color: var(--nc-heading);
+            margin-bottom: var(--nc-space);
             padding-bottom: 10px;
-            border-bottom: 2px solid #ecf0f1;
+            border-bottom: 2px solid var(--nc-border);
         }
         
         .parameters {
@@ -456,20 +558,20 @@ const htmlTemplate = `<!DOCTYPE html>
         
         .parameter {
             padding: 10px;
-            background: #f8f9fa;
+            background: var(--nc-surface-alt);
             border-radius: 4px;
-            border-left: 4px solid #3498db;
+            border-left: 4px solid var(--nc-accent);
         }
         
         .parameter .name {
             font-weight: bold;
-            color: #2c3e50;
+            color: var(--nc-heading);
         }
         
         .parameter .value {
-            color: #666;
+            color: var(--nc-muted);
             margin-top: 5px;
-            font-family: monospace;
+            font-family: var(--nc-font-mono);
         }
         
         .steps-table {
@@ -482,13 +584,13 @@ const htmlTemplate = `<!DOCTYPE html>
         .steps-table td {
             padding: 12px;
             text-align: left;
-            border-bottom: 1px solid #ecf0f1;
+            border-bottom: 1px solid var(--nc-border);
         }
         
         .steps-table th {
-            background: #f8f9fa;
+            background: var(--nc-surface-alt);
             font-weight: 600;
-            color: #2c3e50;
+            color: var(--nc-heading);
         }
         
         .step-status {
@@ -500,46 +602,217 @@ const htmlTemplate = `<!DOCTYPE html>
         }
         
         .step-status.status-success {
-            background: #d4edda;
-            color: #155724;
+            background: var(--nc-success-bg);
+            color: var(--nc-success-fg);
         }
         
         .step-status.status-error {
-            background: #f8d7da;
-            color: #721c24;
+            background: var(--nc-error-bg);
+            color: var(--nc-error-fg);
         }
         
         .step-status.status-warning {
-            background: #fff3cd;
-            color: #856404;
+            background: var(--nc-warning-bg);
+            color: var(--nc-warning-fg);
         }
         
         .footer {
             text-align: center;
-            color: #666;
+            color: var(--nc-muted);
             font-size: 14px;
             margin-top: 40px;
             padding-top: 20px;
-            border-top: 1px solid #ecf0f1;
+            border-top: 1px solid var(--nc-border);
         }
         
         .chart {
             height: 200px;
-            background: #f8f9fa;
+            background: var(--nc-surface-alt);
             border-radius: 4px;
             display: flex;
             align-items: center;
             justify-content: center;
-            color: #666;
-            margin: 20px 0;
+            color: var(--nc-muted);
+            margin: var(--nc-space) 0;
         }
         
-        {{if eq .Config.Theme "dark"}}
-        body { background-color: #1a1a1a; color: #e0e0e0; }
-        .header, .summary-card, .section { background: #2d2d2d; }
-        .header h1, .section h2, .summary-card h3 { color: #ffffff; }
-        .parameter { background: #3a3a3a; }
-        .steps-table th { background: #3a3a3a; }
+        .chart svg {
+            width: 100%;
+            height: 100%;
+        }
+        
+        .charts {
+            display: grid;
+            grid-template-columns: repeat(auto-fit, minmax(320px, 1fr));
+            gap: var(--nc-space);
+        }
+        
+        .charts .chart {
+            flex-direction: column;
+        }
+        
+        .chart-legend {
+            font-size: 13px;
+            margin-top: 8px;
+        }
+        
+        .chart-legend-item {
+            cursor: pointer;
+            margin: 0 8px;
+            user-select: none;
+        }
+        
+        .chart-legend-item.off {
+            opacity: 0.4;
+            text-decoration: line-through;
+        }
+        
+        .chart-legend-item i {
+            display: inline-block;
+            width: 10px;
+            height: 10px;
+            border-radius: 2px;
+            margin-right: 4px;
+        }
+        
+        .chart-tooltip {
+            display: none;
+            position: absolute;
+            pointer-events: none;
+            background: var(--nc-tooltip-bg);
+            color: white;
+            padding: 4px 8px;
+            border-radius: 4px;
+            font-size: 12px;
+            z-index: 10;
+        }
+        
+        .bar-row {
+            display: flex;
+            align-items: center;
+            margin: 6px 0;
+        }
+        
+        .bar-row .label {
+            width: 140px;
+            font-size: 14px;
+        }
+        
+        .bar-row .bar {
+            height: 18px;
+            border-radius: 3px;
+            background: var(--nc-accent);
+            margin-right: 8px;
+        }
+        
+        .badge {
+            display: inline-block;
+            padding: 2px 8px;
+            border-radius: 4px;
+            color: white;
+            font-size: 12px;
+            font-weight: bold;
+            text-transform: uppercase;
+        }
+        
+        .score {
+            font-size: 48px;
+            font-weight: bold;
+        }
+        
+        .actions li {
+            margin: 6px 0 6px 20px;
+        }
+        
+        .diff-added {
+            color: #28a745;
+            font-weight: bold;
+        }
+        
+        .diff-removed {
+            color: #dc3545;
+            font-weight: bold;
+        }
+        
+        .port-list {
+            font-family: var(--nc-font-mono);
+            font-size: 13px;
+        }
+        
+        .host-detail {
+            border-top: 1px solid var(--nc-border);
+            padding: 10px 0;
+        }
+        
+        .host-detail summary {
+            cursor: pointer;
+        }
+        
+        .host-detail .banner {
+            font-family: var(--nc-font-mono);
+            font-size: 12px;
+            white-space: pre-wrap;
+            word-break: break-all;
+        }
+        
+        a {
+            color: var(--nc-accent);
+        }
+        
+        @media print {
+            body {
+                background: none;
+            }
+            
+            .container {
+                max-width: none;
+                padding: 0;
+            }
+            
+            .header, .summary-card, .section {
+                box-shadow: none;
+            }
+            
+            .summary-card, .steps-table tr, .bar-row {
+                break-inside: avoid;
+            }
+            
+            .section h2, .section h3 {
+                break-after: avoid;
+            }
+            
+            .chart-tooltip, .chart-legend {
+                display: none;
+            }
+            
+            a {
+                color: inherit;
+                text-decoration: none;
+            }
+        }
+        
+        {{if eq .Config.Theme "minimal"}}
+        @page {
+            margin: 15mm;
+        }
+        
+        .container {
+            max-width: 960px;
+        }
+        
+        .header, .summary-card, .section {
+            border: 1px solid var(--nc-border);
+        }
+        
+        .badge {
+            background: none !important;
+            color: var(--nc-text);
+            border: 1px solid var(--nc-text);
+        }
+        
+        .step-status {
+            border: 1px solid var(--nc-text);
+        }
         {{end}}
     </style>
 </head>
@@ -548,9 +821,9 @@ const htmlTemplate = `<!DOCTYPE html>
         <div class="header">
             <h1>{{.Config.Title}}</h1>
             <div class="meta">
-                Template: <strong>{{.Result.TemplateName}}</strong> |
-                Session: <strong>{{.Result.SessionID}}</strong> |
-                Generated: <strong>{{formatTime .GeneratedAt}}</strong>
+                {{t "header.operation"}}: <strong>{{.Result.TemplateName}}</strong> |
+                {{t "header.session"}}: <strong>{{.Result.SessionID}}</strong> |
+                {{t "header.generated"}}: <strong>{{formatTime .GeneratedAt}}</strong>
             </div>
             {{if .Config.Description}}
             <div class="description">{{.Config.Description}}</div>
@@ -559,25 +832,205 @@ const htmlTemplate = `<!DOCTYPE html>
 
         <div class="summary">
             <div class="summary-card">
-                <h3>Status</h3>
-                <div class="value {{statusClass .Result.Status}}">{{.Result.Status}}</div>
+                <h3>{{t "card.status"}}</h3>
+                <div class="value {{statusClass .Result.Status}}">{{label "status" .Result.Status}}</div>
             </div>
             <div class="summary-card">
-                <h3>Duration</h3>
+                <h3>{{t "card.duration"}}</h3>
                 <div class="value">{{formatDuration .Result.Duration}}</div>
             </div>
             <div class="summary-card">
-                <h3>Success Rate</h3>
+                <h3>{{t "card.success_rate"}}</h3>
                 <div class="value">{{printf "%.1f%%" .Summary.SuccessRate}}</div>
             </div>
             <div class="summary-card">
-                <h3>Total Steps</h3>
+                <h3>{{t "card.total_steps"}}</h3>
                 <div class="value">{{.Result.TotalSteps}}</div>
             </div>
         </div>
 
+        {{with .Executive}}
         <div class="section">
-            <h2>Parameters</h2>
+            <h2>{{t "section.executive"}}</h2>
+            <div class="summary">
+                <div class="summary-card">
+                    <h3>{{t "executive.score"}}</h3>
+                    <div class="score" style="color: {{scoreColor .Score}}">{{.Score}}<small>/100</small></div>
+                    <div>{{t "executive.grade" .Grade}}</div>
+                </div>
+                {{range .SeverityCounts}}
+                <div class="summary-card">
+                    <h3><span class="badge" style="background: {{.Color}}">{{label "severity" .Label}}</span></h3>
+                    <div class="value">{{.Value}}</div>
+                </div>
+                {{end}}
+                <div class="summary-card">
+                    <h3>{{t "executive.tls_issues"}}</h3>
+                    <div class="value">{{.TLSIssues}}</div>
+                </div>
+            </div>
+            <h3>{{t "executive.actions"}}</h3>
+            {{if .Actions}}
+            <ol class="actions">
+                {{range .Actions}}
+                <li><span class="badge" style="background: {{severityColor .Severity}}">{{label "severity" .Severity}}</span> {{.Advice}}</li>
+                {{end}}
+            </ol>
+            {{else}}
+            <p>{{t "executive.no_actions"}}</p>
+            {{end}}
+        </div>
+        {{end}}
+
+        {{with .Result.Diff}}
+        <div class="section">
+            <h2>{{t "section.changes"}}</h2>
+            <p>{{if .BaseTime.IsZero}}{{t "diff.compared" .BaseRunID}}{{else}}{{t "diff.compared_at" .BaseRunID (formatTime .BaseTime)}}{{end}}</p>
+            <div class="summary">
+                <div class="summary-card">
+                    <h3>{{t "diff.new_hosts"}}</h3>
+                    <div class="value diff-added">+{{len .AddedHosts}}</div>
+                </div>
+                <div class="summary-card">
+                    <h3>{{t "diff.hosts_gone"}}</h3>
+                    <div class="value diff-removed">-{{len .RemovedHosts}}</div>
+                </div>
+                {{if .PortsCompared}}
+                <div class="summary-card">
+                    <h3>{{t "diff.opened_ports"}}</h3>
+                    <div class="value diff-added">+{{len .OpenedPorts}}</div>
+                </div>
+                <div class="summary-card">
+                    <h3>{{t "diff.closed_ports"}}</h3>
+                    <div class="value diff-removed">-{{len .ClosedPorts}}</div>
+                </div>
+                <div class="summary-card">
+                    <h3>{{t "diff.changed_services"}}</h3>
+                    <div class="value">{{len .ChangedServices}}</div>
+                </div>
+                {{end}}
+            </div>
+            {{if not .HasChanges}}
+            <p>{{t "diff.no_changes"}}</p>
+            {{end}}
+            {{if not .PortsCompared}}
+            <p><small>{{t "diff.ports_not_compared"}}</small></p>
+            {{end}}
+
+            {{if or .AddedHosts .RemovedHosts}}
+            <h3>{{t "diff.hosts"}}</h3>
+            <table class="steps-table">
+                <thead>
+                    <tr><th>{{t "col.change"}}</th><th>{{t "col.host"}}</th><th>{{t "col.name"}}</th><th>{{t "col.open_ports"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .AddedHosts}}
+                    <tr>
+                        <td class="diff-added">{{t "diff.added"}}</td>
+                        <td><strong>{{.Host}}</strong>{{if .MAC}}<br><small>{{.MAC}}</small>{{end}}</td>
+                        <td>{{if .Hostname}}{{.Hostname}}{{else}}-{{end}}</td>
+                        <td>{{len .OpenPorts}}</td>
+                    </tr>
+                    {{end}}
+                    {{range .RemovedHosts}}
+                    <tr>
+                        <td class="diff-removed">{{t "diff.removed"}}</td>
+                        <td><strong>{{.Host}}</strong>{{if .MAC}}<br><small>{{.MAC}}</small>{{end}}</td>
+                        <td>{{if .Hostname}}{{.Hostname}}{{else}}-{{end}}</td>
+                        <td>{{len .OpenPorts}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{end}}
+
+            {{if or .OpenedPorts .ClosedPorts .ChangedServices}}
+            <h3>{{t "diff.ports"}}</h3>
+            <table class="steps-table">
+                <thead>
+                    <tr><th>{{t "col.change"}}</th><th>{{t "col.host"}}</th><th>{{t "col.port"}}</th><th>{{t "col.before"}}</th><th>{{t "col.after"}}</th><th>{{t "col.severity"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .OpenedPorts}}
+                    <tr style="border-left: 4px solid {{severityColor .Severity}}">
+                        <td class="diff-added">{{t "diff.opened"}}</td>
+                        <td>{{.Host}}</td>
+                        <td>{{.Port}}/{{.Protocol}}</td>
+                        <td>-</td>
+                        <td>{{.After}}</td>
+                        <td><span class="badge" style="background: {{severityColor .Severity}}">{{label "severity" .Severity}}</span></td>
+                    </tr>
+                    {{end}}
+                    {{range .ChangedServices}}
+                    <tr style="border-left: 4px solid {{severityColor .Severity}}">
+                        <td>{{t "diff.changed"}}</td>
+                        <td>{{.Host}}</td>
+                        <td>{{.Port}}/{{.Protocol}}</td>
+                        <td>{{.Before}}</td>
+                        <td>{{.After}}</td>
+                        <td><span class="badge" style="background: {{severityColor .Severity}}">{{label "severity" .Severity}}</span></td>
+                    </tr>
+                    {{end}}
+                    {{range .ClosedPorts}}
+                    <tr>
+                        <td class="diff-removed">{{t "diff.closed"}}</td>
+                        <td>{{.Host}}</td>
+                        <td>{{.Port}}/{{.Protocol}}</td>
+                        <td>{{.Before}}</td>
+                        <td>-</td>
+                        <td><span class="badge" style="background: {{severityColor .Severity}}">{{label "severity" .Severity}}</span></td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{end}}
+        </div>
+        {{end}}
+
+        <div class="section">
+            <h2>{{t "section.charts"}}</h2>
+            <div class="charts">
+                <div>
+                    <h3>{{t "chart.step_status"}}</h3>
+                    <div class="chart" id="chart-status">{{t "chart.needs_js"}}</div>
+                </div>
+                {{if .Charts.ServiceData}}
+                <div>
+                    <h3>{{t "chart.services"}}</h3>
+                    <div class="chart" id="chart-services">{{t "chart.needs_js"}}</div>
+                </div>
+                {{end}}
+            </div>
+            <h3>{{t "chart.timeline"}}</h3>
+            <div class="chart" id="chart-timeline">{{t "chart.needs_js"}}</div>
+            {{if .Charts.TimelineData}}
+            {{with .Charts.Timeline}}
+            <p>
+                {{t "timeline.summary" (formatDuration .Span.String) .MaxConcurrency}}
+                {{if .Slowest}}{{t "timeline.slowest" .Slowest}}{{end}}
+            </p>
+            {{end}}
+            <table class="steps-table">
+                <thead>
+                    <tr><th>{{t "col.step"}}</th><th>{{t "timeline.starts"}}</th><th>{{t "col.duration"}}</th><th>{{t "timeline.share"}}</th><th>{{t "timeline.along"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Charts.TimelineData}}
+                    <tr>
+                        <td><strong>{{.Name}}</strong>{{if .Slowest}} <span class="badge" style="background: #fd7e14">{{t "timeline.badge"}}</span>{{end}}</td>
+                        <td>+{{formatDuration .Offset.String}}</td>
+                        <td>{{formatDuration .Duration.String}}</td>
+                        <td>{{printf "%.0f%%" .Share}}</td>
+                        <td>{{range $i, $name := .Overlaps}}{{if $i}}, {{end}}{{$name}}{{else}}-{{end}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{end}}
+        </div>
+
+        <div class="section">
+            <h2>{{t "section.parameters"}}</h2>
             <div class="parameters">
                 {{range $key, $value := .Result.Parameters}}
                 <div class="parameter">
@@ -589,21 +1042,21 @@ const htmlTemplate = `<!DOCTYPE html>
         </div>
 
         <div class="section">
-            <h2>Step Execution</h2>
+            <h2>{{t "section.steps"}}</h2>
             <table class="steps-table">
                 <thead>
                     <tr>
-                        <th>Step</th>
-                        <th>Status</th>
-                        <th>Duration</th>
-                        <th>Message</th>
+                        <th>{{t "col.step"}}</th>
+                        <th>{{t "col.status"}}</th>
+                        <th>{{t "col.duration"}}</th>
+                        <th>{{t "col.message"}}</th>
                     </tr>
                 </thead>
                 <tbody>
                     {{range .Steps}}
                     <tr>
                         <td><strong>{{.Name}}</strong></td>
-                        <td><span class="step-status {{.StatusClass}}">{{.Status}}</span></td>
+                        <td><span class="step-status {{.StatusClass}}">{{label "status" .Status}}</span></td>
                         <td>{{formatDuration .Duration}}</td>
                         <td>
                             {{if .Error}}
@@ -618,9 +1071,205 @@ const htmlTemplate = `<!DOCTYPE html>
             </table>
         </div>
 
+        {{with .Result.Scan}}
+        <div class="summary">
+            <div class="summary-card">
+                <h3>{{t "card.hosts_up"}}</h3>
+                <div class="value">{{.HostsUp}}</div>
+            </div>
+            <div class="summary-card">
+                <h3>{{t "card.open_ports"}}</h3>
+                <div class="value">{{.OpenPorts}}</div>
+            </div>
+            <div class="summary-card">
+                <h3>{{t "card.subnets"}}</h3>
+                <div class="value">{{len .Subnets}}</div>
+            </div>
+            <div class="summary-card">
+                <h3>{{t "card.tls_services"}}</h3>
+                <div class="value">{{len .TLS}}</div>
+            </div>
+        </div>
+
+        {{if .Risk}}
+        <div class="section">
+            <h2>{{t "section.risk"}}</h2>
+            {{$max := maxValue .Risk}}
+            {{range .Risk}}
+            <div class="bar-row">
+                <span class="label"><span class="badge" style="background: {{.Color}}">{{label "severity" .Label}}</span></span>
+                <span class="bar" style="width: {{percentage .Value $max}}%; max-width: 60%; background: {{.Color}}"></span>
+                <span>{{.Value}}</span>
+            </div>
+            {{end}}
+            {{if .RiskFindings}}
+            <table class="steps-table">
+                <thead>
+                    <tr><th>{{t "col.host"}}</th><th>{{t "col.port"}}</th><th>{{t "col.service"}}</th><th>{{t "col.severity"}}</th><th>{{t "col.rationale"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .RiskFindings}}
+                    <tr>
+                        <td>{{.Host}}</td>
+                        <td>{{.Port}}</td>
+                        <td>{{.Service}}</td>
+                        <td><span class="badge" style="background: {{severityColor .Severity}}">{{label "severity" .Severity}}</span></td>
+                        <td>{{.Rationale}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{end}}
+        </div>
+        {{end}}
+
+        {{if .TopServices}}
+        <div class="section">
+            <h2>{{t "section.services"}}</h2>
+            {{$max := maxValue .TopServices}}
+            {{range .TopServices}}
+            <div class="bar-row">
+                <span class="label">{{.Label}}</span>
+                <span class="bar" style="width: {{percentage .Value $max}}%; max-width: 60%"></span>
+                <span>{{.Value}}</span>
+            </div>
+            {{end}}
+        </div>
+        {{end}}
+
+        {{if .Hosts}}
+        <div class="section">
+            <h2>{{t "section.hosts"}}</h2>
+            <table class="steps-table">
+                <thead>
+                    <tr><th>{{t "col.host"}}</th><th>{{t "col.name"}}</th><th>{{t "col.device"}}</th><th>{{t "col.open_ports"}}</th><th>{{t "col.risk"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Hosts}}
+                    <tr>
+                        <td><a href="#{{.HostID}}"><strong>{{.Host}}</strong></a>{{if .MAC}}<br><small>{{.MAC}}</small>{{end}}</td>
+                        <td>{{.Hostname}}</td>
+                        <td>{{.Vendor}}{{if .Category}} ({{.Category}}){{end}}</td>
+                        <td class="port-list">
+                            {{range .OpenPorts}}
+                            <div>{{.Port}}/{{.Protocol}} {{.Service}}{{if .Product}} {{.Product}}{{end}}{{if .Version}} {{.Version}}{{end}}</div>
+                            {{else}}-{{end}}
+                        </td>
+                        <td>{{if .Risk}}<span class="badge" style="background: {{severityColor .Risk}}">{{label "severity" .Risk}}</span>{{end}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+
+        <div class="section">
+            <h2>{{t "section.host_details"}}</h2>
+            {{range .Hosts}}
+            <details class="host-detail" id="{{.HostID}}"{{if eq $.Config.Theme "minimal"}} open{{end}}>
+                <summary>
+                    <strong>{{.Host}}</strong>{{if .Hostname}} {{.Hostname}}{{end}} &middot; {{t "host.ports" (len .OpenPorts)}}
+                    {{if .Risk}}<span class="badge" style="background: {{severityColor .Risk}}">{{label "severity" .Risk}}</span>{{end}}
+                </summary>
+                {{if or .MAC .Vendor .Category}}<p>{{.MAC}}{{if .Vendor}} {{.Vendor}}{{end}}{{if .Category}} ({{.Category}}){{end}}</p>{{end}}
+                {{if .OpenPorts}}
+                <table class="steps-table">
+                    <thead>
+                        <tr><th>{{t "col.port"}}</th><th>{{t "col.service"}}</th><th>{{t "col.version"}}</th><th>{{t "col.banner"}}</th><th>{{t "col.fingerprint"}}</th><th>{{t "col.risk"}}</th></tr>
+                    </thead>
+                    <tbody>
+                        {{range .OpenPorts}}
+                        <tr>
+                            <td>{{.Port}}/{{.Protocol}}</td>
+                            <td>{{.Service}}</td>
+                            <td>{{.Product}}{{if and .Product .Version}} {{end}}{{.Version}}</td>
+                            <td class="banner">{{.Banner}}</td>
+                            <td>{{range $key, $value := .Details}}<div><small>{{$key}}</small>: {{$value}}</div>{{end}}</td>
+                            <td>{{if .Risk}}<span class="badge" style="background: {{severityColor .Risk}}">{{label "severity" .Risk}}</span>{{end}}</td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+                {{else}}
+                <p>{{t "host.no_ports"}}</p>
+                {{end}}
+                {{if .History}}
+                <h3>{{t "host.history"}}</h3>
+                <table class="steps-table">
+                    <thead>
+                        <tr><th>{{t "col.run"}}</th><th>{{t "col.when"}}</th><th>{{t "col.change"}}</th></tr>
+                    </thead>
+                    <tbody>
+                        {{range .History}}
+                        <tr>
+                            <td>{{.RunID}}</td>
+                            <td>{{formatTime .StartTime}}</td>
+                            <td class="port-list">
+                                {{if not .Seen}}{{t "history.not_seen"}}
+                                {{else if not .PortsCompared}}{{t "history.seen"}}
+                                {{else if or .Opened .Closed .Changed}}
+                                {{range .Opened}}<div class="diff-added">+ {{.}}</div>{{end}}
+                                {{range .Closed}}<div class="diff-removed">- {{.}}</div>{{end}}
+                                {{range .Changed}}<div>~ {{.}}</div>{{end}}
+                                {{else}}{{t "history.unchanged"}}{{end}}
+                            </td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+                {{end}}
+            </details>
+            {{end}}
+        </div>
+        {{end}}
+
+        {{if .TLS}}
+        <div class="section">
+            <h2>{{t "section.tls"}}</h2>
+            <table class="steps-table">
+                <thead>
+                    <tr><th>{{t "col.service"}}</th><th>{{t "col.protocol"}}</th><th>{{t "col.subject"}}</th><th>{{t "col.issuer"}}</th><th>{{t "col.expires"}}</th><th>{{t "col.issues"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .TLS}}
+                    <tr>
+                        <td>{{.Host}}:{{.Port}}</td>
+                        <td>{{.Version}}{{if .Cipher}}<br><small>{{.Cipher}}</small>{{end}}</td>
+                        <td>{{.Subject}}</td>
+                        <td>{{.Issuer}}</td>
+                        <td>{{if not .NotAfter.IsZero}}{{formatTime .NotAfter}}{{end}}</td>
+                        <td>{{range .Issues}}<span class="badge" style="background: #dc3545">{{issue .}}</span> {{end}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if .Subnets}}
+        <div class="section">
+            <h2>{{t "section.subnets"}}</h2>
+            <table class="steps-table">
+                <thead>
+                    <tr><th>{{t "col.subnet"}}</th><th>{{t "col.hosts_up"}}</th><th>{{t "col.open_ports"}}</th><th>{{t "col.top_services"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Subnets}}
+                    <tr>
+                        <td>{{.Subnet}}</td>
+                        <td>{{.HostsUp}}</td>
+                        <td>{{.OpenPorts}}</td>
+                        <td>{{.TopServices}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+        {{end}}
+
         {{if .Config.IncludeLogs}}
         <div class="section">
-            <h2>Execution Logs</h2>
+            <h2>{{t "section.logs"}}</h2>
             <div class="logs">
                 {{range .Logs}}
                 <div class="log-entry {{.Class}}">
@@ -635,8 +1284,32 @@ const htmlTemplate = `<!DOCTYPE html>
         {{end}}
 
         <div class="footer">
-            <p>Report generated by NetCrate v1.0 on {{formatTime .GeneratedAt}}</p>
+            <p>{{t "footer" (formatTime .GeneratedAt)}}</p>
         </div>
     </div>
+    {{template "charts" .}}
+    <script>
+    // Open a host's details when it is linked to, e.g. from the hosts table,
+    // and all of them for printing
+    (function () {
+        function openHost() {
+            var el = location.hash && document.getElementById(decodeURIComponent(location.hash.slice(1)));
+            if (el && el.tagName === "DETAILS") {
+                el.open = true;
+                el.scrollIntoView();
+            }
+        }
+        window.addEventListener("hashchange", openHost);
+        openHost();
+
+        // Print every host, not only the expanded ones
+        window.addEventListener("beforeprint", function () {
+            var details = document.querySelectorAll("details");
+            for (var i = 0; i < details.length; i++) {
+                details[i].open = true;
+            }
+        });
+    })();
+    </script>
 </body>
 </html>`
\ No newline at end of file
@@ -0,0 +1,372 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultReportLanguage is used for languages without a report catalog
+const DefaultReportLanguage = "en"
+
+// reportMessages holds the wording of reports per language. Keys missing
+// from a catalog fall back to English. Run data such as step messages and
+// risk rule rationales is shown as recorded.
+var reportMessages = map[string]map[string]string{
+	"en":    reportMessagesEN,
+	"zh-CN": reportMessagesZhCN,
+}
+
+var reportMessagesEN = map[string]string{
+	"title.default": "NetCrate Execution Report",
+	"title.run":     "NetCrate report for %s",
+	"title.named":   "NetCrate report: %s",
+	"title.diff":    "NetCrate changes from %s to %s",
+
+	"header.operation": "Operation",
+	"header.session":   "Session",
+	"header.generated": "Generated",
+	"footer":           "Report generated by NetCrate v1.0 on %s",
+
+	"card.status":       "Status",
+	"card.duration":     "Duration",
+	"card.success_rate": "Success Rate",
+	"card.total_steps":  "Total Steps",
+	"card.hosts_up":     "Hosts Up",
+	"card.open_ports":   "Open Ports",
+	"card.subnets":      "Subnets",
+	"card.tls_services": "TLS Services",
+
+	"section.executive":    "Executive Summary",
+	"section.changes":      "What Changed",
+	"section.charts":       "Charts",
+	"section.parameters":   "Parameters",
+	"section.steps":        "Step Execution",
+	"section.risk":         "Risk Breakdown",
+	"section.services":     "Top Services",
+	"section.hosts":        "Hosts",
+	"section.tls":          "TLS / Certificates",
+	"section.subnets":      "Subnets",
+	"section.logs":         "Execution Logs",
+	"section.host_details": "Host Details",
+
+	"executive.score":      "Hygiene Score",
+	"executive.grade":      "Grade %s",
+	"executive.tls_issues": "TLS Issues",
+	"executive.actions":    "Recommended Actions",
+	"executive.no_actions": "No action needed: no open port was rated medium or above.",
+
+	"diff.compared":           "Compared with %s",
+	"diff.compared_at":        "Compared with %s from %s",
+	"diff.new_hosts":          "New Hosts",
+	"diff.hosts_gone":         "Hosts Gone",
+	"diff.opened_ports":       "Opened Ports",
+	"diff.closed_ports":       "Closed Ports",
+	"diff.changed_services":   "Changed Services",
+	"diff.no_changes":         "No changes between the runs.",
+	"diff.ports_not_compared": "Ports were not compared because one of the runs did not scan them.",
+	"diff.hosts":              "Hosts",
+	"diff.ports":              "Ports and Services",
+	"diff.added":              "added",
+	"diff.removed":            "removed",
+	"diff.opened":             "opened",
+	"diff.closed":             "closed",
+	"diff.changed":            "changed",
+
+	"chart.step_status": "Step Status",
+	"chart.services":    "Open Ports by Service",
+	"chart.timeline":    "Timeline",
+	"chart.needs_js":    "Charts need JavaScript",
+	"chart.no_data":     "No data to chart",
+	"chart.concurrency": "concurrency",
+	"chart.running":     "%d steps running",
+	"chart.step_tip":    "%s (%s): starts at +%ss, takes %ss (%s%% of the run)",
+	"chart.alongside":   ", alongside %s",
+
+	"timeline.summary": "Steps span %s, with at most %d running at once.",
+	"timeline.slowest": "The slowest step is %s.",
+	"timeline.starts":  "Starts At",
+	"timeline.share":   "Share of Run",
+	"timeline.along":   "Runs Alongside",
+	"timeline.badge":   "slowest",
+
+	"col.step":         "Step",
+	"col.status":       "Status",
+	"col.duration":     "Duration",
+	"col.message":      "Message",
+	"col.host":         "Host",
+	"col.name":         "Name",
+	"col.device":       "Device",
+	"col.port":         "Port",
+	"col.service":      "Service",
+	"col.severity":     "Severity",
+	"col.rationale":    "Rationale",
+	"col.risk":         "Risk",
+	"col.change":       "Change",
+	"col.before":       "Before",
+	"col.after":        "After",
+	"col.protocol":     "Protocol",
+	"col.subject":      "Subject",
+	"col.issuer":       "Issuer",
+	"col.expires":      "Expires",
+	"col.issues":       "Issues",
+	"col.subnet":       "Subnet",
+	"col.hosts_up":     "Hosts Up",
+	"col.open_ports":   "Open Ports",
+	"col.top_services": "Top Services",
+	"col.version":      "Version",
+	"col.banner":       "Banner",
+	"col.fingerprint":  "Fingerprint",
+	"col.run":          "Run",
+	"col.when":         "When",
+
+	"host.ports":        "%d open ports",
+	"host.no_ports":     "No open ports found.",
+	"host.history":      "History",
+	"history.not_seen":  "Not seen",
+	"history.seen":      "Seen (ports not compared)",
+	"history.unchanged": "No changes",
+
+	"severity.critical": "critical",
+	"severity.high":     "high",
+	"severity.medium":   "medium",
+	"severity.low":      "low",
+
+	"status.completed": "completed",
+	"status.failed":    "failed",
+	"status.skipped":   "skipped",
+	"status.running":   "running",
+
+	"chart.status.completed": "Completed",
+	"chart.status.failed":    "Failed",
+	"chart.status.skipped":   "Skipped",
+
+	"issue.expired":           "expired",
+	"issue.expires_in":        "expires in %d days",
+	"issue.self-signed":       "self-signed",
+	"issue.outdated protocol": "outdated protocol",
+
+	"hosts.count": "%d hosts",
+
+	"action.ftp":             "Replace FTP with SFTP or FTPS on {hosts}",
+	"action.ssh":             "Restrict SSH to management networks and require keys on {hosts}",
+	"action.telnet":          "Disable Telnet and use SSH instead on {hosts}",
+	"action.rpc":             "Block MS-RPC at the network edge for {hosts}",
+	"action.netbios":         "Disable NetBIOS over TCP/IP on {hosts}",
+	"action.smb":             "Patch SMB and limit file sharing to trusted networks on {hosts}",
+	"action.rdp":             "Put RDP behind a VPN or gateway with NLA on {hosts}",
+	"action.http":            "Redirect plain HTTP to HTTPS on {hosts}",
+	"action.https":           "Review the exposure of web services on {hosts}",
+	"action.mysql":           "Keep MySQL off shared networks on {hosts}",
+	"action.postgresql":      "Keep PostgreSQL off shared networks on {hosts}",
+	"action.mongodb":         "Enable authentication and bind MongoDB locally on {hosts}",
+	"action.review":          "Review %s on port %d on {hosts}",
+	"action.tls.expired":     "Renew expired certificates on {hosts}",
+	"action.tls.expiring":    "Renew certificates expiring within 30 days on {hosts}",
+	"action.tls.self-signed": "Replace self-signed certificates on {hosts}",
+	"action.tls.protocol":    "Disable SSL 3.0, TLS 1.0 and TLS 1.1 on {hosts}",
+}
+
+var reportMessagesZhCN = map[string]string{
+	"title.default": "NetCrate 执行报告",
+	"title.run":     "NetCrate 报告：%s",
+	"title.named":   "NetCrate 报告：%s",
+	"title.diff":    "NetCrate 变更报告：%s → %s",
+
+	"header.operation": "操作",
+	"header.session":   "会话",
+	"header.generated": "生成时间",
+	"footer":           "由 NetCrate v1.0 于 %s 生成",
+
+	"card.status":       "状态",
+	"card.duration":     "耗时",
+	"card.success_rate": "成功率",
+	"card.total_steps":  "步骤总数",
+	"card.hosts_up":     "存活主机",
+	"card.open_ports":   "开放端口",
+	"card.subnets":      "子网",
+	"card.tls_services": "TLS 服务",
+
+	"section.executive":    "执行摘要",
+	"section.changes":      "变更内容",
+	"section.charts":       "图表",
+	"section.parameters":   "参数",
+	"section.steps":        "步骤执行",
+	"section.risk":         "风险分布",
+	"section.services":     "常见服务",
+	"section.hosts":        "主机",
+	"section.tls":          "TLS / 证书",
+	"section.subnets":      "子网",
+	"section.logs":         "执行日志",
+	"section.host_details": "主机详情",
+
+	"executive.score":      "网络卫生评分",
+	"executive.grade":      "等级 %s",
+	"executive.tls_issues": "TLS 问题",
+	"executive.actions":    "建议措施",
+	"executive.no_actions": "无需处理：没有开放端口被评为中危及以上。",
+
+	"diff.compared":           "对比运行 %s",
+	"diff.compared_at":        "对比运行 %s（%s）",
+	"diff.new_hosts":          "新增主机",
+	"diff.hosts_gone":         "消失主机",
+	"diff.opened_ports":       "新开端口",
+	"diff.closed_ports":       "关闭端口",
+	"diff.changed_services":   "服务变化",
+	"diff.no_changes":         "两次运行之间没有变化。",
+	"diff.ports_not_compared": "其中一次运行未扫描端口，因此未比较端口。",
+	"diff.hosts":              "主机",
+	"diff.ports":              "端口与服务",
+	"diff.added":              "新增",
+	"diff.removed":            "消失",
+	"diff.opened":             "开放",
+	"diff.closed":             "关闭",
+	"diff.changed":            "变化",
+
+	"chart.step_status": "步骤状态",
+	"chart.services":    "各服务开放端口",
+	"chart.timeline":    "时间线",
+	"chart.needs_js":    "图表需要启用 JavaScript",
+	"chart.no_data":     "暂无图表数据",
+	"chart.concurrency": "并发",
+	"chart.running":     "%d 个步骤运行中",
+	"chart.step_tip":    "%s（%s）：+%s 秒开始，耗时 %s 秒（占运行 %s%%）",
+	"chart.alongside":   "，同时运行：%s",
+
+	"timeline.summary": "步骤共持续 %s，最多 %d 个同时运行。",
+	"timeline.slowest": "最慢的步骤是 %s。",
+	"timeline.starts":  "开始于",
+	"timeline.share":   "占比",
+	"timeline.along":   "同时运行",
+	"timeline.badge":   "最慢",
+
+	"col.step":         "步骤",
+	"col.status":       "状态",
+	"col.duration":     "耗时",
+	"col.message":      "信息",
+	"col.host":         "主机",
+	"col.name":         "名称",
+	"col.device":       "设备",
+	"col.port":         "端口",
+	"col.service":      "服务",
+	"col.severity":     "严重程度",
+	"col.rationale":    "原因",
+	"col.risk":         "风险",
+	"col.change":       "变化",
+	"col.before":       "之前",
+	"col.after":        "之后",
+	"col.protocol":     "协议",
+	"col.subject":      "主题",
+	"col.issuer":       "颁发者",
+	"col.expires":      "到期",
+	"col.issues":       "问题",
+	"col.subnet":       "子网",
+	"col.hosts_up":     "存活主机",
+	"col.open_ports":   "开放端口",
+	"col.top_services": "常见服务",
+	"col.version":      "版本",
+	"col.banner":       "Banner",
+	"col.fingerprint":  "指纹",
+	"col.run":          "运行",
+	"col.when":         "时间",
+
+	"host.ports":        "%d 个开放端口",
+	"host.no_ports":     "未发现开放端口。",
+	"host.history":      "历史记录",
+	"history.not_seen":  "未发现",
+	"history.seen":      "已发现（未比较端口）",
+	"history.unchanged": "无变化",
+
+	"severity.critical": "严重",
+	"severity.high":     "高危",
+	"severity.medium":   "中危",
+	"severity.low":      "低危",
+
+	"status.completed": "已完成",
+	"status.failed":    "失败",
+	"status.skipped":   "已跳过",
+	"status.running":   "运行中",
+
+	"chart.status.completed": "已完成",
+	"chart.status.failed":    "失败",
+	"chart.status.skipped":   "已跳过",
+
+	"issue.expired":           "已过期",
+	"issue.expires_in":        "%d 天后过期",
+	"issue.self-signed":       "自签名",
+	"issue.outdated protocol": "过时的协议",
+
+	"hosts.count": "%d 台主机",
+
+	"action.ftp":             "在 {hosts} 上用 SFTP 或 FTPS 替换 FTP",
+	"action.ssh":             "将 {hosts} 的 SSH 限制在管理网络内并要求密钥登录",
+	"action.telnet":          "在 {hosts} 上禁用 Telnet，改用 SSH",
+	"action.rpc":             "在网络边界为 {hosts} 阻断 MS-RPC",
+	"action.netbios":         "在 {hosts} 上禁用 NetBIOS over TCP/IP",
+	"action.smb":             "为 {hosts} 修补 SMB 并将文件共享限制在可信网络",
+	"action.rdp":             "将 {hosts} 的 RDP 置于启用 NLA 的 VPN 或网关之后",
+	"action.http":            "将 {hosts} 的明文 HTTP 重定向到 HTTPS",
+	"action.https":           "检查 {hosts} 上 Web 服务的暴露情况",
+	"action.mysql":           "不要让 {hosts} 的 MySQL 暴露在共享网络中",
+	"action.postgresql":      "不要让 {hosts} 的 PostgreSQL 暴露在共享网络中",
+	"action.mongodb":         "为 {hosts} 的 MongoDB 启用认证并仅绑定本地",
+	"action.review":          "检查 {hosts} 上 %[2]d 端口的 %[1]s 服务",
+	"action.tls.expired":     "续期 {hosts} 上已过期的证书",
+	"action.tls.expiring":    "续期 {hosts} 上 30 天内到期的证书",
+	"action.tls.self-signed": "替换 {hosts} 上的自签名证书",
+	"action.tls.protocol":    "在 {hosts} 上禁用 SSL 3.0、TLS 1.0 和 TLS 1.1",
+}
+
+// ReportLanguages returns the languages reports can be written in
+func ReportLanguages() []string {
+	return []string{"en", "zh-CN"}
+}
+
+// normalizeReportLanguage maps tags such as "zh", "zh_CN" or "zh-cn" to a
+// catalog key
+func normalizeReportLanguage(lang string) string {
+	lang = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+	if strings.HasPrefix(lang, "zh") {
+		return "zh-CN"
+	}
+	return DefaultReportLanguage
+}
+
+// Text returns the report wording for key in lang, formatted with args
+func Text(lang, key string, args ...interface{}) string {
+	msg, ok := reportMessages[normalizeReportLanguage(lang)][key]
+	if !ok {
+		msg, ok = reportMessagesEN[key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// hasText reports whether key has wording in English, the fallback catalog
+func hasText(key string) bool {
+	_, ok := reportMessagesEN[key]
+	return ok
+}
+
+// label translates a value of a known vocabulary, such as a severity or a
+// status, and returns values it does not know as they are
+func label(lang, vocabulary, value string) string {
+	key := vocabulary + "." + value
+	if !hasText(key) {
+		return value
+	}
+	return Text(lang, key)
+}
+
+// issueText translates a TLS issue recorded by the scan
+func issueText(lang, issue string) string {
+	var days int
+	if _, err := fmt.Sscanf(issue, "expires in %d days", &days); err == nil {
+		return Text(lang, "issue.expires_in", days)
+	}
+	return label(lang, "issue", issue)
+}
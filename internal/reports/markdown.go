@@ -0,0 +1,121 @@
+package reports
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// RenderMarkdown writes a Markdown report for an execution result to w. It
+// carries the same findings as the HTML report without the charts, for
+// pasting into tickets, wikis and pull requests.
+func RenderMarkdown(w io.Writer, result *ExecutionResult, title string, policy SummaryPolicy, lang string) error {
+	lang = normalizeReportLanguage(lang)
+	if title == "" {
+		title = Text(lang, "title.default")
+	}
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "- %s: **%s**\n", Text(lang, "header.operation"), result.TemplateName)
+	fmt.Fprintf(&b, "- %s: `%s`\n", Text(lang, "header.session"), result.SessionID)
+	fmt.Fprintf(&b, "- %s: %s\n", Text(lang, "header.generated"), formatTime(time.Now()))
+	fmt.Fprintf(&b, "- %s: %s\n", Text(lang, "card.status"), label(lang, "status", result.Status))
+	fmt.Fprintf(&b, "- %s: %s\n\n", Text(lang, "card.duration"), formatDuration(result.Duration))
+
+	if scan := result.Scan; scan != nil {
+		if scan.Scanned {
+			summary := BuildExecutiveSummary(scan, policy, lang)
+			fmt.Fprintf(&b, "## %s\n\n", Text(lang, "section.executive"))
+			fmt.Fprintf(&b, "%s: **%d/100** (%s)\n\n", Text(lang, "executive.score"), summary.Score, Text(lang, "executive.grade", summary.Grade))
+			if len(summary.Actions) == 0 {
+				fmt.Fprintf(&b, "%s\n\n", Text(lang, "executive.no_actions"))
+			} else {
+				fmt.Fprintf(&b, "### %s\n\n", Text(lang, "executive.actions"))
+				for _, action := range summary.Actions {
+					fmt.Fprintf(&b, "- **%s** %s\n", label(lang, "severity", action.Severity), markdownEscape(action.Advice))
+				}
+				b.WriteString("\n")
+			}
+		}
+
+		fmt.Fprintf(&b, "## %s\n\n", Text(lang, "section.hosts"))
+		fmt.Fprintf(&b, "%s: %d, %s: %d\n\n", Text(lang, "card.hosts_up"), scan.HostsUp, Text(lang, "card.open_ports"), scan.OpenPorts)
+		if len(scan.Hosts) > 0 {
+			markdownRow(&b, Text(lang, "col.host"), Text(lang, "col.name"), Text(lang, "col.device"), Text(lang, "col.open_ports"), Text(lang, "col.risk"))
+			markdownRow(&b, "---", "---", "---", "---", "---")
+			for _, host := range scan.Hosts {
+				var ports []string
+				for _, port := range host.OpenPorts {
+					entry := fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+					if port.Service != "" {
+						entry += " " + port.Service
+					}
+					ports = append(ports, entry)
+				}
+				markdownRow(&b, host.Host, host.Hostname, strings.TrimSpace(host.Vendor+" "+host.Category),
+					strings.Join(ports, ", "), label(lang, "severity", host.Risk))
+			}
+			b.WriteString("\n")
+		}
+
+		if len(scan.RiskFindings) > 0 {
+			fmt.Fprintf(&b, "## %s\n\n", Text(lang, "section.risk"))
+			markdownRow(&b, Text(lang, "col.host"), Text(lang, "col.port"), Text(lang, "col.service"), Text(lang, "col.severity"), Text(lang, "col.rationale"))
+			markdownRow(&b, "---", "---", "---", "---", "---")
+			for _, finding := range scan.RiskFindings {
+				markdownRow(&b, finding.Host, fmt.Sprint(finding.Port), finding.Service, label(lang, "severity", finding.Severity), finding.Rationale)
+			}
+			b.WriteString("\n")
+		}
+
+		if len(scan.TLS) > 0 {
+			fmt.Fprintf(&b, "## %s\n\n", Text(lang, "section.tls"))
+			markdownRow(&b, Text(lang, "col.host"), Text(lang, "col.port"), Text(lang, "col.subject"), Text(lang, "col.expires"), Text(lang, "col.issues"))
+			markdownRow(&b, "---", "---", "---", "---", "---")
+			for _, finding := range scan.TLS {
+				var issues []string
+				for _, issue := range finding.Issues {
+					issues = append(issues, issueText(lang, issue))
+				}
+				expires := ""
+				if !finding.NotAfter.IsZero() {
+					expires = finding.NotAfter.Format("2006-01-02")
+				}
+				markdownRow(&b, finding.Host, fmt.Sprint(finding.Port), finding.Subject, expires, strings.Join(issues, ", "))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if steps := orderedSteps(result); len(steps) > 0 {
+		fmt.Fprintf(&b, "## %s\n\n", Text(lang, "section.steps"))
+		markdownRow(&b, Text(lang, "col.step"), Text(lang, "col.status"), Text(lang, "col.duration"), Text(lang, "col.message"))
+		markdownRow(&b, "---", "---", "---", "---")
+		for _, step := range steps {
+			message := step.Message
+			if step.Error != "" {
+				message = step.Error
+			}
+			markdownRow(&b, step.Name, label(lang, "status", step.Status), formatDuration(step.Duration), message)
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownRow writes one table row, escaping the cells
+func markdownRow(b *strings.Builder, cells ...string) {
+	for i, cell := range cells {
+		if cell != "---" {
+			cells[i] = markdownEscape(cell)
+		}
+	}
+	fmt.Fprintf(b, "| %s |\n", strings.Join(cells, " | "))
+}
+
+// markdownEscape keeps values from breaking tables or adding markup
+var markdownEscape = strings.NewReplacer("|", "\\|", "\n", " ", "*", "\\*", "_", "\\_", "`", "\\`", "<", "&lt;").Replace
@@ -0,0 +1,129 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReportFormats lists the formats reports can be written in
+func ReportFormats() []string {
+	return []string{"html", "md"}
+}
+
+// ReportOutput describes a report file, such as the one written by the
+// report step that closes a template
+type ReportOutput struct {
+	Format   string // "html" (default) or "md"
+	Path     string // may use {template}, {session} and {date}; default ~/.netcrate/reports/{template}-{date}.<format>
+	Title    string
+	Theme    string
+	Language string
+	Policy   *SummaryPolicy
+}
+
+// Validate checks the format and theme of a report output
+func (o ReportOutput) Validate() error {
+	if o.Theme != "" {
+		if err := validateTheme(o.Theme); err != nil {
+			return err
+		}
+	}
+	format := o.format()
+	for _, known := range ReportFormats() {
+		if format == known {
+			return nil
+		}
+	}
+	if format == "pdf" {
+		return fmt.Errorf("pdf reports are not supported; write html and print it to PDF from a browser")
+	}
+	return fmt.Errorf("unknown report format: %s (available: %s)", format, strings.Join(ReportFormats(), ", "))
+}
+
+func (o ReportOutput) format() string {
+	if o.Format == "" {
+		return "html"
+	}
+	return strings.ToLower(o.Format)
+}
+
+// ResolvePath expands the placeholders and ~ of the output path for a result
+func (o ReportOutput) ResolvePath(result *ExecutionResult) string {
+	path := o.Path
+	if path == "" {
+		homeDir, _ := os.UserHomeDir()
+		path = filepath.Join(homeDir, ".netcrate", "reports", "{template}-{date}."+o.format())
+	} else if strings.HasPrefix(path, "~/") {
+		homeDir, _ := os.UserHomeDir()
+		path = filepath.Join(homeDir, path[2:])
+	}
+
+	date := result.EndTime
+	if date.IsZero() {
+		date = result.StartTime
+	}
+	return strings.NewReplacer(
+		"{template}", safeFileName(result.TemplateName),
+		"{session}", safeFileName(result.SessionID),
+		"{date}", date.Format("20060102-150405"),
+	).Replace(path)
+}
+
+// safeFileName keeps a value usable as part of a file name
+func safeFileName(value string) string {
+	if value == "" {
+		return "report"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, value)
+}
+
+// WriteReport renders a result in the output's format and writes it to the
+// output's path, returning the path written
+func WriteReport(result *ExecutionResult, out ReportOutput) (string, error) {
+	if err := out.Validate(); err != nil {
+		return "", err
+	}
+	policy := DefaultSummaryPolicy
+	if out.Policy != nil {
+		policy = *out.Policy
+	}
+
+	var report bytes.Buffer
+	switch out.format() {
+	case "md":
+		if err := RenderMarkdown(&report, result, out.Title, policy, out.Language); err != nil {
+			return "", err
+		}
+	default:
+		reporter, err := NewHTMLReporter(HTMLReportConfig{
+			Title:      out.Title,
+			Theme:      out.Theme,
+			Standalone: true,
+			Policy:     &policy,
+			Language:   out.Language,
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := reporter.Render(&report, result); err != nil {
+			return "", err
+		}
+	}
+
+	path := out.ResolvePath(result)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+	if err := os.WriteFile(path, report.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	return path, nil
+}
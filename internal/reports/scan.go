@@ -0,0 +1,142 @@
+package reports
+
+import "time"
+
+// ScanReport holds the network findings of a discovery or port scan run,
+// shown in the report alongside the steps
+type ScanReport struct {
+	Scanned      bool            `json:"scanned"` // whether the run included a port scan
+	HostsUp      int             `json:"hosts_up"`
+	OpenPorts    int             `json:"open_ports"`
+	Hosts        []HostReport    `json:"hosts"`
+	TopServices  []ChartPoint    `json:"top_services"`  // open ports per service, most first
+	Risk         []ChartPoint    `json:"risk"`          // open ports per severity, most severe first
+	RiskFindings []RiskFinding   `json:"risk_findings"` // ports rated medium or above
+	TLS          []TLSFinding    `json:"tls"`
+	Subnets      []SubnetSummary `json:"subnets"`
+}
+
+// HostReport is one live host with its open ports
+type HostReport struct {
+	Host      string         `json:"host"`
+	Hostname  string         `json:"hostname,omitempty"`
+	MAC       string         `json:"mac,omitempty"`
+	Vendor    string         `json:"vendor,omitempty"`
+	Category  string         `json:"category,omitempty"`
+	Risk      string         `json:"risk,omitempty"` // highest severity among its ports
+	OpenPorts []PortReport   `json:"open_ports"`
+	History   []HostSighting `json:"history,omitempty"` // earlier runs, most recent first
+}
+
+// PortReport is one open port of a host
+type PortReport struct {
+	Port     int               `json:"port"`
+	Protocol string            `json:"protocol"`
+	Service  string            `json:"service,omitempty"`
+	Product  string            `json:"product,omitempty"`
+	Version  string            `json:"version,omitempty"`
+	Risk     string            `json:"risk"`
+	Banner   string            `json:"banner,omitempty"`
+	Details  map[string]string `json:"details,omitempty"` // fingerprint details, e.g. "http.server"
+}
+
+// HostSighting compares a host with how an earlier run saw it
+type HostSighting struct {
+	RunID         string    `json:"run_id"`
+	StartTime     time.Time `json:"start_time"`
+	Seen          bool      `json:"seen"`              // whether the host was up in that run
+	PortsCompared bool      `json:"ports_compared"`    // false when either run did not scan ports
+	Opened        []string  `json:"opened,omitempty"`  // ports open now but not then
+	Closed        []string  `json:"closed,omitempty"`  // ports open then but not now
+	Changed       []string  `json:"changed,omitempty"` // ports whose service changed
+}
+
+// HostID is the anchor of a host's detail section in the report
+func (h HostReport) HostID() string {
+	return "host-" + h.Host
+}
+
+// RiskFinding explains why a port was rated
+type RiskFinding struct {
+	Host      string `json:"host"`
+	Port      int    `json:"port"`
+	Service   string `json:"service"`
+	Severity  string `json:"severity"`
+	Rationale string `json:"rationale,omitempty"`
+	Rule      string `json:"rule,omitempty"` // risk rule that matched
+}
+
+// TLSFinding describes a certificate seen on a TLS service
+type TLSFinding struct {
+	Host     string    `json:"host"`
+	Port     int       `json:"port"`
+	Version  string    `json:"version,omitempty"`
+	Cipher   string    `json:"cipher,omitempty"`
+	Subject  string    `json:"subject,omitempty"`
+	Issuer   string    `json:"issuer,omitempty"`
+	NotAfter time.Time `json:"not_after,omitempty"`
+	Issues   []string  `json:"issues,omitempty"` // e.g. "expired", "self-signed"
+}
+
+// SubnetSummary aggregates hosts and ports per /24 (IPv4) or /64 (IPv6)
+type SubnetSummary struct {
+	Subnet      string `json:"subnet"`
+	HostsUp     int    `json:"hosts_up"`
+	OpenPorts   int    `json:"open_ports"`
+	TopServices string `json:"top_services,omitempty"`
+}
+
+// severityColor is the badge color of a risk severity
+func severityColor(severity string) string {
+	switch severity {
+	case "critical":
+		return "#8b0000"
+	case "high":
+		return "#dc3545"
+	case "medium":
+		return "#fd7e14"
+	case "low":
+		return "#28a745"
+	default:
+		return "#6c757d"
+	}
+}
+
+// maxValue returns the largest value of a chart, for scaling bars
+func maxValue(points []ChartPoint) float64 {
+	max := 0.0
+	for _, p := range points {
+		if p.Value > max {
+			max = p.Value
+		}
+	}
+	return max
+}
+
+// DiffReport lists what changed between an earlier run and the reported one
+type DiffReport struct {
+	BaseRunID       string       `json:"base_run_id"`
+	BaseTime        time.Time    `json:"base_time"`
+	AddedHosts      []HostReport `json:"added_hosts,omitempty"`
+	RemovedHosts    []HostReport `json:"removed_hosts,omitempty"`
+	OpenedPorts     []PortChange `json:"opened_ports,omitempty"`
+	ClosedPorts     []PortChange `json:"closed_ports,omitempty"`
+	ChangedServices []PortChange `json:"changed_services,omitempty"`
+	PortsCompared   bool         `json:"ports_compared"` // false unless both runs scanned ports
+}
+
+// PortChange is a port that opened, closed or changed service between runs
+type PortChange struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Before   string `json:"before,omitempty"` // service as seen in the earlier run
+	After    string `json:"after,omitempty"`  // service as seen in the reported run
+	Severity string `json:"severity"`
+}
+
+// HasChanges reports whether anything changed between the runs
+func (d *DiffReport) HasChanges() bool {
+	return len(d.AddedHosts) > 0 || len(d.RemovedHosts) > 0 || len(d.OpenedPorts) > 0 ||
+		len(d.ClosedPorts) > 0 || len(d.ChangedServices) > 0
+}
@@ -0,0 +1,264 @@
+package reports
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SummaryPolicy controls how the executive summary scores a network and
+// which actions it recommends. It is read from YAML:
+//
+//	weights: {critical: 25, high: 10, medium: 4, low: 1}
+//	tls_issue_weight: 3
+//	max_actions: 5
+//	actions:
+//	  telnet: Replace Telnet with SSH on {hosts}
+type SummaryPolicy struct {
+	Weights        map[string]float64 `yaml:"weights" json:"weights"`                   // score deducted per open port of a severity
+	TLSIssueWeight float64            `yaml:"tls_issue_weight" json:"tls_issue_weight"` // score deducted per certificate or protocol issue
+	MaxActions     int                `yaml:"max_actions" json:"max_actions"`
+	Actions        map[string]string  `yaml:"actions" json:"actions"` // advice by risk rule or service, replacing the built-in advice; {hosts} is replaced by the hosts
+}
+
+// DefaultSummaryPolicy is used when no policy file exists. Its advice comes
+// from the report catalog, in the language of the report.
+var DefaultSummaryPolicy = SummaryPolicy{
+	Weights:        map[string]float64{"critical": 25, "high": 10, "medium": 4, "low": 1},
+	TLSIssueWeight: 3,
+	MaxActions:     5,
+}
+
+// DefaultSummaryPolicyPath returns ~/.netcrate/report_policy.yaml
+func DefaultSummaryPolicyPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".netcrate", "report_policy.yaml")
+}
+
+// LoadSummaryPolicy loads a policy from path (or the default path when
+// empty). Settings missing from the file keep their defaults.
+func LoadSummaryPolicy(path string) (SummaryPolicy, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultSummaryPolicyPath()
+	}
+
+	policy := DefaultSummaryPolicy
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return policy, nil
+		}
+		return policy, fmt.Errorf("failed to read report policy: %w", err)
+	}
+
+	var custom SummaryPolicy
+	if err := yaml.Unmarshal(data, &custom); err != nil {
+		return policy, fmt.Errorf("failed to parse report policy %s: %w", path, err)
+	}
+
+	policy.Weights = mergeWeights(policy.Weights, custom.Weights)
+	if custom.TLSIssueWeight > 0 {
+		policy.TLSIssueWeight = custom.TLSIssueWeight
+	}
+	if custom.MaxActions > 0 {
+		policy.MaxActions = custom.MaxActions
+	}
+	actions := make(map[string]string, len(custom.Actions))
+	for name, advice := range custom.Actions {
+		actions[strings.ToLower(name)] = advice
+	}
+	policy.Actions = actions
+
+	return policy, nil
+}
+
+func mergeWeights(defaults, custom map[string]float64) map[string]float64 {
+	weights := make(map[string]float64, len(defaults))
+	for severity, weight := range defaults {
+		weights[severity] = weight
+	}
+	for severity, weight := range custom {
+		weights[strings.ToLower(severity)] = weight
+	}
+	return weights
+}
+
+// ExecutiveSummary condenses the findings of a run for readers who will not
+// go through the host tables
+type ExecutiveSummary struct {
+	Score          int          `json:"score"` // network hygiene, 0-100
+	Grade          string       `json:"grade"` // A-F
+	SeverityCounts []ChartPoint `json:"severity_counts"`
+	TLSIssues      int          `json:"tls_issues"`
+	Actions        []Action     `json:"actions"`
+}
+
+// Action is a recommended remediation, most urgent first
+type Action struct {
+	Severity string `json:"severity"`
+	Advice   string `json:"advice"`
+	Hosts    int    `json:"hosts"`
+}
+
+// summarySeverities lists severities most severe first
+var summarySeverities = []string{"critical", "high", "medium", "low"}
+
+// severityRank orders severities; unknown ones rank lowest
+func severityRank(severity string) int {
+	for i, s := range summarySeverities {
+		if s == severity {
+			return len(summarySeverities) - i
+		}
+	}
+	return 0
+}
+
+// BuildExecutiveSummary scores a scan report against a policy, wording the
+// recommended actions in lang
+func BuildExecutiveSummary(scan *ScanReport, policy SummaryPolicy, lang string) *ExecutiveSummary {
+	summary := &ExecutiveSummary{}
+
+	counts := make(map[string]float64)
+	for _, point := range scan.Risk {
+		counts[point.Label] = point.Value
+	}
+	deduction := 0.0
+	for _, severity := range summarySeverities {
+		summary.SeverityCounts = append(summary.SeverityCounts, ChartPoint{
+			Label: severity,
+			Value: counts[severity],
+			Color: severityColor(severity),
+		})
+		deduction += counts[severity] * policy.Weights[severity]
+	}
+	for _, finding := range scan.TLS {
+		summary.TLSIssues += len(finding.Issues)
+	}
+	deduction += float64(summary.TLSIssues) * policy.TLSIssueWeight
+
+	summary.Score = 100 - int(deduction+0.5)
+	if summary.Score < 0 {
+		summary.Score = 0
+	}
+	summary.Grade = scoreGrade(summary.Score)
+	summary.Actions = recommendActions(scan, policy, lang)
+
+	return summary
+}
+
+// scoreColor is the display color of a hygiene score
+func scoreColor(score int) string {
+	switch {
+	case score >= 80:
+		return "#28a745"
+	case score >= 60:
+		return "#fd7e14"
+	default:
+		return "#dc3545"
+	}
+}
+
+// scoreGrade maps a score onto a school grade
+func scoreGrade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+// recommendActions groups the risk findings by rule (or service when no
+// rule matched) and certificate issue, most severe and widespread first
+func recommendActions(scan *ScanReport, policy SummaryPolicy, lang string) []Action {
+	type group struct {
+		severity string
+		hosts    map[string]bool
+		advice   string
+	}
+	groups := make(map[string]*group)
+	add := func(key, severity, host, advice string) {
+		g, ok := groups[key]
+		if !ok {
+			g = &group{severity: severity, hosts: make(map[string]bool), advice: advice}
+			groups[key] = g
+		}
+		if severityRank(severity) > severityRank(g.severity) {
+			g.severity = severity
+		}
+		g.hosts[host] = true
+	}
+
+	for _, finding := range scan.RiskFindings {
+		key := strings.ToLower(finding.Rule)
+		if key == "" {
+			key = strings.ToLower(finding.Service)
+		}
+		advice, ok := policy.Actions[key]
+		if !ok && hasText("action."+key) {
+			advice, ok = Text(lang, "action."+key), true
+		}
+		if !ok {
+			advice = Text(lang, "action.review", finding.Service, finding.Port)
+			if finding.Rationale != "" {
+				advice += ": " + finding.Rationale
+			}
+		}
+		add("rule:"+key, finding.Severity, finding.Host, advice)
+	}
+
+	for _, finding := range scan.TLS {
+		for _, issue := range finding.Issues {
+			switch {
+			case issue == "expired":
+				add("tls:expired", "high", finding.Host, Text(lang, "action.tls.expired"))
+			case strings.HasPrefix(issue, "expires in"):
+				add("tls:expiring", "medium", finding.Host, Text(lang, "action.tls.expiring"))
+			case issue == "self-signed":
+				add("tls:self-signed", "medium", finding.Host, Text(lang, "action.tls.self-signed"))
+			default:
+				add("tls:protocol", "medium", finding.Host, Text(lang, "action.tls.protocol"))
+			}
+		}
+	}
+
+	var actions []Action
+	for _, g := range groups {
+		hosts := Text(lang, "hosts.count", len(g.hosts))
+		if len(g.hosts) == 1 {
+			for host := range g.hosts {
+				hosts = host
+			}
+		}
+		actions = append(actions, Action{
+			Severity: g.severity,
+			Advice:   strings.ReplaceAll(g.advice, "{hosts}", hosts),
+			Hosts:    len(g.hosts),
+		})
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		a, b := actions[i], actions[j]
+		if severityRank(a.Severity) != severityRank(b.Severity) {
+			return severityRank(a.Severity) > severityRank(b.Severity)
+		}
+		if a.Hosts != b.Hosts {
+			return a.Hosts > b.Hosts
+		}
+		return a.Advice < b.Advice
+	})
+	if policy.MaxActions > 0 && len(actions) > policy.MaxActions {
+		actions = actions[:policy.MaxActions]
+	}
+	return actions
+}
@@ -0,0 +1,61 @@
+package reports
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReportTemplatesDir returns ~/.netcrate/report-templates, where users keep
+// their own report layouts as <name>.html, with an optional <name>.yaml
+// summary policy next to each
+func ReportTemplatesDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".netcrate", "report-templates")
+}
+
+// ListReportTemplates returns the names of the custom report templates
+func ListReportTemplates() []string {
+	matches, _ := filepath.Glob(filepath.Join(ReportTemplatesDir(), "*.html"))
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		names = append(names, strings.TrimSuffix(filepath.Base(match), ".html"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FindReportTemplate resolves a template name, or a path to an .html file,
+// to the template file and its policy file. policyPath is empty when the
+// template has no policy of its own.
+func FindReportTemplate(name string) (templatePath, policyPath string, err error) {
+	templatePath = name
+	if !strings.ContainsRune(name, os.PathSeparator) && !strings.HasSuffix(name, ".html") {
+		templatePath = filepath.Join(ReportTemplatesDir(), name+".html")
+	}
+
+	if _, err := os.Stat(templatePath); err != nil {
+		if os.IsNotExist(err) {
+			available := ListReportTemplates()
+			if len(available) == 0 {
+				return "", "", fmt.Errorf("report template %s not found (no templates in %s)", name, ReportTemplatesDir())
+			}
+			return "", "", fmt.Errorf("report template %s not found (available: %s)", name, strings.Join(available, ", "))
+		}
+		return "", "", fmt.Errorf("failed to read report template: %w", err)
+	}
+
+	candidate := strings.TrimSuffix(templatePath, ".html") + ".yaml"
+	if _, err := os.Stat(candidate); err == nil {
+		policyPath = candidate
+	}
+	return templatePath, policyPath, nil
+}
+
+// BuiltinTemplate returns the source of the built-in report layout, as a
+// starting point for custom templates
+func BuiltinTemplate() string {
+	return htmlTemplate
+}
@@ -0,0 +1,138 @@
+package reports
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// ThemeTokens are the design tokens of a report theme: colors, fonts and
+// spacing, emitted as CSS custom properties (--nc-<name>) that the report
+// stylesheet uses instead of literal values
+type ThemeTokens map[string]string
+
+// lightTokens is the base palette; other themes override some of it
+var lightTokens = ThemeTokens{
+	"bg":          "#f8f9fa",
+	"surface":     "#ffffff",
+	"surface-alt": "#f8f9fa",
+	"text":        "#333333",
+	"muted":       "#666666",
+	"heading":     "#2c3e50",
+	"border":      "#ecf0f1",
+	"accent":      "#3498db",
+	"shadow":      "0 2px 4px rgba(0,0,0,0.1)",
+	"tooltip-bg":  "rgba(0,0,0,0.8)",
+	"success-bg":  "#d4edda",
+	"success-fg":  "#155724",
+	"error-bg":    "#f8d7da",
+	"error-fg":    "#721c24",
+	"warning-bg":  "#fff3cd",
+	"warning-fg":  "#856404",
+	"font":        "-apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif",
+	"font-mono":   "SFMono-Regular, Menlo, Consolas, monospace",
+	"font-size":   "16px",
+	"space":       "20px",
+	"space-lg":    "30px",
+	"radius":      "8px",
+}
+
+// reportThemes holds the overrides of each theme over lightTokens
+var reportThemes = map[string]ThemeTokens{
+	"light": {},
+	"dark": {
+		"bg":          "#1a1a1a",
+		"surface":     "#2d2d2d",
+		"surface-alt": "#3a3a3a",
+		"text":        "#e0e0e0",
+		"muted":       "#aaaaaa",
+		"heading":     "#ffffff",
+		"border":      "#444444",
+		"accent":      "#5dade2",
+		"shadow":      "0 2px 4px rgba(0,0,0,0.4)",
+		"success-bg":  "#1e4620",
+		"success-fg":  "#b7e4c0",
+		"error-bg":    "#4a1c20",
+		"error-fg":    "#f5c2c7",
+		"warning-bg":  "#4d3d0b",
+		"warning-fg":  "#ffe69c",
+	},
+	// minimal prints well: black on white, no shadows or tinted panels,
+	// tighter spacing and a serif body for paper and PDF
+	"minimal": {
+		"bg":          "#ffffff",
+		"surface":     "#ffffff",
+		"surface-alt": "#ffffff",
+		"text":        "#000000",
+		"muted":       "#444444",
+		"heading":     "#000000",
+		"border":      "#999999",
+		"accent":      "#000000",
+		"shadow":      "none",
+		"success-bg":  "#ffffff",
+		"success-fg":  "#000000",
+		"error-bg":    "#ffffff",
+		"error-fg":    "#000000",
+		"warning-bg":  "#ffffff",
+		"warning-fg":  "#000000",
+		"font":        "Georgia, 'Times New Roman', serif",
+		"font-size":   "11pt",
+		"space":       "12px",
+		"space-lg":    "16px",
+		"radius":      "0",
+	},
+}
+
+// ReportThemes lists the report themes. "default" follows the reader's
+// light or dark system setting.
+func ReportThemes() []string {
+	return []string{"default", "light", "dark", "minimal"}
+}
+
+// validateTheme checks a theme name
+func validateTheme(theme string) error {
+	for _, known := range ReportThemes() {
+		if theme == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown report theme: %s (available: %s)", theme, strings.Join(ReportThemes(), ", "))
+}
+
+// themeTokens returns the full token set of a theme
+func themeTokens(theme string) ThemeTokens {
+	tokens := make(ThemeTokens, len(lightTokens))
+	for name, value := range lightTokens {
+		tokens[name] = value
+	}
+	for name, value := range reportThemes[theme] {
+		tokens[name] = value
+	}
+	return tokens
+}
+
+// declarations renders tokens as CSS custom property declarations
+func (t ThemeTokens) declarations() string {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "--nc-%s: %s; ", name, t[name])
+	}
+	return b.String()
+}
+
+// themeCSS declares the tokens of a theme on :root. The default theme
+// switches to the dark tokens when the reader's system prefers dark.
+func themeCSS(theme string) template.CSS {
+	if theme == "default" {
+		return template.CSS(":root { " + themeTokens("light").declarations() + "}\n" +
+			"@media (prefers-color-scheme: dark) { :root { " + themeTokens("dark").declarations() + "} }")
+	}
+	return template.CSS(":root { " + themeTokens(theme).declarations() + "}")
+}
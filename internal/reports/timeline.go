@@ -0,0 +1,63 @@
+package reports
+
+import "time"
+
+// TimelineSummary describes how the steps of a run were laid out in time
+type TimelineSummary struct {
+	Span           time.Duration `json:"span_ns"`         // from the first start to the last end
+	MaxConcurrency int           `json:"max_concurrency"` // most steps running at once
+	Slowest        string        `json:"slowest,omitempty"`
+}
+
+// annotateTimeline fills in the offsets, shares and overlaps of timeline
+// points, which must be in start order, and summarizes the timeline
+func annotateTimeline(points []TimelinePoint) TimelineSummary {
+	var summary TimelineSummary
+	if len(points) == 0 {
+		return summary
+	}
+
+	start := points[0].StartTime
+	var end time.Time
+	for _, p := range points {
+		if finish := p.StartTime.Add(p.Duration); finish.After(end) {
+			end = finish
+		}
+	}
+	summary.Span = end.Sub(start)
+
+	var slowest time.Duration
+	for i := range points {
+		p := &points[i]
+		p.Offset = p.StartTime.Sub(start)
+		if summary.Span > 0 {
+			p.Share = float64(p.Duration) / float64(summary.Span) * 100
+		}
+		if p.Duration > slowest {
+			slowest = p.Duration
+			summary.Slowest = p.Name
+		}
+
+		// Steps overlap when each starts before the other ends
+		concurrent := 1
+		for j, other := range points {
+			if i != j && p.StartTime.Before(other.StartTime.Add(other.Duration)) &&
+				other.StartTime.Before(p.StartTime.Add(p.Duration)) {
+				p.Overlaps = append(p.Overlaps, other.Name)
+				if other.StartTime.Before(p.StartTime) || (other.StartTime.Equal(p.StartTime) && j < i) {
+					concurrent++
+				}
+			}
+		}
+		// Counting the steps already running when this one starts gives
+		// the concurrency at that instant
+		if concurrent > summary.MaxConcurrency {
+			summary.MaxConcurrency = concurrent
+		}
+	}
+
+	for i := range points {
+		points[i].Slowest = points[i].Name == summary.Slowest && slowest > 0
+	}
+	return summary
+}
@@ -8,8 +8,13 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/netcrate/netcrate/internal/logging"
+	"github.com/netcrate/netcrate/internal/output/store"
 )
 
+var log = logging.Component("results")
+
 // ExecutionResult represents a complete execution result
 type ExecutionResult struct {
 	// Metadata
@@ -128,7 +133,7 @@ func (hm *HistoryManager) SaveResult(result *ExecutionResult) error {
 		return err
 	}
 	
-	if err := os.WriteFile(resultPath, data, 0644); err != nil {
+	if err := store.WriteSealed(resultPath, data); err != nil {
 		return err
 	}
 	
@@ -153,7 +158,7 @@ func (hm *HistoryManager) LoadResults() error {
 		if !info.IsDir() && strings.HasSuffix(path, ".json") && path != hm.indexPath {
 			result, err := hm.loadResultFromFile(path)
 			if err != nil {
-				fmt.Printf("[WARN] Failed to load result from %s: %v\n", path, err)
+				log.Warn("failed to load result", "path", path, "error", err)
 				return nil // Continue walking
 			}
 			
@@ -168,7 +173,7 @@ func (hm *HistoryManager) LoadResults() error {
 
 // loadResultFromFile loads a single result from file
 func (hm *HistoryManager) loadResultFromFile(filePath string) (*ExecutionResult, error) {
-	data, err := os.ReadFile(filePath)
+	data, err := store.ReadRunFile(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -213,7 +218,7 @@ func (hm *HistoryManager) saveIndex() error {
 		return err
 	}
 	
-	return os.WriteFile(hm.indexPath, data, 0644)
+	return store.WriteSealed(hm.indexPath, data)
 }
 
 // ListResults returns all results matching the filter criteria
@@ -367,7 +372,7 @@ func (hm *HistoryManager) DeleteResult(sessionID string) error {
 	// Remove log file if it exists
 	if result.LogPath != "" {
 		if err := os.Remove(result.LogPath); err != nil && !os.IsNotExist(err) {
-			fmt.Printf("[WARN] Failed to remove log file %s: %v\n", result.LogPath, err)
+			log.Warn("failed to remove log file", "path", result.LogPath, "error", err)
 		}
 	}
 	
@@ -455,7 +460,7 @@ func (hm *HistoryManager) CleanupOldResults(maxAge time.Duration) (int, error) {
 	// Delete results
 	for _, sessionID := range toDelete {
 		if err := hm.DeleteResult(sessionID); err != nil {
-			fmt.Printf("[WARN] Failed to delete result %s: %v\n", sessionID, err)
+			log.Warn("failed to delete result", "session", sessionID, "error", err)
 		}
 	}
 	
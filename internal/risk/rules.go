@@ -0,0 +1,225 @@
+package risk
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Severity levels, ordered from least to most severe
+var severityOrder = map[string]int{
+	"low":      0,
+	"medium":   1,
+	"high":     2,
+	"critical": 3,
+}
+
+// Rule maps a port/service/version/network context to a severity
+type Rule struct {
+	Name      string   `yaml:"name" json:"name"`
+	Ports     []int    `yaml:"ports,omitempty" json:"ports,omitempty"`
+	Services  []string `yaml:"services,omitempty" json:"services,omitempty"`
+	Version   string   `yaml:"version,omitempty" json:"version,omitempty"` // regular expression
+	Network   string   `yaml:"network,omitempty" json:"network,omitempty"` // "private", "public", or a CIDR
+	Severity  string   `yaml:"severity" json:"severity"`
+	Rationale string   `yaml:"rationale" json:"rationale"`
+}
+
+// RuleSet is the on-disk format of a risk rules file
+type RuleSet struct {
+	Version string `yaml:"version" json:"version"`
+	Rules   []Rule `yaml:"rules" json:"rules"`
+}
+
+// Finding describes an open port to be assessed
+type Finding struct {
+	Host    string
+	Port    int
+	Service string
+	Version string
+}
+
+// Assessment is the outcome of evaluating a finding
+type Assessment struct {
+	Severity  string `json:"severity"`
+	Rationale string `json:"rationale,omitempty"`
+	Rule      string `json:"rule,omitempty"`
+}
+
+// Engine evaluates findings against an ordered list of rules; the first matching rule wins
+type Engine struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	version *regexp.Regexp
+	cidr    *net.IPNet
+}
+
+// DefaultRules reproduces the built-in quick mode port risk assessment
+var DefaultRules = []Rule{
+	{Name: "ftp", Ports: []int{21}, Severity: "high", Rationale: "FTP transmits credentials in cleartext"},
+	{Name: "ssh", Ports: []int{22}, Severity: "high", Rationale: "SSH is a common brute-force target when exposed"},
+	{Name: "telnet", Ports: []int{23}, Severity: "high", Rationale: "Telnet transmits credentials in cleartext"},
+	{Name: "rpc", Ports: []int{135}, Severity: "high", Rationale: "MS-RPC exposes a large attack surface"},
+	{Name: "netbios", Ports: []int{139}, Severity: "high", Rationale: "NetBIOS leaks host and share information"},
+	{Name: "smb", Ports: []int{445}, Severity: "high", Rationale: "SMB has a history of wormable vulnerabilities"},
+	{Name: "rdp", Ports: []int{3389}, Severity: "high", Rationale: "RDP is a common brute-force and exploit target"},
+	{Name: "http", Ports: []int{80}, Severity: "medium", Rationale: "Unencrypted web service"},
+	{Name: "https", Ports: []int{443}, Severity: "medium", Rationale: "Web service should be reviewed for exposure"},
+	{Name: "mysql", Ports: []int{3306}, Severity: "medium", Rationale: "Database reachable over the network"},
+	{Name: "postgresql", Ports: []int{5432}, Severity: "medium", Rationale: "Database reachable over the network"},
+	{Name: "mongodb", Ports: []int{27017}, Severity: "medium", Rationale: "Database reachable over the network"},
+}
+
+// DefaultRulesPath returns ~/.netcrate/risk_rules.yaml
+func DefaultRulesPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".netcrate", "risk_rules.yaml")
+}
+
+// LoadEngine loads rules from path (or the default path when empty).
+// The built-in rules are used when no rules file exists.
+func LoadEngine(path string) (*Engine, error) {
+	if path == "" {
+		path = DefaultRulesPath()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewEngine(DefaultRules)
+		}
+		return nil, fmt.Errorf("failed to read risk rules: %w", err)
+	}
+
+	var ruleSet RuleSet
+	if err := yaml.Unmarshal(data, &ruleSet); err != nil {
+		return nil, fmt.Errorf("failed to parse risk rules %s: %w", path, err)
+	}
+
+	return NewEngine(ruleSet.Rules)
+}
+
+// NewEngine validates and compiles a list of rules
+func NewEngine(rules []Rule) (*Engine, error) {
+	engine := &Engine{}
+
+	for i, rule := range rules {
+		rule.Severity = strings.ToLower(rule.Severity)
+		if _, ok := severityOrder[rule.Severity]; !ok {
+			return nil, fmt.Errorf("rule %d (%s): invalid severity %q", i+1, rule.Name, rule.Severity)
+		}
+
+		compiled := compiledRule{Rule: rule}
+		if rule.Version != "" {
+			re, err := regexp.Compile(rule.Version)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d (%s): invalid version pattern: %w", i+1, rule.Name, err)
+			}
+			compiled.version = re
+		}
+
+		switch rule.Network {
+		case "", "any", "private", "public":
+		default:
+			_, cidr, err := net.ParseCIDR(rule.Network)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d (%s): invalid network %q", i+1, rule.Name, rule.Network)
+			}
+			compiled.cidr = cidr
+		}
+
+		engine.rules = append(engine.rules, compiled)
+	}
+
+	return engine, nil
+}
+
+// Evaluate returns the assessment of the first matching rule, or "low" if none match
+func (e *Engine) Evaluate(finding Finding) Assessment {
+	for _, rule := range e.rules {
+		if rule.matches(finding) {
+			return Assessment{
+				Severity:  rule.Severity,
+				Rationale: rule.Rationale,
+				Rule:      rule.Name,
+			}
+		}
+	}
+	return Assessment{Severity: "low"}
+}
+
+// Rules returns the rules loaded into the engine
+func (e *Engine) Rules() []Rule {
+	rules := make([]Rule, 0, len(e.rules))
+	for _, rule := range e.rules {
+		rules = append(rules, rule.Rule)
+	}
+	return rules
+}
+
+// matches reports whether all conditions set on the rule hold for the finding
+func (r compiledRule) matches(finding Finding) bool {
+	if len(r.Ports) > 0 && !containsInt(r.Ports, finding.Port) {
+		return false
+	}
+
+	if len(r.Services) > 0 {
+		matched := false
+		for _, service := range r.Services {
+			if strings.EqualFold(service, finding.Service) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if r.version != nil && !r.version.MatchString(finding.Version) {
+		return false
+	}
+
+	ip := net.ParseIP(finding.Host)
+	switch {
+	case r.cidr != nil:
+		return ip != nil && r.cidr.Contains(ip)
+	case r.Network == "private":
+		return ip != nil && ip.IsPrivate()
+	case r.Network == "public":
+		return ip != nil && !ip.IsPrivate() && !ip.IsLoopback()
+	}
+
+	return true
+}
+
+// ParseSeverity validates a severity name, returning it in lower case
+func ParseSeverity(name string) (string, error) {
+	severity := strings.ToLower(name)
+	if _, ok := severityOrder[severity]; !ok {
+		return "", fmt.Errorf("invalid severity %q (expected low, medium, high or critical)", name)
+	}
+	return severity, nil
+}
+
+// CompareSeverity returns a negative, zero, or positive value as a is less, equal, or more severe than b
+func CompareSeverity(a, b string) int {
+	return severityOrder[strings.ToLower(a)] - severityOrder[strings.ToLower(b)]
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
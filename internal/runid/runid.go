@@ -0,0 +1,107 @@
+// Package runid generates run identifiers. An ID is the run type followed by
+// a ULID (48-bit millisecond timestamp and 80 random bits in Crockford
+// base32), e.g. scan_01JAB3X5Q9M2T7KZ0R4W8YV6CD. IDs of one type sort by
+// start time and stay unique when several runs start in the same second.
+package runid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// crockford is the ULID alphabet; it leaves out I, L, O and U
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidLength is the length of an encoded ULID
+const ulidLength = 26
+
+// New returns an ID for a run of the given type starting now
+func New(kind string) string {
+	return NewAt(kind, time.Now())
+}
+
+// NewAt returns an ID for a run of the given type starting at t
+func NewAt(kind string, t time.Time) string {
+	var id [16]byte
+	ms := uint64(t.UnixMilli())
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	if _, err := rand.Read(id[6:]); err != nil {
+		// No entropy source; fall back to the clock's nanoseconds
+		binary.BigEndian.PutUint64(id[8:], uint64(time.Now().UnixNano()))
+	}
+	return kind + "_" + encode(id)
+}
+
+// encode writes 128 bits as 26 base32 characters, the first of which
+// carries only the top 3 bits
+func encode(id [16]byte) string {
+	var b strings.Builder
+	b.Grow(ulidLength)
+	for i := 0; i < ulidLength; i++ {
+		// Bit offset of this character, counting from the 2 padding bits
+		offset := i*5 - 2
+		var v byte
+		for bit := 0; bit < 5; bit++ {
+			pos := offset + bit
+			if pos < 0 {
+				continue
+			}
+			v <<= 1
+			v |= (id[pos/8] >> (7 - uint(pos%8))) & 1
+		}
+		b.WriteByte(crockford[v])
+	}
+	return b.String()
+}
+
+// Time returns the start time encoded in an ID. It understands both ULID
+// IDs and the older <type>_<unix seconds> form.
+func Time(id string) (time.Time, bool) {
+	_, suffix, found := cutLast(id)
+	if !found {
+		return time.Time{}, false
+	}
+
+	if len(suffix) == ulidLength {
+		var ms uint64
+		for _, c := range suffix[:10] {
+			v := strings.IndexRune(crockford, c)
+			if v < 0 {
+				return time.Time{}, false
+			}
+			ms = ms<<5 | uint64(v)
+		}
+		return time.UnixMilli(int64(ms)), true
+	}
+
+	if seconds, err := strconv.ParseInt(suffix, 10, 64); err == nil {
+		return time.Unix(seconds, 0), true
+	}
+	return time.Time{}, false
+}
+
+// Alias returns a readable name for an ID, <type>-<YYYYMMDD>-<HHMMSS> in
+// local time. Aliases are not guaranteed unique; two runs started in the
+// same second share one. It returns "" when the ID carries no timestamp.
+func Alias(id string) string {
+	kind, _, found := cutLast(id)
+	t, ok := Time(id)
+	if !found || !ok {
+		return ""
+	}
+	return kind + "-" + t.Local().Format("20060102-150405")
+}
+
+// cutLast splits an ID at its last underscore, so types containing
+// underscores keep them
+func cutLast(id string) (kind, suffix string, found bool) {
+	i := strings.LastIndex(id, "_")
+	if i <= 0 || i == len(id)-1 {
+		return "", "", false
+	}
+	return id[:i], id[i+1:], true
+}
@@ -0,0 +1,91 @@
+package runid
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		id   [16]byte
+		want string
+	}{
+		{"zero", [16]byte{}, "00000000000000000000000000"},
+		{"max", [16]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ"},
+		{"lowest bit", [16]byte{15: 1}, "00000000000000000000000001"},
+		{"highest bit", [16]byte{0: 0x80}, "40000000000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encode(tt.id); got != tt.want {
+				t.Errorf("encode = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAt(t *testing.T) {
+	start := time.Date(2026, 3, 14, 15, 9, 26, 535000000, time.UTC)
+	id := NewAt("scan", start)
+
+	kind, suffix, found := cutLast(id)
+	if !found || kind != "scan" || len(suffix) != ulidLength {
+		t.Fatalf("NewAt = %s, want scan_ followed by %d characters", id, ulidLength)
+	}
+	if strings.ContainsAny(suffix, "ILOU") {
+		t.Errorf("NewAt = %s uses letters outside the Crockford alphabet", id)
+	}
+	if again := NewAt("scan", start); again == id {
+		t.Errorf("two IDs for the same millisecond are equal: %s", id)
+	}
+}
+
+func TestIDsSortByStartTime(t *testing.T) {
+	start := time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+	var ids []string
+	for _, offset := range []time.Duration{0, time.Millisecond, time.Second, time.Hour, 24 * 365 * time.Hour} {
+		ids = append(ids, NewAt("quick", start.Add(offset)))
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Errorf("IDs are not in start order: %v", ids)
+	}
+}
+
+func TestTime(t *testing.T) {
+	start := time.Date(2026, 3, 14, 15, 9, 26, 535000000, time.UTC)
+	tests := []struct {
+		name   string
+		id     string
+		want   time.Time
+		wantOK bool
+	}{
+		{"ulid", NewAt("scan", start), start, true},
+		{"type with underscore", NewAt("ops_packet", start), start, true},
+		{"unix seconds", "discover_1773500966", time.Unix(1773500966, 0), true},
+		{"not a ulid", "scan_01JAB3I5Q9M2T7KZ0R4W8YV6CD", time.Time{}, false},
+		{"no suffix", "scan_", time.Time{}, false},
+		{"no type", "01JAB3X5Q9M2T7KZ0R4W8YV6CD", time.Time{}, false},
+		{"name", "scan_latest", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Time(tt.id)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Time(%s) = %v, %v; want %v, %v", tt.id, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAlias(t *testing.T) {
+	start := time.Date(2026, 3, 14, 15, 9, 26, 0, time.Local)
+	if got, want := Alias(NewAt("scan", start)), "scan-20260314-150926"; got != want {
+		t.Errorf("Alias = %s, want %s", got, want)
+	}
+	if got := Alias("scan_latest"); got != "" {
+		t.Errorf("Alias(scan_latest) = %s, want empty", got)
+	}
+}
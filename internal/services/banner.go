@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/netcrate/netcrate/internal/transport"
 )
 
 // BannerGrabber performs lightweight banner grabbing for service identification
@@ -139,7 +141,7 @@ func (bg *BannerGrabber) grabTCPBanner(host string, port int) (string, error) {
 	address := fmt.Sprintf("%s:%d", host, port)
 	
 	// Connect with timeout
-	conn, err := net.DialTimeout("tcp", address, bg.timeout)
+	conn, err := transport.DialTimeout("tcp", address, bg.timeout)
 	if err != nil {
 		return "", fmt.Errorf("connection failed: %v", err)
 	}
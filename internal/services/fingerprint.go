@@ -3,11 +3,12 @@ package services
 import (
 	"crypto/tls"
 	"fmt"
-	"net"
 	"net/http"
-	"regexp"
 	"strings"
 	"time"
+
+	"github.com/netcrate/netcrate/internal/progress"
+	"github.com/netcrate/netcrate/internal/transport"
 )
 
 // ProtocolFingerprinter performs advanced protocol identification
@@ -166,9 +167,7 @@ func (pf *ProtocolFingerprinter) probeTLS(fp *ProtocolFingerprint) bool {
 		ServerName:         fp.Host,
 	}
 	
-	conn, err := tls.DialWithDialer(&net.Dialer{
-		Timeout: pf.timeout,
-	}, "tcp", address, config)
+	conn, err := transport.DialTLS(address, pf.timeout, config)
 	
 	if err != nil {
 		return false
@@ -237,7 +236,8 @@ func (pf *ProtocolFingerprinter) probeHTTP(fp *ProtocolFingerprint) bool {
 	url := fmt.Sprintf("http://%s:%d/", fp.Host, fp.Port)
 	
 	client := &http.Client{
-		Timeout: pf.timeout,
+		Timeout:   pf.timeout,
+		Transport: transport.HTTPTransport(nil),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			// Don't follow redirects, just capture them
 			return http.ErrUseLastResponse
@@ -294,7 +294,7 @@ func (pf *ProtocolFingerprinter) probeSSH(fp *ProtocolFingerprint) bool {
 	}
 	
 	address := fmt.Sprintf("%s:%d", fp.Host, fp.Port)
-	conn, err := net.DialTimeout("tcp", address, pf.timeout)
+	conn, err := transport.DialTimeout("tcp", address, pf.timeout)
 	if err != nil {
 		return false
 	}
@@ -347,7 +347,7 @@ func (pf *ProtocolFingerprinter) probeMySQL(fp *ProtocolFingerprint) bool {
 	}
 	
 	address := fmt.Sprintf("%s:%d", fp.Host, fp.Port)
-	conn, err := net.DialTimeout("tcp", address, pf.timeout)
+	conn, err := transport.DialTimeout("tcp", address, pf.timeout)
 	if err != nil {
 		return false
 	}
@@ -396,7 +396,7 @@ func (pf *ProtocolFingerprinter) probeMySQL(fp *ProtocolFingerprint) bool {
 // probeGenericTCP performs generic TCP banner grabbing
 func (pf *ProtocolFingerprinter) probeGenericTCP(fp *ProtocolFingerprint) {
 	address := fmt.Sprintf("%s:%d", fp.Host, fp.Port)
-	conn, err := net.DialTimeout("tcp", address, pf.timeout)
+	conn, err := transport.DialTimeout("tcp", address, pf.timeout)
 	if err != nil {
 		fp.Error = err.Error()
 		return
@@ -622,10 +622,13 @@ func (pf *ProtocolFingerprinter) FingerprintMultiple(targets []Target, concurren
 	}()
 	
 	// Collect results
+	phase := progress.Start("fingerprint", len(targets))
+	defer phase.End()
 	var results []*ProtocolFingerprint
 	for i := 0; i < len(targets); i++ {
 		result := <-resultChan
 		results = append(results, result)
+		phase.Add(1)
 	}
 	
 	return results
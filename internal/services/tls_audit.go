@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/transport"
+)
+
+// TLSAudit contains the results of a TLS configuration audit
+type TLSAudit struct {
+	Host              string    `json:"host"`
+	Port              int       `json:"port"`
+	SupportedVersions []string  `json:"supported_versions"`
+	CipherSuite       string    `json:"cipher_suite,omitempty"`
+	Certificate       *CertInfo `json:"certificate,omitempty"`
+	SelfSigned        bool      `json:"self_signed"`
+	DaysUntilExpiry   int       `json:"days_until_expiry"`
+	Issues            []string  `json:"issues,omitempty"`
+}
+
+// auditVersions lists the protocol versions probed individually, oldest first
+var auditVersions = []uint16{tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13}
+
+// AuditTLS probes which TLS versions a service accepts and checks its certificate
+func AuditTLS(host string, port int, timeout time.Duration) (*TLSAudit, error) {
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	address := net.JoinHostPort(host, fmt.Sprintf("%d", port))
+	audit := &TLSAudit{Host: host, Port: port}
+	pf := &ProtocolFingerprinter{}
+
+	var newest *tls.ConnectionState
+	for _, version := range auditVersions {
+		conn, err := transport.DialTLS(address, timeout, &tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         host,
+			MinVersion:         version,
+			MaxVersion:         version,
+		})
+		if err != nil {
+			continue
+		}
+		state := conn.ConnectionState()
+		conn.Close()
+
+		audit.SupportedVersions = append(audit.SupportedVersions, pf.getTLSVersion(version))
+		newest = &state
+	}
+
+	if newest == nil {
+		return nil, fmt.Errorf("no TLS handshake succeeded on %s", address)
+	}
+
+	audit.CipherSuite = tls.CipherSuiteName(newest.CipherSuite)
+
+	for _, version := range audit.SupportedVersions {
+		if version == "TLS 1.0" || version == "TLS 1.1" {
+			audit.Issues = append(audit.Issues, fmt.Sprintf("deprecated protocol %s accepted", version))
+		}
+	}
+
+	if len(newest.PeerCertificates) > 0 {
+		cert := newest.PeerCertificates[0]
+		audit.Certificate = &CertInfo{
+			Subject:     cert.Subject.String(),
+			Issuer:      cert.Issuer.String(),
+			CommonName:  cert.Subject.CommonName,
+			SANs:        cert.DNSNames,
+			NotBefore:   cert.NotBefore,
+			NotAfter:    cert.NotAfter,
+			Fingerprint: fmt.Sprintf("%x", cert.Raw[:10]),
+		}
+
+		audit.SelfSigned = bytes.Equal(cert.RawIssuer, cert.RawSubject)
+		audit.DaysUntilExpiry = int(time.Until(cert.NotAfter).Hours() / 24)
+
+		switch {
+		case time.Now().After(cert.NotAfter):
+			audit.Issues = append(audit.Issues, "certificate expired")
+		case audit.DaysUntilExpiry < 30:
+			audit.Issues = append(audit.Issues, fmt.Sprintf("certificate expires in %d days", audit.DaysUntilExpiry))
+		}
+		if audit.SelfSigned {
+			audit.Issues = append(audit.Issues, "self-signed certificate")
+		}
+	}
+
+	return audit, nil
+}
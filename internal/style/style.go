@@ -0,0 +1,169 @@
+// Package style decides how human-readable output looks: whether the
+// dashboard uses colors and whether messages carry emoji. Output meant for
+// people goes through Printf, Fprintf and friends (or Text) so that
+// --no-emoji reaches every message from one place; machine output such as
+// JSON is written as is.
+package style
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var (
+	mu    sync.RWMutex
+	color = true
+	emoji = true
+)
+
+// Options choose the styling of human output
+type Options struct {
+	Color bool // ANSI colors in the dashboard
+	Emoji bool // emoji in messages; without them status emoji become [ok], [error] and [warn]
+}
+
+// Configure sets the styling for the rest of the process
+func Configure(opts Options) {
+	mu.Lock()
+	color, emoji = opts.Color, opts.Emoji
+	mu.Unlock()
+
+	if !opts.Color {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// NoColorEnv reports whether the NO_COLOR convention (https://no-color.org)
+// asks for output without colors: NO_COLOR is set to anything but empty
+func NoColorEnv() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// Color reports whether output may use ANSI colors
+func Color() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return color
+}
+
+// Emoji reports whether output may use emoji
+func Emoji() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return emoji
+}
+
+const (
+	variationSelector = '\uFE0F' // asks for the emoji form of the symbol before it
+	zeroWidthJoiner   = '\u200D' // joins emoji into one, as in 🧑‍💻
+)
+
+// markers replace the emoji that carry a status, so logs keep it
+var markers = map[rune]string{
+	'✅': "[ok]",
+	'❌': "[error]",
+	'⚠': "[warn]",
+	'🚨': "[alert]",
+	'🛑': "[stop]",
+}
+
+// Text returns s as it should be printed: unchanged when emoji are on,
+// otherwise with status emoji replaced by markers and other emoji removed
+// along with the spaces that followed them
+func Text(s string) string {
+	if Emoji() || !hasEmoji(s) {
+		return s
+	}
+
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if !isEmoji(runes, i) {
+			if r != variationSelector && r != zeroWidthJoiner {
+				b.WriteRune(r)
+			}
+			continue
+		}
+
+		if i+1 < len(runes) && runes[i+1] == variationSelector {
+			i++
+		}
+		if marker, ok := markers[r]; ok {
+			b.WriteString(marker)
+			continue
+		}
+
+		// Other emoji go with the padding after them
+		for i+1 < len(runes) && runes[i+1] == ' ' {
+			i++
+		}
+		if i+1 == len(runes) || runes[i+1] == '\n' {
+			trimmed := strings.TrimRight(b.String(), " ")
+			b.Reset()
+			b.WriteString(trimmed)
+		}
+	}
+	return b.String()
+}
+
+// hasEmoji is the fast path of Text for the many messages without emoji
+func hasEmoji(s string) bool {
+	runes := []rune(s)
+	for i := range runes {
+		if isEmoji(runes, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmoji reports whether runes[i] is drawn as an emoji: a pictograph, or a
+// symbol followed by the emoji variation selector. Plain symbols such as
+// arrows, bullets, ✓ and ✗ are kept.
+func isEmoji(runes []rune, i int) bool {
+	r := runes[i]
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	case markers[r] != "":
+		return true
+	case r == '⚡' || r == '✨' || r == '⏳' || r == '⌛' || r == '⏰' || r == '⭐':
+		return true
+	case i+1 < len(runes) && runes[i+1] == variationSelector:
+		return unicode.IsSymbol(r)
+	}
+	return false
+}
+
+// Printf formats like fmt.Printf and writes the styled text to stdout
+func Printf(format string, args ...interface{}) (int, error) {
+	return Fprintf(os.Stdout, format, args...)
+}
+
+// Println formats like fmt.Println and writes the styled text to stdout
+func Println(args ...interface{}) (int, error) {
+	return Fprintln(os.Stdout, args...)
+}
+
+// Fprintf formats like fmt.Fprintf and writes the styled text to w
+func Fprintf(w io.Writer, format string, args ...interface{}) (int, error) {
+	return io.WriteString(w, Text(fmt.Sprintf(format, args...)))
+}
+
+// Fprintln formats like fmt.Fprintln and writes the styled text to w
+func Fprintln(w io.Writer, args ...interface{}) (int, error) {
+	return io.WriteString(w, Text(fmt.Sprintln(args...)))
+}
+
+// Sprintf formats like fmt.Sprintf and returns the styled text
+func Sprintf(format string, args ...interface{}) string {
+	return Text(fmt.Sprintf(format, args...))
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/netcrate/netcrate/internal/logging"
 	"gopkg.in/yaml.v2"
 )
 
@@ -44,11 +45,14 @@ type TemplateStep struct {
 	Name      string                 `yaml:"name" json:"name"`
 	Operation string                 `yaml:"operation" json:"operation"`
 	With      map[string]interface{} `yaml:"with" json:"with"`
+	Requires  []string               `yaml:"requires" json:"requires,omitempty"` // raw_socket, pcap
 	DependsOn string                 `yaml:"depends_on" json:"depends_on"`
 	OnEmpty   string                 `yaml:"on_empty" json:"on_empty"`
 	OnError   string                 `yaml:"on_error" json:"on_error"` // continue, skip, fail (default)
 }
 
+var log = logging.Component("templates")
+
 // Registry manages template discovery and caching
 type Registry struct {
 	searchPaths    []string
@@ -118,7 +122,7 @@ func (r *Registry) LoadTemplates() error {
 		source := r.getSourceName(i, searchPath)
 		err := r.loadFromPath(searchPath, source)
 		if err != nil {
-			fmt.Printf("[WARN] Failed to load templates from %s: %v\n", searchPath, err)
+			log.Warn("failed to load templates", "path", searchPath, "error", err)
 		}
 	}
 	
@@ -200,14 +204,14 @@ func (r *Registry) loadFromPath(searchPath, source string) error {
 		if !info.IsDir() && (strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")) {
 			template, err := r.loadTemplate(path, source)
 			if err != nil {
-				fmt.Printf("[WARN] Failed to load template %s: %v\n", path, err)
+				log.Warn("failed to load template", "path", path, "error", err)
 				return nil // Continue walking
 			}
 			
 			// User templates override builtin ones with same name
 			if existing, exists := r.templates[template.Name]; exists {
 				if source == "user" || (source == "env" && existing.Source != "user") {
-					fmt.Printf("[INFO] Template %s: %s overrides %s\n", template.Name, source, existing.Source)
+					log.Info("template overridden", "template", template.Name, "source", source, "overrides", existing.Source)
 					r.templates[template.Name] = template
 				}
 			} else {
@@ -230,6 +234,12 @@ func (r *Registry) loadTemplate(filePath, source string) (*Template, error) {
 	if err := yaml.Unmarshal(data, &template); err != nil {
 		return nil, err
 	}
+	if err := validateReportSteps(&template); err != nil {
+		return nil, err
+	}
+	if err := validateStepRequirements(&template); err != nil {
+		return nil, err
+	}
 	
 	template.Path = filePath
 	template.Source = source
@@ -238,6 +248,37 @@ func (r *Registry) loadTemplate(filePath, source string) (*Template, error) {
 	return &template, nil
 }
 
+// SearchPaths returns the template directories in priority order
+func (r *Registry) SearchPaths() []string {
+	return r.searchPaths
+}
+
+// IndexPath returns the location of the template index cache
+func (r *Registry) IndexPath() string {
+	return r.indexPath
+}
+
+// Broken returns the template files in the search paths that fail to load,
+// keyed by path, which LoadTemplates only logs and skips
+func (r *Registry) Broken() map[string]error {
+	broken := make(map[string]error)
+	for i, searchPath := range r.searchPaths {
+		source := r.getSourceName(i, searchPath)
+		filepath.Walk(searchPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if !info.IsDir() && (strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")) {
+				if _, err := r.loadTemplate(path, source); err != nil {
+					broken[path] = err
+				}
+			}
+			return nil
+		})
+	}
+	return broken
+}
+
 // getSourceName determines the source name for a search path
 func (r *Registry) getSourceName(index int, path string) string {
 	homeDir, _ := os.UserHomeDir()
@@ -0,0 +1,67 @@
+package templates
+
+import (
+	"fmt"
+
+	"github.com/netcrate/netcrate/internal/reports"
+)
+
+// ReportOperation is the operation of the step that renders the result of
+// the steps before it into a report file:
+//
+//	steps:
+//	  - name: report
+//	    operation: report
+//	    with:
+//	      format: html        # html (default) or md
+//	      path: ~/reports/{template}-{date}.html
+//	      title: Weekly audit
+//	      theme: minimal
+//	      lang: zh-CN
+const ReportOperation = "report"
+
+// ReportStep reads the report output configured by a report step
+func ReportStep(step TemplateStep) (reports.ReportOutput, error) {
+	var out reports.ReportOutput
+	for key, value := range step.With {
+		text, ok := value.(string)
+		if !ok {
+			return out, fmt.Errorf("step %s: %s must be a string", step.Name, key)
+		}
+		switch key {
+		case "format":
+			out.Format = text
+		case "path":
+			out.Path = text
+		case "title":
+			out.Title = text
+		case "theme":
+			out.Theme = text
+		case "lang":
+			out.Language = text
+		default:
+			return out, fmt.Errorf("step %s: unknown report option %s", step.Name, key)
+		}
+	}
+	if err := out.Validate(); err != nil {
+		return out, fmt.Errorf("step %s: %w", step.Name, err)
+	}
+	return out, nil
+}
+
+// validateReportSteps checks that a template has at most one report step,
+// as its last step, since it reports on everything that ran before it
+func validateReportSteps(template *Template) error {
+	for i, step := range template.Steps {
+		if step.Operation != ReportOperation {
+			continue
+		}
+		if i != len(template.Steps)-1 {
+			return fmt.Errorf("step %s: the report step must be the last step", step.Name)
+		}
+		if _, err := ReportStep(step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/netcrate/netcrate/internal/netenv"
+)
+
+// Capabilities a step may declare under requires:
+//
+//	steps:
+//	  - name: scan_ports
+//	    operation: scan_ports
+//	    requires: [raw_socket]
+//	    with:
+//	      scan_type: syn
+const (
+	RequireRawSocket = "raw_socket"
+	RequirePcap      = "pcap"
+)
+
+// requirementCapabilities maps a step requirement to the capability of the
+// network environment that provides it
+var requirementCapabilities = map[string]string{
+	RequireRawSocket: netenv.CapRawIPv4,
+	RequirePcap:      netenv.CapPacketCapture,
+}
+
+// StepDowngrade is a step changed at plan time to run without a capability
+// it asked for
+type StepDowngrade struct {
+	Step        string `json:"step"`
+	Requirement string `json:"requirement"`
+	Change      string `json:"change"` // what the step runs instead
+	Reason      string `json:"reason,omitempty"`
+}
+
+// StepRequirements returns the capabilities a step needs: those it declares,
+// and raw_socket for a SYN scan, which cannot run without one
+func StepRequirements(step TemplateStep) []string {
+	requires := append([]string(nil), step.Requires...)
+	if isScanStep(step) && step.With["scan_type"] == "syn" && !containsString(requires, RequireRawSocket) {
+		requires = append(requires, RequireRawSocket)
+	}
+	return requires
+}
+
+// PlanRequirements checks every step's requirements against the capability
+// matrix before anything runs. A step that can do without a missing
+// capability is downgraded in place, a SYN scan to TCP connect or ICMP
+// discovery to the system ping; any other missing capability fails the plan
+// with how to gain it.
+func PlanRequirements(template *Template, matrix []netenv.Capability) ([]StepDowngrade, error) {
+	var downgrades []StepDowngrade
+	for i := range template.Steps {
+		step := &template.Steps[i]
+		for _, requirement := range StepRequirements(*step) {
+			capability := netenv.LookupCapability(matrix, requirementCapabilities[requirement])
+			if capability == nil || capability.Available {
+				continue
+			}
+			change := downgradeStep(step, requirement)
+			if change == "" {
+				message := fmt.Sprintf("step %s requires %s, which is unavailable", step.Name, requirement)
+				if capability.Error != "" {
+					message += ": " + capability.Error
+				}
+				if capability.Remedy != "" {
+					message += " (" + capability.Remedy + ")"
+				}
+				return downgrades, fmt.Errorf("%s", message)
+			}
+			downgrades = append(downgrades, StepDowngrade{
+				Step:        step.Name,
+				Requirement: requirement,
+				Change:      change,
+				Reason:      capability.Error,
+			})
+		}
+	}
+	return downgrades, nil
+}
+
+// downgradeStep rewrites a step to run without a requirement, describing
+// the change, or returns "" when the step has no way to do without it
+func downgradeStep(step *TemplateStep, requirement string) string {
+	if requirement != RequireRawSocket {
+		return ""
+	}
+	switch {
+	case isScanStep(*step):
+		if step.With["scan_type"] != "syn" {
+			return ""
+		}
+		step.With["scan_type"] = "connect"
+		return "SYN scan → TCP connect scan"
+
+	case step.Operation == "discover":
+		methods, ok := step.With["methods"].([]interface{})
+		if !ok {
+			return ""
+		}
+		var changed []string
+		for j, method := range methods {
+			if method == "icmp" {
+				methods[j] = "ping"
+				changed = append(changed, "icmp → ping")
+			}
+		}
+		if len(changed) == 0 {
+			return ""
+		}
+		return "discovery methods " + strings.Join(changed, ", ")
+	}
+	return ""
+}
+
+// validateStepRequirements checks that steps only declare known capabilities
+func validateStepRequirements(template *Template) error {
+	for _, step := range template.Steps {
+		for _, requirement := range step.Requires {
+			if _, ok := requirementCapabilities[requirement]; !ok {
+				return fmt.Errorf("step %s: unknown requirement %s (want %s or %s)", step.Name, requirement, RequireRawSocket, RequirePcap)
+			}
+		}
+	}
+	return nil
+}
+
+// isScanStep reports whether a step runs a port scan; templates spell the
+// operation both ways
+func isScanStep(step TemplateStep) bool {
+	return step.Operation == "scan_ports" || step.Operation == "scan.ports"
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
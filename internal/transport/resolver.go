@@ -0,0 +1,199 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// System is the resolver setting that keeps the system resolver
+const System = "system"
+
+// Resolver is a DNS server hostname lookups are sent to
+type Resolver struct {
+	Protocol string // "udp" (plain DNS), "tls" (DNS over TLS) or "https" (DNS over HTTPS)
+	Address  string // host:port, for udp and tls
+	URL      string // query URL, for https
+}
+
+// bootstrapDialer reaches DNS servers given by name through the system
+// resolver, since the configured one cannot resolve its own servers
+var bootstrapDialer = &net.Dialer{Timeout: 5 * time.Second, Resolver: &net.Resolver{}}
+
+// dohClient sends DNS over HTTPS queries, never through the probe proxy
+var dohClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext:       bootstrapDialer.DialContext,
+		ForceAttemptHTTP2: true,
+	},
+}
+
+// ParseResolvers parses DNS servers, tried in order:
+//
+//	1.1.1.1 or 1.1.1.1:53               plain DNS
+//	tls://1.1.1.1 or tls://dns.quad9.net  DNS over TLS (port 853)
+//	https://dns.google/dns-query          DNS over HTTPS
+//
+// A single System entry, like an empty list, keeps the system resolver.
+func ParseResolvers(entries []string) ([]Resolver, error) {
+	if len(entries) == 1 && entries[0] == System {
+		return nil, nil
+	}
+	var resolvers []Resolver
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+			continue
+		case strings.HasPrefix(entry, "https://"):
+			u, err := url.Parse(entry)
+			if err != nil || u.Host == "" {
+				return nil, fmt.Errorf("invalid DNS over HTTPS resolver %q", entry)
+			}
+			resolvers = append(resolvers, Resolver{Protocol: "https", URL: entry})
+		case strings.HasPrefix(entry, "tls://"):
+			address := withPort(strings.TrimPrefix(entry, "tls://"), "853")
+			if host, _, err := net.SplitHostPort(address); err != nil || host == "" {
+				return nil, fmt.Errorf("invalid DNS over TLS resolver %q", entry)
+			}
+			resolvers = append(resolvers, Resolver{Protocol: "tls", Address: address})
+		case strings.Contains(entry, "://"):
+			return nil, fmt.Errorf("unsupported resolver %q (expected an IP, tls://host or https://url)", entry)
+		default:
+			address := withPort(entry, "53")
+			host, _, err := net.SplitHostPort(address)
+			if err != nil || net.ParseIP(host) == nil {
+				return nil, fmt.Errorf("invalid resolver %q (plain DNS servers are given by IP)", entry)
+			}
+			resolvers = append(resolvers, Resolver{Protocol: "udp", Address: address})
+		}
+	}
+	return resolvers, nil
+}
+
+// withPort adds port to an address that has none
+func withPort(address, port string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	return net.JoinHostPort(strings.Trim(address, "[]"), port)
+}
+
+// installResolvers makes every hostname lookup of the process use
+// resolvers, or the system resolver when there are none
+func installResolvers(resolvers []Resolver) {
+	mu.Lock()
+	defer mu.Unlock()
+	if len(resolvers) == 0 {
+		net.DefaultResolver.PreferGo = false
+		net.DefaultResolver.Dial = nil
+		return
+	}
+	net.DefaultResolver.PreferGo = true
+	net.DefaultResolver.Dial = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var lastErr error
+		for _, r := range resolvers {
+			conn, err := r.dial(ctx, network)
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+}
+
+// dial opens a connection the Go resolver sends one query over. DoT and DoH
+// connections are streams, so queries carry the TCP length prefix.
+func (r Resolver) dial(ctx context.Context, network string) (net.Conn, error) {
+	switch r.Protocol {
+	case "tls":
+		host, _, _ := net.SplitHostPort(r.Address)
+		dialer := &tls.Dialer{NetDialer: bootstrapDialer, Config: &tls.Config{ServerName: host}}
+		return dialer.DialContext(ctx, "tcp", r.Address)
+	case "https":
+		return &dohConn{ctx: ctx, url: r.URL}, nil
+	}
+	return bootstrapDialer.DialContext(ctx, network, r.Address)
+}
+
+// dohConn carries a length-prefixed DNS query over HTTPS (RFC 8484)
+type dohConn struct {
+	ctx      context.Context
+	url      string
+	query    bytes.Buffer
+	answer   bytes.Buffer
+	deadline time.Time
+}
+
+func (c *dohConn) Write(b []byte) (int, error) {
+	return c.query.Write(b)
+}
+
+func (c *dohConn) Read(b []byte) (int, error) {
+	if c.answer.Len() == 0 {
+		if err := c.exchange(); err != nil {
+			return 0, err
+		}
+	}
+	return c.answer.Read(b)
+}
+
+// exchange posts the buffered query and buffers the length-prefixed answer
+func (c *dohConn) exchange() error {
+	if c.query.Len() < 2 {
+		return io.EOF
+	}
+	query := append([]byte(nil), c.query.Bytes()[2:]...)
+	c.query.Reset()
+
+	ctx := c.ctx
+	if !c.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, c.deadline)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(query))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := dohClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("DNS over HTTPS server %s: %s", c.url, resp.Status)
+	}
+	answer, err := io.ReadAll(io.LimitReader(resp.Body, 65535))
+	if err != nil {
+		return err
+	}
+	binary.Write(&c.answer, binary.BigEndian, uint16(len(answer)))
+	c.answer.Write(answer)
+	return nil
+}
+
+func (c *dohConn) Close() error                       { return nil }
+func (c *dohConn) LocalAddr() net.Addr                { return dohAddr(c.url) }
+func (c *dohConn) RemoteAddr() net.Addr               { return dohAddr(c.url) }
+func (c *dohConn) SetDeadline(t time.Time) error      { c.deadline = t; return nil }
+func (c *dohConn) SetReadDeadline(t time.Time) error  { c.deadline = t; return nil }
+func (c *dohConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type dohAddr string
+
+func (a dohAddr) Network() string { return "https" }
+func (a dohAddr) String() string  { return string(a) }
@@ -0,0 +1,258 @@
+// Package transport holds the outbound proxy and DNS settings used by
+// application probes (packet templates, service fingerprinting) and hostname
+// resolution. Host discovery and port scans always connect directly.
+package transport
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// Direct is the proxy setting that disables proxying, including through
+// $HTTP_PROXY and $HTTPS_PROXY
+const Direct = "direct"
+
+// Settings configures outbound connections
+type Settings struct {
+	Proxy     string   // http://, https:// or socks5:// URL, Direct, or empty to honour $HTTP(S)_PROXY for HTTP probes
+	NoProxy   []string // hosts, domain suffixes, IPs or CIDRs reached without the proxy
+	Resolvers []string // DNS servers (see ParseResolvers); empty for the system resolver
+}
+
+var (
+	mu       sync.RWMutex
+	proxyURL *url.URL
+	direct   bool
+	noProxy  []string
+)
+
+// Configure applies settings to every later probe and lookup
+func Configure(settings Settings) error {
+	u, err := ParseProxy(settings.Proxy)
+	if err != nil {
+		return err
+	}
+	resolvers, err := ParseResolvers(settings.Resolvers)
+	if err != nil {
+		return err
+	}
+	for _, entry := range settings.NoProxy {
+		if strings.TrimSpace(entry) == "" {
+			return fmt.Errorf("empty no_proxy entry")
+		}
+	}
+
+	mu.Lock()
+	proxyURL = u
+	direct = settings.Proxy == Direct
+	noProxy = settings.NoProxy
+	mu.Unlock()
+
+	installResolvers(resolvers)
+	return nil
+}
+
+// ParseProxy parses a proxy URL. Empty and Direct return nil.
+func ParseProxy(raw string) (*url.URL, error) {
+	if raw == "" || raw == Direct {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q (expected e.g. http://proxy:3128 or socks5://127.0.0.1:1080)", raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (expected http, https or socks5)", u.Scheme)
+	}
+	return u, nil
+}
+
+// ProxyFunc chooses the proxy of an HTTP probe, for http.Transport.Proxy
+func ProxyFunc(req *http.Request) (*url.URL, error) {
+	mu.RLock()
+	u, isDirect := proxyURL, direct
+	mu.RUnlock()
+	switch {
+	case isDirect:
+		return nil, nil
+	case u == nil:
+		return http.ProxyFromEnvironment(req)
+	case bypass(req.URL.Hostname()):
+		return nil, nil
+	}
+	return u, nil
+}
+
+// HTTPTransport returns a transport for HTTP probes that uses the proxy
+// settings. tlsConfig may be nil.
+func HTTPTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy:               ProxyFunc,
+		DialContext:         (&net.Dialer{Timeout: 30 * time.Second}).DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+}
+
+// DialTimeout connects to address like net.DialTimeout, going through the
+// configured proxy for TCP unless the host is exempt. Proxies taken from
+// the environment only apply to HTTP probes.
+func DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	mu.RLock()
+	u := proxyURL
+	mu.RUnlock()
+
+	host, _, _ := net.SplitHostPort(address)
+	if u == nil || !strings.HasPrefix(network, "tcp") || bypass(host) {
+		return net.DialTimeout(network, address, timeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if u.Scheme == "socks5" || u.Scheme == "socks5h" {
+		dialer, err := proxy.FromURL(u, &net.Dialer{Timeout: timeout})
+		if err != nil {
+			return nil, err
+		}
+		if contextDialer, ok := dialer.(proxy.ContextDialer); ok {
+			return contextDialer.DialContext(ctx, network, address)
+		}
+		return dialer.Dial(network, address)
+	}
+	return dialConnect(ctx, u, address, timeout)
+}
+
+// DialTLS connects to address with DialTimeout and completes a TLS
+// handshake. The server name defaults to the host of address.
+func DialTLS(address string, timeout time.Duration, config *tls.Config) (*tls.Conn, error) {
+	conn, err := DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if config.ServerName == "" {
+		config = config.Clone()
+		config.ServerName, _, _ = net.SplitHostPort(address)
+	}
+	tlsConn := tls.Client(conn, config)
+	tlsConn.SetDeadline(time.Now().Add(timeout))
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+// dialConnect opens a tunnel to address through an HTTP(S) proxy
+func dialConnect(ctx context.Context, u *url.URL, address string, timeout time.Duration) (net.Conn, error) {
+	proxyAddress := u.Host
+	if u.Port() == "" {
+		if u.Scheme == "https" {
+			proxyAddress = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			proxyAddress = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: timeout}
+	if u.Scheme == "https" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: u.Hostname()}}).DialContext(ctx, "tcp", proxyAddress)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", proxyAddress)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("proxy %s: %w", proxyAddress, err)
+	}
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %w", proxyAddress, err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %w", proxyAddress, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused %s: %s", proxyAddress, address, resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+
+	// Servers that speak first (SSH, SMTP) may already have been read
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a connection whose first bytes were read into reader
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// bypass reports whether host is reached without the proxy. Callers hold
+// no lock.
+func bypass(host string) bool {
+	mu.RLock()
+	entries := noProxy
+	mu.RUnlock()
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip := net.ParseIP(host)
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "*":
+			return true
+		case strings.Contains(entry, "/"):
+			if _, ipnet, err := net.ParseCIDR(entry); err == nil && ip != nil && ipnet.Contains(ip) {
+				return true
+			}
+		case ip != nil:
+			if entryIP := net.ParseIP(entry); entryIP != nil && entryIP.Equal(ip) {
+				return true
+			}
+		default:
+			domain := strings.TrimPrefix(entry, ".")
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/services"
+)
+
+const (
+	// fingerprintConcurrency bounds the ports fingerprinted at once
+	fingerprintConcurrency = 10
+	// maxPort is the last port a deep scan covers
+	maxPort = 65535
+)
+
+// Messages from a running job to the model
+type (
+	discoverResultMsg ops.DiscoverResult
+	scanResultMsg     ops.ScanResult
+	fingerprintMsg    services.ProtocolFingerprint
+
+	// phaseMsg starts a new phase of the job; total is the number of probes
+	// it sends, or 0 when that is not known up front
+	phaseMsg struct {
+		name  string
+		total int
+	}
+
+	// noticeMsg is a line for the status bar, such as a saved run
+	noticeMsg string
+
+	// jobDoneMsg ends a job
+	jobDoneMsg struct {
+		err error
+	}
+)
+
+// job runs an operation in the background and streams its progress. The
+// model waits for one message at a time with next.
+type job struct {
+	events chan tea.Msg
+}
+
+func startJob(run func(send func(tea.Msg)) error) *job {
+	j := &job{events: make(chan tea.Msg, 256)}
+	go func() {
+		err := run(func(msg tea.Msg) { j.events <- msg })
+		j.events <- jobDoneMsg{err: err}
+		close(j.events)
+	}()
+	return j
+}
+
+// next waits for the job's next message
+func (j *job) next() tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-j.events
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// sweep discovers the live hosts among targets, then scans their ports
+func sweep(opts Options, iface string, targets []string, auth Authorization, send func(tea.Msg)) error {
+	send(phaseMsg{name: "discovery"})
+	options := ops.DiscoverOptions{
+		Targets:     targets,
+		Interface:   iface,
+		Rate:        opts.Rate,
+		Timeout:     opts.Timeout,
+		Concurrency: opts.Concurrency,
+		Exclude:     opts.Exclude,
+		Scope:       opts.Scope,
+		Classify:    auth.Classify,
+		OnResult:    func(result ops.DiscoverResult) { send(discoverResultMsg(result)) },
+	}
+	discovered, err := ops.Discover(options)
+	if err != nil {
+		return err
+	}
+	if opts.SaveDiscover != nil {
+		saveRun(discovered.RunID, func() error { return opts.SaveDiscover(discovered, options) }, send)
+	}
+
+	var live []string
+	for _, result := range discovered.Results {
+		if result.Status == "up" {
+			live = append(live, result.Host)
+		}
+	}
+	if len(live) == 0 {
+		return nil
+	}
+	_, err = scan(opts, live, opts.Ports, auth, send)
+	return err
+}
+
+// deepScan scans every port of a host, then fingerprints the open ones
+func deepScan(opts Options, host string, auth Authorization, send func(tea.Msg)) error {
+	ports, _ := ops.ParsePortSpec(fmt.Sprintf("1-%d", maxPort))
+	summary, err := scan(opts, []string{host}, ports, auth, send)
+	if err != nil {
+		return err
+	}
+	var open []int
+	for _, result := range summary.Results {
+		if result.Status == "open" {
+			open = append(open, result.Port)
+		}
+	}
+	return fingerprint(host, open, send)
+}
+
+// scan scans ports on hosts, streaming each result
+func scan(opts Options, hosts []string, ports []int, auth Authorization, send func(tea.Msg)) (*ops.ScanSummary, error) {
+	send(phaseMsg{name: "port scan", total: len(hosts) * len(ports)})
+	options := ops.ScanOptions{
+		Targets:          hosts,
+		Ports:            ports,
+		ScanType:         opts.ScanType,
+		ServiceDetection: true,
+		Rate:             opts.Rate,
+		Timeout:          opts.Timeout,
+		Concurrency:      opts.Concurrency,
+		Exclude:          opts.Exclude,
+		Scope:            opts.Scope,
+		Classify:         auth.Classify,
+		OnResult:         func(result ops.ScanResult) { send(scanResultMsg(result)) },
+	}
+	summary, err := ops.ScanPorts(options)
+	if err != nil {
+		return nil, err
+	}
+	if opts.SaveScan != nil {
+		saveRun(summary.RunID, func() error { return opts.SaveScan(summary, options) }, send)
+	}
+	for _, fallback := range summary.Fallbacks {
+		send(noticeMsg("⚠️  " + fallback))
+	}
+	return summary, nil
+}
+
+// fingerprint identifies the services on the given open ports of a host
+func fingerprint(host string, ports []int, send func(tea.Msg)) error {
+	send(phaseMsg{name: "fingerprinting", total: len(ports)})
+	fingerprinter := services.NewProtocolFingerprinter(services.FingerprintConfig{
+		Timeout: 3 * time.Second,
+	})
+
+	sem := make(chan struct{}, fingerprintConcurrency)
+	var wg sync.WaitGroup
+	for _, port := range ports {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(port int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if fp := fingerprinter.FingerprintProtocol(host, port); fp != nil {
+				send(fingerprintMsg(*fp))
+			}
+		}(port)
+	}
+	wg.Wait()
+	return nil
+}
+
+// saveRun stores a finished run and reports where it went
+func saveRun(runID string, save func() error, send func(tea.Msg)) {
+	if err := save(); err != nil {
+		send(noticeMsg(fmt.Sprintf("⚠️  Failed to save run %s: %v", runID, err)))
+		return
+	}
+	send(noticeMsg("💾 Saved run " + runID))
+}
@@ -0,0 +1,424 @@
+// Package tui is the interactive dashboard of netcrate tui: pick an
+// interface and targets, watch discovery and the port scan as results come
+// in, browse the hosts found and run follow-up actions on one of them.
+package tui
+
+import (
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/netcrate/netcrate/internal/netenv"
+	"github.com/netcrate/netcrate/internal/ops"
+	"github.com/netcrate/netcrate/internal/services"
+)
+
+// Options configure a session. The hooks keep the dashboard free of the
+// command line: the caller applies its rate profile, compliance policy and
+// run storage through them.
+type Options struct {
+	Interfaces  []netenv.NetworkInterface
+	Recommended string // interface selected at start
+	Ports       []int  // ports scanned on every live host
+	ScanType    string
+	Rate        int
+	Concurrency int
+	Timeout     time.Duration
+	Exclude     []string // IPs or CIDRs never probed
+	Scope       []string // IPs or CIDRs targets must lie in; empty allows all
+
+	// Authorize checks the targets of an action, which probes up to ports
+	// ports on each, against the compliance policy. It runs with the
+	// terminal handed back, so it may prompt.
+	Authorize func(command string, targets []string, ports int) (Authorization, error)
+	// SaveDiscover and SaveScan, if set, store finished runs with the
+	// options they ran with
+	SaveDiscover func(summary *ops.DiscoverSummary, options ops.DiscoverOptions) error
+	SaveScan     func(summary *ops.ScanSummary, options ops.ScanOptions) error
+}
+
+// Authorization is the outcome of a compliance check that let an action run
+type Authorization struct {
+	Warnings []string
+	Classify func(host string) *ops.ComplianceLabel // labels results, may be nil
+}
+
+// Run shows the dashboard until the user quits
+func Run(opts Options) error {
+	_, err := tea.NewProgram(newModel(opts), tea.WithAltScreen()).Run()
+	return err
+}
+
+// Screens of the dashboard
+const (
+	screenSetup = iota
+	screenRun
+	screenResults
+)
+
+// Actions that send traffic, each authorized before it starts
+const (
+	actionSweep       = "sweep"
+	actionFingerprint = "fingerprint"
+	actionDeepScan    = "deep scan"
+)
+
+// host is a live host and what the actions found on it
+type host struct {
+	address      string
+	hostname     string
+	rtt          float64
+	method       string
+	open         map[int]ops.ScanResult
+	fingerprints map[int]services.ProtocolFingerprint
+}
+
+// openPorts returns the host's open ports in order
+func (h *host) openPorts() []int {
+	ports := make([]int, 0, len(h.open))
+	for port := range h.open {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+type model struct {
+	opts          Options
+	screen        int
+	width, height int
+
+	// Setup
+	iface        int
+	target       []rune
+	targetEdited bool
+
+	// The running action
+	job      *job
+	action   string
+	phase    string
+	done     int
+	total    int
+	probed   int
+	started  time.Time
+	spinner  int
+	targets  []string
+	selected int
+
+	hosts     []*host
+	byAddress map[string]*host
+
+	status  string   // outcome of the last action, or what is wrong with the input
+	notices []string // warnings and saved runs of the last action
+}
+
+func newModel(opts Options) *model {
+	m := &model{opts: opts, byAddress: make(map[string]*host)}
+	for i, iface := range opts.Interfaces {
+		if iface.Name == opts.Recommended {
+			m.iface = i
+		}
+	}
+	m.target = []rune(m.defaultTarget())
+	return m
+}
+
+// defaultTarget is the network of the selected interface
+func (m *model) defaultTarget() string {
+	if m.iface >= len(m.opts.Interfaces) {
+		return ""
+	}
+	network, err := netenv.InferNetworkRange(m.opts.Interfaces[m.iface])
+	if err != nil {
+		return ""
+	}
+	return network.String()
+}
+
+// interfaceName is the name of the selected interface, or "" for none
+func (m *model) interfaceName() string {
+	if m.iface >= len(m.opts.Interfaces) {
+		return ""
+	}
+	return m.opts.Interfaces[m.iface].Name
+}
+
+// tickMsg animates the spinner while an action runs
+type tickMsg time.Time
+
+func tick() tea.Cmd {
+	return tea.Tick(120*time.Millisecond, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// authorizedMsg carries the outcome of the compliance check of an action
+type authorizedMsg struct {
+	action  string
+	targets []string
+	auth    Authorization
+	err     error
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case tickMsg:
+		if m.job == nil {
+			return m, nil
+		}
+		m.spinner++
+		return m, tick()
+
+	case authorizedMsg:
+		if msg.err != nil {
+			m.status = "❌ " + msg.err.Error()
+			return m, nil
+		}
+		return m, m.start(msg.action, msg.targets, msg.auth)
+
+	case phaseMsg:
+		m.phase, m.total, m.done = msg.name, msg.total, 0
+		return m, m.job.next()
+
+	case discoverResultMsg:
+		m.probed++
+		if msg.Status == "up" {
+			h := m.hostFor(msg.Host)
+			h.hostname, h.rtt, h.method = msg.Hostname, msg.RTT, msg.Method
+		}
+		return m, m.job.next()
+
+	case scanResultMsg:
+		m.done++
+		if msg.Status == "open" {
+			m.hostFor(msg.Host).open[msg.Port] = ops.ScanResult(msg)
+		}
+		return m, m.job.next()
+
+	case fingerprintMsg:
+		m.done++
+		if h := m.byAddress[msg.Host]; h != nil {
+			h.fingerprints[msg.Port] = services.ProtocolFingerprint(msg)
+		}
+		return m, m.job.next()
+
+	case noticeMsg:
+		m.notices = append(m.notices, string(msg))
+		return m, m.job.next()
+
+	case jobDoneMsg:
+		m.job = nil
+		if msg.err != nil {
+			m.status = "❌ " + m.action + " failed: " + msg.err.Error()
+		} else {
+			m.status = "✅ " + m.action + " finished in " + time.Since(m.started).Round(time.Second).String()
+		}
+		m.screen = screenResults
+		return m, nil
+	}
+	return m, nil
+}
+
+// hostFor returns the entry of a live host, adding it in address order
+func (m *model) hostFor(address string) *host {
+	if h, ok := m.byAddress[address]; ok {
+		return h
+	}
+	h := &host{
+		address:      address,
+		open:         make(map[int]ops.ScanResult),
+		fingerprints: make(map[int]services.ProtocolFingerprint),
+	}
+	m.byAddress[address] = h
+	m.hosts = append(m.hosts, h)
+	sort.SliceStable(m.hosts, func(i, j int) bool {
+		return lessAddress(m.hosts[i].address, m.hosts[j].address)
+	})
+	return h
+}
+
+func (m *model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	switch m.screen {
+	case screenSetup:
+		return m.handleSetupKey(msg)
+	case screenRun:
+		if msg.String() == "q" {
+			return m, tea.Quit
+		}
+		return m, nil
+	default:
+		return m.handleResultsKey(msg)
+	}
+}
+
+func (m *model) handleSetupKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		if len(m.hosts) > 0 {
+			m.screen = screenResults
+			return m, nil
+		}
+		return m, tea.Quit
+	case tea.KeyUp, tea.KeyDown:
+		if msg.Type == tea.KeyUp && m.iface > 0 {
+			m.iface--
+		}
+		if msg.Type == tea.KeyDown && m.iface < len(m.opts.Interfaces)-1 {
+			m.iface++
+		}
+		if !m.targetEdited {
+			m.target = []rune(m.defaultTarget())
+		}
+	case tea.KeyBackspace:
+		if len(m.target) > 0 {
+			m.target = m.target[:len(m.target)-1]
+			m.targetEdited = true
+		}
+	case tea.KeyCtrlU:
+		m.target, m.targetEdited = nil, true
+	case tea.KeyRunes, tea.KeySpace:
+		m.target = append(m.target, msg.Runes...)
+		m.targetEdited = true
+	case tea.KeyEnter:
+		targets := splitTargets(string(m.target))
+		if len(targets) == 0 {
+			m.status = "Enter a target: an address, a range or a CIDR"
+			return m, nil
+		}
+		return m, m.authorize(actionSweep, targets, len(m.opts.Ports))
+	}
+	return m, nil
+}
+
+func (m *model) handleResultsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		return m, tea.Quit
+	case "up", "k":
+		if m.selected > 0 {
+			m.selected--
+		}
+	case "down", "j":
+		if m.selected < len(m.hosts)-1 {
+			m.selected++
+		}
+	case "n":
+		m.screen, m.status = screenSetup, ""
+	case "f":
+		h := m.selectedHost()
+		if h == nil {
+			return m, nil
+		}
+		if len(h.open) == 0 {
+			m.status = "No open ports on " + h.address + " to fingerprint"
+			return m, nil
+		}
+		return m, m.authorize(actionFingerprint, []string{h.address}, len(h.open))
+	case "d":
+		if h := m.selectedHost(); h != nil {
+			return m, m.authorize(actionDeepScan, []string{h.address}, maxPort)
+		}
+	}
+	return m, nil
+}
+
+func (m *model) selectedHost() *host {
+	if m.selected < len(m.hosts) {
+		return m.hosts[m.selected]
+	}
+	return nil
+}
+
+// authorize runs the compliance check of an action with the terminal
+// released, as it may ask for a typed confirmation
+func (m *model) authorize(action string, targets []string, ports int) tea.Cmd {
+	if m.opts.Authorize == nil {
+		return func() tea.Msg { return authorizedMsg{action: action, targets: targets} }
+	}
+	check := &authorizeCommand{run: func() (Authorization, error) {
+		return m.opts.Authorize("netcrate tui "+action, targets, ports)
+	}}
+	return tea.Exec(check, func(err error) tea.Msg {
+		if err == nil {
+			err = check.err
+		}
+		return authorizedMsg{action: action, targets: targets, auth: check.auth, err: err}
+	})
+}
+
+// authorizeCommand adapts a compliance check to tea.Exec
+type authorizeCommand struct {
+	run  func() (Authorization, error)
+	auth Authorization
+	err  error
+}
+
+func (c *authorizeCommand) Run() error {
+	c.auth, c.err = c.run()
+	return nil
+}
+
+func (c *authorizeCommand) SetStdin(io.Reader)  {}
+func (c *authorizeCommand) SetStdout(io.Writer) {}
+func (c *authorizeCommand) SetStderr(io.Writer) {}
+
+// start runs an authorized action
+func (m *model) start(action string, targets []string, auth Authorization) tea.Cmd {
+	m.action, m.phase, m.done, m.total, m.probed = action, "", 0, 0, 0
+	m.started = time.Now()
+	m.screen, m.status = screenRun, ""
+	m.notices = nil
+	for _, warning := range auth.Warnings {
+		m.notices = append(m.notices, "⚠️  "+warning)
+	}
+
+	switch action {
+	case actionSweep:
+		m.hosts, m.byAddress, m.selected = nil, make(map[string]*host), 0
+		m.targets = targets
+		iface := m.interfaceName()
+		m.job = startJob(func(send func(tea.Msg)) error {
+			return sweep(m.opts, iface, targets, auth, send)
+		})
+	case actionFingerprint:
+		h := m.byAddress[targets[0]]
+		ports := h.openPorts()
+		m.job = startJob(func(send func(tea.Msg)) error {
+			return fingerprint(h.address, ports, send)
+		})
+	case actionDeepScan:
+		m.job = startJob(func(send func(tea.Msg)) error {
+			return deepScan(m.opts, targets[0], auth, send)
+		})
+	}
+	return tea.Batch(m.job.next(), tick())
+}
+
+// splitTargets splits the target field on commas and spaces
+func splitTargets(field string) []string {
+	return strings.FieldsFunc(field, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// lessAddress orders IP addresses numerically and anything else by text
+func lessAddress(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a < b
+	}
+	return string(ipA.To16()) < string(ipB.To16())
+}
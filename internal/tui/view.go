@@ -0,0 +1,283 @@
+package tui
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/netcrate/netcrate/internal/netenv"
+	"github.com/netcrate/netcrate/internal/style"
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15")).Background(lipgloss.Color("25")).Padding(0, 1)
+	headingStyle  = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	dimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	openStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	panelStyle    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240")).Padding(0, 1)
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const (
+	// chrome is the number of lines around the lists: title, headings,
+	// status and help
+	chrome = 9
+	// listWidth is the width of the host list beside the host details
+	listWidth = 32
+)
+
+func (m *model) View() string {
+	var body string
+	switch m.screen {
+	case screenSetup:
+		body = m.setupView()
+	case screenRun:
+		body = m.runView()
+	default:
+		body = m.resultsView()
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("NetCrate"))
+	b.WriteString("\n\n")
+	b.WriteString(body)
+	b.WriteString("\n")
+	if m.status != "" {
+		b.WriteString(style.Text(m.status) + "\n")
+	}
+	for _, notice := range m.notices {
+		b.WriteString(dimStyle.Render(style.Text(notice)) + "\n")
+	}
+	b.WriteString("\n" + dimStyle.Render(m.help()))
+	return b.String()
+}
+
+func (m *model) help() string {
+	switch m.screen {
+	case screenSetup:
+		back := "esc quit"
+		if len(m.hosts) > 0 {
+			back = "esc back to results"
+		}
+		return "↑/↓ interface · type the targets · ctrl+u clear · enter start · " + back
+	case screenRun:
+		return "q quit"
+	default:
+		return "↑/↓ host · f fingerprint · d deep scan (all ports) · n new sweep · q quit"
+	}
+}
+
+func (m *model) setupView() string {
+	var b strings.Builder
+	b.WriteString(headingStyle.Render("Interface") + "\n")
+	if len(m.opts.Interfaces) == 0 {
+		b.WriteString(dimStyle.Render("  no active interfaces") + "\n")
+	}
+	for i, iface := range m.opts.Interfaces {
+		line := fmt.Sprintf("%-16s %-9s %s", iface.Name, iface.Type, interfaceAddress(iface.Addresses))
+		if iface.Overlay != "" {
+			line += " (" + iface.Overlay + ")"
+		}
+		if iface.Name == m.opts.Recommended {
+			line += " ★"
+		}
+		if i == m.iface {
+			b.WriteString(selectedStyle.Render("▸ "+line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	b.WriteString("\n" + headingStyle.Render("Targets") + "\n")
+	b.WriteString("  " + string(m.target) + selectedStyle.Render("█") + "\n\n")
+	b.WriteString(dimStyle.Render(fmt.Sprintf("Discovers live hosts, then scans %d ports on each at %d pps", len(m.opts.Ports), m.opts.Rate)) + "\n")
+	return b.String()
+}
+
+func (m *model) runView() string {
+	var b strings.Builder
+	frame := spinnerFrames[m.spinner%len(spinnerFrames)]
+	elapsed := time.Since(m.started).Round(time.Second)
+
+	line := fmt.Sprintf("%s %s", frame, m.action)
+	if m.phase != "" && m.phase != m.action {
+		line += ": " + m.phase
+	}
+	b.WriteString(headingStyle.Render(line) + dimStyle.Render(" · "+elapsed.String()) + "\n")
+	if m.total > 0 {
+		b.WriteString(progressBar(m.done, m.total, 40) + fmt.Sprintf(" %d/%d", m.done, m.total) + "\n")
+	} else if m.probed > 0 {
+		b.WriteString(fmt.Sprintf("probed %d addresses\n", m.probed))
+	}
+	b.WriteString(fmt.Sprintf("%d hosts up, %d open ports\n\n", len(m.hosts), m.openCount()))
+
+	rows := m.listHeight()
+	start := 0
+	if len(m.hosts) > rows {
+		// Follow the newest hosts while results come in
+		start = len(m.hosts) - rows
+	}
+	for _, h := range m.hosts[start:] {
+		b.WriteString(fmt.Sprintf("  %-16s %s\n", h.address, openStyle.Render(formatPorts(h.openPorts()))))
+	}
+	return b.String()
+}
+
+func (m *model) resultsView() string {
+	summary := fmt.Sprintf("%d hosts up, %d open ports", len(m.hosts), m.openCount())
+	if len(m.targets) > 0 {
+		summary = strings.Join(m.targets, ", ") + " · " + summary
+	}
+	if len(m.hosts) == 0 {
+		return summary + "\n\n" + dimStyle.Render("No live hosts. Press n to sweep other targets.") + "\n"
+	}
+
+	rows := m.listHeight()
+	start := 0
+	if m.selected >= rows {
+		start = m.selected - rows + 1
+	}
+	var list strings.Builder
+	for i := start; i < len(m.hosts) && i < start+rows; i++ {
+		h := m.hosts[i]
+		line := fmt.Sprintf("%-16s %3d open", h.address, len(h.open))
+		if i == m.selected {
+			list.WriteString(selectedStyle.Render("▸ "+line) + "\n")
+		} else {
+			list.WriteString("  " + line + "\n")
+		}
+	}
+
+	detailWidth := 60
+	if m.width > 0 {
+		detailWidth = m.width - listWidth - 4
+		if detailWidth < 30 {
+			detailWidth = 30
+		}
+	}
+	left := panelStyle.Width(listWidth).Render(strings.TrimRight(list.String(), "\n"))
+	right := panelStyle.Width(detailWidth).Render(m.hostDetail(m.selectedHost()))
+	return summary + "\n" + lipgloss.JoinHorizontal(lipgloss.Top, left, right) + "\n"
+}
+
+// hostDetail describes a host and its open ports with what identified them
+func (m *model) hostDetail(h *host) string {
+	var b strings.Builder
+	b.WriteString(headingStyle.Render(h.address))
+	if h.hostname != "" {
+		b.WriteString(" (" + h.hostname + ")")
+	}
+	b.WriteString("\n")
+	if h.method != "" {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("found by %s in %.1fms", h.method, h.rtt)) + "\n")
+	}
+	b.WriteString("\n")
+
+	ports := h.openPorts()
+	if len(ports) == 0 {
+		b.WriteString(dimStyle.Render("No open ports among those scanned; d scans all of them."))
+		return b.String()
+	}
+	b.WriteString(headingStyle.Render(fmt.Sprintf("%-7s %-12s %s", "PORT", "SERVICE", "DETAIL")) + "\n")
+	for _, port := range ports {
+		service, detail := "", ""
+		if info := h.open[port].Service; info != nil {
+			service = info.Name
+			detail = strings.TrimSpace(info.Version)
+			if detail == "" {
+				detail = firstLine(info.Banner)
+			}
+		}
+		if fp, ok := h.fingerprints[port]; ok {
+			if fp.Service != "" && fp.Service != "unknown" {
+				service = fp.Service
+			}
+			if described := strings.TrimSpace(fp.Application + " " + fp.Version); described != "" {
+				detail = described
+			}
+			if fp.TLS != nil {
+				detail = strings.TrimSpace(detail + " [TLS]")
+			}
+			if detail == "" && fp.Error != "" {
+				detail = dimStyle.Render("fingerprint: " + fp.Error)
+			}
+		}
+		b.WriteString(fmt.Sprintf("%-7s %-12s %s\n", fmt.Sprintf("%d", port), truncate(service, 12), detail))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// listHeight is how many hosts fit on the screen
+func (m *model) listHeight() int {
+	rows := m.height - chrome - len(m.notices)
+	if m.height == 0 || rows > 200 {
+		rows = 200
+	}
+	if rows < 3 {
+		rows = 3
+	}
+	return rows
+}
+
+func (m *model) openCount() int {
+	count := 0
+	for _, h := range m.hosts {
+		count += len(h.open)
+	}
+	return count
+}
+
+// interfaceAddress returns the first IPv4 address of an interface with its
+// prefix length
+func interfaceAddress(addresses []netenv.Address) string {
+	for _, address := range addresses {
+		if ip := net.ParseIP(address.IP); ip != nil && ip.To4() != nil {
+			if mask := net.ParseIP(address.Netmask); mask != nil {
+				ones, _ := net.IPMask(mask.To4()).Size()
+				return fmt.Sprintf("%s/%d", address.IP, ones)
+			}
+			return address.IP
+		}
+	}
+	return ""
+}
+
+func progressBar(done, total, width int) string {
+	filled := width * done / total
+	if filled > width {
+		filled = width
+	}
+	return openStyle.Render(strings.Repeat("█", filled)) + dimStyle.Render(strings.Repeat("░", width-filled))
+}
+
+// formatPorts lists ports, shortened when there are many
+func formatPorts(ports []int) string {
+	const shown = 12
+	parts := make([]string, 0, shown)
+	for i, port := range ports {
+		if i == shown {
+			parts = append(parts, fmt.Sprintf("+%d", len(ports)-shown))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%d", port))
+	}
+	return strings.Join(parts, " ")
+}
+
+func firstLine(text string) string {
+	if i := strings.IndexAny(text, "\r\n"); i >= 0 {
+		text = text[:i]
+	}
+	return truncate(strings.TrimSpace(text), 40)
+}
+
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	return text[:max-1] + "…"
+}
@@ -0,0 +1,339 @@
+// Package update finds, verifies and installs the NetCrate releases published
+// on GitHub. Besides the archives, a release carries checksums.txt with their
+// SHA-256 sums and checksums.txt.sig, an Ed25519 signature of checksums.txt
+// made with the release key. Release builds embed the public half of that key
+// in PublicKey; an archive is only installed when both the signature and its
+// checksum match.
+package update
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netcrate/netcrate/internal/transport"
+)
+
+// DefaultRepository is the GitHub repository official releases come from
+const DefaultRepository = "Ludan-daye/Atomic-Chain-Network-Test"
+
+// PublicKey is the base64 Ed25519 key release checksums are signed with. It
+// is set when a release is built:
+//
+//	-X github.com/netcrate/netcrate/internal/update.PublicKey=<key>
+var PublicKey = ""
+
+// APIURL is where releases are looked up
+var APIURL = "https://api.github.com"
+
+const (
+	checksumsFile = "checksums.txt"
+	signatureFile = "checksums.txt.sig"
+	binaryName    = "netcrate"
+
+	// maxDownload bounds every file fetched from a release
+	maxDownload = 256 << 20
+)
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Release is a published GitHub release
+type Release struct {
+	Tag       string    `json:"tag_name"`
+	Name      string    `json:"name"`
+	URL       string    `json:"html_url"`
+	Published time.Time `json:"published_at"`
+	Assets    []Asset   `json:"assets"`
+}
+
+// Version returns the release version without the leading "v" of its tag,
+// as version.Version is set for release builds
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// Asset returns the file of the release with the given name
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Client talks to the releases of one repository, through the configured
+// proxy
+type Client struct {
+	Repository string
+	HTTP       *http.Client
+}
+
+// NewClient returns a client for repository, DefaultRepository when empty
+func NewClient(repository string) *Client {
+	if repository == "" {
+		repository = DefaultRepository
+	}
+	return &Client{
+		Repository: repository,
+		HTTP:       &http.Client{Transport: transport.HTTPTransport(nil), Timeout: 5 * time.Minute},
+	}
+}
+
+// Latest returns the newest release that is neither a draft nor a
+// prerelease
+func (c *Client) Latest() (*Release, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/repos/%s/releases/latest", APIURL, c.Repository), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the latest release: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s has no published releases", c.Repository)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to look up the latest release of %s: %s", c.Repository, resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDownload)).Decode(&release); err != nil {
+		return nil, fmt.Errorf("invalid release data: %w", err)
+	}
+	return &release, nil
+}
+
+// Fetch downloads the archive of release for this platform and returns the
+// netcrate binary in it, once the signature of checksums.txt and the
+// archive's checksum are verified
+func (c *Client) Fetch(release *Release) ([]byte, error) {
+	key, err := publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	checksums, err := c.downloadAsset(release, checksumsFile)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := c.downloadAsset(release, signatureFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifySignature(key, checksums, signature); err != nil {
+		return nil, err
+	}
+
+	name := ArchiveName(release.Version())
+	archive, err := c.downloadAsset(release, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyChecksum(checksums, name, archive); err != nil {
+		return nil, err
+	}
+	return extractBinary(archive)
+}
+
+func (c *Client) downloadAsset(release *Release, name string) ([]byte, error) {
+	asset, ok := release.Asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s", release.Tag, name)
+	}
+
+	resp, err := c.HTTP.Get(asset.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", name, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	if len(data) > maxDownload {
+		return nil, fmt.Errorf("%s is larger than %d MB", name, maxDownload>>20)
+	}
+	return data, nil
+}
+
+// ArchiveName returns the name of the release archive for this platform
+func ArchiveName(version string) string {
+	return fmt.Sprintf("netcrate_%s_%s_%s.tar.gz", version, runtime.GOOS, runtime.GOARCH)
+}
+
+// publicKey decodes PublicKey
+func publicKey() (ed25519.PublicKey, error) {
+	if PublicKey == "" {
+		return nil, errors.New("this build has no release signing key to verify updates with; install a release build")
+	}
+	key, err := base64.StdEncoding.DecodeString(PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("this build has an invalid release signing key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// VerifySignature checks an Ed25519 signature of data, given raw or base64
+func VerifySignature(key ed25519.PublicKey, data, signature []byte) error {
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil {
+			return fmt.Errorf("%s is not a signature", signatureFile)
+		}
+		signature = decoded
+	}
+	if !ed25519.Verify(key, data, signature) {
+		return fmt.Errorf("the signature of %s does not match the release key", checksumsFile)
+	}
+	return nil
+}
+
+// VerifyChecksum checks data against the SHA-256 sum listed for name in a
+// checksums.txt ("<hex sum>  <file name>" per line)
+func VerifyChecksum(checksums []byte, name string, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("the checksum of %s does not match %s", name, checksumsFile)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s does not list %s", checksumsFile, name)
+}
+
+// extractBinary returns the netcrate binary in a release archive
+func extractBinary(archive []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("invalid release archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("the release archive has no %s binary", binaryName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid release archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == binaryName {
+			return io.ReadAll(io.LimitReader(tr, maxDownload))
+		}
+	}
+}
+
+// Executable returns the path of the running binary with symlinks resolved,
+// which is the file an update replaces
+func Executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// Install replaces the executable at path with binary. The new binary is
+// written next to it and renamed over it, so a failed update leaves the old
+// one in place.
+func Install(path string, binary []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".netcrate-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Newer reports whether latest is a newer version than current. Versions
+// compare by their dot-separated numbers; a dev build is older than any
+// release, and a prerelease older than the release it leads to.
+func Newer(current, latest string) bool {
+	if current == "dev" {
+		return true
+	}
+	return compareVersions(latest, current) > 0
+}
+
+func compareVersions(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	coreA, preA, _ := strings.Cut(a, "-")
+	coreB, preB, _ := strings.Cut(b, "-")
+
+	partsA, partsB := strings.Split(coreA, "."), strings.Split(coreB, ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			y, _ = strconv.Atoi(partsB[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return strings.Compare(preA, preB)
+}
@@ -9,19 +9,19 @@ echo "B1-2: Sampling & Density Prediction Test"
 echo "---------------------------------------"
 
 echo "1. Small network (no sampling):"
-echo "netcrate-simple discover --enhanced 192.168.1.1-10"
-./netcrate-simple discover --enhanced 192.168.1.1-10 2>&1 | head -10
+echo "netcrate debug discover --enhanced 192.168.1.1-10"
+./netcrate debug discover --enhanced 192.168.1.1-10 2>&1 | head -10
 
 echo
 echo "2. Medium network testing sampling threshold (/22 range = ~1K targets):"
-echo "netcrate-simple discover --enhanced 192.168.0.0/22"
-./netcrate-simple discover --enhanced 192.168.0.0/22 2>&1 | head -15
+echo "netcrate debug discover --enhanced 192.168.0.0/22"
+./netcrate debug discover --enhanced 192.168.0.0/22 2>&1 | head -15
 
 echo
 echo "3. Large network with sampling (/20 range = ~4K targets):"
-echo "netcrate-simple discover --enhanced 10.0.0.0/20"
+echo "netcrate debug discover --enhanced 10.0.0.0/20"
 echo "(Showing first 20 lines - sampling should be visible here)"
-./netcrate-simple discover --enhanced 10.0.0.0/20 2>&1 | head -20
+./netcrate debug discover --enhanced 10.0.0.0/20 2>&1 | head -20
 
 echo
 echo "✅ B1-2 Tests completed!"
@@ -9,13 +9,13 @@ echo "B1-3: Method Fallback Test"
 echo "-------------------------"
 
 echo "1. Normal enhanced discovery (should test method availability):"
-echo "netcrate-simple discover --enhanced 192.168.1.1-5"
-./netcrate-simple discover --enhanced 192.168.1.1-5 2>&1 | head -20
+echo "netcrate debug discover --enhanced 192.168.1.1-5"
+./netcrate debug discover --enhanced 192.168.1.1-5 2>&1 | head -20
 
 echo
 echo "2. Test without enhanced discovery (baseline):"
-echo "netcrate-simple discover --compat-a1 192.168.1.1-5"
-./netcrate-simple discover --compat-a1 192.168.1.1-5 2>&1 | head -10
+echo "netcrate debug discover --compat-a1 192.168.1.1-5"
+./netcrate debug discover --compat-a1 192.168.1.1-5 2>&1 | head -10
 
 echo
 echo "✅ B1-3 Tests completed!"
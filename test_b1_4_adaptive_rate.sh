@@ -9,13 +9,13 @@ echo "B1-4: Adaptive Rate Control Test"
 echo "-------------------------------"
 
 echo "1. Normal enhanced discovery (should include adaptive rate simulation):"
-echo "netcrate-simple discover --enhanced 192.168.0.0/22"
-./netcrate-simple discover --enhanced 192.168.0.0/22 2>&1 | head -25
+echo "netcrate debug discover --enhanced 192.168.0.0/22"
+./netcrate debug discover --enhanced 192.168.0.0/22 2>&1 | head -25
 
 echo
 echo "2. Comparison without adaptive rate:"
-echo "netcrate-simple discover --compat-a1 192.168.0.0/22"
-./netcrate-simple discover --compat-a1 192.168.0.0/22 2>&1 | head -10
+echo "netcrate debug discover --compat-a1 192.168.0.0/22"
+./netcrate debug discover --compat-a1 192.168.0.0/22 2>&1 | head -10
 
 echo
 echo "✅ B1-4 Tests completed!"
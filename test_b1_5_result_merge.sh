@@ -9,13 +9,13 @@ echo "B1-5: Result Merge & Deduplication Test"
 echo "--------------------------------------"
 
 echo "1. Enhanced discovery with full B1 features (should show result calibration):"
-echo "netcrate-simple discover --enhanced 192.168.1.1-10"
-./netcrate-simple discover --enhanced 192.168.1.1-10 2>&1 | head -30
+echo "netcrate debug discover --enhanced 192.168.1.1-10"
+./netcrate debug discover --enhanced 192.168.1.1-10 2>&1 | head -30
 
 echo
 echo "2. Comparison with A1 compatibility mode:"
-echo "netcrate-simple discover --compat-a1 192.168.1.1-10"
-./netcrate-simple discover --compat-a1 192.168.1.1-10 2>&1 | head -10
+echo "netcrate debug discover --compat-a1 192.168.1.1-10"
+./netcrate debug discover --compat-a1 192.168.1.1-10 2>&1 | head -10
 
 echo
 echo "✅ B1-5 Tests completed!"
@@ -9,18 +9,18 @@ echo "B1-1: Target Pruning Test"
 echo "-------------------------"
 
 echo "1. Original discover (A1 compatibility):"
-echo "netcrate-simple discover --compat-a1 192.168.1.0/28"
-./netcrate-simple discover --compat-a1 192.168.1.0/28 2>&1 | head -10
+echo "netcrate debug discover --compat-a1 192.168.1.0/28"
+./netcrate debug discover --compat-a1 192.168.1.0/28 2>&1 | head -10
 
 echo
 echo "2. Enhanced discover with target pruning:"
-echo "netcrate-simple discover --target-pruning 192.168.1.0/28"  
-./netcrate-simple discover --target-pruning 192.168.1.0/28 2>&1 | head -15
+echo "netcrate debug discover --target-pruning 192.168.1.0/28"  
+./netcrate debug discover --target-pruning 192.168.1.0/28 2>&1 | head -15
 
 echo
 echo "3. Full enhanced mode:"
-echo "netcrate-simple discover --enhanced 192.168.1.0/28"
-./netcrate-simple discover --enhanced 192.168.1.0/28 2>&1 | head -15
+echo "netcrate debug discover --enhanced 192.168.1.0/28"
+./netcrate debug discover --enhanced 192.168.1.0/28 2>&1 | head -15
 
 echo
 echo "✅ B1-1 Tests completed!"
@@ -6,8 +6,8 @@ echo "================================================"
 
 echo
 echo "1. Testing default (zero-config) mode:"
-echo "netcrate-simple quick"
-./netcrate-simple quick
+echo "netcrate debug quick"
+./netcrate debug quick
 
 echo
 echo
@@ -15,7 +15,7 @@ echo "2. Testing fast profile selection:"
 echo "Simulating: netcrate quick --interactive"
 echo "Port Set: 1 (top100)"
 echo "Speed Profile: 2 (fast)"
-echo "2" | echo "1" | ./netcrate-simple quick --interactive
+echo "2" | echo "1" | ./netcrate debug quick --interactive
 
 echo
 echo
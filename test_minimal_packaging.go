@@ -1,3 +1,5 @@
+//go:build ignore
+
 package main
 
 import (
@@ -15,25 +17,25 @@ func main() {
 	fmt.Println("1. Testing Simple Build:")
 	fmt.Println("=========================")
 	
-	// Build netcrate-simple (should have minimal dependencies)
-	fmt.Printf("Building netcrate-simple binary...\n")
-	cmd := exec.Command("go", "build", "-o", "netcrate-simple-test", "./cmd/netcrate-simple")
+	// Build netcrate (the debug commands ship in the same binary)
+	fmt.Printf("Building netcrate binary...\n")
+	cmd := exec.Command("go", "build", "-o", "netcrate-test", "./cmd/netcrate")
 	if err := cmd.Run(); err != nil {
-		fmt.Printf("❌ Failed to build netcrate-simple: %v\n", err)
+		fmt.Printf("❌ Failed to build netcrate: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Printf("✅ netcrate-simple binary built successfully\n")
+	fmt.Printf("✅ netcrate binary built successfully\n")
 	
 	// Test binary
-	cmd = exec.Command("./netcrate-simple-test", "--version")
+	cmd = exec.Command("./netcrate-test", "--version")
 	if output, err := cmd.Output(); err == nil {
-		fmt.Printf("✅ Simple binary version: %s", string(output))
+		fmt.Printf("✅ Binary version: %s", string(output))
 	} else {
 		fmt.Printf("⚠️  Version command failed: %v\n", err)
 	}
 	
 	// Clean up
-	os.Remove("netcrate-simple-test")
+	os.Remove("netcrate-test")
 	
 	// Test multi-platform builds
 	fmt.Println("\n2. Testing Cross-Platform Builds:")
@@ -49,9 +51,9 @@ func main() {
 	for _, platform := range platforms {
 		fmt.Printf("Building for %s/%s...\n", platform.OS, platform.Arch)
 		
-		binaryName := fmt.Sprintf("netcrate-simple-%s-%s%s", platform.OS, platform.Arch, platform.Ext)
+		binaryName := fmt.Sprintf("netcrate-%s-%s%s", platform.OS, platform.Arch, platform.Ext)
 		
-		cmd = exec.Command("go", "build", "-o", binaryName, "./cmd/netcrate-simple")
+		cmd = exec.Command("go", "build", "-o", binaryName, "./cmd/netcrate")
 		cmd.Env = append(os.Environ(), 
 			"GOOS="+platform.OS, 
 			"GOARCH="+platform.Arch,
@@ -75,11 +77,11 @@ func main() {
 	commit := "abc1234"
 	date := "2023-01-01T00:00:00Z"
 	
-	ldflags := fmt.Sprintf("-ldflags=-X main.Version=%s -X main.Commit=%s -X main.Date=%s",
+	ldflags := fmt.Sprintf("-ldflags=-X github.com/netcrate/netcrate/internal/version.Version=%s -X github.com/netcrate/netcrate/internal/version.Commit=%s -X github.com/netcrate/netcrate/internal/version.Date=%s",
 		version, commit, date)
 	
 	fmt.Printf("Building with version injection...\n")
-	cmd = exec.Command("go", "build", ldflags, "-o", "netcrate-version-test", "./cmd/netcrate-simple")
+	cmd = exec.Command("go", "build", ldflags, "-o", "netcrate-version-test", "./cmd/netcrate")
 	if err := cmd.Run(); err != nil {
 		fmt.Printf("❌ Failed to build with version injection: %v\n", err)
 	} else {
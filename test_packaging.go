@@ -1,3 +1,5 @@
+//go:build ignore
+
 package main
 
 import (
@@ -221,7 +223,6 @@ func main() {
 		"LICENSE", 
 		"CHANGELOG.md",
 		"cmd/netcrate/main.go",
-		"cmd/netcrate-simple/main.go",
 		"internal/version/version.go",
 	}
 	